@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/config"
 	"team4s.v3/backend/internal/database"
 	"team4s.v3/backend/internal/migrations"
@@ -32,6 +33,10 @@ func main() {
 		runBackfillPhaseAMetadata(os.Args[2:])
 	case "backfill-badges":
 		runBackfillBadges(os.Args[2:])
+	case "media-dedupe-report":
+		runMediaDedupeReport(os.Args[2:])
+	case "media-to-bucket":
+		runMediaToBucket(os.Args[2:])
 	default:
 		log.Printf("unknown command: %s", command)
 		printUsageAndExit(1)
@@ -184,6 +189,8 @@ func printUsageAndExit(code int) {
 	fmt.Fprintf(os.Stderr, "  migrate status [-dir path] [-database-url url]\n")
 	fmt.Fprintf(os.Stderr, "  migrate backfill-phase-a-metadata [-database-url url]\n")
 	fmt.Fprintf(os.Stderr, "  migrate backfill-badges [-database-url url]\n")
+	fmt.Fprintf(os.Stderr, "  migrate media-dedupe-report [-register] [-database-url url]\n")
+	fmt.Fprintf(os.Stderr, "  migrate media-to-bucket [-dry-run] [-delete-local] [-database-url url]\n")
 	os.Exit(code)
 }
 
@@ -224,3 +231,103 @@ func runBackfillBadges(args []string) {
 		log.Printf("badge backfill warning: %s", e)
 	}
 }
+
+func runMediaDedupeReport(args []string) {
+	fs := flag.NewFlagSet("media-dedupe-report", flag.ExitOnError)
+	databaseURL := fs.String("database-url", os.Getenv("DATABASE_URL"), "PostgreSQL connection URL")
+	register := fs.Bool("register", false, "Register unlinked files as media blobs (files are never moved or deleted)")
+	_ = fs.Parse(args)
+
+	cfg := config.Load()
+	if *databaseURL == "" {
+		*databaseURL = cfg.DatabaseURL
+	}
+	if *databaseURL == "" {
+		log.Fatal("DATABASE_URL is required. Set env var or pass -database-url.")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	dbPool, err := database.NewPool(ctx, *databaseURL)
+	if err != nil {
+		log.Fatalf("database init failed: %v", err)
+	}
+	defer dbPool.Close()
+
+	mediaRepo := repository.NewMediaRepository(dbPool, cfg.MediaPublicBaseURL, cfg.MediaStorageDir)
+	report, err := services.NewMediaDedupeService(mediaRepo).Report(ctx, *register)
+	if err != nil {
+		log.Fatalf("media dedupe report failed: %v", err)
+	}
+
+	log.Printf("media dedupe report: scanned_assets=%d hashed_files=%d unique_contents=%d duplicate_groups=%d reclaimable_bytes=%d missing=%d",
+		report.ScannedAssets, report.HashedFiles, report.UniqueContents, len(report.DuplicateGroups), report.ReclaimableBytes, len(report.MissingAssetIDs))
+	for _, group := range report.DuplicateGroups {
+		log.Printf("duplicate sha256=%s size_bytes=%d media_asset_ids=%v paths=%v", group.SHA256, group.SizeBytes, group.MediaAssetIDs, group.Paths)
+	}
+	if len(report.MissingAssetIDs) > 0 {
+		log.Printf("media dedupe warning: files missing for media_asset_ids=%v", report.MissingAssetIDs)
+	}
+	if *register {
+		log.Printf("media dedupe register: blobs=%d linked_media_assets=%d", report.RegisteredBlobs, report.LinkedMediaAssets)
+	}
+}
+
+// runMediaToBucket kopiert die referenzierten Mediendateien aus MEDIA_STORAGE_DIR in den per
+// MEDIA_S3_* konfigurierten Bucket. Der Server kann währenddessen bereits mit
+// MEDIA_STORAGE_BACKEND=s3 laufen; noch nicht kopierte Dateien liest er weiter lokal.
+func runMediaToBucket(args []string) {
+	fs := flag.NewFlagSet("media-to-bucket", flag.ExitOnError)
+	databaseURL := fs.String("database-url", os.Getenv("DATABASE_URL"), "PostgreSQL connection URL")
+	dryRun := fs.Bool("dry-run", false, "Only report what would be copied and rewritten")
+	deleteLocal := fs.Bool("delete-local", false, "Delete local files once their content is in the bucket")
+	_ = fs.Parse(args)
+
+	cfg := config.Load()
+	if *databaseURL == "" {
+		*databaseURL = cfg.DatabaseURL
+	}
+	if *databaseURL == "" {
+		log.Fatal("DATABASE_URL is required. Set env var or pass -database-url.")
+	}
+
+	bucket, err := blobstore.NewS3Store(blobstore.S3Config{
+		Endpoint:        cfg.MediaS3Endpoint,
+		Region:          cfg.MediaS3Region,
+		Bucket:          cfg.MediaS3Bucket,
+		AccessKeyID:     cfg.MediaS3AccessKeyID,
+		SecretAccessKey: cfg.MediaS3SecretAccessKey,
+		Prefix:          cfg.MediaS3Prefix,
+		PathStyle:       cfg.MediaS3PathStyle,
+	})
+	if err != nil {
+		log.Fatalf("media bucket init failed (MEDIA_S3_*): %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 6*time.Hour)
+	defer cancel()
+
+	dbPool, err := database.NewPool(ctx, *databaseURL)
+	if err != nil {
+		log.Fatalf("database init failed: %v", err)
+	}
+	defer dbPool.Close()
+
+	mediaRepo := repository.NewMediaRepository(dbPool, cfg.MediaPublicBaseURL, cfg.MediaStorageDir)
+	report, err := services.NewMediaBucketMigrationService(mediaRepo, cfg.MediaStorageDir, bucket).
+		Migrate(ctx, services.MediaBucketMigrationOptions{DryRun: *dryRun, DeleteLocal: *deleteLocal})
+	if err != nil {
+		log.Fatalf("media to bucket failed: %v", err)
+	}
+
+	log.Printf("media to bucket (dry_run=%t, bucket=%s): scanned_paths=%d copied=%d copied_bytes=%d already_in_bucket=%d rewritten_paths=%d rewritten_rows=%d deleted_local=%d",
+		*dryRun, bucket.Bucket(), report.ScannedPaths, report.CopiedObjects, report.CopiedBytes, report.AlreadyInBucket,
+		report.RewrittenPaths, report.RewrittenRows, report.DeletedLocal)
+	if len(report.MissingPaths) > 0 {
+		log.Printf("media to bucket warning: %d files missing locally and in bucket: %v", len(report.MissingPaths), report.MissingPaths)
+	}
+	if len(report.ConflictPaths) > 0 {
+		log.Printf("media to bucket warning: %d paths skipped due to conflicting objects: %v", len(report.ConflictPaths), report.ConflictPaths)
+	}
+}
@@ -30,6 +30,12 @@ type adminRouteHandlers struct {
 	adminCapabilityHandler *handlers.AdminCapabilityHandler
 	// Phase 95-02: Assignable Gruppenrollen aus role_definitions (D-12)
 	adminGroupRolesHandler *handlers.AdminGroupRolesHandler
+	// Kommentar-Moderation (Queue + approve/hide/ban, comments.moderate im Handler)
+	commentHandler *handlers.CommentHandler
+	// Mail-Outbox: Liste und erneuter Versand (requirePlatformAdminIdentity im Handler)
+	adminMailOutboxHandler *handlers.AdminMailOutboxHandler
+	// Ausgehende Gruppen-Webhooks inkl. Zustell-Log (fansub_group.webhooks.manage im Handler)
+	fansubWebhookHandler *handlers.FansubGroupWebhookHandler
 }
 
 func registerAdminRoutes(v1 *gin.RouterGroup, auth gin.HandlerFunc, deps adminRouteHandlers) {
@@ -69,8 +75,22 @@ func registerAdminRoutes(v1 *gin.RouterGroup, auth gin.HandlerFunc, deps adminRo
 	v1.GET("/admin/jellyfin/series", auth, deps.adminContentHandler.SearchJellyfinSeries)
 	v1.POST("/admin/jellyfin/intake/preview", auth, deps.adminContentHandler.PreviewAnimeIntakeFromJellyfin)
 	v1.POST("/admin/anime/:id/jellyfin/preview", auth, deps.adminContentHandler.PreviewAnimeFromJellyfin)
+	v1.GET("/admin/anime/:id/jellyfin/sync-settings", auth, deps.adminContentHandler.GetAnimeJellyfinSyncSettings)
+	v1.PUT("/admin/anime/:id/jellyfin/sync-settings", auth, deps.adminContentHandler.UpdateAnimeJellyfinSyncSettings)
+	v1.GET("/admin/jellyfin/pending-changes", auth, deps.adminContentHandler.ListJellyfinPendingChanges)
+	v1.POST("/admin/jellyfin/pending-changes/:changeId/apply", auth, deps.adminContentHandler.ApplyJellyfinPendingChange)
+	v1.POST("/admin/jellyfin/pending-changes/:changeId/reject", auth, deps.adminContentHandler.RejectJellyfinPendingChange)
+	v1.GET("/admin/jellyfin/reconciliation/runs", auth, deps.adminContentHandler.ListJellyfinReconciliationRuns)
+	v1.GET("/admin/jellyfin/reconciliation/findings", auth, deps.adminContentHandler.ListJellyfinReconciliationFindings)
+	v1.POST("/admin/jellyfin/reconciliation/findings/resolve", auth, deps.adminContentHandler.ResolveJellyfinReconciliationFindings)
+	v1.GET("/admin/playback-sessions", auth, deps.adminContentHandler.ListPlaybackSessions)
+	v1.DELETE("/admin/playback-sessions/:sessionId", auth, deps.adminContentHandler.TerminatePlaybackSession)
+	v1.GET("/admin/playback-security/events", auth, deps.adminContentHandler.ListPlaybackSecurityEvents)
+	v1.GET("/admin/playback-security/blocks", auth, deps.adminContentHandler.ListPlaybackGrantBlocks)
+	v1.DELETE("/admin/playback-security/blocks/:id", auth, deps.adminContentHandler.LiftPlaybackGrantBlock)
 	v1.GET("/admin/episode-versions/:versionId/editor-context", auth, deps.adminContentHandler.GetEpisodeVersionEditorContext)
 	v1.POST("/admin/episode-versions/:versionId/folder-scan", auth, deps.adminContentHandler.ScanEpisodeVersionFolder)
+	v1.GET("/admin/episode-versions/:versionId/media-probe", auth, deps.adminContentHandler.ProbeEpisodeVersionMedia)
 	v1.POST("/admin/episodes", auth, deps.adminContentHandler.CreateEpisode)
 	v1.GET("/admin/genres", auth, deps.adminContentHandler.ListGenreTokens)
 	v1.GET("/admin/tags", auth, deps.adminContentHandler.ListTagTokens)
@@ -116,6 +136,8 @@ func registerAdminRoutes(v1 *gin.RouterGroup, auth gin.HandlerFunc, deps adminRo
 	v1.GET("/admin/anime/:id/segments/library-candidates", auth, deps.adminContentHandler.ListSegmentLibraryCandidates)
 	v1.GET("/admin/anime/:id/segments/suggestions", auth, deps.adminContentHandler.GetAnimeSegmentSuggestions)
 	v1.POST("/admin/anime/:id/segments", auth, deps.adminContentHandler.CreateAnimeSegment)
+	v1.POST("/admin/anime/:id/segments/jellyfin-import/preview", auth, deps.adminContentHandler.PreviewThemeSegmentImport)
+	v1.POST("/admin/anime/:id/segments/jellyfin-import/apply", auth, deps.adminContentHandler.ApplyThemeSegmentImport)
 	v1.PATCH("/admin/anime/:id/segments/:segmentId", auth, deps.adminContentHandler.UpdateAnimeSegment)
 	v1.DELETE("/admin/anime/:id/segments/:segmentId", auth, deps.adminContentHandler.DeleteAnimeSegment)
 	v1.POST("/admin/anime/:id/segments/:segmentId/reuse", auth, deps.adminContentHandler.AttachSegmentLibraryAsset)
@@ -241,4 +263,22 @@ func registerAdminRoutes(v1 *gin.RouterGroup, auth gin.HandlerFunc, deps adminRo
 	if deps.adminGroupRolesHandler != nil {
 		v1.GET("/admin/fansub-group-roles", auth, deps.adminGroupRolesHandler.ListFansubGroupRoles)
 	}
+	// Kommentar-Moderation: Queue und Entscheidungen (CanModerateComments im Handler)
+	if deps.commentHandler != nil {
+		v1.GET("/admin/comments/moderation", auth, deps.commentHandler.ListModerationQueue)
+		v1.POST("/admin/comments/:id/moderation", auth, deps.commentHandler.ModerateByID)
+	}
+	// Mail-Outbox: Admin-Ansicht und erneuter Versand toter Mails
+	if deps.adminMailOutboxHandler != nil {
+		v1.GET("/admin/mail-outbox", auth, deps.adminMailOutboxHandler.List)
+		v1.POST("/admin/mail-outbox/:id/resend", auth, deps.adminMailOutboxHandler.Resend)
+	}
+	// Gruppen-Webhooks: Verwaltung und Zustell-Log
+	if deps.fansubWebhookHandler != nil {
+		v1.GET("/admin/fansubs/:id/webhooks", auth, deps.fansubWebhookHandler.List)
+		v1.POST("/admin/fansubs/:id/webhooks", auth, deps.fansubWebhookHandler.Create)
+		v1.PATCH("/admin/fansubs/:id/webhooks/:webhookId", auth, deps.fansubWebhookHandler.Update)
+		v1.DELETE("/admin/fansubs/:id/webhooks/:webhookId", auth, deps.fansubWebhookHandler.Delete)
+		v1.GET("/admin/fansubs/:id/webhooks/:webhookId/deliveries", auth, deps.fansubWebhookHandler.ListDeliveries)
+	}
 }
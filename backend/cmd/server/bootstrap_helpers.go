@@ -6,8 +6,11 @@ import (
 	"log"
 	"os/exec"
 	"strings"
+	"time"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/config"
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/repository"
 
 	"github.com/jackc/pgx/v5/pgconn"
@@ -47,9 +50,21 @@ func validateRuntimeConfig(cfg config.Config) {
 	if cfg.EpisodePlaybackRateWindowSec <= 0 {
 		log.Fatal("EPISODE_PLAYBACK_RATE_WINDOW_SECONDS must be greater than 0")
 	}
+	if cfg.EpisodePlaybackHLSRateLimit <= 0 {
+		log.Fatal("EPISODE_PLAYBACK_HLS_RATE_LIMIT must be greater than 0")
+	}
 	if cfg.EpisodePlaybackMaxConcurrent <= 0 {
 		log.Fatal("EPISODE_PLAYBACK_MAX_CONCURRENT_STREAMS must be greater than 0")
 	}
+	if cfg.EpisodePlaybackMaxPerUser <= 0 {
+		log.Fatal("EPISODE_PLAYBACK_MAX_SESSIONS_PER_USER must be greater than 0")
+	}
+	if cfg.EpisodePlaybackMaxInstance <= 0 {
+		log.Fatal("EPISODE_PLAYBACK_MAX_INSTANCE_STREAMS must be greater than 0")
+	}
+	if cfg.EpisodePlaybackSessionTTLSec <= 0 {
+		log.Fatal("EPISODE_PLAYBACK_SESSION_TIMEOUT_SECONDS must be greater than 0")
+	}
 	if !cfg.AuthIssueDevMode {
 		return
 	}
@@ -131,3 +146,39 @@ func isLocalDevProfile(profile string) bool {
 func checkFFmpegAvailability(ffmpegPath string) error {
 	return exec.Command(ffmpegPath, "-version").Run()
 }
+
+// buildMediaServer erstellt den Media-Server-Provider für kind aus den JELLYFIN_*- bzw.
+// EMBY_*-Variablen.
+func buildMediaServer(kind string, cfg config.Config) (mediaserver.MediaServerProvider, error) {
+	if mediaserver.NormalizeKind(kind) == mediaserver.KindEmby {
+		return mediaserver.New(kind, mediaserver.Config{
+			BaseURL:            cfg.EmbyStreamBaseURL,
+			APIKey:             cfg.EmbyAPIKey,
+			StreamPathTemplate: cfg.EmbyStreamPathTemplate,
+			HLSPathTemplate:    cfg.EmbyHLSPathTemplate,
+		})
+	}
+	return mediaserver.New(kind, mediaserver.Config{
+		BaseURL:            cfg.JellyfinBaseURL,
+		APIKey:             cfg.JellyfinAPIKey,
+		AllowedLibraryIDs:  cfg.JellyfinAllowedLibraryIDs,
+		StreamPathTemplate: cfg.JellyfinStreamPathTemplate,
+		HLSPathTemplate:    cfg.EmbyHLSPathTemplate,
+	})
+}
+
+// buildMediaBlobStore erstellt die Medienablage aus MEDIA_STORAGE_BACKEND und den
+// MEDIA_S3_*-Variablen. Im S3-Betrieb bleibt MEDIA_STORAGE_DIR Lese-Fallback für noch nicht
+// migrierte Dateien.
+func buildMediaBlobStore(cfg config.Config) (blobstore.BlobStore, error) {
+	return blobstore.New(cfg.MediaStorageBackend, cfg.MediaStorageDir, blobstore.S3Config{
+		Endpoint:        cfg.MediaS3Endpoint,
+		Region:          cfg.MediaS3Region,
+		Bucket:          cfg.MediaS3Bucket,
+		AccessKeyID:     cfg.MediaS3AccessKeyID,
+		SecretAccessKey: cfg.MediaS3SecretAccessKey,
+		Prefix:          cfg.MediaS3Prefix,
+		PathStyle:       cfg.MediaS3PathStyle,
+		PresignTTL:      time.Duration(cfg.MediaS3PresignSeconds) * time.Second,
+	})
+}
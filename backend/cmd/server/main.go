@@ -14,7 +14,9 @@ import (
 	"team4s.v3/backend/internal/config"
 	"team4s.v3/backend/internal/database"
 	"team4s.v3/backend/internal/handlers"
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/permissions"
 	"team4s.v3/backend/internal/repository"
 	"team4s.v3/backend/internal/services"
@@ -41,12 +43,20 @@ func main() {
 	defer redisClient.Close()
 
 	// Check FFmpeg availability for video processing
+	ffmpegAvailable := true
 	if err := checkFFmpegAvailability(cfg.FFmpegPath); err != nil {
+		ffmpegAvailable = false
 		log.Printf("warning: ffmpeg not available at %s: %v (video upload will be disabled)", cfg.FFmpegPath, err)
 	} else {
 		log.Printf("ffmpeg available at %s", cfg.FFmpegPath)
 	}
 
+	mediaBlobStore, err := buildMediaBlobStore(cfg)
+	if err != nil {
+		log.Fatalf("media storage init failed (MEDIA_STORAGE_BACKEND): %v", err)
+	}
+	log.Printf("media storage backend: %s", mediaBlobStore.Kind())
+
 	router := gin.New()
 	router.Use(gin.Logger(), gin.Recovery(), corsMiddleware(cfg.CORSAllowedOrigins))
 
@@ -56,8 +66,8 @@ func main() {
 		})
 	})
 
-	// Serve uploaded media files (images, videos) from storage directory.
-	// Security headers are set via middleware before StaticFS delivery.
+	// Serve uploaded media files (images, videos) from the media blob store.
+	// Security headers are set via middleware before delivery.
 	mediaGroup := router.Group("/media")
 	mediaGroup.Use(func(c *gin.Context) {
 		c.Header("X-Content-Type-Options", "nosniff")
@@ -66,10 +76,20 @@ func main() {
 		c.Header("Cache-Control", "private, no-transform")
 		c.Next()
 	})
-	mediaGroup.StaticFS("", http.Dir(cfg.MediaStorageDir))
+	mediaStorageHandler := handlers.NewMediaStorageHandler(mediaBlobStore)
+	mediaGroup.GET("/*filepath", mediaStorageHandler.ServeFile)
+	mediaGroup.HEAD("/*filepath", mediaStorageHandler.ServeFile)
 
 	animeRepo := repository.NewAnimeRepository(dbPool)
 	animeAssetRepo := repository.NewAnimeAssetRepository(dbPool)
+	catalogMediaServer, err := buildMediaServer(cfg.MediaServerCatalog, cfg)
+	if err != nil {
+		log.Fatalf("media server init failed (MEDIA_SERVER_CATALOG): %v", err)
+	}
+	playbackMediaServer, err := buildMediaServer(cfg.MediaServerPlayback, cfg)
+	if err != nil {
+		log.Fatalf("media server init failed (MEDIA_SERVER_PLAYBACK): %v", err)
+	}
 	animeHandler := handlers.NewAnimeHandler(
 		animeRepo,
 		animeAssetRepo,
@@ -77,30 +97,57 @@ func main() {
 			JellyfinAPIKey:  cfg.JellyfinAPIKey,
 			JellyfinBaseURL: cfg.JellyfinBaseURL,
 		},
-	)
+	).WithMediaServer(catalogMediaServer)
 	episodeRepo := repository.NewEpisodeRepository(dbPool)
 	episodeHandler := handlers.NewEpisodeHandler(episodeRepo)
 	fansubRepo := repository.NewFansubRepository(dbPool, cfg.MediaStorageDir)
 	mediaRepo := repository.NewMediaRepository(dbPool, cfg.MediaPublicBaseURL, cfg.MediaStorageDir)
-	mediaService := services.NewMediaService(cfg.MediaStorageDir, cfg.MediaPublicBaseURL)
+	mediaService := services.NewMediaService(cfg.MediaStorageDir, cfg.MediaPublicBaseURL).WithBlobStore(mediaBlobStore)
+	localMediaLibrary := services.NewLocalMediaLibrary(cfg.LocalMediaRoot, cfg.FFmpegPath)
 	episodeVersionRepo := repository.NewEpisodeVersionRepository(dbPool)
 	episodeVersionImageRepo := repository.NewEpisodeVersionImageRepository(dbPool)
 	episodeVersionImagesHandler := handlers.NewEpisodeVersionImagesHandler(episodeVersionImageRepo)
 	releaseAssetsHandler := handlers.NewReleaseAssetsHandler(episodeVersionRepo)
+	episodeSkipMarkersHandler := handlers.NewEpisodeSkipMarkersHandler(repository.NewEpisodeSkipMarkerRepository(dbPool))
 	episodePlaybackHandler := handlers.NewEpisodePlaybackHandler(episodeRepo, handlers.EpisodePlaybackConfig{
 		EmbyAPIKey:              cfg.EmbyAPIKey,
 		EmbyStreamBaseURL:       cfg.EmbyStreamBaseURL,
 		EmbyStreamPathTemplate:  cfg.EmbyStreamPathTemplate,
+		EmbyHLSPathTemplate:     cfg.EmbyHLSPathTemplate,
 		AllowedAnimeIDs:         cfg.EmbyAllowedAnimeIDs,
 		ReleaseGrantSecret:      resolveReleaseGrantSecret(cfg),
 		ReleaseGrantTTLSeconds:  cfg.ReleaseStreamGrantTTLSeconds,
 		PlaybackRateLimitClient: redisClient,
 		PlaybackRateLimit:       cfg.EpisodePlaybackRateLimit,
 		PlaybackRateWindowSec:   cfg.EpisodePlaybackRateWindowSec,
-		MaxConcurrentStreams:    cfg.EpisodePlaybackMaxConcurrent,
+		HLSRateLimit:            cfg.EpisodePlaybackHLSRateLimit,
+		MaxConcurrentStreams:    cfg.EpisodePlaybackMaxInstance,
+		HLSTTLSeconds:           cfg.EpisodePlaybackHLSTTLSeconds,
 	})
+	// Playback-Sessions liegen in Redis, damit die Stream-Limits über alle Instanzen gelten.
+	playbackSessionRepo := repository.NewPlaybackSessionRepository(redisClient, repository.PlaybackSessionConfig{
+		Timeout:    time.Duration(cfg.EpisodePlaybackSessionTTLSec) * time.Second,
+		MaxPerUser: cfg.EpisodePlaybackMaxPerUser,
+		MaxGlobal:  cfg.EpisodePlaybackMaxConcurrent,
+	})
+	episodePlaybackHandler.WithPlaybackSessions(playbackSessionRepo)
+	// Sicherheitsereignisse landen dauerhaft in Postgres; die Sperrregel blockiert auffällige Nutzer.
+	playbackSecurityRepo := repository.NewPlaybackSecurityRepository(dbPool)
+	playbackGrantBlockRule := models.PlaybackGrantBlockRule{
+		Threshold: cfg.PlaybackGrantBlockThreshold,
+		Window:    time.Duration(cfg.PlaybackGrantBlockWindowMin) * time.Minute,
+		Duration:  time.Duration(cfg.PlaybackGrantBlockMinutes) * time.Minute,
+	}
+	episodePlaybackHandler.WithPlaybackSecurity(playbackSecurityRepo, playbackGrantBlockRule)
+	watchProgressRepo := repository.NewWatchProgressRepository(dbPool)
+	episodePlaybackHandler.WithWatchProgressRepo(watchProgressRepo)
+	// Emby ohne EMBY_STREAM_BASE_URL nutzt weiterhin den Host der Quell-URL; dafür bleibt der
+	// Handler-Fallback aktiv.
+	if playbackMediaServer.Kind() != mediaserver.KindEmby || cfg.EmbyStreamBaseURL != "" {
+		episodePlaybackHandler.WithMediaServer(playbackMediaServer)
+	}
 	commentRepo := repository.NewCommentRepository(dbPool)
-	commentHandler := handlers.NewCommentHandler(commentRepo)
+	commentHandler := handlers.NewCommentHandler(commentRepo).WithMarkdown(services.NewMarkdownService())
 	commentCreateLimiter := middleware.NewCommentRateLimiter(redisClient, 5, time.Minute)
 	authRepo := repository.NewAuthRepository(redisClient)
 	appAuthRepo := repository.NewAppAuthRepository(dbPool)
@@ -119,7 +166,7 @@ func main() {
 		},
 	)
 	watchlistRepo := repository.NewWatchlistRepository(dbPool)
-	watchlistHandler := handlers.NewWatchlistHandler(watchlistRepo)
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistRepo).WithWatchProgressRepo(watchProgressRepo)
 	adminContentRepo := repository.NewAdminContentRepository(dbPool)
 	episodeImportRepo := repository.NewEpisodeImportRepository(dbPool)
 	authzRepo := repository.NewAuthzRepository(dbPool)
@@ -132,6 +179,13 @@ func main() {
 		log.Fatalf("Gruppenrollen-Catalog laden fehlgeschlagen: %v", err)
 	}
 	auditLogRepo := repository.NewAuditLogRepository(dbPool)
+	commentHandler.WithModeration(permissionSvc, auditLogRepo, services.NewCommentSpamHeuristics(services.CommentSpamConfig{
+		MaxLinks:        cfg.CommentSpamMaxLinks,
+		RepeatWindow:    time.Duration(cfg.CommentSpamRepeatWindowSec) * time.Second,
+		RepeatThreshold: cfg.CommentSpamRepeatThreshold,
+		NewAccountAge:   time.Duration(cfg.CommentSpamNewAccountHours) * time.Hour,
+		BlockedWords:    cfg.CommentSpamBlockedWords,
+	}), cfg.CommentReportHideThreshold)
 	memberClaimsRepo := repository.NewMemberClaimsRepository(dbPool).WithAuditLog(auditLogRepo)
 	memberClaimInvitationsRepo := repository.NewMemberClaimInvitationRepository(dbPool, cfg.AppPublicURL).WithAuditLog(auditLogRepo)
 	memberRequestsRepo := repository.NewMemberRequestsRepository(dbPool)
@@ -160,6 +214,38 @@ func main() {
 		mailerSvc = services.NewNoopMailer()
 		log.Printf("SMTP_ENABLED=false: Noop-Mailer aktiv (kein Mailversand)")
 	}
+	mailTemplates, err := services.NewMailTemplates(cfg.AppPublicURL)
+	if err != nil {
+		log.Fatalf("mail templates init failed: %v", err)
+	}
+	mailOutboxRepo := repository.NewMailOutboxRepository(dbPool)
+	mailOutboxWorker := services.NewMailOutboxWorker(mailOutboxRepo, mailerSvc, mailTemplates, services.MailOutboxConfig{
+		PollInterval: time.Duration(cfg.MailOutboxPollSeconds) * time.Second,
+		BatchSize:    cfg.MailOutboxBatchSize,
+		BaseBackoff:  time.Duration(cfg.MailOutboxBaseBackoffSeconds) * time.Second,
+		MaxBackoff:   time.Duration(cfg.MailOutboxMaxBackoffSeconds) * time.Second,
+	})
+	notificationRepo := repository.NewNotificationRepository(dbPool)
+	notificationSvc := services.NewNotificationService(notificationRepo)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo)
+	notificationDigestRepo := repository.NewNotificationDigestRepository(dbPool)
+	notificationDigestSvc := services.NewNotificationDigestService(notificationDigestRepo, services.NotificationDigestConfig{
+		PollInterval: time.Duration(cfg.DigestPollSeconds) * time.Second,
+		SendHour:     cfg.DigestSendHour,
+		WeeklyDay:    time.Weekday(cfg.DigestWeeklyDay),
+	})
+	notificationDigestHandler := handlers.NewNotificationDigestHandler(notificationDigestRepo, notificationDigestSvc)
+	followHandler := handlers.NewFollowHandler(repository.NewFollowRepository(dbPool))
+	releaseFeedHandler := handlers.NewReleaseFeedHandler(repository.NewReleaseFeedRepository(dbPool), cfg.AppPublicURL)
+	webhookRepo := repository.NewWebhookRepository(dbPool)
+	webhookSvc := services.NewWebhookService(webhookRepo)
+	webhookWorker := services.NewWebhookWorker(webhookRepo, cfg.AppPublicURL, services.WebhookWorkerConfig{
+		PollInterval: time.Duration(cfg.WebhookPollSeconds) * time.Second,
+		BaseBackoff:  time.Duration(cfg.WebhookBaseBackoffSeconds) * time.Second,
+		MaxBackoff:   time.Duration(cfg.WebhookMaxBackoffSeconds) * time.Second,
+	})
+	commentHandler.WithNotifications(notificationSvc)
+	memberClaimsHandler.WithNotifications(notificationSvc)
 	groupAppMemberRepo := repository.NewFansubGroupAppMemberRepository(dbPool, cfg.MediaPublicBaseURL)
 	groupInvitationRepo := repository.NewFansubGroupInvitationRepository(dbPool, groupAppMemberRepo)
 	var authMiddleware gin.HandlerFunc
@@ -190,12 +276,11 @@ func main() {
 		permissionSvc,
 		auditLogRepo,
 		tiptapSvc,
-		mailerSvc,
 		cfg.MediaStorageDir,
 		cfg.MediaPublicBaseURL,
 		cfg.KeycloakAccountURL,
-		cfg.AppPublicURL,
-	)
+	).WithNotifications(notificationSvc).
+		WithBlobStore(mediaBlobStore)
 	adminBootstrapUserIDs := resolveAdminBootstrapUserIDs(cfg)
 	if err := bootstrapAdminRoleAssignments(ctx, authzRepo, cfg.AuthAdminRoleName, adminBootstrapUserIDs); err != nil {
 		if isUndefinedTableError(err) {
@@ -225,11 +310,19 @@ func main() {
 		},
 	)
 	adminContentHandler.WithMediaDeps(mediaRepo, mediaService).
+		WithMediaServer(catalogMediaServer).
+		WithLocalMedia(localMediaLibrary).
 		WithNoteDeps(repository.NewFansubNotesRepository(dbPool), services.NewMarkdownService()).
 		WithReleaseVersionNoteDeps(repository.NewReleaseVersionNotesRepository(dbPool)).
 		WithFansubReleasesContributionsDeps(repository.NewFansubReleasesContributionsRepository(dbPool)).
 		WithTipTapDeps(tiptapSvc).
-		WithPermissionDeps(permissionSvc, auditLogRepo)
+		WithPermissionDeps(permissionSvc, auditLogRepo).
+		WithNotifications(notificationSvc).
+		WithWebhooks(webhookSvc).
+		WithJellyfinWebhook(repository.NewJellyfinPendingChangeRepository(dbPool), cfg.JellyfinWebhookSecret).
+		WithJellyfinReconciliation(repository.NewJellyfinReconciliationRepository(dbPool), cfg.JellyfinAllowedLibraryIDs).
+		WithPlaybackSessions(playbackSessionRepo).
+		WithPlaybackSecurity(playbackSecurityRepo)
 	fansubHandler := handlers.NewFansubHandler(
 		fansubRepo,
 		episodeVersionRepo,
@@ -245,7 +338,9 @@ func main() {
 			ReleaseGrantSecret:     resolveReleaseGrantSecret(cfg),
 			ReleaseGrantTTLSeconds: cfg.ReleaseStreamGrantTTLSeconds,
 		},
-	).WithMedia(mediaRepo, mediaService).WithPermissionDeps(permissionSvc, auditLogRepo)
+	).WithMedia(mediaRepo, mediaService).WithPermissionDeps(permissionSvc, auditLogRepo).WithWebhooks(webhookSvc).
+		WithLocalMedia(localMediaLibrary, redisClient, cfg.EpisodePlaybackRateLimit, cfg.EpisodePlaybackRateWindowSec).
+		WithPlaybackSecurity(playbackSecurityRepo, playbackGrantBlockRule)
 	groupRepo := repository.NewGroupRepository(dbPool)
 	groupHandler := handlers.NewGroupHandler(groupRepo)
 	groupContributorsRepo := repository.NewGroupContributorsRepository(dbPool)
@@ -270,14 +365,21 @@ func main() {
 		JellyfinStreamPath: cfg.JellyfinStreamPathTemplate,
 	})
 	assetLifecycleRepo := repository.NewAssetLifecycleRepository(dbPool)
-	assetLifecycleService := services.NewAssetLifecycleService(assetLifecycleRepo, cfg.MediaStorageDir)
+	assetLifecycleService := services.NewAssetLifecycleService(assetLifecycleRepo, cfg.MediaStorageDir).
+		WithBlobStore(mediaBlobStore)
 	mediaUploadRepo := repository.NewMediaUploadRepository(dbPool)
 	mediaUploadHandler := handlers.NewMediaUploadHandler(mediaUploadRepo, cfg.MediaStorageDir, cfg.MediaPublicBaseURL, cfg.FFmpegPath).
-		WithLifecycleService(assetLifecycleService)
+		WithLifecycleService(assetLifecycleService).
+		WithBlobStore(mediaBlobStore)
 
 	// Periodic release-version-media cleanup job (stale processing, missing files, soft-delete).
 	// Runs every 10 minutes in a background goroutine; best-effort, never stops the server.
-	rvmCleanupSvc := services.NewRVMCleanupService(mediaRepo, cfg.MediaStorageDir)
+	trickplayRepo := repository.NewTrickplayRepository(dbPool)
+	releaseTrickplayHandler := handlers.NewReleaseTrickplayHandler(trickplayRepo, cfg.MediaStorageDir).
+		WithBlobStore(mediaBlobStore)
+	rvmCleanupSvc := services.NewRVMCleanupService(mediaRepo, cfg.MediaStorageDir).
+		WithBlobStore(mediaBlobStore).
+		WithTrickplayCleanup(trickplayRepo)
 	go func() {
 		ticker := time.NewTicker(services.RVMCleanupInterval)
 		defer ticker.Stop()
@@ -289,11 +391,47 @@ func main() {
 		}
 	}()
 
+	// Mail-Outbox-Worker: versendet eingereihte Mails mit Backoff; läuft best-effort im Hintergrund.
+	go mailOutboxWorker.Run(context.Background())
+	// Digest-Scheduler: reiht fällige Zusammenfassungs-Mails in den Outbox ein.
+	go notificationDigestSvc.Run(context.Background())
+	// Webhook-Worker: stellt Gruppen-Webhooks signiert und mit Backoff zu.
+	go webhookWorker.Run(context.Background())
+	// Jellyfin-Abgleich: prüft verknüpfte Anime periodisch gegen die freigegebenen Bibliotheken.
+	if cfg.JellyfinReconcileMinutes > 0 {
+		jellyfinReconcileWorker := services.NewJellyfinReconcileWorker(adminContentHandler, services.JellyfinReconcileWorkerConfig{
+			Interval: time.Duration(cfg.JellyfinReconcileMinutes) * time.Minute,
+		})
+		go jellyfinReconcileWorker.Run(context.Background())
+	}
+	// Trickplay-Worker: erzeugt Sprite-Sheets und WebVTT-Vorschauspuren für Release-Varianten.
+	if cfg.TrickplayScanMinutes > 0 && ffmpegAvailable {
+		trickplayWorker := services.NewTrickplayWorker(
+			trickplayRepo,
+			services.NewTrickplaySources(localMediaLibrary, catalogMediaServer, playbackMediaServer),
+			services.TrickplayConfig{
+				FFmpegPath:      cfg.FFmpegPath,
+				StorageDir:      cfg.MediaStorageDir,
+				PublicBaseURL:   cfg.MediaPublicBaseURL,
+				PollInterval:    time.Duration(cfg.TrickplayScanMinutes) * time.Minute,
+				IntervalSeconds: cfg.TrickplayIntervalSeconds,
+			},
+		).WithBlobStore(mediaBlobStore)
+		go trickplayWorker.Run(context.Background())
+	}
+
+	// Öffentliche Atom-Feeds; Gin kann ".atom" nicht im Muster abbilden, der Handler prüft die Endung.
+	router.GET("/feeds/releases.atom", releaseFeedHandler.Releases)
+	router.GET("/feeds/anime/:id", releaseFeedHandler.Anime)
+	router.GET("/feeds/fansubs/:slug", releaseFeedHandler.Fansub)
+
 	v1 := router.Group("/api/v1")
 	v1.POST("/auth/issue", authHandler.Issue)
 	v1.POST("/auth/refresh", authHandler.Refresh)
 	v1.POST("/auth/revoke", authMiddleware, authHandler.Revoke)
 	v1.POST("/auth/keycloak/backchannel-logout", appAuthHandler.HandleKeycloakBackchannelLogout)
+	// Jellyfin-Webhook-Plugin: Authentifizierung über X-Jellyfin-Webhook-Secret im Handler.
+	v1.POST("/jellyfin/webhook", adminContentHandler.ReceiveJellyfinWebhook)
 	v1.GET("/me", authMiddleware, appAuthHandler.GetCurrentUser)
 	v1.GET("/me/profile", authMiddleware, appAuthHandler.GetOwnProfile)
 	v1.PUT("/me/profile", authMiddleware, appAuthHandler.UpdateOwnProfile)
@@ -326,6 +464,9 @@ func main() {
 	v1.GET("/anime/:id/group/:groupId/release-media", groupPublicHandler.GetGroupReleaseMedia)
 	v1.GET("/anime/:id/group/:groupId/project-note", groupPublicHandler.GetGroupProjectNote)
 	v1.GET("/episode-versions/:versionId", fansubHandler.GetEpisodeVersionByID)
+	v1.GET("/episode-versions/:versionId/skip-markers", episodeSkipMarkersHandler.GetSkipMarkers)
+	v1.GET("/episode-versions/:versionId/chapters.vtt", episodeSkipMarkersHandler.GetChaptersVTT)
+	v1.GET("/episode-versions/:versionId/media-segments", episodeSkipMarkersHandler.GetMediaSegments)
 	v1.GET("/anime/:id/comments", commentHandler.ListByAnimeID)
 	v1.POST(
 		"/anime/:id/comments",
@@ -333,13 +474,62 @@ func main() {
 		middleware.CommentCreateRateLimitMiddleware(commentCreateLimiter),
 		commentHandler.CreateByAnimeID,
 	)
+	v1.GET("/episodes/:id/comments", commentHandler.ListByEpisodeID)
+	v1.POST(
+		"/episodes/:id/comments",
+		authMiddleware,
+		middleware.CommentCreateRateLimitMiddleware(commentCreateLimiter),
+		commentHandler.CreateByEpisodeID,
+	)
+	v1.GET("/release-versions/:id/comments", commentHandler.ListByReleaseVersionID)
+	v1.POST(
+		"/release-versions/:id/comments",
+		authMiddleware,
+		middleware.CommentCreateRateLimitMiddleware(commentCreateLimiter),
+		commentHandler.CreateByReleaseVersionID,
+	)
+	v1.GET("/fansubs/:id/comments", commentHandler.ListByFansubGroupID)
+	v1.POST(
+		"/fansubs/:id/comments",
+		authMiddleware,
+		middleware.CommentCreateRateLimitMiddleware(commentCreateLimiter),
+		commentHandler.CreateByFansubGroupID,
+	)
+	v1.PATCH("/comments/:id", authMiddleware, commentHandler.UpdateByID)
+	v1.DELETE("/comments/:id", authMiddleware, commentHandler.DeleteByID)
+	v1.GET("/comments/:id/revisions", commentHandler.ListRevisions)
+	v1.POST("/comments/:id/reports", authMiddleware, commentHandler.ReportByID)
+	v1.GET("/me/mentions", authMiddleware, commentHandler.ListMyMentions)
+	v1.GET("/me/notifications", authMiddleware, notificationHandler.List)
+	v1.GET("/me/notifications/unread-count", authMiddleware, notificationHandler.UnreadCount)
+	v1.POST("/me/notifications/read", authMiddleware, notificationHandler.MarkRead)
+	v1.POST("/me/notifications/:id/read", authMiddleware, notificationHandler.MarkReadByID)
+	v1.GET("/me/notification-preferences", authMiddleware, notificationHandler.GetPreferences)
+	v1.PUT("/me/notification-preferences", authMiddleware, notificationHandler.UpdatePreferences)
+	v1.GET("/me/digest-settings", authMiddleware, notificationDigestHandler.GetSettings)
+	v1.PUT("/me/digest-settings", authMiddleware, notificationDigestHandler.UpdateSettings)
+	v1.POST("/digest/unsubscribe", notificationDigestHandler.Unsubscribe)
+	v1.GET("/me/follows", authMiddleware, followHandler.ListFollows)
+	v1.POST("/me/follows/fansubs/:group", authMiddleware, followHandler.FollowFansubGroup)
+	v1.DELETE("/me/follows/fansubs/:group", authMiddleware, followHandler.UnfollowFansubGroup)
+	v1.POST("/me/follows/anime/:id", authMiddleware, followHandler.FollowAnime)
+	v1.DELETE("/me/follows/anime/:id", authMiddleware, followHandler.UnfollowAnime)
+	v1.GET("/me/feed", authMiddleware, followHandler.Feed)
 	v1.GET("/watchlist", authMiddleware, watchlistHandler.ListByUser)
 	v1.POST("/watchlist", authMiddleware, watchlistHandler.CreateByUser)
+	v1.POST("/watchlist/import/preview", authMiddleware, watchlistHandler.PreviewImport)
+	v1.POST("/watchlist/import", authMiddleware, watchlistHandler.ApplyImport)
+	v1.GET("/watchlist/export", authMiddleware, watchlistHandler.Export)
 	v1.GET(
 		"/watchlist/:anime_id",
 		authMiddleware,
 		watchlistHandler.GetByUserAndAnimeID,
 	)
+	v1.PATCH(
+		"/watchlist/:anime_id",
+		authMiddleware,
+		watchlistHandler.UpdateByUser,
+	)
 	v1.DELETE(
 		"/watchlist/:anime_id",
 		authMiddleware,
@@ -356,6 +546,18 @@ func main() {
 		authOptionalMiddleware,
 		episodePlaybackHandler.Play,
 	)
+	v1.GET(
+		"/episodes/:id/play/hls",
+		authOptionalMiddleware,
+		episodePlaybackHandler.PlayHLS,
+	)
+	// Segment- und Variant-URIs der umgeschriebenen Playlists; geschützt durch den signierten token-Parameter.
+	v1.GET("/episodes/:id/play/hls/resource", episodePlaybackHandler.PlayHLSResource)
+	v1.POST("/playback-sessions/:sessionId/heartbeat", authMiddleware, episodePlaybackHandler.HeartbeatPlaybackSession)
+	v1.DELETE("/playback-sessions/:sessionId", authMiddleware, episodePlaybackHandler.EndPlaybackSession)
+	v1.GET("/episodes/:id/progress", authMiddleware, episodePlaybackHandler.GetPlaybackProgress)
+	v1.PUT("/episodes/:id/progress", authMiddleware, episodePlaybackHandler.ReportPlaybackProgress)
+	v1.GET("/me/continue-watching", authMiddleware, watchlistHandler.ListContinueWatching)
 	v1.GET("/fansubs", fansubHandler.ListFansubs)
 	v1.GET("/fansub-slugs/:slug", fansubHandler.GetFansubBySlug)
 	v1.GET("/fansub-slugs/:slug/public-profile", fansubHandler.GetFansubPublicProfileBySlug)
@@ -385,6 +587,7 @@ func main() {
 		fansubHandler.StreamRelease,
 	)
 	v1.GET("/releases/:id/assets", releaseAssetsHandler.ListReleaseAssets)
+	v1.GET("/releases/:id/trickplay.vtt", releaseTrickplayHandler.GetReleaseTrickplayVTT)
 	v1.GET("/releases/:id/images", episodeVersionImagesHandler.ListReleaseImages)
 	histGroupMembersRepo := repository.NewHistGroupMembersRepository(dbPool)
 	histGroupMemberRolesRepo := repository.NewHistGroupMemberRolesRepository(dbPool)
@@ -404,7 +607,7 @@ func main() {
 	).WithBadgeService(badgeService).WithHistMembersRepo(histGroupMembersRepo).WithCoverageRepo(animeCoverageRepo)
 	groupHistoryHandler := handlers.NewFansubGroupHistoryHandler(fansubGroupHistoryRepo).
 		WithPermissionSvc(permissionSvc)
-	reviewHandler := handlers.NewContributionReviewHandler(animeContributionsRepo, permissionSvc, auditLogRepo)
+	reviewHandler := handlers.NewContributionReviewHandler(animeContributionsRepo, permissionSvc, auditLogRepo).WithNotifications(notificationSvc)
 	defaultCrewRepo := repository.NewFansubDefaultCrewRepository(dbPool)
 	defaultCrewHandler := handlers.NewFansubDefaultCrewHandler(defaultCrewRepo, animeContributionsRepo, permissionSvc, auditLogRepo)
 	// Phase 78: Gruppenmedien-Review — GET-Liste + PATCH Sichtbarkeit/Reviewstatus (Lock K/G/D-08/D-09)
@@ -414,6 +617,8 @@ func main() {
 	adminUsersHandler := handlers.NewAdminUsersHandler(adminUsersRepo, authzRepo, auditLogRepo)
 	// Phase 87: Capability-Matrix CRUD (requirePlatformAdminIdentity im Handler — D-08)
 	adminCapabilityHandler := handlers.NewAdminCapabilityHandler(authzRepo, authzRepo, permissionSvc, auditLogRepo)
+	adminMailOutboxHandler := handlers.NewAdminMailOutboxHandler(authzRepo, mailOutboxRepo, auditLogRepo)
+	fansubWebhookHandler := handlers.NewFansubGroupWebhookHandler(webhookRepo, permissionSvc, auditLogRepo)
 	// Phase 95-02: Assignable Gruppenrollen-Liste (D-12)
 	adminGroupRolesHandler := handlers.NewAdminGroupRolesHandler(authzRepo)
 	registerAdminRoutes(v1, authMiddleware, adminRouteHandlers{
@@ -434,7 +639,10 @@ func main() {
 		defaultCrewHandler:            defaultCrewHandler,
 		adminUsersHandler:             adminUsersHandler,
 		adminCapabilityHandler:        adminCapabilityHandler,
+		adminMailOutboxHandler:        adminMailOutboxHandler,
+		fansubWebhookHandler:          fansubWebhookHandler,
 		adminGroupRolesHandler:        adminGroupRolesHandler,
+		commentHandler:                commentHandler,
 	})
 	memberBadgesHandler := handlers.NewMemberBadgesHandler(badgeRepo)
 	archiveRepo := repository.NewMemberArchiveRepository(dbPool)
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HLSResourceGrantClaims enthält die Nutzinformationen eines HLS-Ressourcen-Grants. Ein solcher
+// Grant steckt in jeder umgeschriebenen Playlist-URI und erlaubt bis zum Ablauf wiederholte
+// Abrufe genau dieser Upstream-Ressource (Variant-Playlist, Segment, Schlüssel).
+type HLSResourceGrantClaims struct {
+	EpisodeID   int64  // ID der Episode, deren Playlist die URI enthielt
+	Principal   string // Rate-Limit-Prinzipal des berechtigten Zuschauers (z. B. "user:2")
+	UpstreamURL string // absolute Upstream-URL ohne API-Schlüssel
+	ExpiresAt   int64  // Unix-Zeitstempel des Ablaufdatums
+	SessionID   string // optionale Playback-Session, die Segmentabrufe am Leben halten
+}
+
+type hlsResourceGrantPayload struct {
+	EpisodeID   int64  `json:"eid"`
+	Principal   string `json:"sub"`
+	UpstreamURL string `json:"u"`
+	ExpiresAt   int64  `json:"exp"`
+	SessionID   string `json:"sid,omitempty"`
+}
+
+// CreateHLSResourceGrant erzeugt ein HMAC-signiertes Token für eine einzelne Upstream-Ressource
+// einer HLS-Playlist. Der Ablaufzeitpunkt wird übernommen, damit umgeschriebene Variant-Playlists
+// die Gültigkeit des Master-Abrufs nicht verlängern.
+func CreateHLSResourceGrant(claims HLSResourceGrantClaims, secret string) (string, error) {
+	trimmedSecret := strings.TrimSpace(secret)
+	if claims.EpisodeID <= 0 || strings.TrimSpace(claims.Principal) == "" || claims.ExpiresAt <= 0 || trimmedSecret == "" {
+		return "", ErrReleaseGrantPayload
+	}
+	parsed, err := url.Parse(claims.UpstreamURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", ErrReleaseGrantPayload
+	}
+
+	payloadBytes, err := json.Marshal(hlsResourceGrantPayload{
+		EpisodeID:   claims.EpisodeID,
+		Principal:   claims.Principal,
+		UpstreamURL: claims.UpstreamURL,
+		ExpiresAt:   claims.ExpiresAt,
+		SessionID:   claims.SessionID,
+	})
+	if err != nil {
+		return "", ErrReleaseGrantPayload
+	}
+
+	payloadSegment := base64.RawURLEncoding.EncodeToString(payloadBytes)
+	return payloadSegment + "." + signHLSResourceGrant(payloadSegment, trimmedSecret), nil
+}
+
+// ParseAndVerifyHLSResourceGrant prüft Signatur und Ablaufzeit eines HLS-Ressourcen-Grants und
+// gibt die enthaltenen Claims zurück.
+func ParseAndVerifyHLSResourceGrant(token string, secret string, now time.Time) (HLSResourceGrantClaims, error) {
+	trimmedSecret := strings.TrimSpace(secret)
+	if strings.TrimSpace(token) == "" || trimmedSecret == "" {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantFormat
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantFormat
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantFormat
+	}
+	expected, _ := base64.RawURLEncoding.DecodeString(signHLSResourceGrant(parts[0], trimmedSecret))
+	if !hmac.Equal(signature, expected) {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantSignature
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantFormat
+	}
+	var payload hlsResourceGrantPayload
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantPayload
+	}
+	if payload.EpisodeID <= 0 || payload.Principal == "" || payload.UpstreamURL == "" {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantPayload
+	}
+	if payload.ExpiresAt <= now.Unix() {
+		return HLSResourceGrantClaims{}, ErrReleaseGrantExpired
+	}
+
+	return HLSResourceGrantClaims{
+		EpisodeID:   payload.EpisodeID,
+		Principal:   payload.Principal,
+		UpstreamURL: payload.UpstreamURL,
+		ExpiresAt:   payload.ExpiresAt,
+		SessionID:   payload.SessionID,
+	}, nil
+}
+
+// signHLSResourceGrant trennt die Signatur per Präfix von Release-Stream-Grants, damit ein
+// Token der einen Art nie als Token der anderen Art gültig ist.
+func signHLSResourceGrant(payloadSegment string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("hls-resource."))
+	mac.Write([]byte(payloadSegment))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHLSResourceGrant_CreateAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	claims := HLSResourceGrantClaims{
+		EpisodeID:   42,
+		Principal:   "user:7",
+		UpstreamURL: "https://media.example/Videos/abc/hls1/main/0.ts?MediaSourceId=abc",
+		ExpiresAt:   now.Add(time.Hour).Unix(),
+	}
+	token, err := CreateHLSResourceGrant(claims, "secret")
+	if err != nil {
+		t.Fatalf("create grant: %v", err)
+	}
+
+	verified, err := ParseAndVerifyHLSResourceGrant(token, "secret", now)
+	if err != nil {
+		t.Fatalf("verify grant: %v", err)
+	}
+	if verified != claims {
+		t.Fatalf("unexpected claims: %+v", verified)
+	}
+
+	if _, err := ParseAndVerifyHLSResourceGrant(token, "other", now); err != ErrReleaseGrantSignature {
+		t.Fatalf("expected ErrReleaseGrantSignature, got %v", err)
+	}
+	if _, err := ParseAndVerifyHLSResourceGrant(token, "secret", now.Add(2*time.Hour)); err != ErrReleaseGrantExpired {
+		t.Fatalf("expected ErrReleaseGrantExpired, got %v", err)
+	}
+}
+
+func TestHLSResourceGrant_NotInterchangeableWithReleaseGrant(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	releaseToken, _, err := CreateReleaseStreamGrant(42, 7, "secret", now, time.Minute)
+	if err != nil {
+		t.Fatalf("create release grant: %v", err)
+	}
+	if _, err := ParseAndVerifyHLSResourceGrant(releaseToken, "secret", now); err != ErrReleaseGrantSignature {
+		t.Fatalf("expected release grant to be rejected, got %v", err)
+	}
+}
+
+func TestHLSResourceGrant_RejectsRelativeUpstream(t *testing.T) {
+	_, err := CreateHLSResourceGrant(HLSResourceGrantClaims{
+		EpisodeID:   42,
+		Principal:   "user:7",
+		UpstreamURL: "/Videos/abc/0.ts",
+		ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+	}, "secret")
+	if err != ErrReleaseGrantPayload {
+		t.Fatalf("expected ErrReleaseGrantPayload, got %v", err)
+	}
+}
@@ -24,15 +24,17 @@ var (
 // ReleaseStreamGrantClaims enthält die Nutzinformationen eines Release-Stream-Grants,
 // der kurzlebigen Zugriff auf einen einzelnen Release-Stream gewährt.
 type ReleaseStreamGrantClaims struct {
-	ReleaseID int64 // ID der freigegebenen Episodenversion
-	UserID    int64 // ID des berechtigten Benutzers
-	ExpiresAt int64 // Unix-Zeitstempel des Ablaufdatums
+	ReleaseID int64  // ID der freigegebenen Episodenversion
+	UserID    int64  // ID des berechtigten Benutzers
+	ExpiresAt int64  // Unix-Zeitstempel des Ablaufdatums
+	SessionID string // optionale Playback-Session, an die der Grant gebunden ist
 }
 
 type releaseStreamGrantPayload struct {
-	ReleaseID int64 `json:"rid"`
-	UserID    int64 `json:"uid"`
-	ExpiresAt int64 `json:"exp"`
+	ReleaseID int64  `json:"rid"`
+	UserID    int64  `json:"uid"`
+	ExpiresAt int64  `json:"exp"`
+	SessionID string `json:"sid,omitempty"`
 }
 
 // CreateReleaseStreamGrant erzeugt ein kurzlebiges, HMAC-signiertes Grant-Token
@@ -43,6 +45,20 @@ func CreateReleaseStreamGrant(
 	secret string,
 	now time.Time,
 	ttl time.Duration,
+) (string, int64, error) {
+	return CreateReleaseStreamGrantWithSession(releaseID, userID, "", secret, now, ttl)
+}
+
+// CreateReleaseStreamGrantWithSession erzeugt wie CreateReleaseStreamGrant ein Grant-Token,
+// bindet es aber zusätzlich an eine Playback-Session. Ein leerer sessionID entspricht
+// CreateReleaseStreamGrant.
+func CreateReleaseStreamGrantWithSession(
+	releaseID int64,
+	userID int64,
+	sessionID string,
+	secret string,
+	now time.Time,
+	ttl time.Duration,
 ) (string, int64, error) {
 	trimmedSecret := strings.TrimSpace(secret)
 	if releaseID <= 0 || userID <= 0 || trimmedSecret == "" || ttl <= 0 {
@@ -54,6 +70,7 @@ func CreateReleaseStreamGrant(
 		ReleaseID: releaseID,
 		UserID:    userID,
 		ExpiresAt: expiresAt,
+		SessionID: strings.TrimSpace(sessionID),
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -124,5 +141,6 @@ func ParseAndVerifyReleaseStreamGrant(
 		ReleaseID: payload.ReleaseID,
 		UserID:    payload.UserID,
 		ExpiresAt: payload.ExpiresAt,
+		SessionID: payload.SessionID,
 	}, nil
 }
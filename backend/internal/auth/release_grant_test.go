@@ -55,3 +55,19 @@ func TestReleaseStreamGrant_InvalidSignature(t *testing.T) {
 		t.Fatalf("expected ErrReleaseGrantSignature, got %v", err)
 	}
 }
+
+func TestReleaseStreamGrant_CarriesSessionID(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	token, _, err := CreateReleaseStreamGrantWithSession(42, 7, "session-1", "secret", now, time.Minute)
+	if err != nil {
+		t.Fatalf("create grant: %v", err)
+	}
+
+	claims, err := ParseAndVerifyReleaseStreamGrant(token, "secret", now)
+	if err != nil {
+		t.Fatalf("verify grant: %v", err)
+	}
+	if claims.SessionID != "session-1" {
+		t.Fatalf("unexpected session id: %q", claims.SessionID)
+	}
+}
@@ -0,0 +1,144 @@
+// Package blobstore kapselt die Ablage hochgeladener Mediendateien hinter einer gemeinsamen
+// Schnittstelle. Schlüssel sind relative, mit "/" getrennte Pfade unterhalb des Medienstamms
+// (z.B. "blobs/ab/ab12….png" oder "anime/7/cover/x.jpg"); ob sie im lokalen Dateisystem oder in
+// einem S3-kompatiblen Bucket liegen, entscheidet die Konfiguration.
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// KindLocal bezeichnet die Ablage im lokalen Dateisystem.
+	KindLocal = "local"
+	// KindS3 bezeichnet einen S3-kompatiblen Bucket (AWS S3, MinIO).
+	KindS3 = "s3"
+
+	// mediaURLPrefix ist der öffentliche Pfadpräfix, unter dem Dateien relativ zum Medienstamm
+	// in media_assets.file_path stehen (Theme-, Segment- und Trickplay-Assets).
+	mediaURLPrefix = "/media/"
+)
+
+// ErrNotExist wird (umhüllt) geliefert, wenn unter einem Schlüssel kein Objekt liegt.
+var ErrNotExist = fs.ErrNotExist
+
+// ObjectInfo beschreibt ein gespeichertes Objekt.
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Object ist ein geöffnetes Objekt. Seek erlaubt Range-Anfragen beim Ausliefern über
+// http.ServeContent.
+type Object interface {
+	io.ReadSeekCloser
+	Info() ObjectInfo
+}
+
+// BlobStore ist die gemeinsame Schnittstelle aller Ablagen. Delete auf fehlende Objekte ist
+// kein Fehler; Open und Stat liefern dann einen Fehler, für den errors.Is(err, ErrNotExist) gilt.
+type BlobStore interface {
+	// Kind liefert KindLocal oder KindS3.
+	Kind() string
+
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (Object, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL liefert eine zeitlich begrenzte Direkt-URL, sofern die Ablage vorsignierte
+	// Auslieferung unterstützt und aktiviert hat; sonst ok=false und die Datei wird durchgereicht.
+	SignedURL(ctx context.Context, key string) (url string, ok bool, err error)
+}
+
+// New erstellt die Ablage für kind. Bei KindS3 bleibt root als Lese-Fallback für Dateien
+// erhalten, die noch nicht in den Bucket migriert wurden (siehe FallbackStore).
+func New(kind string, root string, s3 S3Config) (BlobStore, error) {
+	switch NormalizeKind(kind) {
+	case "", KindLocal:
+		return NewLocalStore(root), nil
+	case KindS3:
+		bucket, err := NewS3Store(s3)
+		if err != nil {
+			return nil, err
+		}
+		return NewFallbackStore(bucket, NewLocalStore(root)), nil
+	default:
+		return nil, fmt.Errorf("blobstore: unknown storage backend %q", kind)
+	}
+}
+
+// NormalizeKind vereinheitlicht Schreibweise und Leerzeichen eines Ablage-Namens.
+func NormalizeKind(kind string) string {
+	return strings.ToLower(strings.TrimSpace(kind))
+}
+
+// KeyFromPath bildet einen in der Datenbank gespeicherten Dateipfad auf einen Schlüssel ab.
+// Unterstützt werden "/media/…"-Pfade sowie absolute oder relative Pfade unterhalb von root.
+// Pfade außerhalb des Medienstamms liefern ok=false.
+func KeyFromPath(root string, raw string) (string, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return "", false
+	}
+	normalized := strings.ReplaceAll(trimmed, "\\", "/")
+	if strings.HasPrefix(normalized, mediaURLPrefix) {
+		return CleanKey(strings.TrimPrefix(normalized, mediaURLPrefix))
+	}
+
+	base, err := filepath.Abs(strings.TrimSpace(root))
+	if err != nil || strings.TrimSpace(root) == "" {
+		return "", false
+	}
+	target, err := filepath.Abs(filepath.FromSlash(normalized))
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return "", false
+	}
+	return CleanKey(filepath.ToSlash(rel))
+}
+
+// PathForKey ist die Umkehrung von KeyFromPath für neu geschriebene Dateien: der Pfad, der in
+// media_assets.file_path landet, bleibt unabhängig von der Ablage root/key.
+func PathForKey(root string, key string) string {
+	return filepath.Join(root, filepath.FromSlash(key))
+}
+
+// CleanKey normalisiert einen Schlüssel (führende "/" entfallen) und lehnt leere oder aus dem
+// Stamm herausführende Schlüssel ab.
+func CleanKey(key string) (string, bool) {
+	trimmed := strings.TrimSpace(strings.ReplaceAll(key, "\\", "/"))
+	trimmed = strings.TrimLeft(trimmed, "/")
+	if trimmed == "" {
+		return "", false
+	}
+	cleaned := path.Clean(trimmed)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// LocalRoot liefert das Wurzelverzeichnis, wenn store Dateien im lokalen Dateisystem ablegt.
+func LocalRoot(store BlobStore) (string, bool) {
+	local, ok := store.(*LocalStore)
+	if !ok || local == nil {
+		return "", false
+	}
+	return local.root, true
+}
+
+func invalidKeyError(key string) error {
+	return fmt.Errorf("blobstore: invalid key %q", key)
+}
@@ -0,0 +1,103 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyFromPath(t *testing.T) {
+	root := t.TempDir()
+	cases := map[string]struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		"absolute below root": {raw: filepath.Join(root, "blobs", "ab", "ab.png"), want: "blobs/ab/ab.png", ok: true},
+		"media prefix":        {raw: "/media/anime/7/cover/x.jpg", want: "anime/7/cover/x.jpg", ok: true},
+		"outside root":        {raw: filepath.Join(filepath.Dir(root), "other.png")},
+		"media traversal":     {raw: "/media/../etc/passwd"},
+		"root itself":         {raw: root},
+		"empty":               {raw: "  "},
+	}
+	for name, tc := range cases {
+		got, ok := KeyFromPath(root, tc.raw)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s: KeyFromPath(%q) = %q, %v; want %q, %v", name, tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	store := NewLocalStore(root)
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "release-version/1/original.png", strings.NewReader("data"), 4, "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "release-version", "1", "original.png")); err != nil {
+		t.Fatalf("expected file below root: %v", err)
+	}
+
+	object, err := store.Open(ctx, "release-version/1/original.png")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(object)
+	object.Close()
+	if string(data) != "data" || object.Info().ContentType != "image/png" || object.Info().Size != 4 {
+		t.Fatalf("unexpected object %q %+v", data, object.Info())
+	}
+
+	if _, err := store.Stat(ctx, "release-version/1"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("expected directories to be treated as missing, got %v", err)
+	}
+	if _, ok, _ := store.SignedURL(ctx, "release-version/1/original.png"); ok {
+		t.Fatalf("local store must not sign urls")
+	}
+	if err := store.Delete(ctx, "release-version/1/original.png"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := store.Delete(ctx, "release-version/1/original.png"); err != nil {
+		t.Fatalf("delete of missing file must succeed: %v", err)
+	}
+	if err := store.Put(ctx, "../outside.png", strings.NewReader("x"), 1, ""); err == nil {
+		t.Fatalf("expected traversal key to be rejected")
+	}
+}
+
+func TestFallbackStoreReadsSecondaryUntilMigrated(t *testing.T) {
+	primary := NewLocalStore(t.TempDir())
+	secondary := NewLocalStore(t.TempDir())
+	store := NewFallbackStore(primary, secondary)
+	ctx := context.Background()
+
+	if err := secondary.Put(ctx, "legacy.png", strings.NewReader("old"), 3, ""); err != nil {
+		t.Fatalf("seed secondary: %v", err)
+	}
+	if info, err := store.Stat(ctx, "legacy.png"); err != nil || info.Size != 3 {
+		t.Fatalf("expected fallback stat, got %+v %v", info, err)
+	}
+
+	if err := store.Put(ctx, "new.png", strings.NewReader("new"), 3, ""); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, err := secondary.Stat(ctx, "new.png"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("writes must only go to primary, got %v", err)
+	}
+
+	if err := store.Delete(ctx, "legacy.png"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Stat(ctx, "legacy.png"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("expected delete in both stores, got %v", err)
+	}
+	if _, ok := LocalRoot(store); ok {
+		t.Fatalf("fallback store must not report a local root")
+	}
+}
@@ -0,0 +1,68 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// FallbackStore schreibt in primary und liest Objekte, die dort (noch) fehlen, aus secondary.
+// Gedacht für den Umstieg auf S3: Dateien, die noch im lokalen Medienverzeichnis liegen, bleiben
+// erreichbar, bis "migrate media-to-bucket" sie verschoben hat. Delete entfernt in beiden Ablagen.
+type FallbackStore struct {
+	primary   BlobStore
+	secondary BlobStore
+}
+
+// NewFallbackStore erstellt einen FallbackStore.
+func NewFallbackStore(primary BlobStore, secondary BlobStore) *FallbackStore {
+	return &FallbackStore{primary: primary, secondary: secondary}
+}
+
+// Kind liefert die Art der primären Ablage.
+func (s *FallbackStore) Kind() string { return s.primary.Kind() }
+
+// Primary liefert die Ablage, in die geschrieben wird.
+func (s *FallbackStore) Primary() BlobStore { return s.primary }
+
+// Put schreibt ausschließlich in die primäre Ablage.
+func (s *FallbackStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	return s.primary.Put(ctx, key, body, size, contentType)
+}
+
+// Open liest aus primary und nur bei ErrNotExist aus secondary.
+func (s *FallbackStore) Open(ctx context.Context, key string) (Object, error) {
+	object, err := s.primary.Open(ctx, key)
+	if errors.Is(err, ErrNotExist) {
+		return s.secondary.Open(ctx, key)
+	}
+	return object, err
+}
+
+// Stat prüft primary und nur bei ErrNotExist secondary.
+func (s *FallbackStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	info, err := s.primary.Stat(ctx, key)
+	if errors.Is(err, ErrNotExist) {
+		return s.secondary.Stat(ctx, key)
+	}
+	return info, err
+}
+
+// Delete entfernt das Objekt in beiden Ablagen.
+func (s *FallbackStore) Delete(ctx context.Context, key string) error {
+	if err := s.primary.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.secondary.Delete(ctx, key)
+}
+
+// SignedURL signiert nur Objekte, die bereits in primary liegen; alle anderen werden durchgereicht.
+func (s *FallbackStore) SignedURL(ctx context.Context, key string) (string, bool, error) {
+	if _, err := s.primary.Stat(ctx, key); err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return s.primary.SignedURL(ctx, key)
+}
@@ -0,0 +1,147 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore legt Objekte unterhalb eines Verzeichnisses im lokalen Dateisystem ab.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore erstellt einen LocalStore. Ein leeres Verzeichnis wird durch "./storage/media"
+// ersetzt.
+func NewLocalStore(root string) *LocalStore {
+	dir := strings.TrimSpace(root)
+	if dir == "" {
+		dir = "./storage/media"
+	}
+	return &LocalStore{root: dir}
+}
+
+// Kind liefert KindLocal.
+func (s *LocalStore) Kind() string { return KindLocal }
+
+// Path liefert den Dateipfad zu key.
+func (s *LocalStore) Path(key string) (string, error) {
+	cleaned, ok := CleanKey(key)
+	if !ok {
+		return "", invalidKeyError(key)
+	}
+	return PathForKey(s.root, cleaned), nil
+}
+
+// Put schreibt über eine temporäre Datei im Zielverzeichnis und ein Rename, damit ein
+// abgebrochener Upload keine halbe Datei hinterlässt.
+func (s *LocalStore) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	target, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("blobstore: create directory for %q: %w", key, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("blobstore: create temporary file for %q: %w", key, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("blobstore: write %q: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("blobstore: write %q: %w", key, err)
+	}
+	if err := os.Chmod(tmpPath, 0o644); err != nil {
+		return fmt.Errorf("blobstore: write %q: %w", key, err)
+	}
+	if err := os.Rename(tmpPath, target); err != nil {
+		return fmt.Errorf("blobstore: move %q into place: %w", key, err)
+	}
+	return nil
+}
+
+// Open öffnet die Datei zu key. Verzeichnisse gelten als nicht vorhanden.
+func (s *LocalStore) Open(_ context.Context, key string) (Object, error) {
+	target, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, &fs.PathError{Op: "open", Path: target, Err: ErrNotExist}
+	}
+	return &localObject{File: file, info: localObjectInfo(key, info)}, nil
+}
+
+// Stat liefert Größe und Änderungszeit der Datei zu key.
+func (s *LocalStore) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	target, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, &fs.PathError{Op: "stat", Path: target, Err: ErrNotExist}
+	}
+	objectInfo := localObjectInfo(key, info)
+	return &objectInfo, nil
+}
+
+// Delete entfernt die Datei zu key; eine fehlende Datei ist kein Fehler.
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	target, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("blobstore: delete %q: %w", key, err)
+	}
+	return nil
+}
+
+// SignedURL wird lokal nicht unterstützt; Dateien werden immer durchgereicht.
+func (s *LocalStore) SignedURL(context.Context, string) (string, bool, error) {
+	return "", false, nil
+}
+
+type localObject struct {
+	*os.File
+	info ObjectInfo
+}
+
+func (o *localObject) Info() ObjectInfo { return o.info }
+
+func localObjectInfo(key string, info fs.FileInfo) ObjectInfo {
+	cleaned, _ := CleanKey(key)
+	return ObjectInfo{
+		Key:         cleaned,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(cleaned)),
+		ModTime:     info.ModTime(),
+	}
+}
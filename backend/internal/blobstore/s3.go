@@ -0,0 +1,348 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region     = "us-east-1"
+	defaultS3Timeout    = 5 * time.Minute
+	s3ErrorBodyMaxBytes = 4096
+)
+
+// S3Config konfiguriert einen S3Store. Für MinIO genügen Endpoint, Bucket, Zugangsdaten und
+// PathStyle=true.
+type S3Config struct {
+	// Endpoint ist die Basis-URL, z.B. "http://minio:9000" oder "https://s3.eu-central-1.amazonaws.com".
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Prefix wird jedem Schlüssel vorangestellt, z.B. "media/".
+	Prefix string
+	// PathStyle adressiert Objekte als {endpoint}/{bucket}/{key} statt {bucket}.{host}/{key}.
+	PathStyle bool
+	// PresignTTL > 0 aktiviert vorsignierte Direkt-URLs; 0 reicht Dateien über das Backend durch.
+	PresignTTL time.Duration
+	HTTPClient *http.Client
+}
+
+// S3Store legt Objekte in einem S3-kompatiblen Bucket ab.
+type S3Store struct {
+	endpoint    *url.URL
+	bucket      string
+	prefix      string
+	pathStyle   bool
+	presignTTL  time.Duration
+	credentials sigV4Credentials
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewS3Store prüft die Konfiguration und erstellt einen S3Store. Es findet kein Netzwerkzugriff statt.
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	rawEndpoint := strings.TrimRight(strings.TrimSpace(cfg.Endpoint), "/")
+	endpoint, err := url.Parse(rawEndpoint)
+	if rawEndpoint == "" || err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("blobstore: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	bucket := strings.TrimSpace(cfg.Bucket)
+	if bucket == "" {
+		return nil, errors.New("blobstore: s3 bucket is required")
+	}
+	if strings.TrimSpace(cfg.AccessKeyID) == "" || strings.TrimSpace(cfg.SecretAccessKey) == "" {
+		return nil, errors.New("blobstore: s3 access key id and secret access key are required")
+	}
+	if cfg.PresignTTL < 0 || cfg.PresignTTL > 7*24*time.Hour {
+		return nil, errors.New("blobstore: s3 presign ttl must be between 0 and 7 days")
+	}
+
+	region := strings.TrimSpace(cfg.Region)
+	if region == "" {
+		region = defaultS3Region
+	}
+	prefix := strings.Trim(strings.TrimSpace(cfg.Prefix), "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultS3Timeout}
+	}
+
+	return &S3Store{
+		endpoint:   &url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host},
+		bucket:     bucket,
+		prefix:     prefix,
+		pathStyle:  cfg.PathStyle,
+		presignTTL: cfg.PresignTTL,
+		credentials: sigV4Credentials{
+			accessKeyID:     strings.TrimSpace(cfg.AccessKeyID),
+			secretAccessKey: strings.TrimSpace(cfg.SecretAccessKey),
+			region:          region,
+			service:         "s3",
+		},
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+// Kind liefert KindS3.
+func (s *S3Store) Kind() string { return KindS3 }
+
+// Bucket liefert den Namen des Buckets.
+func (s *S3Store) Bucket() string { return s.bucket }
+
+// Put lädt body als Objekt hoch. Bei unbekannter Größe (size < 0) wird body vorher gepuffert,
+// da S3 eine Content-Length verlangt.
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	target, cleaned, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("blobstore: read %q: %w", cleaned, err)
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), io.NopCloser(body))
+	if err != nil {
+		return fmt.Errorf("blobstore: build put %q: %w", cleaned, err)
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if strings.TrimSpace(contentType) != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req, sigV4UnsignedBody)
+	if err != nil {
+		return fmt.Errorf("blobstore: put %q: %w", cleaned, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3ResponseError(http.MethodPut, cleaned, resp)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Open liefert ein Objekt, das erst beim Lesen (und nach jedem Seek) einen GET mit passendem
+// Range-Header absetzt.
+func (s *S3Store) Open(ctx context.Context, key string) (Object, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Object{ctx: ctx, store: s, info: *info}, nil
+}
+
+// Stat liefert Größe, Content-Type und Änderungszeit über einen HEAD-Aufruf.
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	target, cleaned, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: build head %q: %w", cleaned, err)
+	}
+	resp, err := s.do(req, sigV4EmptyBodyHash)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: head %q: %w", cleaned, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3ResponseError(http.MethodHead, cleaned, resp)
+	}
+
+	info := &ObjectInfo{Key: cleaned, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modified
+	}
+	return info, nil
+}
+
+// Delete entfernt das Objekt; S3 meldet fehlende Objekte ebenfalls mit 204.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	target, cleaned, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, target.String(), nil)
+	if err != nil {
+		return fmt.Errorf("blobstore: build delete %q: %w", cleaned, err)
+	}
+	resp, err := s.do(req, sigV4EmptyBodyHash)
+	if err != nil {
+		return fmt.Errorf("blobstore: delete %q: %w", cleaned, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	default:
+		return s3ResponseError(http.MethodDelete, cleaned, resp)
+	}
+}
+
+// SignedURL liefert eine vorsignierte GET-URL, sofern PresignTTL gesetzt ist.
+func (s *S3Store) SignedURL(_ context.Context, key string) (string, bool, error) {
+	if s.presignTTL <= 0 {
+		return "", false, nil
+	}
+	target, _, err := s.objectURL(key)
+	if err != nil {
+		return "", false, err
+	}
+	return s.credentials.presignURL(http.MethodGet, target, s.presignTTL, s.now()), true, nil
+}
+
+// objectURL baut die Objekt-URL. RawPath trägt die SigV4-Kodierung, damit die gesendete
+// Anfragezeile exakt dem signierten kanonischen Pfad entspricht.
+func (s *S3Store) objectURL(key string) (*url.URL, string, error) {
+	cleaned, ok := CleanKey(key)
+	if !ok {
+		return nil, "", invalidKeyError(key)
+	}
+	objectPath := "/" + s.prefix + cleaned
+	host := s.endpoint.Host
+	if s.pathStyle {
+		objectPath = "/" + s.bucket + objectPath
+	} else {
+		host = s.bucket + "." + host
+	}
+	return &url.URL{
+		Scheme:  s.endpoint.Scheme,
+		Host:    host,
+		Path:    objectPath,
+		RawPath: sigV4EscapePath(objectPath),
+	}, cleaned, nil
+}
+
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	s.credentials.signRequest(req, payloadHash, s.now())
+	return s.client.Do(req)
+}
+
+type s3ErrorBody struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3ResponseError übersetzt 404 in ErrNotExist und liefert sonst Status und S3-Fehlercode.
+func s3ResponseError(method string, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyMaxBytes))
+	var parsed s3ErrorBody
+	_ = xml.Unmarshal(body, &parsed)
+	if resp.StatusCode == http.StatusNotFound && parsed.Code != "NoSuchBucket" {
+		return fmt.Errorf("blobstore: %s %q: %w", strings.ToLower(method), key, ErrNotExist)
+	}
+	detail := strings.TrimSpace(parsed.Code)
+	if parsed.Message != "" {
+		detail = strings.TrimSpace(detail + " " + parsed.Message)
+	}
+	if detail == "" {
+		detail = http.StatusText(resp.StatusCode)
+	}
+	return fmt.Errorf("blobstore: %s %q: status %d: %s", strings.ToLower(method), key, resp.StatusCode, detail)
+}
+
+// s3Object liest ein Objekt über Range-GETs; Seek schließt nur den laufenden Body.
+type s3Object struct {
+	ctx    context.Context
+	store  *S3Store
+	info   ObjectInfo
+	offset int64
+	body   io.ReadCloser
+}
+
+func (o *s3Object) Info() ObjectInfo { return o.info }
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		body, err := o.open()
+		if err != nil {
+			return 0, err
+		}
+		o.body = body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = o.offset + offset
+	case io.SeekEnd:
+		next = o.info.Size + offset
+	default:
+		return 0, errors.New("blobstore: invalid seek whence")
+	}
+	if next < 0 {
+		return 0, errors.New("blobstore: negative seek position")
+	}
+	if next != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = next
+	return next, nil
+}
+
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+func (o *s3Object) open() (io.ReadCloser, error) {
+	target, _, err := o.store.objectURL(o.info.Key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(o.ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: build get %q: %w", o.info.Key, err)
+	}
+	if o.offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(o.offset, 10)+"-")
+	}
+	resp, err := o.store.do(req, sigV4EmptyBodyHash)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: get %q: %w", o.info.Key, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		return nil, s3ResponseError(http.MethodGet, o.info.Key, resp)
+	}
+	return resp.Body, nil
+}
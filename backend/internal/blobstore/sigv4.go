@@ -0,0 +1,187 @@
+package blobstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4, beschränkt auf das, was S3-kompatible Ablagen benötigen:
+// Header-Signatur für API-Aufrufe und Query-Signatur für vorsignierte GET-URLs.
+const (
+	sigV4Algorithm     = "AWS4-HMAC-SHA256"
+	sigV4DateFormat    = "20060102T150405Z"
+	sigV4UnsignedBody  = "UNSIGNED-PAYLOAD"
+	sigV4EmptyBodyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+type sigV4Credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	region          string
+	service         string
+}
+
+// signRequest setzt X-Amz-Date und Authorization. Signiert werden host sowie alle bereits
+// gesetzten x-amz-*-Header und Content-Type.
+func (c sigV4Credentials) signRequest(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headerNames, canonicalHeaders := sigV4CanonicalHeaders(req)
+	signedHeaders := strings.Join(headerNames, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4EscapePath(req.URL.Path),
+		sigV4CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := c.scope(now)
+	signature := c.signature(now, sigV4StringToSign(amzDate, scope, canonicalRequest))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, c.accessKeyID, scope, signedHeaders, signature))
+}
+
+// presignURL ergänzt target um die X-Amz-*-Query-Parameter einer vorsignierten Anfrage.
+func (c sigV4Credentials) presignURL(method string, target *url.URL, expires time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	scope := c.scope(now)
+
+	query := target.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", c.accessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalQuery := sigV4CanonicalQuery(query)
+	canonicalRequest := strings.Join([]string{
+		method,
+		sigV4EscapePath(target.Path),
+		canonicalQuery,
+		"host:" + target.Host + "\n",
+		"host",
+		sigV4UnsignedBody,
+	}, "\n")
+	signature := c.signature(now, sigV4StringToSign(amzDate, scope, canonicalRequest))
+
+	signed := *target
+	signed.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+	return signed.String()
+}
+
+func (c sigV4Credentials) scope(now time.Time) string {
+	return strings.Join([]string{now.UTC().Format("20060102"), c.region, c.service, "aws4_request"}, "/")
+}
+
+func (c sigV4Credentials) signature(now time.Time, stringToSign string) string {
+	key := sigV4HMAC([]byte("AWS4"+c.secretAccessKey), now.UTC().Format("20060102"))
+	key = sigV4HMAC(key, c.region)
+	key = sigV4HMAC(key, c.service)
+	key = sigV4HMAC(key, "aws4_request")
+	return hex.EncodeToString(sigV4HMAC(key, stringToSign))
+}
+
+func sigV4StringToSign(amzDate string, scope string, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	return strings.Join([]string{sigV4Algorithm, amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+}
+
+func sigV4HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sigV4CanonicalHeaders(req *http.Request) ([]string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, headerValues := range req.Header {
+		lower := strings.ToLower(name)
+		if !strings.HasPrefix(lower, "x-amz-") && lower != "content-type" {
+			continue
+		}
+		trimmed := make([]string, 0, len(headerValues))
+		for _, value := range headerValues {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		values[lower] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteByte(':')
+		builder.WriteString(values[name])
+		builder.WriteByte('\n')
+	}
+	return names, builder.String()
+}
+
+func sigV4CanonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	pairs := make([][2]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{sigV4Escape(key), sigV4Escape(value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	encoded := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		encoded = append(encoded, pair[0]+"="+pair[1])
+	}
+	return strings.Join(encoded, "&")
+}
+
+// sigV4EscapePath kodiert jedes Pfadsegment nach RFC 3986; "/" bleibt erhalten.
+func sigV4EscapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = sigV4Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sigV4Escape(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		b := value[i]
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+	return builder.String()
+}
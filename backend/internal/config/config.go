@@ -23,11 +23,16 @@ type Config struct {
 	EmbyAPIKey                   string   // API-Schlüssel für den Emby-Mediaserver
 	EmbyStreamBaseURL            string   // Basis-URL des Emby-Streamingendpunkts
 	EmbyStreamPathTemplate       string   // Pfadvorlage für Emby-Videostreams
+	EmbyHLSPathTemplate          string   // Pfadvorlage für die HLS-Master-Playlist (Emby/Jellyfin)
 	EmbyAllowedAnimeIDs          []int64  // Erlaubte Anime-IDs für den Emby-Zugriff
 	JellyfinAPIKey               string   // API-Schlüssel für den Jellyfin-Mediaserver
 	JellyfinBaseURL              string   // Basis-URL des Jellyfin-Servers
 	JellyfinStreamPathTemplate   string   // Pfadvorlage für Jellyfin-Videostreams
 	JellyfinAllowedLibraryIDs    []string // Optionale Whitelist von Jellyfin-Bibliotheks-IDs (JELLYFIN_ALLOWED_LIBRARY_IDS, kommagetrennt)
+	JellyfinWebhookSecret        string   // Gemeinsames Secret des Jellyfin-Webhook-Plugins (Header X-Jellyfin-Webhook-Secret, leer = deaktiviert)
+	JellyfinReconcileMinutes     int      // Abstand des Bibliotheksabgleichs Katalog/Jellyfin in Minuten (0 = deaktiviert)
+	MediaServerCatalog           string   // Media-Server für Intake, Sync, Editor und Backdrops: "jellyfin" (Standard) oder "emby"
+	MediaServerPlayback          string   // Media-Server für die Episoden-Wiedergabe: "emby" (Standard) oder "jellyfin"
 	AuthAccessTokenTTLSeconds    int      // Gültigkeitsdauer des Access-Tokens in Sekunden
 	AuthRefreshTokenTTLSeconds   int      // Gültigkeitsdauer des Refresh-Tokens in Sekunden
 	RedisAddr                    string   // Redis-Serveradresse (Host:Port)
@@ -42,10 +47,30 @@ type Config struct {
 	ReleaseStreamGrantTTLSeconds int      // Gültigkeitsdauer von Release-Stream-Grants in Sekunden
 	EpisodePlaybackRateLimit     int      // Maximale Wiedergabeanfragen pro Zeitfenster
 	EpisodePlaybackRateWindowSec int      // Länge des Rate-Limit-Zeitfensters in Sekunden
-	EpisodePlaybackMaxConcurrent int      // Maximale gleichzeitige Streams pro Nutzer
+	EpisodePlaybackHLSRateLimit  int      // Maximale HLS-Segment-/Playlist-Abrufe je Session pro Zeitfenster
+	EpisodePlaybackMaxConcurrent int      // Maximale gleichzeitige Playback-Sessions über alle Instanzen
+	EpisodePlaybackMaxPerUser    int      // Maximale gleichzeitige Playback-Sessions pro Nutzer
+	EpisodePlaybackMaxInstance   int      // Maximale gleichzeitig durchgereichte Streams pro Instanz
+	EpisodePlaybackSessionTTLSec int      // Ablauf einer Playback-Session ohne Heartbeat in Sekunden
+	PlaybackGrantBlockThreshold  int      // Sicherheitsereignisse (warning/critical) bis zur Grant-Sperre; 0 deaktiviert
+	PlaybackGrantBlockWindowMin  int      // Zeitfenster der Sperrregel in Minuten
+	PlaybackGrantBlockMinutes    int      // Dauer einer automatischen Grant-Sperre in Minuten
+	EpisodePlaybackHLSTTLSeconds int      // Gültigkeit der umgeschriebenen HLS-URIs in Sekunden
 	MediaStorageDir              string   // Lokales Verzeichnis für hochgeladene Mediendateien
 	MediaPublicBaseURL           string   // Öffentliche Basis-URL für die Medienauslieferung
+	MediaStorageBackend          string   // Ablage für Mediendateien: "local" (Standard, MEDIA_STORAGE_DIR) oder "s3"
+	MediaS3Endpoint              string   // Endpoint des S3-kompatiblen Speichers (z.B. http://minio:9000)
+	MediaS3Region                string   // S3-Region (Standard: us-east-1)
+	MediaS3Bucket                string   // Bucket für Mediendateien
+	MediaS3AccessKeyID           string   // S3-Zugangsschlüssel
+	MediaS3SecretAccessKey       string   // S3-Geheimschlüssel
+	MediaS3Prefix                string   // Optionaler Schlüsselpräfix innerhalb des Buckets
+	MediaS3PathStyle             bool     // Path-Style-Adressierung (für MinIO erforderlich)
+	MediaS3PresignSeconds        int      // Gültigkeit vorsignierter Medien-URLs in Sekunden (0 = Auslieferung über das Backend)
 	FFmpegPath                   string   // Dateipfad zur FFmpeg-Binärdatei
+	LocalMediaRoot               string   // Wurzelverzeichnis für Episodenversionen mit Media-Provider "local" (leer = deaktiviert)
+	TrickplayScanMinutes         int      // Abstand der Trickplay-Erzeugung für Release-Varianten in Minuten (0 = deaktiviert)
+	TrickplayIntervalSeconds     int      // Abstand zwischen zwei Vorschaubildern in Sekunden
 	TMDBAPIKey                   string   // API-Schlüssel für The Movie Database (TMDB)
 	FanartAPIKey                 string   // API-Schlüssel für fanart.tv
 	// SMTP-Mailer-Konfiguration
@@ -58,6 +83,25 @@ type Config struct {
 	SMTPFromName  string // Absender-Anzeigename
 	SMTPStartTLS  bool   // STARTTLS für SMTP-Verbindung verwenden
 	AppPublicURL  string // Öffentliche Basis-URL der App (für absolute Links in Mails)
+	// Mail-Outbox-Worker
+	MailOutboxPollSeconds        int // Abstand zwischen zwei Outbox-Durchläufen in Sekunden
+	MailOutboxBatchSize          int // Mails pro Durchlauf
+	MailOutboxBaseBackoffSeconds int // Wartezeit nach dem ersten Fehlversuch in Sekunden (verdoppelt sich je Versuch)
+	MailOutboxMaxBackoffSeconds  int // Obergrenze der Wartezeit zwischen zwei Versuchen in Sekunden
+	DigestPollSeconds            int // Abstand zwischen zwei Digest-Durchläufen in Sekunden
+	DigestSendHour               int // Stunde (UTC, 0-23), zu der Digests versendet werden
+	DigestWeeklyDay              int // Wochentag des Wochen-Digests (0 = Sonntag, 1 = Montag, ...)
+	// Webhook-Worker für ausgehende Gruppen-Webhooks
+	WebhookPollSeconds        int // Abstand zwischen zwei Zustell-Durchläufen in Sekunden
+	WebhookBaseBackoffSeconds int // Wartezeit nach dem ersten Fehlversuch in Sekunden (verdoppelt sich je Versuch)
+	WebhookMaxBackoffSeconds  int // Obergrenze der Wartezeit zwischen zwei Versuchen in Sekunden
+	// Kommentar-Moderation: Heuristiken, die verdächtige Kommentare zur Prüfung zurückhalten
+	CommentSpamMaxLinks        int      // Maximale Anzahl Links pro Kommentar (0 = keine Prüfung)
+	CommentSpamRepeatWindowSec int      // Zeitfenster für wiederholte identische Kommentare in Sekunden
+	CommentSpamRepeatThreshold int      // Identische Kommentare im Zeitfenster, ab denen zurückgehalten wird
+	CommentSpamNewAccountHours int      // Konten jünger als diese Stundenzahl gelten als neu (0 = aus)
+	CommentSpamBlockedWords    []string // Gesperrte Wörter (Komma-getrennt, ohne Groß-/Kleinschreibung)
+	CommentReportHideThreshold int      // Offene Meldungen, ab denen ein Kommentar zur Prüfung ausgeblendet wird
 	// CORSAllowedOrigins listet die erlaubten Cross-Origin-Quellen (CORS_ALLOWED_ORIGINS,
 	// Komma-getrennt). Default: AppPublicURL. Ersetzt den frueheren Wildcard '*'.
 	CORSAllowedOrigins []string
@@ -84,11 +128,16 @@ func Load() Config {
 		EmbyAPIKey:                   strings.TrimSpace(os.Getenv("EMBY_API_KEY")),
 		EmbyStreamBaseURL:            strings.TrimSpace(os.Getenv("EMBY_STREAM_BASE_URL")),
 		EmbyStreamPathTemplate:       getEnv("EMBY_STREAM_PATH_TEMPLATE", "/Videos/%s/stream"),
+		EmbyHLSPathTemplate:          getEnv("EMBY_HLS_PATH_TEMPLATE", "/Videos/%s/master.m3u8"),
 		EmbyAllowedAnimeIDs:          getEnvInt64List("EMBY_ALLOWED_ANIME_IDS", nil),
 		JellyfinAPIKey:               strings.TrimSpace(os.Getenv("JELLYFIN_API_KEY")),
 		JellyfinBaseURL:              strings.TrimSpace(os.Getenv("JELLYFIN_BASE_URL")),
 		JellyfinStreamPathTemplate:   getEnv("JELLYFIN_STREAM_PATH_TEMPLATE", "/Videos/%s/stream"),
 		JellyfinAllowedLibraryIDs:    getEnvStringList("JELLYFIN_ALLOWED_LIBRARY_IDS"),
+		JellyfinWebhookSecret:        strings.TrimSpace(os.Getenv("JELLYFIN_WEBHOOK_SECRET")),
+		JellyfinReconcileMinutes:     getEnvInt("JELLYFIN_RECONCILE_MINUTES", 360),
+		MediaServerCatalog:           strings.TrimSpace(getEnv("MEDIA_SERVER_CATALOG", "jellyfin")),
+		MediaServerPlayback:          strings.TrimSpace(getEnv("MEDIA_SERVER_PLAYBACK", "emby")),
 		AuthAccessTokenTTLSeconds:    getEnvInt("AUTH_ACCESS_TOKEN_TTL_SECONDS", 900),
 		AuthRefreshTokenTTLSeconds:   getEnvInt("AUTH_REFRESH_TOKEN_TTL_SECONDS", 604800),
 		RedisAddr:                    getEnv("REDIS_ADDR", "localhost:6379"),
@@ -103,10 +152,30 @@ func Load() Config {
 		ReleaseStreamGrantTTLSeconds: getEnvInt("RELEASE_STREAM_GRANT_TTL_SECONDS", 120),
 		EpisodePlaybackRateLimit:     getEnvInt("EPISODE_PLAYBACK_RATE_LIMIT", 30),
 		EpisodePlaybackRateWindowSec: getEnvInt("EPISODE_PLAYBACK_RATE_WINDOW_SECONDS", 60),
+		EpisodePlaybackHLSRateLimit:  getEnvInt("EPISODE_PLAYBACK_HLS_RATE_LIMIT", 600),
 		EpisodePlaybackMaxConcurrent: getEnvInt("EPISODE_PLAYBACK_MAX_CONCURRENT_STREAMS", 12),
+		EpisodePlaybackMaxPerUser:    getEnvInt("EPISODE_PLAYBACK_MAX_SESSIONS_PER_USER", 2),
+		EpisodePlaybackMaxInstance:   getEnvInt("EPISODE_PLAYBACK_MAX_INSTANCE_STREAMS", 24),
+		EpisodePlaybackSessionTTLSec: getEnvInt("EPISODE_PLAYBACK_SESSION_TIMEOUT_SECONDS", 90),
+		PlaybackGrantBlockThreshold:  getEnvInt("PLAYBACK_GRANT_BLOCK_THRESHOLD", 10),
+		PlaybackGrantBlockWindowMin:  getEnvInt("PLAYBACK_GRANT_BLOCK_WINDOW_MINUTES", 10),
+		PlaybackGrantBlockMinutes:    getEnvInt("PLAYBACK_GRANT_BLOCK_MINUTES", 30),
+		EpisodePlaybackHLSTTLSeconds: getEnvInt("EPISODE_PLAYBACK_HLS_TTL_SECONDS", 14400),
 		MediaStorageDir:              strings.TrimSpace(getEnv("MEDIA_STORAGE_DIR", "./storage/media")),
 		MediaPublicBaseURL:           strings.TrimSpace(getEnv("MEDIA_PUBLIC_BASE_URL", "http://localhost:8092")),
+		MediaStorageBackend:          strings.ToLower(strings.TrimSpace(getEnv("MEDIA_STORAGE_BACKEND", "local"))),
+		MediaS3Endpoint:              strings.TrimSpace(os.Getenv("MEDIA_S3_ENDPOINT")),
+		MediaS3Region:                strings.TrimSpace(getEnv("MEDIA_S3_REGION", "us-east-1")),
+		MediaS3Bucket:                strings.TrimSpace(os.Getenv("MEDIA_S3_BUCKET")),
+		MediaS3AccessKeyID:           strings.TrimSpace(os.Getenv("MEDIA_S3_ACCESS_KEY_ID")),
+		MediaS3SecretAccessKey:       os.Getenv("MEDIA_S3_SECRET_ACCESS_KEY"),
+		MediaS3Prefix:                strings.TrimSpace(os.Getenv("MEDIA_S3_PREFIX")),
+		MediaS3PathStyle:             getEnvBool("MEDIA_S3_PATH_STYLE", true),
+		MediaS3PresignSeconds:        getEnvInt("MEDIA_S3_PRESIGN_SECONDS", 0),
 		FFmpegPath:                   strings.TrimSpace(getEnv("FFMPEG_PATH", "/usr/bin/ffmpeg")),
+		LocalMediaRoot:               strings.TrimSpace(os.Getenv("LOCAL_MEDIA_ROOT")),
+		TrickplayScanMinutes:         getEnvInt("TRICKPLAY_SCAN_MINUTES", 15),
+		TrickplayIntervalSeconds:     getEnvInt("TRICKPLAY_INTERVAL_SECONDS", 10),
 		TMDBAPIKey:                   strings.TrimSpace(os.Getenv("TMDB_API_KEY")),
 		FanartAPIKey:                 strings.TrimSpace(os.Getenv("FANART_API_KEY")),
 		SMTPEnabled:                  getEnvBool("SMTP_ENABLED", false),
@@ -118,6 +187,22 @@ func Load() Config {
 		SMTPFromName:                 getEnv("SMTP_FROM_NAME", "Team4s"),
 		SMTPStartTLS:                 getEnvBool("SMTP_STARTTLS", false),
 		AppPublicURL:                 strings.TrimSpace(getEnv("APP_PUBLIC_URL", "http://localhost:3002")),
+		MailOutboxPollSeconds:        getEnvInt("MAIL_OUTBOX_POLL_SECONDS", 15),
+		MailOutboxBatchSize:          getEnvInt("MAIL_OUTBOX_BATCH_SIZE", 20),
+		MailOutboxBaseBackoffSeconds: getEnvInt("MAIL_OUTBOX_BASE_BACKOFF_SECONDS", 60),
+		MailOutboxMaxBackoffSeconds:  getEnvInt("MAIL_OUTBOX_MAX_BACKOFF_SECONDS", 21600),
+		DigestPollSeconds:            getEnvInt("DIGEST_POLL_SECONDS", 300),
+		DigestSendHour:               getEnvInt("DIGEST_SEND_HOUR", 7),
+		DigestWeeklyDay:              getEnvInt("DIGEST_WEEKLY_DAY", 1),
+		WebhookPollSeconds:           getEnvInt("WEBHOOK_POLL_SECONDS", 10),
+		WebhookBaseBackoffSeconds:    getEnvInt("WEBHOOK_BASE_BACKOFF_SECONDS", 30),
+		WebhookMaxBackoffSeconds:     getEnvInt("WEBHOOK_MAX_BACKOFF_SECONDS", 3600),
+		CommentSpamMaxLinks:          getEnvInt("COMMENT_SPAM_MAX_LINKS", 2),
+		CommentSpamRepeatWindowSec:   getEnvInt("COMMENT_SPAM_REPEAT_WINDOW_SECONDS", 3600),
+		CommentSpamRepeatThreshold:   getEnvInt("COMMENT_SPAM_REPEAT_THRESHOLD", 2),
+		CommentSpamNewAccountHours:   getEnvInt("COMMENT_SPAM_NEW_ACCOUNT_HOURS", 24),
+		CommentSpamBlockedWords:      getEnvStringList("COMMENT_SPAM_BLOCKED_WORDS"),
+		CommentReportHideThreshold:   getEnvInt("COMMENT_REPORT_HIDE_THRESHOLD", 3),
 		CORSAllowedOrigins: parseAllowedOrigins(
 			getEnv("CORS_ALLOWED_ORIGINS", ""),
 			strings.TrimSpace(getEnv("APP_PUBLIC_URL", "http://localhost:3002")),
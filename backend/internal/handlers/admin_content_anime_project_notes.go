@@ -119,6 +119,17 @@ func (h *AdminContentHandler) UpsertAnimeFansubProjectNote(c *gin.Context) {
 	}
 	bodyText, _ := h.tiptapSvc.ExtractText(bodyJSONStr)
 
+	// Der vorherige Stand entscheidet, ob das Speichern eine Veröffentlichung ist.
+	wasPublished := false
+	if h.webhooks != nil {
+		previous, err := h.fansubNotesRepo.GetAnimeFansubProjectNote(c.Request.Context(), animeID, fansubID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			writeInternalErrorResponse(c, "interner serverfehler", err, "Anime-Fansub-Projektnotiz konnte nicht geladen werden.")
+			return
+		}
+		wasPublished = isPublishedProjectNote(previous)
+	}
+
 	note, err := h.fansubNotesRepo.UpsertAnimeFansubProjectNote(
 		c.Request.Context(),
 		animeID,
@@ -148,9 +159,17 @@ func (h *AdminContentHandler) UpsertAnimeFansubProjectNote(c *gin.Context) {
 		writeInternalErrorResponse(c, "interner serverfehler", err, "Anime-Fansub-Projektnotiz konnte nicht gespeichert werden.")
 		return
 	}
+	if !wasPublished && isPublishedProjectNote(note) {
+		h.webhooks.NotifyProjectNotePublished(c.Request.Context(), note.ID)
+	}
 	c.JSON(http.StatusOK, gin.H{"data": note})
 }
 
+// isPublishedProjectNote meldet, ob eine Projektnotiz öffentlich sichtbar ist.
+func isPublishedProjectNote(note *repository.AnimeFansubProjectNote) bool {
+	return note != nil && note.DeletedAt == nil && note.Status == "published" && note.Visibility == "public"
+}
+
 // DeleteAnimeFansubProjectNote verarbeitet DELETE /api/v1/admin/fansubs/:id/anime/:animeId/notes/:noteId.
 func (h *AdminContentHandler) DeleteAnimeFansubProjectNote(c *gin.Context) {
 	identity, ok := h.requireFansubGroupNoteWriteAccess(c)
@@ -6,7 +6,6 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -77,7 +76,7 @@ func parseClockToSeconds(raw *string) *int32 {
 	return &s
 }
 
-func cleanupSegmentAssetRef(ctx context.Context, themeRepo adminThemeRepository, mediaRepo *repository.MediaRepository, mediaStorageDir string, ref string) {
+func cleanupSegmentAssetRef(ctx context.Context, themeRepo adminThemeRepository, mediaRepo *repository.MediaRepository, mediaService *services.MediaService, mediaStorageDir string, ref string) {
 	trimmedRef := strings.TrimSpace(filepath.ToSlash(ref))
 	if trimmedRef == "" {
 		return
@@ -90,7 +89,7 @@ func cleanupSegmentAssetRef(ctx context.Context, themeRepo adminThemeRepository,
 	}
 
 	absPath := filepath.Join(mediaStorageDir, filepath.FromSlash(trimmedRef))
-	_ = os.Remove(absPath)
+	_ = removeMediaFile(ctx, mediaService, absPath)
 
 	if mediaRepo == nil {
 		return
@@ -390,7 +389,7 @@ func (h *AdminContentHandler) UpdateAnimeSegment(c *gin.Context) {
 			newRef = strings.TrimSpace(*updatedSegment.SourceRef)
 		}
 		if newType != segmentSourceTypeReleaseAsset || newRef == "" || newRef != oldRef {
-			cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaStorageDir, oldRef)
+			cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaService, h.mediaStorageDir, oldRef)
 		}
 	}
 
@@ -588,7 +587,7 @@ func (h *AdminContentHandler) UploadSegmentAsset(c *gin.Context) {
 		return
 	}
 
-	saveResult, err := h.mediaService.SaveSegmentAsset(services.SegmentAssetContext{
+	saveResult, err := h.mediaService.SaveSegmentAsset(c.Request.Context(), services.SegmentAssetContext{
 		AnimeID:          animeID,
 		StableProvider:   stableProvider,
 		StableExternalID: stableExternalID,
@@ -608,7 +607,7 @@ func (h *AdminContentHandler) UploadSegmentAsset(c *gin.Context) {
 
 	asset, err := h.mediaRepo.CreateMediaAsset(c.Request.Context(), saveResult.CreateInput)
 	if err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, saveResult.CreateInput.StoragePath)
 		if errors.Is(err, repository.ErrConflict) {
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "media asset bereits vorhanden"}})
 			return
@@ -629,7 +628,7 @@ func (h *AdminContentHandler) UploadSegmentAsset(c *gin.Context) {
 	)
 	if patchErr != nil {
 		log.Printf("segment asset bind failed: anime=%d segment=%d ref=%s: %v", animeID, segmentID, relPath, patchErr)
-		cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaStorageDir, relPath)
+		cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaService, h.mediaStorageDir, relPath)
 		writeInternalErrorResponse(c, "interner serverfehler", patchErr, "Segment-Quelle konnte nicht aktualisiert werden.")
 		return
 	}
@@ -639,7 +638,7 @@ func (h *AdminContentHandler) UploadSegmentAsset(c *gin.Context) {
 		seg.SourceRef != nil &&
 		strings.TrimSpace(*seg.SourceRef) != "" &&
 		strings.TrimSpace(*seg.SourceRef) != relPath {
-		cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaStorageDir, *seg.SourceRef)
+		cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaService, h.mediaStorageDir, *seg.SourceRef)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": updated})
@@ -679,7 +678,7 @@ func (h *AdminContentHandler) DeleteSegmentAsset(c *gin.Context) {
 	}
 
 	if previousRef != nil && *previousRef != "" {
-		cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaStorageDir, *previousRef)
+		cleanupSegmentAssetRef(c.Request.Context(), h.themeRepo, h.mediaRepo, h.mediaService, h.mediaStorageDir, *previousRef)
 	}
 
 	c.Status(http.StatusNoContent)
@@ -42,6 +42,9 @@ func (h *AdminContentHandler) CreateEpisode(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "interner serverfehler"}})
 		return
 	}
+	if err := h.notifications.NotifyNewEpisode(c.Request.Context(), item.AnimeID, item.ID, item.EpisodeNumber, identity.AppUserID); err != nil {
+		log.Printf("admin_content create_episode: notify watchlist failed (episode_id=%d): %v", item.ID, err)
+	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": item})
 }
@@ -8,6 +8,7 @@ import (
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/permissions"
 	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -96,3 +97,69 @@ func (h *AdminContentHandler) ScanEpisodeVersionFolder(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": result})
 }
+
+// ProbeEpisodeVersionMedia verarbeitet GET /api/v1/admin/episode-versions/:versionId/media-probe und liefert
+// Dateigröße, MIME-Typ, Laufzeit und Streams (ffprobe) einer Episodenversion mit Media-Provider "local".
+func (h *AdminContentHandler) ProbeEpisodeVersionMedia(c *gin.Context) {
+	identity, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+
+	versionID, err := parseEpisodeVersionID(c.Param("versionId"))
+	if err != nil {
+		badRequest(c, "ungültige version id")
+		return
+	}
+
+	version, err := h.episodeVersionRepo.GetByID(c.Request.Context(), versionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "episodenversion nicht gefunden"}})
+		return
+	}
+	if err != nil {
+		log.Printf("admin_content episode_version_media_probe: user=%d version=%d err=%v", identity.UserID, versionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "interner serverfehler"}})
+		return
+	}
+	if !isLocalMediaProvider(version.MediaProvider) {
+		badRequest(c, "medien-analyse ist nur für lokale dateien verfügbar")
+		return
+	}
+	if !h.localMedia.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "lokales medienverzeichnis ist nicht konfiguriert"}})
+		return
+	}
+
+	file, err := h.localMedia.Stat(version.MediaItemID)
+	if errors.Is(err, services.ErrLocalMediaInvalidPath) || errors.Is(err, services.ErrLocalMediaNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "mediendatei nicht gefunden"}})
+		return
+	}
+	if err != nil {
+		log.Printf("admin_content episode_version_media_probe: user=%d version=%d err=%v", identity.UserID, versionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "interner serverfehler"}})
+		return
+	}
+
+	probe, err := h.localMedia.Probe(c.Request.Context(), version.MediaItemID)
+	if err != nil {
+		log.Printf("admin_content episode_version_media_probe: ffprobe failed user=%d version=%d err=%v", identity.UserID, versionID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "mediendatei konnte nicht analysiert werden"}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"version_id":       versionID,
+			"media_provider":   services.LocalMediaProviderName,
+			"media_item_id":    file.RelativePath,
+			"content_type":     file.ContentType,
+			"file_size_bytes":  file.Size,
+			"last_modified":    file.ModTime,
+			"duration_seconds": probe.DurationSeconds,
+			"format_name":      probe.FormatName,
+			"streams":          probe.Streams,
+		},
+	})
+}
@@ -153,10 +153,20 @@ func (h *AdminContentHandler) resolveEpisodeVersionDuration(
 	if version == nil {
 		return nil, nil
 	}
-	if !strings.EqualFold(strings.TrimSpace(version.MediaProvider), "jellyfin") {
+	mediaItemID := strings.TrimSpace(version.MediaItemID)
+	if isLocalMediaProvider(version.MediaProvider) {
+		if mediaItemID == "" || !h.localMedia.Enabled() {
+			return nil, nil
+		}
+		probe, err := h.localMedia.Probe(ctx, mediaItemID)
+		if err != nil {
+			return nil, err
+		}
+		return probe.DurationSeconds, nil
+	}
+	if !strings.EqualFold(strings.TrimSpace(version.MediaProvider), h.catalogMediaServer().Kind()) {
 		return nil, nil
 	}
-	mediaItemID := strings.TrimSpace(version.MediaItemID)
 	if mediaItemID == "" || !h.ensureJellyfinConfiguredForEditor() {
 		return nil, nil
 	}
@@ -179,19 +189,14 @@ func (h *AdminContentHandler) resolveEpisodeVersionSelectedGroups(
 	return []models.FansubGroupSummary{}, nil
 }
 
-// ensureJellyfinConfiguredForEditor prüft, ob Jellyfin-Basis-URL und API-Schlüssel für den Editor konfiguriert sind.
+// ensureJellyfinConfiguredForEditor prüft, ob Basis-URL und API-Schlüssel des Media-Servers für den Editor konfiguriert sind.
 func (h *AdminContentHandler) ensureJellyfinConfiguredForEditor() bool {
-	return strings.TrimSpace(h.jellyfinBaseURL) != "" && strings.TrimSpace(h.jellyfinAPIKey) != ""
+	return h.catalogMediaServer().Configured()
 }
 
-// buildJellyfinEditorStreamURL erstellt die Stream-URL für ein Jellyfin-Medienelement anhand seiner ID.
+// buildJellyfinEditorStreamURL erstellt die Stream-URL für ein Medienelement des Media-Servers anhand seiner ID.
 func (h *AdminContentHandler) buildJellyfinEditorStreamURL(itemID string) *string {
-	streamURL, err := buildProviderStreamURL(
-		h.jellyfinBaseURL,
-		normalizeStreamPathTemplate(h.jellyfinStreamPath),
-		h.jellyfinAPIKey,
-		itemID,
-	)
+	streamURL, err := h.catalogMediaServer().StreamURL(itemID)
 	if err != nil {
 		return nil
 	}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,9 +11,11 @@ import (
 	"strings"
 
 	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/services"
 )
 
-// scanEpisodeVersionFolder durchsucht den zu einer Episodenversion gehörenden Jellyfin-Ordner und gibt die gefundenen Mediendateien zurück.
+// scanEpisodeVersionFolder durchsucht den zu einer Episodenversion gehörenden Jellyfin-Ordner bzw. ohne Jellyfin-Bindung
+// den lokalen Anime-Ordner und gibt die gefundenen Mediendateien zurück.
 func (h *AdminContentHandler) scanEpisodeVersionFolder(
 	ctx context.Context,
 	versionID int64,
@@ -22,6 +25,9 @@ func (h *AdminContentHandler) scanEpisodeVersionFolder(
 		return nil, 0, err
 	}
 	if resolved.jellyfinSeriesID == "" {
+		if h.localMedia.Enabled() {
+			return h.scanLocalEpisodeVersionFolder(resolved)
+		}
 		return nil, http.StatusBadRequest, fmt.Errorf("ordner-sync ist nur für jellyfin-gebundene anime verfügbar")
 	}
 	if !h.ensureJellyfinConfiguredForEditor() {
@@ -33,7 +39,7 @@ func (h *AdminContentHandler) scanEpisodeVersionFolder(
 		return nil, http.StatusBadGateway, fmt.Errorf("ordner konnte nicht synchronisiert werden")
 	}
 
-	files := buildEpisodeVersionMediaFiles(items, resolved.animeFolderPath, h.buildJellyfinEditorStreamURL)
+	files := buildEpisodeVersionMediaFiles(items, resolved.animeFolderPath, h.catalogMediaServer().Kind(), h.buildJellyfinEditorStreamURL)
 	return &models.EpisodeVersionFolderScanResult{
 		VersionID:       resolved.version.ID,
 		AnimeID:         resolved.version.AnimeID,
@@ -42,10 +48,11 @@ func (h *AdminContentHandler) scanEpisodeVersionFolder(
 	}, 0, nil
 }
 
-// buildEpisodeVersionMediaFiles erstellt aus einer Liste von Jellyfin-Episoden eine sortierte Liste von Mediendatei-Einträgen für den Editor.
+// buildEpisodeVersionMediaFiles erstellt aus einer Liste von Media-Server-Episoden eine sortierte Liste von Mediendatei-Einträgen für den Editor.
 func buildEpisodeVersionMediaFiles(
 	items []jellyfinEpisodeItem,
 	folderPath *string,
+	provider string,
 	streamURLBuilder func(string) *string,
 ) []models.EpisodeVersionMediaFile {
 	normalizedFolderPath := normalizeJellyfinPath(folderPath)
@@ -61,11 +68,12 @@ func buildEpisodeVersionMediaFiles(
 		}
 
 		entry := models.EpisodeVersionMediaFile{
-			FileName:     path.Base(strings.ReplaceAll(itemPath, "\\", "/")),
-			Path:         itemPath,
-			MediaItemID:  itemID,
-			StreamURL:    streamURLBuilder(itemID),
-			VideoQuality: jellyfinVideoQuality(item.MediaStreams),
+			FileName:      path.Base(strings.ReplaceAll(itemPath, "\\", "/")),
+			Path:          itemPath,
+			MediaProvider: provider,
+			MediaItemID:   itemID,
+			StreamURL:     streamURLBuilder(itemID),
+			VideoQuality:  jellyfinVideoQuality(item.MediaStreams),
 		}
 		if releaseName := normalizeNullableStringPtr(fileBaseWithoutExt(itemPath)); releaseName != nil {
 			entry.ReleaseName = releaseName
@@ -82,6 +90,60 @@ func buildEpisodeVersionMediaFiles(
 		files = append(files, entry)
 	}
 
+	sortEpisodeVersionMediaFiles(files)
+	return files
+}
+
+// scanLocalEpisodeVersionFolder durchsucht den Anime-Ordner unterhalb von LOCAL_MEDIA_ROOT. Der
+// Ordnerpfad darf relativ zum Medienverzeichnis oder ein absoluter Pfad darin sein.
+func (h *AdminContentHandler) scanLocalEpisodeVersionFolder(
+	resolved *episodeVersionEditorResolved,
+) (*models.EpisodeVersionFolderScanResult, int, error) {
+	localFiles, err := h.localMedia.Scan(derefString(resolved.animeFolderPath))
+	if errors.Is(err, services.ErrLocalMediaInvalidPath) || errors.Is(err, services.ErrLocalMediaNotFound) {
+		return nil, http.StatusBadRequest, fmt.Errorf("anime-ordner liegt nicht im lokalen medienverzeichnis")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &models.EpisodeVersionFolderScanResult{
+		VersionID:       resolved.version.ID,
+		AnimeID:         resolved.version.AnimeID,
+		AnimeFolderPath: resolved.animeFolderPath,
+		Files:           buildLocalEpisodeVersionMediaFiles(localFiles),
+	}, 0, nil
+}
+
+// buildLocalEpisodeVersionMediaFiles überführt lokale Dateien in Editor-Einträge; die MediaItemID ist
+// der relative Pfad, eine Stream-URL entfällt, da der Release-Stream die Datei direkt ausliefert.
+func buildLocalEpisodeVersionMediaFiles(localFiles []services.LocalMediaFile) []models.EpisodeVersionMediaFile {
+	files := make([]models.EpisodeVersionMediaFile, 0, len(localFiles))
+	for _, localFile := range localFiles {
+		size := localFile.Size
+		modifiedAt := localFile.ModTime
+		entry := models.EpisodeVersionMediaFile{
+			FileName:              path.Base(localFile.RelativePath),
+			Path:                  localFile.RelativePath,
+			MediaProvider:         services.LocalMediaProviderName,
+			MediaItemID:           localFile.RelativePath,
+			VideoQuality:          localFile.VideoQuality,
+			FileSizeBytes:         &size,
+			LastModified:          &modifiedAt,
+			DetectedEpisodeNumber: localFile.DetectedEpisodeNumber,
+		}
+		if releaseName := normalizeNullableStringPtr(fileBaseWithoutExt(localFile.RelativePath)); releaseName != nil {
+			entry.ReleaseName = releaseName
+		}
+		files = append(files, entry)
+	}
+
+	sortEpisodeVersionMediaFiles(files)
+	return files
+}
+
+// sortEpisodeVersionMediaFiles sortiert nach erkannter Episodennummer, danach nach Dateiname.
+func sortEpisodeVersionMediaFiles(files []models.EpisodeVersionMediaFile) {
 	sort.Slice(files, func(i, j int) bool {
 		leftEpisode := int32(1 << 30)
 		rightEpisode := int32(1 << 30)
@@ -96,8 +158,6 @@ func buildEpisodeVersionMediaFiles(
 		}
 		return strings.ToLower(files[i].FileName) < strings.ToLower(files[j].FileName)
 	})
-
-	return files
 }
 
 // extractJellyfinSourceID extrahiert die Jellyfin-Serien-ID aus einem Anime-Quellbezeichner im Format "jellyfin:<id>".
@@ -108,6 +108,12 @@ func (s *fansubReleaseThemeRepoStub) IsReusableSegmentAsset(ctx context.Context,
 func (s *fansubReleaseThemeRepoStub) GetSegmentReleaseDuration(ctx context.Context, animeID int64, fansubGroupID int64, version string) (*int32, error) {
 	return nil, nil
 }
+func (s *fansubReleaseThemeRepoStub) ListThemeSegmentImportEpisodes(ctx context.Context, animeID int64, fansubGroupID int64, version string) ([]models.ThemeSegmentImportEpisode, error) {
+	return nil, nil
+}
+func (s *fansubReleaseThemeRepoStub) ApplyThemeSegmentImport(ctx context.Context, animeID int64, input models.ThemeSegmentImportApplyInput, check func(current []models.AdminThemeSegment) error) ([]models.AdminThemeSegment, error) {
+	return nil, nil
+}
 func (s *fansubReleaseThemeRepoStub) GetCanonicalFansubAnimeRelease(ctx context.Context, fansubGroupID int64, animeID int64) (*int64, error) {
 	return nil, nil
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/permissions"
 	"team4s.v3/backend/internal/repository"
@@ -65,6 +66,8 @@ type adminThemeRepository interface {
 	AttachSegmentLibraryAsset(ctx context.Context, animeID int64, segmentID int64, input models.SegmentLibraryAttachInput) (*models.AdminThemeSegment, error)
 	IsReusableSegmentAsset(ctx context.Context, sourceRef string) (bool, error)
 	GetSegmentReleaseDuration(ctx context.Context, animeID int64, fansubGroupID int64, version string) (*int32, error)
+	ListThemeSegmentImportEpisodes(ctx context.Context, animeID int64, fansubGroupID int64, version string) ([]models.ThemeSegmentImportEpisode, error)
+	ApplyThemeSegmentImport(ctx context.Context, animeID int64, input models.ThemeSegmentImportApplyInput, check func(current []models.AdminThemeSegment) error) ([]models.AdminThemeSegment, error)
 	GetCanonicalFansubAnimeRelease(ctx context.Context, fansubGroupID int64, animeID int64) (*int64, error)
 	GetFansubRelease(ctx context.Context, fansubGroupID int64, animeID int64) (*int64, error)
 	ListFansubAnime(ctx context.Context, fansubGroupID int64) ([]models.AdminFansubAnimeEntry, error)
@@ -146,6 +149,7 @@ type AdminContentHandler struct {
 	jellyfinBaseURL                 string
 	jellyfinStreamPath              string
 	jellyfinAllowedLibraryIDs       []string
+	mediaServer                     mediaserver.MediaServerProvider
 	httpClient                      *http.Client
 	enrichmentService               adminAniSearchDraftLoader
 	aniSearchEpisodes               adminAniSearchEpisodeFetcher
@@ -158,6 +162,18 @@ type AdminContentHandler struct {
 	tiptapSvc                       *services.TipTapService
 	permissionSvc                   *permissions.Service
 	auditLogRepo                    *repository.AuditLogRepository
+	notifications                   *services.NotificationService
+	webhooks                        *services.WebhookService
+	localMedia                      *services.LocalMediaLibrary
+	jellyfinChanges                 jellyfinPendingChangeStore
+	jellyfinAnimeMatcher            jellyfinAnimeRefMatcher
+	jellyfinItemVersions            jellyfinItemVersionRemover
+	jellyfinWebhookSecret           string
+	jellyfinReconciliation          jellyfinReconciliationStore
+	jellyfinSyncSources             animeSyncSourceLoader
+	jellyfinLibraryIDs              []string
+	playbackSessions                adminPlaybackSessionStore
+	playbackSecurity                adminPlaybackSecurityStore
 }
 
 // AdminContentJellyfinConfig enthält die Verbindungsparameter für die Jellyfin-Integration im Admin-Bereich.
@@ -271,6 +287,26 @@ func (h *AdminContentHandler) WithPermissionDeps(permissionSvc *permissions.Serv
 	return h
 }
 
+// WithNotifications benachrichtigt Watchlist-Nutzer, wenn eine Episode angelegt wird.
+func (h *AdminContentHandler) WithNotifications(notifications *services.NotificationService) *AdminContentHandler {
+	h.notifications = notifications
+	return h
+}
+
+// WithWebhooks meldet neue Release-Medien und veröffentlichte Projektnotizen an die Webhooks
+// der beteiligten Gruppen.
+func (h *AdminContentHandler) WithWebhooks(webhooks *services.WebhookService) *AdminContentHandler {
+	h.webhooks = webhooks
+	return h
+}
+
+// WithLocalMedia aktiviert Ordner-Scan, Laufzeit und Stream-Metadaten für Dateien unterhalb
+// von LOCAL_MEDIA_ROOT (Media-Provider "local").
+func (h *AdminContentHandler) WithLocalMedia(library *services.LocalMediaLibrary) *AdminContentHandler {
+	h.localMedia = library
+	return h
+}
+
 // adminAnimeCreateEnrichmentRepo ist ein interner Adapter, der das AdminContentRepository
 // als adminAniSearchRepository verfügbar macht.
 type adminAnimeCreateEnrichmentRepo struct {
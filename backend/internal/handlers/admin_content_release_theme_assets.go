@@ -263,7 +263,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAsset(c *gin.Context) {
 		return
 	}
 
-	saveResult, err := h.mediaService.SaveReleaseThemeVideoUpload(services.ReleaseThemeVideoStorageContext{
+	saveResult, err := h.mediaService.SaveReleaseThemeVideoUpload(c.Request.Context(), services.ReleaseThemeVideoStorageContext{
 		ReleaseID: *releaseID,
 		ThemeID:   themeID,
 	}, fileHeader.Filename, data)
@@ -283,7 +283,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAsset(c *gin.Context) {
 
 	mediaAsset, err := h.mediaRepo.CreateMediaAsset(c.Request.Context(), saveResult.CreateInput)
 	if err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, saveResult.CreateInput.StoragePath)
 		if errors.Is(err, repository.ErrConflict) {
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "media asset bereits vorhanden"}})
 			return
@@ -300,7 +300,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAsset(c *gin.Context) {
 		int64(len(data)),
 	); err != nil {
 		_ = h.mediaRepo.DeleteMediaAsset(c.Request.Context(), mediaAsset.ID)
-		_ = removeFileQuietly(mediaAsset.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, mediaAsset.StoragePath)
 		writeInternalErrorResponse(c, "interner serverfehler", err, "Media-Dateigroesse konnte nicht gespeichert werden.")
 		return
 	}
@@ -312,7 +312,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAsset(c *gin.Context) {
 	})
 	if err != nil {
 		_ = h.mediaRepo.DeleteMediaAsset(c.Request.Context(), mediaAsset.ID)
-		_ = removeFileQuietly(mediaAsset.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, mediaAsset.StoragePath)
 		if errors.Is(err, repository.ErrConflict) {
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "theme bereits zugewiesen", "code": "already_assigned"}})
 			return
@@ -428,7 +428,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAssetForRelease(c *gin.Context)
 		return
 	}
 
-	saveResult, err := h.mediaService.SaveReleaseThemeVideoUpload(services.ReleaseThemeVideoStorageContext{
+	saveResult, err := h.mediaService.SaveReleaseThemeVideoUpload(c.Request.Context(), services.ReleaseThemeVideoStorageContext{
 		ReleaseID: releaseID,
 		ThemeID:   themeID,
 	}, fileHeader.Filename, data)
@@ -448,7 +448,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAssetForRelease(c *gin.Context)
 
 	mediaAsset, err := h.mediaRepo.CreateMediaAsset(c.Request.Context(), saveResult.CreateInput)
 	if err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, saveResult.CreateInput.StoragePath)
 		if errors.Is(err, repository.ErrConflict) {
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "media asset bereits vorhanden"}})
 			return
@@ -465,7 +465,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAssetForRelease(c *gin.Context)
 		int64(len(data)),
 	); err != nil {
 		_ = h.mediaRepo.DeleteMediaAsset(c.Request.Context(), mediaAsset.ID)
-		_ = removeFileQuietly(mediaAsset.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, mediaAsset.StoragePath)
 		writeInternalErrorResponse(c, "interner serverfehler", err, "Media-Dateigroesse konnte nicht gespeichert werden.")
 		return
 	}
@@ -477,7 +477,7 @@ func (h *AdminContentHandler) UploadReleaseThemeAssetForRelease(c *gin.Context)
 	})
 	if err != nil {
 		_ = h.mediaRepo.DeleteMediaAsset(c.Request.Context(), mediaAsset.ID)
-		_ = removeFileQuietly(mediaAsset.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, mediaAsset.StoragePath)
 		if errors.Is(err, repository.ErrConflict) {
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "theme bereits zugewiesen", "code": "already_assigned"}})
 			return
@@ -552,7 +552,7 @@ func (h *AdminContentHandler) DeleteReleaseThemeAsset(c *gin.Context) {
 			writeInternalErrorResponse(c, "interner serverfehler", err, "Theme-Video-Datei konnte nicht gelöscht werden.")
 			return
 		}
-		_ = removeFileQuietly(mediaAsset.StoragePath)
+		_ = removeMediaFile(c.Request.Context(), h.mediaService, mediaAsset.StoragePath)
 	}
 
 	c.Status(http.StatusNoContent)
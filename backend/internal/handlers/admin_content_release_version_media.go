@@ -12,7 +12,6 @@ import (
 	"log"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -260,12 +259,14 @@ func (h *AdminContentHandler) UploadReleaseVersionMedia(c *gin.Context) {
 
 	uploadedByUserID := identity.UserID
 	results := make([]rvmFileResult, 0, len(files))
+	readyCount := 0
 
 	for i, fileHeader := range files {
 		sortOrder := maxSortOrder + (i+1)*10
 		result := h.processOneRVMFile(c, fileHeader, versionID, category, sortOrder, uploadedByUserID, rvmVisibilityCode, rvmReviewStatusCode)
 		results = append(results, result)
 		if result.Status == "ready" && result.ReleaseVersionMediaID != nil {
+			readyCount++
 			_ = h.auditLogRepo.Write(c.Request.Context(), repository.AuditLogEntry{
 				ActorAppUserID:    &identity.AppUserID,
 				ActorLegacyUserID: &identity.UserID,
@@ -279,6 +280,9 @@ func (h *AdminContentHandler) UploadReleaseVersionMedia(c *gin.Context) {
 		}
 	}
 
+	// Ein Ereignis pro Upload-Request, nicht pro Datei.
+	h.webhooks.NotifyReleaseMediaAdded(c.Request.Context(), versionID, category, readyCount)
+
 	c.JSON(http.StatusOK, gin.H{"results": results})
 }
 
@@ -363,45 +367,42 @@ func (h *AdminContentHandler) processOneRVMFile(
 	originalPath := filepath.Join(assetDir, "original."+ext)
 	thumbPath := filepath.Join(assetDir, "thumb.jpg")
 
-	if err := os.MkdirAll(assetDir, 0o755); err != nil {
-		return rvmFileResult{ClientFileName: clientName, Status: "failed",
-			ErrorCode: "STORAGE_FAILED", Message: "verzeichnis konnte nicht erstellt werden"}
+	ctx := c.Request.Context()
+	removeStored := func() {
+		_ = removeMediaFile(ctx, h.mediaService, originalPath)
+		_ = removeMediaFile(ctx, h.mediaService, thumbPath)
 	}
 
-	// EXIF-Strip für JPEG, PNG und WebP: imaging.Save re-enkodiert das Bild
+	// EXIF-Strip für JPEG, PNG und WebP: imaging re-enkodiert das Bild
 	// ohne Metadaten. GIF bleibt raw, weil imaging die Animation nicht erhalten kann.
-	if mimeType == "image/gif" {
-		if err := os.WriteFile(originalPath, data, 0o644); err != nil {
-			_ = removeFileQuietly(originalPath)
-			return rvmFileResult{ClientFileName: clientName, Status: "failed",
-				ErrorCode: "STORAGE_FAILED", Message: "original (gif) konnte nicht gespeichert werden"}
-		}
-	} else {
+	originalData := data
+	if mimeType != "image/gif" {
 		decoded, _, err := image.Decode(bytes.NewReader(data))
 		if err != nil {
-			_ = removeFileQuietly(originalPath)
 			return rvmFileResult{ClientFileName: clientName, Status: "failed",
 				ErrorCode: "IMAGE_DECODE_FAILED", Message: "original dekodieren fehlgeschlagen"}
 		}
-		if err := imaging.Save(decoded, originalPath); err != nil {
-			_ = removeFileQuietly(originalPath)
+		originalData, err = encodeImageFile(decoded, originalPath)
+		if err != nil {
 			return rvmFileResult{ClientFileName: clientName, Status: "failed",
 				ErrorCode: "STORAGE_FAILED", Message: "original (exif-strip) konnte nicht gespeichert werden"}
 		}
 	}
+	if err := storeMediaFile(ctx, h.mediaService, originalPath, originalData); err != nil {
+		removeStored()
+		return rvmFileResult{ClientFileName: clientName, Status: "failed",
+			ErrorCode: "STORAGE_FAILED", Message: "original konnte nicht gespeichert werden"}
+	}
 
-	if err := os.WriteFile(thumbPath, thumbData, 0o644); err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+	if err := storeMediaFile(ctx, h.mediaService, thumbPath, thumbData); err != nil {
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "STORAGE_FAILED", Message: "thumbnail konnte nicht gespeichert werden"}
 	}
 
-	ctx := c.Request.Context()
 	tx, err := h.mediaRepo.BeginTx(ctx)
 	if err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "transaktion konnte nicht gestartet werden"}
 	}
@@ -414,7 +415,7 @@ func (h *AdminContentHandler) processOneRVMFile(
 		MimeType:         mimeType,
 		Filename:         "original." + ext,
 		StoragePath:      originalPath,
-		SizeBytes:        int64(len(data)),
+		SizeBytes:        int64(len(originalData)),
 		Width:            &meta.Width,
 		Height:           &meta.Height,
 		VisibilityCode:   &visibilityCode,
@@ -422,21 +423,18 @@ func (h *AdminContentHandler) processOneRVMFile(
 	}
 	mediaAsset, err := h.mediaRepo.CreateMediaAssetWithStatusTx(ctx, tx, createInput, "processing")
 	if err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "media asset konnte nicht erstellt werden"}
 	}
 
-	if err := h.mediaRepo.InsertMediaFileWithStatus(ctx, tx, mediaAsset.ID, "original", originalPath, meta.Width, meta.Height, int64(len(data)), "processing"); err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+	if err := h.mediaRepo.InsertMediaFileWithStatus(ctx, tx, mediaAsset.ID, "original", originalPath, meta.Width, meta.Height, int64(len(originalData)), "processing"); err != nil {
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "media file (original) konnte nicht erstellt werden"}
 	}
 	if err := h.mediaRepo.InsertMediaFileWithStatus(ctx, tx, mediaAsset.ID, "thumb", thumbPath, thumbWidth, thumbHeight, int64(len(thumbData)), "processing"); err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "media file (thumb) konnte nicht erstellt werden"}
 	}
@@ -452,28 +450,24 @@ func (h *AdminContentHandler) processOneRVMFile(
 		UploadedByUserID:   &uploadedBy,
 	})
 	if err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "release version media konnte nicht erstellt werden"}
 	}
 
 	if err := h.mediaRepo.UpdateMediaAssetStatusRVMTx(ctx, tx, mediaAsset.ID, "ready"); err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "media asset status konnte nicht auf ready gesetzt werden"}
 	}
 	if err := h.mediaRepo.UpdateMediaFileStatusRVMTx(ctx, tx, mediaAsset.ID, "ready"); err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "media file status konnte nicht auf ready gesetzt werden"}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		_ = removeFileQuietly(originalPath)
-		_ = removeFileQuietly(thumbPath)
+		removeStored()
 		return rvmFileResult{ClientFileName: clientName, Status: "failed",
 			ErrorCode: "DB_FAILED", Message: "transaktion konnte nicht committed werden"}
 	}
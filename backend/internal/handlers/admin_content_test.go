@@ -14,6 +14,8 @@ import (
 	"strings"
 	"testing"
 
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/mediaserver/mediaservertest"
 	"team4s.v3/backend/internal/middleware"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
@@ -1903,3 +1905,23 @@ func readHandlerSource(t *testing.T, name string) string {
 
 	return string(content)
 }
+
+func TestResolveEpisodeVersionDuration_UsesCatalogMediaServer(t *testing.T) {
+	provider := mediaservertest.NewProvider()
+	provider.KindName = mediaserver.KindEmby
+	provider.Runtimes["item-7"] = 1420
+	handler := (&AdminContentHandler{}).WithMediaServer(provider)
+
+	duration, err := handler.resolveEpisodeVersionDuration(context.Background(), &models.EpisodeVersion{MediaProvider: "emby", MediaItemID: "item-7"})
+	if err != nil || duration == nil || *duration != 1420 {
+		t.Fatalf("unexpected duration %v, %v", duration, err)
+	}
+
+	duration, err = handler.resolveEpisodeVersionDuration(context.Background(), &models.EpisodeVersion{MediaProvider: "jellyfin", MediaItemID: "item-7"})
+	if err != nil || duration != nil {
+		t.Fatalf("expected no duration for other providers, got %v, %v", duration, err)
+	}
+	if calls := provider.Calls(); len(calls) != 1 || calls[0] != "GetRuntimeSeconds:item-7" {
+		t.Fatalf("unexpected provider calls %v", calls)
+	}
+}
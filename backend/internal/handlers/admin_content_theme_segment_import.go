@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxThemeSegmentImportToleranceSeconds = 30
+
+// errThemeSegmentImportConflict bricht die Übernahme ab, wenn sich Einträge überschneiden.
+var errThemeSegmentImportConflict = errors.New("theme segment import conflicts")
+
+type adminThemeSegmentImportPreviewRequest struct {
+	FansubGroupID    int64  `json:"fansub_group_id"`
+	Version          string `json:"version"`
+	ToleranceSeconds *int32 `json:"tolerance_seconds"`
+}
+
+type adminThemeSegmentImportApplyItem struct {
+	ThemeID           int64   `json:"theme_id"`
+	StartEpisode      int     `json:"start_episode"`
+	EndEpisode        int     `json:"end_episode"`
+	StartTime         string  `json:"start_time"`
+	EndTime           string  `json:"end_time"`
+	ReplaceSegmentIDs []int64 `json:"replace_segment_ids"`
+}
+
+type adminThemeSegmentImportApplyRequest struct {
+	FansubGroupID int64                              `json:"fansub_group_id"`
+	Version       string                             `json:"version"`
+	Items         []adminThemeSegmentImportApplyItem `json:"items"`
+}
+
+// PreviewThemeSegmentImport verarbeitet POST /api/v1/admin/anime/:id/segments/jellyfin-import/preview
+// Body: { fansub_group_id, version?, tolerance_seconds? }
+// Liest die Intro-/Outro-MediaSegments aller Episoden des Releases aus Jellyfin und fasst
+// übereinstimmende Zeiten zu Segment-Vorschlägen mit Episodenbereich zusammen.
+func (h *AdminContentHandler) PreviewThemeSegmentImport(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if h.themeRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "theme service nicht verfügbar"}})
+		return
+	}
+
+	animeID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || animeID <= 0 {
+		badRequest(c, "ungültige anime id")
+		return
+	}
+
+	var req adminThemeSegmentImportPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	if req.FansubGroupID <= 0 {
+		badRequest(c, "fansub_group_id ist erforderlich")
+		return
+	}
+	tolerance := services.DefaultThemeSegmentImportTolerance
+	if req.ToleranceSeconds != nil {
+		if *req.ToleranceSeconds < 0 || *req.ToleranceSeconds > maxThemeSegmentImportToleranceSeconds {
+			badRequest(c, "tolerance_seconds muss zwischen 0 und 30 liegen")
+			return
+		}
+		tolerance = *req.ToleranceSeconds
+	}
+	version := normalizeThemeSegmentImportVersion(req.Version)
+
+	if !h.ensureJellyfinConfigured(c) {
+		return
+	}
+	segmentProvider, ok := h.catalogMediaServer().(mediaserver.MediaSegmentProvider)
+	if !ok {
+		details := "Der konfigurierte Media-Server liefert keine MediaSegments (erst ab Jellyfin 10.10)."
+		writeJellyfinErrorResponse(c, http.StatusServiceUnavailable, "media segments werden nicht unterstützt", "media_segments_unsupported", &details)
+		return
+	}
+
+	ctx := c.Request.Context()
+	episodes, err := h.themeRepo.ListThemeSegmentImportEpisodes(ctx, animeID, req.FansubGroupID, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "anime nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("admin theme segment import preview: anime_id=%d group=%d: %v", animeID, req.FansubGroupID, err)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Episoden des Releases konnten nicht geladen werden.")
+		return
+	}
+	existing, err := h.themeRepo.ListAnimeSegments(ctx, animeID, req.FansubGroupID, version)
+	if err != nil {
+		log.Printf("admin theme segment import preview: list segments anime_id=%d: %v", animeID, err)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Segmente konnten nicht geladen werden.")
+		return
+	}
+	themes, err := h.themeRepo.ListAdminAnimeThemes(ctx, animeID)
+	if err != nil {
+		log.Printf("admin theme segment import preview: list themes anime_id=%d: %v", animeID, err)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Themes konnten nicht geladen werden.")
+		return
+	}
+
+	preview := models.ThemeSegmentImportPreview{
+		AnimeID:              animeID,
+		FansubGroupID:        req.FansubGroupID,
+		Version:              version,
+		ToleranceSeconds:     tolerance,
+		EpisodeCount:         len(episodes),
+		EpisodesWithSegments: []int{},
+		EpisodesWithoutItem:  []int{},
+	}
+	episodeNumbers := make([]int, 0, len(episodes))
+	observations := make([]models.ThemeSegmentObservation, 0, len(episodes)*2)
+	types := []string{mediaserver.MediaSegmentTypeIntro, mediaserver.MediaSegmentTypeOutro}
+	for _, episode := range episodes {
+		episodeNumbers = append(episodeNumbers, episode.EpisodeNumber)
+		if episode.JellyfinItemID == nil {
+			preview.EpisodesWithoutItem = append(preview.EpisodesWithoutItem, episode.EpisodeNumber)
+			continue
+		}
+
+		segments, err := segmentProvider.ListMediaSegments(ctx, *episode.JellyfinItemID, types)
+		if err != nil {
+			log.Printf("admin theme segment import preview: media segments item=%s: %v", *episode.JellyfinItemID, err)
+			message, code, details := classifyJellyfinUpstreamError(err, "jellyfin media segments konnten nicht geladen werden")
+			writeJellyfinErrorResponse(c, http.StatusBadGateway, message, code, details)
+			return
+		}
+		found := services.ThemeSegmentObservationsFromMediaSegments(episode.EpisodeNumber, segments)
+		if len(found) > 0 {
+			preview.EpisodesWithSegments = append(preview.EpisodesWithSegments, episode.EpisodeNumber)
+		}
+		observations = append(observations, found...)
+	}
+	preview.Proposals = services.BuildThemeSegmentProposals(episodeNumbers, observations, existing, themes, tolerance)
+
+	c.JSON(http.StatusOK, gin.H{"data": preview})
+}
+
+// ApplyThemeSegmentImport verarbeitet POST /api/v1/admin/anime/:id/segments/jellyfin-import/apply
+// Body: { fansub_group_id, version?, items: [{ theme_id, start_episode, end_episode, start_time,
+// end_time, replace_segment_ids? }] }
+// Legt die übernommenen Vorschläge als Segmente an. Überschneidet sich ein Eintrag mit einem
+// bestehenden Segment derselben Art, das nicht in replace_segment_ids steht, oder mit einem
+// anderen Eintrag, wird nichts gespeichert (409 segment_conflict). Löschen und Anlegen laufen in
+// einer Transaktion.
+func (h *AdminContentHandler) ApplyThemeSegmentImport(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if h.themeRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "theme service nicht verfügbar"}})
+		return
+	}
+
+	animeID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || animeID <= 0 {
+		badRequest(c, "ungültige anime id")
+		return
+	}
+
+	var req adminThemeSegmentImportApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	if req.FansubGroupID <= 0 {
+		badRequest(c, "fansub_group_id ist erforderlich")
+		return
+	}
+	if len(req.Items) == 0 {
+		badRequest(c, "items darf nicht leer sein")
+		return
+	}
+	version := normalizeThemeSegmentImportVersion(req.Version)
+
+	ctx := c.Request.Context()
+	themes, err := h.themeRepo.ListAdminAnimeThemes(ctx, animeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "anime nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("admin theme segment import apply: list themes anime_id=%d: %v", animeID, err)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Themes konnten nicht geladen werden.")
+		return
+	}
+	existing, err := h.themeRepo.ListAnimeSegments(ctx, animeID, req.FansubGroupID, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "anime nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("admin theme segment import apply: list segments anime_id=%d: %v", animeID, err)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Segmente konnten nicht geladen werden.")
+		return
+	}
+	releaseDuration, err := h.themeRepo.GetSegmentReleaseDuration(ctx, animeID, req.FansubGroupID, version)
+	if err != nil {
+		log.Printf("admin theme segment import apply: lookup release duration anime=%d group=%d: %v", animeID, req.FansubGroupID, err)
+		// Nicht fatal: ohne Laufzeit wird nur die Reihenfolge von Start und Ende geprüft.
+		releaseDuration = nil
+	}
+
+	themeTypes := make(map[int64]string, len(themes))
+	for _, theme := range themes {
+		themeTypes[theme.ID] = theme.ThemeTypeName
+	}
+	existingIDs := make(map[int64]bool, len(existing))
+	for _, segment := range existing {
+		existingIDs[segment.ID] = true
+	}
+
+	replaced := make(map[int64]bool)
+	replacedIDs := make([]int64, 0)
+	for _, item := range req.Items {
+		for _, segmentID := range item.ReplaceSegmentIDs {
+			if !existingIDs[segmentID] {
+				badRequest(c, "replace_segment_ids enthält ein segment außerhalb von gruppe und version")
+				return
+			}
+			if !replaced[segmentID] {
+				replaced[segmentID] = true
+				replacedIDs = append(replacedIDs, segmentID)
+			}
+		}
+	}
+
+	planned := make([]models.AdminThemeSegment, 0, len(req.Items))
+	for _, item := range req.Items {
+		themeTypeName, ok := themeTypes[item.ThemeID]
+		if !ok {
+			badRequest(c, "theme_id gehört nicht zum anime")
+			return
+		}
+		kind := services.ThemeSegmentKind(themeTypeName)
+		if kind == "" || kind == models.SkipMarkerKindRecap {
+			badRequest(c, "theme_id muss ein opening oder ending sein")
+			return
+		}
+		if item.StartEpisode <= 0 || item.EndEpisode < item.StartEpisode {
+			badRequest(c, "ungültiger episodenbereich")
+			return
+		}
+		_, startOK := services.ParseSegmentClock(&item.StartTime)
+		_, endOK := services.ParseSegmentClock(&item.EndTime)
+		if !startOK || !endOK {
+			badRequest(c, "start_time und end_time sind erforderlich")
+			return
+		}
+		if msg := validateSegmentTimes(&item.StartTime, &item.EndTime, releaseDuration); msg != "" {
+			badRequest(c, msg)
+			return
+		}
+
+		startEpisode, endEpisode := item.StartEpisode, item.EndEpisode
+		startTime, endTime := item.StartTime, item.EndTime
+		planned = append(planned, models.AdminThemeSegment{
+			ThemeID:       item.ThemeID,
+			ThemeTypeName: themeTypeName,
+			StartEpisode:  &startEpisode,
+			EndEpisode:    &endEpisode,
+			StartTime:     &startTime,
+			EndTime:       &endTime,
+		})
+	}
+
+	groupID := req.FansubGroupID
+	input := models.ThemeSegmentImportApplyInput{
+		FansubGroupID:     groupID,
+		Version:           version,
+		ReplaceSegmentIDs: replacedIDs,
+		Segments:          make([]models.AdminThemeSegmentCreateInput, 0, len(planned)),
+	}
+	for _, segment := range planned {
+		input.Segments = append(input.Segments, models.AdminThemeSegmentCreateInput{
+			ThemeID:       segment.ThemeID,
+			FansubGroupID: &groupID,
+			Version:       version,
+			StartEpisode:  segment.StartEpisode,
+			EndEpisode:    segment.EndEpisode,
+			StartTime:     segment.StartTime,
+			EndTime:       segment.EndTime,
+		})
+	}
+
+	// Die Konfliktprüfung läuft in der Transaktion gegen die gesperrten Segmente, damit parallel
+	// kein Segment dazwischenkommt.
+	var conflicts []models.ThemeSegmentProposalConflict
+	created, err := h.themeRepo.ApplyThemeSegmentImport(ctx, animeID, input, func(current []models.AdminThemeSegment) error {
+		conflicts = findThemeSegmentImportConflicts(current, planned)
+		if len(conflicts) > 0 {
+			return errThemeSegmentImportConflict
+		}
+		return nil
+	})
+	if errors.Is(err, errThemeSegmentImportConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": gin.H{
+			"message":   "segmente überschneiden sich mit bestehenden segmenten",
+			"code":      "segment_conflict",
+			"conflicts": conflicts,
+		}})
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "anime oder theme nicht gefunden")
+		return
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "ungültige gruppe oder constraint verletzt", "code": "invalid_theme_or_group"}})
+		return
+	}
+	if err != nil {
+		log.Printf("admin theme segment import apply: anime_id=%d group=%d: %v", animeID, groupID, err)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Segmente konnten nicht gespeichert werden.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": models.ThemeSegmentImportApplyResult{
+		Created:            created,
+		ReplacedSegmentIDs: replacedIDs,
+	}})
+}
+
+// findThemeSegmentImportConflicts prüft jeden Eintrag aus planned gegen current und die
+// vorangehenden Einträge.
+func findThemeSegmentImportConflicts(current []models.AdminThemeSegment, planned []models.AdminThemeSegment) []models.ThemeSegmentProposalConflict {
+	conflicts := make([]models.ThemeSegmentProposalConflict, 0)
+	for i, segment := range planned {
+		startSeconds, _ := services.ParseSegmentClock(segment.StartTime)
+		endSeconds, _ := services.ParseSegmentClock(segment.EndTime)
+		against := append(append([]models.AdminThemeSegment(nil), current...), planned[:i]...)
+		conflicts = append(conflicts, services.FindThemeSegmentConflicts(
+			services.ThemeSegmentKind(segment.ThemeTypeName),
+			*segment.StartEpisode,
+			*segment.EndEpisode,
+			startSeconds,
+			endSeconds,
+			against,
+			0,
+		)...)
+	}
+	return conflicts
+}
+
+func normalizeThemeSegmentImportVersion(version string) string {
+	if trimmed := strings.TrimSpace(version); trimmed != "" {
+		return trimmed
+	}
+	return "v1"
+}
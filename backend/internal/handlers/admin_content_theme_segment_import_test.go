@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/mediaserver/mediaservertest"
+	"team4s.v3/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type themeSegmentImportRepoStub struct {
+	*fansubReleaseThemeRepoStub
+	episodes []models.ThemeSegmentImportEpisode
+	segments []models.AdminThemeSegment
+	created  []models.AdminThemeSegmentCreateInput
+	deleted  []int64
+}
+
+func (s *themeSegmentImportRepoStub) ListThemeSegmentImportEpisodes(_ context.Context, _ int64, _ int64, _ string) ([]models.ThemeSegmentImportEpisode, error) {
+	return s.episodes, nil
+}
+
+func (s *themeSegmentImportRepoStub) ListAnimeSegments(_ context.Context, _ int64, _ int64, _ string) ([]models.AdminThemeSegment, error) {
+	return s.segments, nil
+}
+
+func (s *themeSegmentImportRepoStub) ListAdminAnimeThemes(_ context.Context, animeID int64) ([]models.AdminAnimeTheme, error) {
+	return []models.AdminAnimeTheme{
+		{ID: 7, AnimeID: animeID, ThemeTypeName: "OP Kara"},
+		{ID: 8, AnimeID: animeID, ThemeTypeName: "ED Kara"},
+	}, nil
+}
+
+func (s *themeSegmentImportRepoStub) ApplyThemeSegmentImport(_ context.Context, animeID int64, input models.ThemeSegmentImportApplyInput, check func(current []models.AdminThemeSegment) error) ([]models.AdminThemeSegment, error) {
+	replaced := make(map[int64]bool, len(input.ReplaceSegmentIDs))
+	for _, segmentID := range input.ReplaceSegmentIDs {
+		replaced[segmentID] = true
+	}
+	current := make([]models.AdminThemeSegment, 0, len(s.segments))
+	for _, segment := range s.segments {
+		if !replaced[segment.ID] {
+			current = append(current, segment)
+		}
+	}
+	if err := check(current); err != nil {
+		return nil, err
+	}
+
+	s.deleted = append(s.deleted, input.ReplaceSegmentIDs...)
+	created := make([]models.AdminThemeSegment, 0, len(input.Segments))
+	for _, segment := range input.Segments {
+		s.created = append(s.created, segment)
+		created = append(created, models.AdminThemeSegment{ID: int64(100 + len(s.created)), ThemeID: segment.ThemeID, AnimeID: animeID, StartEpisode: segment.StartEpisode, EndEpisode: segment.EndEpisode})
+	}
+	return created, nil
+}
+
+func newThemeSegmentImportTestRouter(repo *themeSegmentImportRepoStub, provider mediaserver.MediaServerProvider) *gin.Engine {
+	handler := (&AdminContentHandler{
+		authzRepo:     stubAdminRoleChecker{allowed: true},
+		adminRoleName: "admin",
+		themeRepo:     repo,
+	}).WithMediaServer(provider)
+
+	router := gin.New()
+	router.Use(withTestAdminIdentity())
+	router.POST("/api/v1/admin/anime/:id/segments/jellyfin-import/preview", handler.PreviewThemeSegmentImport)
+	router.POST("/api/v1/admin/anime/:id/segments/jellyfin-import/apply", handler.ApplyThemeSegmentImport)
+	return router
+}
+
+func postThemeSegmentImport(router *gin.Engine, target string, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPreviewThemeSegmentImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	itemA, itemB, itemC := "item-1", "item-2", "item-3"
+	repo := &themeSegmentImportRepoStub{
+		fansubReleaseThemeRepoStub: &fansubReleaseThemeRepoStub{},
+		episodes: []models.ThemeSegmentImportEpisode{
+			{EpisodeNumber: 1, ReleaseVariantID: 11, JellyfinItemID: &itemA},
+			{EpisodeNumber: 2, ReleaseVariantID: 12, JellyfinItemID: &itemB},
+			{EpisodeNumber: 3, ReleaseVariantID: 13, JellyfinItemID: &itemC},
+			{EpisodeNumber: 4, ReleaseVariantID: 14},
+		},
+	}
+	provider := mediaservertest.NewProvider()
+	for _, itemID := range []string{itemA, itemB, itemC} {
+		provider.MediaSegments[itemID] = []mediaserver.MediaSegment{
+			{ItemID: itemID, Type: mediaserver.MediaSegmentTypeIntro, StartTicks: 900_000_000, EndTicks: 1_800_000_000},
+		}
+	}
+	router := newThemeSegmentImportTestRouter(repo, provider)
+
+	rec := postThemeSegmentImport(router, "/api/v1/admin/anime/3/segments/jellyfin-import/preview", `{"fansub_group_id":5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data models.ThemeSegmentImportPreview `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode preview: %v", err)
+	}
+	preview := resp.Data
+	if preview.Version != "v1" || preview.ToleranceSeconds != 3 || preview.EpisodeCount != 4 ||
+		len(preview.EpisodesWithSegments) != 3 || len(preview.EpisodesWithoutItem) != 1 || preview.EpisodesWithoutItem[0] != 4 {
+		t.Fatalf("unexpected preview summary %+v", preview)
+	}
+	if len(preview.Proposals) != 1 || preview.Proposals[0].StartEpisode != 1 || preview.Proposals[0].EndEpisode != 3 ||
+		preview.Proposals[0].StartTime != "00:01:30" || *preview.Proposals[0].SuggestedThemeID != 7 {
+		t.Fatalf("unexpected proposals %+v", preview.Proposals)
+	}
+
+	rec = postThemeSegmentImport(router, "/api/v1/admin/anime/3/segments/jellyfin-import/preview", `{"fansub_group_id":5,"tolerance_seconds":90}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for tolerance, got %d", rec.Code)
+	}
+
+	provider.Err = context.DeadlineExceeded
+	rec = postThemeSegmentImport(router, "/api/v1/admin/anime/3/segments/jellyfin-import/preview", `{"fansub_group_id":5}`)
+	if rec.Code != http.StatusBadGateway || decodeErrorMessage(t, rec.Body.Bytes()) != "server nicht erreichbar" {
+		t.Fatalf("expected 502, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestApplyThemeSegmentImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	one, two := 1, 2
+	start, end := "00:01:00", "00:02:30"
+	repo := &themeSegmentImportRepoStub{
+		fansubReleaseThemeRepoStub: &fansubReleaseThemeRepoStub{},
+		segments: []models.AdminThemeSegment{
+			{ID: 40, ThemeID: 7, ThemeTypeName: "OP Kara", StartEpisode: &one, EndEpisode: &two, StartTime: &start, EndTime: &end},
+		},
+	}
+	router := newThemeSegmentImportTestRouter(repo, mediaservertest.NewProvider())
+	target := "/api/v1/admin/anime/3/segments/jellyfin-import/apply"
+
+	rec := postThemeSegmentImport(router, target, `{"fansub_group_id":5,"items":[{"theme_id":7,"start_episode":1,"end_episode":3,"start_time":"00:01:30","end_time":"00:03:00"}]}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), `"code":"segment_conflict"`) ||
+		!strings.Contains(rec.Body.String(), `"segment_id":40`) {
+		t.Fatalf("expected conflict with segment 40, got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("expected nothing to be created on conflict")
+	}
+
+	rec = postThemeSegmentImport(router, target, `{"fansub_group_id":5,"items":[{"theme_id":8,"start_episode":1,"end_episode":2,"start_time":"00:21:00","end_time":"00:22:30"},{"theme_id":8,"start_episode":2,"end_episode":3,"start_time":"00:21:00","end_time":"00:22:30"}]}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected conflict between items, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = postThemeSegmentImport(router, target, `{"fansub_group_id":5,"items":[{"theme_id":99,"start_episode":1,"end_episode":3,"start_time":"00:01:30","end_time":"00:03:00"}]}`)
+	if rec.Code != http.StatusBadRequest || decodeErrorMessage(t, rec.Body.Bytes()) != "theme_id gehört nicht zum anime" {
+		t.Fatalf("expected 400 for foreign theme, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = postThemeSegmentImport(router, target, `{"fansub_group_id":5,"items":[{"theme_id":7,"start_episode":1,"end_episode":3,"start_time":"00:01:30","end_time":"00:03:00","replace_segment_ids":[40]},{"theme_id":8,"start_episode":1,"end_episode":3,"start_time":"00:21:00","end_time":"00:22:30"}]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data models.ThemeSegmentImportApplyResult `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode apply result: %v", err)
+	}
+	if len(resp.Data.Created) != 2 || len(resp.Data.ReplacedSegmentIDs) != 1 || resp.Data.ReplacedSegmentIDs[0] != 40 {
+		t.Fatalf("unexpected apply result %+v", resp.Data)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != 40 {
+		t.Fatalf("expected segment 40 to be deleted, got %v", repo.deleted)
+	}
+	created := repo.created[0]
+	if created.FansubGroupID == nil || *created.FansubGroupID != 5 || created.Version != "v1" ||
+		*created.StartEpisode != 1 || *created.EndEpisode != 3 || *created.StartTime != "00:01:30" {
+		t.Fatalf("unexpected create input %+v", created)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/permissions"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mailOutboxRepository kapselt die Admin-Zugriffe auf den Mail-Outbox.
+type mailOutboxRepository interface {
+	List(ctx context.Context, filter models.MailOutboxFilter) ([]models.MailOutboxItem, int64, error)
+	Requeue(ctx context.Context, id int64) (*models.MailOutboxItem, error)
+}
+
+// AdminMailOutboxHandler stellt die Admin-Ansicht des Mail-Outbox bereit
+// (nur Platform-Admins, requirePlatformAdminIdentity im Handler).
+type AdminMailOutboxHandler struct {
+	authzRepo    capabilityAuthzRepo
+	repo         mailOutboxRepository
+	auditLogRepo auditLogWriter
+}
+
+// NewAdminMailOutboxHandler erstellt einen neuen AdminMailOutboxHandler.
+func NewAdminMailOutboxHandler(
+	authzRepo capabilityAuthzRepo,
+	repo mailOutboxRepository,
+	auditLogRepo auditLogWriter,
+) *AdminMailOutboxHandler {
+	return &AdminMailOutboxHandler{authzRepo: authzRepo, repo: repo, auditLogRepo: auditLogRepo}
+}
+
+// List verarbeitet GET /api/v1/admin/mail-outbox mit optionalem Filter
+// status=pending|sending|sent|dead. Template-Daten werden nicht ausgeliefert.
+func (h *AdminMailOutboxHandler) List(c *gin.Context) {
+	if _, ok := requirePlatformAdminIdentity(c, h.authzRepo, ""); !ok {
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	if status != "" && !models.IsMailOutboxStatus(status) {
+		badRequest(c, "ungültiger status parameter")
+		return
+	}
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "50"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 200 {
+		perPage = 200
+	}
+
+	items, total, err := h.repo.List(c.Request.Context(), models.MailOutboxFilter{
+		Status:  status,
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		log.Printf("mail outbox: admin list failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// Resend verarbeitet POST /api/v1/admin/mail-outbox/:id/resend. Tote Mails und gesendete
+// Mails mit erhaltenen Template-Daten werden mit zurückgesetzten Versuchen neu eingereiht.
+func (h *AdminMailOutboxHandler) Resend(c *gin.Context) {
+	identity, ok := requirePlatformAdminIdentity(c, h.authzRepo, "")
+	if !ok {
+		return
+	}
+
+	id, err := parsePositiveID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige mail id")
+		return
+	}
+
+	item, err := h.repo.Requeue(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "mail nicht gefunden")
+		return
+	case errors.Is(err, repository.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "mail kann nicht erneut gesendet werden"}})
+		return
+	case err != nil:
+		log.Printf("mail outbox: requeue failed (id=%d): %v", id, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	if h.auditLogRepo != nil {
+		var actorAppUserID *int64
+		if identity.AppUserID > 0 {
+			actorAppUserID = &identity.AppUserID
+		}
+		_ = h.auditLogRepo.Write(c.Request.Context(), repository.AuditLogEntry{
+			ActorAppUserID: actorAppUserID,
+			EventType:      "mail_outbox.resend",
+			ScopeType:      permissions.ScopeTypePlatform,
+			TargetType:     "mail_outbox",
+			TargetID:       &item.ID,
+			Action:         "mail_outbox.resend",
+			Outcome:        "allowed",
+			Payload: map[string]any{
+				"template_name": item.TemplateName,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
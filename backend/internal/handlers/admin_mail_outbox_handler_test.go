@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mailOutboxRepoStub struct {
+	filter     models.MailOutboxFilter
+	requeueErr error
+	requeued   []int64
+}
+
+func (s *mailOutboxRepoStub) List(_ context.Context, filter models.MailOutboxFilter) ([]models.MailOutboxItem, int64, error) {
+	s.filter = filter
+	return []models.MailOutboxItem{{ID: 3, TemplateName: "notification", Status: filter.Status}}, 1, nil
+}
+
+func (s *mailOutboxRepoStub) Requeue(_ context.Context, id int64) (*models.MailOutboxItem, error) {
+	if s.requeueErr != nil {
+		return nil, s.requeueErr
+	}
+	s.requeued = append(s.requeued, id)
+	return &models.MailOutboxItem{ID: id, TemplateName: "notification", Status: models.MailOutboxStatusPending}, nil
+}
+
+func mailOutboxAdminIdentity() middleware.AuthIdentity {
+	return middleware.AuthIdentity{UserID: 1, AppUserID: 1, AppUserStatus: models.AppUserStatusActive, DisplayName: "Admin"}
+}
+
+func TestAdminMailOutboxListRequiresPlatformAdmin(t *testing.T) {
+	c, rec := makeCapabilityTestContext(http.MethodGet, "/admin/mail-outbox", mailOutboxAdminIdentity())
+	repo := &mailOutboxRepoStub{}
+
+	NewAdminMailOutboxHandler(&stubCapabilityAuthzRepo{isPlatformAdmin: false}, repo, nil).List(c)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
+func TestAdminMailOutboxListFiltersByStatus(t *testing.T) {
+	c, rec := makeCapabilityTestContext(http.MethodGet, "/admin/mail-outbox?status=dead&per_page=500", mailOutboxAdminIdentity())
+	repo := &mailOutboxRepoStub{}
+
+	NewAdminMailOutboxHandler(&stubCapabilityAuthzRepo{isPlatformAdmin: true}, repo, nil).List(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d with body %s", rec.Code, rec.Body.String())
+	}
+	if repo.filter.Status != models.MailOutboxStatusDead || repo.filter.PerPage != 200 {
+		t.Fatalf("expected dead filter capped to 200 per page, got %+v", repo.filter)
+	}
+
+	c, rec = makeCapabilityTestContext(http.MethodGet, "/admin/mail-outbox?status=lost", mailOutboxAdminIdentity())
+	NewAdminMailOutboxHandler(&stubCapabilityAuthzRepo{isPlatformAdmin: true}, repo, nil).List(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown status, got %d", rec.Code)
+	}
+}
+
+func TestAdminMailOutboxResend(t *testing.T) {
+	tests := []struct {
+		name       string
+		requeueErr error
+		wantStatus int
+		wantAudit  int
+	}{
+		{name: "requeued", wantStatus: http.StatusOK, wantAudit: 1},
+		{name: "missing", requeueErr: repository.ErrNotFound, wantStatus: http.StatusNotFound},
+		{name: "redacted or in flight", requeueErr: repository.ErrConflict, wantStatus: http.StatusConflict},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := makeCapabilityTestContext(http.MethodPost, "/admin/mail-outbox/7/resend", mailOutboxAdminIdentity())
+			c.Params = gin.Params{{Key: "id", Value: "7"}}
+			repo := &mailOutboxRepoStub{requeueErr: tc.requeueErr}
+			audit := &captureAuditLogRepo{}
+
+			NewAdminMailOutboxHandler(&stubCapabilityAuthzRepo{isPlatformAdmin: true}, repo, audit).Resend(c)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected %d, got %d with body %s", tc.wantStatus, rec.Code, rec.Body.String())
+			}
+			if len(audit.entries) != tc.wantAudit {
+				t.Fatalf("expected %d audit entries, got %d", tc.wantAudit, len(audit.entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminPlaybackGrantBlockListLimit = 200
+
+// adminPlaybackSecurityStore liest Sicherheitsereignisse und verwaltet Grant-Sperren
+// (implementiert von repository.PlaybackSecurityRepository).
+type adminPlaybackSecurityStore interface {
+	ListEvents(ctx context.Context, filter models.PlaybackSecurityEventFilter) ([]models.PlaybackSecurityEvent, int64, error)
+	ListGrantBlocks(ctx context.Context, userID int64, activeOnly bool, limit int) ([]models.PlaybackGrantBlock, error)
+	LiftGrantBlock(ctx context.Context, blockID int64, actorAppUserID *int64) (*models.PlaybackGrantBlock, error)
+}
+
+// WithPlaybackSecurity aktiviert die Admin-Endpunkte für Sicherheitsereignisse der Wiedergabe.
+func (h *AdminContentHandler) WithPlaybackSecurity(store adminPlaybackSecurityStore) *AdminContentHandler {
+	h.playbackSecurity = store
+	return h
+}
+
+// ListPlaybackSecurityEvents verarbeitet GET /api/v1/admin/playback-security/events mit den
+// optionalen Filtern user_id, type, severity sowie from/to (RFC 3339).
+func (h *AdminContentHandler) ListPlaybackSecurityEvents(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if !h.ensurePlaybackSecurityConfigured(c) {
+		return
+	}
+
+	filter := models.PlaybackSecurityEventFilter{}
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		parsed, err := parsePositiveID(raw)
+		if err != nil {
+			badRequest(c, "ungültiger user_id parameter")
+			return
+		}
+		filter.UserID = parsed
+	}
+	filter.EventType = strings.ToLower(strings.TrimSpace(c.Query("type")))
+	if filter.EventType != "" && !models.IsPlaybackSecurityEventType(filter.EventType) {
+		badRequest(c, "ungültiger type parameter")
+		return
+	}
+	filter.Severity = strings.ToLower(strings.TrimSpace(c.Query("severity")))
+	if filter.Severity != "" && !models.IsPlaybackSecuritySeverity(filter.Severity) {
+		badRequest(c, "ungültiger severity parameter")
+		return
+	}
+	from, err := parseOptionalRFC3339(c.Query("from"))
+	if err != nil {
+		badRequest(c, "ungültiger from parameter")
+		return
+	}
+	to, err := parseOptionalRFC3339(c.Query("to"))
+	if err != nil {
+		badRequest(c, "ungültiger to parameter")
+		return
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		badRequest(c, "from muss vor to liegen")
+		return
+	}
+	filter.From, filter.To = from, to
+
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "50"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 200 {
+		perPage = 200
+	}
+	filter.Page, filter.PerPage = page, perPage
+
+	items, total, err := h.playbackSecurity.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		log.Printf("admin_content playback_security: list events failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// ListPlaybackGrantBlocks verarbeitet GET /api/v1/admin/playback-security/blocks mit den
+// optionalen Filtern user_id und active=true.
+func (h *AdminContentHandler) ListPlaybackGrantBlocks(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if !h.ensurePlaybackSecurityConfigured(c) {
+		return
+	}
+
+	var userID int64
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		parsed, err := parsePositiveID(raw)
+		if err != nil {
+			badRequest(c, "ungültiger user_id parameter")
+			return
+		}
+		userID = parsed
+	}
+	activeOnly := false
+	if raw := strings.TrimSpace(c.Query("active")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			badRequest(c, "ungültiger active parameter")
+			return
+		}
+		activeOnly = parsed
+	}
+
+	blocks, err := h.playbackSecurity.ListGrantBlocks(c.Request.Context(), userID, activeOnly, adminPlaybackGrantBlockListLimit)
+	if err != nil {
+		log.Printf("admin_content playback_security: list blocks failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": blocks, "meta": gin.H{"total": len(blocks)}})
+}
+
+// LiftPlaybackGrantBlock verarbeitet DELETE /api/v1/admin/playback-security/blocks/:id und hebt
+// eine aktive Grant-Sperre vorzeitig auf.
+func (h *AdminContentHandler) LiftPlaybackGrantBlock(c *gin.Context) {
+	identity, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+	if !h.ensurePlaybackSecurityConfigured(c) {
+		return
+	}
+
+	blockID, err := parsePositiveID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige sperr-id")
+		return
+	}
+
+	ctx := c.Request.Context()
+	block, err := h.playbackSecurity.LiftGrantBlock(ctx, blockID, appUserIDPtr(identity.AppUserID))
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "aktive grant-sperre nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("admin_content playback_security: lift block failed (block_id=%d): %v", blockID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	if err := h.auditLogRepo.Write(ctx, repository.AuditLogEntry{
+		ActorAppUserID: appUserIDPtr(identity.AppUserID),
+		EventType:      "playback_grant_block.lifted",
+		ScopeType:      "user",
+		ScopeID:        &block.UserID,
+		TargetType:     "playback_grant_block",
+		TargetID:       &block.ID,
+		Action:         "playback_grant_block.lift",
+		Outcome:        "allowed",
+		Payload: map[string]any{
+			"user_id":       block.UserID,
+			"reason":        block.Reason,
+			"blocked_until": block.BlockedUntil,
+		},
+	}); err != nil {
+		log.Printf("admin_content playback_security: audit lift failed (block_id=%d): %v", block.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": block})
+}
+
+func (h *AdminContentHandler) ensurePlaybackSecurityConfigured(c *gin.Context) bool {
+	if h.playbackSecurity == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "playback-sicherheitsereignisse sind nicht konfiguriert"}})
+		return false
+	}
+	return true
+}
+
+// parseOptionalRFC3339 parst einen optionalen Zeitpunkt; leere Werte ergeben nil.
+func parseOptionalRFC3339(raw string) (*time.Time, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, trimmed)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeAdminPlaybackSecurityStore struct {
+	lastFilter models.PlaybackSecurityEventFilter
+	blocks     []models.PlaybackGrantBlock
+	lastActive bool
+}
+
+func (s *fakeAdminPlaybackSecurityStore) ListEvents(_ context.Context, filter models.PlaybackSecurityEventFilter) ([]models.PlaybackSecurityEvent, int64, error) {
+	s.lastFilter = filter
+	return []models.PlaybackSecurityEvent{{ID: 1, EventType: filter.EventType, Severity: models.PlaybackSecuritySeverityWarning}}, 51, nil
+}
+
+func (s *fakeAdminPlaybackSecurityStore) ListGrantBlocks(_ context.Context, userID int64, activeOnly bool, _ int) ([]models.PlaybackGrantBlock, error) {
+	s.lastActive = activeOnly
+	items := make([]models.PlaybackGrantBlock, 0, len(s.blocks))
+	for _, block := range s.blocks {
+		if userID <= 0 || block.UserID == userID {
+			items = append(items, block)
+		}
+	}
+	return items, nil
+}
+
+func (s *fakeAdminPlaybackSecurityStore) LiftGrantBlock(_ context.Context, blockID int64, actorAppUserID *int64) (*models.PlaybackGrantBlock, error) {
+	for i := range s.blocks {
+		if s.blocks[i].ID == blockID && s.blocks[i].LiftedAt == nil {
+			now := time.Now()
+			s.blocks[i].LiftedAt = &now
+			s.blocks[i].LiftedByAppUserID = actorAppUserID
+			block := s.blocks[i]
+			return &block, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestAdminPlaybackSecurityEventsFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &fakeAdminPlaybackSecurityStore{}
+	h := (&AdminContentHandler{authzRepo: stubAdminRoleChecker{allowed: true}}).WithPlaybackSecurity(store)
+
+	router := gin.New()
+	router.GET("/api/v1/admin/playback-security/events", withTestAdminIdentity(), h.ListPlaybackSecurityEvents)
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := serve("/api/v1/admin/playback-security/events?user_id=5&type=grant_replay&severity=critical&from=2026-01-01T00:00:00Z&to=2026-01-02T00:00:00Z&per_page=25&page=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	filter := store.lastFilter
+	if filter.UserID != 5 || filter.EventType != "grant_replay" || filter.Severity != "critical" || filter.Page != 2 || filter.PerPage != 25 {
+		t.Fatalf("unexpected filter: %+v", filter)
+	}
+	if filter.From == nil || filter.To == nil || filter.To.Sub(*filter.From) != 24*time.Hour {
+		t.Fatalf("expected time range to be parsed, got %+v", filter)
+	}
+
+	for _, query := range []string{
+		"?type=unknown",
+		"?severity=fatal",
+		"?user_id=0",
+		"?from=yesterday",
+		"?from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z",
+	} {
+		if rec := serve("/api/v1/admin/playback-security/events" + query); rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestAdminPlaybackGrantBlocksListAndLift(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &fakeAdminPlaybackSecurityStore{blocks: []models.PlaybackGrantBlock{
+		{ID: 1, UserID: 5, BlockedUntil: time.Now().Add(time.Hour)},
+		{ID: 2, UserID: 6, BlockedUntil: time.Now().Add(time.Hour)},
+	}}
+	h := (&AdminContentHandler{authzRepo: stubAdminRoleChecker{allowed: true}}).WithPlaybackSecurity(store)
+
+	router := gin.New()
+	router.GET("/api/v1/admin/playback-security/blocks", withTestAdminIdentity(), h.ListPlaybackGrantBlocks)
+	router.DELETE("/api/v1/admin/playback-security/blocks/:id", withTestAdminIdentity(), h.LiftPlaybackGrantBlock)
+	serve := func(method string, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	if rec := serve(http.MethodGet, "/api/v1/admin/playback-security/blocks?user_id=6&active=true"); rec.Code != http.StatusOK || !store.lastActive {
+		t.Fatalf("expected active filter to be passed, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodGet, "/api/v1/admin/playback-security/blocks?active=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid active, got %d", rec.Code)
+	}
+
+	if rec := serve(http.MethodDelete, "/api/v1/admin/playback-security/blocks/1"); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 on lift, got %d", rec.Code)
+	}
+	if store.blocks[0].LiftedAt == nil {
+		t.Fatalf("expected block 1 to be lifted")
+	}
+	if rec := serve(http.MethodDelete, "/api/v1/admin/playback-security/blocks/1"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for lifted block, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodDelete, "/api/v1/admin/playback-security/blocks/abc"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid id, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminPlaybackSessionStore listet und beendet laufende Playback-Sessions (implementiert von
+// repository.PlaybackSessionRepository).
+type adminPlaybackSessionStore interface {
+	List(ctx context.Context, userID int64) ([]models.PlaybackSession, error)
+	Terminate(ctx context.Context, sessionID string) (*models.PlaybackSession, error)
+}
+
+// WithPlaybackSessions aktiviert die Admin-Endpunkte für laufende Playback-Sessions.
+func (h *AdminContentHandler) WithPlaybackSessions(store adminPlaybackSessionStore) *AdminContentHandler {
+	h.playbackSessions = store
+	return h
+}
+
+// ListPlaybackSessions verarbeitet GET /api/v1/admin/playback-sessions mit optionalem Filter
+// user_id und liefert alle laufenden Sessions aller Instanzen.
+func (h *AdminContentHandler) ListPlaybackSessions(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if !h.ensurePlaybackSessionsConfigured(c) {
+		return
+	}
+
+	var userID int64
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		parsed, err := parsePositiveID(raw)
+		if err != nil {
+			badRequest(c, "ungültiger user_id parameter")
+			return
+		}
+		userID = parsed
+	}
+
+	sessions, err := h.playbackSessions.List(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("admin_content playback_sessions: list failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions, "meta": gin.H{"total": len(sessions)}})
+}
+
+// TerminatePlaybackSession verarbeitet DELETE /api/v1/admin/playback-sessions/:sessionId. Weitere
+// Stream-Abrufe der Session werden danach abgewiesen und ihr Slot ist sofort frei.
+func (h *AdminContentHandler) TerminatePlaybackSession(c *gin.Context) {
+	identity, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+	if !h.ensurePlaybackSessionsConfigured(c) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	session, err := h.playbackSessions.Terminate(ctx, strings.TrimSpace(c.Param("sessionId")))
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "playback-session nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("admin_content playback_sessions: terminate failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	if err := h.auditLogRepo.Write(ctx, repository.AuditLogEntry{
+		ActorAppUserID: appUserIDPtr(identity.AppUserID),
+		EventType:      "playback_session.terminated",
+		ScopeType:      "episode",
+		ScopeID:        &session.EpisodeID,
+		TargetType:     "playback_session",
+		Action:         "playback_session.terminate",
+		Outcome:        "allowed",
+		Payload: map[string]any{
+			"session_id": session.ID,
+			"user_id":    session.UserID,
+			"episode_id": session.EpisodeID,
+			"client_ip":  session.ClientIP,
+		},
+	}); err != nil {
+		log.Printf("admin_content playback_sessions: audit terminate failed (episode_id=%d): %v", session.EpisodeID, err)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminContentHandler) ensurePlaybackSessionsConfigured(c *gin.Context) bool {
+	if h.playbackSessions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "playback-sessions sind nicht konfiguriert"}})
+		return false
+	}
+	return true
+}
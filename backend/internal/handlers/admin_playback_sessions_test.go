@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminPlaybackSessionsListAndTerminate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newFakePlaybackSessionStore(0, 0)
+	store.sessions["session-1"] = &models.PlaybackSession{ID: "session-1", UserID: 2, EpisodeID: 76}
+	store.sessions["session-2"] = &models.PlaybackSession{ID: "session-2", UserID: 3, EpisodeID: 77}
+	h := (&AdminContentHandler{authzRepo: stubAdminRoleChecker{allowed: true}}).WithPlaybackSessions(store)
+
+	router := gin.New()
+	router.GET("/api/v1/admin/playback-sessions", withTestAdminIdentity(), h.ListPlaybackSessions)
+	router.DELETE("/api/v1/admin/playback-sessions/:sessionId", withTestAdminIdentity(), h.TerminatePlaybackSession)
+	serve := func(method string, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/api/v1/admin/playback-sessions?user_id=3")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"session-2"`) || strings.Contains(rec.Body.String(), `"session-1"`) {
+		t.Fatalf("expected only session-2 for user 3, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodGet, "/api/v1/admin/playback-sessions?user_id=abc"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid user_id, got %d", rec.Code)
+	}
+
+	if rec := serve(http.MethodDelete, "/api/v1/admin/playback-sessions/session-1"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if _, ok := store.sessions["session-1"]; ok {
+		t.Fatalf("expected session-1 to be terminated")
+	}
+	if rec := serve(http.MethodDelete, "/api/v1/admin/playback-sessions/session-1"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for terminated session, got %d", rec.Code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/middleware"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
@@ -34,6 +35,7 @@ type AnimeHandler struct {
 	assetRepo       *repository.AnimeAssetRepository
 	jellyfinAPIKey  string
 	jellyfinBaseURL string
+	mediaServer     mediaserver.MediaServerProvider
 	httpClient      *http.Client
 }
 
@@ -21,7 +21,7 @@ func (h *AnimeHandler) ListBackdrops(c *gin.Context) {
 
 	result := models.AnimeBackdropManifest{
 		AnimeID:     animeID,
-		Provider:    "jellyfin",
+		Provider:    h.backdropMediaServer().Kind(),
 		Backdrops:   []string{},
 		ThemeVideos: []string{},
 	}
@@ -87,7 +87,7 @@ func (h *AnimeHandler) ListBackdrops(c *gin.Context) {
 		return
 	}
 
-	if strings.TrimSpace(h.jellyfinBaseURL) == "" || strings.TrimSpace(h.jellyfinAPIKey) == "" {
+	if !h.backdropMediaServer().Configured() {
 		c.JSON(http.StatusOK, gin.H{"data": result})
 		return
 	}
@@ -113,7 +113,7 @@ func (h *AnimeHandler) ListBackdrops(c *gin.Context) {
 		result.Backdrops = h.probeJellyfinBackdropProxyURLs(c.Request.Context(), result.MediaItemID)
 	}
 	if len(result.Backdrops) == 0 {
-		result.Backdrops = buildAnimeBackdropProxyURLs(result.MediaItemID, 1)
+		result.Backdrops = buildAnimeBackdropProxyURLs(h.backdropMediaServer().Kind(), result.MediaItemID, 1)
 	}
 	if strings.TrimSpace(result.LogoURL) == "" {
 		result.LogoURL = h.probeJellyfinLogoProxyURL(c.Request.Context(), result.MediaItemID)
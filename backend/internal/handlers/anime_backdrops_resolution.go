@@ -2,8 +2,6 @@ package handlers
 
 import (
 	"context"
-	"net/url"
-	"strconv"
 	"strings"
 
 	"team4s.v3/backend/internal/models"
@@ -107,23 +105,5 @@ func findExactAnimeJellyfinSeriesMatches(items []animeJellyfinSeriesItem, title
 }
 
 func (h *AnimeHandler) searchJellyfinSeries(ctx context.Context, title string, limit int) ([]animeJellyfinSeriesItem, error) {
-	values := url.Values{}
-	values.Set("IncludeItemTypes", "Series")
-	values.Set("Recursive", "true")
-	values.Set("SearchTerm", strings.TrimSpace(title))
-	values.Set("Limit", strconv.Itoa(limit))
-
-	var payload animeJellyfinSeriesListResponse
-	if _, err := h.fetchJellyfinJSON(ctx, "/Items", values, &payload); err != nil {
-		return nil, err
-	}
-
-	items := make([]animeJellyfinSeriesItem, 0, len(payload.Items))
-	for _, item := range payload.Items {
-		if strings.TrimSpace(item.ID) != "" {
-			items = append(items, item)
-		}
-	}
-
-	return items, nil
+	return h.backdropMediaServer().SearchSeries(ctx, title, limit)
 }
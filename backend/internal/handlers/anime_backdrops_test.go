@@ -7,10 +7,13 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/mediaserver/mediaservertest"
 )
 
 func TestBuildAnimeBackdropProxyURLs(t *testing.T) {
-	urls := buildAnimeBackdropProxyURLs("abc123", 4)
+	urls := buildAnimeBackdropProxyURLs(mediaserver.KindJellyfin, "abc123", 4)
 	if len(urls) != 4 {
 		t.Fatalf("expected 4 backdrop urls, got %d", len(urls))
 	}
@@ -95,7 +98,7 @@ func TestProbeJellyfinBackdropProxyURLs_UsesIndexZeroFallbackWhenDefaultMissing(
 }
 
 func TestBuildAnimeBackdropVideoProxyURL(t *testing.T) {
-	got := buildAnimeBackdropVideoProxyURL("0eae7971916d6971c6613e132d7d6048")
+	got := buildAnimeBackdropVideoProxyURL(mediaserver.KindJellyfin, "0eae7971916d6971c6613e132d7d6048")
 	want := "/api/v1/media/video?item_id=0eae7971916d6971c6613e132d7d6048&provider=jellyfin"
 	if got != want {
 		t.Fatalf("unexpected video url: got=%q want=%q", got, want)
@@ -103,7 +106,7 @@ func TestBuildAnimeBackdropVideoProxyURL(t *testing.T) {
 }
 
 func TestBuildAnimeBannerProxyURL(t *testing.T) {
-	got := buildAnimeBannerProxyURL("abc123")
+	got := buildAnimeBannerProxyURL(mediaserver.KindJellyfin, "abc123")
 	want := "/api/v1/media/image?item_id=abc123&kind=banner&provider=jellyfin"
 	if got != want {
 		t.Fatalf("unexpected banner url: got=%q want=%q", got, want)
@@ -173,3 +176,29 @@ func TestProbeJellyfinBannerProxyURL(t *testing.T) {
 		t.Fatalf("unexpected banner proxy url: got=%q want=%q", got, want)
 	}
 }
+
+func TestProbeBackdropsUseConfiguredMediaServer(t *testing.T) {
+	provider := mediaservertest.NewProvider()
+	provider.KindName = mediaserver.KindEmby
+	provider.ThemeVideos["abc123"] = []string{"video-1"}
+	index := 1
+	provider.Images[mediaservertest.ImageKey("abc123", "Backdrop", &index)] = true
+	provider.Images[mediaservertest.ImageKey("abc123", "Logo", nil)] = true
+
+	handler := (&AnimeHandler{}).WithMediaServer(provider)
+	ctx := context.Background()
+
+	backdrops := handler.probeJellyfinBackdropProxyURLs(ctx, "abc123")
+	if want := []string{"/api/v1/media/image?index=1&item_id=abc123&kind=backdrop&provider=emby"}; !reflect.DeepEqual(backdrops, want) {
+		t.Fatalf("unexpected backdrop urls: got=%v want=%v", backdrops, want)
+	}
+	if got := handler.probeJellyfinThemeVideoProxyURLs(ctx, "abc123"); !reflect.DeepEqual(got, []string{"/api/v1/media/video?item_id=video-1&provider=emby"}) {
+		t.Fatalf("unexpected theme video urls: %v", got)
+	}
+	if got := handler.probeJellyfinLogoProxyURL(ctx, "abc123"); got != "/api/v1/media/image?item_id=abc123&kind=logo&provider=emby" {
+		t.Fatalf("unexpected logo url: %q", got)
+	}
+	if got := handler.probeJellyfinBannerProxyURL(ctx, "abc123"); got != "" {
+		t.Fatalf("expected no banner url, got %q", got)
+	}
+}
@@ -1,13 +1,8 @@
 package handlers
 
-type animeJellyfinSeriesListResponse struct {
-	Items []animeJellyfinSeriesItem `json:"Items"`
-}
+import "team4s.v3/backend/internal/mediaserver"
 
-type animeJellyfinSeriesItem struct {
-	ID   string `json:"Id"`
-	Name string `json:"Name"`
-}
+type animeJellyfinSeriesItem = mediaserver.Series
 
 type animeJellyfinThemeVideosResponse struct {
 	Items []animeJellyfinThemeVideoItem `json:"Items"`
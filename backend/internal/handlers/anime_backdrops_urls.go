@@ -8,7 +8,7 @@ import (
 
 const maxAnimeBackdropCandidates = 12
 
-func buildAnimeBackdropProxyURLs(seriesID string, backdropCount int) []string {
+func buildAnimeBackdropProxyURLs(provider string, seriesID string, backdropCount int) []string {
 	trimmedSeriesID := strings.TrimSpace(seriesID)
 	if trimmedSeriesID == "" {
 		return []string{}
@@ -22,23 +22,23 @@ func buildAnimeBackdropProxyURLs(seriesID string, backdropCount int) []string {
 	}
 
 	result := make([]string, 0, backdropCount)
-	result = append(result, buildAnimeBackdropProxyURL(trimmedSeriesID, nil))
+	result = append(result, buildAnimeBackdropProxyURL(provider, trimmedSeriesID, nil))
 	for i := 1; i < backdropCount; i++ {
 		index := i
-		result = append(result, buildAnimeBackdropProxyURL(trimmedSeriesID, &index))
+		result = append(result, buildAnimeBackdropProxyURL(provider, trimmedSeriesID, &index))
 	}
 
 	return result
 }
 
-func buildAnimeBackdropProxyURL(seriesID string, index *int) string {
+func buildAnimeBackdropProxyURL(provider string, seriesID string, index *int) string {
 	trimmedSeriesID := strings.TrimSpace(seriesID)
 	if trimmedSeriesID == "" {
 		return ""
 	}
 
 	query := url.Values{}
-	query.Set("provider", "jellyfin")
+	query.Set("provider", provider)
 	query.Set("item_id", trimmedSeriesID)
 	query.Set("kind", "backdrop")
 	if index != nil {
@@ -48,41 +48,41 @@ func buildAnimeBackdropProxyURL(seriesID string, index *int) string {
 	return "/api/v1/media/image?" + query.Encode()
 }
 
-func buildAnimeBackdropVideoProxyURL(itemID string) string {
+func buildAnimeBackdropVideoProxyURL(provider string, itemID string) string {
 	trimmedItemID := strings.TrimSpace(itemID)
 	if trimmedItemID == "" {
 		return ""
 	}
 
 	query := url.Values{}
-	query.Set("provider", "jellyfin")
+	query.Set("provider", provider)
 	query.Set("item_id", trimmedItemID)
 
 	return "/api/v1/media/video?" + query.Encode()
 }
 
-func buildAnimeLogoProxyURL(seriesID string) string {
+func buildAnimeLogoProxyURL(provider string, seriesID string) string {
 	trimmedSeriesID := strings.TrimSpace(seriesID)
 	if trimmedSeriesID == "" {
 		return ""
 	}
 
 	query := url.Values{}
-	query.Set("provider", "jellyfin")
+	query.Set("provider", provider)
 	query.Set("item_id", trimmedSeriesID)
 	query.Set("kind", "logo")
 
 	return "/api/v1/media/image?" + query.Encode()
 }
 
-func buildAnimeBannerProxyURL(seriesID string) string {
+func buildAnimeBannerProxyURL(provider string, seriesID string) string {
 	trimmedSeriesID := strings.TrimSpace(seriesID)
 	if trimmedSeriesID == "" {
 		return ""
 	}
 
 	query := url.Values{}
-	query.Set("provider", "jellyfin")
+	query.Set("provider", provider)
 	query.Set("item_id", trimmedSeriesID)
 	query.Set("kind", "banner")
 
@@ -3,12 +3,13 @@ package handlers
 import (
 	"context"
 	"errors"
-	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 
 	backendauth "team4s.v3/backend/internal/auth"
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/middleware"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/permissions"
@@ -80,11 +81,11 @@ type AppAuthHandler struct {
 	permissionSvc      *permissions.Service
 	auditLogRepo       auditLogWriter
 	tiptapSvc          *services.TipTapService
-	mailer             services.Mailer
 	mediaStorageDir    string
 	mediaBaseURL       string
+	blobs              blobstore.BlobStore
 	keycloakAccountURL string
-	appPublicURL       string
+	notifications      *services.NotificationService
 }
 
 func NewAppAuthHandler(
@@ -99,11 +100,9 @@ func NewAppAuthHandler(
 	permissionSvc *permissions.Service,
 	auditLogRepo *repository.AuditLogRepository,
 	tiptapSvc *services.TipTapService,
-	mailer services.Mailer,
 	mediaStorageDir string,
 	mediaBaseURL string,
 	keycloakAccountURL string,
-	appPublicURL string,
 ) *AppAuthHandler {
 	return &AppAuthHandler{
 		appAuthRepo:        appAuthRepo,
@@ -117,14 +116,18 @@ func NewAppAuthHandler(
 		permissionSvc:      permissionSvc,
 		auditLogRepo:       auditLogRepo,
 		tiptapSvc:          tiptapSvc,
-		mailer:             mailer,
 		mediaStorageDir:    strings.TrimSpace(mediaStorageDir),
 		mediaBaseURL:       strings.TrimSpace(mediaBaseURL),
 		keycloakAccountURL: strings.TrimSpace(keycloakAccountURL),
-		appPublicURL:       strings.TrimSpace(appPublicURL),
 	}
 }
 
+// WithNotifications benachrichtigt bereits registrierte Accounts über Gruppeneinladungen.
+func (h *AppAuthHandler) WithNotifications(notifications *services.NotificationService) *AppAuthHandler {
+	h.notifications = notifications
+	return h
+}
+
 func (h *AppAuthHandler) GetCurrentUser(c *gin.Context) {
 	identity, ok := middleware.CommentAuthIdentityFromContext(c)
 	if !ok {
@@ -232,6 +235,7 @@ type setFansubGroupMemberMediaPermissionsRequest struct {
 type createFansubGroupInvitationRequest struct {
 	Email            string   `json:"email"`
 	InvitedRoleCodes []string `json:"invited_role_codes"`
+	Locale           string   `json:"locale"`
 }
 
 type acceptFansubGroupInvitationRequest struct {
@@ -388,10 +392,23 @@ func (h *AppAuthHandler) CreateFansubGroupInvitation(c *gin.Context) {
 		return
 	}
 
+	locale := strings.ToLower(strings.TrimSpace(req.Locale))
+	if locale != "" && !models.IsMailLocale(locale) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": "ungueltige sprache"}})
+		return
+	}
+
+	// Die Einladungsmail wird in derselben Transaktion in den Mail-Outbox geschrieben und vom
+	// Worker versendet. Der Roh-Token steht nur im Outbox-Eintrag und wird nach dem Versand
+	// geleert (D-11); ein SMTP-Ausfall storniert die Einladung nicht mehr.
 	created, err := h.invitationRepo.Create(c.Request.Context(), fansubID, models.FansubGroupInvitationCreateInput{
 		Email:              req.Email,
 		InvitedRoleCodes:   req.InvitedRoleCodes,
 		CreatedByAppUserID: &identity.AppUserID,
+		InviteMail: &models.MailOutboxInput{
+			TemplateName: services.MailTemplateFansubGroupInvitation,
+			Locale:       locale,
+		},
 	})
 	if err != nil {
 		if err == repository.ErrNotFound {
@@ -406,46 +423,8 @@ func (h *AppAuthHandler) CreateFansubGroupInvitation(c *gin.Context) {
 		return
 	}
 
-	// Einladungsmail senden wenn ein Mailer konfiguriert ist.
-	// D-10: Absoluter Link aus AppPublicURL + InviteLink.
-	// D-11: Nur der Roh-Token landet im Mail-Link, nicht in DB oder Audit-Log.
-	// D-12: Bei SMTP-Fehler wird die Einladung sofort storniert (kein stiller fail).
-	if h.mailer != nil {
-		inviteURL := strings.TrimRight(h.appPublicURL, "/") + created.InviteLink
-		mailCtx, mailCancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
-		defer mailCancel()
-
-		mailErr := h.mailer.Send(mailCtx, services.MailMessage{
-			To:       created.Invitation.Email,
-			Subject:  "Einladung zur Fansub-Gruppe",
-			BodyText: fmt.Sprintf("Du wurdest zu einer Fansub-Gruppe eingeladen.\n\nLink zum Annehmen: %s\n\nDieser Link ist 7 Tage gÃ¼ltig.", inviteURL),
-			BodyHTML: fmt.Sprintf(`<p>Du wurdest zu einer Fansub-Gruppe eingeladen.</p><p><a href="%s">Einladung annehmen</a></p><p>Dieser Link ist 7 Tage gÃ¼ltig.</p>`, inviteURL),
-		})
-		if mailErr != nil {
-			// Einladung stornieren damit kein stiller pending-Record verbleibt.
-			_, _ = h.invitationRepo.Cancel(c.Request.Context(), fansubID, created.Invitation.ID, models.FansubGroupInvitationCancelInput{
-				CancelledByAppUserID: &identity.AppUserID,
-			})
-			_ = h.auditLogRepo.Write(c.Request.Context(), repository.AuditLogEntry{
-				ActorAppUserID: &identity.AppUserID,
-				EventType:      "fansub_group_invitation.mail_failed",
-				ScopeType:      permissions.ScopeTypeGroup,
-				ScopeID:        &fansubID,
-				TargetType:     "fansub_group_invitation",
-				TargetID:       &created.Invitation.ID,
-				Action:         string(permissions.ActionFansubGroupInvitationsCreate),
-				Outcome:        "error",
-				Payload: map[string]any{
-					"email": created.Invitation.Email,
-					// Kein Token im Audit-Log (D-11).
-				},
-			})
-			c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{
-				"message":     "Einladung konnte nicht gesendet werden. Bitte prÃ¼fe die SMTP-Konfiguration.",
-				"reason_code": "mail_delivery_failed",
-			}})
-			return
-		}
+	if err := h.notifications.NotifyInvitationReceived(c.Request.Context(), created.Invitation.Email, fansubID, created.InviteLink, identity.AppUserID); err != nil {
+		log.Printf("app auth: notify invitation failed (invitation_id=%d): %v", created.Invitation.ID, err)
 	}
 
 	_ = h.auditLogRepo.Write(c.Request.Context(), repository.AuditLogEntry{
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -77,9 +76,10 @@ func (s *auditLogStub) Write(_ context.Context, entry repository.AuditLogEntry)
 }
 
 type invitationRepoStub struct {
-	listResp   []models.FansubGroupInvitation
-	createResp *models.FansubGroupInvitationCreateResult
-	createErr  error
+	listResp    []models.FansubGroupInvitation
+	createResp  *models.FansubGroupInvitationCreateResult
+	createErr   error
+	createInput *models.FansubGroupInvitationCreateInput
 }
 
 type profileRepoStub struct {
@@ -166,7 +166,8 @@ func (s *invitationRepoStub) ListByFansubGroup(_ context.Context, _ int64) ([]mo
 	return s.listResp, nil
 }
 
-func (s *invitationRepoStub) Create(_ context.Context, _ int64, _ models.FansubGroupInvitationCreateInput) (*models.FansubGroupInvitationCreateResult, error) {
+func (s *invitationRepoStub) Create(_ context.Context, _ int64, input models.FansubGroupInvitationCreateInput) (*models.FansubGroupInvitationCreateResult, error) {
+	s.createInput = &input
 	return s.createResp, s.createErr
 }
 
@@ -1551,24 +1552,11 @@ func appAuthTestStringPtr(value string) *string {
 	return &value
 }
 
-// --- Mailer-Stubs fuer Einladungs-Mail-Tests ---
+// --- Einladungs-Mail ueber den Mail-Outbox ---
 
-type mailerStub struct {
-	sendCalls int
-	sendErr   error
-	lastMsg   services.MailMessage
-}
-
-func (s *mailerStub) Send(_ context.Context, msg services.MailMessage) error {
-	s.sendCalls++
-	s.lastMsg = msg
-	return s.sendErr
-}
-
-func TestCreateFansubGroupInvitationSendsMailOnSuccess(t *testing.T) {
+func TestCreateFansubGroupInvitationQueuesInviteMail(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	mailer := &mailerStub{}
 	invitationRepo := &invitationRepoStub{
 		createResp: &models.FansubGroupInvitationCreateResult{
 			Invitation: models.FansubGroupInvitation{
@@ -1589,11 +1577,9 @@ func TestCreateFansubGroupInvitationSendsMailOnSuccess(t *testing.T) {
 			roles:   map[int64][]string{88: {permissions.RoleFansubLead}},
 		}),
 		auditLogRepo: &auditLogStub{},
-		mailer:       mailer,
-		appPublicURL: "http://team4s.local",
 	}
 
-	body := []byte(`{"email":"invitee@example.local","invited_role_codes":["fansub_lead"]}`)
+	body := []byte(`{"email":"invitee@example.local","invited_role_codes":["fansub_lead"],"locale":"EN"}`)
 	c, recorder := makeAppAuthTestContext(http.MethodPost, "/api/v1/admin/fansubs/88/invitations", body, middleware.AuthIdentity{
 		UserID:        107,
 		AppUserID:     41,
@@ -1606,37 +1592,19 @@ func TestCreateFansubGroupInvitationSendsMailOnSuccess(t *testing.T) {
 	if recorder.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d with body %s", recorder.Code, recorder.Body.String())
 	}
-	if mailer.sendCalls != 1 {
-		t.Fatalf("expected exactly one mail send, got %d", mailer.sendCalls)
+	if invitationRepo.createInput == nil || invitationRepo.createInput.InviteMail == nil {
+		t.Fatalf("expected invite mail to be queued with the invitation, got %+v", invitationRepo.createInput)
 	}
-	if mailer.lastMsg.To != "invitee@example.local" {
-		t.Fatalf("expected mail to invitee@example.local, got %q", mailer.lastMsg.To)
-	}
-	// D-10: absoluter Link aus AppPublicURL + InviteLink
-	if !strings.Contains(mailer.lastMsg.BodyText, "http://team4s.local/invitations/accept?token=testtoken123") {
-		t.Fatalf("expected absolute invite URL in mail body, got %q", mailer.lastMsg.BodyText)
+	mail := invitationRepo.createInput.InviteMail
+	if mail.TemplateName != services.MailTemplateFansubGroupInvitation || mail.Locale != models.MailLocaleEN {
+		t.Fatalf("expected english invitation template, got %+v", mail)
 	}
 }
 
-func TestCreateFansubGroupInvitationCancelsOnMailFailure(t *testing.T) {
+func TestCreateFansubGroupInvitationRejectsUnknownLocale(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	cancelCalls := 0
-	invitationRepo := &invitationRepoStubWithCancelTracking{
-		createResp: &models.FansubGroupInvitationCreateResult{
-			Invitation: models.FansubGroupInvitation{
-				ID:               92,
-				FansubGroupID:    88,
-				Email:            "fail@example.local",
-				InvitedRoleCodes: []string{permissions.RoleFansubLead},
-				Status:           models.FansubGroupInvitationStatusPending,
-				ExpiresAt:        time.Date(2026, 5, 23, 12, 0, 0, 0, time.UTC),
-			},
-			InviteLink: "/invitations/accept?token=failing123",
-		},
-		onCancel: func() { cancelCalls++ },
-	}
-	mailer := &mailerStub{sendErr: errors.New("SMTP connection refused")}
+	invitationRepo := &invitationRepoStub{}
 	handler := &AppAuthHandler{
 		invitationRepo: invitationRepo,
 		permissionSvc: permissions.NewService(permissionResolverStub{
@@ -1644,11 +1612,9 @@ func TestCreateFansubGroupInvitationCancelsOnMailFailure(t *testing.T) {
 			roles:   map[int64][]string{88: {permissions.RoleFansubLead}},
 		}),
 		auditLogRepo: &auditLogStub{},
-		mailer:       mailer,
-		appPublicURL: "http://team4s.local",
 	}
 
-	body := []byte(`{"email":"fail@example.local","invited_role_codes":["fansub_lead"]}`)
+	body := []byte(`{"email":"invitee@example.local","invited_role_codes":["fansub_lead"],"locale":"fr"}`)
 	c, recorder := makeAppAuthTestContext(http.MethodPost, "/api/v1/admin/fansubs/88/invitations", body, middleware.AuthIdentity{
 		UserID:        108,
 		AppUserID:     42,
@@ -1658,20 +1624,11 @@ func TestCreateFansubGroupInvitationCancelsOnMailFailure(t *testing.T) {
 
 	handler.CreateFansubGroupInvitation(c)
 
-	// D-12: SMTP-Fehler darf keine stille pending-Einladung hinterlassen
-	if recorder.Code != http.StatusBadGateway {
-		t.Fatalf("expected 502 on mail failure, got %d with body %s", recorder.Code, recorder.Body.String())
-	}
-	if cancelCalls != 1 {
-		t.Fatalf("expected invitation to be cancelled on mail failure, cancel was called %d times", cancelCalls)
-	}
-	payload := decodeBody(t, recorder)
-	errBody, ok := payload["error"].(map[string]any)
-	if !ok {
-		t.Fatalf("expected error body, got %#v", payload)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported locale, got %d", recorder.Code)
 	}
-	if errBody["reason_code"] != "mail_delivery_failed" {
-		t.Fatalf("expected reason_code mail_delivery_failed, got %#v", errBody["reason_code"])
+	if invitationRepo.createInput != nil {
+		t.Fatal("expected no invitation to be created")
 	}
 }
 
@@ -1679,7 +1636,6 @@ func TestCreateFansubGroupInvitationAuditLogContainsNoRawToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	auditLog := &auditLogStub{}
-	mailer := &mailerStub{}
 	invitationRepo := &invitationRepoStub{
 		createResp: &models.FansubGroupInvitationCreateResult{
 			Invitation: models.FansubGroupInvitation{
@@ -1700,8 +1656,6 @@ func TestCreateFansubGroupInvitationAuditLogContainsNoRawToken(t *testing.T) {
 			roles:   map[int64][]string{88: {permissions.RoleFansubLead}},
 		}),
 		auditLogRepo: auditLog,
-		mailer:       mailer,
-		appPublicURL: "http://team4s.local",
 	}
 
 	body := []byte(`{"email":"check@example.local","invited_role_codes":["fansub_lead"]}`)
@@ -1728,28 +1682,3 @@ func TestCreateFansubGroupInvitationAuditLogContainsNoRawToken(t *testing.T) {
 		}
 	}
 }
-
-// invitationRepoStubWithCancelTracking ermoeglicht Callback bei Cancel-Aufrufen.
-type invitationRepoStubWithCancelTracking struct {
-	createResp *models.FansubGroupInvitationCreateResult
-	onCancel   func()
-}
-
-func (s *invitationRepoStubWithCancelTracking) ListByFansubGroup(_ context.Context, _ int64) ([]models.FansubGroupInvitation, error) {
-	return nil, nil
-}
-
-func (s *invitationRepoStubWithCancelTracking) Create(_ context.Context, _ int64, _ models.FansubGroupInvitationCreateInput) (*models.FansubGroupInvitationCreateResult, error) {
-	return s.createResp, nil
-}
-
-func (s *invitationRepoStubWithCancelTracking) Cancel(_ context.Context, _ int64, _ int64, _ models.FansubGroupInvitationCancelInput) (*models.FansubGroupInvitation, error) {
-	if s.onCancel != nil {
-		s.onCancel()
-	}
-	return &models.FansubGroupInvitation{}, nil
-}
-
-func (s *invitationRepoStubWithCancelTracking) Accept(_ context.Context, _ models.AcceptFansubInvitationInput) (*models.FansubGroupInvitation, *models.FansubGroupAppMember, error) {
-	return nil, nil, nil
-}
@@ -6,11 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"image"
-	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -445,51 +442,31 @@ func (h *AppAuthHandler) UploadOwnProfileAvatar(c *gin.Context) {
 	sourceFilename := "source_original." + sourceExt
 	relativePath := relativeDir + "/" + filename
 	relativeSourcePath := relativeDir + "/" + sourceFilename
-	absoluteDir := filepath.Join(h.mediaStorageDir, "profile", fmt.Sprintf("%d", profile.MemberID), "avatar", mediaID)
-	absolutePath := filepath.Join(absoluteDir, filename)
-	absoluteSourcePath := filepath.Join(absoluteDir, sourceFilename)
-
-	if err := os.MkdirAll(absoluteDir, 0755); err != nil {
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar-Verzeichnis konnte nicht erstellt werden.")
-		return
-	}
+	ctx := c.Request.Context()
 
+	var sizeBytes int64
 	if shouldCopyAvatarDisplayFile(mimeType) {
-		if err := copyMultipartFileToPath(croppedFile, absolutePath); err != nil {
-			_ = os.RemoveAll(absoluteDir)
+		if sizeBytes, err = h.storeProfileUpload(ctx, relativePath, croppedFile); err != nil {
+			h.removeProfileMedia(ctx, relativePath)
 			writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar konnte nicht gespeichert werden.")
 			return
 		}
 	} else {
 		img, _, err := image.Decode(croppedFile)
 		if err != nil {
-			_ = os.RemoveAll(absoluteDir)
 			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": "bild konnte nicht gelesen werden"}})
 			return
 		}
-		if err := imaging.Save(img, absolutePath); err != nil {
-			_ = os.RemoveAll(absoluteDir)
+		if sizeBytes, err = h.storeProfileImage(ctx, relativePath, img); err != nil {
+			h.removeProfileMedia(ctx, relativePath)
 			writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar konnte nicht gespeichert werden.")
 			return
 		}
 	}
-	if err := copyMultipartFileToPath(sourceFile, absoluteSourcePath); err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar-Original konnte nicht gespeichert werden.")
-		return
-	}
-
-	sizeBytes, err := fileSize(absolutePath)
-	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar-Größe konnte nicht bestimmt werden.")
-		return
-	}
-
-	sourceSizeBytes, err := fileSize(absoluteSourcePath)
+	sourceSizeBytes, err := h.storeProfileUpload(ctx, relativeSourcePath, sourceFile)
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar-Originalgröße konnte nicht bestimmt werden.")
+		h.removeProfileMedia(ctx, relativePath, relativeSourcePath)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar-Original konnte nicht gespeichert werden.")
 		return
 	}
 
@@ -505,12 +482,12 @@ func (h *AppAuthHandler) UploadOwnProfileAvatar(c *gin.Context) {
 		Height:          &height,
 	})
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
+		h.removeProfileMedia(ctx, relativePath, relativeSourcePath)
 		writeInternalErrorResponse(c, "interner serverfehler", err, "Avatar konnte nicht verknüpft werden.")
 		return
 	}
 
-	cleanupPreviousAvatarFiles(h.mediaStorageDir, profile.Avatar)
+	h.cleanupPreviousAvatarFiles(ctx, profile.Avatar)
 
 	h.applyProfileCapabilities(updatedProfile, identity)
 	if h.auditLogRepo != nil {
@@ -608,18 +585,10 @@ func (h *AppAuthHandler) UploadOwnProfileBackground(c *gin.Context) {
 	relativeDir := fmt.Sprintf("/media/profile/%d/background/%s", profile.MemberID, mediaID)
 	relativePath := relativeDir + "/" + filename
 	relativeSourcePath := relativeDir + "/" + sourceFilename
-	absoluteDir := filepath.Join(h.mediaStorageDir, "profile", fmt.Sprintf("%d", profile.MemberID), "background", mediaID)
-	absolutePath := filepath.Join(absoluteDir, filename)
-	absoluteSourcePath := filepath.Join(absoluteDir, sourceFilename)
-
-	if err := os.MkdirAll(absoluteDir, 0755); err != nil {
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild-Verzeichnis konnte nicht erstellt werden.")
-		return
-	}
+	ctx := c.Request.Context()
 
 	img, _, err := image.Decode(uploadFile)
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
 		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": "bild konnte nicht gelesen werden"}})
 		return
 	}
@@ -631,28 +600,16 @@ func (h *AppAuthHandler) UploadOwnProfileBackground(c *gin.Context) {
 		outputWidth = profileBackgroundBannerWidth
 		outputHeight = profileBackgroundBannerHeight
 	}
-	if err := imaging.Save(outputImage, absolutePath); err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild konnte nicht gespeichert werden.")
-		return
-	}
-	if err := copyMultipartFileToPath(sourceFile, absoluteSourcePath); err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild-Original konnte nicht gespeichert werden.")
-		return
-	}
-
-	sizeBytes, err := fileSize(absolutePath)
+	sizeBytes, err := h.storeProfileImage(ctx, relativePath, outputImage)
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild-Größe konnte nicht bestimmt werden.")
+		h.removeProfileMedia(ctx, relativePath)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild konnte nicht gespeichert werden.")
 		return
 	}
-
-	sourceSizeBytes, err := fileSize(absoluteSourcePath)
+	sourceSizeBytes, err := h.storeProfileUpload(ctx, relativeSourcePath, sourceFile)
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild-Originalgröße konnte nicht bestimmt werden.")
+		h.removeProfileMedia(ctx, relativePath, relativeSourcePath)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild-Original konnte nicht gespeichert werden.")
 		return
 	}
 
@@ -672,12 +629,12 @@ func (h *AppAuthHandler) UploadOwnProfileBackground(c *gin.Context) {
 		Height:          &outputHeight,
 	})
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
+		h.removeProfileMedia(ctx, relativePath, relativeSourcePath)
 		writeInternalErrorResponse(c, "interner serverfehler", err, "Hintergrundbild konnte nicht verknüpft werden.")
 		return
 	}
 
-	cleanupPreviousProfileBackgroundFiles(h.mediaStorageDir, profile.BackgroundImage)
+	h.cleanupPreviousProfileBackgroundFiles(ctx, profile.BackgroundImage)
 
 	h.applyProfileCapabilities(updatedProfile, identity)
 	if h.auditLogRepo != nil {
@@ -784,39 +741,18 @@ func readProfileBackgroundUploadFileHeaders(c *gin.Context) (*multipart.FileHead
 	return fileHeader, fileHeader, false, nil
 }
 
-func copyMultipartFileToPath(file multipart.File, targetPath string) error {
-	target, err := os.Create(targetPath)
-	if err != nil {
-		return err
-	}
-	defer target.Close()
-
-	_, err = io.Copy(target, file)
-	return err
-}
-
-func cleanupPreviousAvatarFiles(mediaStorageDir string, avatar *models.MediaAsset) {
+func (h *AppAuthHandler) cleanupPreviousAvatarFiles(ctx context.Context, avatar *models.MediaAsset) {
 	if avatar == nil || strings.TrimSpace(avatar.StoragePath) == "" {
 		return
 	}
-	trimmed := strings.TrimPrefix(strings.TrimSpace(avatar.StoragePath), "/")
-	trimmed = strings.TrimPrefix(trimmed, "media/")
-	targetDir := filepath.Dir(filepath.Join(mediaStorageDir, trimmed))
-	if ok, err := isUploadPathWithinBase(mediaStorageDir, targetDir); err == nil && ok {
-		_ = os.RemoveAll(targetDir)
-	}
+	h.removeProfileMedia(ctx, avatar.StoragePath, profileMediaPathFromURL(avatar.SourceOriginalURL))
 }
 
-func cleanupPreviousProfileBackgroundFiles(mediaStorageDir string, background *models.MemberProfileBgImage) {
+func (h *AppAuthHandler) cleanupPreviousProfileBackgroundFiles(ctx context.Context, background *models.MemberProfileBgImage) {
 	if background == nil || strings.TrimSpace(background.StoragePath) == "" {
 		return
 	}
-	trimmed := strings.TrimPrefix(strings.TrimSpace(background.StoragePath), "/")
-	trimmed = strings.TrimPrefix(trimmed, "media/")
-	targetDir := filepath.Dir(filepath.Join(mediaStorageDir, trimmed))
-	if ok, err := isUploadPathWithinBase(mediaStorageDir, targetDir); err == nil && ok {
-		_ = os.RemoveAll(targetDir)
-	}
+	h.removeProfileMedia(ctx, background.StoragePath, profileMediaPathFromURL(background.SourceOriginalURL))
 }
 
 func (h *AppAuthHandler) auditProfileUpdated(
@@ -948,11 +884,3 @@ func shouldCopyAvatarDisplayFile(mimeType string) bool {
 		return false
 	}
 }
-
-func fileSize(path string) (int64, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		return 0, err
-	}
-	return info.Size(), nil
-}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"team4s.v3/backend/internal/blobstore"
+)
+
+// WithBlobStore setzt die Ablage für Avatare, Hintergrund- und Story-Bilder (Standard: lokales
+// Medienverzeichnis).
+func (h *AppAuthHandler) WithBlobStore(store blobstore.BlobStore) *AppAuthHandler {
+	if store != nil {
+		h.blobs = store
+	}
+	return h
+}
+
+func (h *AppAuthHandler) profileBlobs() blobstore.BlobStore {
+	if h.blobs != nil {
+		return h.blobs
+	}
+	return blobstore.NewLocalStore(h.mediaStorageDir)
+}
+
+// profileMediaKey bildet einen "/media/profile/…"-Pfad auf den Schlüssel im BlobStore ab.
+func (h *AppAuthHandler) profileMediaKey(relativePath string) (string, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(relativePath), "/")
+	key, ok := blobstore.CleanKey(strings.TrimPrefix(trimmed, "media/"))
+	if !ok {
+		return "", fmt.Errorf("media path %q is outside the media storage", relativePath)
+	}
+	return key, nil
+}
+
+// storeProfileMedia legt data unter relativePath im BlobStore ab und liefert die Größe.
+func (h *AppAuthHandler) storeProfileMedia(ctx context.Context, relativePath string, data []byte) (int64, error) {
+	key, err := h.profileMediaKey(relativePath)
+	if err != nil {
+		return 0, err
+	}
+	contentType := mime.TypeByExtension(path.Ext(relativePath))
+	if err := h.profileBlobs().Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+// storeProfileImage kodiert img passend zur Dateiendung von relativePath (ohne EXIF-Metadaten)
+// und legt es ab.
+func (h *AppAuthHandler) storeProfileImage(ctx context.Context, relativePath string, img image.Image) (int64, error) {
+	data, err := encodeImageFile(img, relativePath)
+	if err != nil {
+		return 0, err
+	}
+	return h.storeProfileMedia(ctx, relativePath, data)
+}
+
+// storeProfileUpload legt eine hochgeladene Datei unverändert unter relativePath ab.
+func (h *AppAuthHandler) storeProfileUpload(ctx context.Context, relativePath string, file io.Reader) (int64, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+	return h.storeProfileMedia(ctx, relativePath, data)
+}
+
+// removeProfileMedia löscht die Dateien relativePaths. Bei lokaler Ablage wird wie bisher das
+// Upload-Verzeichnis der Dateien komplett entfernt.
+func (h *AppAuthHandler) removeProfileMedia(ctx context.Context, relativePaths ...string) {
+	store := h.profileBlobs()
+	_, local := blobstore.LocalRoot(store)
+	for _, relativePath := range relativePaths {
+		if strings.TrimSpace(relativePath) == "" {
+			continue
+		}
+		key, err := h.profileMediaKey(relativePath)
+		if err != nil {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("profile media: delete %s failed: %v", key, err)
+		}
+		if !local {
+			continue
+		}
+		targetDir := filepath.Dir(blobstore.PathForKey(h.mediaStorageDir, key))
+		if ok, err := isUploadPathWithinBase(h.mediaStorageDir, targetDir); err == nil && ok {
+			_ = os.RemoveAll(targetDir)
+		}
+	}
+}
+
+// profileMediaPathFromURL liefert den "/media/…"-Pfad einer öffentlichen Medien-URL.
+func profileMediaPathFromURL(publicURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(publicURL))
+	if err != nil {
+		return ""
+	}
+	if index := strings.Index(parsed.Path, "/media/"); index >= 0 {
+		return parsed.Path[index:]
+	}
+	return ""
+}
@@ -7,11 +7,10 @@ import (
 	"fmt"
 	"image"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/middleware"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
@@ -33,7 +32,7 @@ const storyImageMaxSize = 10 * 1024 * 1024 // 10 MB (D-17)
 // UploadOwnProfileStoryImage implementiert POST /api/v1/me/profile/story-images.
 // Pflichtfeld: multipart "image" (jpg/png/webp, max 10 MB).
 // Validierungen: MIME-Allowlist (D-16), Groessenlimit (D-17), Pixel-Bomb-Guard (D-19),
-// EXIF-Strip via imaging.Save (D-19), Resize auf max 1600px.
+// EXIF-Strip per Re-Encode (D-19), Resize auf max 1600px.
 // Pfad: /media/profile/{memberID}/story/{uuid}/original.{ext} (D-08).
 func (h *AppAuthHandler) UploadOwnProfileStoryImage(c *gin.Context) {
 	identity, ok := middleware.CommentAuthIdentityFromContext(c)
@@ -122,24 +121,16 @@ func (h *AppAuthHandler) UploadOwnProfileStoryImage(c *gin.Context) {
 	relativeDir := fmt.Sprintf("/media/profile/%d/story/%s", profile.MemberID, mediaID)
 	filename := "original." + ext
 	relativePath := relativeDir + "/" + filename
-	absoluteDir := filepath.Join(h.mediaStorageDir, "profile", fmt.Sprintf("%d", profile.MemberID), "story", mediaID)
-	absolutePath := filepath.Join(absoluteDir, filename)
-
-	if err := os.MkdirAll(absoluteDir, 0755); err != nil {
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Story-Bild-Verzeichnis konnte nicht erstellt werden.")
-		return
-	}
+	ctx := c.Request.Context()
 
 	// Zurueck zum Anfang fuer vollstaendiges Decode
 	if _, err := file.Seek(0, 0); err != nil {
-		_ = os.RemoveAll(absoluteDir)
 		writeInternalErrorResponse(c, "interner serverfehler", err, "Story-Bild konnte nicht vorbereitet werden.")
 		return
 	}
 
 	img, _, err := image.Decode(file)
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
 		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": "bild konnte nicht dekodiert werden"}})
 		return
 	}
@@ -149,17 +140,11 @@ func (h *AppAuthHandler) UploadOwnProfileStoryImage(c *gin.Context) {
 		img = imaging.Resize(img, 1600, 0, imaging.Lanczos)
 	}
 
-	// EXIF-Strip: imaging.Save re-enkodiert ohne EXIF-Metadaten (D-19)
-	if err := imaging.Save(img, absolutePath); err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Story-Bild konnte nicht gespeichert werden.")
-		return
-	}
-
-	sizeBytes, err := fileSize(absolutePath)
+	// EXIF-Strip: Re-Encode ohne EXIF-Metadaten (D-19)
+	sizeBytes, err := h.storeProfileImage(ctx, relativePath, img)
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
-		writeInternalErrorResponse(c, "interner serverfehler", err, "Story-Bild-Groesse konnte nicht bestimmt werden.")
+		h.removeProfileMedia(ctx, relativePath)
+		writeInternalErrorResponse(c, "interner serverfehler", err, "Story-Bild konnte nicht gespeichert werden.")
 		return
 	}
 
@@ -173,7 +158,7 @@ func (h *AppAuthHandler) UploadOwnProfileStoryImage(c *gin.Context) {
 		OwnerMemberID: profile.MemberID,
 	})
 	if err != nil {
-		_ = os.RemoveAll(absoluteDir)
+		h.removeProfileMedia(ctx, relativePath)
 		writeInternalErrorResponse(c, "interner serverfehler", err, "Story-Bild konnte nicht in der Datenbank gespeichert werden.")
 		return
 	}
@@ -228,19 +213,20 @@ func (h *AppAuthHandler) ResolveStoryImageByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "interner serverfehler"}})
 		return
 	}
-	// file_path (/media/profile/{m}/story/{uuid}/original.ext) -> Disk unter mediaStorageDir.
-	trimmed := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(ref.FilePath), "/"), "media/")
-	diskPath := filepath.Join(h.mediaStorageDir, trimmed)
-	if ok, perr := isUploadPathWithinBase(h.mediaStorageDir, diskPath); perr != nil || !ok {
+	// file_path (/media/profile/{m}/story/{uuid}/original.ext) -> Schluessel im BlobStore.
+	key, err := h.profileMediaKey(ref.FilePath)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "story-bild nicht gefunden"}})
 		return
 	}
-	if _, serr := os.Stat(diskPath); serr != nil {
+	err = serveStoredMedia(c, h.profileBlobs(), key, "", "public, max-age=31536000, immutable")
+	if errors.Is(err, blobstore.ErrNotExist) {
 		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "story-bild nicht gefunden"}})
 		return
 	}
-	c.Header("Cache-Control", "public, max-age=31536000, immutable")
-	c.File(diskPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "interner serverfehler"}})
+	}
 }
 
 // extractStoryImageIDsFromJSON parst ein TipTap-JSON-Dokument und sammelt alle
@@ -288,17 +274,8 @@ func collectStoryImageIDs(node map[string]interface{}, ids *[]int64) {
 
 // cleanupStoryImageAsset loescht Datei und Verzeichnis fuer ein einzelnes Story-Bild-Asset.
 // Wird von UpdateOwnProfile nach dem Referenz-Diff aufgerufen (Cleanup-on-Save, D-22).
-// Path-Traversal-Guard: isUploadPathWithinBase wird vor os.RemoveAll geprueft.
-func cleanupStoryImageAsset(mediaStorageDir string, storagePath string) {
-	if strings.TrimSpace(storagePath) == "" {
-		return
-	}
-	trimmed := strings.TrimPrefix(strings.TrimSpace(storagePath), "/")
-	trimmed = strings.TrimPrefix(trimmed, "media/")
-	targetDir := filepath.Dir(filepath.Join(mediaStorageDir, trimmed))
-	if ok, err := isUploadPathWithinBase(mediaStorageDir, targetDir); err == nil && ok {
-		_ = os.RemoveAll(targetDir)
-	}
+func (h *AppAuthHandler) cleanupStoryImageAsset(ctx context.Context, storagePath string) {
+	h.removeProfileMedia(ctx, storagePath)
 }
 
 // ErrIDORViolation wird zurueckgegeben wenn ein body_json eine media_asset_id
@@ -368,7 +345,7 @@ func (h *AppAuthHandler) applyStoryImageLifecycle(
 			}
 			// Dateisystem-Cleanup
 			if filePath, ok := assetFilePath(assets, oldID); ok {
-				cleanupStoryImageAsset(h.mediaStorageDir, filePath)
+				h.cleanupStoryImageAsset(ctx, filePath)
 			}
 			// DB-Zeile loeschen
 			if delErr := h.profileRepo.DeleteStoryImageAsset(ctx, oldID, profile.MemberID); delErr != nil {
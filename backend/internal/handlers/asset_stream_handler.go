@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -72,12 +73,11 @@ func (h *AssetStreamHandler) StreamAsset(c *gin.Context) {
 		}
 	}
 
-	targetURL, err := buildProviderStreamURL(
-		h.jellyfinBaseURL,
-		h.jellyfinStreamPath,
-		h.jellyfinAPIKey,
-		assetID,
-	)
+	targetURL, err := mediaserver.NewJellyfin(mediaserver.Config{
+		BaseURL:            h.jellyfinBaseURL,
+		APIKey:             h.jellyfinAPIKey,
+		StreamPathTemplate: h.jellyfinStreamPath,
+	}).StreamURL(assetID)
 	if err != nil || strings.TrimSpace(targetURL) == "" {
 		log.Printf("asset stream: unable to build stream url (asset_id=%q, user_id=%d): %v", assetID, identity.UserID, err)
 		c.JSON(http.StatusNotFound, gin.H{
@@ -2,13 +2,18 @@
 
 import (
 	"errors"
+	"log"
 	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"team4s.v3/backend/internal/middleware"
 	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/permissions"
 	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,12 +24,30 @@ const (
 )
 
 type createCommentRequest struct {
+	Content  string `json:"content"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+type updateCommentRequest struct {
 	Content string `json:"content"`
 }
 
 // CommentHandler verwaltet HTTP-Endpunkte für Kommentare zu Anime-Einträgen.
 type CommentHandler struct {
 	repo *repository.CommentRepository
+
+	// Moderation (optional): Rechteprüfung, Audit-Log, Spam-Heuristiken und die Anzahl
+	// offener Meldungen, ab der ein Kommentar zur Prüfung zurückgehalten wird.
+	permissionSvc       *permissions.Service
+	auditLogRepo        auditLogWriter
+	spamHeuristics      *services.CommentSpamHeuristics
+	reportHideThreshold int
+
+	// markdown (optional) rendert content_html; ohne Service bleibt content_html leer.
+	markdown *services.MarkdownService
+
+	// notifications (optional) benachrichtigt per @slug erwähnte Accounts.
+	notifications *services.NotificationService
 }
 
 // NewCommentHandler erstellt einen neuen CommentHandler mit dem angegebenen Repository.
@@ -32,11 +55,93 @@ func NewCommentHandler(repo *repository.CommentRepository) *CommentHandler {
 	return &CommentHandler{repo: repo}
 }
 
-// ListByAnimeID verarbeitet GET /api/v1/anime/:id/comments und gibt eine paginierte Kommentarliste zurück.
+// WithModeration aktiviert Meldungen, Moderations-Queue und Spam-Heuristiken.
+func (h *CommentHandler) WithModeration(
+	permissionSvc *permissions.Service,
+	auditLogRepo *repository.AuditLogRepository,
+	spamHeuristics *services.CommentSpamHeuristics,
+	reportHideThreshold int,
+) *CommentHandler {
+	h.permissionSvc = permissionSvc
+	h.auditLogRepo = auditLogRepo
+	h.spamHeuristics = spamHeuristics
+	h.reportHideThreshold = reportHideThreshold
+	return h
+}
+
+// WithMarkdown aktiviert das Rendern von content_html samt verlinkter @mentions.
+func (h *CommentHandler) WithMarkdown(markdown *services.MarkdownService) *CommentHandler {
+	h.markdown = markdown
+	return h
+}
+
+// WithNotifications aktiviert Benachrichtigungen für neue @mentions.
+func (h *CommentHandler) WithNotifications(notifications *services.NotificationService) *CommentHandler {
+	h.notifications = notifications
+	return h
+}
+
+// commentTargetRoute beschreibt ein Kommentarziel samt Fehlermeldungen für ungültige oder
+// unbekannte IDs im Pfad.
+type commentTargetRoute struct {
+	targetType      string
+	invalidMessage  string
+	notFoundMessage string
+}
+
+var (
+	animeCommentTarget          = commentTargetRoute{models.CommentTargetAnime, "ungültige anime id", "anime nicht gefunden"}
+	episodeCommentTarget        = commentTargetRoute{models.CommentTargetEpisode, "ungültige episode id", "episode nicht gefunden"}
+	releaseVersionCommentTarget = commentTargetRoute{models.CommentTargetReleaseVersion, "ungültige release-version id", "release-version nicht gefunden"}
+	fansubGroupCommentTarget    = commentTargetRoute{models.CommentTargetFansubGroup, "ungültige fansub id", "fansubgruppe nicht gefunden"}
+)
+
+// ListByAnimeID verarbeitet GET /api/v1/anime/:id/comments.
 func (h *CommentHandler) ListByAnimeID(c *gin.Context) {
-	animeID, err := parseAnimeID(c.Param("id"))
+	h.listByTarget(c, animeCommentTarget)
+}
+
+// CreateByAnimeID verarbeitet POST /api/v1/anime/:id/comments.
+func (h *CommentHandler) CreateByAnimeID(c *gin.Context) {
+	h.createForTarget(c, animeCommentTarget)
+}
+
+// ListByEpisodeID verarbeitet GET /api/v1/episodes/:id/comments.
+func (h *CommentHandler) ListByEpisodeID(c *gin.Context) {
+	h.listByTarget(c, episodeCommentTarget)
+}
+
+// CreateByEpisodeID verarbeitet POST /api/v1/episodes/:id/comments.
+func (h *CommentHandler) CreateByEpisodeID(c *gin.Context) {
+	h.createForTarget(c, episodeCommentTarget)
+}
+
+// ListByReleaseVersionID verarbeitet GET /api/v1/release-versions/:id/comments.
+func (h *CommentHandler) ListByReleaseVersionID(c *gin.Context) {
+	h.listByTarget(c, releaseVersionCommentTarget)
+}
+
+// CreateByReleaseVersionID verarbeitet POST /api/v1/release-versions/:id/comments.
+func (h *CommentHandler) CreateByReleaseVersionID(c *gin.Context) {
+	h.createForTarget(c, releaseVersionCommentTarget)
+}
+
+// ListByFansubGroupID verarbeitet GET /api/v1/fansubs/:id/comments.
+func (h *CommentHandler) ListByFansubGroupID(c *gin.Context) {
+	h.listByTarget(c, fansubGroupCommentTarget)
+}
+
+// CreateByFansubGroupID verarbeitet POST /api/v1/fansubs/:id/comments.
+func (h *CommentHandler) CreateByFansubGroupID(c *gin.Context) {
+	h.createForTarget(c, fansubGroupCommentTarget)
+}
+
+// listByTarget gibt eine paginierte Liste von Threads eines Ziels zurück; per_page und meta
+// beziehen sich auf Top-Level-Kommentare.
+func (h *CommentHandler) listByTarget(c *gin.Context, route commentTargetRoute) {
+	targetID, err := parseCommentID(c.Param("id"))
 	if err != nil {
-		badRequest(c, "ungültige anime id")
+		badRequest(c, route.invalidMessage)
 		return
 	}
 
@@ -55,19 +160,21 @@ func (h *CommentHandler) ListByAnimeID(c *gin.Context) {
 		perPage = 100
 	}
 
-	items, total, err := h.repo.ListByAnimeID(c.Request.Context(), animeID, models.CommentFilter{
+	target := models.CommentTarget{Type: route.targetType, ID: targetID}
+	items, total, err := h.repo.ListByTarget(c.Request.Context(), target, models.CommentFilter{
 		Page:    page,
 		PerPage: perPage,
 	})
 	if errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": gin.H{
-				"message": "anime nicht gefunden",
+				"message": route.notFoundMessage,
 			},
 		})
 		return
 	}
 	if err != nil {
+		log.Printf("comment: list failed (target=%s, target_id=%d): %v", target.Type, target.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
 				"message": "interner serverfehler",
@@ -76,6 +183,8 @@ func (h *CommentHandler) ListByAnimeID(c *gin.Context) {
 		return
 	}
 
+	h.renderComments(items)
+
 	totalPages := 0
 	if total > 0 {
 		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
@@ -92,11 +201,11 @@ func (h *CommentHandler) ListByAnimeID(c *gin.Context) {
 	})
 }
 
-// CreateByAnimeID verarbeitet POST /api/v1/anime/:id/comments und legt einen neuen Kommentar an.
-func (h *CommentHandler) CreateByAnimeID(c *gin.Context) {
-	animeID, err := parseAnimeID(c.Param("id"))
+// createForTarget legt einen neuen Kommentar oder eine Antwort an einem Ziel an.
+func (h *CommentHandler) createForTarget(c *gin.Context, route commentTargetRoute) {
+	targetID, err := parseCommentID(c.Param("id"))
 	if err != nil {
-		badRequest(c, "ungültige anime id")
+		badRequest(c, route.invalidMessage)
 		return
 	}
 
@@ -121,12 +230,38 @@ func (h *CommentHandler) CreateByAnimeID(c *gin.Context) {
 		badRequest(c, validationMessage)
 		return
 	}
+	input.AuthorUserID = identity.UserID
 
-	item, err := h.repo.CreateByAnimeID(c.Request.Context(), animeID, input)
+	if h.writeCommentBanned(c, identity.UserID) {
+		return
+	}
+	holdFlags, err := h.evaluateNewComment(c, identity.UserID, input.Content)
+	if err != nil {
+		log.Printf("comment: spam signals failed (user_id=%d): %v", identity.UserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	input.HoldFlags = holdFlags
+
+	mentions, err := h.resolveCommentMentions(c, input.Content)
+	if err != nil {
+		log.Printf("comment: resolve mentions failed (user_id=%d): %v", identity.UserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	input.Mentions = mentions
+
+	target := models.CommentTarget{Type: route.targetType, ID: targetID}
+	item, err := h.repo.CreateForTarget(c.Request.Context(), target, input)
+	var threadErr *repository.CommentThreadError
+	if errors.As(err, &threadErr) {
+		badRequest(c, threadErr.Message)
+		return
+	}
 	if errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": gin.H{
-				"message": "anime nicht gefunden",
+				"message": route.notFoundMessage,
 			},
 		})
 		return
@@ -140,14 +275,136 @@ func (h *CommentHandler) CreateByAnimeID(c *gin.Context) {
 		return
 	}
 
+	h.notifyCommentMentions(c, item, identity.AppUserID)
+	h.renderComment(item)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"data": item,
 	})
 }
 
+// UpdateByID verarbeitet PATCH /api/v1/comments/:id. Nur der Autor darf den Inhalt ändern;
+// der vorherige Stand wird als Revision gespeichert.
+func (h *CommentHandler) UpdateByID(c *gin.Context) {
+	commentID, err := parseCommentID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige kommentar id")
+		return
+	}
+
+	identity, ok := middleware.CommentAuthIdentityFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "anmeldung erforderlich"}})
+		return
+	}
+
+	var req updateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	content, validationMessage := validateCommentContent(req.Content)
+	if validationMessage != "" {
+		badRequest(c, validationMessage)
+		return
+	}
+	if h.writeCommentBanned(c, identity.UserID) {
+		return
+	}
+
+	mentions, err := h.resolveCommentMentions(c, content)
+	if err != nil {
+		log.Printf("comment: resolve mentions failed (comment_id=%d): %v", commentID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	// Bearbeitungen werden nur auf inhaltliche Merkmale geprüft (Links, gesperrte Wörter); ein
+	// Treffer hält den Kommentar mit derselben Änderung zur Prüfung zurück.
+	holdFlags := h.spamHeuristics.Evaluate(content, nil, time.Now())
+	item, err := h.repo.UpdateContent(c.Request.Context(), commentID, identity.UserID, content, mentions, holdFlags)
+	if h.writeCommentMutationError(c, err, commentID) {
+		return
+	}
+	h.notifyCommentMentions(c, item, identity.AppUserID)
+	h.renderComment(item)
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// DeleteByID verarbeitet DELETE /api/v1/comments/:id (Soft-Delete). Erlaubt für den Autor
+// und Kommentar-Moderatoren; Antworten bleiben unter einem Platzhalter sichtbar.
+func (h *CommentHandler) DeleteByID(c *gin.Context) {
+	commentID, err := parseCommentID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige kommentar id")
+		return
+	}
+
+	identity, actor, ok := permissionActorFromContext(c)
+	if !ok {
+		return
+	}
+
+	err = h.repo.SoftDelete(c.Request.Context(), commentID, identity.UserID, h.canModerate(actor))
+	if h.writeCommentMutationError(c, err, commentID) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListRevisions verarbeitet GET /api/v1/comments/:id/revisions und liefert den Bearbeitungsverlauf.
+func (h *CommentHandler) ListRevisions(c *gin.Context) {
+	commentID, err := parseCommentID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige kommentar id")
+		return
+	}
+
+	revisions, err := h.repo.ListRevisions(c.Request.Context(), commentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "kommentar nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("comment: list revisions failed (comment_id=%d): %v", commentID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": revisions})
+}
+
+// writeCommentMutationError übersetzt Repository-Fehler beim Bearbeiten/Löschen in
+// API-Antworten. Gibt true zurück, wenn eine Fehlerantwort geschrieben wurde.
+func (h *CommentHandler) writeCommentMutationError(c *gin.Context, err error, commentID int64) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "kommentar nicht gefunden")
+	case errors.Is(err, repository.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": gin.H{"message": "keine berechtigung"}})
+	case errors.Is(err, repository.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "gelöschte kommentare können nicht bearbeitet werden"}})
+	default:
+		log.Printf("comment: mutation failed (comment_id=%d): %v", commentID, err)
+		internalError(c, "interner serverfehler")
+	}
+	return true
+}
+
+func parseCommentID(raw string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return id, nil
+}
+
 func validateCreateCommentRequest(req createCommentRequest, authorNameRaw string) (models.CommentCreateInput, string) {
 	authorName := strings.TrimSpace(authorNameRaw)
-	content := strings.TrimSpace(req.Content)
 
 	if authorName == "" {
 		return models.CommentCreateInput{}, "author_name ist erforderlich"
@@ -156,15 +413,29 @@ func validateCreateCommentRequest(req createCommentRequest, authorNameRaw string
 		return models.CommentCreateInput{}, "author_name ist zu lang (max 80 zeichen)"
 	}
 
-	if content == "" {
-		return models.CommentCreateInput{}, "content ist erforderlich"
+	content, message := validateCommentContent(req.Content)
+	if message != "" {
+		return models.CommentCreateInput{}, message
 	}
-	if len([]rune(content)) > maxCommentContentLength {
-		return models.CommentCreateInput{}, "content ist zu lang (max 4000 zeichen)"
+	if req.ParentID != nil && *req.ParentID <= 0 {
+		return models.CommentCreateInput{}, "ungültige parent_id"
 	}
 
 	return models.CommentCreateInput{
 		AuthorName: authorName,
 		Content:    content,
+		ParentID:   req.ParentID,
 	}, ""
 }
+
+func validateCommentContent(raw string) (string, string) {
+	content := strings.TrimSpace(raw)
+	if content == "" {
+		return "", "content ist erforderlich"
+	}
+	if len([]rune(content)) > maxCommentContentLength {
+		return "", "content ist zu lang (max 4000 zeichen)"
+	}
+
+	return content, ""
+}
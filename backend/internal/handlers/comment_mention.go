@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListMyMentions verarbeitet GET /api/v1/me/mentions und listet sichtbare Kommentare, in
+// denen das eigene Member-Profil per @slug erwähnt wurde (neueste zuerst).
+func (h *CommentHandler) ListMyMentions(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "20"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	items, total, err := h.repo.ListMentionsForUser(c.Request.Context(), identity.AppUserID, identity.UserID, models.CommentFilter{
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		log.Printf("comment: list mentions failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	for i := range items {
+		items[i].ContentHTML = h.renderCommentHTML(items[i].Content, items[i].MentionSlugs)
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// resolveCommentMentions sucht @slugs im Inhalt und löst sie gegen die Member-Profile auf.
+func (h *CommentHandler) resolveCommentMentions(c *gin.Context, content string) ([]models.CommentMentionTarget, error) {
+	slugs := services.ExtractCommentMentions(content)
+	if len(slugs) == 0 {
+		return nil, nil
+	}
+	return h.repo.ResolveMentions(c.Request.Context(), slugs)
+}
+
+// notifyCommentMentions benachrichtigt neu erwähnte Accounts. Zurückgehaltene Kommentare
+// lösen keine Benachrichtigung aus.
+func (h *CommentHandler) notifyCommentMentions(c *gin.Context, item *models.CommentListItem, actorAppUserID int64) {
+	if item == nil || len(item.AddedMentions) == 0 || item.ModerationStatus != models.CommentModerationVisible {
+		return
+	}
+	if err := h.notifications.NotifyCommentMentions(c.Request.Context(), item, item.AddedMentions, actorAppUserID); err != nil {
+		log.Printf("comment: notify mentions failed (comment_id=%d): %v", item.ID, err)
+	}
+}
+
+// renderComments füllt content_html für einen Thread-Baum; Platzhalter bleiben leer.
+func (h *CommentHandler) renderComments(items []models.CommentListItem) {
+	for i := range items {
+		h.renderComment(&items[i])
+		h.renderComments(items[i].Replies)
+	}
+}
+
+func (h *CommentHandler) renderComment(item *models.CommentListItem) {
+	if item == nil {
+		return
+	}
+	item.ContentHTML = h.renderCommentHTML(item.Content, item.MentionSlugs)
+}
+
+// renderCommentHTML verlinkt aufgelöste @mentions und rendert die Kommentar-Teilmenge von
+// Markdown. Renderfehler werden geloggt und liefern einen leeren String.
+func (h *CommentHandler) renderCommentHTML(content string, mentionSlugs []string) string {
+	if h.markdown == nil || content == "" {
+		return ""
+	}
+
+	rendered, err := h.markdown.RenderCommentMarkdown(services.LinkCommentMentions(content, mentionSlugs))
+	if err != nil {
+		log.Printf("comment: render markdown failed: %v", err)
+		return ""
+	}
+	return rendered
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/services"
+)
+
+func TestRenderCommentsLinksMentionsAndSkipsPlaceholders(t *testing.T) {
+	handler := NewCommentHandler(nil).WithMarkdown(services.NewMarkdownService())
+
+	items := []models.CommentListItem{
+		{
+			ID:           1,
+			Content:      "**Danke** @Nico und @fremd",
+			MentionSlugs: []string{"nico"},
+			Replies: []models.CommentListItem{
+				{ID: 2, IsDeleted: true},
+				{ID: 3, Content: "*ja*"},
+			},
+		},
+	}
+
+	handler.renderComments(items)
+
+	root := items[0].ContentHTML
+	if !strings.Contains(root, "<strong>Danke</strong>") || !strings.Contains(root, `<a href="/members/nico" rel="nofollow">@Nico</a>`) {
+		t.Fatalf("expected markdown and mention link, got %q", root)
+	}
+	if strings.Contains(root, "/members/fremd") {
+		t.Fatalf("expected unresolved mention to stay plain text, got %q", root)
+	}
+	if items[0].Replies[0].ContentHTML != "" {
+		t.Fatalf("expected empty html for placeholder, got %q", items[0].Replies[0].ContentHTML)
+	}
+	if !strings.Contains(items[0].Replies[1].ContentHTML, "<em>ja</em>") {
+		t.Fatalf("expected nested reply to be rendered, got %q", items[0].Replies[1].ContentHTML)
+	}
+}
+
+func TestRenderCommentWithoutMarkdownServiceLeavesHTMLEmpty(t *testing.T) {
+	handler := NewCommentHandler(nil)
+	item := &models.CommentListItem{Content: "**fett**"}
+
+	handler.renderComment(item)
+
+	if item.ContentHTML != "" {
+		t.Fatalf("expected empty html without markdown service, got %q", item.ContentHTML)
+	}
+}
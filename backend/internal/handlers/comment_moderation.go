@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/permissions"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxCommentReportNoteLength       = 500
+	maxCommentModerationReasonLength = 500
+	maxCommentBanDays                = 3650
+)
+
+var commentReportReasons = map[string]struct{}{
+	"spam":      {},
+	"abuse":     {},
+	"spoiler":   {},
+	"off_topic": {},
+	"other":     {},
+}
+
+type reportCommentRequest struct {
+	Reason string  `json:"reason"`
+	Note   *string `json:"note"`
+}
+
+type moderateCommentRequest struct {
+	Action  string  `json:"action"`
+	Reason  *string `json:"reason"`
+	BanDays int     `json:"ban_days"`
+}
+
+// ReportByID verarbeitet POST /api/v1/comments/:id/reports. Jeder angemeldete Nutzer kann
+// einen fremden Kommentar einmal melden.
+func (h *CommentHandler) ReportByID(c *gin.Context) {
+	commentID, err := parseCommentID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige kommentar id")
+		return
+	}
+
+	identity, ok := middleware.CommentAuthIdentityFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "anmeldung erforderlich"}})
+		return
+	}
+
+	var req reportCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	input, validationMessage := validateReportCommentRequest(req)
+	if validationMessage != "" {
+		badRequest(c, validationMessage)
+		return
+	}
+	input.ReporterUserID = identity.UserID
+
+	result, err := h.repo.CreateReport(c.Request.Context(), commentID, input, h.reportHideThreshold)
+	var moderationErr *repository.CommentModerationError
+	switch {
+	case errors.As(err, &moderationErr):
+		badRequest(c, moderationErr.Message)
+		return
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "kommentar nicht gefunden")
+		return
+	case errors.Is(err, repository.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "kommentar wurde bereits gemeldet"}})
+		return
+	case err != nil:
+		log.Printf("comment: report failed (comment_id=%d): %v", commentID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": result})
+}
+
+// ListModerationQueue verarbeitet GET /api/v1/admin/comments/moderation. Optionaler Filter
+// status=pending|hidden|reported; ohne Filter alle zurückgehaltenen und gemeldeten Kommentare.
+func (h *CommentHandler) ListModerationQueue(c *gin.Context) {
+	if _, _, ok := h.requireCommentModerator(c, nil, "list"); !ok {
+		return
+	}
+
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "20"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	items, total, err := h.repo.ListModerationQueue(c.Request.Context(), models.CommentModerationFilter{
+		Status:  strings.TrimSpace(c.Query("status")),
+		Page:    page,
+		PerPage: perPage,
+	})
+	var moderationErr *repository.CommentModerationError
+	if errors.As(err, &moderationErr) {
+		badRequest(c, moderationErr.Message)
+		return
+	}
+	if err != nil {
+		log.Printf("comment: list moderation queue failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// ModerateByID verarbeitet POST /api/v1/admin/comments/:id/moderation mit der Aktion
+// approve, hide oder ban. Jede Entscheidung wird im Audit-Log festgehalten.
+func (h *CommentHandler) ModerateByID(c *gin.Context) {
+	commentID, err := parseCommentID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige kommentar id")
+		return
+	}
+
+	var req moderateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	input, validationMessage := validateModerateCommentRequest(req)
+	if validationMessage != "" {
+		badRequest(c, validationMessage)
+		return
+	}
+
+	identity, _, ok := h.requireCommentModerator(c, &commentID, input.Action)
+	if !ok {
+		return
+	}
+	input.ModeratorAppUserID = identity.AppUserID
+
+	result, err := h.repo.Moderate(c.Request.Context(), commentID, input)
+	var moderationErr *repository.CommentModerationError
+	switch {
+	case errors.As(err, &moderationErr):
+		badRequest(c, moderationErr.Message)
+		return
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "kommentar nicht gefunden")
+		return
+	case err != nil:
+		log.Printf("comment: moderation failed (comment_id=%d, action=%s): %v", commentID, input.Action, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	payload := map[string]any{
+		"moderation_status": result.ModerationStatus,
+		"resolved_reports":  result.ResolvedReports,
+	}
+	if input.Reason != nil {
+		payload["reason"] = *input.Reason
+	}
+	if result.BannedUserID != nil {
+		payload["banned_user_id"] = *result.BannedUserID
+		payload["ban_expires_at"] = result.BanExpiresAt
+	}
+	h.auditCommentModeration(c, identity, &commentID, input.Action, "allowed", nil, payload)
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// requireCommentModerator prüft comments.moderate über den permissions-Service und schreibt
+// abgelehnte Zugriffe ins Audit-Log.
+func (h *CommentHandler) requireCommentModerator(
+	c *gin.Context,
+	commentID *int64,
+	action string,
+) (middleware.AuthIdentity, permissions.Actor, bool) {
+	identity, actor, ok := permissionActorFromContext(c)
+	if !ok {
+		return identity, actor, false
+	}
+	if h.permissionSvc == nil {
+		writePermissionInternalError(c, errors.New("permission service fehlt"), "comment moderation")
+		return identity, actor, false
+	}
+
+	result := h.permissionSvc.CanModerateComments(actor)
+	if !result.Allowed {
+		reasonCode := result.ReasonCode
+		h.auditCommentModeration(c, identity, commentID, action, "denied", &reasonCode, nil)
+		writePermissionDenied(c, result)
+		return identity, actor, false
+	}
+
+	return identity, actor, true
+}
+
+// canModerate meldet, ob der Akteur fremde Kommentare löschen darf. Ohne permissions-Service
+// gilt der platform_admin-Status aus der Identität.
+func (h *CommentHandler) canModerate(actor permissions.Actor) bool {
+	if h.permissionSvc == nil {
+		return actor.IsPlatformAdmin
+	}
+	return h.permissionSvc.CanModerateComments(actor).Allowed
+}
+
+// writeCommentBanned antwortet mit 403, wenn der Nutzer für Kommentare gesperrt ist.
+// Gibt true zurück, wenn eine Antwort geschrieben wurde.
+func (h *CommentHandler) writeCommentBanned(c *gin.Context, userID int64) bool {
+	if userID <= 0 {
+		return false
+	}
+
+	banned, err := h.repo.IsUserBanned(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("comment: ban check failed (user_id=%d): %v", userID, err)
+		internalError(c, "interner serverfehler")
+		return true
+	}
+	if banned {
+		c.JSON(http.StatusForbidden, gin.H{"error": gin.H{"message": "du bist für kommentare gesperrt"}})
+		return true
+	}
+
+	return false
+}
+
+// evaluateNewComment wendet die Spam-Heuristiken auf einen neuen Kommentar an und liefert
+// die ausgelösten Flags (leer = sofort sichtbar).
+func (h *CommentHandler) evaluateNewComment(c *gin.Context, userID int64, content string) ([]string, error) {
+	if h.spamHeuristics == nil {
+		return nil, nil
+	}
+
+	signals, err := h.repo.LoadAuthorSignals(c.Request.Context(), userID, content, h.spamHeuristics.RepeatWindow())
+	if err != nil {
+		return nil, err
+	}
+
+	return h.spamHeuristics.Evaluate(content, &signals, time.Now()), nil
+}
+
+func (h *CommentHandler) auditCommentModeration(
+	c *gin.Context,
+	identity middleware.AuthIdentity,
+	commentID *int64,
+	action string,
+	outcome string,
+	reasonCode *string,
+	payload map[string]any,
+) {
+	if h.auditLogRepo == nil {
+		return
+	}
+
+	var actorAppUserID *int64
+	if identity.AppUserID > 0 {
+		actorAppUserID = &identity.AppUserID
+	}
+	var actorLegacyUserID *int64
+	if identity.UserID > 0 {
+		actorLegacyUserID = &identity.UserID
+	}
+	_ = h.auditLogRepo.Write(c.Request.Context(), repository.AuditLogEntry{
+		ActorAppUserID:    actorAppUserID,
+		ActorLegacyUserID: actorLegacyUserID,
+		EventType:         "comment.moderation",
+		ScopeType:         permissions.ScopeTypePlatform,
+		TargetType:        "comment",
+		TargetID:          commentID,
+		Action:            string(permissions.ActionCommentsModerate) + "." + action,
+		Outcome:           outcome,
+		ReasonCode:        reasonCode,
+		Payload:           payload,
+	})
+}
+
+func validateReportCommentRequest(req reportCommentRequest) (models.CommentReportInput, string) {
+	reason := strings.ToLower(strings.TrimSpace(req.Reason))
+	if _, ok := commentReportReasons[reason]; !ok {
+		return models.CommentReportInput{}, "ungültiger meldegrund"
+	}
+
+	var note *string
+	if req.Note != nil {
+		trimmed := strings.TrimSpace(*req.Note)
+		if len([]rune(trimmed)) > maxCommentReportNoteLength {
+			return models.CommentReportInput{}, "note ist zu lang (max 500 zeichen)"
+		}
+		if trimmed != "" {
+			note = &trimmed
+		}
+	}
+
+	return models.CommentReportInput{Reason: reason, Note: note}, ""
+}
+
+func validateModerateCommentRequest(req moderateCommentRequest) (models.CommentModerationInput, string) {
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	switch action {
+	case models.CommentModerationActionApprove, models.CommentModerationActionHide, models.CommentModerationActionBan:
+	default:
+		return models.CommentModerationInput{}, "ungültige moderationsaktion"
+	}
+
+	if req.BanDays < 0 || req.BanDays > maxCommentBanDays {
+		return models.CommentModerationInput{}, "ungültige ban_days (0-3650)"
+	}
+	if req.BanDays > 0 && action != models.CommentModerationActionBan {
+		return models.CommentModerationInput{}, "ban_days ist nur für ban erlaubt"
+	}
+
+	var reason *string
+	if req.Reason != nil {
+		trimmed := strings.TrimSpace(*req.Reason)
+		if len([]rune(trimmed)) > maxCommentModerationReasonLength {
+			return models.CommentModerationInput{}, "reason ist zu lang (max 500 zeichen)"
+		}
+		if trimmed != "" {
+			reason = &trimmed
+		}
+	}
+
+	return models.CommentModerationInput{Action: action, Reason: reason, BanDays: req.BanDays}, ""
+}
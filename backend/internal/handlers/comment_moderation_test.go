@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReportCommentRequest(t *testing.T) {
+	longNote := strings.Repeat("x", maxCommentReportNoteLength+1)
+	blank := "   "
+
+	tests := []struct {
+		name    string
+		req     reportCommentRequest
+		wantMsg string
+	}{
+		{name: "valid", req: reportCommentRequest{Reason: " Spam "}},
+		{name: "blank note dropped", req: reportCommentRequest{Reason: "other", Note: &blank}},
+		{name: "unknown reason", req: reportCommentRequest{Reason: "boring"}, wantMsg: "ungültiger meldegrund"},
+		{name: "note too long", req: reportCommentRequest{Reason: "abuse", Note: &longNote}, wantMsg: "note ist zu lang (max 500 zeichen)"},
+	}
+
+	for _, tc := range tests {
+		input, msg := validateReportCommentRequest(tc.req)
+		if msg != tc.wantMsg {
+			t.Fatalf("%s: expected message %q, got %q", tc.name, tc.wantMsg, msg)
+		}
+		if msg == "" && input.Note != nil && strings.TrimSpace(*input.Note) == "" {
+			t.Fatalf("%s: expected blank note to be dropped", tc.name)
+		}
+	}
+
+	input, _ := validateReportCommentRequest(reportCommentRequest{Reason: " Spam "})
+	if input.Reason != "spam" {
+		t.Fatalf("expected normalized reason spam, got %q", input.Reason)
+	}
+}
+
+func TestValidateModerateCommentRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     moderateCommentRequest
+		wantMsg string
+	}{
+		{name: "approve", req: moderateCommentRequest{Action: "approve"}},
+		{name: "ban with days", req: moderateCommentRequest{Action: "BAN", BanDays: 7}},
+		{name: "unknown action", req: moderateCommentRequest{Action: "delete"}, wantMsg: "ungültige moderationsaktion"},
+		{name: "negative days", req: moderateCommentRequest{Action: "ban", BanDays: -1}, wantMsg: "ungültige ban_days (0-3650)"},
+		{name: "days without ban", req: moderateCommentRequest{Action: "hide", BanDays: 3}, wantMsg: "ban_days ist nur für ban erlaubt"},
+	}
+
+	for _, tc := range tests {
+		if _, msg := validateModerateCommentRequest(tc.req); msg != tc.wantMsg {
+			t.Fatalf("%s: expected message %q, got %q", tc.name, tc.wantMsg, msg)
+		}
+	}
+}
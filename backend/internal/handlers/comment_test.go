@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/gin-gonic/gin"
 )
 
 func TestValidateCreateCommentRequest(t *testing.T) {
@@ -55,6 +60,15 @@ func TestValidateCreateCommentRequest(t *testing.T) {
 			authorName:  "Nico",
 			wantMessage: "content ist zu lang (max 4000 zeichen)",
 		},
+		{
+			name: "invalid parent id",
+			req: createCommentRequest{
+				Content:  "Antwort",
+				ParentID: ptrInt64(0),
+			},
+			authorName:  "Nico",
+			wantMessage: "ungültige parent_id",
+		},
 	}
 
 	for _, tc := range tests {
@@ -78,3 +92,43 @@ func TestValidateCreateCommentRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestCommentTargetRoutesRejectInvalidIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewCommentHandler(nil)
+
+	tests := []struct {
+		name        string
+		list        gin.HandlerFunc
+		wantMessage string
+	}{
+		{name: "anime", list: handler.ListByAnimeID, wantMessage: "ungültige anime id"},
+		{name: "episode", list: handler.ListByEpisodeID, wantMessage: "ungültige episode id"},
+		{name: "release version", list: handler.ListByReleaseVersionID, wantMessage: "ungültige release-version id"},
+		{name: "fansub group", list: handler.ListByFansubGroupID, wantMessage: "ungültige fansub id"},
+	}
+
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/comments", nil)
+		c.Params = gin.Params{{Key: "id", Value: "0"}}
+
+		tc.list(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", tc.name, rec.Code)
+		}
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: decode body: %v", tc.name, err)
+		}
+		if body.Error.Message != tc.wantMessage {
+			t.Fatalf("%s: expected message %q, got %q", tc.name, tc.wantMessage, body.Error.Message)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"team4s.v3/backend/internal/permissions"
 	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,6 +34,7 @@ type ContributionReviewHandler struct {
 	reviewRepo    ReviewRepository
 	permissionSvc reviewPermissionChecker
 	auditLogRepo  auditLogWriter
+	notifications *services.NotificationService
 }
 
 // NewContributionReviewHandler erstellt einen neuen ContributionReviewHandler.
@@ -48,6 +50,12 @@ func NewContributionReviewHandler(
 	}
 }
 
+// WithNotifications benachrichtigt den Ersteller eines Vorschlags über die Review-Entscheidung.
+func (h *ContributionReviewHandler) WithNotifications(notifications *services.NotificationService) *ContributionReviewHandler {
+	h.notifications = notifications
+	return h
+}
+
 // rejectRequest enthält den optionalen Ablehnungsgrund.
 type rejectRequest struct {
 	ReviewNote *string `json:"review_note"`
@@ -149,6 +157,9 @@ func (h *ContributionReviewHandler) ConfirmProposal(c *gin.Context) {
 		Action:         string(permissions.ActionFansubGroupMembersManage),
 		Outcome:        "allowed",
 	})
+	if err := h.notifications.NotifyContributionReviewed(c.Request.Context(), contributionID, identity.AppUserID, true, nil); err != nil {
+		log.Printf("contribution review: notify confirmed failed (contribution_id=%d): %v", contributionID, err)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Vorschlag wurde bestätigt."})
 }
@@ -221,6 +232,9 @@ func (h *ContributionReviewHandler) RejectProposal(c *gin.Context) {
 		Outcome:        "allowed",
 		Payload:        map[string]any{"has_note": req.ReviewNote != nil},
 	})
+	if err := h.notifications.NotifyContributionReviewed(c.Request.Context(), contributionID, identity.AppUserID, false, req.ReviewNote); err != nil {
+		log.Printf("contribution review: notify rejected failed (contribution_id=%d): %v", contributionID, err)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Vorschlag wurde abgelehnt."})
 }
@@ -82,6 +82,20 @@ func requireMeIdentity(c *gin.Context) (middleware.AuthIdentity, bool) {
 	return identity, true
 }
 
+// requireAppUserIdentity ist requireMeIdentity für Endpunkte, deren Daten an app_users.id hängen
+// (Legacy-Token und lokaler Auth-Bypass tragen keine AppUserID).
+func requireAppUserIdentity(c *gin.Context) (middleware.AuthIdentity, bool) {
+	identity, ok := requireMeIdentity(c)
+	if !ok {
+		return middleware.AuthIdentity{}, false
+	}
+	if identity.AppUserID <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "anmeldung erforderlich"}})
+		return middleware.AuthIdentity{}, false
+	}
+	return identity, true
+}
+
 // ListMyAnimeContributions handles GET /api/v1/me/anime-contributions
 func (h *ContributionsMeHandler) ListMyAnimeContributions(c *gin.Context) {
 	identity, ok := requireMeIdentity(c)
@@ -15,9 +15,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func (h *EpisodePlaybackHandler) authorizePlayback(c *gin.Context, episodeID int64) (string, bool) {
+// authorizePlayback prüft angemeldete Nutzer bzw. den grant-Parameter. Die Session stammt aus
+// dem Grant oder, bei angemeldeten Nutzern, aus dem optionalen session-Parameter.
+func (h *EpisodePlaybackHandler) authorizePlayback(c *gin.Context, episodeID int64) (playbackAccess, bool) {
 	if identity, ok := middleware.CommentAuthIdentityFromContext(c); ok && identity.UserID > 0 {
-		return playbackPrincipalForUserID(identity.UserID), true
+		return playbackAccess{
+			principal: playbackPrincipalForUserID(identity.UserID),
+			userID:    identity.UserID,
+			sessionID: strings.TrimSpace(c.Query(playbackSessionQueryParameter)),
+		}, true
 	}
 
 	grantToken := strings.TrimSpace(c.Query("grant"))
@@ -27,7 +33,7 @@ func (h *EpisodePlaybackHandler) authorizePlayback(c *gin.Context, episodeID int
 				"message": "anmeldung erforderlich",
 			},
 		})
-		return "", false
+		return playbackAccess{}, false
 	}
 
 	if strings.TrimSpace(h.releaseGrantSecret) == "" {
@@ -36,10 +42,20 @@ func (h *EpisodePlaybackHandler) authorizePlayback(c *gin.Context, episodeID int
 				"message": "stream grant vorübergehend nicht verfügbar",
 			},
 		})
-		return "", false
+		return playbackAccess{}, false
 	}
 
-	// Check for grant replay
+	claims, err := auth.ParseAndVerifyReleaseStreamGrant(grantToken, h.releaseGrantSecret, time.Now())
+	if err != nil || claims.ReleaseID != episodeID {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"message": "ungültiger stream grant",
+			},
+		})
+		return playbackAccess{}, false
+	}
+
+	// Check for grant replay; the grant is verified first so the attempt is attributed to its user.
 	if h.grantStore != nil {
 		used, err := h.grantStore.IsUsed(c.Request.Context(), grantToken)
 		if err != nil {
@@ -47,27 +63,17 @@ func (h *EpisodePlaybackHandler) authorizePlayback(c *gin.Context, episodeID int
 		} else if used {
 			clientIP := extractClientIP(c)
 			if h.auditLogger != nil {
-				h.auditLogger.logGrantReplayAttempt(c.Request.Context(), 0, episodeID, clientIP)
+				h.auditLogger.logGrantReplayAttempt(c.Request.Context(), claims.UserID, episodeID, clientIP)
 			}
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": gin.H{
 					"message": "ungültiger stream grant",
 				},
 			})
-			return "", false
+			return playbackAccess{}, false
 		}
 	}
 
-	claims, err := auth.ParseAndVerifyReleaseStreamGrant(grantToken, h.releaseGrantSecret, time.Now())
-	if err != nil || claims.ReleaseID != episodeID {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": gin.H{
-				"message": "ungültiger stream grant",
-			},
-		})
-		return "", false
-	}
-
 	// Mark grant as used
 	if h.grantStore != nil {
 		if err := h.grantStore.MarkUsed(c.Request.Context(), grantToken, h.releaseGrantTTL*2); err != nil {
@@ -75,7 +81,11 @@ func (h *EpisodePlaybackHandler) authorizePlayback(c *gin.Context, episodeID int
 		}
 	}
 
-	return playbackPrincipalForUserID(claims.UserID), true
+	return playbackAccess{
+		principal: playbackPrincipalForUserID(claims.UserID),
+		userID:    claims.UserID,
+		sessionID: claims.SessionID,
+	}, true
 }
 
 func (h *EpisodePlaybackHandler) loadPlayableEpisode(c *gin.Context, episodeID int64) (*models.EpisodeDetail, bool) {
@@ -4,13 +4,42 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"team4s.v3/backend/internal/models"
+
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	rapidGrantRequestThreshold = 5
+	rapidGrantRequestWindow    = time.Minute
+	playbackSecurityWriteLimit = 2 * time.Second
+	// playbackSecurityMaxPendingWrites begrenzt die gleichzeitig laufenden Hintergrund-
+	// Schreibvorgänge für Rate-Limit-Ereignisse; darüber hinaus werden sie verworfen.
+	playbackSecurityMaxPendingWrites = 16
+	// rateLimitEventWindow fasst 429-Antworten je Principal und Aktion zu einem Ereignis zusammen.
+	rateLimitEventWindow = time.Minute
+)
+
+// playbackSecurityEventStore speichert Sicherheitsereignisse und wertet die Sperrregel aus
+// (implementiert von repository.PlaybackSecurityRepository).
+type playbackSecurityEventStore interface {
+	RecordEvent(ctx context.Context, input models.PlaybackSecurityEventInput) (*models.PlaybackSecurityEvent, error)
+	ApplyGrantBlockRule(ctx context.Context, userID int64, rule models.PlaybackGrantBlockRule) (*models.PlaybackGrantBlock, error)
+	ActiveGrantBlock(ctx context.Context, userID int64) (*models.PlaybackGrantBlock, error)
+}
+
 type playbackAuditLogger struct {
-	client redis.UniversalClient
+	client    redis.UniversalClient
+	events    playbackSecurityEventStore
+	blockRule models.PlaybackGrantBlockRule
+	// writeSlots begrenzt die Hintergrund-Schreibvorgänge dieses Loggers (siehe record).
+	writeSlots chan struct{}
+	pending    sync.WaitGroup
 }
 
 func newPlaybackAuditLogger(client redis.UniversalClient) *playbackAuditLogger {
@@ -33,6 +62,34 @@ func (a *playbackAuditLogger) logRapidGrantRequests(ctx context.Context, userID
 		a.client.Incr(ctx, key)
 		a.client.Expire(ctx, key, 24*time.Hour)
 	}
+	a.record(ctx, models.PlaybackSecurityEventInput{
+		EventType: models.PlaybackSecurityEventRapidGrants,
+		Severity:  models.PlaybackSecuritySeverityWarning,
+		UserID:    userID,
+		ClientIP:  clientIP,
+		EpisodeID: episodeID,
+		Action:    "grant",
+	})
+}
+
+// trackGrantRequest zählt Grant-Anfragen je Nutzer und Episode und meldet auffällig viele
+// innerhalb eines kurzen Fensters als rapid grant requests.
+func (a *playbackAuditLogger) trackGrantRequest(ctx context.Context, userID int64, episodeID int64, clientIP string) {
+	if a == nil || a.client == nil {
+		return
+	}
+
+	key := fmt.Sprintf("audit:grant_requests:user:%d:episode:%d", userID, episodeID)
+	count, err := a.client.Incr(ctx, key).Result()
+	if err != nil {
+		return
+	}
+	if count == 1 {
+		a.client.Expire(ctx, key, rapidGrantRequestWindow)
+	}
+	if count > rapidGrantRequestThreshold {
+		a.logRapidGrantRequests(ctx, userID, episodeID, clientIP)
+	}
 }
 
 func (a *playbackAuditLogger) logMultipleIPsPerUser(ctx context.Context, userID int64, clientIP string) {
@@ -55,24 +112,48 @@ func (a *playbackAuditLogger) logMultipleIPsPerUser(ctx context.Context, userID
 				count,
 				clientIP,
 			)
+			a.record(ctx, models.PlaybackSecurityEventInput{
+				EventType: models.PlaybackSecurityEventMultipleIPs,
+				Severity:  models.PlaybackSecuritySeverityWarning,
+				UserID:    userID,
+				ClientIP:  clientIP,
+				Action:    "grant",
+				Details:   map[string]any{"ip_count": count},
+			})
 		}
 		a.client.Expire(ctx, key, 24*time.Hour)
 	}
 }
 
+// logRateLimitViolation zählt jede 429-Antwort, protokolliert und speichert aber nur die erste
+// je Principal, Aktion und rateLimitEventWindow. Rate-Limit-Ereignisse zählen nicht für die
+// Grant-Sperrregel, da auch normales Puffern und Spulen das Limit kurz überschreiten kann.
 func (a *playbackAuditLogger) logRateLimitViolation(ctx context.Context, action string, principal string, clientIP string) {
+	if a != nil && a.client != nil {
+		key := fmt.Sprintf("audit:rate_limit:principal:%s", principal)
+		a.client.Incr(ctx, key)
+		a.client.Expire(ctx, key, 1*time.Hour)
+
+		eventKey := fmt.Sprintf("audit:rate_limit_event:%s:principal:%s", action, principal)
+		if first, err := a.client.SetNX(ctx, eventKey, 1, rateLimitEventWindow).Result(); err == nil && !first {
+			return
+		}
+	}
+
 	log.Printf(
 		"AUDIT: rate limit violated (action=%s, principal=%s, client_ip=%s)",
 		action,
 		principal,
 		clientIP,
 	)
-
-	if a != nil && a.client != nil {
-		key := fmt.Sprintf("audit:rate_limit:principal:%s", principal)
-		a.client.Incr(ctx, key)
-		a.client.Expire(ctx, key, 1*time.Hour)
-	}
+	a.record(ctx, models.PlaybackSecurityEventInput{
+		EventType: models.PlaybackSecurityEventRateLimit,
+		Severity:  models.PlaybackSecuritySeverityWarning,
+		UserID:    playbackUserIDFromPrincipal(principal),
+		ClientIP:  clientIP,
+		Action:    action,
+		Details:   map[string]any{"principal": principal},
+	})
 }
 
 func (a *playbackAuditLogger) logGrantReplayAttempt(ctx context.Context, userID int64, episodeID int64, clientIP string) {
@@ -88,4 +169,109 @@ func (a *playbackAuditLogger) logGrantReplayAttempt(ctx context.Context, userID
 		a.client.Incr(ctx, key)
 		a.client.Expire(ctx, key, 24*time.Hour)
 	}
+	a.record(ctx, models.PlaybackSecurityEventInput{
+		EventType: models.PlaybackSecurityEventGrantReplay,
+		Severity:  models.PlaybackSecuritySeverityCritical,
+		UserID:    userID,
+		ClientIP:  clientIP,
+		EpisodeID: episodeID,
+		Action:    "stream",
+	})
+}
+
+func (a *playbackAuditLogger) logSessionLimitReached(ctx context.Context, scope string, userID int64, episodeID int64, clientIP string) {
+	log.Printf(
+		"AUDIT: playback session limit reached (scope=%s, user_id=%d, episode_id=%d, client_ip=%s)",
+		scope,
+		userID,
+		episodeID,
+		clientIP,
+	)
+
+	if a != nil && a.client != nil {
+		key := fmt.Sprintf("audit:session_limit:%s:user:%d", scope, userID)
+		a.client.Incr(ctx, key)
+		a.client.Expire(ctx, key, 1*time.Hour)
+	}
+	a.record(ctx, models.PlaybackSecurityEventInput{
+		EventType: models.PlaybackSecurityEventSessionLimit,
+		Severity:  models.PlaybackSecuritySeverityInfo,
+		UserID:    userID,
+		ClientIP:  clientIP,
+		EpisodeID: episodeID,
+		Action:    "session",
+		Details:   map[string]any{"scope": scope},
+	})
+}
+
+// record speichert ein Ereignis. Ereignisse, die für die Grant-Sperre zählen können, werden direkt
+// geschrieben, damit keines verloren geht. Nur die zusammengefassten Rate-Limit-Ereignisse laufen
+// im Hintergrund; sind dafür alle writeSlots belegt, werden sie nur protokolliert.
+func (a *playbackAuditLogger) record(ctx context.Context, input models.PlaybackSecurityEventInput) {
+	if a == nil || a.events == nil {
+		return
+	}
+	if input.EventType != models.PlaybackSecurityEventRateLimit {
+		a.persist(context.WithoutCancel(ctx), input)
+		return
+	}
+
+	select {
+	case a.writeSlots <- struct{}{}:
+	default:
+		log.Printf("episode_playback: security event dropped, too many pending writes (type=%s, user_id=%d)", input.EventType, input.UserID)
+		return
+	}
+
+	a.pending.Add(1)
+	go func() {
+		defer func() {
+			<-a.writeSlots
+			a.pending.Done()
+		}()
+		a.persist(context.WithoutCancel(ctx), input)
+	}()
+}
+
+// persist speichert ein Ereignis dauerhaft und prüft danach die Sperrregel des Nutzers, sofern das
+// Ereignis für sie zählt. Fehler werden nur protokolliert, damit die Wiedergabe nicht an der
+// Auswertung hängt.
+func (a *playbackAuditLogger) persist(ctx context.Context, input models.PlaybackSecurityEventInput) {
+	ctx, cancel := context.WithTimeout(ctx, playbackSecurityWriteLimit)
+	defer cancel()
+
+	if _, err := a.events.RecordEvent(ctx, input); err != nil {
+		log.Printf("episode_playback: record security event failed (type=%s, user_id=%d): %v", input.EventType, input.UserID, err)
+		return
+	}
+	if input.UserID <= 0 || !models.CountsTowardPlaybackGrantBlock(input.EventType, input.Severity) {
+		return
+	}
+
+	block, err := a.events.ApplyGrantBlockRule(ctx, input.UserID, a.blockRule)
+	if err != nil {
+		log.Printf("episode_playback: apply grant block rule failed (user_id=%d): %v", input.UserID, err)
+		return
+	}
+	if block != nil {
+		log.Printf(
+			"AUDIT: playback grants blocked (user_id=%d, event_count=%d, blocked_until=%s)",
+			block.UserID,
+			block.EventCount,
+			block.BlockedUntil.Format(time.RFC3339),
+		)
+	}
+}
+
+// playbackUserIDFromPrincipal liefert die Nutzer-ID eines "user:<id>"-Principals, sonst 0.
+func playbackUserIDFromPrincipal(principal string) int64 {
+	raw, ok := strings.CutPrefix(principal, "user:")
+	if !ok {
+		return 0
+	}
+	userID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0
+	}
+	return userID
 }
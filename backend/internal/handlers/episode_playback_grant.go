@@ -13,6 +13,7 @@ import (
 )
 
 // CreatePlaybackGrant verarbeitet POST /api/v1/episodes/:id/playback-grant und stellt ein signiertes Stream-Grant-Token aus.
+// Sind Playback-Sessions aktiv, wird der Grant an eine (ggf. wiederverwendete) Session gebunden.
 func (h *EpisodePlaybackHandler) CreatePlaybackGrant(c *gin.Context) {
 	identity, ok := middleware.CommentAuthIdentityFromContext(c)
 	if !ok {
@@ -32,12 +33,16 @@ func (h *EpisodePlaybackHandler) CreatePlaybackGrant(c *gin.Context) {
 	if !h.enforcePlaybackRateLimit(c, "grant", playbackPrincipalForUserID(identity.UserID)) {
 		return
 	}
+	if !h.enforcePlaybackGrantBlock(c, identity.UserID) {
+		return
+	}
 
 	clientIP := extractClientIP(c)
 
-	// Track multiple IPs per user for audit
+	// Track multiple IPs and rapid grant requests per user for audit
 	if h.auditLogger != nil {
 		h.auditLogger.logMultipleIPsPerUser(c.Request.Context(), identity.UserID, clientIP)
+		h.auditLogger.trackGrantRequest(c.Request.Context(), identity.UserID, episodeID, clientIP)
 	}
 
 	episode, ok := h.loadPlayableEpisode(c, episodeID)
@@ -63,9 +68,19 @@ func (h *EpisodePlaybackHandler) CreatePlaybackGrant(c *gin.Context) {
 		return
 	}
 
-	grantToken, expiresAt, err := auth.CreateReleaseStreamGrant(
+	session, ok := h.openPlaybackSession(c, identity.UserID, episodeID)
+	if !ok {
+		return
+	}
+	sessionID := ""
+	if session != nil {
+		sessionID = session.ID
+	}
+
+	grantToken, expiresAt, err := auth.CreateReleaseStreamGrantWithSession(
 		episodeID,
 		identity.UserID,
+		sessionID,
 		h.releaseGrantSecret,
 		time.Now(),
 		h.releaseGrantTTL,
@@ -82,14 +97,21 @@ func (h *EpisodePlaybackHandler) CreatePlaybackGrant(c *gin.Context) {
 
 	log.Printf("episode playback grant: created (episode_id=%d, user_id=%d, client_ip=%s)", episodeID, identity.UserID, clientIP)
 
+	data := gin.H{
+		"episode_id":  episodeID,
+		"grant_token": grantToken,
+		"expires_at":  expiresAt,
+		"ttl_seconds": int64(h.releaseGrantTTL / time.Second),
+		"issued_for":  identity.UserID,
+	}
+	if session != nil {
+		data["session_id"] = session.ID
+		data["session_expires_at"] = session.ExpiresAt
+		data["heartbeat_interval_seconds"] = h.heartbeatIntervalSeconds()
+	}
+
 	c.Header("Cache-Control", "no-store")
 	c.JSON(http.StatusCreated, gin.H{
-		"data": gin.H{
-			"episode_id":  episodeID,
-			"grant_token": grantToken,
-			"expires_at":  expiresAt,
-			"ttl_seconds": int64(h.releaseGrantTTL / time.Second),
-			"issued_for":  identity.UserID,
-		},
+		"data": data,
 	})
 }
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const playbackGrantsBlockedMessage = "stream-grants vorübergehend gesperrt"
+
+// WithPlaybackSecurity speichert Sicherheitsereignisse der Wiedergabe dauerhaft in store und
+// sperrt die Grants eines Nutzers automatisch nach rule.
+func (h *EpisodePlaybackHandler) WithPlaybackSecurity(
+	store playbackSecurityEventStore,
+	rule models.PlaybackGrantBlockRule,
+) *EpisodePlaybackHandler {
+	h.auditLogger = withPlaybackSecurityEvents(h.auditLogger, store, rule)
+	h.securityEvents = store
+	return h
+}
+
+// withPlaybackSecurityEvents verbindet logger mit store und rule. Ohne logger (kein Redis) wird
+// einer angelegt, der nur speichert.
+func withPlaybackSecurityEvents(
+	logger *playbackAuditLogger,
+	store playbackSecurityEventStore,
+	rule models.PlaybackGrantBlockRule,
+) *playbackAuditLogger {
+	if logger == nil {
+		logger = &playbackAuditLogger{}
+	}
+	logger.events = store
+	logger.blockRule = rule
+	if logger.writeSlots == nil {
+		logger.writeSlots = make(chan struct{}, playbackSecurityMaxPendingWrites)
+	}
+	return logger
+}
+
+// enforcePlaybackGrantBlock weist Nutzer mit aktiver Grant-Sperre mit 403 ab. Ist die Sperre
+// nicht prüfbar, wird die Anfrage zugelassen.
+func (h *EpisodePlaybackHandler) enforcePlaybackGrantBlock(c *gin.Context, userID int64) bool {
+	return enforceGrantBlock(c, h.securityEvents, "episode_playback", userID)
+}
+
+// enforceGrantBlock prüft die Grant-Sperre von userID in store für Episoden- und Release-Streams.
+func enforceGrantBlock(c *gin.Context, store playbackSecurityEventStore, logPrefix string, userID int64) bool {
+	if store == nil || userID <= 0 {
+		return true
+	}
+
+	block, err := store.ActiveGrantBlock(c.Request.Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		log.Printf("%s: grant block check failed (user_id=%d): %v", logPrefix, userID, err)
+		return true
+	}
+
+	retryAfter := int64(time.Until(block.BlockedUntil).Seconds()) + 1
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": gin.H{
+			"message": playbackGrantsBlockedMessage,
+		},
+	})
+	return false
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/auth"
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakePlaybackSecurityStore bildet repository.PlaybackSecurityRepository im Speicher nach.
+type fakePlaybackSecurityStore struct {
+	events []models.PlaybackSecurityEventInput
+	blocks []models.PlaybackGrantBlock
+}
+
+func (s *fakePlaybackSecurityStore) RecordEvent(_ context.Context, input models.PlaybackSecurityEventInput) (*models.PlaybackSecurityEvent, error) {
+	s.events = append(s.events, input)
+	return &models.PlaybackSecurityEvent{ID: int64(len(s.events)), EventType: input.EventType, Severity: input.Severity}, nil
+}
+
+func (s *fakePlaybackSecurityStore) ApplyGrantBlockRule(ctx context.Context, userID int64, rule models.PlaybackGrantBlockRule) (*models.PlaybackGrantBlock, error) {
+	if !rule.Enabled() {
+		return nil, nil
+	}
+	if _, err := s.ActiveGrantBlock(ctx, userID); err == nil {
+		return nil, nil
+	}
+	count := 0
+	for _, event := range s.events {
+		if event.UserID == userID && models.CountsTowardPlaybackGrantBlock(event.EventType, event.Severity) {
+			count++
+		}
+	}
+	if count < rule.Threshold {
+		return nil, nil
+	}
+	block := models.PlaybackGrantBlock{
+		ID:           int64(len(s.blocks) + 1),
+		UserID:       userID,
+		EventCount:   int32(count),
+		BlockedUntil: time.Now().Add(rule.Duration),
+	}
+	s.blocks = append(s.blocks, block)
+	return &block, nil
+}
+
+func (s *fakePlaybackSecurityStore) ActiveGrantBlock(_ context.Context, userID int64) (*models.PlaybackGrantBlock, error) {
+	for i := range s.blocks {
+		block := s.blocks[i]
+		if block.UserID == userID && block.LiftedAt == nil && block.BlockedUntil.After(time.Now()) {
+			return &block, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// flush wartet auf alle laufenden Hintergrund-Schreibvorgänge von a.
+func (a *playbackAuditLogger) flush() {
+	if a != nil {
+		a.pending.Wait()
+	}
+}
+
+func TestPlaybackAuditLoggerPersistsEventsAndBlocksGrants(t *testing.T) {
+	store := &fakePlaybackSecurityStore{}
+	handler := (&EpisodePlaybackHandler{}).WithPlaybackSecurity(store, models.PlaybackGrantBlockRule{
+		Threshold: 2,
+		Window:    10 * time.Minute,
+		Duration:  30 * time.Minute,
+	})
+	ctx := context.Background()
+
+	handler.auditLogger.logSessionLimitReached(ctx, "user", 5, 76, "10.0.0.1")
+	handler.auditLogger.flush()
+	for i := 0; i < 3; i++ {
+		handler.auditLogger.logRateLimitViolation(ctx, "grant", "user:5", "10.0.0.1")
+		handler.auditLogger.flush()
+	}
+	if len(store.events) != 4 || len(store.blocks) != 0 {
+		t.Fatalf("expected rate limit events not to count toward a block, got %d events and %d blocks", len(store.events), len(store.blocks))
+	}
+	if store.events[1].UserID != 5 || store.events[1].Action != "grant" {
+		t.Fatalf("expected rate limit event for user 5, got %+v", store.events[1])
+	}
+
+	handler.auditLogger.logRapidGrantRequests(ctx, 5, 76, "10.0.0.1")
+	handler.auditLogger.flush()
+
+	context, recorder := newPlaybackSessionTestContext()
+	if !handler.enforcePlaybackGrantBlock(context, 5) {
+		t.Fatalf("expected grants to be allowed below the threshold")
+	}
+
+	handler.auditLogger.logGrantReplayAttempt(ctx, 5, 76, "10.0.0.2")
+	handler.auditLogger.flush()
+	if len(store.blocks) != 1 {
+		t.Fatalf("expected a block after the second warning/critical event, got %d", len(store.blocks))
+	}
+	if store.events[5].Severity != models.PlaybackSecuritySeverityCritical {
+		t.Fatalf("expected grant replay to be critical, got %q", store.events[5].Severity)
+	}
+
+	context, recorder = newPlaybackSessionTestContext()
+	if handler.enforcePlaybackGrantBlock(context, 5) {
+		t.Fatalf("expected blocked user to be rejected")
+	}
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", recorder.Code)
+	}
+	if message := decodeErrorMessage(t, recorder.Body.Bytes()); message != playbackGrantsBlockedMessage {
+		t.Fatalf("unexpected message: %q", message)
+	}
+	retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+	if err != nil || retryAfter <= 0 || retryAfter > 30*60+1 {
+		t.Fatalf("expected Retry-After until the block ends, got %q", recorder.Header().Get("Retry-After"))
+	}
+
+	context, _ = newPlaybackSessionTestContext()
+	if !handler.enforcePlaybackGrantBlock(context, 6) {
+		t.Fatalf("expected other users to be unaffected")
+	}
+}
+
+func TestPlaybackAuditLoggerSkipsRuleForAnonymousEvents(t *testing.T) {
+	store := &fakePlaybackSecurityStore{}
+	handler := (&EpisodePlaybackHandler{}).WithPlaybackSecurity(store, models.PlaybackGrantBlockRule{
+		Threshold: 1,
+		Window:    time.Minute,
+		Duration:  time.Minute,
+	})
+
+	handler.auditLogger.logRateLimitViolation(context.Background(), "play", "ip:10.0.0.1", "10.0.0.1")
+	handler.auditLogger.flush()
+	if len(store.events) != 1 || store.events[0].UserID != 0 {
+		t.Fatalf("expected one anonymous event, got %+v", store.events)
+	}
+	if len(store.blocks) != 0 {
+		t.Fatalf("expected no block for anonymous principals")
+	}
+
+	details, err := json.Marshal(store.events[0].Details)
+	if err != nil || string(details) != `{"principal":"ip:10.0.0.1"}` {
+		t.Fatalf("unexpected details: %s", details)
+	}
+}
+
+func TestPlaybackAuditLoggerAggregatesRateLimitEvents(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	store := &fakePlaybackSecurityStore{}
+	logger := withPlaybackSecurityEvents(newPlaybackAuditLogger(client), store, models.PlaybackGrantBlockRule{})
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		logger.logRateLimitViolation(ctx, "hls", "user:5", "10.0.0.1")
+	}
+	logger.flush()
+	logger.logRateLimitViolation(ctx, "play", "user:5", "10.0.0.1")
+	logger.flush()
+	if len(store.events) != 2 {
+		t.Fatalf("expected one stored event per action and window, got %d", len(store.events))
+	}
+	if count, err := client.Get(ctx, "audit:rate_limit:principal:user:5").Int64(); err != nil || count != 6 {
+		t.Fatalf("expected every violation to be counted, got %d (%v)", count, err)
+	}
+
+	mr.FastForward(rateLimitEventWindow)
+	logger.logRateLimitViolation(ctx, "hls", "user:5", "10.0.0.1")
+	logger.flush()
+	if len(store.events) != 3 {
+		t.Fatalf("expected a new event in the next window, got %d", len(store.events))
+	}
+}
+
+func TestPlaybackAuditLoggerKeepsBlockEventsWhenWritesPile(t *testing.T) {
+	store := &fakePlaybackSecurityStore{}
+	logger := withPlaybackSecurityEvents(nil, store, models.PlaybackGrantBlockRule{
+		Threshold: 1,
+		Window:    time.Minute,
+		Duration:  time.Minute,
+	})
+	for i := 0; i < cap(logger.writeSlots); i++ {
+		logger.writeSlots <- struct{}{}
+	}
+	ctx := context.Background()
+
+	logger.logRateLimitViolation(ctx, "grant", "user:5", "10.0.0.1")
+	logger.logGrantReplayAttempt(ctx, 5, 76, "10.0.0.1")
+	if len(store.events) != 1 || store.events[0].EventType != models.PlaybackSecurityEventGrantReplay {
+		t.Fatalf("expected only the rate limit event to be dropped, got %+v", store.events)
+	}
+	if len(store.blocks) != 1 {
+		t.Fatalf("expected the replay to block grants immediately, got %d blocks", len(store.blocks))
+	}
+}
+
+func TestReleaseStreamRejectsBlockedUsers(t *testing.T) {
+	store := &fakePlaybackSecurityStore{
+		blocks: []models.PlaybackGrantBlock{{ID: 1, UserID: 5, BlockedUntil: time.Now().Add(time.Hour)}},
+	}
+	handler := (&FansubHandler{releaseGrantSecret: "secret", releaseGrantTTL: time.Minute}).
+		WithPlaybackSecurity(store, models.PlaybackGrantBlockRule{})
+
+	context, recorder := newPlaybackSessionTestContext()
+	context.Params = gin.Params{{Key: "id", Value: "7"}}
+	context.Set("auth_identity", middleware.AuthIdentity{UserID: 5, DisplayName: "Nutzer"})
+	handler.CreateReleaseStreamGrant(context)
+	if recorder.Code != http.StatusForbidden || recorder.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected blocked grant request to be rejected, got %d", recorder.Code)
+	}
+
+	grant, _, err := auth.CreateReleaseStreamGrant(7, 5, "secret", time.Now(), time.Minute)
+	if err != nil {
+		t.Fatalf("create grant: %v", err)
+	}
+	context, recorder = newPlaybackSessionTestContext()
+	context.Request = httptest.NewRequest(http.MethodGet, "/api/v1/releases/7/stream?grant="+grant, nil)
+	if _, ok := handler.authorizeReleaseStream(context, 7); ok || recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected stream with grant of blocked user to be rejected, got %d", recorder.Code)
+	}
+
+	context, _ = newPlaybackSessionTestContext()
+	context.Set("auth_identity", middleware.AuthIdentity{UserID: 6, DisplayName: "Nutzer"})
+	if principal, ok := handler.authorizeReleaseStream(context, 7); !ok || principal != "user:6" {
+		t.Fatalf("expected other users to be unaffected, got %q", principal)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/auth"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -64,7 +66,20 @@ func (h *EpisodePlaybackHandler) enforcePlaybackRateLimit(
 	action string,
 	principal string,
 ) bool {
-	if h.playbackRateLimiter == nil {
+	return enforceEpisodePlaybackRateLimit(c, h.playbackRateLimiter, h.auditLogger, action, principal)
+}
+
+// enforceEpisodePlaybackRateLimit prüft das Rate-Limit pro Prinzipal und pro Client-IP und
+// schreibt bei Überschreitung 429 bzw. bei Store-Fehlern 503. Wird auch vom lokalen
+// Release-Stream verwendet, damit beide Wiedergabewege dieselben Grenzen haben.
+func enforceEpisodePlaybackRateLimit(
+	c *gin.Context,
+	limiter *episodePlaybackRateLimiter,
+	auditLogger *playbackAuditLogger,
+	action string,
+	principal string,
+) bool {
+	if limiter == nil {
 		return true
 	}
 
@@ -72,11 +87,11 @@ func (h *EpisodePlaybackHandler) enforcePlaybackRateLimit(
 
 	// Check user-based rate limit
 	userKey := strings.TrimSpace(action) + ":" + strings.TrimSpace(principal)
-	userAllowed, userRetryAfter, userErr := h.playbackRateLimiter.Allow(c.Request.Context(), userKey)
+	userAllowed, userRetryAfter, userErr := limiter.Allow(c.Request.Context(), userKey)
 
 	// Check IP-based rate limit
 	ipKey := strings.TrimSpace(action) + ":" + playbackPrincipalForIP(clientIP)
-	ipAllowed, ipRetryAfter, ipErr := h.playbackRateLimiter.Allow(c.Request.Context(), ipKey)
+	ipAllowed, ipRetryAfter, ipErr := limiter.Allow(c.Request.Context(), ipKey)
 
 	if userErr != nil || ipErr != nil {
 		log.Printf(
@@ -98,32 +113,78 @@ func (h *EpisodePlaybackHandler) enforcePlaybackRateLimit(
 	}
 
 	if !userAllowed || !ipAllowed {
-		if h.auditLogger != nil {
-			h.auditLogger.logRateLimitViolation(c.Request.Context(), action, principal, clientIP)
+		if auditLogger != nil {
+			auditLogger.logRateLimitViolation(c.Request.Context(), action, principal, clientIP)
 		}
 
 		retryAfter := userRetryAfter
 		if ipRetryAfter > retryAfter {
 			retryAfter = ipRetryAfter
 		}
+		writePlaybackRateLimitExceeded(c, retryAfter)
+		return false
+	}
 
-		retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
-		if retryAfterSeconds < 1 {
-			retryAfterSeconds = 1
-		}
+	return true
+}
+
+// enforceHLSResourceRateLimit begrenzt Segment- und Playlist-Abrufe eines HLS-Grants mit einem
+// eigenen, deutlich höheren Budget als Play: Wiedergabe mit kurzen Segmenten und Spulen erzeugt
+// viele Abrufe pro Minute. Gezählt wird je Playback-Session, ohne Session je Principal und
+// Episode; die Client-IP zählt nicht, da der signierte Grant den Zuschauer bereits bindet.
+func (h *EpisodePlaybackHandler) enforceHLSResourceRateLimit(c *gin.Context, claims auth.HLSResourceGrantClaims) bool {
+	if h.hlsRateLimiter == nil {
+		return true
+	}
 
-		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
-		c.JSON(http.StatusTooManyRequests, gin.H{
+	key := fmt.Sprintf("hls:%s:episode:%d", strings.TrimSpace(claims.Principal), claims.EpisodeID)
+	if claims.SessionID != "" {
+		key = "hls:session:" + claims.SessionID
+	}
+	allowed, retryAfter, err := h.hlsRateLimiter.Allow(c.Request.Context(), key)
+	if err != nil {
+		log.Printf(
+			"episode_playback: hls rate limit store unavailable (episode_id=%d principal=%s): %v",
+			claims.EpisodeID,
+			claims.Principal,
+			err,
+		)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": gin.H{
-				"message": episodePlaybackRateLimitExceededMessage,
+				"message": episodePlaybackRateLimitUnavailableMessage,
 			},
 		})
 		return false
 	}
+	if !allowed {
+		if h.auditLogger != nil {
+			h.auditLogger.logRateLimitViolation(c.Request.Context(), "hls", claims.Principal, extractClientIP(c))
+		}
+		writePlaybackRateLimitExceeded(c, retryAfter)
+		return false
+	}
 
 	return true
 }
 
+// writePlaybackRateLimitExceeded schreibt 429 mit Retry-After in ganzen Sekunden (mindestens 1).
+func writePlaybackRateLimitExceeded(c *gin.Context, retryAfter time.Duration) {
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+	if retryAfterSeconds < 1 {
+		retryAfterSeconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": gin.H{
+			"message": episodePlaybackRateLimitExceededMessage,
+		},
+	})
+}
+
+// acquirePlaybackSlot begrenzt die gleichzeitig durch diese Instanz gereichten Streams. Die
+// Playback-Sessions begrenzen Nutzer und Cluster; dieser Schutz verhindert zusätzlich, dass
+// eine einzelne Instanz mit Proxy-Verbindungen überlastet wird.
 func (h *EpisodePlaybackHandler) acquirePlaybackSlot(c *gin.Context) (func(), bool) {
 	if h.streamSlots == nil {
 		return func() {}, true
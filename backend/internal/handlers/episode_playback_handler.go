@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/repository"
 
 	"github.com/redis/go-redis/v9"
@@ -15,13 +16,16 @@ type EpisodePlaybackConfig struct {
 	EmbyAPIKey              string
 	EmbyStreamBaseURL       string
 	EmbyStreamPathTemplate  string
+	EmbyHLSPathTemplate     string
 	AllowedAnimeIDs         []int64
 	ReleaseGrantSecret      string
 	ReleaseGrantTTLSeconds  int
 	PlaybackRateLimitClient redis.UniversalClient
 	PlaybackRateLimit       int
 	PlaybackRateWindowSec   int
+	HLSRateLimit            int
 	MaxConcurrentStreams    int
+	HLSTTLSeconds           int
 }
 
 // EpisodePlaybackHandler verwaltet HTTP-Endpunkte für Episode-Wiedergabe, Stream-Grants und Rate-Limiting.
@@ -30,14 +34,21 @@ type EpisodePlaybackHandler struct {
 	embyAPIKey             string
 	embyStreamBaseURL      string
 	embyStreamPathTemplate string
+	embyHLSPathTemplate    string
+	mediaServer            mediaserver.MediaServerProvider
+	hlsTTL                 time.Duration
 	allowedAnimeIDs        map[int64]struct{}
 	releaseGrantSecret     string
 	releaseGrantTTL        time.Duration
 	playbackRateLimiter    *episodePlaybackRateLimiter
+	hlsRateLimiter         *episodePlaybackRateLimiter
 	streamSlots            chan struct{}
+	sessions               playbackSessionStore
 	httpClient             *http.Client
 	grantStore             grantTokenStore
 	auditLogger            *playbackAuditLogger
+	securityEvents         playbackSecurityEventStore
+	progressRepo           *repository.WatchProgressRepository
 }
 
 // NewEpisodePlaybackHandler erstellt einen neuen EpisodePlaybackHandler mit Repository und vollständiger Konfiguration.
@@ -54,6 +65,14 @@ func NewEpisodePlaybackHandler(repo *repository.EpisodeRepository, cfg EpisodePl
 	if streamPathTemplate == "" || !strings.Contains(streamPathTemplate, "%s") {
 		streamPathTemplate = "/Videos/%s/stream"
 	}
+	hlsPathTemplate := strings.TrimSpace(cfg.EmbyHLSPathTemplate)
+	if hlsPathTemplate == "" || !strings.Contains(hlsPathTemplate, "%s") {
+		hlsPathTemplate = "/Videos/%s/master.m3u8"
+	}
+	hlsTTL := time.Duration(cfg.HLSTTLSeconds) * time.Second
+	if hlsTTL <= 0 {
+		hlsTTL = 4 * time.Hour
+	}
 
 	var streamSlots chan struct{}
 	if cfg.MaxConcurrentStreams > 0 {
@@ -65,6 +84,8 @@ func NewEpisodePlaybackHandler(repo *repository.EpisodeRepository, cfg EpisodePl
 		embyAPIKey:             strings.TrimSpace(cfg.EmbyAPIKey),
 		embyStreamBaseURL:      strings.TrimSpace(cfg.EmbyStreamBaseURL),
 		embyStreamPathTemplate: streamPathTemplate,
+		embyHLSPathTemplate:    hlsPathTemplate,
+		hlsTTL:                 hlsTTL,
 		allowedAnimeIDs:        allowedAnimeIDs,
 		releaseGrantSecret:     strings.TrimSpace(cfg.ReleaseGrantSecret),
 		releaseGrantTTL:        time.Duration(cfg.ReleaseGrantTTLSeconds) * time.Second,
@@ -73,9 +94,14 @@ func NewEpisodePlaybackHandler(repo *repository.EpisodeRepository, cfg EpisodePl
 			cfg.PlaybackRateLimit,
 			time.Duration(cfg.PlaybackRateWindowSec)*time.Second,
 		),
-		streamSlots:  streamSlots,
-		grantStore:   newGrantTokenStore(cfg.PlaybackRateLimitClient),
-		auditLogger:  newPlaybackAuditLogger(cfg.PlaybackRateLimitClient),
+		hlsRateLimiter: newEpisodePlaybackRateLimiter(
+			cfg.PlaybackRateLimitClient,
+			cfg.HLSRateLimit,
+			time.Duration(cfg.PlaybackRateWindowSec)*time.Second,
+		),
+		streamSlots: streamSlots,
+		grantStore:  newGrantTokenStore(cfg.PlaybackRateLimitClient),
+		auditLogger: newPlaybackAuditLogger(cfg.PlaybackRateLimitClient),
 		httpClient: &http.Client{
 			Timeout: 0,
 		},
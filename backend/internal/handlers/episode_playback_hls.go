@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	hlsPlaylistContentType    = "application/vnd.apple.mpegurl"
+	hlsPlaylistMaxBytes       = 4 << 20
+	hlsPlaylistFetchTimeout   = 15 * time.Second
+	hlsResourcePathSuffix     = "/resource"
+	hlsResourceTokenParameter = "token"
+)
+
+var (
+	// hlsURIAttributePattern findet URI-Attribute in Tags wie EXT-X-MEDIA, EXT-X-KEY,
+	// EXT-X-MAP und EXT-X-I-FRAME-STREAM-INF.
+	hlsURIAttributePattern = regexp.MustCompile(`URI="([^"]*)"`)
+
+	// hlsSecretQueryParameters werden nie in Grants übernommen und erst beim Upstream-Abruf
+	// wieder gesetzt, damit der API-Schlüssel nicht beim Client landet.
+	hlsSecretQueryParameters = []string{"api_key", "ApiKey", "X-Emby-Token", "X-MediaBrowser-Token"}
+
+	errHLSForeignHost = errors.New("hls uri points to foreign host")
+)
+
+// PlayHLS verarbeitet GET /api/v1/episodes/:id/play/hls. Autorisierung, Rate-Limit und
+// Grant-Verbrauch entsprechen Play; die Master-Playlist wird vom Mediaserver geladen und jede
+// URI auf /play/hls/resource mit einem eigenen, signierten Ressourcen-Grant umgeschrieben.
+func (h *EpisodePlaybackHandler) PlayHLS(c *gin.Context) {
+	episodeID, err := parseEpisodeID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige episode id")
+		return
+	}
+
+	access, ok := h.authorizePlayback(c, episodeID)
+	if !ok {
+		return
+	}
+	if !h.enforcePlaybackRateLimit(c, "play", access.principal) {
+		return
+	}
+	session, ok := h.attachPlaybackSession(c, episodeID, access)
+	if !ok {
+		return
+	}
+	sessionID := ""
+	if session != nil {
+		sessionID = session.ID
+	}
+
+	if !h.playbackConfigured() || strings.TrimSpace(h.releaseGrantSecret) == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"message": "stream derzeit nicht verfügbar",
+			},
+		})
+		return
+	}
+
+	episode, ok := h.loadPlayableEpisode(c, episodeID)
+	if !ok {
+		return
+	}
+
+	sourceURL := firstNonEmpty(episode.StreamLinks)
+	if sourceURL == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"message": "stream nicht gefunden",
+			},
+		})
+		return
+	}
+	masterURL, err := h.buildEmbyHLSURL(sourceURL)
+	if err != nil {
+		log.Printf("episode_playback: build hls url failed (episode_id=%d): %v", episodeID, err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": gin.H{
+				"message": "stream nicht erreichbar",
+			},
+		})
+		return
+	}
+
+	h.proxyHLSPlaylist(c, episodeID, access.principal, sessionID, masterURL, time.Now().Add(h.hlsTTL).Unix())
+}
+
+// PlayHLSResource verarbeitet GET /api/v1/episodes/:id/play/hls/resource?token=...
+// Variant-Playlists werden erneut umgeschrieben, Segmente unter dem Instanz-Slot-Limit von Play
+// durchgereicht. Das Rate-Limit ist ein eigenes, höheres Segment-Budget je Session (siehe
+// enforceHLSResourceRateLimit). Jeder Abruf hält die Playback-Session aus dem Grant am Leben.
+func (h *EpisodePlaybackHandler) PlayHLSResource(c *gin.Context) {
+	episodeID, err := parseEpisodeID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige episode id")
+		return
+	}
+
+	claims, err := auth.ParseAndVerifyHLSResourceGrant(c.Query(hlsResourceTokenParameter), h.releaseGrantSecret, time.Now())
+	if err != nil || claims.EpisodeID != episodeID {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"message": "ungültiger stream grant",
+			},
+		})
+		return
+	}
+	if !h.enforceHLSResourceRateLimit(c, claims) {
+		return
+	}
+	if h.sessions != nil && claims.SessionID != "" {
+		if _, ok := h.touchPlaybackSession(c, claims.SessionID); !ok {
+			return
+		}
+	}
+
+	upstream, err := url.Parse(claims.UpstreamURL)
+	if err != nil {
+		badRequest(c, "ungültiger stream grant")
+		return
+	}
+	h.playbackMediaServer(upstream).Authorize(upstream)
+
+	if strings.HasSuffix(strings.ToLower(upstream.Path), ".m3u8") {
+		h.proxyHLSPlaylist(c, episodeID, claims.Principal, claims.SessionID, upstream.String(), claims.ExpiresAt)
+		return
+	}
+
+	releaseSlot, ok := h.acquirePlaybackSlot(c)
+	if !ok {
+		return
+	}
+	defer releaseSlot()
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, upstream.String(), nil)
+	if err != nil {
+		log.Printf("episode_playback: create hls segment request failed (episode_id=%d): %v", episodeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"message": "interner serverfehler",
+			},
+		})
+		return
+	}
+	copyProxyHeaders(c.Request.Header, req.Header)
+	h.proxyPlaybackResponse(c, episodeID, req)
+}
+
+// proxyHLSPlaylist lädt eine Playlist vom Mediaserver und liefert sie mit umgeschriebenen URIs aus.
+func (h *EpisodePlaybackHandler) proxyHLSPlaylist(c *gin.Context, episodeID int64, principal string, sessionID string, upstreamURL string, expiresAt int64) {
+	base, err := url.Parse(upstreamURL)
+	if err != nil {
+		log.Printf("episode_playback: parse hls playlist url failed (episode_id=%d): %v", episodeID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), hlsPlaylistFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstreamURL, nil)
+	if err != nil {
+		log.Printf("episode_playback: create hls playlist request failed (episode_id=%d): %v", episodeID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	copyProxyHeaders(c.Request.Header, req.Header)
+	req.Header.Del("Range")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		log.Printf("episode_playback: hls playlist request failed (episode_id=%d): %v", episodeID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "stream nicht erreichbar"}})
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "stream nicht gefunden"}})
+		return
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("episode_playback: hls playlist upstream status %d (episode_id=%d)", resp.StatusCode, episodeID)
+		c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "stream nicht erreichbar"}})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, hlsPlaylistMaxBytes+1))
+	if err != nil || len(body) > hlsPlaylistMaxBytes {
+		log.Printf("episode_playback: hls playlist unreadable (episode_id=%d, bytes=%d): %v", episodeID, len(body), err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "stream nicht erreichbar"}})
+		return
+	}
+
+	resourcePath := strings.TrimSuffix(c.Request.URL.Path, hlsResourcePathSuffix) + hlsResourcePathSuffix
+	rewritten, err := rewriteHLSPlaylist(string(body), base, func(target *url.URL) (string, error) {
+		token, err := auth.CreateHLSResourceGrant(auth.HLSResourceGrantClaims{
+			EpisodeID:   episodeID,
+			Principal:   principal,
+			UpstreamURL: stripHLSSecretParameters(target).String(),
+			ExpiresAt:   expiresAt,
+			SessionID:   sessionID,
+		}, h.releaseGrantSecret)
+		if err != nil {
+			return "", err
+		}
+		return resourcePath + "?" + hlsResourceTokenParameter + "=" + url.QueryEscape(token), nil
+	})
+	if err != nil {
+		log.Printf("episode_playback: hls playlist rewrite failed (episode_id=%d): %v", episodeID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "stream nicht erreichbar"}})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, hlsPlaylistContentType, []byte(rewritten))
+}
+
+// rewriteHLSPlaylist ersetzt jede URI einer Playlist (URI-Zeilen und URI-Attribute) durch das
+// Ergebnis von rewrite. Relative URIs werden gegen base aufgelöst; URIs auf fremde Hosts werden
+// abgelehnt, damit der Proxy nur den konfigurierten Mediaserver erreicht.
+func rewriteHLSPlaylist(playlist string, base *url.URL, rewrite func(*url.URL) (string, error)) (string, error) {
+	if !strings.HasPrefix(strings.TrimPrefix(playlist, "\ufeff"), "#EXTM3U") {
+		return "", errors.New("hls playlist missing #EXTM3U header")
+	}
+
+	resolve := func(raw string) (string, error) {
+		ref, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return "", fmt.Errorf("parse hls uri %q: %w", raw, err)
+		}
+		target := base.ResolveReference(ref)
+		if !strings.EqualFold(target.Scheme, base.Scheme) || !strings.EqualFold(target.Host, base.Host) {
+			return "", errHLSForeignHost
+		}
+		return rewrite(target)
+	}
+
+	var out strings.Builder
+	out.Grow(len(playlist) * 2)
+	scanner := bufio.NewScanner(strings.NewReader(playlist))
+	scanner.Buffer(make([]byte, 64<<10), hlsPlaylistMaxBytes)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			out.WriteString(line)
+		case strings.HasPrefix(trimmed, "#"):
+			var rewriteErr error
+			line = hlsURIAttributePattern.ReplaceAllStringFunc(line, func(match string) string {
+				rewritten, err := resolve(hlsURIAttributePattern.FindStringSubmatch(match)[1])
+				if err != nil {
+					rewriteErr = err
+					return match
+				}
+				return `URI="` + rewritten + `"`
+			})
+			if rewriteErr != nil {
+				return "", rewriteErr
+			}
+			out.WriteString(line)
+		default:
+			rewritten, err := resolve(trimmed)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(rewritten)
+		}
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("scan hls playlist: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+func stripHLSSecretParameters(target *url.URL) *url.URL {
+	stripped := *target
+	query := stripped.Query()
+	for _, key := range hlsSecretQueryParameters {
+		query.Del(key)
+	}
+	stripped.RawQuery = query.Encode()
+	return &stripped
+}
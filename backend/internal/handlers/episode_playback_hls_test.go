@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeHLSMediaServer bildet die HLS-Endpunkte von Emby/Jellyfin nach und protokolliert die
+// empfangenen Anfragen.
+type fakeHLSMediaServer struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	master   string
+}
+
+func (s *fakeHLSMediaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	s.mu.Unlock()
+
+	if r.URL.Query().Get("api_key") != "media-key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/Videos/abc/master.m3u8":
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		_, _ = io.WriteString(w, s.master)
+	case "/Videos/abc/main.m3u8":
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		_, _ = io.WriteString(w, "#EXTM3U\r\n#EXT-X-TARGETDURATION:6\r\n#EXT-X-MAP:URI=\"hls1/main/init.mp4\"\r\n#EXTINF:6.0,\r\nhls1/main/0.ts?api_key=media-key\r\n#EXT-X-ENDLIST\r\n")
+	case "/Videos/abc/hls1/main/0.ts":
+		w.Header().Set("Content-Type", "video/mp2t")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = io.WriteString(w, "segment-bytes")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *fakeHLSMediaServer) lastRequest() *http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+const testHLSMaster = "#EXTM3U\n" +
+	"#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID=\"subs\",NAME=\"Deutsch\",URI=\"subs.m3u8?MediaSourceId=abc\"\n" +
+	"#EXT-X-STREAM-INF:BANDWIDTH=2000000,SUBTITLES=\"subs\"\n" +
+	"main.m3u8?MediaSourceId=abc&api_key=media-key\n"
+
+func newTestHLSRouter(t *testing.T, handler *EpisodePlaybackHandler, upstreamMaster string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Ersatz für PlayHLS ohne Datenbank: Autorisierung und Episodenauflösung sind dort
+	// identisch zu Play und separat getestet. Die Session kommt direkt aus dem session-Parameter.
+	router.GET("/api/v1/episodes/:id/play/hls", func(c *gin.Context) {
+		handler.proxyHLSPlaylist(c, 76, "user:2", c.Query("session"), upstreamMaster, time.Now().Add(time.Hour).Unix())
+	})
+	router.GET("/api/v1/episodes/:id/play/hls/resource", handler.PlayHLSResource)
+	return router
+}
+
+func newTestHLSHandler(client *http.Client) *EpisodePlaybackHandler {
+	return &EpisodePlaybackHandler{
+		embyAPIKey:         "media-key",
+		releaseGrantSecret: "grant-secret",
+		httpClient:         client,
+		hlsTTL:             time.Hour,
+	}
+}
+
+func serveTestHLS(router *gin.Engine, target string, header http.Header) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// playlistURIs liefert die URI-Zeilen einer Playlist.
+func playlistURIs(playlist string) []string {
+	uris := make([]string, 0)
+	for _, line := range strings.Split(playlist, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			uris = append(uris, line)
+		}
+	}
+	return uris
+}
+
+func TestPlayHLSRewritesPlaylistsAndProxiesSegments(t *testing.T) {
+	media := &fakeHLSMediaServer{master: testHLSMaster}
+	server := httptest.NewServer(media)
+	defer server.Close()
+
+	router := newTestHLSRouter(t, newTestHLSHandler(server.Client()), server.URL+"/Videos/abc/master.m3u8?api_key=media-key&MediaSourceId=abc")
+
+	master := serveTestHLS(router, "/api/v1/episodes/76/play/hls", nil)
+	if master.Code != http.StatusOK {
+		t.Fatalf("expected 200 for master, got %d: %s", master.Code, master.Body.String())
+	}
+	if got := master.Header().Get("Content-Type"); got != hlsPlaylistContentType {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	body := master.Body.String()
+	if strings.Contains(body, "media-key") || strings.Contains(body, server.Listener.Addr().String()) {
+		t.Fatalf("rewritten master leaks api key or media host: %s", body)
+	}
+	if !strings.Contains(body, `URI="/api/v1/episodes/76/play/hls/resource?token=`) {
+		t.Fatalf("expected subtitle uri attribute to be rewritten: %s", body)
+	}
+
+	variantURIs := playlistURIs(body)
+	if len(variantURIs) != 1 || !strings.HasPrefix(variantURIs[0], "/api/v1/episodes/76/play/hls/resource?token=") {
+		t.Fatalf("expected one rewritten variant uri, got %v", variantURIs)
+	}
+
+	variant := serveTestHLS(router, variantURIs[0], nil)
+	if variant.Code != http.StatusOK {
+		t.Fatalf("expected 200 for variant playlist, got %d: %s", variant.Code, variant.Body.String())
+	}
+	if !strings.Contains(variant.Body.String(), `#EXT-X-MAP:URI="/api/v1/episodes/76/play/hls/resource?token=`) {
+		t.Fatalf("expected init segment uri to be rewritten: %s", variant.Body.String())
+	}
+	segmentURIs := playlistURIs(variant.Body.String())
+	if len(segmentURIs) != 1 {
+		t.Fatalf("expected one segment uri, got %v", segmentURIs)
+	}
+
+	segment := serveTestHLS(router, segmentURIs[0], http.Header{"Range": {"bytes=0-"}})
+	if segment.Code != http.StatusPartialContent || segment.Body.String() != "segment-bytes" {
+		t.Fatalf("expected proxied segment, got %d %q", segment.Code, segment.Body.String())
+	}
+	upstream := media.lastRequest()
+	if upstream.URL.Path != "/Videos/abc/hls1/main/0.ts" || upstream.URL.Query().Get("api_key") != "media-key" {
+		t.Fatalf("unexpected upstream segment request %s", upstream.URL.String())
+	}
+	if upstream.Header.Get("Range") != "bytes=0-" {
+		t.Fatalf("expected range header to be forwarded, got %q", upstream.Header.Get("Range"))
+	}
+}
+
+func TestPlayHLSResourceRejectsInvalidTokens(t *testing.T) {
+	media := &fakeHLSMediaServer{master: testHLSMaster}
+	server := httptest.NewServer(media)
+	defer server.Close()
+
+	router := newTestHLSRouter(t, newTestHLSHandler(server.Client()), server.URL+"/Videos/abc/master.m3u8?api_key=media-key")
+	variantURI := playlistURIs(serveTestHLS(router, "/api/v1/episodes/76/play/hls", nil).Body.String())[0]
+
+	otherEpisode := strings.Replace(variantURI, "/episodes/76/", "/episodes/77/", 1)
+	if rec := serveTestHLS(router, otherEpisode, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for token of another episode, got %d", rec.Code)
+	}
+
+	parsed, _ := url.Parse(variantURI)
+	tampered := parsed.Path + "?token=" + url.QueryEscape(parsed.Query().Get("token")+"x")
+	if rec := serveTestHLS(router, tampered, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for tampered token, got %d", rec.Code)
+	}
+
+	if rec := serveTestHLS(router, "/api/v1/episodes/76/play/hls/resource", nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rec.Code)
+	}
+}
+
+func TestPlayHLSRejectsForeignHosts(t *testing.T) {
+	media := &fakeHLSMediaServer{master: "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1\nhttps://cdn.example/other.m3u8\n"}
+	server := httptest.NewServer(media)
+	defer server.Close()
+
+	router := newTestHLSRouter(t, newTestHLSHandler(server.Client()), server.URL+"/Videos/abc/master.m3u8?api_key=media-key")
+	if rec := serveTestHLS(router, "/api/v1/episodes/76/play/hls", nil); rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 for foreign host, got %d", rec.Code)
+	}
+}
+
+func TestPlayHLSSegmentsUseGuardrails(t *testing.T) {
+	media := &fakeHLSMediaServer{master: "#EXTM3U\n#EXTINF:6.0,\nhls1/main/0.ts\n"}
+	server := httptest.NewServer(media)
+	defer server.Close()
+
+	sessions := newFakePlaybackSessionStore(0, 0)
+	sessions.sessions["session-1"] = &models.PlaybackSession{ID: "session-1", UserID: 2, EpisodeID: 76}
+	handler := newTestHLSHandler(server.Client()).WithPlaybackSessions(sessions)
+	playCounts := &fakeEpisodePlaybackRateLimitStore{counts: map[string]int64{}}
+	handler.playbackRateLimiter = &episodePlaybackRateLimiter{
+		store:   playCounts,
+		limit:   1,
+		window:  time.Minute,
+		nowFunc: time.Now,
+		prefix:  "episode_playback_rate_limit",
+	}
+	handler.hlsRateLimiter = &episodePlaybackRateLimiter{
+		store:   &fakeEpisodePlaybackRateLimitStore{counts: map[string]int64{}},
+		limit:   2,
+		window:  time.Minute,
+		nowFunc: time.Now,
+		prefix:  "episode_playback_rate_limit",
+	}
+	router := newTestHLSRouter(t, handler, server.URL+"/Videos/abc/master.m3u8?api_key=media-key")
+	segmentURI := playlistURIs(serveTestHLS(router, "/api/v1/episodes/76/play/hls?session=session-1", nil).Body.String())[0]
+
+	if rec := serveTestHLS(router, segmentURI, nil); rec.Code != http.StatusPartialContent {
+		t.Fatalf("expected segment within live session, got %d", rec.Code)
+	}
+	if len(sessions.touched) != 1 || sessions.touched[0] != "session-1" {
+		t.Fatalf("expected segment request to keep the session alive, got %v", sessions.touched)
+	}
+
+	delete(sessions.sessions, "session-1")
+	if rec := serveTestHLS(router, segmentURI, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after the session ended, got %d", rec.Code)
+	}
+
+	if rec := serveTestHLS(router, segmentURI, nil); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected segment requests to share the hls session budget, got %d", rec.Code)
+	}
+	for key, count := range playCounts.counts {
+		if count > 1 {
+			t.Fatalf("expected segments not to consume the play budget, got %s=%d", key, count)
+		}
+	}
+}
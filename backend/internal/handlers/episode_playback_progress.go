@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// watchProgressCompletedRatio ist der Anteil der Laufzeit, ab dem eine Episode als gesehen gilt
+// (Abspann/Preview am Ende werden häufig übersprungen).
+const watchProgressCompletedRatio = 0.9
+
+type playbackProgressRequest struct {
+	ReleaseVersionID *int64 `json:"release_version_id"`
+	PositionSeconds  *int32 `json:"position_seconds"`
+	DurationSeconds  *int32 `json:"duration_seconds"`
+	Completed        bool   `json:"completed"`
+}
+
+// WithWatchProgressRepo aktiviert die Fortschritts-Endpunkte des Players.
+func (h *EpisodePlaybackHandler) WithWatchProgressRepo(repo *repository.WatchProgressRepository) *EpisodePlaybackHandler {
+	h.progressRepo = repo
+	return h
+}
+
+// ReportPlaybackProgress verarbeitet PUT /api/v1/episodes/:id/progress (Player-Heartbeat).
+func (h *EpisodePlaybackHandler) ReportPlaybackProgress(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+	if h.progressRepo == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "wiedergabefortschritt derzeit nicht verfügbar"}})
+		return
+	}
+
+	episodeID, err := parseEpisodeID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige episode id")
+		return
+	}
+
+	var req playbackProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+
+	input, message := validatePlaybackProgressRequest(req)
+	if message != "" {
+		badRequest(c, message)
+		return
+	}
+	input.AppUserID = identity.AppUserID
+	input.EpisodeID = episodeID
+
+	item, err := h.progressRepo.UpsertProgress(c.Request.Context(), input)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "episode nicht gefunden")
+		return
+	}
+	if errors.Is(err, repository.ErrValidation) {
+		badRequest(c, "release_version_id gehört nicht zur episode")
+		return
+	}
+	if err != nil {
+		log.Printf("episode_playback: save progress failed (episode_id=%d, app_user_id=%d): %v", episodeID, identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// GetPlaybackProgress verarbeitet GET /api/v1/episodes/:id/progress und liefert die Resume-Positionen
+// des angemeldeten Users (eine je Release-Version, zuletzt gesehene zuerst).
+func (h *EpisodePlaybackHandler) GetPlaybackProgress(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+	if h.progressRepo == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "wiedergabefortschritt derzeit nicht verfügbar"}})
+		return
+	}
+
+	episodeID, err := parseEpisodeID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige episode id")
+		return
+	}
+
+	items, err := h.progressRepo.ListByEpisode(c.Request.Context(), identity.AppUserID, episodeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "episode nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("episode_playback: load progress failed (episode_id=%d, app_user_id=%d): %v", episodeID, identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// validatePlaybackProgressRequest prüft einen Heartbeat und leitet das Completed-Flag ab.
+// Positionen hinter der gemeldeten Laufzeit werden auf die Laufzeit begrenzt.
+func validatePlaybackProgressRequest(req playbackProgressRequest) (models.EpisodeWatchProgressInput, string) {
+	if req.PositionSeconds == nil {
+		return models.EpisodeWatchProgressInput{}, "position_seconds ist erforderlich"
+	}
+	if *req.PositionSeconds < 0 {
+		return models.EpisodeWatchProgressInput{}, "position_seconds darf nicht negativ sein"
+	}
+	if req.DurationSeconds != nil && *req.DurationSeconds <= 0 {
+		return models.EpisodeWatchProgressInput{}, "duration_seconds muss positiv sein"
+	}
+	if req.ReleaseVersionID != nil && *req.ReleaseVersionID <= 0 {
+		return models.EpisodeWatchProgressInput{}, "ungültige release_version_id"
+	}
+
+	position := *req.PositionSeconds
+	if req.DurationSeconds != nil && position > *req.DurationSeconds {
+		position = *req.DurationSeconds
+	}
+
+	completed := req.Completed
+	if req.DurationSeconds != nil && float64(position) >= float64(*req.DurationSeconds)*watchProgressCompletedRatio {
+		completed = true
+	}
+
+	return models.EpisodeWatchProgressInput{
+		ReleaseVersionID: req.ReleaseVersionID,
+		PositionSeconds:  position,
+		DurationSeconds:  req.DurationSeconds,
+		Completed:        completed,
+	}, ""
+}
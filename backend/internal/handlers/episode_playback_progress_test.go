@@ -0,0 +1,78 @@
+package handlers
+
+import "testing"
+
+func TestValidatePlaybackProgressRequest(t *testing.T) {
+	releaseVersionID := int64(7)
+	invalidReleaseVersionID := int64(0)
+
+	tests := []struct {
+		name          string
+		req           playbackProgressRequest
+		wantMessage   string
+		wantPosition  int32
+		wantCompleted bool
+	}{
+		{
+			name:         "heartbeat mid episode",
+			req:          playbackProgressRequest{PositionSeconds: int32Ptr(300), DurationSeconds: int32Ptr(1440), ReleaseVersionID: &releaseVersionID},
+			wantPosition: 300,
+		},
+		{
+			name:          "near the end counts as completed",
+			req:           playbackProgressRequest{PositionSeconds: int32Ptr(1300), DurationSeconds: int32Ptr(1440)},
+			wantPosition:  1300,
+			wantCompleted: true,
+		},
+		{
+			name:          "position is clamped to duration",
+			req:           playbackProgressRequest{PositionSeconds: int32Ptr(1500), DurationSeconds: int32Ptr(1440)},
+			wantPosition:  1440,
+			wantCompleted: true,
+		},
+		{
+			name:          "explicit completed without duration",
+			req:           playbackProgressRequest{PositionSeconds: int32Ptr(0), Completed: true},
+			wantCompleted: true,
+		},
+		{
+			name:        "missing position",
+			req:         playbackProgressRequest{DurationSeconds: int32Ptr(1440)},
+			wantMessage: "position_seconds ist erforderlich",
+		},
+		{
+			name:        "negative position",
+			req:         playbackProgressRequest{PositionSeconds: int32Ptr(-1)},
+			wantMessage: "position_seconds darf nicht negativ sein",
+		},
+		{
+			name:        "zero duration",
+			req:         playbackProgressRequest{PositionSeconds: int32Ptr(10), DurationSeconds: int32Ptr(0)},
+			wantMessage: "duration_seconds muss positiv sein",
+		},
+		{
+			name:        "invalid release version",
+			req:         playbackProgressRequest{PositionSeconds: int32Ptr(10), ReleaseVersionID: &invalidReleaseVersionID},
+			wantMessage: "ungültige release_version_id",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			input, message := validatePlaybackProgressRequest(tc.req)
+			if message != tc.wantMessage {
+				t.Fatalf("expected message %q, got %q", tc.wantMessage, message)
+			}
+			if message != "" {
+				return
+			}
+			if input.PositionSeconds != tc.wantPosition {
+				t.Fatalf("expected position %d, got %d", tc.wantPosition, input.PositionSeconds)
+			}
+			if input.Completed != tc.wantCompleted {
+				t.Fatalf("expected completed=%v, got %v", tc.wantCompleted, input.Completed)
+			}
+		})
+	}
+}
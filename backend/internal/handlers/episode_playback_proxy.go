@@ -4,40 +4,75 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"path"
 	"strings"
+
+	"team4s.v3/backend/internal/mediaserver"
 )
 
-func (h *EpisodePlaybackHandler) buildEmbyStreamURL(sourceURL string) (string, error) {
-	parsedSource, err := url.Parse(strings.TrimSpace(sourceURL))
-	if err != nil {
-		return "", fmt.Errorf("parse source url: %w", err)
+// WithMediaServer setzt den Media-Server für Play und PlayHLS (MEDIA_SERVER_PLAYBACK). Ohne
+// Aufruf wird Emby aus EMBY_* genutzt; fehlt EMBY_STREAM_BASE_URL, gilt der Host der Stream-Quelle.
+func (h *EpisodePlaybackHandler) WithMediaServer(provider mediaserver.MediaServerProvider) *EpisodePlaybackHandler {
+	h.mediaServer = provider
+	return h
+}
+
+// playbackConfigured meldet, ob Streams über den Media-Server ausgeliefert werden können.
+func (h *EpisodePlaybackHandler) playbackConfigured() bool {
+	if h.mediaServer != nil {
+		return h.mediaServer.Configured()
 	}
+	return strings.TrimSpace(h.embyAPIKey) != ""
+}
 
-	itemID, err := extractEmbyItemID(parsedSource)
-	if err != nil {
-		return "", err
+// playbackMediaServer liefert den Media-Server, der die Stream-Quelle ausliefert.
+func (h *EpisodePlaybackHandler) playbackMediaServer(source *url.URL) mediaserver.MediaServerProvider {
+	if h.mediaServer != nil {
+		return h.mediaServer
 	}
 
 	baseURL := strings.TrimSpace(h.embyStreamBaseURL)
-	if baseURL == "" {
-		baseURL = parsedSource.Scheme + "://" + parsedSource.Host
+	if baseURL == "" && source != nil {
+		baseURL = source.Scheme + "://" + source.Host
 	}
+	return mediaserver.NewEmby(mediaserver.Config{
+		BaseURL:            baseURL,
+		APIKey:             h.embyAPIKey,
+		StreamPathTemplate: h.embyStreamPathTemplate,
+		HLSPathTemplate:    h.embyHLSPathTemplate,
+		HTTPClient:         h.httpClient,
+	})
+}
 
-	parsedBase, err := url.Parse(baseURL)
+func (h *EpisodePlaybackHandler) buildEmbyStreamURL(sourceURL string) (string, error) {
+	parsedSource, itemID, err := parsePlaybackSource(sourceURL)
 	if err != nil {
-		return "", fmt.Errorf("parse emby base url: %w", err)
+		return "", err
 	}
+	return h.playbackMediaServer(parsedSource).StreamURL(itemID)
+}
 
-	streamPath := fmt.Sprintf(h.embyStreamPathTemplate, itemID)
-	parsedBase.Path = path.Clean("/" + strings.TrimPrefix(streamPath, "/"))
+// buildEmbyHLSURL liefert die Master-Playlist des Items; MediaSourceId entspricht bei
+// Emby und Jellyfin für einfache Items der Item-ID.
+func (h *EpisodePlaybackHandler) buildEmbyHLSURL(sourceURL string) (string, error) {
+	parsedSource, itemID, err := parsePlaybackSource(sourceURL)
+	if err != nil {
+		return "", err
+	}
+	return h.playbackMediaServer(parsedSource).HLSURL(itemID)
+}
 
-	query := parsedBase.Query()
-	query.Set("api_key", h.embyAPIKey)
-	query.Set("static", "true")
-	parsedBase.RawQuery = query.Encode()
+// parsePlaybackSource zerlegt den gespeicherten Stream-Link und liefert die Item-ID.
+func parsePlaybackSource(sourceURL string) (*url.URL, string, error) {
+	parsedSource, err := url.Parse(strings.TrimSpace(sourceURL))
+	if err != nil {
+		return nil, "", fmt.Errorf("parse source url: %w", err)
+	}
 
-	return parsedBase.String(), nil
+	itemID, err := extractEmbyItemID(parsedSource)
+	if err != nil {
+		return nil, "", err
+	}
+	return parsedSource, itemID, nil
 }
 
 func extractEmbyItemID(sourceURL *url.URL) (string, error) {
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	playbackSessionQueryParameter          = "session"
+	playbackSessionUserLimitMessage        = "zu viele gleichzeitige streams"
+	playbackSessionUnavailableMessage      = "stream-sessions vorübergehend nicht verfügbar"
+	playbackSessionEndedMessage            = "playback-session beendet"
+	playbackSessionHeartbeatsPerTimeout    = 3
+	playbackSessionMinHeartbeatIntervalSec = 1
+)
+
+// playbackSessionStore verwaltet clusterweite Playback-Sessions (implementiert von
+// repository.PlaybackSessionRepository).
+type playbackSessionStore interface {
+	Open(ctx context.Context, input models.PlaybackSessionInput) (*models.PlaybackSession, error)
+	Get(ctx context.Context, sessionID string) (*models.PlaybackSession, error)
+	Touch(ctx context.Context, sessionID string) (*models.PlaybackSession, error)
+	Terminate(ctx context.Context, sessionID string) (*models.PlaybackSession, error)
+	Timeout() time.Duration
+}
+
+// playbackAccess beschreibt den autorisierten Zuschauer einer Wiedergabeanfrage.
+type playbackAccess struct {
+	principal string
+	userID    int64
+	sessionID string // aus dem Grant bzw. dem session-Parameter; leer, wenn keine Session angegeben ist
+}
+
+// WithPlaybackSessions aktiviert Playback-Sessions: Grants und Stream-Abrufe laufen dann nur
+// innerhalb einer Session, deren Limits über alle Instanzen gelten.
+func (h *EpisodePlaybackHandler) WithPlaybackSessions(store playbackSessionStore) *EpisodePlaybackHandler {
+	h.sessions = store
+	return h
+}
+
+// heartbeatIntervalSeconds ist das empfohlene Heartbeat-Intervall für Clients.
+func (h *EpisodePlaybackHandler) heartbeatIntervalSeconds() int64 {
+	interval := int64(h.sessions.Timeout()/time.Second) / playbackSessionHeartbeatsPerTimeout
+	if interval < playbackSessionMinHeartbeatIntervalSec {
+		interval = playbackSessionMinHeartbeatIntervalSec
+	}
+	return interval
+}
+
+// openPlaybackSession legt für userID und episodeID eine Session an oder verlängert die laufende.
+// Bei erreichtem Nutzerlimit antwortet sie mit 429, bei erreichtem globalem Limit mit 503.
+// Ohne konfigurierten Store liefert sie (nil, true).
+func (h *EpisodePlaybackHandler) openPlaybackSession(c *gin.Context, userID int64, episodeID int64) (*models.PlaybackSession, bool) {
+	if h.sessions == nil {
+		return nil, true
+	}
+
+	clientIP := extractClientIP(c)
+	session, err := h.sessions.Open(c.Request.Context(), models.PlaybackSessionInput{
+		UserID:    userID,
+		EpisodeID: episodeID,
+		ClientIP:  clientIP,
+		UserAgent: c.GetHeader("User-Agent"),
+	})
+	switch {
+	case err == nil:
+		return session, true
+	case errors.Is(err, repository.ErrPlaybackSessionUserLimit):
+		if h.auditLogger != nil {
+			h.auditLogger.logSessionLimitReached(c.Request.Context(), "user", userID, episodeID, clientIP)
+		}
+		c.Header("Retry-After", strconv.FormatInt(h.heartbeatIntervalSeconds(), 10))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": gin.H{
+				"message": playbackSessionUserLimitMessage,
+			},
+		})
+		return nil, false
+	case errors.Is(err, repository.ErrPlaybackSessionGlobalLimit):
+		if h.auditLogger != nil {
+			h.auditLogger.logSessionLimitReached(c.Request.Context(), "global", userID, episodeID, clientIP)
+		}
+		c.Header("Retry-After", strconv.FormatInt(h.heartbeatIntervalSeconds(), 10))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"message": episodePlaybackOverloadedMessage,
+			},
+		})
+		return nil, false
+	default:
+		log.Printf("episode_playback: open session failed (episode_id=%d, user_id=%d): %v", episodeID, userID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"message": playbackSessionUnavailableMessage,
+			},
+		})
+		return nil, false
+	}
+}
+
+// touchPlaybackSession hält eine Session bei Stream-Abrufen am Leben. Abgelaufene oder beendete
+// Sessions werden mit 401 abgewiesen.
+func (h *EpisodePlaybackHandler) touchPlaybackSession(c *gin.Context, sessionID string) (*models.PlaybackSession, bool) {
+	session, err := h.sessions.Touch(c.Request.Context(), sessionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"message": playbackSessionEndedMessage,
+			},
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("episode_playback: touch session failed (session_id=%s): %v", sessionID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"message": playbackSessionUnavailableMessage,
+			},
+		})
+		return nil, false
+	}
+	return session, true
+}
+
+// attachPlaybackSession ordnet einen Stream-Abruf einer Session zu: eine im Grant bzw. Parameter
+// genannte Session muss noch laufen und dem Zuschauer gehören, sonst wird für Nutzer und
+// Episode eine Session geöffnet, sofern seine Grants nicht gesperrt sind. Ohne konfigurierten
+// Store liefert sie (nil, true).
+func (h *EpisodePlaybackHandler) attachPlaybackSession(c *gin.Context, episodeID int64, access playbackAccess) (*models.PlaybackSession, bool) {
+	if access.sessionID == "" && !h.enforcePlaybackGrantBlock(c, access.userID) {
+		return nil, false
+	}
+	if h.sessions == nil {
+		return nil, true
+	}
+	if access.sessionID == "" {
+		return h.openPlaybackSession(c, access.userID, episodeID)
+	}
+
+	session, ok := h.touchPlaybackSession(c, access.sessionID)
+	if !ok {
+		return nil, false
+	}
+	if session.UserID != access.userID || session.EpisodeID != episodeID {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"message": playbackSessionEndedMessage,
+			},
+		})
+		return nil, false
+	}
+	return session, true
+}
+
+// HeartbeatPlaybackSession verarbeitet POST /api/v1/playback-sessions/:sessionId/heartbeat und
+// verlängert eine eigene Session, etwa während der Player pausiert.
+func (h *EpisodePlaybackHandler) HeartbeatPlaybackSession(c *gin.Context) {
+	session, ok := h.loadOwnPlaybackSession(c)
+	if !ok {
+		return
+	}
+
+	session, ok = h.touchPlaybackSession(c, session.ID)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"session":                    session,
+			"heartbeat_interval_seconds": h.heartbeatIntervalSeconds(),
+		},
+	})
+}
+
+// EndPlaybackSession verarbeitet DELETE /api/v1/playback-sessions/:sessionId und gibt den
+// Concurrency-Slot sofort frei.
+func (h *EpisodePlaybackHandler) EndPlaybackSession(c *gin.Context) {
+	session, ok := h.loadOwnPlaybackSession(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.sessions.Terminate(c.Request.Context(), session.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Printf("episode_playback: end session failed (session_id=%s): %v", session.ID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"message": playbackSessionUnavailableMessage,
+			},
+		})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadOwnPlaybackSession lädt die Session aus dem Pfad und stellt sicher, dass sie dem
+// angemeldeten Nutzer gehört. Fremde Sessions werden wie fehlende behandelt.
+func (h *EpisodePlaybackHandler) loadOwnPlaybackSession(c *gin.Context) (*models.PlaybackSession, bool) {
+	identity, ok := middleware.CommentAuthIdentityFromContext(c)
+	if !ok || identity.UserID <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"message": "anmeldung erforderlich",
+			},
+		})
+		return nil, false
+	}
+	if h.sessions == nil {
+		notFound(c, "playback-session nicht gefunden")
+		return nil, false
+	}
+
+	session, err := h.sessions.Get(c.Request.Context(), strings.TrimSpace(c.Param("sessionId")))
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && session.UserID != identity.UserID) {
+		notFound(c, "playback-session nicht gefunden")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("episode_playback: load session failed (user_id=%d): %v", identity.UserID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"message": playbackSessionUnavailableMessage,
+			},
+		})
+		return nil, false
+	}
+	return session, true
+}
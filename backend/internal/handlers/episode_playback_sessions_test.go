@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakePlaybackSessionStore bildet repository.PlaybackSessionRepository im Speicher nach.
+type fakePlaybackSessionStore struct {
+	sessions   map[string]*models.PlaybackSession
+	maxPerUser int
+	maxGlobal  int
+	touched    []string
+	nextID     int
+}
+
+func newFakePlaybackSessionStore(maxPerUser int, maxGlobal int) *fakePlaybackSessionStore {
+	return &fakePlaybackSessionStore{
+		sessions:   map[string]*models.PlaybackSession{},
+		maxPerUser: maxPerUser,
+		maxGlobal:  maxGlobal,
+	}
+}
+
+func (s *fakePlaybackSessionStore) Open(_ context.Context, input models.PlaybackSessionInput) (*models.PlaybackSession, error) {
+	perUser := 0
+	for _, session := range s.sessions {
+		if session.UserID != input.UserID {
+			continue
+		}
+		if session.EpisodeID == input.EpisodeID {
+			return session, nil
+		}
+		perUser++
+	}
+	if s.maxPerUser > 0 && perUser >= s.maxPerUser {
+		return nil, repository.ErrPlaybackSessionUserLimit
+	}
+	if s.maxGlobal > 0 && len(s.sessions) >= s.maxGlobal {
+		return nil, repository.ErrPlaybackSessionGlobalLimit
+	}
+
+	s.nextID++
+	session := &models.PlaybackSession{
+		ID:        fmt.Sprintf("session-%d", s.nextID),
+		UserID:    input.UserID,
+		EpisodeID: input.EpisodeID,
+		ClientIP:  input.ClientIP,
+		ExpiresAt: time.Now().Add(s.Timeout()),
+	}
+	s.sessions[session.ID] = session
+	return session, nil
+}
+
+func (s *fakePlaybackSessionStore) Get(_ context.Context, sessionID string) (*models.PlaybackSession, error) {
+	if session, ok := s.sessions[sessionID]; ok {
+		return session, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (s *fakePlaybackSessionStore) Touch(ctx context.Context, sessionID string) (*models.PlaybackSession, error) {
+	session, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	s.touched = append(s.touched, sessionID)
+	return session, nil
+}
+
+func (s *fakePlaybackSessionStore) Terminate(ctx context.Context, sessionID string) (*models.PlaybackSession, error) {
+	session, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.sessions, sessionID)
+	return session, nil
+}
+
+func (s *fakePlaybackSessionStore) List(_ context.Context, userID int64) ([]models.PlaybackSession, error) {
+	items := make([]models.PlaybackSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if userID <= 0 || session.UserID == userID {
+			items = append(items, *session)
+		}
+	}
+	return items, nil
+}
+
+func (s *fakePlaybackSessionStore) Timeout() time.Duration {
+	return 90 * time.Second
+}
+
+func newPlaybackSessionTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request = httptest.NewRequest(http.MethodPost, "/api/v1/episodes/76/play/grant", nil)
+	return context, recorder
+}
+
+func TestOpenPlaybackSessionEnforcesLimits(t *testing.T) {
+	store := newFakePlaybackSessionStore(1, 2)
+	handler := (&EpisodePlaybackHandler{}).WithPlaybackSessions(store)
+
+	context, _ := newPlaybackSessionTestContext()
+	first, ok := handler.openPlaybackSession(context, 1, 76)
+	if !ok {
+		t.Fatalf("expected first session to open")
+	}
+	context, _ = newPlaybackSessionTestContext()
+	if again, ok := handler.openPlaybackSession(context, 1, 76); !ok || again.ID != first.ID {
+		t.Fatalf("expected same episode to reuse the session")
+	}
+
+	context, recorder := newPlaybackSessionTestContext()
+	if _, ok := handler.openPlaybackSession(context, 1, 77); ok {
+		t.Fatalf("expected per-user limit")
+	}
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", recorder.Code)
+	}
+	if message := decodeErrorMessage(t, recorder.Body.Bytes()); message != playbackSessionUserLimitMessage {
+		t.Fatalf("unexpected message: %q", message)
+	}
+
+	context, _ = newPlaybackSessionTestContext()
+	if _, ok := handler.openPlaybackSession(context, 2, 77); !ok {
+		t.Fatalf("expected second user to open a session")
+	}
+	context, recorder = newPlaybackSessionTestContext()
+	if _, ok := handler.openPlaybackSession(context, 3, 77); ok {
+		t.Fatalf("expected global limit")
+	}
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Retry-After"); got != "30" {
+		t.Fatalf("expected Retry-After of one heartbeat interval, got %q", got)
+	}
+	if message := decodeErrorMessage(t, recorder.Body.Bytes()); message != episodePlaybackOverloadedMessage {
+		t.Fatalf("unexpected message: %q", message)
+	}
+}
+
+func TestAttachPlaybackSessionRejectsForeignSession(t *testing.T) {
+	store := newFakePlaybackSessionStore(0, 0)
+	store.sessions["session-9"] = &models.PlaybackSession{ID: "session-9", UserID: 9, EpisodeID: 76}
+	handler := (&EpisodePlaybackHandler{}).WithPlaybackSessions(store)
+
+	context, recorder := newPlaybackSessionTestContext()
+	access := playbackAccess{principal: "user:2", userID: 2, sessionID: "session-9"}
+	if _, ok := handler.attachPlaybackSession(context, 76, access); ok {
+		t.Fatalf("expected foreign session to be rejected")
+	}
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", recorder.Code)
+	}
+
+	context, _ = newPlaybackSessionTestContext()
+	access = playbackAccess{principal: "user:9", userID: 9, sessionID: "session-9"}
+	if _, ok := handler.attachPlaybackSession(context, 76, access); !ok {
+		t.Fatalf("expected own session to be accepted")
+	}
+	if len(store.touched) != 2 || store.touched[1] != "session-9" {
+		t.Fatalf("expected stream request to touch the session, got %v", store.touched)
+	}
+}
+
+func TestPlaybackSessionHeartbeatAndEnd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newFakePlaybackSessionStore(0, 0)
+	store.sessions["session-1"] = &models.PlaybackSession{ID: "session-1", UserID: 2, EpisodeID: 76}
+	store.sessions["session-9"] = &models.PlaybackSession{ID: "session-9", UserID: 9, EpisodeID: 76}
+	handler := (&EpisodePlaybackHandler{}).WithPlaybackSessions(store)
+
+	router := gin.New()
+	withUser := func(c *gin.Context) {
+		c.Set("auth_identity", middleware.AuthIdentity{UserID: 2, DisplayName: "Viewer"})
+		c.Next()
+	}
+	router.POST("/api/v1/playback-sessions/:sessionId/heartbeat", withUser, handler.HeartbeatPlaybackSession)
+	router.DELETE("/api/v1/playback-sessions/:sessionId", withUser, handler.EndPlaybackSession)
+	serve := func(method string, target string) int {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		return recorder.Code
+	}
+
+	if code := serve(http.MethodPost, "/api/v1/playback-sessions/session-9/heartbeat"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for foreign session heartbeat, got %d", code)
+	}
+	if code := serve(http.MethodPost, "/api/v1/playback-sessions/session-1/heartbeat"); code != http.StatusOK {
+		t.Fatalf("expected heartbeat 200, got %d", code)
+	}
+	if len(store.touched) != 1 || store.touched[0] != "session-1" {
+		t.Fatalf("expected heartbeat to touch session-1, got %v", store.touched)
+	}
+	if code := serve(http.MethodDelete, "/api/v1/playback-sessions/session-1"); code != http.StatusNoContent {
+		t.Fatalf("expected 204 on end, got %d", code)
+	}
+	if _, ok := store.sessions["session-1"]; ok {
+		t.Fatalf("expected session-1 to be terminated")
+	}
+	if code := serve(http.MethodDelete, "/api/v1/playback-sessions/session-9"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 when ending a foreign session, got %d", code)
+	}
+}
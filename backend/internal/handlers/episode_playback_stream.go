@@ -4,7 +4,6 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,11 +16,14 @@ func (h *EpisodePlaybackHandler) Play(c *gin.Context) {
 		return
 	}
 
-	principal, ok := h.authorizePlayback(c, episodeID)
+	access, ok := h.authorizePlayback(c, episodeID)
 	if !ok {
 		return
 	}
-	if !h.enforcePlaybackRateLimit(c, "play", principal) {
+	if !h.enforcePlaybackRateLimit(c, "play", access.principal) {
+		return
+	}
+	if _, ok := h.attachPlaybackSession(c, episodeID, access); !ok {
 		return
 	}
 
@@ -31,7 +33,7 @@ func (h *EpisodePlaybackHandler) Play(c *gin.Context) {
 	}
 	defer releaseSlot()
 
-	if strings.TrimSpace(h.embyAPIKey) == "" {
+	if !h.playbackConfigured() {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": gin.H{
 				"message": "stream derzeit nicht verfügbar",
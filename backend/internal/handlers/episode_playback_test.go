@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"team4s.v3/backend/internal/auth"
+	"team4s.v3/backend/internal/mediaserver/mediaservertest"
 
 	"github.com/gin-gonic/gin"
 )
@@ -108,12 +109,12 @@ func TestAuthorizePlayback_ValidGrant(t *testing.T) {
 		releaseGrantSecret: "test-secret",
 	}
 
-	principal, ok := handler.authorizePlayback(context, 76)
+	access, ok := handler.authorizePlayback(context, 76)
 	if !ok {
 		t.Fatalf("expected valid grant authorization")
 	}
-	if principal != "user:2" {
-		t.Fatalf("expected principal user:2, got %q", principal)
+	if access.principal != "user:2" || access.userID != 2 {
+		t.Fatalf("expected principal user:2, got %+v", access)
 	}
 }
 
@@ -344,3 +345,37 @@ func TestAcquirePlaybackSlotOverloaded(t *testing.T) {
 		t.Fatalf("unexpected message: %q", message)
 	}
 }
+
+func TestBuildEmbyStreamURL_FallsBackToSourceHost(t *testing.T) {
+	handler := &EpisodePlaybackHandler{
+		embyAPIKey:             "media-key",
+		embyStreamPathTemplate: "/Videos/%s/stream",
+	}
+
+	targetURL, err := handler.buildEmbyStreamURL("https://anime.team4s.de/web/index.html#!/item?id=6424")
+	if err != nil {
+		t.Fatalf("build stream url: %v", err)
+	}
+	if targetURL != "https://anime.team4s.de/Videos/6424/stream?api_key=media-key&static=true" {
+		t.Fatalf("unexpected stream url %q", targetURL)
+	}
+}
+
+func TestBuildEmbyURLs_UseConfiguredMediaServer(t *testing.T) {
+	provider := mediaservertest.NewProvider()
+	handler := (&EpisodePlaybackHandler{embyAPIKey: "unused"}).WithMediaServer(provider)
+
+	streamURL, err := handler.buildEmbyStreamURL("https://anime.team4s.de/web/index.html#!/item?id=6424")
+	if err != nil || streamURL != "http://media.test/Videos/6424/stream?api_key=test-key&static=true" {
+		t.Fatalf("unexpected stream url %q, %v", streamURL, err)
+	}
+	hlsURL, err := handler.buildEmbyHLSURL("https://anime.team4s.de/Videos/6424/stream")
+	if err != nil || hlsURL != "http://media.test/Videos/6424/master.m3u8?MediaSourceId=6424&api_key=test-key" {
+		t.Fatalf("unexpected hls url %q, %v", hlsURL, err)
+	}
+
+	provider.APIKey = ""
+	if handler.playbackConfigured() {
+		t.Fatalf("expected playback to follow the media server configuration")
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// episodeSkipMarkerStore liefert die Segmente einer Episodenversion (implementiert von
+// repository.EpisodeSkipMarkerRepository).
+type episodeSkipMarkerStore interface {
+	GetEpisodeSkipMarkerSource(ctx context.Context, versionID int64) (*models.EpisodeSkipMarkerSource, error)
+}
+
+// EpisodeSkipMarkersHandler exportiert die gepflegten OP/ED-Segmente einer Episodenversion als
+// Skip-Marker, WebVTT-Kapitel und Jellyfin-MediaSegments.
+type EpisodeSkipMarkersHandler struct {
+	repo episodeSkipMarkerStore
+}
+
+func NewEpisodeSkipMarkersHandler(repo episodeSkipMarkerStore) *EpisodeSkipMarkersHandler {
+	return &EpisodeSkipMarkersHandler{repo: repo}
+}
+
+// GetSkipMarkers verarbeitet GET /api/v1/episode-versions/:versionId/skip-markers.
+func (h *EpisodeSkipMarkersHandler) GetSkipMarkers(c *gin.Context) {
+	source, markers, ok := h.loadMarkers(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"data": models.EpisodeSkipMarkersData{
+		ReleaseVariantID: source.ReleaseVariantID,
+		ReleaseVersionID: source.ReleaseVersionID,
+		DurationSeconds:  source.DurationSeconds,
+		Markers:          markers,
+	}})
+}
+
+// GetChaptersVTT verarbeitet GET /api/v1/episode-versions/:versionId/chapters.vtt.
+func (h *EpisodeSkipMarkersHandler) GetChaptersVTT(c *gin.Context) {
+	source, markers, ok := h.loadMarkers(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "text/vtt; charset=utf-8", []byte(services.BuildSkipChapterVTT(markers, source.DurationSeconds)))
+}
+
+// GetMediaSegments verarbeitet GET /api/v1/episode-versions/:versionId/media-segments. Die Antwort
+// folgt dem QueryResult von Jellyfins GET /MediaSegments/{itemId} und ist daher nicht in "data"
+// verpackt.
+func (h *EpisodeSkipMarkersHandler) GetMediaSegments(c *gin.Context) {
+	source, markers, ok := h.loadMarkers(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, services.BuildJellyfinMediaSegments(markers, source.ReleaseVariantID, source.JellyfinItemID))
+}
+
+func (h *EpisodeSkipMarkersHandler) loadMarkers(c *gin.Context) (*models.EpisodeSkipMarkerSource, []models.EpisodeSkipMarker, bool) {
+	versionID, err := parseEpisodeVersionID(c.Param("versionId"))
+	if err != nil {
+		badRequest(c, "ungültige version id")
+		return nil, nil, false
+	}
+
+	source, err := h.repo.GetEpisodeSkipMarkerSource(c.Request.Context(), versionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "episodenversion nicht gefunden")
+		return nil, nil, false
+	}
+	if err != nil {
+		log.Printf("episode skip markers: repo error (version_id=%d): %v", versionID, err)
+		internalError(c, "interner serverfehler")
+		return nil, nil, false
+	}
+
+	return source, services.ResolveEpisodeSkipMarkers(*source), true
+}
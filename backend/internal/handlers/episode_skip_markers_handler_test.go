@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeEpisodeSkipMarkerStore struct {
+	sources map[int64]*models.EpisodeSkipMarkerSource
+}
+
+func (s fakeEpisodeSkipMarkerStore) GetEpisodeSkipMarkerSource(_ context.Context, versionID int64) (*models.EpisodeSkipMarkerSource, error) {
+	if source, ok := s.sources[versionID]; ok {
+		return source, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestEpisodeSkipMarkersEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	itemID := "jf-item"
+	handler := NewEpisodeSkipMarkersHandler(fakeEpisodeSkipMarkerStore{sources: map[int64]*models.EpisodeSkipMarkerSource{
+		5: {
+			ReleaseVariantID: 11,
+			ReleaseVersionID: 5,
+			DurationSeconds:  int32Ptr(1440),
+			JellyfinItemID:   &itemID,
+			Segments: []models.EpisodeSkipMarkerSegment{
+				{SegmentID: 1, ThemeTypeName: "OP Kara", StartSeconds: int32Ptr(90), EndSeconds: int32Ptr(180)},
+				{SegmentID: 2, ThemeTypeName: "ED Kara", StartSeconds: int32Ptr(1300), EndSeconds: int32Ptr(1390)},
+			},
+		},
+	}})
+
+	router := gin.New()
+	router.GET("/api/v1/episode-versions/:versionId/skip-markers", handler.GetSkipMarkers)
+	router.GET("/api/v1/episode-versions/:versionId/chapters.vtt", handler.GetChaptersVTT)
+	router.GET("/api/v1/episode-versions/:versionId/media-segments", handler.GetMediaSegments)
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := serve("/api/v1/episode-versions/5/skip-markers")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var markers struct {
+		Data models.EpisodeSkipMarkersData `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &markers); err != nil {
+		t.Fatalf("decode markers: %v", err)
+	}
+	if markers.Data.ReleaseVariantID != 11 || len(markers.Data.Markers) != 2 || markers.Data.Markers[0].Kind != models.SkipMarkerKindIntro {
+		t.Fatalf("unexpected markers: %+v", markers.Data)
+	}
+
+	rec = serve("/api/v1/episode-versions/5/chapters.vtt")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/vtt") {
+		t.Fatalf("expected vtt, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "00:01:30.000 --> 00:03:00.000\nOpening\n") {
+		t.Fatalf("unexpected chapters: %s", rec.Body.String())
+	}
+
+	rec = serve("/api/v1/episode-versions/5/media-segments")
+	var segments models.JellyfinMediaSegmentsResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &segments); err != nil {
+		t.Fatalf("decode media segments: %v", err)
+	}
+	if segments.TotalRecordCount != 2 || segments.Items[1].Type != "Outro" || segments.Items[1].StartTicks != 13_000_000_000 {
+		t.Fatalf("unexpected media segments: %+v", segments)
+	}
+	if !strings.Contains(rec.Body.String(), `"ItemId":"jf-item"`) {
+		t.Fatalf("expected jellyfin field names, got %s", rec.Body.String())
+	}
+
+	if rec := serve("/api/v1/episode-versions/9/skip-markers"); rec.Code != http.StatusNotFound || decodeErrorMessage(t, rec.Body.Bytes()) != "episodenversion nicht gefunden" {
+		t.Fatalf("expected 404, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve("/api/v1/episode-versions/abc/chapters.vtt"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
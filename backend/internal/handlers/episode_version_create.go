@@ -59,5 +59,7 @@ func (h *FansubHandler) CreateEpisodeVersion(c *gin.Context) {
 		return
 	}
 
+	h.webhooks.NotifyReleaseVersionCreated(c.Request.Context(), item.ID)
+
 	c.JSON(http.StatusCreated, gin.H{"data": item})
 }
@@ -9,11 +9,23 @@ import (
 
 	"team4s.v3/backend/internal/auth"
 	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
+// WithPlaybackSecurity speichert Sicherheitsereignisse der Release-Streams in store und weist
+// Nutzer mit aktiver Grant-Sperre ab (wie die Episoden-Wiedergabe).
+func (h *FansubHandler) WithPlaybackSecurity(store playbackSecurityEventStore, rule models.PlaybackGrantBlockRule) *FansubHandler {
+	h.securityEvents = store
+	h.grantBlockRule = rule
+	if h.localAuditLogger != nil {
+		h.localAuditLogger = withPlaybackSecurityEvents(h.localAuditLogger, store, rule)
+	}
+	return h
+}
+
 // CreateReleaseStreamGrant stellt ein zeitlich begrenztes Stream-Grant-Token für eine Release-Version aus.
 func (h *FansubHandler) CreateReleaseStreamGrant(c *gin.Context) {
 	identity, ok := middleware.CommentAuthIdentityFromContext(c)
@@ -27,6 +39,9 @@ func (h *FansubHandler) CreateReleaseStreamGrant(c *gin.Context) {
 		badRequest(c, "ungültige release id")
 		return
 	}
+	if !enforceGrantBlock(c, h.securityEvents, "release stream grant", identity.UserID) {
+		return
+	}
 
 	if _, err := h.episodeVersionRepo.GetReleaseStreamSource(c.Request.Context(), versionID); errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "release nicht gefunden"}})
@@ -62,27 +77,35 @@ func (h *FansubHandler) CreateReleaseStreamGrant(c *gin.Context) {
 	})
 }
 
-func (h *FansubHandler) authorizeReleaseStream(c *gin.Context, versionID int64) bool {
+// authorizeReleaseStream prüft Bearer-Token bzw. Stream-Grant und liefert den Rate-Limit-Prinzipal
+// des berechtigten Benutzers.
+func (h *FansubHandler) authorizeReleaseStream(c *gin.Context, versionID int64) (string, bool) {
 	if identity, ok := middleware.CommentAuthIdentityFromContext(c); ok && identity.UserID > 0 {
-		return true
+		if !enforceGrantBlock(c, h.securityEvents, "release stream", identity.UserID) {
+			return "", false
+		}
+		return playbackPrincipalForUserID(identity.UserID), true
 	}
 
 	grantToken := strings.TrimSpace(c.Query("grant"))
 	if grantToken == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "anmeldung erforderlich"}})
-		return false
+		return "", false
 	}
 
 	if strings.TrimSpace(h.releaseGrantSecret) == "" {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "stream grant vorübergehend nicht verfügbar"}})
-		return false
+		return "", false
 	}
 
 	claims, err := auth.ParseAndVerifyReleaseStreamGrant(grantToken, h.releaseGrantSecret, time.Now())
 	if err != nil || claims.ReleaseID != versionID {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "ungültiger stream grant"}})
-		return false
+		return "", false
+	}
+	if !enforceGrantBlock(c, h.securityEvents, "release stream", claims.UserID) {
+		return "", false
 	}
 
-	return true
+	return playbackPrincipalForUserID(claims.UserID), true
 }
@@ -20,7 +20,8 @@ func (h *FansubHandler) StreamRelease(c *gin.Context) {
 		return
 	}
 
-	if !h.authorizeReleaseStream(c, versionID) {
+	principal, ok := h.authorizeReleaseStream(c, versionID)
+	if !ok {
 		return
 	}
 
@@ -35,6 +36,11 @@ func (h *FansubHandler) StreamRelease(c *gin.Context) {
 		return
 	}
 
+	if isLocalMediaProvider(release.MediaProvider) {
+		h.streamLocalRelease(c, versionID, principal, release.MediaItemID)
+		return
+	}
+
 	targetURL, err := h.buildProviderStreamURL(release.MediaProvider, release.MediaItemID, release.StreamURL)
 	if err != nil || strings.TrimSpace(targetURL) == "" {
 		log.Printf("release stream: unable to build stream url (release_id=%d, provider=%q): %v", versionID, release.MediaProvider, err)
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// WithLocalMedia aktiviert den Media-Provider "local" für Release-Streams. Das Rate-Limit
+// entspricht dem der Episoden-Wiedergabe (EPISODE_PLAYBACK_RATE_LIMIT/-WINDOW_SECONDS).
+func (h *FansubHandler) WithLocalMedia(
+	library *services.LocalMediaLibrary,
+	rateLimitClient redis.UniversalClient,
+	rateLimit int,
+	rateWindowSec int,
+) *FansubHandler {
+	h.localMedia = library
+	h.localRateLimiter = newEpisodePlaybackRateLimiter(rateLimitClient, rateLimit, time.Duration(rateWindowSec)*time.Second)
+	h.localAuditLogger = newPlaybackAuditLogger(rateLimitClient)
+	if h.securityEvents != nil {
+		h.localAuditLogger = withPlaybackSecurityEvents(h.localAuditLogger, h.securityEvents, h.grantBlockRule)
+	}
+	return h
+}
+
+func isLocalMediaProvider(provider string) bool {
+	return strings.EqualFold(strings.TrimSpace(provider), services.LocalMediaProviderName)
+}
+
+// streamLocalRelease liefert eine Datei unterhalb von LOCAL_MEDIA_ROOT direkt aus. Range-Anfragen,
+// HEAD und If-Range übernimmt http.ServeContent.
+func (h *FansubHandler) streamLocalRelease(c *gin.Context, versionID int64, principal string, itemID string) {
+	if !h.localMedia.Enabled() {
+		log.Printf("release stream: local media root not configured (release_id=%d)", versionID)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "stream derzeit nicht verfügbar"}})
+		return
+	}
+	if !enforceEpisodePlaybackRateLimit(c, h.localRateLimiter, h.localAuditLogger, "release", principal) {
+		return
+	}
+
+	file, err := h.localMedia.Stat(itemID)
+	if errors.Is(err, services.ErrLocalMediaInvalidPath) || errors.Is(err, services.ErrLocalMediaNotFound) {
+		log.Printf("release stream: local media unavailable (release_id=%d, item_id=%q): %v", versionID, itemID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "stream nicht gefunden"}})
+		return
+	}
+	if err != nil {
+		log.Printf("release stream: local media stat failed (release_id=%d): %v", versionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "interner serverfehler"}})
+		return
+	}
+
+	handle, err := os.Open(file.AbsolutePath)
+	if err != nil {
+		log.Printf("release stream: open local media failed (release_id=%d): %v", versionID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "stream nicht gefunden"}})
+		return
+	}
+	defer handle.Close()
+
+	c.Header("Content-Type", file.ContentType)
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, path.Base(file.RelativePath), file.ModTime, handle)
+}
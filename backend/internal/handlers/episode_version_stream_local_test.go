@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newLocalReleaseTestHandler(t *testing.T) *FansubHandler {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "Frieren"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "Frieren", "Frieren - 01.mkv"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	return &FansubHandler{localMedia: services.NewLocalMediaLibrary(root, "")}
+}
+
+func serveLocalRelease(handler *FansubHandler, itemID string, header http.Header) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/releases/7/stream", nil)
+	for key, values := range header {
+		c.Request.Header[key] = values
+	}
+	handler.streamLocalRelease(c, 7, "user:2", itemID)
+	return rec
+}
+
+func TestStreamLocalReleaseServesRanges(t *testing.T) {
+	handler := newLocalReleaseTestHandler(t)
+
+	rec := serveLocalRelease(handler, "Frieren/Frieren - 01.mkv", http.Header{"Range": {"bytes=2-5"}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "2345" {
+		t.Fatalf("expected partial content, got %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Fatalf("unexpected content-range %q", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "video/x-matroska" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	rec = serveLocalRelease(handler, "Frieren/Frieren - 01.mkv", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" || rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("expected full file, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestStreamLocalReleaseRejectsUnsafePaths(t *testing.T) {
+	handler := newLocalReleaseTestHandler(t)
+
+	for _, itemID := range []string{"../secret.mkv", "/etc/passwd", "Frieren", "Frieren/missing.mkv"} {
+		if rec := serveLocalRelease(handler, itemID, nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %q, got %d", itemID, rec.Code)
+		}
+	}
+
+	if rec := serveLocalRelease(&FansubHandler{}, "Frieren/Frieren - 01.mkv", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without local media root, got %d", rec.Code)
+	}
+}
+
+func TestStreamLocalReleaseUsesPlaybackRateLimit(t *testing.T) {
+	handler := newLocalReleaseTestHandler(t)
+	handler.localRateLimiter = &episodePlaybackRateLimiter{
+		store:   &fakeEpisodePlaybackRateLimitStore{counts: map[string]int64{}},
+		limit:   1,
+		window:  time.Minute,
+		nowFunc: time.Now,
+		prefix:  "episode_playback_rate_limit",
+	}
+
+	if rec := serveLocalRelease(handler, "Frieren/Frieren - 01.mkv", nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected first request to pass, got %d", rec.Code)
+	}
+	rec := serveLocalRelease(handler, "Frieren/Frieren - 01.mkv", nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with retry-after, got %d", rec.Code)
+	}
+}
+
+func TestBuildLocalEpisodeVersionMediaFiles(t *testing.T) {
+	five, one := int32(5), int32(1)
+	quality := "1080p"
+	files := buildLocalEpisodeVersionMediaFiles([]services.LocalMediaFile{
+		{RelativePath: "Frieren/Frieren - 05 [1080p].mkv", Size: 10, DetectedEpisodeNumber: &five, VideoQuality: &quality},
+		{RelativePath: "Frieren/Frieren - 01.mkv", Size: 20, DetectedEpisodeNumber: &one},
+	})
+	if len(files) != 2 || files[0].MediaItemID != "Frieren/Frieren - 01.mkv" || files[1].FileName != "Frieren - 05 [1080p].mkv" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+	if files[0].MediaProvider != "local" || files[0].StreamURL != nil || files[1].ReleaseName == nil || *files[1].ReleaseName != "Frieren - 05 [1080p]" {
+		t.Fatalf("unexpected local file entry %+v", files[1])
+	}
+}
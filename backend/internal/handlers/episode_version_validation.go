@@ -32,7 +32,7 @@ func validateEpisodeVersionCreateRequest(req episodeVersionCreateRequest) (model
 	}
 
 	mediaItemID := normalizeRequiredString(&req.MediaItemID)
-	if mediaItemID == nil || len([]rune(*mediaItemID)) > 120 {
+	if mediaItemID == nil || len([]rune(*mediaItemID)) > 255 {
 		return models.EpisodeVersionCreateInput{}, "ungültiger media_item_id parameter"
 	}
 
@@ -115,7 +115,7 @@ func validateEpisodeVersionPatchRequest(req models.EpisodeVersionPatchInput) (mo
 	}
 	if req.MediaItemID.Set {
 		value := normalizeRequiredString(req.MediaItemID.Value)
-		if value == nil || len([]rune(*value)) > 120 {
+		if value == nil || len([]rune(*value)) > 255 {
 			return models.EpisodeVersionPatchInput{}, "ungültiger media_item_id parameter"
 		}
 		req.MediaItemID.Value = value
@@ -4,13 +4,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
-	"path"
 	"strconv"
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/permissions"
 	"team4s.v3/backend/internal/repository"
 	"team4s.v3/backend/internal/services"
@@ -51,6 +51,12 @@ type FansubHandler struct {
 	httpClient         *http.Client
 	permissionSvc      *permissions.Service
 	auditLogRepo       *repository.AuditLogRepository
+	webhooks           *services.WebhookService
+	localMedia         *services.LocalMediaLibrary
+	localRateLimiter   *episodePlaybackRateLimiter
+	localAuditLogger   *playbackAuditLogger
+	securityEvents     playbackSecurityEventStore
+	grantBlockRule     models.PlaybackGrantBlockRule
 }
 
 // FansubProxyConfig enthält die Konfigurationswerte für den Emby- und Jellyfin-Medienproxy sowie das Stream-Grant-System.
@@ -98,6 +104,12 @@ func (h *FansubHandler) WithPermissionDeps(permissionSvc *permissions.Service, a
 	return h
 }
 
+// WithWebhooks meldet neu angelegte Episodenversionen an die Webhooks der beteiligten Gruppen.
+func (h *FansubHandler) WithWebhooks(webhooks *services.WebhookService) *FansubHandler {
+	h.webhooks = webhooks
+	return h
+}
+
 func (h *FansubHandler) requireAdmin(c *gin.Context) (middleware.AuthIdentity, bool) {
 	return requirePlatformAdminIdentity(c, h.authzRepo, h.adminRoleName)
 }
@@ -168,6 +180,28 @@ func normalizeStreamPathTemplate(raw string) string {
 	return trimmed
 }
 
+// mediaServerFor liefert den Media-Server zu einem gespeicherten Provider-Namen ("jellyfin", "emby").
+func (h *FansubHandler) mediaServerFor(provider string) (mediaserver.MediaServerProvider, error) {
+	switch mediaserver.NormalizeKind(provider) {
+	case mediaserver.KindJellyfin:
+		return mediaserver.NewJellyfin(mediaserver.Config{
+			BaseURL:            h.jellyfinBaseURL,
+			APIKey:             h.jellyfinAPIKey,
+			StreamPathTemplate: h.jellyfinStreamPath,
+			HTTPClient:         h.httpClient,
+		}), nil
+	case mediaserver.KindEmby:
+		return mediaserver.NewEmby(mediaserver.Config{
+			BaseURL:            h.embyBaseURL,
+			APIKey:             h.embyAPIKey,
+			StreamPathTemplate: h.embyStreamPath,
+			HTTPClient:         h.httpClient,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown media provider %q", provider)
+	}
+}
+
 func (h *FansubHandler) buildProviderStreamURL(provider, itemID string, fallbackURL *string) (string, error) {
 	if fallbackURL != nil {
 		if trimmed := strings.TrimSpace(*fallbackURL); trimmed != "" {
@@ -175,43 +209,11 @@ func (h *FansubHandler) buildProviderStreamURL(provider, itemID string, fallback
 		}
 	}
 
-	normalizedProvider := strings.ToLower(strings.TrimSpace(provider))
-	switch normalizedProvider {
-	case "jellyfin":
-		return buildProviderStreamURL(h.jellyfinBaseURL, h.jellyfinStreamPath, h.jellyfinAPIKey, itemID)
-	case "emby":
-		return buildProviderStreamURL(h.embyBaseURL, h.embyStreamPath, h.embyAPIKey, itemID)
-	default:
-		return "", fmt.Errorf("unknown media provider %q", provider)
-	}
-}
-
-func buildProviderStreamURL(baseURL, pathTemplate, apiKey, itemID string) (string, error) {
-	base := strings.TrimSpace(baseURL)
-	if base == "" {
-		return "", fmt.Errorf("media base url is missing")
-	}
-	if strings.TrimSpace(apiKey) == "" {
-		return "", fmt.Errorf("media api key is missing")
-	}
-	if strings.TrimSpace(itemID) == "" {
-		return "", fmt.Errorf("media item id is missing")
-	}
-
-	parsedBase, err := url.Parse(base)
+	server, err := h.mediaServerFor(provider)
 	if err != nil {
-		return "", fmt.Errorf("parse media base url: %w", err)
+		return "", err
 	}
-
-	streamPath := fmt.Sprintf(pathTemplate, strings.TrimSpace(itemID))
-	parsedBase.Path = path.Clean("/" + strings.TrimPrefix(streamPath, "/"))
-
-	query := parsedBase.Query()
-	query.Set("api_key", strings.TrimSpace(apiKey))
-	query.Set("static", "true")
-	parsedBase.RawQuery = query.Encode()
-
-	return parsedBase.String(), nil
+	return server.StreamURL(itemID)
 }
 
 func (h *FansubHandler) buildProviderImageURL(
@@ -222,32 +224,9 @@ func (h *FansubHandler) buildProviderImageURL(
 	quality,
 	index *int,
 ) (string, error) {
-	normalizedProvider := strings.ToLower(strings.TrimSpace(provider))
-	baseURL := ""
-	apiKey := ""
-
-	switch normalizedProvider {
-	case "jellyfin":
-		baseURL = h.jellyfinBaseURL
-		apiKey = h.jellyfinAPIKey
-	case "emby":
-		baseURL = h.embyBaseURL
-		apiKey = h.embyAPIKey
-	default:
-		return "", fmt.Errorf("unknown media provider %q", provider)
-	}
-
-	baseURL = strings.TrimSpace(baseURL)
-	if baseURL == "" {
-		return "", fmt.Errorf("media base url is missing")
-	}
-	apiKey = strings.TrimSpace(apiKey)
-	if apiKey == "" {
-		return "", fmt.Errorf("media api key is missing")
-	}
-	itemID = strings.TrimSpace(itemID)
-	if itemID == "" {
-		return "", fmt.Errorf("media item id is missing")
+	server, err := h.mediaServerFor(provider)
+	if err != nil {
+		return "", err
 	}
 
 	imageType := "Primary"
@@ -264,27 +243,5 @@ func (h *FansubHandler) buildProviderImageURL(
 		imageType = "Thumb"
 	}
 
-	parsedBase, err := url.Parse(baseURL)
-	if err != nil {
-		return "", fmt.Errorf("parse media base url: %w", err)
-	}
-
-	imagePath := fmt.Sprintf("/Items/%s/Images/%s", itemID, imageType)
-	if strings.EqualFold(imageType, "Backdrop") && index != nil {
-		imagePath = fmt.Sprintf("/Items/%s/Images/%s/%d", itemID, imageType, *index)
-	}
-
-	parsedBase.Path = path.Clean("/" + strings.TrimPrefix(imagePath, "/"))
-
-	query := parsedBase.Query()
-	query.Set("api_key", apiKey)
-	if width != nil {
-		query.Set("maxWidth", strconv.Itoa(*width))
-	}
-	if quality != nil {
-		query.Set("quality", strconv.Itoa(*quality))
-	}
-	parsedBase.RawQuery = query.Encode()
-
-	return parsedBase.String(), nil
+	return server.ImageURL(itemID, imageType, index, mediaserver.ImageOptions{MaxWidth: width, Quality: quality})
 }
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/permissions"
+	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookURLLength begrenzt die Länge registrierter Ziel-URLs.
+const maxWebhookURLLength = 2000
+
+// fansubGroupWebhookRepository kapselt die Webhook-Verwaltung einer Gruppe.
+type fansubGroupWebhookRepository interface {
+	ListForGroup(ctx context.Context, fansubGroupID int64) ([]models.FansubGroupWebhook, error)
+	Create(ctx context.Context, fansubGroupID int64, input models.FansubGroupWebhookInput, createdByAppUserID int64) (*models.FansubGroupWebhook, error)
+	Update(ctx context.Context, fansubGroupID int64, webhookID int64, input models.FansubGroupWebhookInput) (*models.FansubGroupWebhook, error)
+	Delete(ctx context.Context, fansubGroupID int64, webhookID int64) error
+	ListDeliveries(ctx context.Context, fansubGroupID int64, webhookID int64, filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, int64, error)
+}
+
+// FansubGroupWebhookHandler verwaltet die ausgehenden Webhooks einer Fansub-Gruppe
+// (fansub_group.webhooks.manage im Handler).
+type FansubGroupWebhookHandler struct {
+	repo          fansubGroupWebhookRepository
+	permissionSvc *permissions.Service
+	auditLogRepo  auditLogWriter
+}
+
+// NewFansubGroupWebhookHandler erstellt einen neuen FansubGroupWebhookHandler.
+func NewFansubGroupWebhookHandler(
+	repo fansubGroupWebhookRepository,
+	permissionSvc *permissions.Service,
+	auditLogRepo auditLogWriter,
+) *FansubGroupWebhookHandler {
+	return &FansubGroupWebhookHandler{repo: repo, permissionSvc: permissionSvc, auditLogRepo: auditLogRepo}
+}
+
+type fansubGroupWebhookCreateRequest struct {
+	URL      string   `json:"url"`
+	Format   string   `json:"format"`
+	Events   []string `json:"events"`
+	IsActive *bool    `json:"is_active"`
+}
+
+type fansubGroupWebhookUpdateRequest struct {
+	URL          *string  `json:"url"`
+	Format       *string  `json:"format"`
+	Events       []string `json:"events"`
+	IsActive     *bool    `json:"is_active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+// List verarbeitet GET /api/v1/admin/fansubs/:id/webhooks.
+func (h *FansubGroupWebhookHandler) List(c *gin.Context) {
+	_, fansubID, ok := h.requireManage(c, "fansub_group_webhook.list.denied")
+	if !ok {
+		return
+	}
+
+	items, err := h.repo.ListForGroup(c.Request.Context(), fansubID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "fansubgruppe nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("fansub webhooks list: repo error (fansub_id=%d): %v", fansubID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items, "meta": gin.H{"events": models.WebhookEvents}})
+}
+
+// Create verarbeitet POST /api/v1/admin/fansubs/:id/webhooks. Das Signatur-Secret ist nur in
+// dieser Antwort enthalten.
+func (h *FansubGroupWebhookHandler) Create(c *gin.Context) {
+	identity, fansubID, ok := h.requireManage(c, "fansub_group_webhook.create.denied")
+	if !ok {
+		return
+	}
+
+	var req fansubGroupWebhookCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	input := models.FansubGroupWebhookInput{IsActive: req.IsActive}
+	var message string
+	if input.URL, message = normalizeWebhookURL(req.URL); message != "" {
+		badRequest(c, message)
+		return
+	}
+	if input.Format, message = normalizeWebhookFormat(req.Format); message != "" {
+		badRequest(c, message)
+		return
+	}
+	if input.Events, message = normalizeWebhookEvents(req.Events); message != "" {
+		badRequest(c, message)
+		return
+	}
+
+	item, err := h.repo.Create(c.Request.Context(), fansubID, input, identity.AppUserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "fansubgruppe nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("fansub webhooks create: repo error (user_id=%d, fansub_id=%d): %v", identity.UserID, fansubID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	h.writeAudit(c, identity, "fansub_group_webhook.created", fansubID, item.ID, map[string]any{
+		"format": item.Format,
+		"events": item.Events,
+	})
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// Update verarbeitet PATCH /api/v1/admin/fansubs/:id/webhooks/:webhookId. Mit rotate_secret
+// wird ein neues Secret erzeugt und einmalig ausgeliefert.
+func (h *FansubGroupWebhookHandler) Update(c *gin.Context) {
+	identity, fansubID, ok := h.requireManage(c, "fansub_group_webhook.update.denied")
+	if !ok {
+		return
+	}
+	webhookID, err := parsePositiveID(c.Param("webhookId"))
+	if err != nil {
+		badRequest(c, "ungültige webhook id")
+		return
+	}
+
+	var req fansubGroupWebhookUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	input := models.FansubGroupWebhookInput{IsActive: req.IsActive, RotateSecret: req.RotateSecret}
+	var message string
+	if req.URL != nil {
+		if input.URL, message = normalizeWebhookURL(*req.URL); message != "" {
+			badRequest(c, message)
+			return
+		}
+	}
+	if req.Format != nil {
+		if input.Format, message = normalizeWebhookFormat(*req.Format); message != "" {
+			badRequest(c, message)
+			return
+		}
+	}
+	if req.Events != nil {
+		if input.Events, message = normalizeWebhookEvents(req.Events); message != "" {
+			badRequest(c, message)
+			return
+		}
+	}
+
+	item, err := h.repo.Update(c.Request.Context(), fansubID, webhookID, input)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "webhook nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("fansub webhooks update: repo error (fansub_id=%d, webhook_id=%d): %v", fansubID, webhookID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	h.writeAudit(c, identity, "fansub_group_webhook.updated", fansubID, item.ID, map[string]any{
+		"format":         item.Format,
+		"events":         item.Events,
+		"is_active":      item.IsActive,
+		"secret_rotated": req.RotateSecret,
+	})
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// Delete verarbeitet DELETE /api/v1/admin/fansubs/:id/webhooks/:webhookId.
+func (h *FansubGroupWebhookHandler) Delete(c *gin.Context) {
+	identity, fansubID, ok := h.requireManage(c, "fansub_group_webhook.delete.denied")
+	if !ok {
+		return
+	}
+	webhookID, err := parsePositiveID(c.Param("webhookId"))
+	if err != nil {
+		badRequest(c, "ungültige webhook id")
+		return
+	}
+
+	err = h.repo.Delete(c.Request.Context(), fansubID, webhookID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "webhook nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("fansub webhooks delete: repo error (fansub_id=%d, webhook_id=%d): %v", fansubID, webhookID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	h.writeAudit(c, identity, "fansub_group_webhook.deleted", fansubID, webhookID, nil)
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries verarbeitet GET /api/v1/admin/fansubs/:id/webhooks/:webhookId/deliveries mit
+// optionalem Filter status=pending|sending|delivered|dead.
+func (h *FansubGroupWebhookHandler) ListDeliveries(c *gin.Context) {
+	_, fansubID, ok := h.requireManage(c, "fansub_group_webhook.deliveries.denied")
+	if !ok {
+		return
+	}
+	webhookID, err := parsePositiveID(c.Param("webhookId"))
+	if err != nil {
+		badRequest(c, "ungültige webhook id")
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	if status != "" && !models.IsWebhookDeliveryStatus(status) {
+		badRequest(c, "ungültiger status parameter")
+		return
+	}
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "50"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 200 {
+		perPage = 200
+	}
+
+	items, total, err := h.repo.ListDeliveries(c.Request.Context(), fansubID, webhookID, models.WebhookDeliveryFilter{
+		Status:  status,
+		Page:    page,
+		PerPage: perPage,
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "webhook nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("fansub webhooks deliveries: repo error (fansub_id=%d, webhook_id=%d): %v", fansubID, webhookID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// requireManage prüft fansub_group.webhooks.manage für die Gruppe aus :id und protokolliert
+// Ablehnungen mit deniedEvent.
+func (h *FansubGroupWebhookHandler) requireManage(c *gin.Context, deniedEvent string) (middleware.AuthIdentity, int64, bool) {
+	identity, actor, ok := permissionActorFromContext(c)
+	if !ok {
+		return middleware.AuthIdentity{}, 0, false
+	}
+	fansubID, err := parseFansubID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige fansub id")
+		return middleware.AuthIdentity{}, 0, false
+	}
+
+	result, err := h.permissionSvc.CanForFansubGroup(c.Request.Context(), actor, permissions.ActionFansubGroupWebhooksManage, fansubID)
+	if err != nil {
+		writePermissionInternalError(c, err, "Webhook-Berechtigung konnte nicht geprüft werden.")
+		return middleware.AuthIdentity{}, 0, false
+	}
+	if !result.Allowed {
+		auditPermissionDenied(c, h.auditLogRepo, identity, deniedEvent, &fansubID, "fansub_group", &fansubID, permissions.ActionFansubGroupWebhooksManage, result)
+		writePermissionDenied(c, result)
+		return middleware.AuthIdentity{}, 0, false
+	}
+	return identity, fansubID, true
+}
+
+func (h *FansubGroupWebhookHandler) writeAudit(c *gin.Context, identity middleware.AuthIdentity, eventType string, fansubID int64, webhookID int64, payload map[string]any) {
+	if h.auditLogRepo == nil {
+		return
+	}
+	_ = h.auditLogRepo.Write(c.Request.Context(), repository.AuditLogEntry{
+		ActorAppUserID: &identity.AppUserID,
+		EventType:      eventType,
+		ScopeType:      permissions.ScopeTypeGroup,
+		ScopeID:        &fansubID,
+		TargetType:     "fansub_group_webhook",
+		TargetID:       &webhookID,
+		Action:         string(permissions.ActionFansubGroupWebhooksManage),
+		Outcome:        "allowed",
+		Payload:        payload,
+	})
+}
+
+// normalizeWebhookURL akzeptiert nur absolute https-URLs ohne Zugangsdaten. Offensichtlich
+// interne Ziele (localhost, nicht-öffentliche IP-Literale) werden schon hier abgelehnt; für
+// DNS-Namen prüft der WebhookWorker die aufgelöste Adresse bei jeder Zustellung.
+func normalizeWebhookURL(raw string) (string, string) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", "url fehlt"
+	}
+	if len(value) > maxWebhookURLLength {
+		return "", "url ist zu lang"
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || parsed.User != nil {
+		return "", "url muss eine https-adresse sein"
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "", "url muss eine öffentliche adresse sein"
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !services.IsPublicWebhookAddress(addr) {
+		return "", "url muss eine öffentliche adresse sein"
+	}
+	return parsed.String(), ""
+}
+
+func normalizeWebhookFormat(raw string) (string, string) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return models.WebhookFormatJSON, ""
+	}
+	if !models.IsWebhookFormat(value) {
+		return "", "ungültiges format"
+	}
+	return value, ""
+}
+
+func normalizeWebhookEvents(raw []string) ([]string, string) {
+	seen := make(map[string]struct{}, len(raw))
+	events := make([]string, 0, len(raw))
+	for _, event := range raw {
+		value := strings.ToLower(strings.TrimSpace(event))
+		if !models.IsWebhookEvent(value) {
+			return nil, "ungültiges ereignis: " + value
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		events = append(events, value)
+	}
+	if len(events) == 0 {
+		return nil, "mindestens ein ereignis erforderlich"
+	}
+	return events, ""
+}
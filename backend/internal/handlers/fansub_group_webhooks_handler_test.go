@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/permissions"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webhookPermissionResolverStub löst jede Gruppe auf und liefert die konfigurierten Rollen.
+type webhookPermissionResolverStub struct {
+	roles []string
+}
+
+func (s webhookPermissionResolverStub) ResolveFansubGroup(_ context.Context, fansubGroupID int64) (*permissions.Context, error) {
+	return &permissions.Context{ScopeType: permissions.ScopeTypeGroup, FansubGroupIDs: []int64{fansubGroupID}}, nil
+}
+
+func (s webhookPermissionResolverStub) ResolveRelease(_ context.Context, _ int64) (*permissions.Context, error) {
+	return nil, nil
+}
+
+func (s webhookPermissionResolverStub) ResolveReleaseVersion(_ context.Context, _ int64) (*permissions.Context, error) {
+	return nil, nil
+}
+
+func (s webhookPermissionResolverStub) ResolveReleaseVersionMedia(_ context.Context, _ int64) (*permissions.Context, error) {
+	return nil, nil
+}
+
+func (s webhookPermissionResolverStub) ListActorGroupRoles(_ context.Context, _ int64, _ int64) ([]string, error) {
+	return s.roles, nil
+}
+
+func (s webhookPermissionResolverStub) ListActorContributionRolesForVersion(_ context.Context, _ int64, _ int64) ([]string, error) {
+	return nil, nil
+}
+
+type webhookRepoStub struct {
+	created      *models.FansubGroupWebhookInput
+	deliveryArgs *models.WebhookDeliveryFilter
+}
+
+func (s *webhookRepoStub) ListForGroup(_ context.Context, _ int64) ([]models.FansubGroupWebhook, error) {
+	return []models.FansubGroupWebhook{}, nil
+}
+
+func (s *webhookRepoStub) Create(_ context.Context, fansubGroupID int64, input models.FansubGroupWebhookInput, _ int64) (*models.FansubGroupWebhook, error) {
+	s.created = &input
+	return &models.FansubGroupWebhook{ID: 9, FansubGroupID: fansubGroupID, URL: input.URL, Format: input.Format, Events: input.Events, IsActive: true, Secret: "whsec_new"}, nil
+}
+
+func (s *webhookRepoStub) Update(_ context.Context, fansubGroupID int64, webhookID int64, input models.FansubGroupWebhookInput) (*models.FansubGroupWebhook, error) {
+	return &models.FansubGroupWebhook{ID: webhookID, FansubGroupID: fansubGroupID}, nil
+}
+
+func (s *webhookRepoStub) Delete(_ context.Context, _ int64, _ int64) error {
+	return nil
+}
+
+func (s *webhookRepoStub) ListDeliveries(_ context.Context, _ int64, _ int64, filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, int64, error) {
+	s.deliveryArgs = &filter
+	return []models.WebhookDelivery{}, 0, nil
+}
+
+func newWebhookTestContext(method string, target string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+	c.Set("auth_identity", middleware.AuthIdentity{UserID: 3, AppUserID: 3, AppUserStatus: models.AppUserStatusActive, DisplayName: "Lead"})
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+	return c, rec
+}
+
+func TestFansubGroupWebhookCreateRequiresManagePermission(t *testing.T) {
+	c, rec := newWebhookTestContext(http.MethodPost, "/admin/fansubs/5/webhooks", `{"url":"https://example.org/hook","events":["release_version.created"]}`)
+	repo := &webhookRepoStub{}
+	audit := &captureAuditLogRepo{}
+
+	NewFansubGroupWebhookHandler(repo, permissions.NewService(webhookPermissionResolverStub{roles: []string{permissions.RoleTranslator}}), audit).Create(c)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d with body %s", rec.Code, rec.Body.String())
+	}
+	if repo.created != nil {
+		t.Fatal("expected repository not to be called")
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Outcome != "denied" {
+		t.Fatalf("expected one denied audit entry, got %+v", audit.entries)
+	}
+}
+
+func TestFansubGroupWebhookCreateValidatesAndReturnsSecret(t *testing.T) {
+	lead := permissions.NewService(webhookPermissionResolverStub{roles: []string{permissions.RoleFansubLead}})
+
+	for name, body := range map[string]string{
+		"plain http":     `{"url":"http://example.org/hook","events":["release_version.created"]}`,
+		"loopback":       `{"url":"https://127.0.0.1:6379/","events":["release_version.created"]}`,
+		"localhost":      `{"url":"https://localhost/hook","events":["release_version.created"]}`,
+		"metadata":       `{"url":"https://169.254.169.254/latest/meta-data","events":["release_version.created"]}`,
+		"private ipv6":   `{"url":"https://[fd00::1]/hook","events":["release_version.created"]}`,
+		"unknown event":  `{"url":"https://example.org/hook","events":["anime.deleted"]}`,
+		"no events":      `{"url":"https://example.org/hook","events":[]}`,
+		"unknown format": `{"url":"https://example.org/hook","format":"slack","events":["release_version.created"]}`,
+	} {
+		c, rec := newWebhookTestContext(http.MethodPost, "/admin/fansubs/5/webhooks", body)
+		NewFansubGroupWebhookHandler(&webhookRepoStub{}, lead, nil).Create(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+
+	c, rec := newWebhookTestContext(http.MethodPost, "/admin/fansubs/5/webhooks",
+		`{"url":"https://discord.com/api/webhooks/1/abc","format":"Discord","events":["project_note.published","project_note.published"]}`)
+	repo := &webhookRepoStub{}
+	audit := &captureAuditLogRepo{}
+	NewFansubGroupWebhookHandler(repo, lead, audit).Create(c)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d with body %s", rec.Code, rec.Body.String())
+	}
+	if repo.created.Format != models.WebhookFormatDiscord || len(repo.created.Events) != 1 {
+		t.Fatalf("expected normalized discord input with deduplicated events, got %+v", repo.created)
+	}
+	var response struct {
+		Data models.FansubGroupWebhook `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || response.Data.Secret != "whsec_new" {
+		t.Fatalf("expected secret in create response, got %s", rec.Body.String())
+	}
+	if len(audit.entries) != 1 || audit.entries[0].EventType != "fansub_group_webhook.created" {
+		t.Fatalf("expected created audit entry, got %+v", audit.entries)
+	}
+}
+
+func TestFansubGroupWebhookListDeliveriesFiltersByStatus(t *testing.T) {
+	lead := permissions.NewService(webhookPermissionResolverStub{roles: []string{permissions.RoleFansubLead}})
+
+	c, rec := newWebhookTestContext(http.MethodGet, "/admin/fansubs/5/webhooks/9/deliveries?status=dead", "")
+	c.Params = append(c.Params, gin.Param{Key: "webhookId", Value: "9"})
+	repo := &webhookRepoStub{}
+	NewFansubGroupWebhookHandler(repo, lead, nil).ListDeliveries(c)
+	if rec.Code != http.StatusOK || repo.deliveryArgs == nil || repo.deliveryArgs.Status != models.WebhookDeliveryStatusDead {
+		t.Fatalf("expected dead filter, got %d with %+v", rec.Code, repo.deliveryArgs)
+	}
+
+	c, rec = newWebhookTestContext(http.MethodGet, "/admin/fansubs/5/webhooks/9/deliveries?status=lost", "")
+	c.Params = append(c.Params, gin.Param{Key: "webhookId", Value: "9"})
+	NewFansubGroupWebhookHandler(&webhookRepoStub{}, lead, nil).ListDeliveries(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown status, got %d", rec.Code)
+	}
+}
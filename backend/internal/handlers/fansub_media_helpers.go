@@ -1,18 +1,24 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
 	"io"
 	"log"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
 
+	"github.com/disintegration/imaging"
 	"github.com/gin-gonic/gin"
 )
 
@@ -97,10 +103,11 @@ func (h *FansubHandler) tryCleanupUnusedMedia(ctx context.Context, mediaID int64
 		return
 	}
 
-	if err := removeFileQuietly(asset.StoragePath); err != nil {
-		log.Printf("fansub media cleanup: delete file failed (media_id=%d, path=%q): %v", mediaID, asset.StoragePath, err)
-	}
 	seenPaths := map[string]struct{}{strings.TrimSpace(asset.StoragePath): {}}
+	if !h.removeStoredMediaFile(ctx, asset.StoragePath) {
+		// Geteilter Blob: das abgeleitete Thumbnail gehört weiterhin den übrigen Assets.
+		seenPaths[groupMediaThumbPath(strings.TrimSpace(asset.StoragePath))] = struct{}{}
+	}
 	for _, filePath := range filePaths {
 		trimmedPath := strings.TrimSpace(filePath)
 		if trimmedPath == "" {
@@ -110,12 +117,41 @@ func (h *FansubHandler) tryCleanupUnusedMedia(ctx context.Context, mediaID int64
 			continue
 		}
 		seenPaths[trimmedPath] = struct{}{}
-		if err := removeFileQuietly(trimmedPath); err != nil {
+		if err := removeMediaFile(ctx, h.mediaService, trimmedPath); err != nil {
 			log.Printf("fansub media cleanup: delete media file variant failed (media_id=%d, path=%q): %v", mediaID, trimmedPath, err)
 		}
 	}
 }
 
+// removeStoredMediaFile löscht eine Upload-Datei nur, wenn kein media_assets-Eintrag mehr auf sie
+// oder ihren Blob verweist. Das abgeleitete Gruppen-Thumbnail wird mit entfernt; beides geschieht,
+// solange die Blob-Zeile gesperrt ist. Gibt zurück, ob die Datei entfernt wurde.
+func (h *FansubHandler) removeStoredMediaFile(ctx context.Context, path string) bool {
+	trimmedPath := strings.TrimSpace(path)
+	if trimmedPath == "" {
+		return false
+	}
+	remove := func(ctx context.Context) error {
+		if err := removeMediaFile(ctx, h.mediaService, trimmedPath); err != nil {
+			return err
+		}
+		return removeMediaFile(ctx, h.mediaService, groupMediaThumbPath(trimmedPath))
+	}
+	if h.mediaRepo == nil {
+		if err := remove(ctx); err != nil {
+			log.Printf("fansub media cleanup: delete file failed (path=%q): %v", trimmedPath, err)
+			return false
+		}
+		return true
+	}
+	released, err := h.mediaRepo.ReleaseMediaBlobFile(ctx, trimmedPath, remove)
+	if err != nil {
+		log.Printf("fansub media cleanup: release blob failed (path=%q): %v", trimmedPath, err)
+		return false
+	}
+	return released
+}
+
 // parseMediaKind wandelt einen rohen String in einen validen MediaKind-Wert um.
 func parseMediaKind(raw string) (models.MediaKind, error) {
 	switch models.MediaKind(strings.ToLower(strings.TrimSpace(raw))) {
@@ -130,6 +166,41 @@ func parseMediaKind(raw string) (models.MediaKind, error) {
 	}
 }
 
+// removeMediaFile löscht eine vom MediaService abgelegte Datei aus dessen BlobStore. Ohne
+// MediaService wird wie bisher direkt im Dateisystem gelöscht.
+func removeMediaFile(ctx context.Context, mediaService *services.MediaService, path string) error {
+	if mediaService == nil {
+		return removeFileQuietly(path)
+	}
+	return mediaService.RemoveStoredFile(ctx, path)
+}
+
+// storeMediaFile schreibt data unter path in den BlobStore des MediaService. Ohne MediaService
+// wird wie bisher direkt ins Dateisystem geschrieben.
+func storeMediaFile(ctx context.Context, mediaService *services.MediaService, path string, data []byte) error {
+	if mediaService == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0o644)
+	}
+	return mediaService.StoreFile(ctx, path, data, mime.TypeByExtension(filepath.Ext(path)))
+}
+
+// encodeImageFile kodiert img im Format, das die Dateiendung von name vorgibt. Wie imaging.Save
+// ohne Metadaten, aber im Speicher, damit das Ergebnis in den BlobStore geschrieben werden kann.
+func encodeImageFile(img image.Image, name string) ([]byte, error) {
+	format, err := imaging.FormatFromFilename(name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // removeFileQuietly löscht eine Datei ohne Fehler zurückzugeben, wenn die Datei nicht existiert.
 func removeFileQuietly(path string) error {
 	trimmedPath := strings.TrimSpace(path)
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
@@ -37,6 +38,23 @@ func (h *FansubHandler) ServeMediaFile(c *gin.Context) {
 		return
 	}
 
+	cacheControl := "public, max-age=31536000, immutable"
+	if h.mediaService != nil {
+		if key, ok := h.mediaService.StorageKey(asset.StoragePath); ok {
+			err := serveStoredMedia(c, h.mediaService.BlobStore(), key, asset.MimeType, cacheControl)
+			if errors.Is(err, blobstore.ErrNotExist) {
+				c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "datei nicht gefunden"}})
+				return
+			}
+			if err != nil {
+				log.Printf("media serve: read failed (filename=%q): %v", filename, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "interner serverfehler"}})
+			}
+			return
+		}
+	}
+
+	// Altbestand außerhalb des Medienstamms liegt weiterhin nur im lokalen Dateisystem.
 	if _, err := os.Stat(asset.StoragePath); err != nil {
 		if os.IsNotExist(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "datei nicht gefunden"}})
@@ -48,6 +66,6 @@ func (h *FansubHandler) ServeMediaFile(c *gin.Context) {
 	}
 
 	c.Header("Content-Type", asset.MimeType)
-	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("Cache-Control", cacheControl)
 	c.File(asset.StoragePath)
 }
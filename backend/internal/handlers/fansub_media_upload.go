@@ -1,13 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"io"
 	"log"
 	"mime/multipart"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -18,6 +18,7 @@ import (
 	"team4s.v3/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 // validVisibilityCodes enthält die erlaubten visibility_code-Werte (aus Migration 0037 visibilities-Tabelle).
@@ -171,7 +172,7 @@ func (h *FansubHandler) storeFansubMediaUpload(
 		var sourceOK bool
 		sourceResult, sourceOK = h.saveFansubMediaSourceOriginal(c, userID, fansubID, kind, sourceFileHeader.Filename, sourceData)
 		if !sourceOK {
-			_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
+			h.removeStoredMediaFile(c.Request.Context(), saveResult.CreateInput.StoragePath)
 			return nil, false, false
 		}
 	}
@@ -183,7 +184,7 @@ func (h *FansubHandler) storeFansubMediaUpload(
 	asset, ok := h.persistFansubMediaAsset(c, userID, fansubID, kind, saveResult, sourceResult)
 	if !ok {
 		if sourceResult != nil {
-			_ = removeFileQuietly(sourceResult.StoragePath)
+			_ = removeMediaFile(c.Request.Context(), h.mediaService, sourceResult.StoragePath)
 		}
 		return nil, false, false
 	}
@@ -199,7 +200,7 @@ func (h *FansubHandler) saveFansubMediaUpload(
 	filename string,
 	data []byte,
 ) (*services.MediaSaveResult, bool) {
-	saveResult, err := h.mediaService.SaveUpload(kind, filename, data)
+	saveResult, err := h.mediaService.SaveUpload(c.Request.Context(), kind, filename, data)
 	if err != nil {
 		var validationErr *services.MediaValidationError
 		if errors.As(err, &validationErr) {
@@ -222,7 +223,7 @@ func (h *FansubHandler) saveFansubMediaSourceOriginal(
 	filename string,
 	data []byte,
 ) (*services.MediaVariantSaveResult, bool) {
-	saveResult, err := h.mediaService.SaveUploadSourceOriginal(kind, filename, data)
+	saveResult, err := h.mediaService.SaveUploadSourceOriginal(c.Request.Context(), kind, filename, data)
 	if err != nil {
 		var validationErr *services.MediaValidationError
 		if errors.As(err, &validationErr) {
@@ -245,9 +246,9 @@ func (h *FansubHandler) persistFansubMediaAsset(
 	saveResult *services.MediaSaveResult,
 	sourceResult *services.MediaVariantSaveResult,
 ) (*models.MediaAsset, bool) {
-	asset, err := h.mediaRepo.CreateMediaAsset(c.Request.Context(), saveResult.CreateInput)
+	asset, err := h.mediaRepo.CreateMediaAssetWithBlob(c.Request.Context(), saveResult.CreateInput, saveResult.EnsureStored)
 	if err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
+		h.removeStoredMediaFile(c.Request.Context(), saveResult.CreateInput.StoragePath)
 		if errors.Is(err, repository.ErrConflict) {
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "media asset bereits vorhanden"}})
 			return nil, false
@@ -258,7 +259,7 @@ func (h *FansubHandler) persistFansubMediaAsset(
 	}
 	if err := h.mediaRepo.InsertMediaFile(c.Request.Context(), asset.ID, "original", asset.StoragePath, asset.SizeBytes); err != nil {
 		_ = h.mediaRepo.DeleteMediaAsset(c.Request.Context(), asset.ID)
-		_ = removeFileQuietly(asset.StoragePath)
+		h.removeStoredMediaFile(c.Request.Context(), asset.StoragePath)
 		log.Printf("fansub media upload: insert original media file failed (user_id=%d, fansub_id=%d, media_id=%d): %v", userID, fansubID, asset.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "upload fehlgeschlagen"}})
 		return nil, false
@@ -266,8 +267,8 @@ func (h *FansubHandler) persistFansubMediaAsset(
 	if sourceResult != nil && strings.TrimSpace(sourceResult.StoragePath) != "" && sourceResult.StoragePath != asset.StoragePath {
 		if err := h.mediaRepo.InsertMediaFile(c.Request.Context(), asset.ID, "source_original", sourceResult.StoragePath, sourceResult.SizeBytes); err != nil {
 			_ = h.mediaRepo.DeleteMediaAsset(c.Request.Context(), asset.ID)
-			_ = removeFileQuietly(asset.StoragePath)
-			_ = removeFileQuietly(sourceResult.StoragePath)
+			h.removeStoredMediaFile(c.Request.Context(), asset.StoragePath)
+			_ = removeMediaFile(c.Request.Context(), h.mediaService, sourceResult.StoragePath)
 			log.Printf("fansub media upload: insert source media file failed (user_id=%d, fansub_id=%d, media_id=%d): %v", userID, fansubID, asset.ID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"message": "upload fehlgeschlagen"}})
 			return nil, false
@@ -278,9 +279,9 @@ func (h *FansubHandler) persistFansubMediaAsset(
 	previousMediaID, err := h.mediaRepo.AssignFansubMedia(c.Request.Context(), fansubID, kind, asset.ID, asset.PublicURL)
 	if err != nil {
 		_ = h.mediaRepo.DeleteMediaAsset(c.Request.Context(), asset.ID)
-		_ = removeFileQuietly(asset.StoragePath)
+		h.removeStoredMediaFile(c.Request.Context(), asset.StoragePath)
 		if sourceResult != nil {
-			_ = removeFileQuietly(sourceResult.StoragePath)
+			_ = removeMediaFile(c.Request.Context(), h.mediaService, sourceResult.StoragePath)
 		}
 		if errors.Is(err, repository.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"message": "fansubgruppe nicht gefunden"}})
@@ -322,6 +323,18 @@ func groupMediaFileProxyURL(storagePath string) string {
 	return "/api/v1/media/files/" + url.PathEscape(filename)
 }
 
+// discardFansubGroupMediaFiles verwirft die Dateien eines fehlgeschlagenen Gruppen-Uploads. Eine
+// offene Transaktion wird vorher zurückgerollt, damit sie den Blob nicht mehr sperrt. Das Thumbnail
+// wird nur mit dem Original entfernt, da es bei geteilten Blobs zu allen Assets gehört.
+func (h *FansubHandler) discardFansubGroupMediaFiles(ctx context.Context, tx pgx.Tx, originalPath string, thumbPath string) {
+	if tx != nil {
+		_ = tx.Rollback(ctx)
+	}
+	if h.removeStoredMediaFile(ctx, originalPath) {
+		_ = removeMediaFile(ctx, h.mediaService, thumbPath)
+	}
+}
+
 func (h *FansubHandler) uploadFansubGroupMedia(c *gin.Context, identity middleware.AuthIdentity, fansubID int64) {
 	exists, err := h.mediaRepo.FansubGroupExistsForMedia(c.Request.Context(), fansubID)
 	if err != nil {
@@ -437,7 +450,7 @@ func (h *FansubHandler) processOneFansubGroupMediaFile(
 	if len(data) > rvmMaxFileSizeBytes {
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "FILE_TOO_LARGE", Message: "bild ist zu groß (max 15MB)"}
 	}
-	saveResult, err := h.mediaService.SaveUpload(models.MediaKindImage, clientName, data)
+	saveResult, err := h.mediaService.SaveUpload(c.Request.Context(), models.MediaKindImage, clientName, data)
 	if err != nil {
 		var validationErr *services.MediaValidationError
 		if errors.As(err, &validationErr) {
@@ -448,14 +461,17 @@ func (h *FansubHandler) processOneFansubGroupMediaFile(
 	}
 	thumbData, thumbWidth, thumbHeight, err := generateRVMThumbnail(data, saveResult.CreateInput.MimeType)
 	if err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
+		h.discardFansubGroupMediaFiles(c.Request.Context(), nil, saveResult.CreateInput.StoragePath, "")
 		log.Printf("fansub group media thumbnail error for %s: %v", clientName, err)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "THUMBNAIL_FAILED", Message: "thumbnail konnte nicht erzeugt werden"}
 	}
 	thumbPath := groupMediaThumbPath(saveResult.CreateInput.StoragePath)
-	if err := os.WriteFile(thumbPath, thumbData, 0o644); err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+	// Bei einem bereits vorhandenen Blob existiert auch dessen Thumbnail schon.
+	if !saveResult.Deduplicated || !h.mediaService.StoredFileExists(c.Request.Context(), thumbPath) {
+		err = h.mediaService.StoreFile(c.Request.Context(), thumbPath, thumbData, "image/jpeg")
+	}
+	if err != nil {
+		h.discardFansubGroupMediaFiles(c.Request.Context(), nil, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "STORAGE_FAILED", Message: "thumbnail konnte nicht gespeichert werden"}
 	}
 	saveResult.CreateInput.VisibilityCode = &visibilityCode
@@ -464,18 +480,27 @@ func (h *FansubHandler) processOneFansubGroupMediaFile(
 	ctx := c.Request.Context()
 	tx, err := h.mediaRepo.BeginTx(ctx)
 	if err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, nil, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "transaktion konnte nicht gestartet werden"}
 	}
 	defer tx.Rollback(ctx)
 
 	mediaAsset, err := h.mediaRepo.CreateMediaAssetWithStatusTx(ctx, tx, saveResult.CreateInput, "processing")
 	if err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "media asset konnte nicht erstellt werden"}
 	}
+	// Die Blob-Zeile ist jetzt gesperrt: erst hier ist verlässlich, dass Original und Thumbnail
+	// nicht von einem parallelen Löschen desselben Inhalts entfernt wurden.
+	err = saveResult.EnsureStored(ctx)
+	if err == nil && !h.mediaService.StoredFileExists(ctx, thumbPath) {
+		err = h.mediaService.StoreFile(ctx, thumbPath, thumbData, "image/jpeg")
+	}
+	if err != nil {
+		log.Printf("fansub group media upload: ensure blob failed (fansub_id=%d): %v", fansubID, err)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
+		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "STORAGE_FAILED", Message: "datei konnte nicht gespeichert werden"}
+	}
 	width, height := 0, 0
 	if saveResult.CreateInput.Width != nil {
 		width = *saveResult.CreateInput.Width
@@ -484,13 +509,11 @@ func (h *FansubHandler) processOneFansubGroupMediaFile(
 		height = *saveResult.CreateInput.Height
 	}
 	if err := h.mediaRepo.InsertMediaFileWithStatus(ctx, tx, mediaAsset.ID, "original", saveResult.CreateInput.StoragePath, width, height, int64(len(data)), "processing"); err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "media file konnte nicht erstellt werden"}
 	}
 	if err := h.mediaRepo.InsertMediaFileWithStatus(ctx, tx, mediaAsset.ID, "thumb", thumbPath, thumbWidth, thumbHeight, int64(len(thumbData)), "processing"); err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "thumbnail file konnte nicht erstellt werden"}
 	}
 	uploadedBy := uploadedByUserID
@@ -501,23 +524,19 @@ func (h *FansubHandler) processOneFansubGroupMediaFile(
 		SortOrder:        sortOrder,
 		UploadedByUserID: &uploadedBy,
 	}); err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "gruppenmedium konnte nicht erstellt werden"}
 	}
 	if err := h.mediaRepo.UpdateMediaAssetStatusRVMTx(ctx, tx, mediaAsset.ID, "ready"); err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "media asset status konnte nicht gesetzt werden"}
 	}
 	if err := h.mediaRepo.UpdateMediaFileStatusRVMTx(ctx, tx, mediaAsset.ID, "ready"); err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "media file status konnte nicht gesetzt werden"}
 	}
 	if err := tx.Commit(ctx); err != nil {
-		_ = removeFileQuietly(saveResult.CreateInput.StoragePath)
-		_ = removeFileQuietly(thumbPath)
+		h.discardFansubGroupMediaFiles(ctx, tx, saveResult.CreateInput.StoragePath, thumbPath)
 		return fansubGroupMediaFileResult{ClientFileName: clientName, Status: "failed", ErrorCode: "DB_FAILED", Message: "transaktion konnte nicht committed werden"}
 	}
 
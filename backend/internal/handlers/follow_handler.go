@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// followRepository definiert die Datenbankoperationen des FollowHandlers.
+type followRepository interface {
+	FollowFansubGroup(ctx context.Context, appUserID int64, ref models.FansubGroupRef) (*models.FollowState, error)
+	UnfollowFansubGroup(ctx context.Context, appUserID int64, ref models.FansubGroupRef) (*models.FollowState, error)
+	FollowAnime(ctx context.Context, appUserID int64, animeID int64) (*models.FollowState, error)
+	UnfollowAnime(ctx context.Context, appUserID int64, animeID int64) (*models.FollowState, error)
+	ListFollows(ctx context.Context, appUserID int64) (*models.FollowList, error)
+	ListFeed(ctx context.Context, appUserID int64, cursor *models.FeedCursor, limit int) ([]models.FeedItem, error)
+}
+
+// FollowHandler verwaltet Follows auf Fansub-Gruppen und Anime sowie den Feed unter /me/feed.
+type FollowHandler struct {
+	repo followRepository
+}
+
+// NewFollowHandler erstellt einen neuen FollowHandler.
+func NewFollowHandler(repo followRepository) *FollowHandler {
+	return &FollowHandler{repo: repo}
+}
+
+// ListFollows verarbeitet GET /api/v1/me/follows.
+func (h *FollowHandler) ListFollows(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.repo.ListFollows(c.Request.Context(), identity.AppUserID)
+	if err != nil {
+		log.Printf("follow: list failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// FollowFansubGroup verarbeitet POST /api/v1/me/follows/fansubs/:group (ID oder Slug).
+func (h *FollowHandler) FollowFansubGroup(c *gin.Context) {
+	h.changeFansubGroupFollow(c, true)
+}
+
+// UnfollowFansubGroup verarbeitet DELETE /api/v1/me/follows/fansubs/:group (ID oder Slug).
+func (h *FollowHandler) UnfollowFansubGroup(c *gin.Context) {
+	h.changeFansubGroupFollow(c, false)
+}
+
+// FollowAnime verarbeitet POST /api/v1/me/follows/anime/:id.
+func (h *FollowHandler) FollowAnime(c *gin.Context) {
+	h.changeAnimeFollow(c, true)
+}
+
+// UnfollowAnime verarbeitet DELETE /api/v1/me/follows/anime/:id.
+func (h *FollowHandler) UnfollowAnime(c *gin.Context) {
+	h.changeAnimeFollow(c, false)
+}
+
+// Feed verarbeitet GET /api/v1/me/feed mit cursor-basierter Paginierung (neueste zuerst).
+func (h *FollowHandler) Feed(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	limit, err := parsePositiveInt(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		badRequest(c, "ungültiger limit parameter")
+		return
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	var cursor *models.FeedCursor
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		parsed, err := models.ParseFeedCursor(raw)
+		if err != nil {
+			badRequest(c, "ungültiger cursor parameter")
+			return
+		}
+		cursor = &parsed
+	}
+
+	// Ein Eintrag mehr als angefordert zeigt an, ob eine weitere Seite existiert.
+	items, err := h.repo.ListFeed(c.Request.Context(), identity.AppUserID, cursor, limit+1)
+	if err != nil {
+		log.Printf("follow: feed failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	meta := models.FeedMeta{}
+	if len(items) > limit {
+		items = items[:limit]
+		next := models.FeedCursorAfter(items[len(items)-1]).Encode()
+		meta.NextCursor = &next
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items, "meta": meta})
+}
+
+func (h *FollowHandler) changeFansubGroupFollow(c *gin.Context, follow bool) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	ref, ok := parseFansubGroupRef(c.Param("group"))
+	if !ok {
+		badRequest(c, "ungültige fansub id oder slug")
+		return
+	}
+
+	var state *models.FollowState
+	var err error
+	if follow {
+		state, err = h.repo.FollowFansubGroup(c.Request.Context(), identity.AppUserID, ref)
+	} else {
+		state, err = h.repo.UnfollowFansubGroup(c.Request.Context(), identity.AppUserID, ref)
+	}
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "fansubgruppe nicht gefunden")
+		return
+	case err != nil:
+		log.Printf("follow: fansub group change failed (app_user_id=%d, id=%d, slug=%q): %v", identity.AppUserID, ref.ID, ref.Slug, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": state})
+}
+
+func (h *FollowHandler) changeAnimeFollow(c *gin.Context, follow bool) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	animeID, err := parsePositiveID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige anime id")
+		return
+	}
+
+	var state *models.FollowState
+	if follow {
+		state, err = h.repo.FollowAnime(c.Request.Context(), identity.AppUserID, animeID)
+	} else {
+		state, err = h.repo.UnfollowAnime(c.Request.Context(), identity.AppUserID, animeID)
+	}
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "anime nicht gefunden")
+		return
+	case err != nil:
+		log.Printf("follow: anime change failed (app_user_id=%d, anime_id=%d): %v", identity.AppUserID, animeID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": state})
+}
+
+// parseFansubGroupRef liest eine Gruppen-ID oder, falls nicht numerisch, einen Slug.
+func parseFansubGroupRef(raw string) (models.FansubGroupRef, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len([]rune(raw)) > 120 {
+		return models.FansubGroupRef{}, false
+	}
+	if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if id <= 0 {
+			return models.FansubGroupRef{}, false
+		}
+		return models.FansubGroupRef{ID: id}, true
+	}
+	return models.FansubGroupRef{Slug: raw}, true
+}
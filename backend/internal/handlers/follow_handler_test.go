@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubFollowRepository struct {
+	lastRef    models.FansubGroupRef
+	lastCursor *models.FeedCursor
+	lastLimit  int
+	feed       []models.FeedItem
+}
+
+func (s *stubFollowRepository) FollowFansubGroup(_ context.Context, _ int64, ref models.FansubGroupRef) (*models.FollowState, error) {
+	s.lastRef = ref
+	if ref.Slug == "missing" {
+		return nil, repository.ErrNotFound
+	}
+	return &models.FollowState{TargetType: "fansub_group", TargetID: 3, Following: true, FollowerCount: 1}, nil
+}
+
+func (s *stubFollowRepository) UnfollowFansubGroup(_ context.Context, _ int64, ref models.FansubGroupRef) (*models.FollowState, error) {
+	s.lastRef = ref
+	return &models.FollowState{TargetType: "fansub_group", TargetID: ref.ID}, nil
+}
+
+func (s *stubFollowRepository) FollowAnime(_ context.Context, _ int64, animeID int64) (*models.FollowState, error) {
+	return &models.FollowState{TargetType: "anime", TargetID: animeID, Following: true, FollowerCount: 1}, nil
+}
+
+func (s *stubFollowRepository) UnfollowAnime(_ context.Context, _ int64, animeID int64) (*models.FollowState, error) {
+	return &models.FollowState{TargetType: "anime", TargetID: animeID}, nil
+}
+
+func (s *stubFollowRepository) ListFollows(context.Context, int64) (*models.FollowList, error) {
+	return &models.FollowList{}, nil
+}
+
+func (s *stubFollowRepository) ListFeed(_ context.Context, _ int64, cursor *models.FeedCursor, limit int) ([]models.FeedItem, error) {
+	s.lastCursor = cursor
+	s.lastLimit = limit
+	if len(s.feed) > limit {
+		return s.feed[:limit], nil
+	}
+	return s.feed, nil
+}
+
+func TestFollowHandlerResolvesGroupByIDOrSlug(t *testing.T) {
+	repo := &stubFollowRepository{}
+	handler := NewFollowHandler(repo)
+
+	c, rec := newNotificationTestContext(http.MethodPost, "/api/v1/me/follows/fansubs/42", "")
+	c.Params = gin.Params{{Key: "group", Value: "42"}}
+	handler.FollowFansubGroup(c)
+	if rec.Code != http.StatusOK || repo.lastRef.ID != 42 || repo.lastRef.Slug != "" {
+		t.Fatalf("expected follow by id, got %d %+v", rec.Code, repo.lastRef)
+	}
+
+	c, rec = newNotificationTestContext(http.MethodPost, "/api/v1/me/follows/fansubs/gruppe-x", "")
+	c.Params = gin.Params{{Key: "group", Value: "gruppe-x"}}
+	handler.FollowFansubGroup(c)
+	if rec.Code != http.StatusOK || repo.lastRef.Slug != "gruppe-x" {
+		t.Fatalf("expected follow by slug, got %d %+v", rec.Code, repo.lastRef)
+	}
+
+	c, rec = newNotificationTestContext(http.MethodPost, "/api/v1/me/follows/fansubs/missing", "")
+	c.Params = gin.Params{{Key: "group", Value: "missing"}}
+	handler.FollowFansubGroup(c)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown group, got %d", rec.Code)
+	}
+
+	c, rec = newNotificationTestContext(http.MethodDelete, "/api/v1/me/follows/fansubs/0", "")
+	c.Params = gin.Params{{Key: "group", Value: "0"}}
+	handler.UnfollowFansubGroup(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for id 0, got %d", rec.Code)
+	}
+}
+
+func TestFollowHandlerFeedPaginatesWithCursor(t *testing.T) {
+	base := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubFollowRepository{feed: []models.FeedItem{
+		{Kind: models.FeedKindReleaseVersion, ID: 9, OccurredAt: base},
+		{Kind: models.FeedKindMemberStory, ID: 4, OccurredAt: base.Add(-time.Hour)},
+		{Kind: models.FeedKindGroupHistory, ID: 2, OccurredAt: base.Add(-2 * time.Hour)},
+	}}
+	handler := NewFollowHandler(repo)
+
+	c, rec := newNotificationTestContext(http.MethodGet, "/api/v1/me/feed?limit=2", "")
+	handler.Feed(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d with body %s", rec.Code, rec.Body.String())
+	}
+	if repo.lastLimit != 3 {
+		t.Fatalf("expected one extra item to be requested, got limit %d", repo.lastLimit)
+	}
+
+	var body struct {
+		Data []models.FeedItem `json:"data"`
+		Meta models.FeedMeta   `json:"meta"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Data) != 2 || body.Meta.NextCursor == nil {
+		t.Fatalf("expected two items and a next cursor, got %+v", body)
+	}
+
+	c, rec = newNotificationTestContext(http.MethodGet, "/api/v1/me/feed?limit=2&cursor="+*body.Meta.NextCursor, "")
+	handler.Feed(c)
+	if rec.Code != http.StatusOK || repo.lastCursor == nil {
+		t.Fatalf("expected cursor to be passed on, got %d", rec.Code)
+	}
+	if repo.lastCursor.Kind != models.FeedKindMemberStory || repo.lastCursor.ID != 4 || !repo.lastCursor.OccurredAt.Equal(base.Add(-time.Hour)) {
+		t.Fatalf("expected cursor after the second item, got %+v", repo.lastCursor)
+	}
+
+	c, rec = newNotificationTestContext(http.MethodGet, "/api/v1/me/feed?cursor=kaputt", "")
+	handler.Feed(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for broken cursor, got %d", rec.Code)
+	}
+}
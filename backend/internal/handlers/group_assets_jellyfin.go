@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/models"
 )
 
@@ -559,8 +560,13 @@ func durationSecondsFromTicks(value *int64) *int32 {
 
 // buildGroupMediaImageURL erstellt die interne Proxy-URL für ein Jellyfin-Bild.
 func buildGroupMediaImageURL(itemID string, kind string, index *int) string {
+	return buildMediaProxyImageURL(mediaserver.KindJellyfin, itemID, kind, index)
+}
+
+// buildMediaProxyImageURL erstellt die interne Proxy-URL (/api/v1/media/image) für ein Bild des Media-Servers provider.
+func buildMediaProxyImageURL(provider string, itemID string, kind string, index *int) string {
 	values := url.Values{}
-	values.Set("provider", "jellyfin")
+	values.Set("provider", provider)
 	values.Set("item_id", itemID)
 	values.Set("kind", kind)
 	if index != nil {
@@ -26,6 +26,6 @@ func (h *AdminContentHandler) previewJellysyncAnimeCreateFollowup(
 		themeVideoIDs = nil
 	}
 
-	preview := buildAdminJellyfinIntakePreviewResult(*detail, themeVideoIDs)
+	preview := buildAdminJellyfinIntakePreviewResult(h.catalogMediaServer().Kind(), *detail, themeVideoIDs)
 	return services.BuildJellysyncFollowupResult(draft, preview), nil
 }
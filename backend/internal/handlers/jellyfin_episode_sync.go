@@ -83,7 +83,7 @@ func (h *AdminContentHandler) SyncEpisodeFromJellyfin(c *gin.Context) {
 		return
 	}
 
-	fansubGroupID := h.resolveJellyfinEpisodeFansubGroupID(c, animeID, targetEpisode)
+	fansubGroupID := h.resolveJellyfinEpisodeFansubGroupID(c.Request.Context(), animeID, targetEpisode)
 	deletedCount, ok := h.cleanupJellyfinEpisodeProviderVersions(c, identity.UserID, animeID, episodeNumber, input.CleanupProviderVersions)
 	if !ok {
 		return
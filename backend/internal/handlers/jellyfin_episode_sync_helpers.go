@@ -1,6 +1,7 @@
 ﻿package handlers
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net/http"
@@ -101,7 +102,7 @@ func findTargetJellyfinEpisode(
 }
 
 func (h *AdminContentHandler) resolveJellyfinEpisodeFansubGroupID(
-	c *gin.Context,
+	ctx context.Context,
 	animeID int64,
 	targetEpisode *jellyfinEpisodeItem,
 ) *int64 {
@@ -110,7 +111,7 @@ func (h *AdminContentHandler) resolveJellyfinEpisodeFansubGroupID(
 	}
 
 	aliasResolver := newFansubAliasResolver(nil)
-	candidates, aliasErr := h.fansubRepo.ListAnimeAliasCandidates(c.Request.Context(), animeID)
+	candidates, aliasErr := h.fansubRepo.ListAnimeAliasCandidates(ctx, animeID)
 	if aliasErr != nil {
 		return nil
 	}
@@ -134,7 +135,7 @@ func (h *AdminContentHandler) cleanupJellyfinEpisodeProviderVersions(
 		c.Request.Context(),
 		animeID,
 		episodeNumber,
-		"jellyfin",
+		h.catalogMediaServer().Kind(),
 	)
 	if deleteErr != nil {
 		log.Printf(
@@ -171,15 +172,12 @@ func (h *AdminContentHandler) upsertJellyfinEpisode(
 	targetEpisode *jellyfinEpisodeItem,
 	input adminAnimeJellyfinSyncInput,
 ) (*string, bool, bool) {
-	episodeNumberText := strconv.Itoa(int(episodeNumber))
-	episodeTitle := normalizeNullableStringPtr(targetEpisode.Name)
-	_, episodeCreated, upsertEpisodeErr := h.repo.UpsertEpisodeByAnimeAndNumber(
+	episodeTitle, episodeCreated, upsertEpisodeErr := h.upsertJellyfinEpisodeRecord(
 		c.Request.Context(),
 		animeID,
-		episodeNumberText,
-		episodeTitle,
+		episodeNumber,
+		targetEpisode,
 		input.EpisodeStatus,
-		false,
 	)
 	if upsertEpisodeErr != nil {
 		log.Printf(
@@ -200,6 +198,30 @@ func (h *AdminContentHandler) upsertJellyfinEpisode(
 	return episodeTitle, episodeCreated, true
 }
 
+// upsertJellyfinEpisodeRecord legt die Episode zur Jellyfin-Episode an oder aktualisiert sie;
+// gemeinsamer Kern des Einzel-Syncs und der Webhook-Übernahme.
+func (h *AdminContentHandler) upsertJellyfinEpisodeRecord(
+	ctx context.Context,
+	animeID int64,
+	episodeNumber int32,
+	targetEpisode *jellyfinEpisodeItem,
+	episodeStatus string,
+) (*string, bool, error) {
+	episodeTitle := normalizeNullableStringPtr(targetEpisode.Name)
+	_, episodeCreated, err := h.repo.UpsertEpisodeByAnimeAndNumber(
+		ctx,
+		animeID,
+		strconv.Itoa(int(episodeNumber)),
+		episodeTitle,
+		episodeStatus,
+		false,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	return episodeTitle, episodeCreated, nil
+}
+
 func (h *AdminContentHandler) upsertJellyfinEpisodeVersion(
 	c *gin.Context,
 	animeID int64,
@@ -209,22 +231,14 @@ func (h *AdminContentHandler) upsertJellyfinEpisodeVersion(
 	fansubGroupID *int64,
 	targetEpisode *jellyfinEpisodeItem,
 ) (bool, bool) {
-	_, versionCreated, upsertVersionErr := h.episodeVersionRepo.UpsertByMediaSource(
+	versionCreated, upsertVersionErr := h.upsertJellyfinEpisodeVersionRecord(
 		c.Request.Context(),
-		models.EpisodeVersionCreateInput{
-			AnimeID:         animeID,
-			EpisodeNumber:   episodeNumber,
-			Title:           episodeTitle,
-			FansubGroupID:   fansubGroupID,
-			MediaProvider:   "jellyfin",
-			MediaItemID:     mediaItemID,
-			VideoQuality:    jellyfinVideoQuality(targetEpisode.MediaStreams),
-			SubtitleType:    nil,
-			ReleaseDate:     parseJellyfinPremiereDate(targetEpisode.PremiereDate),
-			StreamURL:       h.buildJellyfinEditorStreamURL(mediaItemID),
-			DurationSeconds: durationSecondsFromTicks(targetEpisode.RunTimeTicks),
-		},
-		false,
+		animeID,
+		episodeNumber,
+		mediaItemID,
+		episodeTitle,
+		fansubGroupID,
+		targetEpisode,
 	)
 	if upsertVersionErr != nil {
 		log.Printf(
@@ -244,3 +258,37 @@ func (h *AdminContentHandler) upsertJellyfinEpisodeVersion(
 
 	return versionCreated, true
 }
+
+// upsertJellyfinEpisodeVersionRecord legt die Provider-Version der Jellyfin-Episode an oder
+// aktualisiert sie.
+func (h *AdminContentHandler) upsertJellyfinEpisodeVersionRecord(
+	ctx context.Context,
+	animeID int64,
+	episodeNumber int32,
+	mediaItemID string,
+	episodeTitle *string,
+	fansubGroupID *int64,
+	targetEpisode *jellyfinEpisodeItem,
+) (bool, error) {
+	_, versionCreated, err := h.episodeVersionRepo.UpsertByMediaSource(
+		ctx,
+		models.EpisodeVersionCreateInput{
+			AnimeID:         animeID,
+			EpisodeNumber:   episodeNumber,
+			Title:           episodeTitle,
+			FansubGroupID:   fansubGroupID,
+			MediaProvider:   h.catalogMediaServer().Kind(),
+			MediaItemID:     mediaItemID,
+			VideoQuality:    jellyfinVideoQuality(targetEpisode.MediaStreams),
+			SubtitleType:    nil,
+			ReleaseDate:     parseJellyfinPremiereDate(targetEpisode.PremiereDate),
+			StreamURL:       h.buildJellyfinEditorStreamURL(mediaItemID),
+			DurationSeconds: durationSecondsFromTicks(targetEpisode.RunTimeTicks),
+		},
+		false,
+	)
+	if err != nil {
+		return false, err
+	}
+	return versionCreated, nil
+}
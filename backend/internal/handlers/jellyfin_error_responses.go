@@ -64,13 +64,16 @@ func classifyJellyfinUpstreamError(err error, fallbackMessage string) (string, s
 		details := "Die Jellyfin-Anfrage hat das Timeout erreicht. Bitte Netzwerkpfad, Serverlast und Timeout-Konfiguration prüfen."
 		return "server nicht erreichbar", "jellyfin_unreachable", &details
 	case strings.Contains(normalized, "call jellyfin:"),
+		strings.Contains(normalized, "call emby:"),
 		strings.Contains(normalized, "connection refused"),
 		strings.Contains(normalized, "no such host"),
 		strings.Contains(normalized, "network is unreachable"):
 		details := "Die Verbindung zu Jellyfin konnte nicht aufgebaut werden. Bitte Host, Port, DNS und Erreichbarkeit pruefen."
 		return "server nicht erreichbar", "jellyfin_unreachable", &details
 	case strings.Contains(normalized, "decode jellyfin response"),
-		strings.Contains(normalized, "read jellyfin response"):
+		strings.Contains(normalized, "read jellyfin response"),
+		strings.Contains(normalized, "decode emby response"),
+		strings.Contains(normalized, "read emby response"):
 		details := "Die Jellyfin-Antwort konnte nicht gelesen oder ausgewertet werden."
 		return "ungültige antwort von jellyfin", "jellyfin_invalid_response", &details
 	default:
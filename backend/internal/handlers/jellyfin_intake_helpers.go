@@ -11,6 +11,7 @@ import (
 
 // buildAdminJellyfinIntakeSearchItems erstellt eine sortierte Liste von Intake-Suchtreffern aus Jellyfin-Ergebnissen.
 func buildAdminJellyfinIntakeSearchItems(
+	provider string,
 	items []jellyfinSeriesItem,
 	query string,
 	existingMatches []repository.ExistingJellyfinAnimeMatch,
@@ -26,7 +27,7 @@ func buildAdminJellyfinIntakeSearchItems(
 		if strings.TrimSpace(item.ID) == "" {
 			continue
 		}
-		candidate, score := buildAdminJellyfinIntakeSearchItem(item, query, existingBySource, existingByFolder)
+		candidate, score := buildAdminJellyfinIntakeSearchItem(provider, item, query, existingBySource, existingByFolder)
 		scored = append(scored, scoredCandidate{score: score, candidate: candidate})
 	}
 
@@ -47,6 +48,7 @@ func buildAdminJellyfinIntakeSearchItems(
 
 // buildAdminJellyfinIntakeSearchItem erstellt einen einzelnen Intake-Suchtreffer aus einem Jellyfin-Serien-Item.
 func buildAdminJellyfinIntakeSearchItem(
+	provider string,
 	item jellyfinSeriesItem,
 	query string,
 	existingBySource map[string]repository.ExistingJellyfinAnimeMatch,
@@ -67,10 +69,10 @@ func buildAdminJellyfinIntakeSearchItem(
 		LibraryContext:   libraryContext,
 		Confidence:       confidence,
 		TypeHint:         typeHint,
-		PosterURL:        normalizeNullableStringPtr(buildGroupMediaImageURL(seriesID, "primary", nil)),
-		BannerURL:        normalizeNullableStringPtr(buildGroupMediaImageURL(seriesID, "banner", nil)),
-		LogoURL:          normalizeNullableStringPtr(buildGroupMediaImageURL(seriesID, "logo", nil)),
-		BackgroundURL:    normalizeNullableStringPtr(buildGroupMediaImageURL(seriesID, "backdrop", nil)),
+		PosterURL:        normalizeNullableStringPtr(buildMediaProxyImageURL(provider, seriesID, "primary", nil)),
+		BannerURL:        normalizeNullableStringPtr(buildMediaProxyImageURL(provider, seriesID, "banner", nil)),
+		LogoURL:          normalizeNullableStringPtr(buildMediaProxyImageURL(provider, seriesID, "logo", nil)),
+		BackgroundURL:    normalizeNullableStringPtr(buildMediaProxyImageURL(provider, seriesID, "backdrop", nil)),
 	}
 	if match != nil {
 		result.AlreadyImported = true
@@ -118,6 +120,7 @@ func resolveExistingJellyfinIntakeMatch(
 
 // buildAdminJellyfinIntakePreviewResult erstellt das vollständige Intake-Vorschau-Ergebnis aus Jellyfin-Seriendetails.
 func buildAdminJellyfinIntakePreviewResult(
+	provider string,
 	detail jellyfinSeriesDetailItem,
 	themeVideoIDs []string,
 ) models.AdminJellyfinIntakePreviewResult {
@@ -140,11 +143,11 @@ func buildAdminJellyfinIntakePreviewResult(
 		AniDBID:             extractAniDBID(detail.ProviderIDs),
 		TypeHint:            typeHint,
 		AssetSlots: models.AdminJellyfinIntakeAssetSlots{
-			Cover:           buildJellyfinIntakeAssetSlot("cover", buildGroupMediaImageURL(seriesID, "primary", nil), hasImageTag(detail.ImageTags, "Primary")),
-			Logo:            buildJellyfinIntakeAssetSlot("logo", buildGroupMediaImageURL(seriesID, "logo", nil), hasImageTag(detail.ImageTags, "Logo")),
-			Banner:          buildJellyfinIntakeAssetSlot("banner", buildGroupMediaImageURL(seriesID, "banner", nil), hasImageTag(detail.ImageTags, "Banner")),
-			Backgrounds:     buildJellyfinIntakeBackgroundSlots(seriesID, len(detail.BackdropImageTags)),
-			BackgroundVideo: buildJellyfinIntakeAssetSlot("background_video", buildAnimeBackdropVideoProxyURL(firstNonEmptyString(themeVideoIDs...)), len(themeVideoIDs) > 0),
+			Cover:           buildJellyfinIntakeAssetSlot("cover", buildMediaProxyImageURL(provider, seriesID, "primary", nil), hasImageTag(detail.ImageTags, "Primary")),
+			Logo:            buildJellyfinIntakeAssetSlot("logo", buildMediaProxyImageURL(provider, seriesID, "logo", nil), hasImageTag(detail.ImageTags, "Logo")),
+			Banner:          buildJellyfinIntakeAssetSlot("banner", buildMediaProxyImageURL(provider, seriesID, "banner", nil), hasImageTag(detail.ImageTags, "Banner")),
+			Backgrounds:     buildJellyfinIntakeBackgroundSlots(provider, seriesID, len(detail.BackdropImageTags)),
+			BackgroundVideo: buildJellyfinIntakeAssetSlot("background_video", buildAnimeBackdropVideoProxyURL(provider, firstNonEmptyString(themeVideoIDs...)), len(themeVideoIDs) > 0),
 		},
 	}
 }
@@ -183,7 +186,7 @@ func buildJellyfinIntakeAssetSlot(kind string, rawURL string, present bool) mode
 }
 
 // buildJellyfinIntakeBackgroundSlots erstellt eine Liste von Hintergrund-Asset-Slots für die gegebene Anzahl von Backdrops.
-func buildJellyfinIntakeBackgroundSlots(seriesID string, count int) []models.AdminJellyfinIntakeAssetSlot {
+func buildJellyfinIntakeBackgroundSlots(provider string, seriesID string, count int) []models.AdminJellyfinIntakeAssetSlot {
 	if count <= 0 {
 		return []models.AdminJellyfinIntakeAssetSlot{}
 	}
@@ -191,7 +194,7 @@ func buildJellyfinIntakeBackgroundSlots(seriesID string, count int) []models.Adm
 	result := make([]models.AdminJellyfinIntakeAssetSlot, 0, count)
 	for i := 0; i < count; i++ {
 		index := i
-		slot := buildJellyfinIntakeAssetSlot("background", buildAnimeBackdropProxyURL(seriesID, &index), true)
+		slot := buildJellyfinIntakeAssetSlot("background", buildAnimeBackdropProxyURL(provider, seriesID, &index), true)
 		slot.Index = &index
 		result = append(result, slot)
 	}
@@ -73,6 +73,6 @@ func (h *AdminContentHandler) PreviewAnimeIntakeFromJellyfin(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": buildAdminJellyfinIntakePreviewResult(*detail, themeVideoIDs),
+		"data": buildAdminJellyfinIntakePreviewResult(h.catalogMediaServer().Kind(), *detail, themeVideoIDs),
 	})
 }
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/models"
 )
 
@@ -23,7 +24,7 @@ func TestBuildAdminJellyfinIntakePreviewResult_ReturnsMetadataSlotsAndReasons(t
 		BackdropImageTags: []string{"bg-1"},
 	}
 
-	result := buildAdminJellyfinIntakePreviewResult(detail, []string{"video-1"})
+	result := buildAdminJellyfinIntakePreviewResult(mediaserver.KindJellyfin, detail, []string{"video-1"})
 	if result.Description == nil || *result.Description != "Leaf village side story" {
 		t.Fatalf("expected description, got %+v", result.Description)
 	}
@@ -59,7 +60,7 @@ func TestBuildAdminJellyfinIntakePreviewResult_ReturnsMetadataSlotsAndReasons(t
 func TestBuildAdminJellyfinIntakePreviewResult_UsesExplicitEmptySlots(t *testing.T) {
 	t.Parallel()
 
-	result := buildAdminJellyfinIntakePreviewResult(jellyfinSeriesDetailItem{
+	result := buildAdminJellyfinIntakePreviewResult(mediaserver.KindJellyfin, jellyfinSeriesDetailItem{
 		ID:   "series-2",
 		Name: "Naruto",
 		Path: `D:\Anime\TV\Naruto`,
@@ -80,7 +81,7 @@ func TestBuildAdminJellyfinIntakePreviewResult_UsesExplicitEmptySlots(t *testing
 func TestBuildAdminJellyfinIntakePreviewResult_RecognizesOVAFromPathSegments(t *testing.T) {
 	t.Parallel()
 
-	result := buildAdminJellyfinIntakePreviewResult(jellyfinSeriesDetailItem{
+	result := buildAdminJellyfinIntakePreviewResult(mediaserver.KindJellyfin, jellyfinSeriesDetailItem{
 		ID:   "series-macross",
 		Name: "Macross",
 		Path: `/media/Anime/OVA/Anime.OVA.Sub/Macross Flash Back 2012`,
@@ -339,7 +339,7 @@ func (h *AdminContentHandler) buildAnimeJellyfinContext(
 	result.SourceKind = "jellyfin"
 	result.JellyfinSeriesID = stringPtrFromValue(seriesID)
 
-	if strings.TrimSpace(h.jellyfinBaseURL) == "" || strings.TrimSpace(h.jellyfinAPIKey) == "" {
+	if !h.catalogMediaServer().Configured() {
 		return result, http.StatusOK, nil
 	}
 
@@ -356,7 +356,7 @@ func (h *AdminContentHandler) buildAnimeJellyfinContext(
 		themeVideoIDs = nil
 	}
 
-	preview := buildAdminJellyfinIntakePreviewResult(*detail, themeVideoIDs)
+	preview := buildAdminJellyfinIntakePreviewResult(h.catalogMediaServer().Kind(), *detail, themeVideoIDs)
 	result.JellyfinSeriesName = stringPtrFromValue(strings.TrimSpace(detail.Name))
 	result.JellyfinSeriesPath = normalizeNullableStringPtr(detail.Path)
 	result.AssetSlots = &preview.AssetSlots
@@ -396,13 +396,8 @@ func (h *AdminContentHandler) buildAnimeJellyfinMetadataPreview(
 		themeVideoIDs = nil
 	}
 
-	intakePreview := buildAdminJellyfinIntakePreviewResult(*detail, themeVideoIDs)
-	diff := []models.AdminAnimeJellyfinMetadataFieldPreview{
-		buildMetadataFieldPreview("source", "Quelle", animeSource.Source, stringPtrFromValue("jellyfin:"+strings.TrimSpace(series.ID))),
-		buildMetadataFieldPreview("folder_name", "Ordner", animeSource.FolderName, normalizeNullableStringPtr(detail.Path)),
-		buildMetadataFieldPreview("year", "Jahr", int16ToStringPtr(animeSource.Year), int16ToStringPtr(intakePreview.Year)),
-		buildMetadataFieldPreview("description", "Beschreibung", animeSource.Description, intakePreview.Description),
-	}
+	intakePreview := buildAdminJellyfinIntakePreviewResult(h.catalogMediaServer().Kind(), *detail, themeVideoIDs)
+	diff := buildAnimeJellyfinMetadataDiff(animeSource, intakePreview)
 
 	return models.AdminAnimeJellyfinMetadataPreviewResult{
 		AnimeID:            animeSource.ID,
@@ -416,6 +411,20 @@ func (h *AdminContentHandler) buildAnimeJellyfinMetadataPreview(
 	}, http.StatusOK, nil
 }
 
+// buildAnimeJellyfinMetadataDiff vergleicht die gespeicherten Metadaten einer Anime mit der
+// Jellyfin-Serie aus intakePreview.
+func buildAnimeJellyfinMetadataDiff(
+	animeSource *models.AdminAnimeSyncSource,
+	intakePreview models.AdminJellyfinIntakePreviewResult,
+) []models.AdminAnimeJellyfinMetadataFieldPreview {
+	return []models.AdminAnimeJellyfinMetadataFieldPreview{
+		buildMetadataFieldPreview("source", "Quelle", animeSource.Source, stringPtrFromValue("jellyfin:"+intakePreview.JellyfinSeriesID)),
+		buildMetadataFieldPreview("folder_name", "Ordner", animeSource.FolderName, intakePreview.JellyfinSeriesPath),
+		buildMetadataFieldPreview("year", "Jahr", int16ToStringPtr(animeSource.Year), int16ToStringPtr(intakePreview.Year)),
+		buildMetadataFieldPreview("description", "Beschreibung", animeSource.Description, intakePreview.Description),
+	}
+}
+
 // buildMetadataFieldPreview erstellt eine Vorschau-Struktur für ein einzelnes Metadatenfeld mit Vergleich von aktuellem und eingehendem Wert.
 func buildMetadataFieldPreview(field string, label string, current *string, incoming *string) models.AdminAnimeJellyfinMetadataFieldPreview {
 	result := models.AdminAnimeJellyfinMetadataFieldPreview{
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminAnimeJellyfinSyncSettingsRequest struct {
+	AutoApply *bool `json:"auto_apply"`
+}
+
+// ListJellyfinPendingChanges verarbeitet GET /api/v1/admin/jellyfin/pending-changes mit den
+// optionalen Filtern status und anime_id.
+func (h *AdminContentHandler) ListJellyfinPendingChanges(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if !h.ensureJellyfinChangesConfigured(c) {
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	if status != "" && !models.IsJellyfinPendingChangeStatus(status) {
+		badRequest(c, "ungültiger status parameter")
+		return
+	}
+	var animeID int64
+	if raw := strings.TrimSpace(c.Query("anime_id")); raw != "" {
+		parsed, err := parseAnimeID(raw)
+		if err != nil {
+			badRequest(c, "ungültige anime id")
+			return
+		}
+		animeID = parsed
+	}
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "50"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 200 {
+		perPage = 200
+	}
+
+	items, total, err := h.jellyfinChanges.List(c.Request.Context(), models.JellyfinPendingChangeFilter{
+		Status:  status,
+		AnimeID: animeID,
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		log.Printf("admin_content jellyfin_pending_changes: list failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// ApplyJellyfinPendingChange verarbeitet POST /api/v1/admin/jellyfin/pending-changes/:changeId/apply.
+// Schlägt die Übernahme fehl, wird die Änderung mit status=failed und Fehlermeldung abgeschlossen.
+func (h *AdminContentHandler) ApplyJellyfinPendingChange(c *gin.Context) {
+	identity, change, ok := h.loadOpenJellyfinPendingChange(c)
+	if !ok {
+		return
+	}
+	if !h.ensureJellyfinConfigured(c) {
+		return
+	}
+
+	resolved, err := h.applyJellyfinPendingChange(c.Request.Context(), change, appUserIDPtr(identity.AppUserID))
+	if !h.writeJellyfinChangeResolveError(c, change.ID, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resolved})
+}
+
+// RejectJellyfinPendingChange verarbeitet POST /api/v1/admin/jellyfin/pending-changes/:changeId/reject.
+func (h *AdminContentHandler) RejectJellyfinPendingChange(c *gin.Context) {
+	identity, change, ok := h.loadOpenJellyfinPendingChange(c)
+	if !ok {
+		return
+	}
+
+	actor := appUserIDPtr(identity.AppUserID)
+	resolved, err := h.jellyfinChanges.Resolve(c.Request.Context(), change.ID, models.JellyfinPendingChangeResolution{
+		Status:              models.JellyfinPendingChangeStatusRejected,
+		ResolvedByAppUserID: actor,
+	})
+	if !h.writeJellyfinChangeResolveError(c, change.ID, err) {
+		return
+	}
+	h.writeJellyfinChangeAudit(c.Request.Context(), actor, "jellyfin_pending_change.rejected", resolved, "allowed", nil)
+	c.JSON(http.StatusOK, gin.H{"data": resolved})
+}
+
+// GetAnimeJellyfinSyncSettings verarbeitet GET /api/v1/admin/anime/:id/jellyfin/sync-settings.
+func (h *AdminContentHandler) GetAnimeJellyfinSyncSettings(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	animeID, err := parseAnimeID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige anime id")
+		return
+	}
+	if !h.ensureJellyfinChangesConfigured(c) {
+		return
+	}
+
+	settings, err := h.jellyfinChanges.GetSyncSettings(c.Request.Context(), animeID)
+	if err != nil {
+		log.Printf("admin_content jellyfin_sync_settings: load failed (anime_id=%d): %v", animeID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// UpdateAnimeJellyfinSyncSettings verarbeitet PUT /api/v1/admin/anime/:id/jellyfin/sync-settings
+// und schaltet die automatische Übernahme von Webhook-Änderungen für eine Anime um.
+func (h *AdminContentHandler) UpdateAnimeJellyfinSyncSettings(c *gin.Context) {
+	identity, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+	animeID, err := parseAnimeID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige anime id")
+		return
+	}
+	if !h.ensureJellyfinChangesConfigured(c) {
+		return
+	}
+
+	var req adminAnimeJellyfinSyncSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.AutoApply == nil {
+		badRequest(c, "auto_apply ist erforderlich")
+		return
+	}
+
+	actor := appUserIDPtr(identity.AppUserID)
+	settings, err := h.jellyfinChanges.SetAutoApply(c.Request.Context(), animeID, *req.AutoApply, actor)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "anime nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("admin_content jellyfin_sync_settings: save failed (user_id=%d, anime_id=%d): %v", identity.UserID, animeID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	_ = h.auditLogRepo.Write(c.Request.Context(), repository.AuditLogEntry{
+		ActorAppUserID:    actor,
+		ActorLegacyUserID: &identity.UserID,
+		EventType:         "anime.jellyfin_sync_settings_updated",
+		ScopeType:         "anime",
+		ScopeID:           &animeID,
+		TargetType:        "anime",
+		TargetID:          &animeID,
+		Action:            "anime.jellyfin_sync_settings_updated",
+		Outcome:           "allowed",
+		Payload:           map[string]any{"auto_apply": settings.AutoApply},
+	})
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// applyJellyfinPendingChange übernimmt eine offene Änderung mit der Logik des Episoden-Syncs
+// und schließt sie als applied bzw. failed ab. actor ist nil bei automatischer Übernahme.
+func (h *AdminContentHandler) applyJellyfinPendingChange(
+	ctx context.Context,
+	change *models.JellyfinPendingChange,
+	actor *int64,
+) (*models.JellyfinPendingChange, error) {
+	result, applyErr := h.syncJellyfinPendingChange(ctx, change)
+
+	resolution := models.JellyfinPendingChangeResolution{
+		Status:              models.JellyfinPendingChangeStatusApplied,
+		Result:              result,
+		ResolvedByAppUserID: actor,
+	}
+	eventType, outcome := "jellyfin_pending_change.applied", "allowed"
+	if applyErr != nil {
+		log.Printf("admin_content jellyfin_pending_changes: apply failed (change_id=%d, anime_id=%d): %v", change.ID, change.AnimeID, applyErr)
+		message := applyErr.Error()
+		resolution.Status = models.JellyfinPendingChangeStatusFailed
+		resolution.ErrorMessage = &message
+		eventType, outcome = "jellyfin_pending_change.failed", "failed"
+	}
+
+	resolved, err := h.jellyfinChanges.Resolve(ctx, change.ID, resolution)
+	if err != nil {
+		return nil, err
+	}
+	h.writeJellyfinChangeAudit(ctx, actor, eventType, resolved, outcome, map[string]any{
+		"auto_applied": actor == nil,
+	})
+	return resolved, nil
+}
+
+// syncJellyfinPendingChange führt die eigentliche Übernahme aus: hinzugefügte und geänderte
+// Episoden werden wie beim Einzel-Sync angelegt bzw. aktualisiert, entfernte Episoden räumen
+// die Provider-Versionen der Episodennummer ab.
+func (h *AdminContentHandler) syncJellyfinPendingChange(
+	ctx context.Context,
+	change *models.JellyfinPendingChange,
+) (map[string]any, error) {
+	provider := h.catalogMediaServer().Kind()
+
+	if change.EventType == models.JellyfinChangeEventItemRemoved {
+		// Nur die Versionen des entfernten Items löschen: dieselbe Episodennummer kann in anderen
+		// Staffeln oder Releases weiter gültige Versionen haben.
+		if h.jellyfinItemVersions == nil {
+			return nil, errors.New("episode version repository fehlt")
+		}
+		deleted, err := h.jellyfinItemVersions.DeleteByAnimeMediaItemAndProvider(ctx, change.AnimeID, change.JellyfinItemID, provider)
+		if err != nil {
+			return nil, fmt.Errorf("versionen konnten nicht gelöscht werden: %w", err)
+		}
+		result := map[string]any{"media_item_id": change.JellyfinItemID, "deleted_count": deleted}
+		if change.EpisodeNumber != nil {
+			result["episode_number"] = *change.EpisodeNumber
+		}
+		return result, nil
+	}
+
+	if h.episodeVersionRepo == nil {
+		return nil, errors.New("episode version repository fehlt")
+	}
+
+	episodes, err := h.listJellyfinEpisodes(ctx, change.JellyfinSeriesID)
+	if err != nil {
+		return nil, fmt.Errorf("jellyfin episoden konnten nicht geladen werden: %w", err)
+	}
+	var target *jellyfinEpisodeItem
+	for i := range episodes {
+		if strings.TrimSpace(episodes[i].ID) == change.JellyfinItemID {
+			target = &episodes[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, errors.New("episode nicht in jellyfin gefunden")
+	}
+	episodeNumber := jellyfinEpisodeNumber(target.IndexNumber)
+	if episodeNumber <= 0 {
+		return nil, errors.New("jellyfin episode hat keine episodennummer")
+	}
+
+	defaults, _ := validateAdminAnimeJellyfinSyncRequest(adminAnimeJellyfinSyncRequest{})
+	fansubGroupID := h.resolveJellyfinEpisodeFansubGroupID(ctx, change.AnimeID, target)
+	episodeTitle, episodeCreated, err := h.upsertJellyfinEpisodeRecord(ctx, change.AnimeID, episodeNumber, target, defaults.EpisodeStatus)
+	if err != nil {
+		return nil, fmt.Errorf("episode import fehlgeschlagen: %w", err)
+	}
+	versionCreated, err := h.upsertJellyfinEpisodeVersionRecord(ctx, change.AnimeID, episodeNumber, change.JellyfinItemID, episodeTitle, fansubGroupID, target)
+	if err != nil {
+		return nil, fmt.Errorf("version import fehlgeschlagen: %w", err)
+	}
+
+	return map[string]any{
+		"episode_number":  episodeNumber,
+		"episode_created": episodeCreated,
+		"version_created": versionCreated,
+		"media_item_id":   change.JellyfinItemID,
+	}, nil
+}
+
+func (h *AdminContentHandler) ensureJellyfinChangesConfigured(c *gin.Context) bool {
+	if h.jellyfinChanges == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "jellyfin webhook ist nicht konfiguriert"}})
+		return false
+	}
+	return true
+}
+
+func (h *AdminContentHandler) loadOpenJellyfinPendingChange(c *gin.Context) (middleware.AuthIdentity, *models.JellyfinPendingChange, bool) {
+	identity, ok := h.requireAdmin(c)
+	if !ok {
+		return middleware.AuthIdentity{}, nil, false
+	}
+	changeID, err := parsePositiveID(c.Param("changeId"))
+	if err != nil {
+		badRequest(c, "ungültige change id")
+		return middleware.AuthIdentity{}, nil, false
+	}
+	if !h.ensureJellyfinChangesConfigured(c) {
+		return middleware.AuthIdentity{}, nil, false
+	}
+
+	change, err := h.jellyfinChanges.GetByID(c.Request.Context(), changeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound(c, "änderung nicht gefunden")
+		return middleware.AuthIdentity{}, nil, false
+	}
+	if err != nil {
+		log.Printf("admin_content jellyfin_pending_changes: load failed (change_id=%d): %v", changeID, err)
+		internalError(c, "interner serverfehler")
+		return middleware.AuthIdentity{}, nil, false
+	}
+	if change.Status != models.JellyfinPendingChangeStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "änderung wurde bereits entschieden"}})
+		return middleware.AuthIdentity{}, nil, false
+	}
+	return identity, change, true
+}
+
+// writeJellyfinChangeResolveError schreibt die Fehlerantwort eines Resolve-Aufrufs; true
+// bedeutet kein Fehler.
+func (h *AdminContentHandler) writeJellyfinChangeResolveError(c *gin.Context, changeID int64, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "änderung nicht gefunden")
+	case errors.Is(err, repository.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": gin.H{"message": "änderung wurde bereits entschieden"}})
+	default:
+		log.Printf("admin_content jellyfin_pending_changes: resolve failed (change_id=%d): %v", changeID, err)
+		internalError(c, "interner serverfehler")
+	}
+	return false
+}
+
+func appUserIDPtr(appUserID int64) *int64 {
+	if appUserID <= 0 {
+		return nil
+	}
+	return &appUserID
+}
@@ -103,7 +103,7 @@ func (h *AdminContentHandler) PreviewAnimeFromJellyfin(c *gin.Context) {
 	// Count existing Jellyfin versions for this anime
 	var existingJellyfinVersions int32
 	if h.episodeVersionRepo != nil {
-		count, countErr := h.episodeVersionRepo.CountByAnimeAndProvider(c.Request.Context(), animeID, "jellyfin")
+		count, countErr := h.episodeVersionRepo.CountByAnimeAndProvider(c.Request.Context(), animeID, h.catalogMediaServer().Kind())
 		if countErr != nil {
 			log.Printf("admin_content jellyfin_preview: count existing versions failed (anime_id=%d): %v", animeID, countErr)
 			// Non-fatal: continue with 0
@@ -115,7 +115,7 @@ func (h *AdminContentHandler) PreviewAnimeFromJellyfin(c *gin.Context) {
 	// Count episodes that would become orphaned if all jellyfin versions are deleted
 	var existingEpisodes int32
 	{
-		episodeCount, countErr := h.repo.CountEpisodesWithOnlyProvider(c.Request.Context(), animeID, "jellyfin")
+		episodeCount, countErr := h.repo.CountEpisodesWithOnlyProvider(c.Request.Context(), animeID, h.catalogMediaServer().Kind())
 		if countErr != nil {
 			log.Printf("admin_content jellyfin_preview: count episodes with only jellyfin failed (anime_id=%d): %v", animeID, countErr)
 			// Non-fatal: continue with 0
@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// jellyfinRuntimeToleranceSeconds ist die erlaubte Abweichung zwischen duration_seconds einer
+	// Variante und der Laufzeit des Jellyfin-Items (Rundung, Intro-Schnitte).
+	jellyfinRuntimeToleranceSeconds = 5
+	// jellyfinReconciliationStaleMinutes markiert hängengebliebene Läufe (Absturz, Neustart)
+	// als abgebrochen, damit der nächste Lauf starten kann.
+	jellyfinReconciliationStaleMinutes = 360
+	// maxJellyfinFindingResolveIDs begrenzt die IDs pro Bulk-Aufruf.
+	maxJellyfinFindingResolveIDs = 500
+)
+
+// errJellyfinReconciliationUnavailable meldet, dass Abgleich oder Katalog-Server nicht
+// konfiguriert sind.
+var errJellyfinReconciliationUnavailable = errors.New("jellyfin reconciliation is not configured")
+
+// jellyfinReconciliationStore kapselt Läufe, Befunde und die Katalogdaten des Abgleichs.
+type jellyfinReconciliationStore interface {
+	StartRun(ctx context.Context, provider string, libraryIDs []string, staleAfterMinutes int) (*models.JellyfinReconciliationRun, error)
+	FinishRun(ctx context.Context, runID int64, result models.JellyfinReconciliationRunResult) (*models.JellyfinReconciliationRun, error)
+	ListRuns(ctx context.Context, limit int) ([]models.JellyfinReconciliationRun, error)
+	UpsertFinding(ctx context.Context, runID int64, input models.JellyfinReconciliationFindingInput) error
+	ListFindings(ctx context.Context, filter models.JellyfinReconciliationFindingFilter) ([]models.JellyfinReconciliationFinding, int64, error)
+	ResolveFindings(ctx context.Context, ids []int64, status string, actorAppUserID *int64) ([]models.JellyfinReconciliationFinding, error)
+	ListLinkedAnime(ctx context.Context) ([]models.JellyfinLinkedAnime, error)
+	ListProviderVariants(ctx context.Context, animeID int64, provider string) ([]models.JellyfinReconciliationVariant, error)
+}
+
+// animeSyncSourceLoader liefert die für den Metadaten-Vergleich gespeicherten Anime-Felder.
+type animeSyncSourceLoader interface {
+	GetAnimeSyncSource(ctx context.Context, animeID int64) (*models.AdminAnimeSyncSource, error)
+}
+
+// WithJellyfinReconciliation aktiviert den Bibliotheksabgleich; libraryIDs werden nur am Lauf
+// protokolliert, die Einschränkung selbst übernimmt der Katalog-Server.
+func (h *AdminContentHandler) WithJellyfinReconciliation(store jellyfinReconciliationStore, libraryIDs []string) *AdminContentHandler {
+	h.jellyfinReconciliation = store
+	h.jellyfinLibraryIDs = append([]string(nil), libraryIDs...)
+	if h.jellyfinSyncSources == nil && h.repo != nil {
+		h.jellyfinSyncSources = h.repo
+	}
+	return h
+}
+
+type adminJellyfinFindingResolveRequest struct {
+	IDs    []int64 `json:"ids"`
+	Status string  `json:"status"`
+}
+
+// ReconcileJellyfinLibraries gleicht alle verknüpften Anime mit den freigegebenen Bibliotheken
+// des Katalog-Servers ab und speichert die Abweichungen als Befunde. Läuft bereits ein Abgleich,
+// wird repository.ErrConflict geliefert. Fehler des Media-Servers brechen den Lauf ab (status
+// failed), damit bestehende Befunde nicht fälschlich als erledigt gelten.
+func (h *AdminContentHandler) ReconcileJellyfinLibraries(ctx context.Context) (*models.JellyfinReconciliationRun, error) {
+	server := h.catalogMediaServer()
+	if h.jellyfinReconciliation == nil || !server.Configured() {
+		return nil, errJellyfinReconciliationUnavailable
+	}
+
+	run, err := h.jellyfinReconciliation.StartRun(ctx, server.Kind(), h.jellyfinLibraryIDs, jellyfinReconciliationStaleMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.JellyfinReconciliationRunResult{Status: models.JellyfinReconciliationRunStatusCompleted}
+	if runErr := h.reconcileJellyfinLibraries(ctx, server, run.ID, &result); runErr != nil {
+		log.Printf("admin_content jellyfin_reconciliation: run failed (run_id=%d): %v", run.ID, runErr)
+		message := runErr.Error()
+		result.Status = models.JellyfinReconciliationRunStatusFailed
+		result.ErrorMessage = &message
+	}
+
+	// Der Lauf wird auch nach Abbruch des Kontexts sauber beendet.
+	finished, err := h.jellyfinReconciliation.FinishRun(context.WithoutCancel(ctx), run.ID, result)
+	if err != nil {
+		return nil, err
+	}
+	return finished, nil
+}
+
+func (h *AdminContentHandler) reconcileJellyfinLibraries(
+	ctx context.Context,
+	server mediaserver.MediaServerProvider,
+	runID int64,
+	result *models.JellyfinReconciliationRunResult,
+) error {
+	librarySeries, err := server.ListLibrarySeries(ctx)
+	if err != nil {
+		return fmt.Errorf("list library series: %w", err)
+	}
+	seriesByID := make(map[string]mediaserver.Series, len(librarySeries))
+	for _, series := range librarySeries {
+		seriesByID[strings.TrimSpace(series.ID)] = series
+	}
+
+	linked, err := h.jellyfinReconciliation.ListLinkedAnime(ctx)
+	if err != nil {
+		return err
+	}
+	seriesIDsByAnime := make(map[int64][]string)
+	animeIDs := make([]int64, 0)
+	for _, item := range linked {
+		if _, exists := seriesIDsByAnime[item.AnimeID]; !exists {
+			animeIDs = append(animeIDs, item.AnimeID)
+		}
+		seriesIDsByAnime[item.AnimeID] = append(seriesIDsByAnime[item.AnimeID], item.SeriesID)
+	}
+
+	for _, animeID := range animeIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		findings, err := h.reconcileJellyfinAnime(ctx, server, animeID, seriesIDsByAnime[animeID], seriesByID)
+		if err != nil {
+			return fmt.Errorf("anime %d: %w", animeID, err)
+		}
+		for _, finding := range findings {
+			if err := h.jellyfinReconciliation.UpsertFinding(ctx, runID, finding); err != nil {
+				return err
+			}
+		}
+		result.AnimeChecked++
+		result.FindingsDetected += int32(len(findings))
+	}
+	return nil
+}
+
+// reconcileJellyfinAnime vergleicht eine Anime mit ihren verknüpften Serien. Episoden- und
+// Variantenprüfungen laufen nur, wenn alle Serien in den freigegebenen Bibliotheken liegen;
+// sonst würde jede Variante der fehlenden Serie zusätzlich gemeldet.
+func (h *AdminContentHandler) reconcileJellyfinAnime(
+	ctx context.Context,
+	server mediaserver.MediaServerProvider,
+	animeID int64,
+	seriesIDs []string,
+	seriesByID map[string]mediaserver.Series,
+) ([]models.JellyfinReconciliationFindingInput, error) {
+	findings := make([]models.JellyfinReconciliationFindingInput, 0)
+	episodesByItemID := make(map[string]mediaserver.Episode)
+	episodeItemIDs := make([]string, 0)
+	seriesOfItem := make(map[string]string)
+	allSeriesAvailable := true
+
+	for _, seriesID := range seriesIDs {
+		series, inLibraries := seriesByID[seriesID]
+		if !inLibraries {
+			allSeriesAvailable = false
+			existing, err := server.GetSeries(ctx, seriesID)
+			if err != nil {
+				return nil, err
+			}
+			findingType := models.JellyfinFindingSeriesMissing
+			if existing != nil {
+				findingType = models.JellyfinFindingSeriesOutsideLibraries
+			}
+			findings = append(findings, models.JellyfinReconciliationFindingInput{
+				Fingerprint:      jellyfinFindingFingerprint(findingType, animeID, seriesID),
+				FindingType:      findingType,
+				AnimeID:          animeID,
+				JellyfinSeriesID: stringPtrFromValue(seriesID),
+				Details:          map[string]any{},
+			})
+			continue
+		}
+
+		if drift, ok, err := h.detectJellyfinMetadataDrift(ctx, server.Kind(), animeID, series); err != nil {
+			return nil, err
+		} else if ok {
+			findings = append(findings, drift)
+		}
+
+		episodes, err := server.ListEpisodes(ctx, seriesID)
+		if err != nil {
+			return nil, err
+		}
+		for _, episode := range episodes {
+			itemID := strings.TrimSpace(episode.ID)
+			if itemID == "" {
+				continue
+			}
+			if _, exists := episodesByItemID[itemID]; !exists {
+				episodeItemIDs = append(episodeItemIDs, itemID)
+			}
+			episodesByItemID[itemID] = episode
+			seriesOfItem[itemID] = seriesID
+		}
+	}
+	if !allSeriesAvailable {
+		return findings, nil
+	}
+
+	variants, err := h.jellyfinReconciliation.ListProviderVariants(ctx, animeID, server.Kind())
+	if err != nil {
+		return nil, err
+	}
+	variantItemIDs := make(map[string]struct{}, len(variants))
+	for _, variant := range variants {
+		variantItemIDs[variant.ItemID] = struct{}{}
+		variantID := variant.VariantID
+		episode, exists := episodesByItemID[variant.ItemID]
+		if !exists {
+			findings = append(findings, models.JellyfinReconciliationFindingInput{
+				Fingerprint:      jellyfinFindingFingerprint(models.JellyfinFindingVariantItemMissing, animeID, strconv.FormatInt(variantID, 10)+":"+variant.ItemID),
+				FindingType:      models.JellyfinFindingVariantItemMissing,
+				AnimeID:          animeID,
+				JellyfinItemID:   stringPtrFromValue(variant.ItemID),
+				ReleaseVariantID: &variantID,
+				EpisodeNumber:    stringPtrFromValue(variant.EpisodeNumber),
+				Details:          map[string]any{},
+			})
+			continue
+		}
+
+		runtime := mediaserver.RuntimeTicksToSeconds(episode.RunTimeTicks)
+		if variant.DurationSeconds == nil || runtime == nil {
+			continue
+		}
+		delta := int64(*variant.DurationSeconds) - int64(*runtime)
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta <= jellyfinRuntimeToleranceSeconds {
+			continue
+		}
+		findings = append(findings, models.JellyfinReconciliationFindingInput{
+			Fingerprint:      jellyfinFindingFingerprint(models.JellyfinFindingRuntimeMismatch, animeID, strconv.FormatInt(variantID, 10)+":"+variant.ItemID),
+			FindingType:      models.JellyfinFindingRuntimeMismatch,
+			AnimeID:          animeID,
+			JellyfinSeriesID: stringPtrFromValue(seriesOfItem[variant.ItemID]),
+			JellyfinItemID:   stringPtrFromValue(variant.ItemID),
+			ReleaseVariantID: &variantID,
+			EpisodeNumber:    stringPtrFromValue(variant.EpisodeNumber),
+			Details: map[string]any{
+				"duration_seconds":         *variant.DurationSeconds,
+				"jellyfin_runtime_seconds": *runtime,
+				"delta_seconds":            delta,
+			},
+		})
+	}
+
+	for _, itemID := range episodeItemIDs {
+		if _, exists := variantItemIDs[itemID]; exists {
+			continue
+		}
+		episode := episodesByItemID[itemID]
+		details := map[string]any{"name": strings.TrimSpace(episode.Name)}
+		if episode.ParentIndexNumber != nil {
+			details["season_number"] = *episode.ParentIndexNumber
+		}
+		var episodeNumber *string
+		if episode.IndexNumber != nil {
+			episodeNumber = stringPtrFromValue(strconv.Itoa(*episode.IndexNumber))
+		}
+		findings = append(findings, models.JellyfinReconciliationFindingInput{
+			Fingerprint:      jellyfinFindingFingerprint(models.JellyfinFindingEpisodeWithoutVariant, animeID, itemID),
+			FindingType:      models.JellyfinFindingEpisodeWithoutVariant,
+			AnimeID:          animeID,
+			JellyfinSeriesID: stringPtrFromValue(seriesOfItem[itemID]),
+			JellyfinItemID:   stringPtrFromValue(itemID),
+			EpisodeNumber:    episodeNumber,
+			Details:          details,
+		})
+	}
+
+	return findings, nil
+}
+
+// detectJellyfinMetadataDrift nutzt den Vergleich der Metadaten-Vorschau. Gemeldet werden Felder,
+// die Jellyfin füllen könnte (fill) oder die vom gespeicherten Wert abweichen (protect); die
+// Quelle selbst ist über die Verknüpfung bereits geprüft.
+func (h *AdminContentHandler) detectJellyfinMetadataDrift(
+	ctx context.Context,
+	provider string,
+	animeID int64,
+	series mediaserver.Series,
+) (models.JellyfinReconciliationFindingInput, bool, error) {
+	if h.jellyfinSyncSources == nil {
+		return models.JellyfinReconciliationFindingInput{}, false, nil
+	}
+	animeSource, err := h.jellyfinSyncSources.GetAnimeSyncSource(ctx, animeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.JellyfinReconciliationFindingInput{}, false, nil
+	}
+	if err != nil {
+		return models.JellyfinReconciliationFindingInput{}, false, err
+	}
+
+	intakePreview := buildAdminJellyfinIntakePreviewResult(provider, series, nil)
+	fields := make([]models.AdminAnimeJellyfinMetadataFieldPreview, 0)
+	for _, field := range buildAnimeJellyfinMetadataDiff(animeSource, intakePreview) {
+		if field.Field == "source" || field.Action == "keep" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return models.JellyfinReconciliationFindingInput{}, false, nil
+	}
+
+	return models.JellyfinReconciliationFindingInput{
+		Fingerprint:      jellyfinFindingFingerprint(models.JellyfinFindingMetadataDrift, animeID, intakePreview.JellyfinSeriesID),
+		FindingType:      models.JellyfinFindingMetadataDrift,
+		AnimeID:          animeID,
+		JellyfinSeriesID: stringPtrFromValue(intakePreview.JellyfinSeriesID),
+		Details:          map[string]any{"fields": fields},
+	}, true, nil
+}
+
+// jellyfinFindingFingerprint identifiziert dieselbe Abweichung über Läufe hinweg.
+func jellyfinFindingFingerprint(findingType string, animeID int64, key string) string {
+	return fmt.Sprintf("%s:%d:%s", findingType, animeID, key)
+}
+
+// ListJellyfinReconciliationRuns verarbeitet GET /api/v1/admin/jellyfin/reconciliation/runs
+// (limit 1-100, Standard 20).
+func (h *AdminContentHandler) ListJellyfinReconciliationRuns(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if !h.ensureJellyfinReconciliationConfigured(c) {
+		return
+	}
+
+	limit, err := parsePositiveInt(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		badRequest(c, "ungültiger limit parameter")
+		return
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	runs, err := h.jellyfinReconciliation.ListRuns(c.Request.Context(), limit)
+	if err != nil {
+		log.Printf("admin_content jellyfin_reconciliation: list runs failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": runs})
+}
+
+// ListJellyfinReconciliationFindings verarbeitet GET /api/v1/admin/jellyfin/reconciliation/findings
+// mit den optionalen Filtern status, type und anime_id.
+func (h *AdminContentHandler) ListJellyfinReconciliationFindings(c *gin.Context) {
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+	if !h.ensureJellyfinReconciliationConfigured(c) {
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	if status != "" && !models.IsJellyfinFindingStatus(status) {
+		badRequest(c, "ungültiger status parameter")
+		return
+	}
+	findingType := strings.ToLower(strings.TrimSpace(c.Query("type")))
+	if findingType != "" && !models.IsJellyfinFindingType(findingType) {
+		badRequest(c, "ungültiger type parameter")
+		return
+	}
+	var animeID int64
+	if raw := strings.TrimSpace(c.Query("anime_id")); raw != "" {
+		parsed, err := parseAnimeID(raw)
+		if err != nil {
+			badRequest(c, "ungültige anime id")
+			return
+		}
+		animeID = parsed
+	}
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "50"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 200 {
+		perPage = 200
+	}
+
+	items, total, err := h.jellyfinReconciliation.ListFindings(c.Request.Context(), models.JellyfinReconciliationFindingFilter{
+		Status:      status,
+		FindingType: findingType,
+		AnimeID:     animeID,
+		Page:        page,
+		PerPage:     perPage,
+	})
+	if err != nil {
+		log.Printf("admin_content jellyfin_reconciliation: list findings failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// ResolveJellyfinReconciliationFindings verarbeitet POST
+// /api/v1/admin/jellyfin/reconciliation/findings/resolve. status ist resolved (behoben) oder
+// dismissed (bewusst ignoriert; wird bei späteren Läufen nicht erneut geöffnet). Nur offene
+// Befunde werden geändert; die Antwort enthält die tatsächlich geänderten.
+func (h *AdminContentHandler) ResolveJellyfinReconciliationFindings(c *gin.Context) {
+	identity, ok := h.requireAdmin(c)
+	if !ok {
+		return
+	}
+	if !h.ensureJellyfinReconciliationConfigured(c) {
+		return
+	}
+
+	var req adminJellyfinFindingResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if status != models.JellyfinFindingStatusResolved && status != models.JellyfinFindingStatusDismissed {
+		badRequest(c, "status muss resolved oder dismissed sein")
+		return
+	}
+	if len(req.IDs) == 0 {
+		badRequest(c, "ids ist erforderlich")
+		return
+	}
+	if len(req.IDs) > maxJellyfinFindingResolveIDs {
+		badRequest(c, fmt.Sprintf("maximal %d ids pro aufruf", maxJellyfinFindingResolveIDs))
+		return
+	}
+	for _, id := range req.IDs {
+		if id <= 0 {
+			badRequest(c, "ungültige befund id")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	actor := appUserIDPtr(identity.AppUserID)
+	resolved, err := h.jellyfinReconciliation.ResolveFindings(ctx, req.IDs, status, actor)
+	if err != nil {
+		log.Printf("admin_content jellyfin_reconciliation: resolve findings failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	for i := range resolved {
+		finding := &resolved[i]
+		if err := h.auditLogRepo.Write(ctx, repository.AuditLogEntry{
+			ActorAppUserID: actor,
+			EventType:      "jellyfin_reconciliation_finding." + status,
+			ScopeType:      "anime",
+			ScopeID:        &finding.AnimeID,
+			TargetType:     "jellyfin_reconciliation_finding",
+			TargetID:       &finding.ID,
+			Action:         "jellyfin_reconciliation_finding." + status,
+			Outcome:        "allowed",
+			Payload: map[string]any{
+				"anime_id":     finding.AnimeID,
+				"finding_type": finding.FindingType,
+				"status":       finding.Status,
+			},
+		}); err != nil {
+			log.Printf("admin_content jellyfin_reconciliation: audit resolve failed (finding_id=%d): %v", finding.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"updated": len(resolved), "findings": resolved}})
+}
+
+func (h *AdminContentHandler) ensureJellyfinReconciliationConfigured(c *gin.Context) bool {
+	if h.jellyfinReconciliation == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "jellyfin abgleich ist nicht konfiguriert"}})
+		return false
+	}
+	return true
+}
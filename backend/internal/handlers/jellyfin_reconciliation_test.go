@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/mediaserver/mediaservertest"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type jellyfinReconciliationStoreStub struct {
+	startErr  error
+	linked    []models.JellyfinLinkedAnime
+	variants  map[int64][]models.JellyfinReconciliationVariant
+	findings  []models.JellyfinReconciliationFindingInput
+	finished  *models.JellyfinReconciliationRunResult
+	resolveTo string
+}
+
+func (s *jellyfinReconciliationStoreStub) StartRun(_ context.Context, provider string, libraryIDs []string, _ int) (*models.JellyfinReconciliationRun, error) {
+	if s.startErr != nil {
+		return nil, s.startErr
+	}
+	return &models.JellyfinReconciliationRun{ID: 1, Status: models.JellyfinReconciliationRunStatusRunning, Provider: provider, LibraryIDs: libraryIDs}, nil
+}
+
+func (s *jellyfinReconciliationStoreStub) FinishRun(_ context.Context, runID int64, result models.JellyfinReconciliationRunResult) (*models.JellyfinReconciliationRun, error) {
+	s.finished = &result
+	return &models.JellyfinReconciliationRun{
+		ID:               runID,
+		Status:           result.Status,
+		AnimeChecked:     result.AnimeChecked,
+		FindingsDetected: result.FindingsDetected,
+		ErrorMessage:     result.ErrorMessage,
+	}, nil
+}
+
+func (s *jellyfinReconciliationStoreStub) ListRuns(context.Context, int) ([]models.JellyfinReconciliationRun, error) {
+	return nil, nil
+}
+
+func (s *jellyfinReconciliationStoreStub) UpsertFinding(_ context.Context, _ int64, input models.JellyfinReconciliationFindingInput) error {
+	s.findings = append(s.findings, input)
+	return nil
+}
+
+func (s *jellyfinReconciliationStoreStub) ListFindings(context.Context, models.JellyfinReconciliationFindingFilter) ([]models.JellyfinReconciliationFinding, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *jellyfinReconciliationStoreStub) ResolveFindings(_ context.Context, ids []int64, status string, _ *int64) ([]models.JellyfinReconciliationFinding, error) {
+	s.resolveTo = status
+	items := make([]models.JellyfinReconciliationFinding, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, models.JellyfinReconciliationFinding{ID: id, AnimeID: 7, Status: status})
+	}
+	return items, nil
+}
+
+func (s *jellyfinReconciliationStoreStub) ListLinkedAnime(context.Context) ([]models.JellyfinLinkedAnime, error) {
+	return s.linked, nil
+}
+
+func (s *jellyfinReconciliationStoreStub) ListProviderVariants(_ context.Context, animeID int64, _ string) ([]models.JellyfinReconciliationVariant, error) {
+	return s.variants[animeID], nil
+}
+
+type animeSyncSourceStub map[int64]*models.AdminAnimeSyncSource
+
+func (s animeSyncSourceStub) GetAnimeSyncSource(_ context.Context, animeID int64) (*models.AdminAnimeSyncSource, error) {
+	if source, ok := s[animeID]; ok {
+		return source, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func episodeTicks(seconds int64) *int64 {
+	ticks := seconds * 10_000_000
+	return &ticks
+}
+
+func TestReconcileJellyfinLibrariesDetectsDrift(t *testing.T) {
+	provider := mediaservertest.NewProvider()
+	year := 2023
+	provider.Series = []mediaserver.Series{{ID: "series-1", Name: "Frieren", Path: "/anime/Frieren", ProductionYear: &year}}
+	episodeOne, episodeTwo := 1, 2
+	provider.Episodes["series-1"] = []mediaserver.Episode{
+		{ID: "ep-1", IndexNumber: &episodeOne, RunTimeTicks: episodeTicks(1440)},
+		{ID: "ep-2", IndexNumber: &episodeTwo, RunTimeTicks: episodeTicks(1420)},
+	}
+
+	store := &jellyfinReconciliationStoreStub{
+		linked: []models.JellyfinLinkedAnime{{AnimeID: 7, SeriesID: "series-1"}, {AnimeID: 9, SeriesID: "gone"}},
+		variants: map[int64][]models.JellyfinReconciliationVariant{
+			7: {
+				{VariantID: 70, AnimeID: 7, EpisodeNumber: "1", ItemID: "ep-1", DurationSeconds: int32Ptr(1300)},
+				{VariantID: 71, AnimeID: 7, EpisodeNumber: "3", ItemID: "ep-deleted"},
+			},
+		},
+	}
+	h := (&AdminContentHandler{}).WithMediaServer(provider).WithJellyfinReconciliation(store, []string{"lib-a"})
+	h.jellyfinSyncSources = animeSyncSourceStub{
+		7: {ID: 7, Title: "Frieren", FolderName: stringPtrFromValue("/anime/Frieren")},
+	}
+
+	run, err := h.ReconcileJellyfinLibraries(context.Background())
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if run.Status != models.JellyfinReconciliationRunStatusCompleted || run.AnimeChecked != 2 {
+		t.Fatalf("unexpected run %+v", run)
+	}
+
+	fingerprints := make([]string, 0, len(store.findings))
+	for _, finding := range store.findings {
+		fingerprints = append(fingerprints, finding.Fingerprint)
+	}
+	sort.Strings(fingerprints)
+	expected := []string{
+		"episode_without_variant:7:ep-2",
+		"metadata_drift:7:series-1",
+		"runtime_mismatch:7:70:ep-1",
+		"series_missing:9:gone",
+		"variant_item_missing:7:71:ep-deleted",
+	}
+	if strings.Join(fingerprints, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected findings %v", fingerprints)
+	}
+	if int(run.FindingsDetected) != len(expected) {
+		t.Fatalf("expected %d findings on run, got %d", len(expected), run.FindingsDetected)
+	}
+}
+
+func TestReconcileJellyfinLibrariesSkipsEpisodeChecksOutsideLibraries(t *testing.T) {
+	provider := mediaservertest.NewProvider()
+	provider.Series = []mediaserver.Series{{ID: "series-1", Name: "Frieren"}}
+	store := &jellyfinReconciliationStoreStub{
+		linked: []models.JellyfinLinkedAnime{{AnimeID: 7, SeriesID: "series-1"}},
+		variants: map[int64][]models.JellyfinReconciliationVariant{
+			7: {{VariantID: 70, AnimeID: 7, EpisodeNumber: "1", ItemID: "ep-1"}},
+		},
+	}
+	h := (&AdminContentHandler{}).WithMediaServer(&libraryScopedProvider{Provider: provider}).WithJellyfinReconciliation(store, nil)
+
+	if _, err := h.ReconcileJellyfinLibraries(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(store.findings) != 1 || store.findings[0].FindingType != models.JellyfinFindingSeriesOutsideLibraries {
+		t.Fatalf("expected only series_outside_libraries, got %+v", store.findings)
+	}
+}
+
+func TestReconcileJellyfinLibrariesFailsRunOnServerError(t *testing.T) {
+	provider := mediaservertest.NewProvider()
+	provider.Err = context.DeadlineExceeded
+	store := &jellyfinReconciliationStoreStub{}
+	h := (&AdminContentHandler{}).WithMediaServer(provider).WithJellyfinReconciliation(store, nil)
+
+	run, err := h.ReconcileJellyfinLibraries(context.Background())
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if run.Status != models.JellyfinReconciliationRunStatusFailed || store.finished.ErrorMessage == nil {
+		t.Fatalf("expected failed run with message, got %+v", store.finished)
+	}
+}
+
+// libraryScopedProvider liefert keine Serien aus den freigegebenen Bibliotheken, kennt die
+// Serien aber weiterhin per ID.
+type libraryScopedProvider struct {
+	*mediaservertest.Provider
+}
+
+func (p *libraryScopedProvider) ListLibrarySeries(context.Context) ([]mediaserver.Series, error) {
+	return nil, nil
+}
+
+func TestResolveJellyfinReconciliationFindingsValidatesStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &jellyfinReconciliationStoreStub{}
+	h := (&AdminContentHandler{authzRepo: stubAdminRoleChecker{allowed: true}}).WithJellyfinReconciliation(store, nil)
+
+	router := gin.New()
+	router.POST("/api/v1/admin/jellyfin/reconciliation/findings/resolve", withTestAdminIdentity(), h.ResolveJellyfinReconciliationFindings)
+	serve := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/jellyfin/reconciliation/findings/resolve", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(`{"ids":[1],"status":"open"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for open status, got %d", rec.Code)
+	}
+	if rec := serve(`{"ids":[],"status":"resolved"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty ids, got %d", rec.Code)
+	}
+	rec := serve(`{"ids":[1,2],"status":"Dismissed"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"updated":2`) {
+		t.Fatalf("expected 2 dismissed findings, got %d %s", rec.Code, rec.Body.String())
+	}
+	if store.resolveTo != models.JellyfinFindingStatusDismissed {
+		t.Fatalf("expected dismissed status, got %q", store.resolveTo)
+	}
+}
@@ -92,7 +92,7 @@ func (h *AdminContentHandler) SearchJellyfinSeries(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": buildAdminJellyfinIntakeSearchItems(items, query, existingMatches),
+		"data": buildAdminJellyfinIntakeSearchItems(h.catalogMediaServer().Kind(), items, query, existingMatches),
 	})
 }
 
@@ -143,7 +143,7 @@ func validateAdminAnimeJellyfinSyncRequest(req adminAnimeJellyfinSyncRequest) (a
 
 // ensureJellyfinConfigured checks if Jellyfin integration is set up.
 func (h *AdminContentHandler) ensureJellyfinConfigured(c *gin.Context) bool {
-	if strings.TrimSpace(h.jellyfinBaseURL) == "" || strings.TrimSpace(h.jellyfinAPIKey) == "" {
+	if !h.catalogMediaServer().Configured() {
 		details := "JELLYFIN_BASE_URL oder JELLYFIN_API_KEY fehlt."
 		writeJellyfinErrorResponse(c, http.StatusServiceUnavailable, "jellyfin ist nicht konfiguriert", "jellyfin_not_configured", &details)
 		return false
@@ -3,6 +3,7 @@ package handlers
 import (
 	"testing"
 
+	"team4s.v3/backend/internal/mediaserver"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
 )
@@ -15,7 +16,7 @@ func TestBuildAdminJellyfinIntakeSearchItems_RanksStrongerMatchesFirst(t *testin
 		{ID: "strong", Name: "Naruto", Path: `D:\Anime\TV\Naruto`},
 	}
 
-	result := buildAdminJellyfinIntakeSearchItems(items, "Naruto", nil)
+	result := buildAdminJellyfinIntakeSearchItems(mediaserver.KindJellyfin, items, "Naruto", nil)
 	if len(result) != 2 {
 		t.Fatalf("expected 2 results, got %d", len(result))
 	}
@@ -39,7 +40,7 @@ func TestBuildAdminJellyfinIntakeSearchItems_IncludeEvidenceAndPreviewReferences
 		},
 	}
 
-	result := buildAdminJellyfinIntakeSearchItems(items, "Naruto", nil)
+	result := buildAdminJellyfinIntakeSearchItems(mediaserver.KindJellyfin, items, "Naruto", nil)
 	if len(result) != 1 {
 		t.Fatalf("expected 1 result, got %d", len(result))
 	}
@@ -58,7 +59,7 @@ func TestBuildAdminJellyfinIntakeSearchItems_MarksAlreadyImportedCandidates(t *t
 	t.Parallel()
 
 	path := `/media/Anime/OVA/Anime.OVA.Sub/Macross Flash Back 2012`
-	result := buildAdminJellyfinIntakeSearchItems([]jellyfinSeriesItem{
+	result := buildAdminJellyfinIntakeSearchItems(mediaserver.KindJellyfin, []jellyfinSeriesItem{
 		{
 			ID:   "macross-ova",
 			Name: "Macross",
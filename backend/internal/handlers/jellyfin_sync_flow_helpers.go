@@ -40,7 +40,7 @@ func (h *AdminContentHandler) cleanupJellyfinProviderVersions(
 		return true
 	}
 
-	deletedCount, deleteErr := h.episodeVersionRepo.DeleteByAnimeAndProvider(c.Request.Context(), animeID, "jellyfin")
+	deletedCount, deleteErr := h.episodeVersionRepo.DeleteByAnimeAndProvider(c.Request.Context(), animeID, h.catalogMediaServer().Kind())
 	if deleteErr != nil {
 		log.Printf(
 			"admin_content jellyfin_sync: delete existing versions failed (user_id=%d, anime_id=%d): %v",
@@ -129,7 +129,7 @@ func (h *AdminContentHandler) importAcceptedEpisodes(
 				EpisodeNumber: accepted.episodeNumber,
 				Title:         accepted.episodeTitle,
 				FansubGroupID: accepted.fansubGroupID,
-				MediaProvider: "jellyfin",
+				MediaProvider: h.catalogMediaServer().Kind(),
 				MediaItemID:   accepted.mediaItemID,
 				VideoQuality:  accepted.videoQuality,
 				SubtitleType:  nil,
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jellyfinWebhookSecretHeader trägt das gemeinsame Secret (JELLYFIN_WEBHOOK_SECRET); im
+// Jellyfin-Webhook-Plugin als zusätzlicher Request-Header konfigurieren.
+const jellyfinWebhookSecretHeader = "X-Jellyfin-Webhook-Secret"
+
+// jellyfinPendingChangeStore kapselt die Persistenz der Webhook-Änderungen.
+type jellyfinPendingChangeStore interface {
+	Upsert(ctx context.Context, input models.JellyfinPendingChangeInput) (*models.JellyfinPendingChange, bool, error)
+	List(ctx context.Context, filter models.JellyfinPendingChangeFilter) ([]models.JellyfinPendingChange, int64, error)
+	GetByID(ctx context.Context, id int64) (*models.JellyfinPendingChange, error)
+	Resolve(ctx context.Context, id int64, resolution models.JellyfinPendingChangeResolution) (*models.JellyfinPendingChange, error)
+	GetSyncSettings(ctx context.Context, animeID int64) (*models.AnimeJellyfinSyncSettings, error)
+	SetAutoApply(ctx context.Context, animeID int64, autoApply bool, actorAppUserID *int64) (*models.AnimeJellyfinSyncSettings, error)
+}
+
+// jellyfinAnimeRefMatcher ordnet Jellyfin-Serien-IDs über anime.source und
+// anime_source_links ("jellyfin:<id>") bestehenden Anime zu.
+type jellyfinAnimeRefMatcher interface {
+	FindExistingAnimeByJellyfinIntakeRefs(ctx context.Context, seriesIDs []string, paths []string) ([]repository.ExistingJellyfinAnimeMatch, error)
+}
+
+// jellyfinItemVersionRemover entfernt die Versionen, die auf ein gelöschtes Mediaserver-Item zeigen.
+type jellyfinItemVersionRemover interface {
+	DeleteByAnimeMediaItemAndProvider(ctx context.Context, animeID int64, mediaItemID string, provider string) (int64, error)
+}
+
+// WithJellyfinWebhook aktiviert den Webhook-Empfänger und die Admin-Prüfliste. Ohne secret
+// lehnt der Empfänger alle Aufrufe mit 503 ab.
+func (h *AdminContentHandler) WithJellyfinWebhook(store jellyfinPendingChangeStore, secret string) *AdminContentHandler {
+	h.jellyfinChanges = store
+	h.jellyfinWebhookSecret = strings.TrimSpace(secret)
+	if h.jellyfinAnimeMatcher == nil && h.repo != nil {
+		h.jellyfinAnimeMatcher = h.repo
+	}
+	if h.jellyfinItemVersions == nil && h.episodeVersionRepo != nil {
+		h.jellyfinItemVersions = h.episodeVersionRepo
+	}
+	return h
+}
+
+// jellyfinWebhookPayload ist das JSON des Jellyfin-Webhook-Plugins (Generic-Destination).
+// Staffel- und Episodennummern kommen je nach Template als Zahl oder als String ("01").
+type jellyfinWebhookPayload struct {
+	NotificationType string                `json:"NotificationType"`
+	ItemID           string                `json:"ItemId"`
+	ItemType         string                `json:"ItemType"`
+	Name             string                `json:"Name"`
+	SeriesID         string                `json:"SeriesId"`
+	SeriesName       string                `json:"SeriesName"`
+	SeasonNumber     jellyfinWebhookNumber `json:"SeasonNumber"`
+	EpisodeNumber    jellyfinWebhookNumber `json:"EpisodeNumber"`
+}
+
+// jellyfinWebhookNumber akzeptiert Zahlen, numerische Strings und leere Werte.
+type jellyfinWebhookNumber struct {
+	Value *int32
+}
+
+func (n *jellyfinWebhookNumber) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	if raw == "" || raw == "null" {
+		n.Value = nil
+		return nil
+	}
+	parsed, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || parsed < 0 {
+		n.Value = nil
+		return nil
+	}
+	value := int32(parsed)
+	n.Value = &value
+	return nil
+}
+
+// jellyfinWebhookEventTypes bildet die NotificationType-Werte des Plugins auf die gespeicherten
+// Ereignistypen ab; ItemDeleted ist der Plugin-Name für entfernte Items.
+var jellyfinWebhookEventTypes = map[string]string{
+	"itemadded":   models.JellyfinChangeEventItemAdded,
+	"itemupdated": models.JellyfinChangeEventItemUpdated,
+	"itemremoved": models.JellyfinChangeEventItemRemoved,
+	"itemdeleted": models.JellyfinChangeEventItemRemoved,
+}
+
+// ReceiveJellyfinWebhook verarbeitet POST /api/v1/jellyfin/webhook. Episoden-Ereignisse werden
+// über die gespeicherte Jellyfin-Serien-ID den Anime zugeordnet und als ausstehende Änderung
+// abgelegt; bei aktivierter Auto-Übernahme wird die Änderung sofort mit der Logik des
+// Episoden-Syncs übernommen. Andere Ereignisse werden mit status=ignored quittiert.
+func (h *AdminContentHandler) ReceiveJellyfinWebhook(c *gin.Context) {
+	if h.jellyfinChanges == nil || h.jellyfinAnimeMatcher == nil || h.jellyfinWebhookSecret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"message": "jellyfin webhook ist nicht konfiguriert"}})
+		return
+	}
+	provided := strings.TrimSpace(c.GetHeader(jellyfinWebhookSecretHeader))
+	if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(h.jellyfinWebhookSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "ungültiges webhook secret"}})
+		return
+	}
+
+	var payload jellyfinWebhookPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+
+	eventType, ok := jellyfinWebhookEventTypes[strings.ToLower(strings.TrimSpace(payload.NotificationType))]
+	if !ok {
+		writeJellyfinWebhookIgnored(c, "unsupported_notification_type")
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(payload.ItemType), "Episode") {
+		writeJellyfinWebhookIgnored(c, "unsupported_item_type")
+		return
+	}
+	itemID := strings.TrimSpace(payload.ItemID)
+	seriesID := strings.TrimSpace(payload.SeriesID)
+	if itemID == "" || seriesID == "" {
+		badRequest(c, "ItemId und SeriesId sind erforderlich")
+		return
+	}
+	if len(itemID) > 120 || len(seriesID) > 120 {
+		badRequest(c, "ItemId oder SeriesId ist zu lang")
+		return
+	}
+
+	ctx := c.Request.Context()
+	matches, err := h.jellyfinAnimeMatcher.FindExistingAnimeByJellyfinIntakeRefs(ctx, []string{seriesID}, nil)
+	if err != nil {
+		log.Printf("admin_content jellyfin_webhook: match series failed (series_id=%s, item_id=%s): %v", seriesID, itemID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	if len(matches) == 0 {
+		log.Printf("admin_content jellyfin_webhook: no anime for series (series_id=%s, item_id=%s, event=%s)", seriesID, itemID, eventType)
+		writeJellyfinWebhookIgnored(c, "unmatched_series")
+		return
+	}
+
+	changes := make([]models.JellyfinPendingChange, 0, len(matches))
+	for _, match := range matches {
+		change, created, upsertErr := h.jellyfinChanges.Upsert(ctx, models.JellyfinPendingChangeInput{
+			AnimeID:          match.AnimeID,
+			EventType:        eventType,
+			ItemType:         strings.TrimSpace(payload.ItemType),
+			JellyfinItemID:   itemID,
+			JellyfinSeriesID: seriesID,
+			ItemName:         normalizeNullableStringPtr(payload.Name),
+			SeasonNumber:     payload.SeasonNumber.Value,
+			EpisodeNumber:    payload.EpisodeNumber.Value,
+			Payload: map[string]any{
+				"notification_type": strings.TrimSpace(payload.NotificationType),
+				"series_name":       strings.TrimSpace(payload.SeriesName),
+			},
+		})
+		if upsertErr != nil {
+			log.Printf("admin_content jellyfin_webhook: queue change failed (anime_id=%d, item_id=%s): %v", match.AnimeID, itemID, upsertErr)
+			internalError(c, "interner serverfehler")
+			return
+		}
+		h.writeJellyfinChangeAudit(ctx, nil, "jellyfin_pending_change.queued", change, "allowed", map[string]any{
+			"event_type": eventType,
+			"created":    created,
+		})
+
+		settings, settingsErr := h.jellyfinChanges.GetSyncSettings(ctx, match.AnimeID)
+		if settingsErr != nil {
+			log.Printf("admin_content jellyfin_webhook: load sync settings failed (anime_id=%d): %v", match.AnimeID, settingsErr)
+		} else if settings.AutoApply && h.catalogMediaServer().Configured() {
+			if resolved, resolveErr := h.applyJellyfinPendingChange(ctx, change, nil); resolveErr == nil {
+				change = resolved
+			}
+		}
+		changes = append(changes, *change)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": gin.H{"status": "queued", "changes": changes}})
+}
+
+func writeJellyfinWebhookIgnored(c *gin.Context, reason string) {
+	c.JSON(http.StatusAccepted, gin.H{"data": gin.H{"status": "ignored", "reason": reason}})
+}
+
+// writeJellyfinChangeAudit protokolliert eine Aktion auf einer Webhook-Änderung; actor ist
+// nil für Webhook-Aufrufe und automatische Übernahmen.
+func (h *AdminContentHandler) writeJellyfinChangeAudit(
+	ctx context.Context,
+	actorAppUserID *int64,
+	eventType string,
+	change *models.JellyfinPendingChange,
+	outcome string,
+	extra map[string]any,
+) {
+	payload := map[string]any{
+		"anime_id":         change.AnimeID,
+		"jellyfin_item_id": change.JellyfinItemID,
+		"status":           change.Status,
+	}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	if err := h.auditLogRepo.Write(ctx, repository.AuditLogEntry{
+		ActorAppUserID: actorAppUserID,
+		EventType:      eventType,
+		ScopeType:      "anime",
+		ScopeID:        &change.AnimeID,
+		TargetType:     "jellyfin_pending_change",
+		TargetID:       &change.ID,
+		Action:         eventType,
+		Outcome:        outcome,
+		Payload:        payload,
+	}); err != nil {
+		log.Printf("admin_content jellyfin_webhook: audit %s failed (change_id=%d): %v", eventType, change.ID, err)
+	}
+}
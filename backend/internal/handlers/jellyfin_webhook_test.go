@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/mediaserver/mediaservertest"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type jellyfinChangeStoreStub struct {
+	inputs      []models.JellyfinPendingChangeInput
+	resolutions []models.JellyfinPendingChangeResolution
+	autoApply   bool
+}
+
+func (s *jellyfinChangeStoreStub) Upsert(_ context.Context, input models.JellyfinPendingChangeInput) (*models.JellyfinPendingChange, bool, error) {
+	s.inputs = append(s.inputs, input)
+	return &models.JellyfinPendingChange{
+		ID:               int64(len(s.inputs)),
+		AnimeID:          input.AnimeID,
+		EventType:        input.EventType,
+		ItemType:         input.ItemType,
+		JellyfinItemID:   input.JellyfinItemID,
+		JellyfinSeriesID: input.JellyfinSeriesID,
+		SeasonNumber:     input.SeasonNumber,
+		EpisodeNumber:    input.EpisodeNumber,
+		Status:           models.JellyfinPendingChangeStatusPending,
+		EventCount:       1,
+	}, true, nil
+}
+
+func (s *jellyfinChangeStoreStub) List(context.Context, models.JellyfinPendingChangeFilter) ([]models.JellyfinPendingChange, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *jellyfinChangeStoreStub) GetByID(context.Context, int64) (*models.JellyfinPendingChange, error) {
+	return nil, repository.ErrNotFound
+}
+
+func (s *jellyfinChangeStoreStub) Resolve(_ context.Context, id int64, resolution models.JellyfinPendingChangeResolution) (*models.JellyfinPendingChange, error) {
+	s.resolutions = append(s.resolutions, resolution)
+	return &models.JellyfinPendingChange{ID: id, Status: resolution.Status, ErrorMessage: resolution.ErrorMessage}, nil
+}
+
+func (s *jellyfinChangeStoreStub) GetSyncSettings(_ context.Context, animeID int64) (*models.AnimeJellyfinSyncSettings, error) {
+	return &models.AnimeJellyfinSyncSettings{AnimeID: animeID, AutoApply: s.autoApply}, nil
+}
+
+func (s *jellyfinChangeStoreStub) SetAutoApply(_ context.Context, animeID int64, autoApply bool, _ *int64) (*models.AnimeJellyfinSyncSettings, error) {
+	s.autoApply = autoApply
+	return &models.AnimeJellyfinSyncSettings{AnimeID: animeID, AutoApply: autoApply}, nil
+}
+
+type jellyfinAnimeMatcherStub struct {
+	matches   []repository.ExistingJellyfinAnimeMatch
+	seriesIDs []string
+}
+
+func (s *jellyfinAnimeMatcherStub) FindExistingAnimeByJellyfinIntakeRefs(_ context.Context, seriesIDs []string, _ []string) ([]repository.ExistingJellyfinAnimeMatch, error) {
+	s.seriesIDs = append(s.seriesIDs, seriesIDs...)
+	return s.matches, nil
+}
+
+func newJellyfinWebhookTestHandler(store *jellyfinChangeStoreStub, matcher *jellyfinAnimeMatcherStub) *AdminContentHandler {
+	h := &AdminContentHandler{jellyfinAnimeMatcher: matcher}
+	return h.WithJellyfinWebhook(store, "hook-secret")
+}
+
+func serveJellyfinWebhook(h *AdminContentHandler, secret string, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/jellyfin/webhook", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		c.Request.Header.Set(jellyfinWebhookSecretHeader, secret)
+	}
+	h.ReceiveJellyfinWebhook(c)
+	return rec
+}
+
+func TestReceiveJellyfinWebhookRequiresConfiguredSecret(t *testing.T) {
+	body := `{"NotificationType":"ItemAdded","ItemType":"Episode","ItemId":"ep-1","SeriesId":"series-1"}`
+
+	unconfigured := (&AdminContentHandler{}).WithJellyfinWebhook(&jellyfinChangeStoreStub{}, "")
+	if rec := serveJellyfinWebhook(unconfigured, "hook-secret", body); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without secret, got %d", rec.Code)
+	}
+
+	store := &jellyfinChangeStoreStub{}
+	h := newJellyfinWebhookTestHandler(store, &jellyfinAnimeMatcherStub{})
+	for _, secret := range []string{"", "wrong"} {
+		if rec := serveJellyfinWebhook(h, secret, body); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for secret %q, got %d", secret, rec.Code)
+		}
+	}
+	if len(store.inputs) != 0 {
+		t.Fatalf("expected no queued changes, got %+v", store.inputs)
+	}
+}
+
+func TestReceiveJellyfinWebhookIgnoresUnsupportedEvents(t *testing.T) {
+	matcher := &jellyfinAnimeMatcherStub{}
+	h := newJellyfinWebhookTestHandler(&jellyfinChangeStoreStub{}, matcher)
+
+	cases := map[string]string{
+		`{"NotificationType":"PlaybackStart","ItemType":"Episode","ItemId":"ep-1","SeriesId":"series-1"}`: "unsupported_notification_type",
+		`{"NotificationType":"ItemAdded","ItemType":"Series","ItemId":"series-1"}`:                        "unsupported_item_type",
+		`{"NotificationType":"ItemAdded","ItemType":"Episode","ItemId":"ep-1","SeriesId":"unknown"}`:      "unmatched_series",
+	}
+	for body, reason := range cases {
+		rec := serveJellyfinWebhook(h, "hook-secret", body)
+		if rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), `"reason":"`+reason+`"`) {
+			t.Fatalf("expected ignored %s, got %d %s", reason, rec.Code, rec.Body.String())
+		}
+	}
+	if len(matcher.seriesIDs) != 1 || matcher.seriesIDs[0] != "unknown" {
+		t.Fatalf("expected only the episode event to be matched, got %v", matcher.seriesIDs)
+	}
+}
+
+func TestReceiveJellyfinWebhookQueuesChangePerMatchedAnime(t *testing.T) {
+	store := &jellyfinChangeStoreStub{}
+	matcher := &jellyfinAnimeMatcherStub{matches: []repository.ExistingJellyfinAnimeMatch{{AnimeID: 7}, {AnimeID: 9}}}
+	h := newJellyfinWebhookTestHandler(store, matcher)
+
+	rec := serveJellyfinWebhook(h, "hook-secret", `{
+		"NotificationType":"ItemUpdated","ItemType":"Episode","ItemId":" ep-3 ","SeriesId":"series-1",
+		"Name":"Folge 3","SeriesName":"Frieren","SeasonNumber":"01","EpisodeNumber":3
+	}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(store.inputs) != 2 {
+		t.Fatalf("expected one change per anime, got %+v", store.inputs)
+	}
+	input := store.inputs[0]
+	if input.AnimeID != 7 || input.EventType != models.JellyfinChangeEventItemUpdated || input.JellyfinItemID != "ep-3" {
+		t.Fatalf("unexpected change input %+v", input)
+	}
+	if input.SeasonNumber == nil || *input.SeasonNumber != 1 || input.EpisodeNumber == nil || *input.EpisodeNumber != 3 {
+		t.Fatalf("expected parsed season/episode numbers, got %v/%v", input.SeasonNumber, input.EpisodeNumber)
+	}
+	if len(store.resolutions) != 0 {
+		t.Fatalf("expected no auto-apply, got %+v", store.resolutions)
+	}
+
+	var response struct {
+		Data struct {
+			Status  string                         `json:"status"`
+			Changes []models.JellyfinPendingChange `json:"changes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Data.Status != "queued" || len(response.Data.Changes) != 2 || response.Data.Changes[1].AnimeID != 9 {
+		t.Fatalf("unexpected response %s", rec.Body.String())
+	}
+}
+
+func TestReceiveJellyfinWebhookAutoApplyRecordsFailure(t *testing.T) {
+	store := &jellyfinChangeStoreStub{autoApply: true}
+	h := newJellyfinWebhookTestHandler(store, &jellyfinAnimeMatcherStub{matches: []repository.ExistingJellyfinAnimeMatch{{AnimeID: 7}}})
+	h.WithMediaServer(mediaservertest.NewProvider())
+
+	rec := serveJellyfinWebhook(h, "hook-secret", `{"NotificationType":"ItemDeleted","ItemType":"Episode","ItemId":"ep-3","SeriesId":"series-1"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(store.resolutions) != 1 {
+		t.Fatalf("expected auto-apply to resolve the change, got %+v", store.resolutions)
+	}
+	resolution := store.resolutions[0]
+	if resolution.Status != models.JellyfinPendingChangeStatusFailed || resolution.ErrorMessage == nil || resolution.ResolvedByAppUserID != nil {
+		t.Fatalf("expected failed auto-apply without actor, got %+v", resolution)
+	}
+	if !strings.Contains(rec.Body.String(), `"status":"failed"`) {
+		t.Fatalf("expected failed change in response, got %s", rec.Body.String())
+	}
+}
+
+// jellyfinItemVersionStub hält Provider-Versionen je Mediaserver-Item im Speicher.
+type jellyfinItemVersionStub struct {
+	versions []jellyfinItemVersionStubEntry
+}
+
+type jellyfinItemVersionStubEntry struct {
+	animeID       int64
+	season        int32
+	episodeNumber int32
+	mediaItemID   string
+}
+
+func (s *jellyfinItemVersionStub) DeleteByAnimeMediaItemAndProvider(_ context.Context, animeID int64, mediaItemID string, _ string) (int64, error) {
+	kept := s.versions[:0]
+	var deleted int64
+	for _, version := range s.versions {
+		if version.animeID == animeID && version.mediaItemID == mediaItemID {
+			deleted++
+			continue
+		}
+		kept = append(kept, version)
+	}
+	s.versions = kept
+	return deleted, nil
+}
+
+func TestReceiveJellyfinWebhookItemRemovedDeletesOnlyRemovedItem(t *testing.T) {
+	store := &jellyfinChangeStoreStub{autoApply: true}
+	h := newJellyfinWebhookTestHandler(store, &jellyfinAnimeMatcherStub{matches: []repository.ExistingJellyfinAnimeMatch{{AnimeID: 7}}})
+	h.WithMediaServer(mediaservertest.NewProvider())
+	versions := &jellyfinItemVersionStub{versions: []jellyfinItemVersionStubEntry{
+		{animeID: 7, season: 1, episodeNumber: 1, mediaItemID: "ep-s1e1"},
+		{animeID: 7, season: 2, episodeNumber: 1, mediaItemID: "ep-s2e1"},
+	}}
+	h.jellyfinItemVersions = versions
+
+	rec := serveJellyfinWebhook(h, "hook-secret", `{
+		"NotificationType":"ItemDeleted","ItemType":"Episode","ItemId":"ep-s1e1","SeriesId":"series-1",
+		"SeasonNumber":1,"EpisodeNumber":1
+	}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(store.resolutions) != 1 || store.resolutions[0].Status != models.JellyfinPendingChangeStatusApplied {
+		t.Fatalf("expected applied change, got %+v", store.resolutions)
+	}
+	if len(versions.versions) != 1 || versions.versions[0].mediaItemID != "ep-s2e1" {
+		t.Fatalf("expected only the removed item's version to be deleted, got %+v", versions.versions)
+	}
+}
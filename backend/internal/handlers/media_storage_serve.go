@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path"
+
+	"team4s.v3/backend/internal/blobstore"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaStorageHandler liefert Dateien unter /media/* aus dem BlobStore aus. Bei einem Bucket mit
+// aktivierter Vorsignierung wird auf eine zeitlich begrenzte Direkt-URL umgeleitet, sonst wird die
+// Datei durch das Backend gereicht (inklusive Range-Anfragen).
+type MediaStorageHandler struct {
+	store blobstore.BlobStore
+}
+
+// NewMediaStorageHandler erstellt einen neuen MediaStorageHandler.
+func NewMediaStorageHandler(store blobstore.BlobStore) *MediaStorageHandler {
+	return &MediaStorageHandler{store: store}
+}
+
+// ServeFile verarbeitet GET/HEAD /media/*filepath. Verzeichnisse und Pfade außerhalb des
+// Medienstamms liefern 404.
+func (h *MediaStorageHandler) ServeFile(c *gin.Context) {
+	key, ok := blobstore.CleanKey(c.Param("filepath"))
+	if !ok {
+		notFound(c, "datei nicht gefunden")
+		return
+	}
+
+	err := serveStoredMedia(c, h.store, key, "", "")
+	if errors.Is(err, blobstore.ErrNotExist) {
+		notFound(c, "datei nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("media storage serve: failed (key=%q): %v", key, err)
+		internalError(c, "interner serverfehler")
+	}
+}
+
+// serveStoredMedia liefert key aus store aus. Leere contentType/cacheControl lassen die Header
+// unverändert bzw. überlassen den Typ http.ServeContent. Vorsignierte URLs werden nicht gecacht,
+// da sie ablaufen. Fehlt das Objekt, gilt errors.Is(err, blobstore.ErrNotExist) und es wurde
+// noch nichts geschrieben.
+func serveStoredMedia(c *gin.Context, store blobstore.BlobStore, key string, contentType string, cacheControl string) error {
+	ctx := c.Request.Context()
+	signedURL, signed, err := store.SignedURL(ctx, key)
+	if err != nil {
+		return err
+	}
+	if signed {
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, signedURL)
+		return nil
+	}
+	return proxyStoredMedia(c, store, key, contentType, cacheControl)
+}
+
+// proxyStoredMedia reicht key immer durch das Backend, z.B. für WebVTT-Spuren, die ohne CORS-Header
+// des Buckets nicht per Redirect geladen werden können.
+func proxyStoredMedia(c *gin.Context, store blobstore.BlobStore, key string, contentType string, cacheControl string) error {
+	object, err := store.Open(c.Request.Context(), key)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	info := object.Info()
+	if contentType == "" {
+		contentType = info.ContentType
+	}
+	if contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	if cacheControl != "" {
+		c.Header("Cache-Control", cacheControl)
+	}
+	http.ServeContent(c.Writer, c.Request, path.Base(key), info.ModTime, object)
+	return nil
+}
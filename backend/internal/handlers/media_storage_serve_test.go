@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/blobstore"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signingBlobStore simuliert einen Bucket mit aktivierter Vorsignierung.
+type signingBlobStore struct {
+	*blobstore.LocalStore
+}
+
+func (s signingBlobStore) SignedURL(_ context.Context, key string) (string, bool, error) {
+	return "https://bucket.example/" + key + "?X-Amz-Signature=abc", true, nil
+}
+
+func TestMediaStorageHandlerServeFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := blobstore.NewLocalStore(t.TempDir())
+	if err := store.Put(context.Background(), "anime/7/cover/x.jpg", strings.NewReader("0123456789"), 10, "image/jpeg"); err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+
+	serve := func(handler *MediaStorageHandler, target string, header http.Header) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/media/*filepath", handler.ServeFile)
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	handler := NewMediaStorageHandler(store)
+	rec := serve(handler, "/media/anime/7/cover/x.jpg", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" || rec.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("unexpected response %d %q %q", rec.Code, rec.Body.String(), rec.Header().Get("Content-Type"))
+	}
+
+	rec = serve(handler, "/media/anime/7/cover/x.jpg", http.Header{"Range": {"bytes=6-"}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "6789" {
+		t.Fatalf("expected ranged response, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	for _, target := range []string{"/media/anime/7", "/media/anime/7/cover/missing.jpg", "/media/"} {
+		if rec := serve(handler, target, nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s, got %d", target, rec.Code)
+		}
+	}
+
+	rec = serve(NewMediaStorageHandler(signingBlobStore{store}), "/media/anime/7/cover/x.jpg", nil)
+	if rec.Code != http.StatusFound || !strings.HasPrefix(rec.Header().Get("Location"), "https://bucket.example/anime/7/cover/x.jpg?") {
+		t.Fatalf("expected redirect to signed url, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("signed redirects must not be cached, got %q", rec.Header().Get("Cache-Control"))
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/middleware"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
@@ -69,6 +70,7 @@ type mediaUploadLifecycle interface {
 type MediaUploadHandler struct {
 	repo            repository.MediaUploadRepoTx
 	lifecycle       mediaUploadLifecycle
+	blobs           blobstore.BlobStore
 	mediaStorageDir string
 	mediaBaseURL    string
 	ffmpegPath      string
@@ -77,12 +79,21 @@ type MediaUploadHandler struct {
 func NewMediaUploadHandler(repo repository.MediaUploadRepoTx, storageDir, baseURL, ffmpegPath string) *MediaUploadHandler {
 	return &MediaUploadHandler{
 		repo:            repo,
+		blobs:           blobstore.NewLocalStore(storageDir),
 		mediaStorageDir: storageDir,
 		mediaBaseURL:    baseURL,
 		ffmpegPath:      ffmpegPath,
 	}
 }
 
+// WithBlobStore setzt die Ablage für Originale und Thumbnails (Standard: lokales Medienverzeichnis).
+func (h *MediaUploadHandler) WithBlobStore(store blobstore.BlobStore) *MediaUploadHandler {
+	if store != nil {
+		h.blobs = store
+	}
+	return h
+}
+
 func (h *MediaUploadHandler) WithLifecycleService(lifecycle mediaUploadLifecycle) *MediaUploadHandler {
 	h.lifecycle = lifecycle
 	return h
@@ -161,12 +172,6 @@ func (h *MediaUploadHandler) Upload(c *gin.Context) {
 		return
 	}
 
-	if err := os.MkdirAll(storagePath, 0755); err != nil {
-		log.Printf("media_upload: failed to create directory: %v", err)
-		h.writeUploadError(c, http.StatusInternalServerError, fmt.Sprintf("upload-verzeichnis konnte nicht erstellt werden: %v", err), "media_upload.storage_prepare_failed", "")
-		return
-	}
-
 	// Process file based on format
 	var uploadResp *models.UploadResponse
 	if format == "image" {
@@ -337,6 +342,14 @@ func (h *MediaUploadHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "media nicht gefunden"})
 		return
 	}
+	files, err := h.repo.GetMediaFiles(c.Request.Context(), mediaID)
+	if err != nil {
+		log.Printf("media_upload: get files failed: %v", err)
+	}
+	filePaths := make([]string, 0, len(files))
+	for _, file := range files {
+		filePaths = append(filePaths, file.Path)
+	}
 
 	// Delete from database
 	if err := h.repo.DeleteMediaAsset(c.Request.Context(), mediaID); err != nil {
@@ -361,10 +374,8 @@ func (h *MediaUploadHandler) Delete(c *gin.Context) {
 		log.Printf("media_upload: path traversal attempt detected during delete: %s", storagePath)
 		// Don't fail the request since DB deletion succeeded
 	} else {
-		if err := os.RemoveAll(storagePath); err != nil {
-			log.Printf("media_upload: delete directory failed: %v", err)
-			// Don't fail the request if directory deletion fails
-		}
+		// Don't fail the request if file deletion fails
+		h.cleanupUpload(c.Request.Context(), storagePath, filePaths...)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
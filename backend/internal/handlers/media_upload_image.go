@@ -1,11 +1,12 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"image"
+	"io"
 	"mime/multipart"
-	"path/filepath"
 	"time"
 
 	"team4s.v3/backend/internal/models"
@@ -51,20 +52,23 @@ func (h *MediaUploadHandler) processImage(
 	originalFilename := "original." + ext
 	thumbFilename := "thumb." + ext
 
-	originalPath := filepath.Join(storagePath, originalFilename)
 	originalRelPath := h.buildRelativePath(req.EntityType, req.EntityID, req.AssetType, mediaID, originalFilename)
+	var originalSize int64
 	if format == "gif" && h.isAnimatedGIF(file) {
-		originalPath = filepath.Join(storagePath, "original.gif")
 		originalRelPath = h.buildRelativePath(req.EntityType, req.EntityID, req.AssetType, mediaID, "original.gif")
 		file.Seek(0, 0)
-		if err := h.saveFile(file, originalPath); err != nil {
+		data, err := io.ReadAll(file)
+		if err == nil {
+			err = h.storeUploadFile(ctx, originalRelPath, bytes.NewReader(data), int64(len(data)))
+		}
+		if err != nil {
 			return nil, fmt.Errorf("original gif speichern: %w", err)
 		}
-	} else if err := imaging.Save(img, originalPath); err != nil {
+		originalSize = int64(len(data))
+	} else if originalSize, err = h.storeUploadImage(ctx, originalRelPath, img); err != nil {
 		return nil, fmt.Errorf("original speichern: %w", err)
 	}
 
-	originalSize, _ := h.getFileSize(originalPath)
 	files := []models.UploadFileInfo{{
 		Variant: "original",
 		Path:    originalRelPath,
@@ -72,15 +76,15 @@ func (h *MediaUploadHandler) processImage(
 		Height:  originalHeight,
 	}}
 
-	thumbPath := filepath.Join(storagePath, thumbFilename)
 	thumbRelPath := h.buildRelativePath(req.EntityType, req.EntityID, req.AssetType, mediaID, thumbFilename)
 	thumb := imaging.Resize(img, thumbWidth, 0, imaging.Lanczos)
-	if err := imaging.Save(thumb, thumbPath); err != nil {
+	thumbSize, err := h.storeUploadImage(ctx, thumbRelPath, thumb)
+	if err != nil {
+		h.cleanupUpload(ctx, storagePath, originalRelPath)
 		return nil, fmt.Errorf("thumbnail speichern: %w", err)
 	}
 
 	thumbBounds := thumb.Bounds()
-	thumbSize, _ := h.getFileSize(thumbPath)
 	files = append(files, models.UploadFileInfo{
 		Variant: "thumb",
 		Path:    thumbRelPath,
@@ -90,7 +94,7 @@ func (h *MediaUploadHandler) processImage(
 
 	usePathFallback, err := h.shouldUseAnimePosterPathFallback(ctx, req)
 	if err != nil {
-		h.cleanupStoragePath(storagePath)
+		h.cleanupUpload(ctx, storagePath, originalRelPath, thumbRelPath)
 		return nil, err
 	}
 	if usePathFallback {
@@ -142,7 +146,7 @@ func (h *MediaUploadHandler) processImage(
 		return nil
 	})
 	if txErr != nil {
-		h.cleanupStoragePath(storagePath)
+		h.cleanupUpload(ctx, storagePath, originalRelPath, thumbRelPath)
 		return nil, txErr
 	}
 
@@ -158,7 +162,3 @@ func (h *MediaUploadHandler) processImage(
 func (h *MediaUploadHandler) isAnimatedGIF(file multipart.File) bool {
 	return true
 }
-
-func (h *MediaUploadHandler) saveAsWebP(img image.Image, path string) error {
-	return imaging.Save(img, path)
-}
@@ -1,32 +1,68 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"image"
 	"io"
 	"log"
+	"mime"
 	"os"
+	"path"
 	"strings"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/repository"
 )
 
-func (h *MediaUploadHandler) saveFile(reader io.Reader, path string) error {
-	out, err := os.Create(path)
+// uploadKey bildet einen "/media/…"-Pfad aus buildRelativePath auf den Schlüssel im BlobStore ab.
+func (h *MediaUploadHandler) uploadKey(relativePath string) (string, error) {
+	key, ok := blobstore.KeyFromPath(h.mediaStorageDir, relativePath)
+	if !ok {
+		return "", fmt.Errorf("media path %q is outside the media storage", relativePath)
+	}
+	return key, nil
+}
+
+// storeUploadFile legt body unter relativePath im BlobStore ab.
+func (h *MediaUploadHandler) storeUploadFile(ctx context.Context, relativePath string, body io.Reader, size int64) error {
+	key, err := h.uploadKey(relativePath)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
+	return h.blobs.Put(ctx, key, body, size, mime.TypeByExtension(path.Ext(relativePath)))
+}
 
-	_, err = io.Copy(out, reader)
-	return err
+// storeUploadImage kodiert img passend zur Dateiendung und legt es unter relativePath ab.
+// Liefert die geschriebene Größe in Bytes.
+func (h *MediaUploadHandler) storeUploadImage(ctx context.Context, relativePath string, img image.Image) (int64, error) {
+	data, err := encodeImageFile(img, relativePath)
+	if err != nil {
+		return 0, err
+	}
+	if err := h.storeUploadFile(ctx, relativePath, bytes.NewReader(data), int64(len(data))); err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
 }
 
-func (h *MediaUploadHandler) getFileSize(path string) (int64, error) {
-	info, err := os.Stat(path)
+// storeUploadLocalFile kopiert eine lokal erzeugte Datei (z.B. aus ffmpeg) unter relativePath in
+// den BlobStore. Liefert die Größe in Bytes.
+func (h *MediaUploadHandler) storeUploadLocalFile(ctx context.Context, relativePath string, localPath string) (int64, error) {
+	file, err := os.Open(localPath)
 	if err != nil {
 		return 0, err
 	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if err := h.storeUploadFile(ctx, relativePath, file, info.Size()); err != nil {
+		return 0, err
+	}
 	return info.Size(), nil
 }
 
@@ -57,8 +93,34 @@ func (h *MediaUploadHandler) createJoinTableEntryWithRepo(ctx context.Context, r
 	}
 }
 
-func (h *MediaUploadHandler) cleanupStoragePath(storagePath string) {
+// cleanupUpload entfernt die bereits abgelegten Dateien eines Uploads. Bei lokaler Ablage wird
+// zusätzlich das Upload-Verzeichnis storagePath gelöscht.
+func (h *MediaUploadHandler) cleanupUpload(ctx context.Context, storagePath string, relativePaths ...string) {
+	for _, relativePath := range relativePaths {
+		key, err := h.uploadKey(relativePath)
+		if err != nil {
+			continue
+		}
+		if err := h.blobs.Delete(ctx, key); err != nil {
+			log.Printf("media_upload: failed to delete %s: %v", key, err)
+		}
+	}
+	if _, local := blobstore.LocalRoot(h.blobs); !local || storagePath == "" {
+		return
+	}
 	if err := os.RemoveAll(storagePath); err != nil {
 		log.Printf("media_upload: failed to cleanup storage path %s: %v", storagePath, err)
 	}
 }
+
+// saveFile schreibt reader in eine lokale Arbeitsdatei.
+func (h *MediaUploadHandler) saveFile(reader io.Reader, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, reader)
+	return err
+}
@@ -38,14 +38,21 @@ func (h *MediaUploadHandler) processVideo(
 		return nil, err
 	}
 
+	// ffprobe und ffmpeg brauchen lokale Dateien: Verarbeitung in einem Arbeitsverzeichnis,
+	// danach landen Original und Thumbnail im BlobStore.
+	workDir, err := os.MkdirTemp("", "media-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("arbeitsverzeichnis erstellen: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
 	originalFilename := "original" + ext
-	originalPath := filepath.Join(storagePath, originalFilename)
+	originalPath := filepath.Join(workDir, originalFilename)
 	originalRelPath := h.buildRelativePath(req.EntityType, req.EntityID, req.AssetType, mediaID, originalFilename)
 	if err := h.saveFile(file, originalPath); err != nil {
 		return nil, fmt.Errorf("video speichern: %w", err)
 	}
 
-	originalSize, _ := h.getFileSize(originalPath)
 	width, height, duration, err := h.getVideoMetadata(originalPath)
 	if err != nil {
 		log.Printf("warning: failed to get video metadata: %v", err)
@@ -59,7 +66,7 @@ func (h *MediaUploadHandler) processVideo(
 		Height:  height,
 	}}
 
-	thumbPath := filepath.Join(storagePath, "thumb.jpg")
+	thumbPath := filepath.Join(workDir, "thumb.jpg")
 	thumbRelPath := h.buildRelativePath(req.EntityType, req.EntityID, req.AssetType, mediaID, "thumb.jpg")
 	extractTime := videoThumbTimeSec
 	if duration > 0 && duration < videoThumbTimeSec {
@@ -79,7 +86,6 @@ func (h *MediaUploadHandler) processVideo(
 		thumbHeight = thumbBounds.Dy()
 	}
 
-	thumbSize, _ := h.getFileSize(thumbPath)
 	files = append(files, models.UploadFileInfo{
 		Variant: "thumb",
 		Path:    thumbRelPath,
@@ -87,6 +93,17 @@ func (h *MediaUploadHandler) processVideo(
 		Height:  thumbHeight,
 	})
 
+	originalSize, err := h.storeUploadLocalFile(ctx, originalRelPath, originalPath)
+	if err != nil {
+		h.cleanupUpload(ctx, storagePath, originalRelPath)
+		return nil, fmt.Errorf("video speichern: %w", err)
+	}
+	thumbSize, err := h.storeUploadLocalFile(ctx, thumbRelPath, thumbPath)
+	if err != nil {
+		h.cleanupUpload(ctx, storagePath, originalRelPath, thumbRelPath)
+		return nil, fmt.Errorf("thumbnail speichern: %w", err)
+	}
+
 	txErr := h.repo.WithTx(ctx, func(txRepo repository.MediaUploadRepo) error {
 		asset := &models.UploadMediaAsset{
 			ID:         mediaID,
@@ -126,7 +143,7 @@ func (h *MediaUploadHandler) processVideo(
 		return nil
 	})
 	if txErr != nil {
-		h.cleanupStoragePath(storagePath)
+		h.cleanupUpload(ctx, storagePath, originalRelPath, thumbRelPath)
 		return nil, txErr
 	}
 
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"team4s.v3/backend/internal/permissions"
 	"team4s.v3/backend/internal/repository"
+	"team4s.v3/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +18,7 @@ type MemberClaimsHandler struct {
 	claimsRepo    *repository.MemberClaimsRepository
 	permissionSvc *permissions.Service
 	auditLogRepo  auditLogWriter
+	notifications *services.NotificationService
 }
 
 func NewMemberClaimsHandler(
@@ -30,6 +33,12 @@ func NewMemberClaimsHandler(
 	}
 }
 
+// WithNotifications benachrichtigt Antragsteller, sobald ihr Claim bestätigt wurde.
+func (h *MemberClaimsHandler) WithNotifications(notifications *services.NotificationService) *MemberClaimsHandler {
+	h.notifications = notifications
+	return h
+}
+
 type submitMemberClaimRequest struct {
 	MemberID int64  `json:"member_id"`
 	Note     string `json:"note"`
@@ -155,6 +164,9 @@ func (h *MemberClaimsHandler) VerifyClaim(c *gin.Context) {
 	}
 
 	h.writeAudit(c, identity.AppUserID, "member_claim.verified", fansubID, "member_claim", claimID, "verify", nil)
+	if err := h.notifications.NotifyMemberClaimVerified(c.Request.Context(), claimID, identity.AppUserID); err != nil {
+		log.Printf("member claims: notify verified failed (claim_id=%d): %v", claimID, err)
+	}
 	c.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "Claim erfolgreich bestätigt."}})
 }
 
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notificationDigestRepository definiert die Datenbankoperationen des NotificationDigestHandlers.
+type notificationDigestRepository interface {
+	GetSettings(ctx context.Context, appUserID int64) (*models.DigestSettings, error)
+	UpdateSettings(ctx context.Context, appUserID int64, update models.DigestSettingsUpdate) (*models.DigestSettings, error)
+	Unsubscribe(ctx context.Context, token string) error
+}
+
+// digestSchedule berechnet den nächsten Sendezeitpunkt (services.NotificationDigestService).
+type digestSchedule interface {
+	NextDue(frequency string, after time.Time) *time.Time
+}
+
+// NotificationDigestHandler verwaltet die Zusammenfassungs-Mail unter /me/digest-settings
+// und das öffentliche Abbestellen per Token.
+type NotificationDigestHandler struct {
+	repo     notificationDigestRepository
+	schedule digestSchedule
+}
+
+// NewNotificationDigestHandler erstellt einen neuen NotificationDigestHandler.
+func NewNotificationDigestHandler(repo notificationDigestRepository, schedule digestSchedule) *NotificationDigestHandler {
+	return &NotificationDigestHandler{repo: repo, schedule: schedule}
+}
+
+type updateDigestSettingsRequest struct {
+	Frequency string `json:"frequency"`
+	Locale    string `json:"locale"`
+}
+
+type unsubscribeDigestRequest struct {
+	Token string `json:"token"`
+}
+
+// GetSettings verarbeitet GET /api/v1/me/digest-settings.
+func (h *NotificationDigestHandler) GetSettings(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.repo.GetSettings(c.Request.Context(), identity.AppUserID)
+	if err != nil {
+		log.Printf("notification digest: get settings failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// UpdateSettings verarbeitet PUT /api/v1/me/digest-settings mit frequency=off|daily|weekly
+// und optionaler locale (ohne Angabe bleibt die bisherige Sprache).
+func (h *NotificationDigestHandler) UpdateSettings(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	var req updateDigestSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	frequency := strings.ToLower(strings.TrimSpace(req.Frequency))
+	if !models.IsDigestFrequency(frequency) {
+		badRequest(c, "ungültige frequency")
+		return
+	}
+	locale := strings.ToLower(strings.TrimSpace(req.Locale))
+	if locale != "" && !models.IsMailLocale(locale) {
+		badRequest(c, "ungültige sprache")
+		return
+	}
+
+	if locale == "" {
+		current, err := h.repo.GetSettings(c.Request.Context(), identity.AppUserID)
+		if err != nil {
+			log.Printf("notification digest: get settings failed (app_user_id=%d): %v", identity.AppUserID, err)
+			internalError(c, "interner serverfehler")
+			return
+		}
+		locale = current.Locale
+	}
+
+	updated, err := h.repo.UpdateSettings(c.Request.Context(), identity.AppUserID, models.DigestSettingsUpdate{
+		Frequency: frequency,
+		Locale:    locale,
+		NextDueAt: h.schedule.NextDue(frequency, time.Now()),
+	})
+	if err != nil {
+		log.Printf("notification digest: update settings failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// Unsubscribe verarbeitet POST /api/v1/digest/unsubscribe. Der Token stammt aus dem Link in
+// der Digest-Mail; eine Anmeldung ist nicht nötig.
+func (h *NotificationDigestHandler) Unsubscribe(c *gin.Context) {
+	var req unsubscribeDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Token) == "" {
+		badRequest(c, "token fehlt")
+		return
+	}
+
+	err := h.repo.Unsubscribe(c.Request.Context(), req.Token)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		notFound(c, "abmelde-link ungültig")
+		return
+	case err != nil:
+		log.Printf("notification digest: unsubscribe failed: %v", err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"frequency": models.DigestFrequencyOff}})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+)
+
+type stubNotificationDigestRepository struct {
+	current     models.DigestSettings
+	lastUpdate  models.DigestSettingsUpdate
+	knownToken  string
+	unsubscribe []string
+}
+
+func (s *stubNotificationDigestRepository) GetSettings(context.Context, int64) (*models.DigestSettings, error) {
+	settings := s.current
+	return &settings, nil
+}
+
+func (s *stubNotificationDigestRepository) UpdateSettings(_ context.Context, _ int64, update models.DigestSettingsUpdate) (*models.DigestSettings, error) {
+	s.lastUpdate = update
+	return &models.DigestSettings{Frequency: update.Frequency, Locale: update.Locale, NextDueAt: update.NextDueAt}, nil
+}
+
+func (s *stubNotificationDigestRepository) Unsubscribe(_ context.Context, token string) error {
+	if token != s.knownToken {
+		return repository.ErrNotFound
+	}
+	s.unsubscribe = append(s.unsubscribe, token)
+	return nil
+}
+
+type fixedDigestSchedule struct{}
+
+func (fixedDigestSchedule) NextDue(frequency string, _ time.Time) *time.Time {
+	if frequency == models.DigestFrequencyOff {
+		return nil
+	}
+	next := time.Date(2026, 6, 2, 7, 0, 0, 0, time.UTC)
+	return &next
+}
+
+func TestNotificationDigestHandlerUpdateSettings(t *testing.T) {
+	repo := &stubNotificationDigestRepository{current: models.DigestSettings{Frequency: models.DigestFrequencyOff, Locale: models.MailLocaleEN}}
+	handler := NewNotificationDigestHandler(repo, fixedDigestSchedule{})
+
+	c, rec := newNotificationTestContext(http.MethodPut, "/api/v1/me/digest-settings", `{"frequency":"Daily"}`)
+	handler.UpdateSettings(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d with body %s", rec.Code, rec.Body.String())
+	}
+	if repo.lastUpdate.Frequency != models.DigestFrequencyDaily || repo.lastUpdate.Locale != models.MailLocaleEN {
+		t.Fatalf("expected daily digest keeping the stored locale, got %+v", repo.lastUpdate)
+	}
+	if repo.lastUpdate.NextDueAt == nil {
+		t.Fatal("expected next due time for an enabled digest")
+	}
+
+	for _, body := range []string{`{"frequency":"hourly"}`, `{"frequency":"weekly","locale":"fr"}`} {
+		c, rec = newNotificationTestContext(http.MethodPut, "/api/v1/me/digest-settings", body)
+		handler.UpdateSettings(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestNotificationDigestHandlerUnsubscribe(t *testing.T) {
+	repo := &stubNotificationDigestRepository{knownToken: "abc"}
+	handler := NewNotificationDigestHandler(repo, fixedDigestSchedule{})
+
+	c, rec := newNotificationTestContext(http.MethodPost, "/api/v1/digest/unsubscribe", `{"token":"abc"}`)
+	handler.Unsubscribe(c)
+	if rec.Code != http.StatusOK || len(repo.unsubscribe) != 1 {
+		t.Fatalf("expected successful unsubscribe, got %d with body %s", rec.Code, rec.Body.String())
+	}
+
+	c, rec = newNotificationTestContext(http.MethodPost, "/api/v1/digest/unsubscribe", `{"token":"unknown"}`)
+	handler.Unsubscribe(c)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown token, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxNotificationMarkReadIDs = 200
+
+// notificationRepository definiert die Datenbankoperationen des NotificationHandlers.
+type notificationRepository interface {
+	ListForUser(ctx context.Context, appUserID int64, filter models.NotificationFilter) ([]models.Notification, int64, error)
+	CountUnread(ctx context.Context, appUserID int64) (int64, error)
+	MarkRead(ctx context.Context, appUserID int64, ids []int64) (int64, error)
+	ListPreferences(ctx context.Context, appUserID int64) ([]models.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, appUserID int64, preferences []models.NotificationPreference) ([]models.NotificationPreference, error)
+}
+
+// NotificationHandler liefert das Benachrichtigungszentrum unter /me/notifications.
+type NotificationHandler struct {
+	repo notificationRepository
+}
+
+// NewNotificationHandler erstellt einen neuen NotificationHandler.
+func NewNotificationHandler(repo notificationRepository) *NotificationHandler {
+	return &NotificationHandler{repo: repo}
+}
+
+type markNotificationsReadRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type updateNotificationPreferencesRequest struct {
+	Preferences []models.NotificationPreference `json:"preferences"`
+}
+
+// List verarbeitet GET /api/v1/me/notifications (neueste zuerst, optional unread=true).
+func (h *NotificationHandler) List(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	page, err := parsePositiveInt(c.DefaultQuery("page", "1"))
+	if err != nil {
+		badRequest(c, "ungültiger page parameter")
+		return
+	}
+	perPage, err := parsePositiveInt(c.DefaultQuery("per_page", "20"))
+	if err != nil {
+		badRequest(c, "ungültiger per_page parameter")
+		return
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	unreadOnly, err := parseNotificationUnreadFlag(c.Query("unread"))
+	if err != nil {
+		badRequest(c, "ungültiger unread parameter")
+		return
+	}
+
+	items, total, err := h.repo.ListForUser(c.Request.Context(), identity.AppUserID, models.NotificationFilter{
+		UnreadOnly: unreadOnly,
+		Page:       page,
+		PerPage:    perPage,
+	})
+	if err != nil {
+		log.Printf("notification: list failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(perPage)))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": models.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// UnreadCount verarbeitet GET /api/v1/me/notifications/unread-count.
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.repo.CountUnread(c.Request.Context(), identity.AppUserID)
+	if err != nil {
+		log.Printf("notification: unread count failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"unread_count": count}})
+}
+
+// MarkRead verarbeitet POST /api/v1/me/notifications/read. Ohne ids werden alle
+// ungelesenen Benachrichtigungen als gelesen markiert.
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	var req markNotificationsReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			badRequest(c, "ungültiger request body")
+			return
+		}
+	}
+	ids, validationMessage := validateNotificationIDs(req.IDs)
+	if validationMessage != "" {
+		badRequest(c, validationMessage)
+		return
+	}
+
+	h.markRead(c, identity.AppUserID, ids)
+}
+
+// MarkReadByID verarbeitet POST /api/v1/me/notifications/:id/read.
+func (h *NotificationHandler) MarkReadByID(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		badRequest(c, "ungültige benachrichtigungs-id")
+		return
+	}
+
+	h.markRead(c, identity.AppUserID, []int64{id})
+}
+
+// GetPreferences verarbeitet GET /api/v1/me/notification-preferences.
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.repo.ListPreferences(c.Request.Context(), identity.AppUserID)
+	if err != nil {
+		log.Printf("notification: list preferences failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences})
+}
+
+// UpdatePreferences verarbeitet PUT /api/v1/me/notification-preferences. Nicht genannte
+// Ereignistypen bleiben unverändert.
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+
+	var req updateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+	preferences, validationMessage := validateNotificationPreferences(req.Preferences)
+	if validationMessage != "" {
+		badRequest(c, validationMessage)
+		return
+	}
+
+	updated, err := h.repo.UpdatePreferences(c.Request.Context(), identity.AppUserID, preferences)
+	if err != nil {
+		log.Printf("notification: update preferences failed (app_user_id=%d): %v", identity.AppUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+func (h *NotificationHandler) markRead(c *gin.Context, appUserID int64, ids []int64) {
+	updated, err := h.repo.MarkRead(c.Request.Context(), appUserID, ids)
+	if err != nil {
+		log.Printf("notification: mark read failed (app_user_id=%d): %v", appUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	unread, err := h.repo.CountUnread(c.Request.Context(), appUserID)
+	if err != nil {
+		log.Printf("notification: unread count failed (app_user_id=%d): %v", appUserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"updated": updated, "unread_count": unread}})
+}
+
+func parseNotificationUnreadFlag(raw string) (bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+func validateNotificationIDs(ids []int64) ([]int64, string) {
+	if len(ids) > maxNotificationMarkReadIDs {
+		return nil, "zu viele ids (max 200)"
+	}
+	seen := make(map[int64]struct{}, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, "ungültige benachrichtigungs-id"
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique, ""
+}
+
+func validateNotificationPreferences(raw []models.NotificationPreference) ([]models.NotificationPreference, string) {
+	if len(raw) == 0 {
+		return nil, "preferences ist erforderlich"
+	}
+
+	byType := make(map[string]string, len(raw))
+	order := make([]string, 0, len(raw))
+	for _, preference := range raw {
+		eventType := strings.ToLower(strings.TrimSpace(preference.EventType))
+		channel := strings.ToLower(strings.TrimSpace(preference.Channel))
+		if !models.IsNotificationEventType(eventType) {
+			return nil, "ungültiger event_type"
+		}
+		if !models.IsNotificationChannel(channel) {
+			return nil, "ungültiger channel (in_app, email oder off)"
+		}
+		if _, ok := byType[eventType]; !ok {
+			order = append(order, eventType)
+		}
+		byType[eventType] = channel
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(order))
+	for _, eventType := range order {
+		preferences = append(preferences, models.NotificationPreference{EventType: eventType, Channel: byType[eventType]})
+	}
+	return preferences, ""
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubNotificationRepository struct {
+	lastFilter  models.NotificationFilter
+	markedIDs   []int64
+	unreadCount int64
+}
+
+func (s *stubNotificationRepository) ListForUser(_ context.Context, _ int64, filter models.NotificationFilter) ([]models.Notification, int64, error) {
+	s.lastFilter = filter
+	return []models.Notification{}, 0, nil
+}
+
+func (s *stubNotificationRepository) CountUnread(context.Context, int64) (int64, error) {
+	return s.unreadCount, nil
+}
+
+func (s *stubNotificationRepository) MarkRead(_ context.Context, _ int64, ids []int64) (int64, error) {
+	s.markedIDs = ids
+	return int64(len(ids)), nil
+}
+
+func (s *stubNotificationRepository) ListPreferences(context.Context, int64) ([]models.NotificationPreference, error) {
+	return nil, nil
+}
+
+func (s *stubNotificationRepository) UpdatePreferences(_ context.Context, _ int64, preferences []models.NotificationPreference) ([]models.NotificationPreference, error) {
+	return preferences, nil
+}
+
+func newNotificationTestContext(method, target, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+	c.Set("auth_identity", middleware.AuthIdentity{UserID: 4, AppUserID: 12, DisplayName: "Nico"})
+	return c, rec
+}
+
+func TestNotificationHandlerListParsesUnreadFilter(t *testing.T) {
+	repo := &stubNotificationRepository{}
+	handler := NewNotificationHandler(repo)
+
+	c, rec := newNotificationTestContext(http.MethodGet, "/api/v1/me/notifications?unread=true&per_page=500", "")
+	handler.List(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !repo.lastFilter.UnreadOnly || repo.lastFilter.PerPage != 100 {
+		t.Fatalf("expected unread filter and capped per_page, got %+v", repo.lastFilter)
+	}
+
+	c, rec = newNotificationTestContext(http.MethodGet, "/api/v1/me/notifications?unread=vielleicht", "")
+	handler.List(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid unread flag, got %d", rec.Code)
+	}
+}
+
+func TestNotificationHandlerMarkReadDedupesIDs(t *testing.T) {
+	repo := &stubNotificationRepository{unreadCount: 3}
+	handler := NewNotificationHandler(repo)
+
+	c, rec := newNotificationTestContext(http.MethodPost, "/api/v1/me/notifications/read", `{"ids":[5,5,6]}`)
+	handler.MarkRead(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.markedIDs) != 2 {
+		t.Fatalf("expected deduplicated ids, got %v", repo.markedIDs)
+	}
+	if !strings.Contains(rec.Body.String(), `"unread_count":3`) {
+		t.Fatalf("expected unread count in response, got %s", rec.Body.String())
+	}
+
+	c, rec = newNotificationTestContext(http.MethodPost, "/api/v1/me/notifications/read", `{"ids":[0]}`)
+	handler.MarkRead(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid id, got %d", rec.Code)
+	}
+}
+
+func TestValidateNotificationPreferences(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []models.NotificationPreference
+		wantMessage string
+		wantLen     int
+	}{
+		{name: "empty", wantMessage: "preferences ist erforderlich"},
+		{name: "unknown event", input: []models.NotificationPreference{{EventType: "foo", Channel: "off"}}, wantMessage: "ungültiger event_type"},
+		{name: "unknown channel", input: []models.NotificationPreference{{EventType: models.NotificationEventCommentMention, Channel: "sms"}}, wantMessage: "ungültiger channel (in_app, email oder off)"},
+		{
+			name: "last entry wins and input is normalized",
+			input: []models.NotificationPreference{
+				{EventType: " Comment.Mention ", Channel: "EMAIL"},
+				{EventType: models.NotificationEventCommentMention, Channel: "off"},
+				{EventType: models.NotificationEventWatchlistNewEpisode, Channel: "in_app"},
+			},
+			wantLen: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, message := validateNotificationPreferences(tc.input)
+			if message != tc.wantMessage {
+				t.Fatalf("expected message %q, got %q", tc.wantMessage, message)
+			}
+			if len(got) != tc.wantLen {
+				t.Fatalf("expected %d preferences, got %+v", tc.wantLen, got)
+			}
+			if tc.wantLen > 0 && got[0].Channel != models.NotificationChannelOff {
+				t.Fatalf("expected later entry to win, got %+v", got[0])
+			}
+		})
+	}
+}
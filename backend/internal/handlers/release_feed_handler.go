@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	releaseFeedEntryLimit   = 50
+	releaseFeedSuffix       = ".atom"
+	releaseFeedContentType  = "application/atom+xml; charset=utf-8"
+	releaseFeedCacheControl = "public, max-age=300"
+	// releaseFeedFormatVersion fließt in den ETag ein, damit Formatänderungen alte Caches ungültig machen.
+	releaseFeedFormatVersion = "1"
+)
+
+// releaseFeedRepository definiert die Datenbankoperationen des ReleaseFeedHandlers.
+type releaseFeedRepository interface {
+	GetState(ctx context.Context, scope models.ReleaseFeedScope) (*models.ReleaseFeedState, error)
+	ListEntries(ctx context.Context, scope models.ReleaseFeedScope, limit int) ([]models.ReleaseFeedEntry, error)
+}
+
+// ReleaseFeedHandler liefert öffentliche Atom-Feeds neuer Release-Versionen unter /feeds.
+// Vor dem Laden der Einträge wird nur der Feed-Stand abgefragt; passt er zu If-None-Match
+// bzw. If-Modified-Since, antwortet der Handler mit 304.
+type ReleaseFeedHandler struct {
+	repo          releaseFeedRepository
+	publicBaseURL string
+}
+
+// NewReleaseFeedHandler erstellt einen neuen ReleaseFeedHandler. publicBaseURL macht die
+// Links auf Anime-, Gruppen- und Episodenseiten absolut.
+func NewReleaseFeedHandler(repo releaseFeedRepository, publicBaseURL string) *ReleaseFeedHandler {
+	return &ReleaseFeedHandler{
+		repo:          repo,
+		publicBaseURL: strings.TrimRight(strings.TrimSpace(publicBaseURL), "/"),
+	}
+}
+
+// Releases verarbeitet GET /feeds/releases.atom.
+func (h *ReleaseFeedHandler) Releases(c *gin.Context) {
+	h.serve(c, models.ReleaseFeedScope{Kind: models.ReleaseFeedScopeAll})
+}
+
+// Anime verarbeitet GET /feeds/anime/:id.atom.
+func (h *ReleaseFeedHandler) Anime(c *gin.Context) {
+	raw, ok := trimReleaseFeedSuffix(c.Param("id"))
+	if !ok {
+		notFound(c, "feed nicht gefunden")
+		return
+	}
+	animeID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || animeID <= 0 {
+		badRequest(c, "ungültige anime id")
+		return
+	}
+	h.serve(c, models.ReleaseFeedScope{Kind: models.ReleaseFeedScopeAnime, AnimeID: animeID})
+}
+
+// Fansub verarbeitet GET /feeds/fansubs/:slug.atom.
+func (h *ReleaseFeedHandler) Fansub(c *gin.Context) {
+	slug, ok := trimReleaseFeedSuffix(c.Param("slug"))
+	if !ok || strings.TrimSpace(slug) == "" {
+		notFound(c, "feed nicht gefunden")
+		return
+	}
+	h.serve(c, models.ReleaseFeedScope{Kind: models.ReleaseFeedScopeFansub, FansubSlug: strings.TrimSpace(slug)})
+}
+
+func (h *ReleaseFeedHandler) serve(c *gin.Context, scope models.ReleaseFeedScope) {
+	ctx := c.Request.Context()
+
+	state, err := h.repo.GetState(ctx, scope)
+	if errors.Is(err, repository.ErrNotFound) {
+		if scope.Kind == models.ReleaseFeedScopeAnime {
+			notFound(c, "anime nicht gefunden")
+		} else {
+			notFound(c, "fansubgruppe nicht gefunden")
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("release feed: state failed (scope=%s, anime_id=%d, slug=%q): %v", scope.Kind, scope.AnimeID, scope.FansubSlug, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	etag := releaseFeedETag(scope, state)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", releaseFeedCacheControl)
+	var lastModified time.Time
+	if state.LastModified != nil {
+		lastModified = state.LastModified.UTC().Truncate(time.Second)
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if releaseFeedNotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	entries, err := h.repo.ListEntries(ctx, scope, releaseFeedEntryLimit)
+	if err != nil {
+		log.Printf("release feed: entries failed (scope=%s, anime_id=%d, slug=%q): %v", scope.Kind, scope.AnimeID, scope.FansubSlug, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	body, err := h.renderFeed(scope, state, lastModified, entries)
+	if err != nil {
+		log.Printf("release feed: render failed (scope=%s): %v", scope.Kind, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+	c.Data(http.StatusOK, releaseFeedContentType, body)
+}
+
+// trimReleaseFeedSuffix entfernt die Endung .atom; Gin kann sie nicht im Pfadmuster abbilden.
+func trimReleaseFeedSuffix(value string) (string, bool) {
+	if !strings.HasSuffix(value, releaseFeedSuffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(value, releaseFeedSuffix), true
+}
+
+// releaseFeedETag bildet einen schwachen ETag aus Geltungsbereich und Feed-Stand. Anzahl und
+// höchste ID erfassen auch gelöschte oder ausgeblendete Versionen, die den Zeitstempel nicht ändern.
+func releaseFeedETag(scope models.ReleaseFeedScope, state *models.ReleaseFeedState) string {
+	var modified int64
+	if state.LastModified != nil {
+		modified = state.LastModified.UTC().UnixNano()
+	}
+	raw := fmt.Sprintf("%s|%s|%d|%s|%d|%d|%d|%s",
+		releaseFeedFormatVersion, scope.Kind, scope.AnimeID, scope.FansubSlug,
+		state.EntryCount, state.MaxVersionID, modified, state.Title)
+	sum := sha256.Sum256([]byte(raw))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// releaseFeedNotModified wertet die bedingten Header nach RFC 9110 aus: If-None-Match hat
+// Vorrang, If-Modified-Since greift nur ohne ETag-Bedingung.
+func releaseFeedNotModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if inm := strings.TrimSpace(req.Header.Get("If-None-Match")); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Authors    []atomPerson   `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+}
+
+func (h *ReleaseFeedHandler) renderFeed(
+	scope models.ReleaseFeedScope,
+	state *models.ReleaseFeedState,
+	lastModified time.Time,
+	entries []models.ReleaseFeedEntry,
+) ([]byte, error) {
+	selfURL := h.releaseFeedSelfURL(scope)
+	feed := atomFeed{
+		ID:      selfURL,
+		Title:   "Team4s – Neue Releases",
+		Updated: formatAtomTime(lastModified),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: selfURL},
+			{Rel: "alternate", Type: "text/html", Href: h.publicBaseURL + "/"},
+		},
+		Author:  atomPerson{Name: "Team4s"},
+		Entries: make([]atomEntry, 0, len(entries)),
+	}
+	switch scope.Kind {
+	case models.ReleaseFeedScopeAnime:
+		feed.Title = "Team4s – " + state.Title
+		feed.Links[1].Href = fmt.Sprintf("%s/anime/%d", h.publicBaseURL, scope.AnimeID)
+	case models.ReleaseFeedScopeFansub:
+		feed.Title = "Team4s – Releases von " + state.Title
+		feed.Links[1].Href = h.publicBaseURL + "/fansubs/" + scope.FansubSlug
+	}
+	if lastModified.IsZero() {
+		feed.Updated = formatAtomTime(time.Now())
+	}
+
+	for _, entry := range entries {
+		feed.Entries = append(feed.Entries, h.buildAtomEntry(entry))
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func (h *ReleaseFeedHandler) buildAtomEntry(entry models.ReleaseFeedEntry) atomEntry {
+	published := entry.CreatedAt
+	if entry.ReleaseDate != nil {
+		published = *entry.ReleaseDate
+	}
+
+	title := fmt.Sprintf("%s – Episode %s (%s)", entry.AnimeTitle, entry.EpisodeNumber, entry.Version)
+	if len(entry.FansubGroups) > 0 {
+		title += " [" + strings.Join(entry.FansubGroups, ", ") + "]"
+	}
+
+	summary := make([]string, 0, 4)
+	if entry.Title != nil {
+		summary = append(summary, *entry.Title)
+	}
+	if len(entry.FansubGroups) > 0 {
+		summary = append(summary, "Gruppe: "+strings.Join(entry.FansubGroups, ", "))
+	}
+	if len(entry.Qualities) > 0 {
+		summary = append(summary, "Qualität: "+strings.Join(entry.Qualities, ", "))
+	}
+	summary = append(summary, "Veröffentlicht: "+published.UTC().Format("02.01.2006"))
+
+	authors := make([]atomPerson, 0, len(entry.FansubGroups))
+	for _, group := range entry.FansubGroups {
+		authors = append(authors, atomPerson{Name: group})
+	}
+	categories := make([]atomCategory, 0, len(entry.Qualities))
+	for _, quality := range entry.Qualities {
+		categories = append(categories, atomCategory{Term: quality})
+	}
+
+	return atomEntry{
+		ID:        fmt.Sprintf("urn:team4s:release-version:%d", entry.ReleaseVersionID),
+		Title:     title,
+		Updated:   formatAtomTime(entry.UpdatedAt),
+		Published: formatAtomTime(published),
+		Links: []atomLink{
+			{Rel: "alternate", Type: "text/html", Href: fmt.Sprintf("%s/episodes/%d", h.publicBaseURL, entry.EpisodeID)},
+		},
+		Authors:    authors,
+		Categories: categories,
+		Summary:    atomText{Type: "text", Body: strings.Join(summary, " · ")},
+	}
+}
+
+func formatAtomTime(value time.Time) string {
+	return value.UTC().Format(time.RFC3339)
+}
+
+// releaseFeedSelfURL bildet <id> und self-Link des Feeds aus publicBaseURL und dem Routenpfad,
+// damit Host- oder X-Forwarded-Proto-Header der Anfrage keinen Einfluss haben.
+func (h *ReleaseFeedHandler) releaseFeedSelfURL(scope models.ReleaseFeedScope) string {
+	switch scope.Kind {
+	case models.ReleaseFeedScopeAnime:
+		return fmt.Sprintf("%s/feeds/anime/%d%s", h.publicBaseURL, scope.AnimeID, releaseFeedSuffix)
+	case models.ReleaseFeedScopeFansub:
+		return h.publicBaseURL + "/feeds/fansubs/" + url.PathEscape(scope.FansubSlug) + releaseFeedSuffix
+	default:
+		return h.publicBaseURL + "/feeds/releases" + releaseFeedSuffix
+	}
+}
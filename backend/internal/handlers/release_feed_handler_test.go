@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type releaseFeedRepoStub struct {
+	state       *models.ReleaseFeedState
+	stateErr    error
+	entries     []models.ReleaseFeedEntry
+	scope       models.ReleaseFeedScope
+	entriesRead int
+}
+
+func (s *releaseFeedRepoStub) GetState(_ context.Context, scope models.ReleaseFeedScope) (*models.ReleaseFeedState, error) {
+	s.scope = scope
+	return s.state, s.stateErr
+}
+
+func (s *releaseFeedRepoStub) ListEntries(_ context.Context, _ models.ReleaseFeedScope, _ int) ([]models.ReleaseFeedEntry, error) {
+	s.entriesRead++
+	return s.entries, nil
+}
+
+func newReleaseFeedTestContext(target string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	c.Params = params
+	return c, rec
+}
+
+func testReleaseFeedRepo() *releaseFeedRepoStub {
+	modified := time.Date(2026, 6, 1, 12, 30, 15, 500, time.UTC)
+	releaseDate := time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)
+	return &releaseFeedRepoStub{
+		state: &models.ReleaseFeedState{Title: "Frieren", EntryCount: 1, MaxVersionID: 7, LastModified: &modified},
+		entries: []models.ReleaseFeedEntry{{
+			ReleaseVersionID: 7,
+			Version:          "v2",
+			AnimeID:          12,
+			AnimeTitle:       "Frieren",
+			EpisodeID:        42,
+			EpisodeNumber:    "3",
+			ReleaseDate:      &releaseDate,
+			CreatedAt:        modified,
+			UpdatedAt:        modified,
+			FansubGroups:     []string{"Team4s"},
+			Qualities:        []string{"1080p"},
+		}},
+	}
+}
+
+func TestReleaseFeedAnimeRendersAtom(t *testing.T) {
+	repo := testReleaseFeedRepo()
+	c, rec := newReleaseFeedTestContext("/feeds/anime/12.atom", gin.Params{{Key: "id", Value: "12.atom"}})
+	c.Request.Host = "attacker.example"
+	c.Request.Header.Set("X-Forwarded-Proto", "http")
+
+	NewReleaseFeedHandler(repo, "https://team4s.example/").Anime(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d with body %s", rec.Code, rec.Body.String())
+	}
+	if repo.scope.Kind != models.ReleaseFeedScopeAnime || repo.scope.AnimeID != 12 {
+		t.Fatalf("unexpected scope %+v", repo.scope)
+	}
+	if got := rec.Header().Get("Content-Type"); got != releaseFeedContentType {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	if got := rec.Header().Get("Last-Modified"); got != "Mon, 01 Jun 2026 12:30:15 GMT" {
+		t.Fatalf("unexpected last-modified %q", got)
+	}
+	if !strings.HasPrefix(rec.Header().Get("ETag"), `W/"`) {
+		t.Fatalf("expected weak etag, got %q", rec.Header().Get("ETag"))
+	}
+
+	var feed struct {
+		ID    string `xml:"id"`
+		Title string `xml:"title"`
+		Links []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Title     string `xml:"title"`
+			Published string `xml:"published"`
+			Link      struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+			Category struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil || len(feed.Entries) != 1 {
+		t.Fatalf("expected one atom entry, got %s (%v)", rec.Body.String(), err)
+	}
+	entry := feed.Entries[0]
+	if feed.Title != "Team4s – Frieren" || entry.ID != "urn:team4s:release-version:7" ||
+		entry.Title != "Frieren – Episode 3 (v2) [Team4s]" || entry.Published != "2026-05-31T00:00:00Z" ||
+		entry.Link.Href != "https://team4s.example/episodes/42" || entry.Category.Term != "1080p" {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+	if feed.ID != "https://team4s.example/feeds/anime/12.atom" || len(feed.Links) == 0 ||
+		feed.Links[0].Rel != "self" || feed.Links[0].Href != feed.ID {
+		t.Fatalf("expected id and self link from the public base url, got %+v", feed)
+	}
+}
+
+func TestReleaseFeedConditionalRequestsSkipEntries(t *testing.T) {
+	repo := testReleaseFeedRepo()
+	c, rec := newReleaseFeedTestContext("/feeds/releases.atom", nil)
+	NewReleaseFeedHandler(repo, "https://team4s.example").Releases(c)
+	etag := rec.Header().Get("ETag")
+
+	c, rec = newReleaseFeedTestContext("/feeds/releases.atom", nil)
+	c.Request.Header.Set("If-None-Match", `"other", `+strings.TrimPrefix(etag, "W/"))
+	NewReleaseFeedHandler(repo, "https://team4s.example").Releases(c)
+	if c.Writer.Status() != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304 for matching etag, got %d", c.Writer.Status())
+	}
+
+	c, rec = newReleaseFeedTestContext("/feeds/releases.atom", nil)
+	c.Request.Header.Set("If-Modified-Since", "Mon, 01 Jun 2026 12:30:15 GMT")
+	NewReleaseFeedHandler(repo, "https://team4s.example").Releases(c)
+	if c.Writer.Status() != http.StatusNotModified {
+		t.Fatalf("expected 304 for unchanged last-modified, got %d", c.Writer.Status())
+	}
+	if repo.entriesRead != 1 {
+		t.Fatalf("expected entries to be loaded only for the first request, got %d loads", repo.entriesRead)
+	}
+
+	c, rec = newReleaseFeedTestContext("/feeds/releases.atom", nil)
+	c.Request.Header.Set("If-None-Match", `W/"stale"`)
+	c.Request.Header.Set("If-Modified-Since", "Mon, 01 Jun 2026 12:30:15 GMT")
+	NewReleaseFeedHandler(repo, "https://team4s.example").Releases(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected stale etag to take precedence over if-modified-since, got %d", rec.Code)
+	}
+}
+
+func TestReleaseFeedFansubNotFound(t *testing.T) {
+	c, rec := newReleaseFeedTestContext("/feeds/fansubs/team4s", gin.Params{{Key: "slug", Value: "team4s"}})
+	NewReleaseFeedHandler(&releaseFeedRepoStub{}, "").Fansub(c)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without .atom suffix, got %d", rec.Code)
+	}
+
+	repo := &releaseFeedRepoStub{stateErr: repository.ErrNotFound}
+	c, rec = newReleaseFeedTestContext("/feeds/fansubs/unknown.atom", gin.Params{{Key: "slug", Value: "unknown.atom"}})
+	NewReleaseFeedHandler(repo, "").Fansub(c)
+	if rec.Code != http.StatusNotFound || repo.scope.FansubSlug != "unknown" {
+		t.Fatalf("expected 404 for unknown group, got %d with scope %+v", rec.Code, repo.scope)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"team4s.v3/backend/internal/blobstore"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// releaseTrickplayStore liefert fertige Trickplay-Spuren (implementiert von
+// repository.TrickplayRepository).
+type releaseTrickplayStore interface {
+	GetReadyTrickplay(ctx context.Context, releaseVersionID int64) (*models.ReleaseVariantTrickplay, error)
+}
+
+// ReleaseTrickplayHandler liefert die WebVTT-Vorschauspur einer Release-Variante aus.
+type ReleaseTrickplayHandler struct {
+	repo            releaseTrickplayStore
+	mediaStorageDir string
+	blobs           blobstore.BlobStore
+}
+
+// NewReleaseTrickplayHandler erstellt einen Handler, der WebVTT-Dateien unter mediaStorageDir ausliefert.
+func NewReleaseTrickplayHandler(repo releaseTrickplayStore, mediaStorageDir string) *ReleaseTrickplayHandler {
+	dir := strings.TrimSpace(mediaStorageDir)
+	return &ReleaseTrickplayHandler{repo: repo, mediaStorageDir: dir, blobs: blobstore.NewLocalStore(dir)}
+}
+
+// WithBlobStore liest die WebVTT-Dateien aus store statt direkt aus mediaStorageDir.
+func (h *ReleaseTrickplayHandler) WithBlobStore(store blobstore.BlobStore) *ReleaseTrickplayHandler {
+	if store != nil {
+		h.blobs = store
+	}
+	return h
+}
+
+// GetReleaseTrickplayVTT verarbeitet GET /api/v1/releases/:id/trickplay.vtt; :id ist wie bei
+// /releases/:id/stream die Release-Version. Die Spur verweist per #xywh auf die öffentlich
+// ausgelieferten Sprites; ohne fertige Sprites antwortet der Endpunkt mit 404.
+func (h *ReleaseTrickplayHandler) GetReleaseTrickplayVTT(c *gin.Context) {
+	releaseID, err := parseEpisodeVersionID(c.Param("id"))
+	if err != nil {
+		badRequest(c, "ungültige release id")
+		return
+	}
+
+	trickplay, err := h.repo.GetReadyTrickplay(c.Request.Context(), releaseID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && trickplay.VTTFilePath == nil) {
+		notFound(c, "vorschaubilder nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("release trickplay: repo error (release_id=%d): %v", releaseID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	// file_path (/media/trickplay/variant_{id}/{generation}/thumbnails.vtt) -> Schlüssel im Medienstamm.
+	key, ok := blobstore.KeyFromPath(h.mediaStorageDir, *trickplay.VTTFilePath)
+	if !ok {
+		notFound(c, "vorschaubilder nicht gefunden")
+		return
+	}
+	err = proxyStoredMedia(c, h.blobs, key, "text/vtt; charset=utf-8", "public, max-age=3600")
+	if errors.Is(err, blobstore.ErrNotExist) {
+		log.Printf("release trickplay: vtt file missing (release_id=%d, key=%q)", releaseID, key)
+		notFound(c, "vorschaubilder nicht gefunden")
+		return
+	}
+	if err != nil {
+		log.Printf("release trickplay: read vtt failed (release_id=%d, key=%q): %v", releaseID, key, err)
+		internalError(c, "interner serverfehler")
+	}
+}
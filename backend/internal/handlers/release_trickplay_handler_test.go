@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeReleaseTrickplayStore struct {
+	items map[int64]*models.ReleaseVariantTrickplay
+}
+
+func (s fakeReleaseTrickplayStore) GetReadyTrickplay(_ context.Context, releaseID int64) (*models.ReleaseVariantTrickplay, error) {
+	if item, ok := s.items[releaseID]; ok {
+		return item, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestGetReleaseTrickplayVTT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storageDir := t.TempDir()
+	vttDir := filepath.Join(storageDir, "trickplay", "variant_7", "1")
+	if err := os.MkdirAll(vttDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:10.000\nhttp://localhost/media/trickplay/variant_7/1/sprite_0001.jpg#xywh=0,0,320,180\n"
+	if err := os.WriteFile(filepath.Join(vttDir, "thumbnails.vtt"), []byte(vtt), 0o644); err != nil {
+		t.Fatalf("write vtt: %v", err)
+	}
+
+	stored := "/media/trickplay/variant_7/1/thumbnails.vtt"
+	escaping := "/media/../../etc/passwd"
+	handler := NewReleaseTrickplayHandler(fakeReleaseTrickplayStore{items: map[int64]*models.ReleaseVariantTrickplay{
+		7: {ReleaseVariantID: 7, Status: models.TrickplayStatusReady, VTTFilePath: &stored},
+		8: {ReleaseVariantID: 8, Status: models.TrickplayStatusReady, VTTFilePath: &escaping},
+	}}, storageDir)
+
+	router := gin.New()
+	router.GET("/api/v1/releases/:id/trickplay.vtt", handler.GetReleaseTrickplayVTT)
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := serve("/api/v1/releases/7/trickplay.vtt")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/vtt") {
+		t.Fatalf("expected text/vtt, got %q", got)
+	}
+	if rec.Body.String() != vtt {
+		t.Fatalf("unexpected body: %q", rec.Body.String())
+	}
+
+	for target, want := range map[string]int{
+		"/api/v1/releases/9/trickplay.vtt":   http.StatusNotFound,
+		"/api/v1/releases/8/trickplay.vtt":   http.StatusNotFound,
+		"/api/v1/releases/abc/trickplay.vtt": http.StatusBadRequest,
+	} {
+		if rec := serve(target); rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+}
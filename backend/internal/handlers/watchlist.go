@@ -4,6 +4,9 @@ import (
 	"errors"
 	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"team4s.v3/backend/internal/middleware"
 	"team4s.v3/backend/internal/models"
@@ -13,17 +16,32 @@ import (
 )
 
 type createWatchlistRequest struct {
-	AnimeID int64 `json:"anime_id"`
+	AnimeID         int64   `json:"anime_id"`
+	ListStatus      *string `json:"list_status"`
+	Score           *int16  `json:"score"`
+	EpisodesWatched *int32  `json:"episodes_watched"`
+	StartedOn       *string `json:"started_on"`
+	FinishedOn      *string `json:"finished_on"`
+	Note            *string `json:"note"`
 }
 
+const maxWatchlistNoteLength = 2000
+
 type WatchlistHandler struct {
-	repo *repository.WatchlistRepository
+	repo         *repository.WatchlistRepository
+	progressRepo *repository.WatchProgressRepository
 }
 
 func NewWatchlistHandler(repo *repository.WatchlistRepository) *WatchlistHandler {
 	return &WatchlistHandler{repo: repo}
 }
 
+// WithWatchProgressRepo aktiviert /me/continue-watching auf Basis des Wiedergabefortschritts.
+func (h *WatchlistHandler) WithWatchProgressRepo(repo *repository.WatchProgressRepository) *WatchlistHandler {
+	h.progressRepo = repo
+	return h
+}
+
 func (h *WatchlistHandler) ListByUser(c *gin.Context) {
 	identity, ok := middleware.CommentAuthIdentityFromContext(c)
 	if !ok {
@@ -51,11 +69,16 @@ func (h *WatchlistHandler) ListByUser(c *gin.Context) {
 		perPage = 100
 	}
 
-	items, total, err := h.repo.ListByUser(c.Request.Context(), models.WatchlistFilter{
-		Page:    page,
-		PerPage: perPage,
-		UserID:  userID,
-	})
+	filter, validationMessage := parseWatchlistListFilter(c)
+	if validationMessage != "" {
+		badRequest(c, validationMessage)
+		return
+	}
+	filter.Page = page
+	filter.PerPage = perPage
+	filter.UserID = userID
+
+	items, total, err := h.repo.ListByUser(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
@@ -105,7 +128,14 @@ func (h *WatchlistHandler) CreateByUser(c *gin.Context) {
 		return
 	}
 
-	item, err := h.repo.CreateByUser(c.Request.Context(), userID, animeID)
+	item, err := h.repo.CreateByUser(c.Request.Context(), userID, animeID, models.WatchlistEntryInput{
+		ListStatus:      req.ListStatus,
+		Score:           req.Score,
+		EpisodesWatched: req.EpisodesWatched,
+		StartedOn:       req.StartedOn,
+		FinishedOn:      req.FinishedOn,
+		Note:            normalizeWatchlistNote(req.Note),
+	})
 	if errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": gin.H{
@@ -114,6 +144,11 @@ func (h *WatchlistHandler) CreateByUser(c *gin.Context) {
 		})
 		return
 	}
+	var patchErr *repository.WatchlistPatchError
+	if errors.As(err, &patchErr) {
+		badRequest(c, patchErr.Message)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
@@ -206,10 +241,251 @@ func (h *WatchlistHandler) GetByUserAndAnimeID(c *gin.Context) {
 	})
 }
 
+// UpdateByUser verarbeitet PATCH /api/v1/watchlist/:anime_id und aktualisiert Listenstatus,
+// Wertung, Episodenzähler, Datumsangaben und Notiz eines bestehenden Eintrags.
+func (h *WatchlistHandler) UpdateByUser(c *gin.Context) {
+	identity, ok := middleware.CommentAuthIdentityFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"message": "anmeldung erforderlich",
+			},
+		})
+		return
+	}
+	userID := identity.UserID
+
+	animeID, err := parseAnimeID(c.Param("anime_id"))
+	if err != nil {
+		badRequest(c, "ungültige anime id")
+		return
+	}
+
+	var patch models.WatchlistEntryPatchInput
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+
+	if validationMessage := validateWatchlistPatch(patch); validationMessage != "" {
+		badRequest(c, validationMessage)
+		return
+	}
+	if patch.Note.Set {
+		patch.Note.Value = normalizeWatchlistNote(patch.Note.Value)
+	}
+
+	item, err := h.repo.UpdateByUser(c.Request.Context(), userID, animeID, patch)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"message": "watchlist-eintrag nicht gefunden",
+			},
+		})
+		return
+	}
+	var patchErr *repository.WatchlistPatchError
+	if errors.As(err, &patchErr) {
+		badRequest(c, patchErr.Message)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"message": "interner serverfehler",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": item,
+	})
+}
+
+// ListContinueWatching verarbeitet GET /api/v1/me/continue-watching und liefert pro Watchlist-Anime
+// die nächste ungesehene Episode inklusive Resume-Position.
+func (h *WatchlistHandler) ListContinueWatching(c *gin.Context) {
+	identity, ok := requireAppUserIdentity(c)
+	if !ok {
+		return
+	}
+	if h.progressRepo == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"message": "wiedergabefortschritt derzeit nicht verfügbar",
+			},
+		})
+		return
+	}
+
+	limit, err := parsePositiveInt(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		badRequest(c, "ungültiger limit parameter")
+		return
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	items, err := h.progressRepo.ListContinueWatching(c.Request.Context(), models.ContinueWatchingFilter{
+		AppUserID:       identity.AppUserID,
+		WatchlistUserID: identity.UserID,
+		Limit:           limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"message": "interner serverfehler",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+	})
+}
+
 func validateCreateWatchlistRequest(req createWatchlistRequest) (int64, string) {
 	if req.AnimeID <= 0 {
 		return 0, "anime_id ist erforderlich"
 	}
 
+	patch := models.WatchlistEntryPatchInput{
+		ListStatus:      models.OptionalString{Set: req.ListStatus != nil, Value: req.ListStatus},
+		Score:           models.OptionalInt16{Set: req.Score != nil, Value: req.Score},
+		EpisodesWatched: models.OptionalInt32{Set: req.EpisodesWatched != nil, Value: req.EpisodesWatched},
+		StartedOn:       models.OptionalString{Set: req.StartedOn != nil, Value: req.StartedOn},
+		FinishedOn:      models.OptionalString{Set: req.FinishedOn != nil, Value: req.FinishedOn},
+		Note:            models.OptionalString{Set: req.Note != nil, Value: req.Note},
+	}
+	if message := validateWatchlistPatch(patch); message != "" {
+		return 0, message
+	}
+
 	return req.AnimeID, ""
 }
+
+// validateWatchlistPatch prüft Format und Wertebereich der einzelnen Listenfelder.
+// Feldübergreifende Regeln (Datumsreihenfolge, Episodenmaximum) prüft das Repository.
+func validateWatchlistPatch(patch models.WatchlistEntryPatchInput) string {
+	if patch.ListStatus.Set && (patch.ListStatus.Value == nil || !models.IsValidWatchlistStatus(*patch.ListStatus.Value)) {
+		return "ungültiger listenstatus"
+	}
+	if patch.Score.Set && patch.Score.Value != nil && (*patch.Score.Value < 1 || *patch.Score.Value > 10) {
+		return "score muss zwischen 1 und 10 liegen"
+	}
+	if patch.EpisodesWatched.Set && patch.EpisodesWatched.Value != nil && *patch.EpisodesWatched.Value < 0 {
+		return "episodes_watched darf nicht negativ sein"
+	}
+	if patch.StartedOn.Set {
+		if _, err := parseOptionalDate(patch.StartedOn.Value); err != nil {
+			return "ungültiges started_on: " + err.Error()
+		}
+	}
+	if patch.FinishedOn.Set {
+		if _, err := parseOptionalDate(patch.FinishedOn.Value); err != nil {
+			return "ungültiges finished_on: " + err.Error()
+		}
+	}
+	if patch.Note.Set && patch.Note.Value != nil && utf8.RuneCountInString(*patch.Note.Value) > maxWatchlistNoteLength {
+		return "notiz ist zu lang"
+	}
+
+	return ""
+}
+
+// parseWatchlistListFilter liest die optionalen Filter- und Sortierparameter von GET /watchlist.
+func parseWatchlistListFilter(c *gin.Context) (models.WatchlistFilter, string) {
+	var filter models.WatchlistFilter
+
+	if raw := strings.TrimSpace(c.Query("list_status")); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			status := strings.TrimSpace(part)
+			if !models.IsValidWatchlistStatus(status) {
+				return filter, "ungültiger list_status parameter"
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	for _, param := range []struct {
+		key    string
+		target **int16
+	}{
+		{key: "score_min", target: &filter.ScoreMin},
+		{key: "score_max", target: &filter.ScoreMax},
+	} {
+		raw := strings.TrimSpace(c.Query(param.key))
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 16)
+		if err != nil || value < 1 || value > 10 {
+			return filter, "ungültiger " + param.key + " parameter"
+		}
+		score := int16(value)
+		*param.target = &score
+	}
+
+	if raw := strings.TrimSpace(c.Query("episodes_watched_min")); raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || value < 0 {
+			return filter, "ungültiger episodes_watched_min parameter"
+		}
+		episodes := int32(value)
+		filter.EpisodesWatchedMin = &episodes
+	}
+
+	for _, param := range []struct {
+		key    string
+		target **string
+	}{
+		{key: "started_from", target: &filter.StartedFrom},
+		{key: "started_to", target: &filter.StartedTo},
+		{key: "finished_from", target: &filter.FinishedFrom},
+		{key: "finished_to", target: &filter.FinishedTo},
+	} {
+		raw := strings.TrimSpace(c.Query(param.key))
+		if raw == "" {
+			continue
+		}
+		if _, err := parseOptionalDate(&raw); err != nil {
+			return filter, "ungültiger " + param.key + " parameter"
+		}
+		value := raw
+		*param.target = &value
+	}
+
+	if raw := strings.TrimSpace(c.Query("has_note")); raw != "" {
+		hasNote, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, "ungültiger has_note parameter"
+		}
+		filter.HasNote = &hasNote
+	}
+
+	filter.SortBy = strings.TrimSpace(c.DefaultQuery("sort", "added_at"))
+	if !repository.IsValidWatchlistSort(filter.SortBy) {
+		return filter, "ungültiger sort parameter"
+	}
+	filter.SortOrder = strings.ToLower(strings.TrimSpace(c.DefaultQuery("order", "desc")))
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		return filter, "ungültiger order parameter"
+	}
+
+	return filter, ""
+}
+
+// normalizeWatchlistNote trimmt die Notiz; eine leere Notiz wird als "keine Notiz" gespeichert.
+func normalizeWatchlistNote(note *string) *string {
+	if note == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*note)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/middleware"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxWatchlistImportFileBytes begrenzt Uploads; große MAL-Exporte liegen bei wenigen MB.
+	maxWatchlistImportFileBytes = 8 << 20
+	maxWatchlistImportEntries   = 5000
+)
+
+type applyWatchlistImportRequest struct {
+	Overwrite bool                     `json:"overwrite"`
+	Entries   []createWatchlistRequest `json:"entries"`
+}
+
+// PreviewImport verarbeitet POST /api/v1/watchlist/import/preview. Erwartet eine MAL-XML- oder
+// AniList-JSON-Datei (multipart "file" oder roher Body) und liefert die Zuordnung je Eintrag.
+// Es wird nichts gespeichert; nicht zugeordnete Einträge kann der Client manuell auflösen.
+func (h *WatchlistHandler) PreviewImport(c *gin.Context) {
+	identity, ok := middleware.CommentAuthIdentityFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "anmeldung erforderlich"}})
+		return
+	}
+
+	data, message := readWatchlistImportUpload(c)
+	if message != "" {
+		badRequest(c, message)
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	if format == "" {
+		format = services.DetectWatchlistListFormat(data)
+	}
+	if format != models.WatchlistListFormatMAL && format != models.WatchlistListFormatAniList {
+		badRequest(c, "ungültiges format (erlaubt: mal, anilist)")
+		return
+	}
+
+	entries, skipped, err := services.ParseWatchlistListFile(format, data)
+	if err != nil {
+		badRequest(c, "datei konnte nicht gelesen werden")
+		return
+	}
+	if len(entries) > maxWatchlistImportEntries {
+		badRequest(c, fmt.Sprintf("zu viele einträge (maximal %d)", maxWatchlistImportEntries))
+		return
+	}
+
+	items, err := h.repo.PreviewImport(c.Request.Context(), identity.UserID, entries)
+	if err != nil {
+		log.Printf("watchlist: import preview failed (user_id=%d): %v", identity.UserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.WatchlistImportPreview{
+		Format:  format,
+		Items:   items,
+		Summary: summarizeWatchlistImportPreview(items, skipped),
+	}})
+}
+
+// ApplyImport verarbeitet POST /api/v1/watchlist/import mit den bestätigten Einträgen aus der
+// Vorschau (anime_id bereits aufgelöst). Bestehende Einträge bleiben ohne overwrite unverändert.
+func (h *WatchlistHandler) ApplyImport(c *gin.Context) {
+	identity, ok := middleware.CommentAuthIdentityFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "anmeldung erforderlich"}})
+		return
+	}
+
+	var req applyWatchlistImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "ungültiger request body")
+		return
+	}
+
+	entries, message := validateApplyWatchlistImportRequest(req)
+	if message != "" {
+		badRequest(c, message)
+		return
+	}
+
+	result, err := h.repo.ApplyImport(c.Request.Context(), identity.UserID, entries, req.Overwrite)
+	if err != nil {
+		log.Printf("watchlist: import apply failed (user_id=%d): %v", identity.UserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Export verarbeitet GET /api/v1/watchlist/export?format=mal|anilist und liefert die komplette
+// Watchlist als Download im jeweiligen Austauschformat.
+func (h *WatchlistHandler) Export(c *gin.Context) {
+	identity, ok := middleware.CommentAuthIdentityFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"message": "anmeldung erforderlich"}})
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", models.WatchlistListFormatMAL)))
+	if format != models.WatchlistListFormatMAL && format != models.WatchlistListFormatAniList {
+		badRequest(c, "ungültiges format (erlaubt: mal, anilist)")
+		return
+	}
+
+	items, err := h.repo.ListForExport(c.Request.Context(), identity.UserID)
+	if err != nil {
+		log.Printf("watchlist: export failed (user_id=%d): %v", identity.UserID, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	var body []byte
+	contentType := "application/xml; charset=utf-8"
+	extension := "xml"
+	if format == models.WatchlistListFormatAniList {
+		body, err = services.EncodeAniListListJSON(items)
+		contentType = "application/json; charset=utf-8"
+		extension = "json"
+	} else {
+		body, err = services.EncodeMALListXML(items)
+	}
+	if err != nil {
+		log.Printf("watchlist: export encode failed (user_id=%d, format=%s): %v", identity.UserID, format, err)
+		internalError(c, "interner serverfehler")
+		return
+	}
+
+	fileName := fmt.Sprintf("watchlist-%s-%s.%s", format, time.Now().UTC().Format("20060102"), extension)
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// readWatchlistImportUpload liest die Importdatei aus dem multipart-Feld "file" oder, falls
+// kein Formular gesendet wurde, aus dem Request-Body.
+func readWatchlistImportUpload(c *gin.Context) ([]byte, string) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWatchlistImportFileBytes)
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "datei fehlt (field: file)"
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "datei konnte nicht gelesen werden"
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxWatchlistImportFileBytes+1))
+	if err != nil {
+		return nil, "datei konnte nicht gelesen werden"
+	}
+	if len(data) > maxWatchlistImportFileBytes {
+		return nil, "datei ist zu groß"
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, "datei ist leer"
+	}
+
+	return data, ""
+}
+
+// validateApplyWatchlistImportRequest prüft jeden Eintrag mit denselben Regeln wie
+// POST /watchlist und meldet den ersten Fehler mit Position.
+func validateApplyWatchlistImportRequest(req applyWatchlistImportRequest) ([]models.WatchlistImportApplyEntry, string) {
+	if len(req.Entries) == 0 {
+		return nil, "entries ist erforderlich"
+	}
+	if len(req.Entries) > maxWatchlistImportEntries {
+		return nil, fmt.Sprintf("zu viele einträge (maximal %d)", maxWatchlistImportEntries)
+	}
+
+	entries := make([]models.WatchlistImportApplyEntry, 0, len(req.Entries))
+	for index, raw := range req.Entries {
+		animeID, message := validateCreateWatchlistRequest(raw)
+		if message != "" {
+			return nil, "eintrag " + strconv.Itoa(index) + ": " + message
+		}
+
+		entry := models.WatchlistImportApplyEntry{
+			AnimeID:    animeID,
+			ListStatus: models.WatchlistStatusPlanned,
+			Score:      raw.Score,
+			StartedOn:  normalizeWatchlistImportDate(raw.StartedOn),
+			FinishedOn: normalizeWatchlistImportDate(raw.FinishedOn),
+			Note:       normalizeWatchlistNote(raw.Note),
+		}
+		if raw.ListStatus != nil {
+			entry.ListStatus = *raw.ListStatus
+		}
+		if raw.EpisodesWatched != nil {
+			entry.EpisodesWatched = *raw.EpisodesWatched
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, ""
+}
+
+// normalizeWatchlistImportDate behandelt leere Datumsstrings aus Client-Formularen als "kein Datum".
+func normalizeWatchlistImportDate(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
+
+func summarizeWatchlistImportPreview(items []models.WatchlistImportPreviewItem, skipped int) models.WatchlistImportPreviewSummary {
+	summary := models.WatchlistImportPreviewSummary{Total: len(items), Skipped: skipped}
+	for _, item := range items {
+		switch item.Match {
+		case models.WatchlistImportMatchSource, models.WatchlistImportMatchTitle:
+			summary.Matched++
+		case models.WatchlistImportMatchAmbiguous:
+			summary.Ambiguous++
+		default:
+			summary.Unmatched++
+		}
+		if item.OnWatchlist {
+			summary.OnWatchlist++
+		}
+	}
+	return summary
+}
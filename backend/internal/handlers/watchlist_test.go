@@ -1,6 +1,10 @@
 package handlers
 
-import "testing"
+import (
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
 
 func TestValidateCreateWatchlistRequest(t *testing.T) {
 	tests := []struct {
@@ -45,3 +49,56 @@ func TestValidateCreateWatchlistRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateWatchlistPatch(t *testing.T) {
+	status := func(value string) models.OptionalString {
+		return models.OptionalString{Set: true, Value: &value}
+	}
+	score := func(value int16) models.OptionalInt16 {
+		return models.OptionalInt16{Set: true, Value: &value}
+	}
+
+	tests := []struct {
+		name        string
+		patch       models.WatchlistEntryPatchInput
+		wantMessage string
+	}{
+		{
+			name:  "valid lifecycle update",
+			patch: models.WatchlistEntryPatchInput{ListStatus: status("on_hold"), Score: score(8), StartedOn: status("2026-01-05")},
+		},
+		{
+			name:  "clearing score is allowed",
+			patch: models.WatchlistEntryPatchInput{Score: models.OptionalInt16{Set: true}},
+		},
+		{
+			name:        "unknown status",
+			patch:       models.WatchlistEntryPatchInput{ListStatus: status("paused")},
+			wantMessage: "ungültiger listenstatus",
+		},
+		{
+			name:        "null status",
+			patch:       models.WatchlistEntryPatchInput{ListStatus: models.OptionalString{Set: true}},
+			wantMessage: "ungültiger listenstatus",
+		},
+		{
+			name:        "score out of range",
+			patch:       models.WatchlistEntryPatchInput{Score: score(11)},
+			wantMessage: "score muss zwischen 1 und 10 liegen",
+		},
+		{
+			name:        "malformed date",
+			patch:       models.WatchlistEntryPatchInput{FinishedOn: status("05.01.2026")},
+			wantMessage: "ungültiges finished_on: Format JJJJ-MM-TT erwartet",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if message := validateWatchlistPatch(tc.patch); message != tc.wantMessage {
+				t.Fatalf("expected message %q, got %q", tc.wantMessage, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package mediaserver
+
+// Emby ist der Provider für Emby-Server. Jellyfin ist ein Fork von Emby; beide sprechen für
+// die hier genutzten Endpunkte (/Items, /Shows, /Videos) dieselbe API.
+type Emby struct {
+	restServer
+}
+
+// NewEmby erstellt einen Emby-Provider. Fehlt cfg.HTTPClient, wird ein Client mit
+// 15 Sekunden Timeout verwendet.
+func NewEmby(cfg Config) *Emby {
+	return &Emby{restServer: newRestServer(KindEmby, cfg)}
+}
+
+var _ MediaServerProvider = (*Emby)(nil)
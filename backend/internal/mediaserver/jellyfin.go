@@ -0,0 +1,56 @@
+package mediaserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Jellyfin ist der Provider für Jellyfin-Server.
+type Jellyfin struct {
+	restServer
+}
+
+type mediaSegmentsResponse struct {
+	Items []MediaSegment `json:"Items"`
+}
+
+// NewJellyfin erstellt einen Jellyfin-Provider. Fehlt cfg.HTTPClient, wird ein Client mit
+// 15 Sekunden Timeout verwendet.
+func NewJellyfin(cfg Config) *Jellyfin {
+	return &Jellyfin{restServer: newRestServer(KindJellyfin, cfg)}
+}
+
+// ListMediaSegments liest GET /MediaSegments/{itemId} (Jellyfin 10.10+). Ältere Server ohne den
+// Endpunkt antworten mit 404 und liefern damit ebenfalls nil.
+func (j *Jellyfin) ListMediaSegments(ctx context.Context, itemID string, types []string) ([]MediaSegment, error) {
+	trimmedItemID := strings.TrimSpace(itemID)
+	if trimmedItemID == "" {
+		return nil, nil
+	}
+
+	values := url.Values{}
+	for _, segmentType := range types {
+		if trimmed := strings.TrimSpace(segmentType); trimmed != "" {
+			values.Add("includeSegmentTypes", trimmed)
+		}
+	}
+
+	var payload mediaSegmentsResponse
+	statusCode, err := j.FetchJSON(ctx, fmt.Sprintf("/MediaSegments/%s", url.PathEscape(trimmedItemID)), values, &payload)
+	if statusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.Items, nil
+}
+
+var (
+	_ MediaServerProvider  = (*Jellyfin)(nil)
+	_ MediaSegmentProvider = (*Jellyfin)(nil)
+)
@@ -0,0 +1,234 @@
+// Package mediaservertest stellt einen speicherbasierten MediaServerProvider für Tests bereit
+// (analog zu net/http/httptest). Handler-Tests befüllen Serien, Episoden und Bilder direkt,
+// statt einen Jellyfin- oder Emby-Server per HTTP nachzubauen.
+package mediaservertest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"team4s.v3/backend/internal/mediaserver"
+)
+
+// Provider ist ein konfigurierbarer Fake. Alle Felder dürfen vor der Nutzung gesetzt werden;
+// Err wird, falls gesetzt, von jeder lesenden Methode zurückgegeben.
+type Provider struct {
+	KindName string
+	BaseURL  string
+	APIKey   string
+
+	Series      []mediaserver.Series
+	Episodes    map[string][]mediaserver.Episode
+	Runtimes    map[string]int32
+	ThemeVideos map[string][]string
+	// MediaSegments enthält die Segmente je Item-ID für ListMediaSegments.
+	MediaSegments map[string][]mediaserver.MediaSegment
+	// Images enthält vorhandene Bilder als ImageKey(itemID, imageType, index).
+	Images map[string]bool
+	Err    error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var (
+	_ mediaserver.MediaServerProvider  = (*Provider)(nil)
+	_ mediaserver.MediaSegmentProvider = (*Provider)(nil)
+)
+
+// NewProvider erstellt einen konfigurierten Jellyfin-Fake unter http://media.test.
+func NewProvider() *Provider {
+	return &Provider{
+		KindName:      mediaserver.KindJellyfin,
+		BaseURL:       "http://media.test",
+		APIKey:        "test-key",
+		Episodes:      map[string][]mediaserver.Episode{},
+		Runtimes:      map[string]int32{},
+		ThemeVideos:   map[string][]string{},
+		MediaSegments: map[string][]mediaserver.MediaSegment{},
+		Images:        map[string]bool{},
+	}
+}
+
+// ImageKey bildet den Schlüssel für Provider.Images.
+func ImageKey(itemID string, imageType string, index *int) string {
+	if index == nil {
+		return itemID + "/" + imageType
+	}
+	return fmt.Sprintf("%s/%s/%d", itemID, imageType, *index)
+}
+
+// Calls liefert die bisherigen Aufrufe in der Form "Methode:Argument".
+func (p *Provider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.calls...)
+}
+
+func (p *Provider) record(method string, arg string) {
+	p.mu.Lock()
+	p.calls = append(p.calls, method+":"+arg)
+	p.mu.Unlock()
+}
+
+func (p *Provider) Kind() string {
+	return p.KindName
+}
+
+func (p *Provider) Configured() bool {
+	return strings.TrimSpace(p.BaseURL) != "" && strings.TrimSpace(p.APIKey) != ""
+}
+
+// SearchSeries liefert alle Serien, deren Name term enthält (ohne Groß-/Kleinschreibung).
+func (p *Provider) SearchSeries(_ context.Context, term string, limit int) ([]mediaserver.Series, error) {
+	p.record("SearchSeries", term)
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(term))
+	result := make([]mediaserver.Series, 0)
+	for _, series := range p.Series {
+		if strings.Contains(strings.ToLower(series.Name), needle) {
+			result = append(result, series)
+		}
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// ListLibrarySeries liefert alle Serien des Fakes.
+func (p *Provider) ListLibrarySeries(context.Context) ([]mediaserver.Series, error) {
+	p.record("ListLibrarySeries", "")
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return append([]mediaserver.Series(nil), p.Series...), nil
+}
+
+func (p *Provider) GetSeries(_ context.Context, seriesID string) (*mediaserver.Series, error) {
+	p.record("GetSeries", seriesID)
+	return p.findSeries(seriesID)
+}
+
+func (p *Provider) GetSeriesDetail(_ context.Context, seriesID string) (*mediaserver.Series, error) {
+	p.record("GetSeriesDetail", seriesID)
+	return p.findSeries(seriesID)
+}
+
+func (p *Provider) findSeries(seriesID string) (*mediaserver.Series, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	for _, series := range p.Series {
+		if series.ID == strings.TrimSpace(seriesID) {
+			found := series
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *Provider) ListEpisodes(_ context.Context, seriesID string) ([]mediaserver.Episode, error) {
+	p.record("ListEpisodes", seriesID)
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return append([]mediaserver.Episode(nil), p.Episodes[seriesID]...), nil
+}
+
+func (p *Provider) GetRuntimeSeconds(_ context.Context, itemID string) (*int32, error) {
+	p.record("GetRuntimeSeconds", itemID)
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	seconds, ok := p.Runtimes[itemID]
+	if !ok {
+		return nil, nil
+	}
+	return &seconds, nil
+}
+
+func (p *Provider) ListThemeVideoIDs(_ context.Context, seriesID string) ([]string, error) {
+	p.record("ListThemeVideoIDs", seriesID)
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return append([]string{}, p.ThemeVideos[seriesID]...), nil
+}
+
+func (p *Provider) ListMediaSegments(_ context.Context, itemID string, types []string) ([]mediaserver.MediaSegment, error) {
+	p.record("ListMediaSegments", itemID)
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	segments, ok := p.MediaSegments[itemID]
+	if !ok {
+		return nil, nil
+	}
+	result := make([]mediaserver.MediaSegment, 0, len(segments))
+	for _, segment := range segments {
+		if len(types) == 0 || slices.Contains(types, segment.Type) {
+			result = append(result, segment)
+		}
+	}
+	return result, nil
+}
+
+func (p *Provider) ImageURL(itemID string, imageType string, index *int, opts mediaserver.ImageOptions) (string, error) {
+	query := url.Values{}
+	if opts.MaxWidth != nil {
+		query.Set("maxWidth", fmt.Sprint(*opts.MaxWidth))
+	}
+	if opts.Quality != nil {
+		query.Set("quality", fmt.Sprint(*opts.Quality))
+	}
+	if !strings.EqualFold(imageType, "Backdrop") {
+		index = nil
+	}
+	return p.url("/Items/"+ImageKey(itemID, imageType, index), query)
+}
+
+func (p *Provider) ImageExists(_ context.Context, itemID string, imageType string, index *int) (bool, error) {
+	p.record("ImageExists", ImageKey(itemID, imageType, index))
+	if p.Err != nil {
+		return false, p.Err
+	}
+	return p.Images[ImageKey(itemID, imageType, index)], nil
+}
+
+func (p *Provider) StreamURL(itemID string) (string, error) {
+	return p.url("/Videos/"+itemID+"/stream", url.Values{"static": {"true"}})
+}
+
+func (p *Provider) HLSURL(itemID string) (string, error) {
+	return p.url("/Videos/"+itemID+"/master.m3u8", url.Values{"MediaSourceId": {itemID}})
+}
+
+func (p *Provider) Authorize(target *url.URL) {
+	if target == nil {
+		return
+	}
+	query := target.Query()
+	query.Set("api_key", p.APIKey)
+	target.RawQuery = query.Encode()
+}
+
+func (p *Provider) url(itemPath string, query url.Values) (string, error) {
+	if !p.Configured() {
+		return "", fmt.Errorf("media base url is missing")
+	}
+	target, err := url.Parse(strings.TrimRight(p.BaseURL, "/") + itemPath)
+	if err != nil {
+		return "", err
+	}
+	target.RawQuery = query.Encode()
+	p.Authorize(target)
+	return target.String(), nil
+}
@@ -0,0 +1,168 @@
+// Package mediaserver kapselt den Zugriff auf externe Media-Server (Jellyfin, Emby) hinter
+// einer gemeinsamen Schnittstelle. Intake, Sync, Backdrops und Wiedergabe arbeiten nur gegen
+// MediaServerProvider; welcher Server dahinter steht, entscheidet die Konfiguration.
+package mediaserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// KindJellyfin bezeichnet einen Jellyfin-Server.
+	KindJellyfin = "jellyfin"
+	// KindEmby bezeichnet einen Emby-Server.
+	KindEmby = "emby"
+
+	defaultStreamPathTemplate = "/Videos/%s/stream"
+	defaultHLSPathTemplate    = "/Videos/%s/master.m3u8"
+)
+
+// Series ist eine Serie, wie sie Jellyfin und Emby über /Items liefern.
+type Series struct {
+	ID                string            `json:"Id"`
+	Name              string            `json:"Name"`
+	ProductionYear    *int              `json:"ProductionYear"`
+	Overview          string            `json:"Overview"`
+	Path              string            `json:"Path"`
+	Genres            []string          `json:"Genres"`
+	Tags              []string          `json:"Tags"`
+	ProviderIDs       map[string]string `json:"ProviderIds"`
+	ImageTags         map[string]string `json:"ImageTags"`
+	BackdropImageTags []string          `json:"BackdropImageTags"`
+}
+
+// Episode ist eine Episode einer Serie inklusive Medien-Streams und Laufzeit.
+type Episode struct {
+	ID                string        `json:"Id"`
+	Name              string        `json:"Name"`
+	Path              string        `json:"Path"`
+	IndexNumber       *int          `json:"IndexNumber"`
+	ParentIndexNumber *int          `json:"ParentIndexNumber"`
+	PremiereDate      *string       `json:"PremiereDate"`
+	MediaStreams      []MediaStream `json:"MediaStreams"`
+	RunTimeTicks      *int64        `json:"RunTimeTicks"`
+}
+
+// MediaStream ist ein Video-, Audio- oder Untertitel-Stream einer Episode.
+type MediaStream struct {
+	Type   string `json:"Type"`
+	Height *int   `json:"Height"`
+}
+
+// ImageOptions steuert Größe und Qualität eines angeforderten Bildes.
+type ImageOptions struct {
+	MaxWidth *int
+	Quality  *int
+}
+
+// MediaServerProvider ist die gemeinsame Schnittstelle aller Media-Server. Lesende Methoden
+// liefern (nil, nil), wenn ein Item nicht existiert; Fehler bedeuten Transport- oder
+// Upstream-Probleme.
+type MediaServerProvider interface {
+	// Kind liefert KindJellyfin oder KindEmby.
+	Kind() string
+	// Configured meldet, ob Basis-URL und API-Schlüssel gesetzt sind.
+	Configured() bool
+
+	SearchSeries(ctx context.Context, term string, limit int) ([]Series, error)
+	// ListLibrarySeries liefert alle Serien der freigegebenen Bibliotheken.
+	ListLibrarySeries(ctx context.Context) ([]Series, error)
+	GetSeries(ctx context.Context, seriesID string) (*Series, error)
+	// GetSeriesDetail liefert zusätzlich Provider-IDs, Genres, Tags und Bild-Tags.
+	GetSeriesDetail(ctx context.Context, seriesID string) (*Series, error)
+	ListEpisodes(ctx context.Context, seriesID string) ([]Episode, error)
+	// GetRuntimeSeconds liefert die Laufzeit eines Items in Sekunden oder nil, wenn unbekannt.
+	GetRuntimeSeconds(ctx context.Context, itemID string) (*int32, error)
+	ListThemeVideoIDs(ctx context.Context, seriesID string) ([]string, error)
+
+	// ImageURL baut die Upstream-URL eines Bildes; index wird nur für Backdrops ausgewertet.
+	ImageURL(itemID string, imageType string, index *int, opts ImageOptions) (string, error)
+	ImageExists(ctx context.Context, itemID string, imageType string, index *int) (bool, error)
+	// StreamURL liefert die Direct-Stream-URL (static=true) eines Items.
+	StreamURL(itemID string) (string, error)
+	// HLSURL liefert die HLS-Master-Playlist eines Items.
+	HLSURL(itemID string) (string, error)
+	// Authorize ergänzt eine Upstream-URL dieses Servers um den API-Schlüssel.
+	Authorize(target *url.URL)
+}
+
+// Typen von Media-Segmenten (Jellyfin ab 10.10).
+const (
+	MediaSegmentTypeIntro = "Intro"
+	MediaSegmentTypeOutro = "Outro"
+)
+
+// MediaSegment ist ein erkannter Abschnitt eines Items (MediaSegmentDto, Ticks = 100 ns).
+type MediaSegment struct {
+	ID         string `json:"Id"`
+	ItemID     string `json:"ItemId"`
+	Type       string `json:"Type"`
+	StartTicks int64  `json:"StartTicks"`
+	EndTicks   int64  `json:"EndTicks"`
+}
+
+// MediaSegmentProvider ist optional: nur Jellyfin liefert erkannte Intro-/Outro-Segmente. Aufrufer
+// prüfen per Type-Assertion, ob der konfigurierte Server sie unterstützt.
+type MediaSegmentProvider interface {
+	// ListMediaSegments liefert die Segmente der angegebenen Typen (leer = alle) oder nil, wenn
+	// das Item nicht existiert.
+	ListMediaSegments(ctx context.Context, itemID string, types []string) ([]MediaSegment, error)
+}
+
+// Config enthält Verbindungsdaten und Pfad-Templates eines Media-Servers.
+type Config struct {
+	BaseURL string
+	APIKey  string
+	// AllowedLibraryIDs beschränkt SearchSeries und ListLibrarySeries auf diese Bibliotheken
+	// (leer = alle).
+	AllowedLibraryIDs []string
+	// StreamPathTemplate und HLSPathTemplate enthalten genau ein %s für die Item-ID.
+	StreamPathTemplate string
+	HLSPathTemplate    string
+	HTTPClient         *http.Client
+}
+
+// New erstellt den Provider für kind ("jellyfin" oder "emby").
+func New(kind string, cfg Config) (MediaServerProvider, error) {
+	switch NormalizeKind(kind) {
+	case KindJellyfin:
+		return NewJellyfin(cfg), nil
+	case KindEmby:
+		return NewEmby(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown media server %q", kind)
+	}
+}
+
+// NormalizeKind vereinheitlicht Schreibweise und Leerzeichen eines Provider-Namens.
+func NormalizeKind(kind string) string {
+	return strings.ToLower(strings.TrimSpace(kind))
+}
+
+// RuntimeTicksToSeconds rechnet 100-ns-Ticks in ganze Sekunden um; nil bei fehlenden oder
+// ungültigen Werten.
+func RuntimeTicksToSeconds(ticks *int64) *int32 {
+	const maxInt32 = int64(2147483647)
+
+	if ticks == nil || *ticks <= 0 {
+		return nil
+	}
+	seconds := *ticks / 10_000_000
+	if seconds <= 0 || seconds > maxInt32 {
+		return nil
+	}
+	value := int32(seconds)
+	return &value
+}
+
+func normalizePathTemplate(raw string, fallback string) string {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || !strings.Contains(trimmed, "%s") {
+		return fallback
+	}
+	return trimmed
+}
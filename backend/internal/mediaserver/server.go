@@ -0,0 +1,485 @@
+package mediaserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// restServer implementiert die REST-API, die Jellyfin von Emby geerbt hat. Jellyfin und Emby
+// betten ihn ein und setzen nur ihren Namen; Abweichungen gehören in die jeweiligen Typen.
+type restServer struct {
+	kind               string
+	baseURL            string
+	apiKey             string
+	allowedLibraryIDs  []string
+	streamPathTemplate string
+	hlsPathTemplate    string
+	httpClient         *http.Client
+}
+
+type seriesListResponse struct {
+	Items []Series `json:"Items"`
+}
+
+type episodeListResponse struct {
+	Items []Episode `json:"Items"`
+}
+
+type themeVideosResponse struct {
+	Items []struct {
+		ID string `json:"Id"`
+	} `json:"Items"`
+}
+
+func newRestServer(kind string, cfg Config) restServer {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	allowed := make([]string, 0, len(cfg.AllowedLibraryIDs))
+	for _, libraryID := range cfg.AllowedLibraryIDs {
+		if trimmed := strings.TrimSpace(libraryID); trimmed != "" {
+			allowed = append(allowed, trimmed)
+		}
+	}
+
+	return restServer{
+		kind:               kind,
+		baseURL:            strings.TrimSpace(cfg.BaseURL),
+		apiKey:             strings.TrimSpace(cfg.APIKey),
+		allowedLibraryIDs:  allowed,
+		streamPathTemplate: normalizePathTemplate(cfg.StreamPathTemplate, defaultStreamPathTemplate),
+		hlsPathTemplate:    normalizePathTemplate(cfg.HLSPathTemplate, defaultHLSPathTemplate),
+		httpClient:         httpClient,
+	}
+}
+
+func (s *restServer) Kind() string {
+	return s.kind
+}
+
+func (s *restServer) Configured() bool {
+	return s.baseURL != "" && s.apiKey != ""
+}
+
+// SearchSeries sucht Serien per Titel. Sind Bibliotheken freigegeben, wird je Bibliothek
+// mit ParentId gesucht und nach Item-ID dedupliziert.
+func (s *restServer) SearchSeries(ctx context.Context, term string, limit int) ([]Series, error) {
+	values := url.Values{}
+	values.Set("SearchTerm", strings.TrimSpace(term))
+	values.Set("Limit", strconv.Itoa(limit))
+	return s.listSeries(ctx, values)
+}
+
+// ListLibrarySeries liefert alle Serien der freigegebenen Bibliotheken (ohne Freigabe: alle
+// Serien des Servers), dedupliziert nach Item-ID.
+func (s *restServer) ListLibrarySeries(ctx context.Context) ([]Series, error) {
+	return s.listSeries(ctx, url.Values{})
+}
+
+func (s *restServer) listSeries(ctx context.Context, values url.Values) ([]Series, error) {
+	values.Set("IncludeItemTypes", "Series")
+	values.Set("Recursive", "true")
+	values.Set("Fields", "Path,ProductionYear,Overview")
+
+	parentIDs := s.allowedLibraryIDs
+	if len(parentIDs) == 0 {
+		parentIDs = []string{""}
+	}
+
+	seen := make(map[string]struct{})
+	items := make([]Series, 0)
+	for _, parentID := range parentIDs {
+		query := cloneValues(values)
+		if parentID != "" {
+			query.Set("ParentId", parentID)
+		}
+
+		var payload seriesListResponse
+		if _, err := s.FetchJSON(ctx, "/Items", query, &payload); err != nil {
+			return nil, err
+		}
+		for _, item := range payload.Items {
+			itemID := strings.TrimSpace(item.ID)
+			if itemID == "" {
+				continue
+			}
+			if _, exists := seen[itemID]; exists {
+				continue
+			}
+			seen[itemID] = struct{}{}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+func (s *restServer) GetSeries(ctx context.Context, seriesID string) (*Series, error) {
+	return s.getSeries(ctx, seriesID, "Path,ProductionYear,Overview")
+}
+
+func (s *restServer) GetSeriesDetail(ctx context.Context, seriesID string) (*Series, error) {
+	return s.getSeries(ctx, seriesID, "Path,ProductionYear,Overview,ProviderIds,Genres,Tags,ImageTags,BackdropImageTags")
+}
+
+func (s *restServer) getSeries(ctx context.Context, seriesID string, fields string) (*Series, error) {
+	trimmedSeriesID := strings.TrimSpace(seriesID)
+	if trimmedSeriesID == "" {
+		return nil, nil
+	}
+
+	values := url.Values{}
+	values.Set("IncludeItemTypes", "Series")
+	values.Set("Recursive", "true")
+	values.Set("Ids", trimmedSeriesID)
+	values.Set("Limit", "1")
+	values.Set("Fields", fields)
+
+	var payload seriesListResponse
+	statusCode, err := s.FetchJSON(ctx, "/Items", values, &payload)
+	if statusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(payload.Items) == 0 {
+		return nil, nil
+	}
+
+	for _, item := range payload.Items {
+		if strings.TrimSpace(item.ID) == trimmedSeriesID {
+			return &item, nil
+		}
+	}
+
+	return &payload.Items[0], nil
+}
+
+func (s *restServer) ListEpisodes(ctx context.Context, seriesID string) ([]Episode, error) {
+	values := url.Values{}
+	values.Set("Fields", "MediaStreams,Path,RunTimeTicks")
+	values.Set("EnableUserData", "false")
+
+	var payload episodeListResponse
+	if _, err := s.FetchJSON(ctx, fmt.Sprintf("/Shows/%s/Episodes", url.PathEscape(seriesID)), values, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Items, nil
+}
+
+func (s *restServer) GetRuntimeSeconds(ctx context.Context, itemID string) (*int32, error) {
+	trimmedItemID := strings.TrimSpace(itemID)
+	if trimmedItemID == "" {
+		return nil, nil
+	}
+
+	values := url.Values{}
+	values.Set("Ids", trimmedItemID)
+	values.Set("Limit", "1")
+	values.Set("Fields", "RunTimeTicks")
+
+	var payload episodeListResponse
+	statusCode, err := s.FetchJSON(ctx, "/Items", values, &payload)
+	if statusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(payload.Items) == 0 {
+		return nil, nil
+	}
+
+	for _, item := range payload.Items {
+		if strings.TrimSpace(item.ID) == trimmedItemID {
+			return RuntimeTicksToSeconds(item.RunTimeTicks), nil
+		}
+	}
+
+	return RuntimeTicksToSeconds(payload.Items[0].RunTimeTicks), nil
+}
+
+// ListThemeVideoIDs liefert die Item-IDs der Themenvideos einer Serie ohne Duplikate.
+func (s *restServer) ListThemeVideoIDs(ctx context.Context, seriesID string) ([]string, error) {
+	trimmedSeriesID := strings.TrimSpace(seriesID)
+	if trimmedSeriesID == "" {
+		return []string{}, nil
+	}
+
+	var payload themeVideosResponse
+	apiPath := fmt.Sprintf("/Items/%s/ThemeVideos", url.PathEscape(trimmedSeriesID))
+	if _, err := s.FetchJSON(ctx, apiPath, url.Values{}, &payload); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(payload.Items))
+	result := make([]string, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		trimmedID := strings.TrimSpace(item.ID)
+		if trimmedID == "" {
+			continue
+		}
+		if _, exists := seen[trimmedID]; exists {
+			continue
+		}
+		seen[trimmedID] = struct{}{}
+		result = append(result, trimmedID)
+	}
+
+	return result, nil
+}
+
+func (s *restServer) ImageURL(itemID string, imageType string, index *int, opts ImageOptions) (string, error) {
+	trimmedItemID := strings.TrimSpace(itemID)
+	if trimmedItemID == "" {
+		return "", fmt.Errorf("media item id is missing")
+	}
+
+	imagePath := fmt.Sprintf("/Items/%s/Images/%s", url.PathEscape(trimmedItemID), imageType)
+	if strings.EqualFold(imageType, "Backdrop") && index != nil {
+		imagePath = fmt.Sprintf("%s/%d", imagePath, *index)
+	}
+
+	query := url.Values{}
+	if opts.MaxWidth != nil {
+		query.Set("maxWidth", strconv.Itoa(*opts.MaxWidth))
+	}
+	if opts.Quality != nil {
+		query.Set("quality", strconv.Itoa(*opts.Quality))
+	}
+
+	target, err := s.buildURL(imagePath, query)
+	if err != nil {
+		return "", err
+	}
+	return target.String(), nil
+}
+
+// ImageExists prüft ein Bild über eine verkleinerte Variante. 4xx gilt als "nicht
+// vorhanden", erst 5xx und Transportfehler als Fehler.
+func (s *restServer) ImageExists(ctx context.Context, itemID string, imageType string, index *int) (bool, error) {
+	maxWidth, quality := 64, 35
+	targetURL, err := s.ImageURL(itemID, imageType, index, ImageOptions{MaxWidth: &maxWidth, Quality: &quality})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("create %s request: %w", s.kind, err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("call %s: %w", s.kind, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return false, fmt.Errorf("%s returned status %d", s.kind, resp.StatusCode)
+	}
+	return resp.StatusCode >= 200 && resp.StatusCode < 300, nil
+}
+
+func (s *restServer) StreamURL(itemID string) (string, error) {
+	return s.itemURL(s.streamPathTemplate, itemID, "static", "true")
+}
+
+// HLSURL setzt MediaSourceId auf die Item-ID; für einfache Items sind beide identisch.
+func (s *restServer) HLSURL(itemID string) (string, error) {
+	return s.itemURL(s.hlsPathTemplate, itemID, "MediaSourceId", strings.TrimSpace(itemID))
+}
+
+func (s *restServer) itemURL(pathTemplate string, itemID string, key string, value string) (string, error) {
+	trimmedItemID := strings.TrimSpace(itemID)
+	if trimmedItemID == "" {
+		return "", fmt.Errorf("media item id is missing")
+	}
+
+	query := url.Values{}
+	query.Set(key, value)
+	target, err := s.buildURL(fmt.Sprintf(pathTemplate, url.PathEscape(trimmedItemID)), query)
+	if err != nil {
+		return "", err
+	}
+	return target.String(), nil
+}
+
+func (s *restServer) Authorize(target *url.URL) {
+	if target == nil {
+		return
+	}
+	query := target.Query()
+	query.Set("api_key", s.apiKey)
+	target.RawQuery = query.Encode()
+}
+
+// FetchJSON sendet eine GET-Anfrage an apiPath und dekodiert die Antwort in target. Der
+// Statuscode wird auch im Fehlerfall zurückgegeben, damit Aufrufer 404 unterscheiden können.
+func (s *restServer) FetchJSON(ctx context.Context, apiPath string, query url.Values, target any) (int, error) {
+	startedAt := time.Now()
+	logPath := strings.TrimSpace(apiPath)
+
+	if s.baseURL == "" {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s base url missing", s.kind)
+	}
+	if s.apiKey == "" {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s api key missing", s.kind)
+	}
+
+	targetURL, err := s.buildURL(apiPath, query)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("create %s request: %w", s.kind, err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		log.Printf(
+			"mediaserver %s_http: request failed (path=%s, elapsed_ms=%d, category=%s): %v",
+			s.kind, logPath, time.Since(startedAt).Milliseconds(), ClassifyTransportError(err), err,
+		)
+		return 0, fmt.Errorf("call %s: %w", s.kind, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		log.Printf(
+			"mediaserver %s_http: upstream status (path=%s, status=%d, elapsed_ms=%d)",
+			s.kind, logPath, resp.StatusCode, time.Since(startedAt).Milliseconds(),
+		)
+		return resp.StatusCode, fmt.Errorf("%s returned status %d", s.kind, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf(
+			"mediaserver %s_http: read response failed (path=%s, status=%d, elapsed_ms=%d): %v",
+			s.kind, logPath, resp.StatusCode, time.Since(startedAt).Milliseconds(), err,
+		)
+		return resp.StatusCode, fmt.Errorf("read %s response: %w", s.kind, err)
+	}
+	body = NormalizeResponseEncoding(body, resp.Header.Get("Content-Type"))
+	if err := json.Unmarshal(body, target); err != nil {
+		log.Printf(
+			"mediaserver %s_http: decode response failed (path=%s, status=%d, elapsed_ms=%d): %v",
+			s.kind, logPath, resp.StatusCode, time.Since(startedAt).Milliseconds(), err,
+		)
+		return resp.StatusCode, fmt.Errorf("decode %s response: %w", s.kind, err)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// buildURL hängt den bereits escapten apiPath an die Basis-URL (inklusive eventuellem Präfix
+// wie /jellyfin) und ergänzt den API-Schlüssel.
+func (s *restServer) buildURL(apiPath string, query url.Values) (*url.URL, error) {
+	if s.baseURL == "" {
+		return nil, fmt.Errorf("media base url is missing")
+	}
+	if s.apiKey == "" {
+		return nil, fmt.Errorf("media api key is missing")
+	}
+
+	parsedBase, err := url.Parse(s.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s base url: %w", s.kind, err)
+	}
+
+	target := parsedBase.JoinPath(strings.TrimPrefix(apiPath, "/"))
+	values := cloneValues(query)
+	values.Set("api_key", s.apiKey)
+	target.RawQuery = values.Encode()
+
+	return target, nil
+}
+
+func cloneValues(values url.Values) url.Values {
+	cloned := url.Values{}
+	for key, entries := range values {
+		for _, value := range entries {
+			cloned.Add(key, value)
+		}
+	}
+	return cloned
+}
+
+// NormalizeResponseEncoding konvertiert einen nicht-UTF-8-kodierten Antwort-Body in UTF-8.
+func NormalizeResponseEncoding(body []byte, contentType string) []byte {
+	if len(body) == 0 || utf8.Valid(body) {
+		return body
+	}
+
+	normalizedContentType := strings.ToLower(strings.TrimSpace(contentType))
+	switch {
+	case strings.Contains(normalizedContentType, "charset=windows-1252"),
+		strings.Contains(normalizedContentType, "charset=cp1252"):
+		if decoded, _, err := transform.Bytes(charmap.Windows1252.NewDecoder(), body); err == nil {
+			return decoded
+		}
+	case strings.Contains(normalizedContentType, "charset=iso-8859-1"),
+		strings.Contains(normalizedContentType, "charset=latin1"):
+		if decoded, _, err := transform.Bytes(charmap.ISO8859_1.NewDecoder(), body); err == nil {
+			return decoded
+		}
+	}
+
+	if decoded, _, err := transform.Bytes(charmap.Windows1252.NewDecoder(), body); err == nil && utf8.Valid(decoded) {
+		return decoded
+	}
+
+	if decoded, _, err := transform.Bytes(charmap.ISO8859_1.NewDecoder(), body); err == nil && utf8.Valid(decoded) {
+		return decoded
+	}
+
+	return body
+}
+
+// ClassifyTransportError kategorisiert einen Transportfehler (timeout, connectivity, transport).
+func ClassifyTransportError(err error) string {
+	if err == nil {
+		return "unknown"
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(err.Error()))
+	switch {
+	case strings.Contains(normalized, "connection refused"),
+		strings.Contains(normalized, "no such host"),
+		strings.Contains(normalized, "network is unreachable"),
+		strings.Contains(normalized, "connectex"):
+		return "connectivity"
+	default:
+		return "transport"
+	}
+}
@@ -0,0 +1,258 @@
+package mediaserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeTestJSON(w http.ResponseWriter, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func TestSearchSeriesQueriesAllowedLibrariesAndDeduplicates(t *testing.T) {
+	var parentIDs []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jellyfin/Items" || r.URL.Query().Get("SearchTerm") != "Frieren" {
+			t.Fatalf("unexpected request %s", r.URL.String())
+		}
+		parentIDs = append(parentIDs, r.URL.Query().Get("ParentId"))
+		writeTestJSON(w, map[string]any{"Items": []map[string]any{
+			{"Id": "series-1", "Name": "Frieren"},
+			{"Id": "series-" + r.URL.Query().Get("ParentId"), "Name": "Frieren Specials"},
+			{"Id": "", "Name": "ohne id"},
+		}})
+	})
+
+	provider := NewJellyfin(Config{
+		BaseURL:           server.URL + "/jellyfin/",
+		APIKey:            "test-key",
+		AllowedLibraryIDs: []string{"lib-a", " ", "lib-b"},
+		HTTPClient:        server.Client(),
+	})
+	items, err := provider.SearchSeries(context.Background(), " Frieren ", 10)
+	if err != nil {
+		t.Fatalf("search series: %v", err)
+	}
+
+	if !reflect.DeepEqual(parentIDs, []string{"lib-a", "lib-b"}) {
+		t.Fatalf("unexpected parent ids %v", parentIDs)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"series-1", "series-lib-a", "series-lib-b"}) {
+		t.Fatalf("unexpected series ids %v", ids)
+	}
+}
+
+func TestListLibrarySeriesQueriesAllowedLibrariesWithoutSearchTerm(t *testing.T) {
+	var parentIDs []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Has("SearchTerm") || query.Has("Limit") || query.Get("IncludeItemTypes") != "Series" {
+			t.Fatalf("unexpected request %s", r.URL.String())
+		}
+		parentIDs = append(parentIDs, query.Get("ParentId"))
+		writeTestJSON(w, map[string]any{"Items": []map[string]any{{"Id": "series-1", "Name": "Frieren"}}})
+	})
+
+	provider := NewEmby(Config{
+		BaseURL:           server.URL,
+		APIKey:            "test-key",
+		AllowedLibraryIDs: []string{"lib-a", "lib-b"},
+		HTTPClient:        server.Client(),
+	})
+	items, err := provider.ListLibrarySeries(context.Background())
+	if err != nil {
+		t.Fatalf("list library series: %v", err)
+	}
+	if !reflect.DeepEqual(parentIDs, []string{"lib-a", "lib-b"}) || len(items) != 1 {
+		t.Fatalf("unexpected parent ids %v or items %+v", parentIDs, items)
+	}
+}
+
+func TestGetSeriesAndRuntimeHandleMissingItems(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("Ids") {
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "episode-1":
+			writeTestJSON(w, map[string]any{"Items": []map[string]any{{"Id": "episode-1", "RunTimeTicks": 14_200_000_000}}})
+		default:
+			writeTestJSON(w, map[string]any{"Items": []map[string]any{{"Id": "series-1", "Name": "Frieren", "ProviderIds": map[string]string{"AniDb": "17617"}}}})
+		}
+	})
+	provider := NewEmby(Config{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()})
+
+	if series, err := provider.GetSeries(context.Background(), "missing"); err != nil || series != nil {
+		t.Fatalf("expected nil series for 404, got %+v, %v", series, err)
+	}
+	detail, err := provider.GetSeriesDetail(context.Background(), "series-1")
+	if err != nil || detail == nil || detail.ProviderIDs["AniDb"] != "17617" {
+		t.Fatalf("unexpected series detail %+v, %v", detail, err)
+	}
+	runtime, err := provider.GetRuntimeSeconds(context.Background(), "episode-1")
+	if err != nil || runtime == nil || *runtime != 1420 {
+		t.Fatalf("unexpected runtime %v, %v", runtime, err)
+	}
+}
+
+func TestFetchJSONReportsUpstreamStatus(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	provider := NewEmby(Config{BaseURL: server.URL, APIKey: "wrong-key", HTTPClient: server.Client()})
+
+	_, err := provider.ListEpisodes(context.Background(), "series-1")
+	if err == nil || err.Error() != "emby returned status 401" {
+		t.Fatalf("expected emby status error, got %v", err)
+	}
+	if _, err := NewJellyfin(Config{}).ListEpisodes(context.Background(), "series-1"); err == nil {
+		t.Fatalf("expected error without base url")
+	}
+}
+
+func TestJellyfinListMediaSegments(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/MediaSegments/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path != "/MediaSegments/episode-1" || !reflect.DeepEqual(r.URL.Query()["includeSegmentTypes"], []string{"Intro", "Outro"}) {
+			t.Fatalf("unexpected request %s", r.URL.String())
+		}
+		writeTestJSON(w, map[string]any{"Items": []map[string]any{
+			{"Id": "seg-1", "ItemId": "episode-1", "Type": "Intro", "StartTicks": 900_000_000, "EndTicks": 1_800_000_000},
+		}})
+	})
+	provider := NewJellyfin(Config{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()})
+
+	segments, err := provider.ListMediaSegments(context.Background(), "episode-1", []string{MediaSegmentTypeIntro, MediaSegmentTypeOutro})
+	if err != nil || len(segments) != 1 || segments[0].Type != "Intro" || segments[0].EndTicks != 1_800_000_000 {
+		t.Fatalf("unexpected segments %+v, %v", segments, err)
+	}
+	if segments, err := provider.ListMediaSegments(context.Background(), "missing", nil); err != nil || segments != nil {
+		t.Fatalf("expected nil segments for 404, got %+v, %v", segments, err)
+	}
+	if _, ok := MediaServerProvider(NewEmby(Config{})).(MediaSegmentProvider); ok {
+		t.Fatalf("emby must not advertise media segments")
+	}
+}
+
+func TestListThemeVideoIDsAndImageExists(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Items/series-1/ThemeVideos":
+			writeTestJSON(w, map[string]any{"Items": []map[string]any{{"Id": "video-1"}, {"Id": "video-1"}, {"Id": " "}, {"Id": "video-2"}}})
+		case "/Items/series-1/Images/Backdrop/2":
+			if r.URL.Query().Get("maxWidth") != "64" {
+				t.Fatalf("expected downscaled probe, got %s", r.URL.RawQuery)
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/Items/series-1/Images/Banner":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	provider := NewJellyfin(Config{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()})
+	ctx := context.Background()
+
+	ids, err := provider.ListThemeVideoIDs(ctx, "series-1")
+	if err != nil || !reflect.DeepEqual(ids, []string{"video-1", "video-2"}) {
+		t.Fatalf("unexpected theme video ids %v, %v", ids, err)
+	}
+
+	index := 2
+	if exists, err := provider.ImageExists(ctx, "series-1", "Backdrop", &index); err != nil || !exists {
+		t.Fatalf("expected backdrop 2 to exist, got %v, %v", exists, err)
+	}
+	if exists, err := provider.ImageExists(ctx, "series-1", "Logo", nil); err != nil || exists {
+		t.Fatalf("expected missing logo without error, got %v, %v", exists, err)
+	}
+	if _, err := provider.ImageExists(ctx, "series-1", "Banner", nil); err == nil {
+		t.Fatalf("expected error for upstream 5xx")
+	}
+}
+
+func TestStreamAndHLSURLs(t *testing.T) {
+	provider, err := New(" Emby ", Config{
+		BaseURL:            "http://media.local:8096",
+		APIKey:             "media-key",
+		StreamPathTemplate: "/emby/Videos/%s/stream.mkv",
+	})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	if provider.Kind() != KindEmby || !provider.Configured() {
+		t.Fatalf("unexpected provider %s", provider.Kind())
+	}
+
+	streamURL, err := provider.StreamURL("abc")
+	if err != nil || streamURL != "http://media.local:8096/emby/Videos/abc/stream.mkv?api_key=media-key&static=true" {
+		t.Fatalf("unexpected stream url %q, %v", streamURL, err)
+	}
+	hlsURL, err := provider.HLSURL("abc")
+	if err != nil || hlsURL != "http://media.local:8096/Videos/abc/master.m3u8?MediaSourceId=abc&api_key=media-key" {
+		t.Fatalf("unexpected hls url %q, %v", hlsURL, err)
+	}
+	if _, err := provider.StreamURL(" "); err == nil {
+		t.Fatalf("expected error for empty item id")
+	}
+
+	width := 640
+	imageURL, err := provider.ImageURL("abc", "Primary", nil, ImageOptions{MaxWidth: &width})
+	if err != nil || imageURL != "http://media.local:8096/Items/abc/Images/Primary?api_key=media-key&maxWidth=640" {
+		t.Fatalf("unexpected image url %q, %v", imageURL, err)
+	}
+
+	segment, _ := url.Parse("http://media.local:8096/Videos/abc/hls1/main/0.ts?api_key=stale")
+	provider.Authorize(segment)
+	if segment.Query().Get("api_key") != "media-key" {
+		t.Fatalf("expected api key to be replaced, got %s", segment.RawQuery)
+	}
+
+	if _, err := New("plex", Config{}); err == nil || !strings.Contains(err.Error(), "unknown media server") {
+		t.Fatalf("expected unknown media server error, got %v", err)
+	}
+}
+
+func TestNormalizeResponseEncoding_Windows1252Body(t *testing.T) {
+	raw := []byte("{\"Overview\":\"Kr\xe4ften und Pr\xfcfung\"}")
+
+	decoded := NormalizeResponseEncoding(raw, "application/json")
+
+	expected := "{\"Overview\":\"Kräften und Prüfung\"}"
+	if string(decoded) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(decoded))
+	}
+}
+
+func TestNormalizeResponseEncoding_ValidUTF8Unchanged(t *testing.T) {
+	raw := []byte("{\"Overview\":\"Kräften und Prüfung\"}")
+
+	decoded := NormalizeResponseEncoding(raw, "application/json; charset=utf-8")
+
+	if string(decoded) != string(raw) {
+		t.Fatalf("expected body to remain unchanged, got %q", string(decoded))
+	}
+}
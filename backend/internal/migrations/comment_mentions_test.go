@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommentMentionsMigrationCreatesMentionTable(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0122_comment_mentions.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0122_comment_mentions.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists comment_mentions",
+		"comment_id bigint not null references comments(id) on delete cascade",
+		"member_id bigint not null references members(id) on delete cascade",
+		"mentioned_app_user_id bigint null references app_users(id) on delete set null",
+		"constraint uq_comment_mentions_comment_member unique (comment_id, member_id)",
+		"create index if not exists idx_comment_mentions_app_user",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists comment_mentions",
+	})
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommentModerationMigrationAddsReportsBansAndStatus(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0120_comment_moderation.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0120_comment_moderation.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"add column if not exists moderation_status varchar(20) not null default 'visible'",
+		"moderation_status in ('visible', 'pending', 'hidden')",
+		"create table if not exists comment_reports",
+		"constraint uq_comment_reports_comment_reporter unique (comment_id, reporter_user_id)",
+		"create table if not exists comment_user_bans",
+		"('comments.moderate', 'kommentare moderieren', 'kommentare', 10)",
+	})
+	assertContainsAll(t, down, []string{
+		"delete from action_definitions where code = 'comments.moderate'",
+		"drop table if exists comment_reports",
+		"drop column if exists moderation_status",
+	})
+}
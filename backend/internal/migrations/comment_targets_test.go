@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommentTargetsMigrationAddsPolymorphicTarget(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0121_comment_targets.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0121_comment_targets.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"add column if not exists target_type varchar(30) not null default 'anime'",
+		"target_id = anime_id",
+		"alter column anime_id drop not null",
+		"target_type in ('anime', 'episode', 'release_version', 'fansub_group')",
+		"create index if not exists idx_comments_target_top_level",
+	})
+	assertContainsAll(t, down, []string{
+		"delete from comments where target_type <> 'anime'",
+		"alter column anime_id set not null",
+		"drop column if exists target_type",
+	})
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommentThreadsMigrationAddsThreadShapeAndRevisions(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0119_comment_threads.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0119_comment_threads.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"add column if not exists parent_id bigint null references comments(id) on delete cascade",
+		"add column if not exists deleted_at timestamptz null",
+		"depth between 1 and 3",
+		"create table if not exists comment_revisions",
+		"constraint uq_comment_revisions_comment_revision unique (comment_id, revision)",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists comment_revisions",
+		"drop column if exists parent_id",
+	})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFansubGroupWebhooksMigrationCreatesWebhooksAndDeliveryLog(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0127_fansub_group_webhooks.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0127_fansub_group_webhooks.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists fansub_group_webhooks",
+		"references fansub_groups(id) on delete cascade",
+		"constraint chk_fansub_group_webhooks_format check (format in ('json', 'discord'))",
+		"create table if not exists webhook_deliveries",
+		"references fansub_group_webhooks(id) on delete cascade",
+		"constraint chk_webhook_deliveries_status check (status in ('pending', 'sending', 'delivered', 'dead'))",
+		"create index if not exists idx_webhook_deliveries_due",
+		"('fansub_group.webhooks.manage', 'webhooks verwalten', 'gruppe', 48)",
+		"('fansub_lead', 'fansub_group.webhooks.manage')",
+	})
+	assertContainsAll(t, down, []string{
+		"delete from action_definitions where code = 'fansub_group.webhooks.manage'",
+		"drop table if exists webhook_deliveries",
+		"drop table if exists fansub_group_webhooks",
+	})
+}
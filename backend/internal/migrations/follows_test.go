@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFollowsMigrationCreatesAnimeFollows(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0126_follows.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0126_follows.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists anime_follows",
+		"references anime(id) on delete cascade",
+		"constraint pk_anime_follows primary key (app_user_id, anime_id)",
+		"create index if not exists idx_release_versions_created",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists anime_follows",
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJellyfinPendingChangesMigrationCreatesQueueAndSettings(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0130_jellyfin_pending_changes.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0130_jellyfin_pending_changes.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists jellyfin_pending_changes",
+		"anime_id bigint not null references anime(id) on delete cascade",
+		"constraint chk_jellyfin_pending_changes_event_type check (event_type in ('item_added', 'item_updated', 'item_removed'))",
+		"constraint chk_jellyfin_pending_changes_status check (status in ('pending', 'applied', 'rejected', 'failed'))",
+		"create unique index if not exists uq_jellyfin_pending_changes_open_item",
+		"where status = 'pending'",
+		"create table if not exists anime_jellyfin_sync_settings",
+		"auto_apply boolean not null default false",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists anime_jellyfin_sync_settings",
+		"drop table if exists jellyfin_pending_changes",
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJellyfinReconciliationMigrationCreatesRunsAndFindings(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0131_jellyfin_reconciliation.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0131_jellyfin_reconciliation.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists jellyfin_reconciliation_runs",
+		"constraint chk_jellyfin_reconciliation_runs_status check (status in ('running', 'completed', 'failed'))",
+		"create unique index if not exists uq_jellyfin_reconciliation_runs_running",
+		"where status = 'running'",
+		"create table if not exists jellyfin_reconciliation_findings",
+		"anime_id bigint not null references anime(id) on delete cascade",
+		"release_variant_id bigint null references release_variants(id) on delete cascade",
+		"'series_missing'",
+		"'episode_without_variant'",
+		"'variant_item_missing'",
+		"'runtime_mismatch'",
+		"constraint chk_jellyfin_reconciliation_findings_status check (status in ('open', 'resolved', 'dismissed'))",
+		"create unique index if not exists uq_jellyfin_reconciliation_findings_active_fingerprint",
+		"where status in ('open', 'dismissed')",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists jellyfin_reconciliation_findings",
+		"drop table if exists jellyfin_reconciliation_runs",
+	})
+}
@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMailOutboxRedactDeadMigrationClearsDeadTemplateData(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0136_mail_outbox_redact_dead.up.sql"))
+
+	assertContainsAll(t, up, []string{
+		"update mail_outbox",
+		"set template_data = '{}'::jsonb",
+		"where status = 'dead'",
+		"and redact_after_send",
+	})
+}
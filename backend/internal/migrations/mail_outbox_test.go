@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMailOutboxMigrationCreatesOutboxTable(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0124_mail_outbox.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0124_mail_outbox.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists mail_outbox",
+		"template_data jsonb not null default '{}'::jsonb",
+		"redact_after_send boolean not null default false",
+		"check (status in ('pending', 'sending', 'sent', 'dead'))",
+		"next_attempt_at timestamptz not null default now()",
+		"create index if not exists idx_mail_outbox_due",
+		"where status in ('pending', 'sending')",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists mail_outbox",
+	})
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMediaBlobsMigrationAddsBlobTableAndAssetReference(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0135_media_blobs.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0135_media_blobs.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists media_blobs",
+		"sha256 char(64) not null",
+		"constraint uq_media_blobs_sha256 unique (sha256)",
+		"constraint uq_media_blobs_storage_path unique (storage_path)",
+		"add column if not exists blob_id bigint null references media_blobs(id) on delete restrict",
+		"create index if not exists idx_media_assets_blob",
+	})
+	assertContainsAll(t, down, []string{
+		"drop column if exists blob_id",
+		"drop table if exists media_blobs",
+	})
+}
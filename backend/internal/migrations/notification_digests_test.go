@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNotificationDigestsMigrationCreatesSettingsAndFollows(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0125_notification_digests.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0125_notification_digests.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists notification_digest_settings",
+		"check (frequency in ('off', 'daily', 'weekly'))",
+		"constraint uq_notification_digest_settings_unsubscribe_token unique (unsubscribe_token)",
+		"create index if not exists idx_notification_digest_settings_due",
+		"create table if not exists fansub_group_follows",
+		"constraint pk_fansub_group_follows primary key (app_user_id, fansub_group_id)",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists fansub_group_follows",
+		"drop table if exists notification_digest_settings",
+	})
+}
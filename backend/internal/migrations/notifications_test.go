@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNotificationsMigrationCreatesNotificationTables(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0123_notifications.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0123_notifications.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists notifications",
+		"app_user_id bigint not null references app_users(id) on delete cascade",
+		"read_at timestamptz null",
+		"create index if not exists idx_notifications_user_unread",
+		"create table if not exists notification_preferences",
+		"constraint pk_notification_preferences primary key (app_user_id, event_type)",
+		"check (channel in ('in_app', 'email', 'off'))",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists notification_preferences",
+		"drop table if exists notifications",
+	})
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlaybackSecurityEventsMigrationCreatesEventsAndBlocks(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0132_playback_security_events.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0132_playback_security_events.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"create table if not exists playback_security_events",
+		"'rate_limit_violation'",
+		"'grant_replay'",
+		"'multiple_ips'",
+		"constraint chk_playback_security_events_severity check (severity in ('info', 'warning', 'critical'))",
+		"create index if not exists idx_playback_security_events_user_created",
+		"create table if not exists playback_grant_blocks",
+		"lifted_by_app_user_id bigint null references app_users(id) on delete set null",
+		"constraint chk_playback_grant_blocks_until check (blocked_until > created_at)",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists playback_grant_blocks",
+		"drop table if exists playback_security_events",
+	})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReleaseVariantTrickplayMigrationAddsOwnerAndTrickplayTable(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0133_release_variant_trickplay.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0133_release_variant_trickplay.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"('trickplay_sprite')",
+		"('trickplay_vtt')",
+		"on conflict (name) do nothing",
+		"add column if not exists owner_release_variant_id bigint null references release_variants(id) on delete set null",
+		"create index if not exists idx_media_assets_owner_release_variant",
+		"create table if not exists release_variant_trickplay",
+		"release_variant_id bigint primary key references release_variants(id) on delete cascade",
+		"vtt_media_asset_id bigint null references media_assets(id) on delete set null",
+		"constraint chk_release_variant_trickplay_status check (status in ('processing', 'ready', 'failed'))",
+	})
+	assertContainsAll(t, down, []string{
+		"drop table if exists release_variant_trickplay",
+		"drop column if exists owner_release_variant_id",
+		"delete from media_types where name in ('trickplay_sprite', 'trickplay_vtt')",
+	})
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSeedThemeTypeRecapMigrationIsIdempotent(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0134_seed_theme_type_recap.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0134_seed_theme_type_recap.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"insert into theme_types (name) values",
+		"('recap')",
+		"on conflict (name) do nothing",
+	})
+	assertContainsAll(t, down, []string{
+		"delete from theme_types tt",
+		"where tt.name = 'recap'",
+		"not exists (select 1 from themes t where t.theme_type_id = tt.id)",
+	})
+}
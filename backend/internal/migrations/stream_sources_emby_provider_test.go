@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStreamSourcesEmbyProviderMigration(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0129_stream_sources_emby_provider.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0129_stream_sources_emby_provider.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"drop constraint if exists chk_stream_sources_provider_type",
+		"check (provider_type in ('jellyfin', 'emby', 'youtube', 'vimeo', 'direct', 'local'))",
+	})
+	assertContainsAll(t, down, []string{
+		"drop constraint if exists chk_stream_sources_provider_type",
+		"check (provider_type in ('jellyfin', 'youtube', 'vimeo', 'direct', 'local')) not valid",
+	})
+}
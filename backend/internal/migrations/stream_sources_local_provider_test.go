@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStreamSourcesLocalProviderMigration(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0128_stream_sources_local_provider.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0128_stream_sources_local_provider.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"drop constraint if exists chk_stream_sources_provider_type",
+		"check (provider_type in ('jellyfin', 'youtube', 'vimeo', 'direct', 'local'))",
+	})
+	assertContainsAll(t, down, []string{
+		"drop constraint if exists chk_stream_sources_provider_type",
+		"check (provider_type in ('jellyfin', 'youtube', 'vimeo', 'direct')) not valid",
+	})
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWatchlistListStatusMigrationDefaultsExistingRowsToPlanned(t *testing.T) {
+	up := strings.ToLower(readMigrationFile(t, "0118_watchlist_list_status.up.sql"))
+	down := strings.ToLower(readMigrationFile(t, "0118_watchlist_list_status.down.sql"))
+
+	assertContainsAll(t, up, []string{
+		"add column if not exists list_status varchar(20) not null default 'planned'",
+		"list_status in ('planned', 'watching', 'on_hold', 'dropped', 'completed')",
+		"score is null or score between 1 and 10",
+		"finished_on >= started_on",
+	})
+	assertContainsAll(t, down, []string{
+		"drop column if exists list_status",
+		"drop column if exists note",
+	})
+}
@@ -139,6 +139,9 @@ type FansubGroupInvitationCreateInput struct {
 	InvitedRoleCodes   []string
 	ExpiresAt          *time.Time
 	CreatedByAppUserID *int64
+	// InviteMail wird in derselben Transaktion in den Mail-Outbox geschrieben. Empfänger,
+	// Referenz und invite_link ergänzt das Repository; nil = keine Einladungsmail.
+	InviteMail *MailOutboxInput
 }
 
 type FansubGroupInvitationCreateResult struct {
@@ -2,24 +2,196 @@ package models
 
 import "time"
 
+// MaxCommentDepth ist die maximale Antworttiefe eines Threads (0 = Top-Level-Kommentar).
+const MaxCommentDepth = 3
+
+// Kommentarziele. Kommentare hängen an genau einem Ziel; anime_id bleibt als Kontext erhalten
+// und ist nur bei Fansubgruppen leer.
+const (
+	CommentTargetAnime          = "anime"
+	CommentTargetEpisode        = "episode"
+	CommentTargetReleaseVersion = "release_version"
+	CommentTargetFansubGroup    = "fansub_group"
+)
+
+// CommentTarget identifiziert das Ziel eines Kommentars.
+type CommentTarget struct {
+	Type string
+	ID   int64
+}
+
+// Moderationsstatus eines Kommentars. Nur sichtbare Kommentare erscheinen mit Inhalt in
+// öffentlichen Listen.
+const (
+	CommentModerationVisible = "visible"
+	CommentModerationPending = "pending"
+	CommentModerationHidden  = "hidden"
+)
+
+// Heuristik-Flags, mit denen ein Kommentar zur Prüfung zurückgehalten wurde.
+const (
+	CommentFlagTooManyLinks    = "too_many_links"
+	CommentFlagRepeatedContent = "repeated_content"
+	CommentFlagNewAccount      = "new_account"
+	CommentFlagBlockedWord     = "blocked_word"
+	CommentFlagReported        = "reported"
+)
+
+// Moderationsaktionen der Admin-Queue.
+const (
+	CommentModerationActionApprove = "approve"
+	CommentModerationActionHide    = "hide"
+	CommentModerationActionBan     = "ban"
+)
+
 // CommentFilter enthält die Paginierungsparameter für Kommentarlistenabfragen.
+// Paginiert wird über Top-Level-Kommentare; Antworten hängen vollständig am Thread.
 type CommentFilter struct {
 	Page    int // Seitennummer (1-basiert)
-	PerPage int // Einträge pro Seite
+	PerPage int // Threads pro Seite
 }
 
 // CommentListItem repräsentiert einen einzelnen Kommentar in der öffentlichen Kommentarliste
-// eines Anime.
+// eines Ziels (Anime, Episode, Release-Version oder Fansubgruppe). Gelöschte Kommentare mit sichtbaren Antworten bleiben als Platzhalter ohne
+// Autor und Inhalt im Thread.
 type CommentListItem struct {
-	ID         int64     `json:"id"`
-	AnimeID    int64     `json:"anime_id"`
-	AuthorName string    `json:"author_name"`
-	Content    string    `json:"content"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         int64             `json:"id"`
+	TargetType string            `json:"target_type"`
+	TargetID   int64             `json:"target_id"`
+	AnimeID    *int64            `json:"anime_id"`
+	ParentID   *int64            `json:"parent_id"`
+	Depth      int16             `json:"depth"`
+	AuthorName string            `json:"author_name"`
+	Content    string            `json:"content"`
+	CreatedAt  time.Time         `json:"created_at"`
+	EditedAt   *time.Time        `json:"edited_at"`
+	IsDeleted  bool              `json:"is_deleted"`
+	ReplyCount int               `json:"reply_count"`
+	Replies    []CommentListItem `json:"replies"`
+
+	// ModerationStatus ist "visible", "pending" (zur Prüfung zurückgehalten) oder "hidden".
+	// Nicht sichtbare Kommentare erscheinen in Listen nur als Platzhalter.
+	ModerationStatus string `json:"moderation_status"`
+
+	// ContentHTML ist der serverseitig gerenderte Markdown-Inhalt mit verlinkten @mentions
+	// (leer bei Platzhaltern).
+	ContentHTML string `json:"content_html"`
+
+	AuthorUserID *int64   `json:"-"`
+	RootID       *int64   `json:"-"`
+	MentionSlugs []string `json:"-"` // aufgelöste @mentions, Grundlage für die Verlinkung
+
+	// AddedMentions enthält nach Anlegen/Bearbeiten die neu hinzugekommenen Erwähnungen.
+	AddedMentions []CommentMentionTarget `json:"-"`
 }
 
 // CommentCreateInput enthält die Eingabedaten zum Erstellen eines neuen Kommentars.
 type CommentCreateInput struct {
-	AuthorName string
-	Content    string
+	AuthorName   string
+	AuthorUserID int64
+	Content      string
+	ParentID     *int64   // nil = neuer Thread
+	HoldFlags    []string // Heuristik-Flags; nicht leer = Kommentar wird zur Prüfung zurückgehalten
+	Mentions     []CommentMentionTarget
+}
+
+// CommentMentionTarget ist ein per @slug erwähntes und aufgelöstes Member-Profil.
+// AppUserID ist nil, wenn hinter dem Profil (noch) kein Account steht.
+type CommentMentionTarget struct {
+	MemberID   int64
+	MemberSlug string
+	AppUserID  *int64
+}
+
+// CommentMentionItem ist ein Eintrag in der Liste der eigenen Erwähnungen (/me/mentions).
+type CommentMentionItem struct {
+	ID          int64     `json:"id"`
+	CommentID   int64     `json:"comment_id"`
+	TargetType  string    `json:"target_type"`
+	TargetID    int64     `json:"target_id"`
+	AnimeID     *int64    `json:"anime_id"`
+	AuthorName  string    `json:"author_name"`
+	Content     string    `json:"content"`
+	ContentHTML string    `json:"content_html"`
+	MemberSlug  string    `json:"member_slug"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	MentionSlugs []string `json:"-"`
+}
+
+// CommentRevision ist ein früherer Stand eines bearbeiteten Kommentars.
+type CommentRevision struct {
+	Revision  int       `json:"revision"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentAuthorSignals sind die aus der Datenbank ermittelten Vergleichswerte der
+// Spam-Heuristiken für den Autor eines neuen Kommentars.
+type CommentAuthorSignals struct {
+	RecentDuplicates int        // identische Kommentare des Autors im Prüfzeitfenster
+	AccountCreatedAt *time.Time // nil = unbekannt (Prüfung entfällt)
+}
+
+// CommentReportInput enthält eine Nutzermeldung zu einem Kommentar.
+type CommentReportInput struct {
+	ReporterUserID int64
+	Reason         string
+	Note           *string
+}
+
+// CommentReportResult beschreibt das Ergebnis einer Meldung.
+type CommentReportResult struct {
+	ReportID    int64 `json:"report_id"`
+	OpenReports int   `json:"open_reports"`
+	HeldBack    bool  `json:"held_back"`
+}
+
+// CommentModerationFilter filtert die Admin-Moderationsqueue.
+type CommentModerationFilter struct {
+	Status  string // "pending", "hidden" oder "reported" (sichtbar, aber mit offenen Meldungen)
+	Page    int
+	PerPage int
+}
+
+// CommentReportSummary fasst die offenen Meldungen eines Kommentars nach Grund zusammen.
+type CommentReportSummary struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// CommentModerationQueueItem ist ein Eintrag der Admin-Moderationsqueue.
+type CommentModerationQueueItem struct {
+	ID               int64                  `json:"id"`
+	TargetType       string                 `json:"target_type"`
+	TargetID         int64                  `json:"target_id"`
+	AnimeID          *int64                 `json:"anime_id"`
+	ParentID         *int64                 `json:"parent_id"`
+	AuthorName       string                 `json:"author_name"`
+	AuthorUserID     *int64                 `json:"author_user_id"`
+	AuthorBanned     bool                   `json:"author_banned"`
+	Content          string                 `json:"content"`
+	CreatedAt        time.Time              `json:"created_at"`
+	ModerationStatus string                 `json:"moderation_status"`
+	ModerationFlags  []string               `json:"moderation_flags"`
+	ModeratedAt      *time.Time             `json:"moderated_at"`
+	OpenReports      int                    `json:"open_reports"`
+	Reports          []CommentReportSummary `json:"reports"`
+}
+
+// CommentModerationInput beschreibt eine Moderationsentscheidung.
+type CommentModerationInput struct {
+	Action             string
+	ModeratorAppUserID int64
+	Reason             *string
+	BanDays            int // nur für "ban"; 0 = unbefristet
+}
+
+// CommentModerationResult ist der Zustand nach einer Moderationsentscheidung.
+type CommentModerationResult struct {
+	CommentID        int64      `json:"comment_id"`
+	ModerationStatus string     `json:"moderation_status"`
+	ResolvedReports  int        `json:"resolved_reports"`
+	BannedUserID     *int64     `json:"banned_user_id,omitempty"`
+	BanExpiresAt     *time.Time `json:"ban_expires_at,omitempty"`
 }
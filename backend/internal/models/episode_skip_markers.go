@@ -0,0 +1,62 @@
+package models
+
+// Arten der Skip-Marker, abgeleitet aus dem Theme-Typ eines Segments.
+const (
+	SkipMarkerKindIntro = "intro"
+	SkipMarkerKindOutro = "outro"
+	SkipMarkerKindRecap = "recap"
+)
+
+// EpisodeSkipMarkerSegment ist ein Theme-Segment, das auf eine Episodenversion zutrifft. Die
+// Zeiten sind bereits in Sekunden aufgelöst (Playback-Offsets vor start_time/end_time).
+type EpisodeSkipMarkerSegment struct {
+	SegmentID     int64
+	ThemeTypeName string
+	ThemeTitle    *string
+	StartSeconds  *int32
+	EndSeconds    *int32
+}
+
+// EpisodeSkipMarkerSource bündelt die Release-Variante einer Episodenversion mit ihren Segmenten.
+type EpisodeSkipMarkerSource struct {
+	ReleaseVariantID int64
+	ReleaseVersionID int64
+	DurationSeconds  *int32
+	JellyfinItemID   *string
+	Segments         []EpisodeSkipMarkerSegment
+}
+
+// EpisodeSkipMarker ist ein überspringbarer Abschnitt (Intro, Outro, Recap) einer Episodenversion.
+type EpisodeSkipMarker struct {
+	SegmentID    int64   `json:"segment_id"`
+	Kind         string  `json:"kind"`
+	Label        string  `json:"label"`
+	ThemeType    string  `json:"theme_type"`
+	ThemeTitle   *string `json:"theme_title"`
+	StartSeconds int32   `json:"start_seconds"`
+	EndSeconds   int32   `json:"end_seconds"`
+}
+
+// EpisodeSkipMarkersData ist die JSON-Antwort des Skip-Marker-Endpunkts.
+type EpisodeSkipMarkersData struct {
+	ReleaseVariantID int64               `json:"release_variant_id"`
+	ReleaseVersionID int64               `json:"release_version_id"`
+	DurationSeconds  *int32              `json:"duration_seconds"`
+	Markers          []EpisodeSkipMarker `json:"markers"`
+}
+
+// JellyfinMediaSegment entspricht MediaSegmentDto der Jellyfin-API (Ticks = 100 ns).
+type JellyfinMediaSegment struct {
+	ID         string  `json:"Id"`
+	ItemID     *string `json:"ItemId"`
+	Type       string  `json:"Type"`
+	StartTicks int64   `json:"StartTicks"`
+	EndTicks   int64   `json:"EndTicks"`
+}
+
+// JellyfinMediaSegmentsResult entspricht dem QueryResult von GET /MediaSegments/{itemId}.
+type JellyfinMediaSegmentsResult struct {
+	Items            []JellyfinMediaSegment `json:"Items"`
+	TotalRecordCount int                    `json:"TotalRecordCount"`
+	StartIndex       int                    `json:"StartIndex"`
+}
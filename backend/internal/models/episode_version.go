@@ -36,6 +36,7 @@ type GroupedEpisode struct {
 	EpisodeTitle     *string          `json:"episode_title,omitempty"`
 	DefaultVersionID *int64           `json:"default_version_id,omitempty"`
 	VersionCount     int32            `json:"version_count"`
+	CommentCount     int64            `json:"comment_count"`
 	Versions         []EpisodeVersion `json:"versions"`
 }
 
@@ -124,6 +125,7 @@ type EpisodeVersionEditorContext struct {
 type EpisodeVersionMediaFile struct {
 	FileName              string     `json:"file_name"`
 	Path                  string     `json:"path"`
+	MediaProvider         string     `json:"media_provider,omitempty"`
 	MediaItemID           string     `json:"media_item_id"`
 	StreamURL             *string    `json:"stream_url,omitempty"`
 	VideoQuality          *string    `json:"video_quality,omitempty"`
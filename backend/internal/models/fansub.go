@@ -68,6 +68,10 @@ type PublicFansubProfileResponse struct {
 	Projects []PublicFansubProject   `json:"projects"`
 	History  []PublicFansubHistory   `json:"history"`
 	Media    []PublicFansubMediaItem `json:"media"`
+	// CommentCount zählt die sichtbaren Kommentare (inkl. Antworten) an der Gruppe.
+	CommentCount int64 `json:"comment_count"`
+	// FollowerCount zählt die App-User, die der Gruppe folgen.
+	FollowerCount int64 `json:"follower_count"`
 }
 
 // PublicFansubStory is the public, published fansub_group_notes projection.
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Arten von Einträgen im Aktivitäts-Feed unter /me/feed.
+const (
+	FeedKindReleaseVersion = "release_version"
+	FeedKindGroupHistory   = "group_history"
+	FeedKindMemberStory    = "member_story"
+	FeedKindProjectNote    = "project_note"
+)
+
+// FansubGroupRef adressiert eine Fansub-Gruppe über ID oder Slug (ID hat Vorrang).
+type FansubGroupRef struct {
+	ID   int64
+	Slug string
+}
+
+// FollowState ist der Zustand nach Folgen/Entfolgen eines Ziels.
+type FollowState struct {
+	TargetType    string `json:"target_type"` // fansub_group | anime
+	TargetID      int64  `json:"target_id"`
+	Following     bool   `json:"following"`
+	FollowerCount int64  `json:"follower_count"`
+}
+
+// FollowedFansubGroup ist eine gefolgte Fansub-Gruppe in /me/follows.
+type FollowedFansubGroup struct {
+	ID         int64     `json:"id"`
+	Slug       string    `json:"slug"`
+	Name       string    `json:"name"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FollowedAnime ist ein gefolgter Anime in /me/follows.
+type FollowedAnime struct {
+	ID         int64     `json:"id"`
+	Title      string    `json:"title"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FollowList bündelt alle Follows eines Nutzers.
+type FollowList struct {
+	FansubGroups []FollowedFansubGroup `json:"fansub_groups"`
+	Anime        []FollowedAnime       `json:"anime"`
+}
+
+// FeedItem ist ein Eintrag im Aktivitäts-Feed. Anime- und Gruppenfelder sind je nach Art leer.
+type FeedItem struct {
+	Kind            string    `json:"kind"`
+	ID              int64     `json:"id"`
+	OccurredAt      time.Time `json:"occurred_at"`
+	Title           string    `json:"title"`
+	Excerpt         *string   `json:"excerpt"`
+	LinkURL         string    `json:"link_url"`
+	AnimeID         *int64    `json:"anime_id"`
+	AnimeTitle      *string   `json:"anime_title"`
+	EpisodeID       *int64    `json:"episode_id"`
+	FansubGroupID   *int64    `json:"fansub_group_id"`
+	FansubGroupSlug *string   `json:"fansub_group_slug"`
+	FansubGroupName *string   `json:"fansub_group_name"`
+}
+
+// FeedMeta enthält den Cursor der nächsten Seite (nil auf der letzten Seite).
+type FeedMeta struct {
+	NextCursor *string `json:"next_cursor"`
+}
+
+// FeedCursor ist die Position des letzten ausgelieferten Feed-Eintrags. Die Sortierung
+// (occurred_at, kind, id) absteigend ist eindeutig und damit stabil über Seiten hinweg.
+type FeedCursor struct {
+	OccurredAt time.Time
+	Kind       string
+	ID         int64
+}
+
+// ErrInvalidFeedCursor wird für nicht lesbare Cursor geliefert.
+var ErrInvalidFeedCursor = errors.New("invalid feed cursor")
+
+// FeedCursorAfter liefert den Cursor, der auf item folgt.
+func FeedCursorAfter(item FeedItem) FeedCursor {
+	return FeedCursor{OccurredAt: item.OccurredAt, Kind: item.Kind, ID: item.ID}
+}
+
+// Encode serialisiert den Cursor URL-sicher.
+func (c FeedCursor) Encode() string {
+	raw := c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.Kind + "|" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseFeedCursor liest einen mit Encode erzeugten Cursor.
+func ParseFeedCursor(value string) (FeedCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return FeedCursor{}, ErrInvalidFeedCursor
+	}
+	parts := strings.Split(string(decoded), "|")
+	if len(parts) != 3 {
+		return FeedCursor{}, ErrInvalidFeedCursor
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return FeedCursor{}, ErrInvalidFeedCursor
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || id <= 0 || !isFeedKind(parts[1]) {
+		return FeedCursor{}, ErrInvalidFeedCursor
+	}
+	return FeedCursor{OccurredAt: occurredAt, Kind: parts[1], ID: id}, nil
+}
+
+func isFeedKind(kind string) bool {
+	switch kind {
+	case FeedKindReleaseVersion, FeedKindGroupHistory, FeedKindMemberStory, FeedKindProjectNote:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestFeedCursorRoundTrip(t *testing.T) {
+	cursor := models.FeedCursor{
+		OccurredAt: time.Date(2026, 6, 1, 12, 30, 0, 123456000, time.UTC),
+		Kind:       models.FeedKindProjectNote,
+		ID:         77,
+	}
+
+	parsed, err := models.ParseFeedCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if !parsed.OccurredAt.Equal(cursor.OccurredAt) || parsed.Kind != cursor.Kind || parsed.ID != cursor.ID {
+		t.Fatalf("expected %+v, got %+v", cursor, parsed)
+	}
+
+	for _, raw := range []string{"", "%%%", models.FeedCursor{OccurredAt: cursor.OccurredAt, Kind: "comment", ID: 1}.Encode()} {
+		if _, err := models.ParseFeedCursor(raw); err == nil {
+			t.Fatalf("expected error for cursor %q", raw)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Ereignistypen des Jellyfin-Webhooks in normalisierter Form.
+const (
+	JellyfinChangeEventItemAdded   = "item_added"
+	JellyfinChangeEventItemUpdated = "item_updated"
+	JellyfinChangeEventItemRemoved = "item_removed"
+)
+
+// Zustände einer ausstehenden Jellyfin-Änderung.
+const (
+	JellyfinPendingChangeStatusPending  = "pending"
+	JellyfinPendingChangeStatusApplied  = "applied"
+	JellyfinPendingChangeStatusRejected = "rejected"
+	JellyfinPendingChangeStatusFailed   = "failed"
+)
+
+// IsJellyfinPendingChangeStatus meldet, ob status ein gültiger Änderungsstatus ist.
+func IsJellyfinPendingChangeStatus(status string) bool {
+	switch status {
+	case JellyfinPendingChangeStatusPending,
+		JellyfinPendingChangeStatusApplied,
+		JellyfinPendingChangeStatusRejected,
+		JellyfinPendingChangeStatusFailed:
+		return true
+	}
+	return false
+}
+
+// JellyfinPendingChange ist eine per Webhook gemeldete Änderung eines Jellyfin-Items, die auf
+// Übernahme in die Episoden einer Anime wartet (oder bereits entschieden wurde).
+type JellyfinPendingChange struct {
+	ID                  int64           `json:"id"`
+	AnimeID             int64           `json:"anime_id"`
+	EventType           string          `json:"event_type"`
+	ItemType            string          `json:"item_type"`
+	JellyfinItemID      string          `json:"jellyfin_item_id"`
+	JellyfinSeriesID    string          `json:"jellyfin_series_id"`
+	ItemName            *string         `json:"item_name"`
+	SeasonNumber        *int32          `json:"season_number"`
+	EpisodeNumber       *int32          `json:"episode_number"`
+	Status              string          `json:"status"`
+	EventCount          int32           `json:"event_count"`
+	Result              json.RawMessage `json:"result,omitempty"`
+	ErrorMessage        *string         `json:"error_message"`
+	ResolvedByAppUserID *int64          `json:"resolved_by_app_user_id"`
+	ResolvedAt          *time.Time      `json:"resolved_at"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
+
+// JellyfinPendingChangeInput beschreibt ein eingehendes Webhook-Ereignis für eine Anime.
+type JellyfinPendingChangeInput struct {
+	AnimeID          int64
+	EventType        string
+	ItemType         string
+	JellyfinItemID   string
+	JellyfinSeriesID string
+	ItemName         *string
+	SeasonNumber     *int32
+	EpisodeNumber    *int32
+	Payload          map[string]any
+}
+
+// JellyfinPendingChangeResolution hält das Ergebnis einer Übernahme oder Ablehnung fest.
+type JellyfinPendingChangeResolution struct {
+	Status              string
+	Result              map[string]any
+	ErrorMessage        *string
+	ResolvedByAppUserID *int64
+}
+
+// JellyfinPendingChangeFilter filtert die Admin-Liste; leere Felder bedeuten kein Filter.
+type JellyfinPendingChangeFilter struct {
+	Status  string
+	AnimeID int64
+	Page    int
+	PerPage int
+}
+
+// AnimeJellyfinSyncSettings steuert pro Anime, ob Webhook-Änderungen automatisch übernommen werden.
+type AnimeJellyfinSyncSettings struct {
+	AnimeID   int64      `json:"anime_id"`
+	AutoApply bool       `json:"auto_apply"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
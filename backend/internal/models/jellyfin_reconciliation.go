@@ -0,0 +1,139 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Zustände eines Abgleichlaufs.
+const (
+	JellyfinReconciliationRunStatusRunning   = "running"
+	JellyfinReconciliationRunStatusCompleted = "completed"
+	JellyfinReconciliationRunStatusFailed    = "failed"
+)
+
+// Befundtypen des Bibliotheksabgleichs.
+const (
+	// JellyfinFindingSeriesMissing: die verknüpfte Serie existiert auf dem Server nicht mehr.
+	JellyfinFindingSeriesMissing = "series_missing"
+	// JellyfinFindingSeriesOutsideLibraries: die Serie existiert, liegt aber in keiner
+	// freigegebenen Bibliothek.
+	JellyfinFindingSeriesOutsideLibraries = "series_outside_libraries"
+	// JellyfinFindingEpisodeWithoutVariant: eine Episode der Serie hat keine Release-Variante.
+	JellyfinFindingEpisodeWithoutVariant = "episode_without_variant"
+	// JellyfinFindingVariantItemMissing: eine Release-Variante zeigt auf ein gelöschtes Item.
+	JellyfinFindingVariantItemMissing = "variant_item_missing"
+	// JellyfinFindingRuntimeMismatch: duration_seconds der Variante weicht von der Laufzeit ab.
+	JellyfinFindingRuntimeMismatch = "runtime_mismatch"
+	// JellyfinFindingMetadataDrift: Jellyfin liefert abweichende oder fehlende Metadaten.
+	JellyfinFindingMetadataDrift = "metadata_drift"
+)
+
+// Zustände eines Befunds.
+const (
+	JellyfinFindingStatusOpen      = "open"
+	JellyfinFindingStatusResolved  = "resolved"
+	JellyfinFindingStatusDismissed = "dismissed"
+)
+
+// IsJellyfinFindingType meldet, ob findingType ein bekannter Befundtyp ist.
+func IsJellyfinFindingType(findingType string) bool {
+	switch findingType {
+	case JellyfinFindingSeriesMissing,
+		JellyfinFindingSeriesOutsideLibraries,
+		JellyfinFindingEpisodeWithoutVariant,
+		JellyfinFindingVariantItemMissing,
+		JellyfinFindingRuntimeMismatch,
+		JellyfinFindingMetadataDrift:
+		return true
+	}
+	return false
+}
+
+// IsJellyfinFindingStatus meldet, ob status ein gültiger Befundstatus ist.
+func IsJellyfinFindingStatus(status string) bool {
+	switch status {
+	case JellyfinFindingStatusOpen, JellyfinFindingStatusResolved, JellyfinFindingStatusDismissed:
+		return true
+	}
+	return false
+}
+
+// JellyfinReconciliationRun ist ein Lauf des Bibliotheksabgleichs.
+type JellyfinReconciliationRun struct {
+	ID                   int64      `json:"id"`
+	Status               string     `json:"status"`
+	Provider             string     `json:"provider"`
+	LibraryIDs           []string   `json:"library_ids"`
+	AnimeChecked         int32      `json:"anime_checked"`
+	FindingsDetected     int32      `json:"findings_detected"`
+	FindingsAutoResolved int32      `json:"findings_auto_resolved"`
+	ErrorMessage         *string    `json:"error_message"`
+	StartedAt            time.Time  `json:"started_at"`
+	FinishedAt           *time.Time `json:"finished_at"`
+}
+
+// JellyfinReconciliationRunResult hält die Zähler eines beendeten Laufs fest.
+type JellyfinReconciliationRunResult struct {
+	Status           string
+	AnimeChecked     int32
+	FindingsDetected int32
+	ErrorMessage     *string
+}
+
+// JellyfinReconciliationFinding ist eine beim Abgleich gefundene Abweichung.
+type JellyfinReconciliationFinding struct {
+	ID                  int64           `json:"id"`
+	FindingType         string          `json:"finding_type"`
+	AnimeID             int64           `json:"anime_id"`
+	JellyfinSeriesID    *string         `json:"jellyfin_series_id"`
+	JellyfinItemID      *string         `json:"jellyfin_item_id"`
+	ReleaseVariantID    *int64          `json:"release_variant_id"`
+	EpisodeNumber       *string         `json:"episode_number"`
+	Details             json.RawMessage `json:"details"`
+	Status              string          `json:"status"`
+	FirstRunID          *int64          `json:"first_run_id"`
+	LastRunID           *int64          `json:"last_run_id"`
+	SeenCount           int32           `json:"seen_count"`
+	ResolvedByAppUserID *int64          `json:"resolved_by_app_user_id"`
+	ResolvedAt          *time.Time      `json:"resolved_at"`
+	FirstSeenAt         time.Time       `json:"first_seen_at"`
+	LastSeenAt          time.Time       `json:"last_seen_at"`
+}
+
+// JellyfinReconciliationFindingInput beschreibt einen Befund eines Laufs. Fingerprint
+// identifiziert dieselbe Abweichung über Läufe hinweg.
+type JellyfinReconciliationFindingInput struct {
+	Fingerprint      string
+	FindingType      string
+	AnimeID          int64
+	JellyfinSeriesID *string
+	JellyfinItemID   *string
+	ReleaseVariantID *int64
+	EpisodeNumber    *string
+	Details          map[string]any
+}
+
+// JellyfinReconciliationFindingFilter filtert die Admin-Liste; leere Felder bedeuten kein Filter.
+type JellyfinReconciliationFindingFilter struct {
+	Status      string
+	FindingType string
+	AnimeID     int64
+	Page        int
+	PerPage     int
+}
+
+// JellyfinLinkedAnime ist eine Anime mit Verknüpfung zu einer Serie des Katalog-Servers.
+type JellyfinLinkedAnime struct {
+	AnimeID  int64
+	SeriesID string
+}
+
+// JellyfinReconciliationVariant ist eine Release-Variante mit Stream auf dem Katalog-Server.
+type JellyfinReconciliationVariant struct {
+	VariantID       int64
+	AnimeID         int64
+	EpisodeNumber   string
+	ItemID          string
+	DurationSeconds *int32
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Zustände einer Mail im Outbox. pending und sending werden vom Worker abgearbeitet;
+// dead bedeutet: max_attempts erreicht, nur noch manuell erneut einreihbar (nicht bei
+// redact_after_send, deren Template-Daten beim Übergang nach dead geleert werden).
+const (
+	MailOutboxStatusPending = "pending"
+	MailOutboxStatusSending = "sending"
+	MailOutboxStatusSent    = "sent"
+	MailOutboxStatusDead    = "dead"
+)
+
+// Unterstützte Sprachen der Mail-Templates.
+const (
+	MailLocaleDE = "de"
+	MailLocaleEN = "en"
+)
+
+// DefaultMailLocale wird verwendet, wenn keine oder eine unbekannte Sprache angegeben ist.
+const DefaultMailLocale = MailLocaleDE
+
+// IsMailLocale meldet, ob locale eine unterstützte Template-Sprache ist.
+func IsMailLocale(locale string) bool {
+	return locale == MailLocaleDE || locale == MailLocaleEN
+}
+
+// IsMailOutboxStatus meldet, ob status ein gültiger Outbox-Status ist.
+func IsMailOutboxStatus(status string) bool {
+	switch status {
+	case MailOutboxStatusPending, MailOutboxStatusSending, MailOutboxStatusSent, MailOutboxStatusDead:
+		return true
+	}
+	return false
+}
+
+// MailOutboxInput beschreibt eine einzureihende Mail. Der Inhalt wird erst beim Versand
+// aus TemplateName, Locale und TemplateData gerendert.
+type MailOutboxInput struct {
+	TemplateName    string
+	Locale          string
+	Recipient       string
+	TemplateData    map[string]any
+	RedactAfterSend bool
+	ReferenceType   *string
+	ReferenceID     *int64
+}
+
+// MailOutboxMessage ist eine vom Worker beanspruchte Mail inklusive Template-Daten.
+type MailOutboxMessage struct {
+	ID           int64
+	TemplateName string
+	Locale       string
+	Recipient    string
+	TemplateData map[string]any
+	Attempts     int
+	MaxAttempts  int
+}
+
+// MailOutboxItem ist die Admin-Sicht auf einen Outbox-Eintrag (ohne Template-Daten).
+type MailOutboxItem struct {
+	ID            int64      `json:"id"`
+	TemplateName  string     `json:"template_name"`
+	Locale        string     `json:"locale"`
+	Recipient     string     `json:"recipient"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     *string    `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	Redacted      bool       `json:"redacted"`
+	ReferenceType *string    `json:"reference_type"`
+	ReferenceID   *int64     `json:"reference_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// MailOutboxFilter filtert die Admin-Liste; leerer Status = alle.
+type MailOutboxFilter struct {
+	Status  string
+	Page    int
+	PerPage int
+}
@@ -36,4 +36,5 @@ type MediaAssetCreateInput struct {
 	Height           *int
 	VisibilityCode   *string // nil = Backend-Default; z.B. "public", "private"
 	ReviewStatusCode *string // nil = Backend-Default; z.B. "approved", "in_review"
+	ContentSHA256    string  // gesetzt bei inhaltsadressierten Uploads; verknüpft das Asset mit media_blobs
 }
@@ -0,0 +1,44 @@
+package models
+
+// MediaAssetFile ist eine media_assets-Zeile mit lesbarem Dateipfad für den Dedupe-Bericht.
+type MediaAssetFile struct {
+	MediaAssetID int64
+	StoragePath  string
+	MimeType     string
+	BlobID       *int64
+}
+
+// MediaDedupeGroup fasst Dateien mit identischem Inhalt zusammen, die unter verschiedenen Pfaden
+// liegen. Die erste Datei ist die kanonische; die übrigen belegen ReclaimableBytes.
+type MediaDedupeGroup struct {
+	SHA256           string   `json:"sha256"`
+	SizeBytes        int64    `json:"size_bytes"`
+	Paths            []string `json:"paths"`
+	MediaAssetIDs    []int64  `json:"media_asset_ids"`
+	ReclaimableBytes int64    `json:"reclaimable_bytes"`
+}
+
+// MediaDedupeReport ist das Ergebnis eines Scans über alle media_assets-Dateien.
+type MediaDedupeReport struct {
+	ScannedAssets     int                `json:"scanned_assets"`
+	HashedFiles       int                `json:"hashed_files"`
+	UniqueContents    int                `json:"unique_contents"`
+	MissingAssetIDs   []int64            `json:"missing_asset_ids"`
+	DuplicateGroups   []MediaDedupeGroup `json:"duplicate_groups"`
+	ReclaimableBytes  int64              `json:"reclaimable_bytes"`
+	RegisteredBlobs   int                `json:"registered_blobs"`
+	LinkedMediaAssets int                `json:"linked_media_assets"`
+}
+
+// MediaBucketMigrationReport ist das Ergebnis von "migrate media-to-bucket".
+type MediaBucketMigrationReport struct {
+	ScannedPaths    int      `json:"scanned_paths"`
+	CopiedObjects   int      `json:"copied_objects"`
+	CopiedBytes     int64    `json:"copied_bytes"`
+	AlreadyInBucket int      `json:"already_in_bucket"`
+	RewrittenPaths  int      `json:"rewritten_paths"`
+	RewrittenRows   int64    `json:"rewritten_rows"`
+	DeletedLocal    int      `json:"deleted_local"`
+	MissingPaths    []string `json:"missing_paths"`
+	ConflictPaths   []string `json:"conflict_paths"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Ereignistypen, zu denen Benachrichtigungen verschickt werden.
+const (
+	NotificationEventContributionConfirmed = "contribution.confirmed"
+	NotificationEventContributionRejected  = "contribution.rejected"
+	NotificationEventMemberClaimVerified   = "member_claim.verified"
+	NotificationEventInvitationReceived    = "invitation.received"
+	NotificationEventCommentMention        = "comment.mention"
+	NotificationEventWatchlistNewEpisode   = "watchlist.new_episode"
+)
+
+// NotificationEventTypes listet alle bekannten Ereignistypen in Anzeigereihenfolge.
+var NotificationEventTypes = []string{
+	NotificationEventContributionConfirmed,
+	NotificationEventContributionRejected,
+	NotificationEventMemberClaimVerified,
+	NotificationEventInvitationReceived,
+	NotificationEventCommentMention,
+	NotificationEventWatchlistNewEpisode,
+}
+
+// Zustellkanäle je Ereignistyp. "email" legt die Benachrichtigung zusätzlich zur E-Mail
+// auch in-app ab, damit die Liste unter /me/notifications vollständig bleibt.
+const (
+	NotificationChannelInApp = "in_app"
+	NotificationChannelEmail = "email"
+	NotificationChannelOff   = "off"
+)
+
+// DefaultNotificationChannel gilt für Ereignistypen ohne gespeicherte Präferenz.
+const DefaultNotificationChannel = NotificationChannelInApp
+
+// IsNotificationEventType meldet, ob eventType ein bekannter Ereignistyp ist.
+func IsNotificationEventType(eventType string) bool {
+	for _, known := range NotificationEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotificationChannel meldet, ob channel ein gültiger Zustellkanal ist.
+func IsNotificationChannel(channel string) bool {
+	switch channel {
+	case NotificationChannelInApp, NotificationChannelEmail, NotificationChannelOff:
+		return true
+	}
+	return false
+}
+
+// Notification ist ein Eintrag im Benachrichtigungszentrum eines Nutzers.
+type Notification struct {
+	ID        int64          `json:"id"`
+	EventType string         `json:"event_type"`
+	Title     string         `json:"title"`
+	Body      *string        `json:"body"`
+	LinkURL   *string        `json:"link_url"`
+	Payload   map[string]any `json:"payload"`
+	IsRead    bool           `json:"is_read"`
+	ReadAt    *time.Time     `json:"read_at"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// NotificationEvent beschreibt ein Ereignis, das an einen oder mehrere Empfänger geht.
+// Der auslösende Nutzer (ActorAppUserID) erhält keine Benachrichtigung über sein eigenes Handeln.
+type NotificationEvent struct {
+	EventType           string
+	RecipientAppUserIDs []int64
+	ActorAppUserID      *int64
+	Title               string
+	Body                *string
+	LinkURL             *string
+	Payload             map[string]any
+}
+
+// NotificationFilter enthält Paginierung und Filter für /me/notifications.
+type NotificationFilter struct {
+	UnreadOnly bool
+	Page       int
+	PerPage    int
+}
+
+// NotificationPreference ist der gewählte Zustellkanal für einen Ereignistyp.
+type NotificationPreference struct {
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+}
+
+// NotificationRecipient ist ein Empfänger samt Kanal und (für E-Mail) Adresse.
+type NotificationRecipient struct {
+	AppUserID int64
+	Channel   string
+	Email     string
+}
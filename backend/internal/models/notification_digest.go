@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Häufigkeiten der Zusammenfassungs-Mail. off ist der Standard für Nutzer ohne Einstellung.
+const (
+	DigestFrequencyOff    = "off"
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+)
+
+// IsDigestFrequency meldet, ob frequency eine gültige Digest-Häufigkeit ist.
+func IsDigestFrequency(frequency string) bool {
+	switch frequency {
+	case DigestFrequencyOff, DigestFrequencyDaily, DigestFrequencyWeekly:
+		return true
+	}
+	return false
+}
+
+// DigestSettings ist die Digest-Einstellung eines Nutzers unter /me/digest-settings.
+type DigestSettings struct {
+	Frequency  string     `json:"frequency"`
+	Locale     string     `json:"locale"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	NextDueAt  *time.Time `json:"next_due_at"`
+}
+
+// DigestSettingsUpdate ist die Eingabe zum Ändern der Digest-Einstellung. NextDueAt wird vom
+// Service aus Häufigkeit und Sendezeitpunkt berechnet (nil bei off).
+type DigestSettingsUpdate struct {
+	Frequency string
+	Locale    string
+	NextDueAt *time.Time
+}
+
+// DigestSubscription ist ein fälliger Digest-Empfänger aus Sicht des Schedulers.
+// LastSentAt ist das Ende des zuletzt verarbeiteten Zeitfensters (nil vor dem ersten Lauf).
+type DigestSubscription struct {
+	AppUserID        int64
+	Email            string
+	Frequency        string
+	Locale           string
+	UnsubscribeToken string
+	LastSentAt       *time.Time
+	NextDueAt        time.Time
+}
+
+// DigestReleaseVersion ist eine neue Release-Version im Digest.
+type DigestReleaseVersion struct {
+	ReleaseVersionID int64     `json:"release_version_id"`
+	AnimeID          int64     `json:"anime_id"`
+	AnimeTitle       string    `json:"anime_title"`
+	EpisodeID        int64     `json:"episode_id"`
+	EpisodeNumber    string    `json:"episode_number"`
+	Version          string    `json:"version"`
+	GroupNames       string    `json:"group_names"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// DigestContent bündelt die Inhalte eines Digest-Zeitfensters. Comments enthält neue Antworten
+// anderer Nutzer in Threads, die der Empfänger begonnen hat.
+type DigestContent struct {
+	ReleaseVersions []DigestReleaseVersion
+	Comments        []CommentListItem
+}
+
+// IsEmpty meldet, ob im Zeitfenster nichts Neues angefallen ist.
+func (c DigestContent) IsEmpty() bool {
+	return len(c.ReleaseVersions) == 0 && len(c.Comments) == 0
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Typen der Sicherheitsereignisse bei der Wiedergabe.
+const (
+	PlaybackSecurityEventRapidGrants  = "rapid_grant_requests"
+	PlaybackSecurityEventMultipleIPs  = "multiple_ips"
+	PlaybackSecurityEventRateLimit    = "rate_limit_violation"
+	PlaybackSecurityEventGrantReplay  = "grant_replay"
+	PlaybackSecurityEventSessionLimit = "session_limit_reached"
+)
+
+// Schweregrade der Sicherheitsereignisse. Nur warning und critical zählen für Grant-Sperren
+// (ohne rate_limit_violation, siehe CountsTowardPlaybackGrantBlock).
+const (
+	PlaybackSecuritySeverityInfo     = "info"
+	PlaybackSecuritySeverityWarning  = "warning"
+	PlaybackSecuritySeverityCritical = "critical"
+)
+
+// IsPlaybackSecurityEventType meldet, ob eventType ein bekannter Ereignistyp ist.
+func IsPlaybackSecurityEventType(eventType string) bool {
+	switch eventType {
+	case PlaybackSecurityEventRapidGrants,
+		PlaybackSecurityEventMultipleIPs,
+		PlaybackSecurityEventRateLimit,
+		PlaybackSecurityEventGrantReplay,
+		PlaybackSecurityEventSessionLimit:
+		return true
+	}
+	return false
+}
+
+// CountsTowardPlaybackGrantBlock meldet, ob ein Ereignis für die automatische Grant-Sperre zählt.
+// Rate-Limit-Überschreitungen zählen nie: sie entstehen auch bei normalem Puffern und Spulen.
+func CountsTowardPlaybackGrantBlock(eventType string, severity string) bool {
+	if eventType == PlaybackSecurityEventRateLimit {
+		return false
+	}
+	return severity == PlaybackSecuritySeverityWarning || severity == PlaybackSecuritySeverityCritical
+}
+
+// IsPlaybackSecuritySeverity meldet, ob severity eine gültige Schwere ist.
+func IsPlaybackSecuritySeverity(severity string) bool {
+	switch severity {
+	case PlaybackSecuritySeverityInfo, PlaybackSecuritySeverityWarning, PlaybackSecuritySeverityCritical:
+		return true
+	}
+	return false
+}
+
+// PlaybackSecurityEvent ist ein gespeichertes Sicherheitsereignis der Wiedergabe.
+type PlaybackSecurityEvent struct {
+	ID        int64           `json:"id"`
+	EventType string          `json:"event_type"`
+	Severity  string          `json:"severity"`
+	UserID    *int64          `json:"user_id"`
+	ClientIP  *string         `json:"client_ip"`
+	EpisodeID *int64          `json:"episode_id"`
+	Action    *string         `json:"action"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PlaybackSecurityEventInput beschreibt ein neues Sicherheitsereignis; Nullwerte werden als
+// NULL gespeichert.
+type PlaybackSecurityEventInput struct {
+	EventType string
+	Severity  string
+	UserID    int64
+	ClientIP  string
+	EpisodeID int64
+	Action    string
+	Details   map[string]any
+}
+
+// PlaybackSecurityEventFilter filtert die Admin-Liste; leere Felder bedeuten kein Filter.
+type PlaybackSecurityEventFilter struct {
+	UserID    int64
+	EventType string
+	Severity  string
+	From      *time.Time
+	To        *time.Time
+	Page      int
+	PerPage   int
+}
+
+// PlaybackGrantBlock sperrt die Stream-Grants eines Nutzers bis BlockedUntil.
+type PlaybackGrantBlock struct {
+	ID                int64      `json:"id"`
+	UserID            int64      `json:"user_id"`
+	Reason            string     `json:"reason"`
+	EventCount        int32      `json:"event_count"`
+	BlockedUntil      time.Time  `json:"blocked_until"`
+	LiftedByAppUserID *int64     `json:"lifted_by_app_user_id"`
+	LiftedAt          *time.Time `json:"lifted_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// PlaybackGrantBlockRule sperrt die Grants eines Nutzers für Duration, sobald er innerhalb von
+// Window mindestens Threshold Ereignisse der Schwere warning oder critical ausgelöst hat.
+// Threshold <= 0 deaktiviert die Regel.
+type PlaybackGrantBlockRule struct {
+	Threshold int
+	Window    time.Duration
+	Duration  time.Duration
+}
+
+// Enabled meldet, ob die Regel aktiv ist.
+func (r PlaybackGrantBlockRule) Enabled() bool {
+	return r.Threshold > 0 && r.Window > 0 && r.Duration > 0
+}
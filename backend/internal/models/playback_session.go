@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PlaybackSession ist eine laufende Wiedergabe eines Nutzers. Sessions liegen in Redis, damit
+// die Concurrency-Limits über alle Backend-Instanzen hinweg gelten.
+type PlaybackSession struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"user_id"`
+	EpisodeID  int64     `json:"episode_id"`
+	ClientIP   string    `json:"client_ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// PlaybackSessionInput beschreibt eine neue Wiedergabe.
+type PlaybackSessionInput struct {
+	UserID    int64
+	EpisodeID int64
+	ClientIP  string
+	UserAgent string
+}
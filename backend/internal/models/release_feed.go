@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Geltungsbereiche der öffentlichen Release-Feeds unter /feeds.
+const (
+	ReleaseFeedScopeAll    = "all"
+	ReleaseFeedScopeAnime  = "anime"
+	ReleaseFeedScopeFansub = "fansub"
+)
+
+// ReleaseFeedScope wählt die Release-Versionen eines Feeds aus. AnimeID bzw. FansubSlug sind
+// nur für den jeweiligen Geltungsbereich gesetzt.
+type ReleaseFeedScope struct {
+	Kind       string
+	AnimeID    int64
+	FansubSlug string
+}
+
+// ReleaseFeedState beschreibt den Stand eines Feeds ohne dessen Einträge. Daraus werden
+// ETag und Last-Modified gebildet, bevor die Einträge geladen werden.
+type ReleaseFeedState struct {
+	Title        string     // Anime-Titel bzw. Gruppenname; leer für den Gesamt-Feed
+	EntryCount   int64      // Anzahl sichtbarer Release-Versionen
+	MaxVersionID int64      // höchste sichtbare Release-Version-ID
+	LastModified *time.Time // jüngste Änderung einer sichtbaren Version oder Variante
+}
+
+// ReleaseFeedEntry ist eine Release-Version im Atom-Feed.
+type ReleaseFeedEntry struct {
+	ReleaseVersionID int64
+	Version          string
+	Title            *string
+	AnimeID          int64
+	AnimeTitle       string
+	EpisodeID        int64
+	EpisodeNumber    string
+	ReleaseDate      *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	FansubGroups     []string
+	Qualities        []string
+}
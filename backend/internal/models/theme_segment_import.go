@@ -0,0 +1,86 @@
+package models
+
+// Status eines Segment-Vorschlags aus dem Jellyfin-Import.
+const (
+	ThemeSegmentProposalStatusNew       = "new"
+	ThemeSegmentProposalStatusDuplicate = "duplicate"
+	ThemeSegmentProposalStatusConflict  = "conflict"
+)
+
+// Gründe, aus denen ein bestehendes Segment mit einem Vorschlag kollidiert.
+const (
+	ThemeSegmentConflictDuplicate = "duplicate"
+	ThemeSegmentConflictOverlap   = "overlap"
+)
+
+// ThemeSegmentImportEpisode ist eine Episode eines Releases (Anime, Gruppe, Version) mit dem
+// Jellyfin-Item ihrer ersten Variante.
+type ThemeSegmentImportEpisode struct {
+	EpisodeNumber    int
+	ReleaseVariantID int64
+	JellyfinItemID   *string
+	DurationSeconds  *int32
+}
+
+// ThemeSegmentObservation ist ein von Jellyfin erkannter Abschnitt einer Episode in Sekunden.
+type ThemeSegmentObservation struct {
+	EpisodeNumber int
+	Kind          string
+	StartSeconds  int32
+	EndSeconds    int32
+}
+
+// ThemeSegmentProposalConflict beschreibt ein bestehendes Segment, das sich mit einem Vorschlag
+// überschneidet.
+type ThemeSegmentProposalConflict struct {
+	SegmentID     int64   `json:"segment_id"`
+	ThemeID       int64   `json:"theme_id"`
+	ThemeTypeName string  `json:"theme_type_name"`
+	StartEpisode  *int    `json:"start_episode"`
+	EndEpisode    *int    `json:"end_episode"`
+	StartTime     *string `json:"start_time"`
+	EndTime       *string `json:"end_time"`
+	Reason        string  `json:"reason"`
+}
+
+// ThemeSegmentProposal fasst Episoden mit übereinstimmenden Zeiten zu einem Segment-Vorschlag
+// mit Episodenbereich zusammen.
+type ThemeSegmentProposal struct {
+	Kind                string                         `json:"kind"`
+	SuggestedThemeID    *int64                         `json:"suggested_theme_id"`
+	StartEpisode        int                            `json:"start_episode"`
+	EndEpisode          int                            `json:"end_episode"`
+	Episodes            []int                          `json:"episodes"`
+	StartTime           string                         `json:"start_time"`
+	EndTime             string                         `json:"end_time"`
+	MaxDeviationSeconds int32                          `json:"max_deviation_seconds"`
+	Status              string                         `json:"status"`
+	Conflicts           []ThemeSegmentProposalConflict `json:"conflicts"`
+}
+
+// ThemeSegmentImportPreview ist die Antwort der Import-Vorschau.
+type ThemeSegmentImportPreview struct {
+	AnimeID              int64                  `json:"anime_id"`
+	FansubGroupID        int64                  `json:"fansub_group_id"`
+	Version              string                 `json:"version"`
+	ToleranceSeconds     int32                  `json:"tolerance_seconds"`
+	EpisodeCount         int                    `json:"episode_count"`
+	EpisodesWithSegments []int                  `json:"episodes_with_segments"`
+	EpisodesWithoutItem  []int                  `json:"episodes_without_item"`
+	Proposals            []ThemeSegmentProposal `json:"proposals"`
+}
+
+// ThemeSegmentImportApplyInput beschreibt eine Übernahme: ReplaceSegmentIDs werden gelöscht und
+// Segments angelegt.
+type ThemeSegmentImportApplyInput struct {
+	FansubGroupID     int64
+	Version           string
+	ReplaceSegmentIDs []int64
+	Segments          []AdminThemeSegmentCreateInput
+}
+
+// ThemeSegmentImportApplyResult ist die Antwort der Übernahme.
+type ThemeSegmentImportApplyResult struct {
+	Created            []AdminThemeSegment `json:"created"`
+	ReplacedSegmentIDs []int64             `json:"replaced_segment_ids"`
+}
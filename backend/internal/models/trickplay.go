@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Status der Trickplay-Erzeugung einer Release-Variante.
+const (
+	TrickplayStatusProcessing = "processing"
+	TrickplayStatusReady      = "ready"
+	TrickplayStatusFailed     = "failed"
+)
+
+// Media-Typen der Trickplay-Assets in media_types.
+const (
+	TrickplayMediaTypeSprite = "trickplay_sprite"
+	TrickplayMediaTypeVTT    = "trickplay_vtt"
+)
+
+// ReleaseVariantTrickplay beschreibt die Vorschau-Sprites einer Release-Variante.
+type ReleaseVariantTrickplay struct {
+	ReleaseVariantID int64      `json:"release_variant_id"`
+	Status           string     `json:"status"`
+	IntervalSeconds  int32      `json:"interval_seconds"`
+	TileWidth        *int32     `json:"tile_width"`
+	TileHeight       *int32     `json:"tile_height"`
+	TileColumns      *int32     `json:"tile_columns"`
+	TileRows         *int32     `json:"tile_rows"`
+	ThumbnailCount   *int32     `json:"thumbnail_count"`
+	SpriteCount      *int32     `json:"sprite_count"`
+	VTTFilePath      *string    `json:"-"`
+	Attempts         int32      `json:"attempts"`
+	ErrorMessage     *string    `json:"error_message"`
+	GeneratedAt      *time.Time `json:"generated_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TrickplayCandidate ist eine Release-Variante mit auflösbarer Quelle, für die Sprites erzeugt
+// werden sollen.
+type TrickplayCandidate struct {
+	ReleaseVariantID int64
+	MediaProvider    string
+	MediaItemID      string
+	StreamURL        *string
+	DurationSeconds  *int32
+}
+
+// TrickplayResult beschreibt erzeugte Sprites und WebVTT-Spur. Pfade sind wie bei anderen
+// media_assets relativ zum Medienverzeichnis mit führendem "/media/".
+type TrickplayResult struct {
+	IntervalSeconds int32
+	TileWidth       int32
+	TileHeight      int32
+	TileColumns     int32
+	TileRows        int32
+	ThumbnailCount  int32
+	SpriteFilePaths []string
+	VTTFilePath     string
+}
+
+// TrickplayOrphanAsset ist ein Trickplay-Asset, dessen Variante gelöscht wurde.
+type TrickplayOrphanAsset struct {
+	MediaAssetID int64
+	FilePath     string
+}
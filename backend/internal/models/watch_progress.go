@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// EpisodeWatchProgress ist der gespeicherte Wiedergabestand eines App-Users für eine Episode
+// (optional gebunden an eine konkrete Release-Version).
+type EpisodeWatchProgress struct {
+	AnimeID          int64      `json:"anime_id"`
+	EpisodeID        int64      `json:"episode_id"`
+	ReleaseVersionID *int64     `json:"release_version_id,omitempty"`
+	PositionSeconds  int32      `json:"position_seconds"`
+	DurationSeconds  *int32     `json:"duration_seconds,omitempty"`
+	Completed        bool       `json:"completed"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	LastWatchedAt    time.Time  `json:"last_watched_at"`
+}
+
+// EpisodeWatchProgressInput enthält einen Fortschritts-Heartbeat des Players.
+type EpisodeWatchProgressInput struct {
+	AppUserID        int64
+	EpisodeID        int64
+	ReleaseVersionID *int64
+	PositionSeconds  int32
+	DurationSeconds  *int32
+	Completed        bool
+}
+
+// ContinueWatchingFilter steuert die Abfrage für /me/continue-watching.
+// WatchlistUserID ist die Legacy-users.id, unter der watchlist_entries geführt werden.
+type ContinueWatchingFilter struct {
+	AppUserID       int64
+	WatchlistUserID int64
+	Limit           int
+}
+
+// ContinueWatchingItem beschreibt die nächste ungesehene Episode eines Anime auf der Watchlist.
+type ContinueWatchingItem struct {
+	AnimeID           int64      `json:"anime_id"`
+	AnimeTitle        string     `json:"anime_title"`
+	CoverImage        *string    `json:"cover_image,omitempty"`
+	EpisodeID         int64      `json:"episode_id"`
+	EpisodeNumber     string     `json:"episode_number"`
+	EpisodeTitle      *string    `json:"episode_title,omitempty"`
+	ReleaseVersionID  *int64     `json:"release_version_id,omitempty"`
+	PositionSeconds   int32      `json:"position_seconds"`
+	DurationSeconds   *int32     `json:"duration_seconds,omitempty"`
+	CompletedEpisodes int        `json:"completed_episodes"`
+	TotalEpisodes     int        `json:"total_episodes"`
+	LastWatchedAt     *time.Time `json:"last_watched_at,omitempty"`
+	AddedAt           time.Time  `json:"added_at"`
+}
@@ -2,19 +2,91 @@ package models
 
 import "time"
 
+// Watchlist-Listenstatus (MAL-artiger Lebenszyklus eines Eintrags).
+const (
+	WatchlistStatusPlanned   = "planned"
+	WatchlistStatusWatching  = "watching"
+	WatchlistStatusOnHold    = "on_hold"
+	WatchlistStatusDropped   = "dropped"
+	WatchlistStatusCompleted = "completed"
+)
+
+// WatchlistStatuses listet alle gültigen Listenstatus in Anzeige-Reihenfolge.
+var WatchlistStatuses = []string{
+	WatchlistStatusWatching,
+	WatchlistStatusPlanned,
+	WatchlistStatusOnHold,
+	WatchlistStatusCompleted,
+	WatchlistStatusDropped,
+}
+
+// IsValidWatchlistStatus prüft, ob status ein bekannter Listenstatus ist.
+func IsValidWatchlistStatus(status string) bool {
+	for _, candidate := range WatchlistStatuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
 type WatchlistFilter struct {
 	Page    int
 	PerPage int
 	UserID  int64
+
+	// Optionale Filter; leere Werte bedeuten "kein Filter".
+	Statuses           []string
+	ScoreMin           *int16
+	ScoreMax           *int16
+	EpisodesWatchedMin *int32
+	StartedFrom        *string // YYYY-MM-DD
+	StartedTo          *string
+	FinishedFrom       *string
+	FinishedTo         *string
+	HasNote            *bool
+
+	// SortBy ist ein Schlüssel aus der Whitelist im Repository, SortOrder "asc" oder "desc".
+	SortBy    string
+	SortOrder string
 }
 
 type WatchlistItem struct {
-	AnimeID     int64     `json:"anime_id"`
-	Title       string    `json:"title"`
-	Type        string    `json:"type"`
-	Status      string    `json:"status"`
-	Year        *int16    `json:"year,omitempty"`
-	CoverImage  *string   `json:"cover_image,omitempty"`
-	MaxEpisodes *int16    `json:"max_episodes,omitempty"`
-	AddedAt     time.Time `json:"added_at"`
+	AnimeID         int64     `json:"anime_id"`
+	Title           string    `json:"title"`
+	Type            string    `json:"type"`
+	Status          string    `json:"status"`
+	Year            *int16    `json:"year,omitempty"`
+	CoverImage      *string   `json:"cover_image,omitempty"`
+	MaxEpisodes     *int16    `json:"max_episodes,omitempty"`
+	AddedAt         time.Time `json:"added_at"`
+	ListStatus      string    `json:"list_status"`
+	Score           *int16    `json:"score,omitempty"`
+	EpisodesWatched int32     `json:"episodes_watched"`
+	StartedOn       *string   `json:"started_on,omitempty"`
+	FinishedOn      *string   `json:"finished_on,omitempty"`
+	Note            *string   `json:"note,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// WatchlistEntryInput enthält die optionalen Listenfelder beim Anlegen eines Eintrags.
+// Nil-Felder übernehmen die Defaults ('planned', keine Wertung, 0 Episoden).
+type WatchlistEntryInput struct {
+	ListStatus      *string
+	Score           *int16
+	EpisodesWatched *int32
+	StartedOn       *string
+	FinishedOn      *string
+	Note            *string
+}
+
+// WatchlistEntryPatchInput beschreibt ein partielles Update eines Listeneintrags.
+// Nur gesetzte Felder werden geschrieben; explizites null löscht optionale Felder.
+type WatchlistEntryPatchInput struct {
+	ListStatus      OptionalString
+	Score           OptionalInt16
+	EpisodesWatched OptionalInt32
+	StartedOn       OptionalString
+	FinishedOn      OptionalString
+	Note            OptionalString
 }
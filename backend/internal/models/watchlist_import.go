@@ -0,0 +1,92 @@
+package models
+
+// Unterstützte Austauschformate für Watchlist-Import und -Export.
+const (
+	WatchlistListFormatMAL     = "mal"
+	WatchlistListFormatAniList = "anilist"
+)
+
+// Ergebnis der Zuordnung eines importierten Eintrags zu einem lokalen Anime.
+const (
+	WatchlistImportMatchSource    = "source"
+	WatchlistImportMatchTitle     = "title"
+	WatchlistImportMatchAmbiguous = "ambiguous"
+	WatchlistImportMatchUnmatched = "unmatched"
+)
+
+// WatchlistImportEntry ist ein normalisierter Eintrag aus einer MAL- oder AniList-Liste.
+// Status und Felder sind bereits auf den lokalen Listen-Lebenszyklus abgebildet.
+type WatchlistImportEntry struct {
+	MALID           *int64   `json:"mal_id,omitempty"`
+	AniListID       *int64   `json:"anilist_id,omitempty"`
+	Title           string   `json:"title"`
+	AltTitles       []string `json:"alt_titles,omitempty"`
+	ListStatus      string   `json:"list_status"`
+	Score           *int16   `json:"score,omitempty"`
+	EpisodesWatched int32    `json:"episodes_watched"`
+	StartedOn       *string  `json:"started_on,omitempty"`
+	FinishedOn      *string  `json:"finished_on,omitempty"`
+	Note            *string  `json:"note,omitempty"`
+}
+
+// WatchlistImportCandidate ist ein lokaler Anime, der zu einem importierten Titel passt.
+type WatchlistImportCandidate struct {
+	AnimeID int64  `json:"anime_id"`
+	Title   string `json:"title"`
+}
+
+// WatchlistImportPreviewItem beschreibt die Zuordnung eines Eintrags in der Import-Vorschau.
+type WatchlistImportPreviewItem struct {
+	Index       int                        `json:"index"`
+	Entry       WatchlistImportEntry       `json:"entry"`
+	Match       string                     `json:"match"`
+	AnimeID     *int64                     `json:"anime_id,omitempty"`
+	AnimeTitle  *string                    `json:"anime_title,omitempty"`
+	Candidates  []WatchlistImportCandidate `json:"candidates,omitempty"`
+	OnWatchlist bool                       `json:"on_watchlist"`
+}
+
+type WatchlistImportPreviewSummary struct {
+	Total       int `json:"total"`
+	Matched     int `json:"matched"`
+	Ambiguous   int `json:"ambiguous"`
+	Unmatched   int `json:"unmatched"`
+	OnWatchlist int `json:"on_watchlist"`
+	Skipped     int `json:"skipped"`
+}
+
+type WatchlistImportPreview struct {
+	Format  string                        `json:"format"`
+	Items   []WatchlistImportPreviewItem  `json:"items"`
+	Summary WatchlistImportPreviewSummary `json:"summary"`
+}
+
+// WatchlistImportApplyEntry ist ein vom Client bestätigter Eintrag (Anime bereits zugeordnet).
+type WatchlistImportApplyEntry struct {
+	AnimeID         int64
+	ListStatus      string
+	Score           *int16
+	EpisodesWatched int32
+	StartedOn       *string
+	FinishedOn      *string
+	Note            *string
+}
+
+type WatchlistImportFailure struct {
+	AnimeID int64  `json:"anime_id"`
+	Message string `json:"message"`
+}
+
+type WatchlistImportResult struct {
+	Created int                      `json:"created"`
+	Updated int                      `json:"updated"`
+	Skipped int                      `json:"skipped"`
+	Failed  []WatchlistImportFailure `json:"failed"`
+}
+
+// WatchlistExportItem ist ein Watchlist-Eintrag mit den externen IDs aus anime_source_links.
+type WatchlistExportItem struct {
+	WatchlistItem
+	MALID     *int64
+	AniListID *int64
+}
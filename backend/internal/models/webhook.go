@@ -0,0 +1,152 @@
+package models
+
+import "time"
+
+// Ereignistypen, die eine Fansub-Gruppe per Webhook abonnieren kann.
+const (
+	WebhookEventReleaseVersionCreated = "release_version.created"
+	WebhookEventReleaseMediaAdded     = "release_version.media_added"
+	WebhookEventProjectNotePublished  = "project_note.published"
+)
+
+// Payload-Formate: json liefert den generischen Umschlag, discord ein Embed für
+// Discord-Webhook-URLs.
+const (
+	WebhookFormatJSON    = "json"
+	WebhookFormatDiscord = "discord"
+)
+
+// Zustände einer Webhook-Zustellung, analog zum Mail-Outbox.
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSending   = "sending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusDead      = "dead"
+)
+
+// WebhookEvents listet alle abonnierbaren Ereignistypen in stabiler Reihenfolge.
+var WebhookEvents = []string{
+	WebhookEventReleaseVersionCreated,
+	WebhookEventReleaseMediaAdded,
+	WebhookEventProjectNotePublished,
+}
+
+// IsWebhookEvent meldet, ob event ein abonnierbarer Ereignistyp ist.
+func IsWebhookEvent(event string) bool {
+	for _, known := range WebhookEvents {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWebhookFormat meldet, ob format ein unterstütztes Payload-Format ist.
+func IsWebhookFormat(format string) bool {
+	return format == WebhookFormatJSON || format == WebhookFormatDiscord
+}
+
+// IsWebhookDeliveryStatus meldet, ob status ein gültiger Zustellstatus ist.
+func IsWebhookDeliveryStatus(status string) bool {
+	switch status {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSending, WebhookDeliveryStatusDelivered, WebhookDeliveryStatusDead:
+		return true
+	}
+	return false
+}
+
+// FansubGroupWebhook ist ein registrierter Webhook einer Gruppe. Secret wird nur direkt nach
+// dem Anlegen bzw. Rotieren ausgeliefert.
+type FansubGroupWebhook struct {
+	ID            int64     `json:"id"`
+	FansubGroupID int64     `json:"fansub_group_id"`
+	URL           string    `json:"url"`
+	Format        string    `json:"format"`
+	Events        []string  `json:"events"`
+	IsActive      bool      `json:"is_active"`
+	Secret        string    `json:"secret,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// FansubGroupWebhookInput beschreibt Anlage oder Änderung eines Webhooks. Bei Updates bleiben
+// leere bzw. nil-Felder unverändert; RotateSecret erzeugt ein neues Secret.
+type FansubGroupWebhookInput struct {
+	URL          string
+	Format       string
+	Events       []string
+	IsActive     *bool
+	RotateSecret bool
+}
+
+// WebhookEvent ist ein fachliches Ereignis, das an alle passenden Webhooks der beteiligten
+// Gruppen verteilt wird. Title, Description und LinkURL speisen das Discord-Embed; Data
+// enthält die strukturierten Felder für das JSON-Format.
+type WebhookEvent struct {
+	Type           string         `json:"event"`
+	FansubGroupIDs []int64        `json:"-"`
+	OccurredAt     time.Time      `json:"occurred_at"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description,omitempty"`
+	LinkURL        string         `json:"link_url,omitempty"`
+	Data           map[string]any `json:"data"`
+}
+
+// WebhookDeliveryMessage ist eine vom Worker beanspruchte Zustellung inklusive Ziel und Secret.
+type WebhookDeliveryMessage struct {
+	ID          int64
+	WebhookID   int64
+	URL         string
+	Format      string
+	Secret      string
+	Event       WebhookEvent
+	Attempts    int
+	MaxAttempts int
+}
+
+// WebhookDelivery ist ein Eintrag im Zustell-Log eines Webhooks.
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	MaxAttempts    int        `json:"max_attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus *int       `json:"response_status"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveryFilter filtert das Zustell-Log; leerer Status = alle.
+type WebhookDeliveryFilter struct {
+	Status  string
+	Page    int
+	PerPage int
+}
+
+// WebhookReleaseContext bündelt die Release-Daten, aus denen Release-Ereignisse gebaut werden.
+type WebhookReleaseContext struct {
+	ReleaseVersionID int64
+	Version          string
+	AnimeID          int64
+	AnimeTitle       string
+	EpisodeID        int64
+	EpisodeNumber    string
+	VideoQuality     string
+	FansubGroupIDs   []int64
+	FansubGroupNames []string
+}
+
+// WebhookProjectNoteContext bündelt die Daten einer veröffentlichten Projektnotiz.
+type WebhookProjectNoteContext struct {
+	NoteID          int64
+	AnimeID         int64
+	AnimeTitle      string
+	FansubGroupID   int64
+	FansubGroupName string
+	Title           string
+	BodyText        string
+}
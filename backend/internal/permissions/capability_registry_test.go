@@ -46,6 +46,7 @@ var allActionCodesWave0 = []string{
 	"fansub_group.invitations.cancel",
 	"fansub_group.invitations.accept", // in action_definitions, KEIN role_capabilities-Eintrag (Pitfall 2)
 	"fansub_group.notes.write",
+	"fansub_group.webhooks.manage",
 	"fansub_group_media.view",
 	"fansub_group_media.upload",
 	"fansub_group_media.update",
@@ -77,6 +78,7 @@ func roleMatrixStubData() map[string][]Action {
 			ActionFansubGroupInvitationsCreate,
 			ActionFansubGroupInvitationsCancel,
 			ActionFansubGroupNotesWrite,
+			ActionFansubGroupWebhooksManage,
 			ActionFansubGroupMediaView,
 			ActionFansubGroupMediaUpload,
 			ActionFansubGroupMediaUpdate,
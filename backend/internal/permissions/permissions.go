@@ -9,7 +9,8 @@ import (
 )
 
 const (
-	ScopeTypeGroup = "group"
+	ScopeTypeGroup    = "group"
+	ScopeTypePlatform = "platform"
 )
 
 type Action string
@@ -27,6 +28,7 @@ const (
 	ActionFansubGroupInvitationsCancel       Action = "fansub_group.invitations.cancel"
 	ActionFansubGroupInvitationsAccept       Action = "fansub_group.invitations.accept"
 	ActionFansubGroupNotesWrite              Action = "fansub_group.notes.write"
+	ActionFansubGroupWebhooksManage          Action = "fansub_group.webhooks.manage"
 	ActionFansubGroupMediaView               Action = "fansub_group_media.view"
 	ActionFansubGroupMediaUpload             Action = "fansub_group_media.upload"
 	ActionFansubGroupMediaUpdate             Action = "fansub_group_media.update"
@@ -40,6 +42,7 @@ const (
 	ActionReleaseVersionMediaDelete          Action = "release_version_media.delete"
 	ActionReleaseVersionMediaDeleteOwn       Action = "release_version_media.delete_own"
 	ActionReleaseVersionNotesWrite           Action = "release_version.notes.write"
+	ActionCommentsModerate                   Action = "comments.moderate"
 )
 
 const (
@@ -87,6 +90,7 @@ var roleMatrix = map[string][]Action{
 		ActionFansubGroupInvitationsCreate,
 		ActionFansubGroupInvitationsCancel,
 		ActionFansubGroupNotesWrite,
+		ActionFansubGroupWebhooksManage,
 		ActionFansubGroupMediaView,
 		ActionFansubGroupMediaUpload,
 		ActionFansubGroupMediaUpdate,
@@ -192,6 +196,7 @@ var allKnownActions = []Action{
 	ActionFansubGroupInvitationsCancel,
 	ActionFansubGroupInvitationsAccept,
 	ActionFansubGroupNotesWrite,
+	ActionFansubGroupWebhooksManage,
 	ActionFansubGroupMediaView,
 	ActionFansubGroupMediaUpload,
 	ActionFansubGroupMediaUpdate,
@@ -205,12 +210,14 @@ var allKnownActions = []Action{
 	ActionReleaseVersionMediaDelete,
 	ActionReleaseVersionMediaDeleteOwn,
 	ActionReleaseVersionNotesWrite,
+	ActionCommentsModerate,
 }
 
 // standaloneActions sind Actions, die in action_definitions existieren,
 // aber keinen role_capabilities-Eintrag haben (keine Rolle gewährt sie direkt).
-// ActionFansubGroupInvitationsAccept wird über CanAcceptInvitation ohne Rollen-Lookup geprüft.
-var standaloneActions = []Action{ActionFansubGroupInvitationsAccept}
+// ActionFansubGroupInvitationsAccept wird über CanAcceptInvitation ohne Rollen-Lookup geprüft,
+// ActionCommentsModerate plattformweit über CanModerateComments.
+var standaloneActions = []Action{ActionFansubGroupInvitationsAccept, ActionCommentsModerate}
 
 // fansubGroupRoleCatalog: wird beim Start via LoadFansubGroupCatalog aus role_definitions geladen (D-12).
 // Leer bei Init — LoadFansubGroupCatalog MUSS vor dem ersten Zugriff aufgerufen werden.
@@ -384,6 +391,27 @@ func (s *Service) CanAcceptInvitation(actor Actor) Result {
 	}
 }
 
+// CanModerateComments prüft die plattformweite Kommentar-Moderation (Queue, Ausblenden, Sperren,
+// Löschen fremder Kommentare). Kommentare hängen an keiner Gruppe, daher gibt es keinen
+// Gruppenrollen-Pfad; berechtigt ist nur platform_admin.
+func (s *Service) CanModerateComments(actor Actor) Result {
+	if actor.AppUserID <= 0 {
+		return denied(ReasonUnauthorized, "aktueller app-user fehlt")
+	}
+	if strings.TrimSpace(actor.Status) == "disabled" {
+		return denied(ReasonDisabledUser, "deaktivierter benutzer")
+	}
+	if !actor.IsPlatformAdmin {
+		return denied(ReasonInsufficientRole, "kommentar-moderation ist platform_admin vorbehalten")
+	}
+	return Result{
+		Allowed:     true,
+		ReasonCode:  ReasonPlatformAdmin,
+		Reason:      "platform_admin darf kommentare moderieren",
+		MatchedRole: RolePlatformAdmin,
+	}
+}
+
 func (s *Service) CanForRelease(ctx context.Context, actor Actor, action Action, releaseID int64) (Result, error) {
 	return s.canForContext(ctx, actor, []Action{action}, func(ctx context.Context) (*Context, error) {
 		return s.resolver.ResolveRelease(ctx, releaseID)
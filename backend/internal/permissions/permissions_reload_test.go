@@ -44,6 +44,7 @@ func fullValidCacheData() map[string][]Action {
 			ActionFansubGroupInvitationsCreate,
 			ActionFansubGroupInvitationsCancel,
 			ActionFansubGroupNotesWrite,
+			ActionFansubGroupWebhooksManage,
 			ActionFansubGroupMediaView,
 			ActionFansubGroupMediaUpload,
 			ActionFansubGroupMediaUpdate,
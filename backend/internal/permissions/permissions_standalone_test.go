@@ -7,10 +7,13 @@ import (
 // TestIsStandaloneAction prüft, ob IsStandaloneAction korrekt zwischen Standalone-
 // und normalen Actions unterscheidet.
 func TestIsStandaloneAction(t *testing.T) {
-	// ActionFansubGroupInvitationsAccept ist die einzige standalone-Action.
+	// ActionFansubGroupInvitationsAccept und ActionCommentsModerate sind standalone-Actions.
 	if !IsStandaloneAction(ActionFansubGroupInvitationsAccept) {
 		t.Errorf("IsStandaloneAction(ActionFansubGroupInvitationsAccept) = false, erwartet true")
 	}
+	if !IsStandaloneAction(ActionCommentsModerate) {
+		t.Errorf("IsStandaloneAction(ActionCommentsModerate) = false, erwartet true")
+	}
 
 	// ActionReleaseView hat einen role_capabilities-Eintrag — keine standalone-Action.
 	if IsStandaloneAction(ActionReleaseView) {
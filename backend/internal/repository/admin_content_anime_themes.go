@@ -449,18 +449,6 @@ func (r *AdminContentRepository) CreateAnimeSegment(ctx context.Context, animeID
 		return nil, ErrNotFound
 	}
 
-	// Sicherstellen dass Theme existiert und zum Anime gehoert
-	var themeAnimeID int64
-	if err := r.db.QueryRow(ctx, `SELECT anime_id FROM themes WHERE id = $1`, input.ThemeID).Scan(&themeAnimeID); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrNotFound
-		}
-		return nil, fmt.Errorf("check theme anime id theme=%d: %w", input.ThemeID, err)
-	}
-	if themeAnimeID != animeID {
-		return nil, ErrNotFound
-	}
-
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("begin create anime segment anime=%d: %w", animeID, err)
@@ -469,9 +457,35 @@ func (r *AdminContentRepository) CreateAnimeSegment(ctx context.Context, animeID
 		_ = tx.Rollback(ctx)
 	}()
 
+	segID, err := r.insertAnimeSegmentTx(ctx, tx, animeID, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit create anime segment anime=%d segment=%d: %w", animeID, segID, err)
+	}
+
+	return loadSegmentByID(ctx, r, segID)
+}
+
+// insertAnimeSegmentTx legt ein Segment innerhalb von tx an und gleicht dessen Playback-Quelle ab.
+// Prueft ob das Theme zum animeID gehoert.
+func (r *AdminContentRepository) insertAnimeSegmentTx(ctx context.Context, tx pgx.Tx, animeID int64, input models.AdminThemeSegmentCreateInput) (int64, error) {
+	// Sicherstellen dass Theme existiert und zum Anime gehoert
+	var themeAnimeID int64
+	if err := tx.QueryRow(ctx, `SELECT anime_id FROM themes WHERE id = $1`, input.ThemeID).Scan(&themeAnimeID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("check theme anime id theme=%d: %w", input.ThemeID, err)
+	}
+	if themeAnimeID != animeID {
+		return 0, ErrNotFound
+	}
+
 	var segID int64
 	encodedSource := encodeThemeSegmentSource(input.SourceType, input.SourceRef, input.SourceLabel, input.SourceJellyfinItemID)
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		INSERT INTO theme_segments (theme_id, fansub_group_id, version, start_episode, end_episode, start_time, end_time, source_jellyfin_item_id, source_type, source_ref, source_label)
 		VALUES ($1, $2, $3, $4, $5, $6::interval, $7::interval, $8, $9, $10, $11)
 		RETURNING id
@@ -481,20 +495,16 @@ func (r *AdminContentRepository) CreateAnimeSegment(ctx context.Context, animeID
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			if pgErr.Code == "23503" || pgErr.Code == "23514" {
-				return nil, ErrConflict
+				return 0, ErrConflict
 			}
 		}
-		return nil, fmt.Errorf("create anime segment anime=%d: %w", animeID, err)
+		return 0, fmt.Errorf("create anime segment anime=%d: %w", animeID, err)
 	}
 
 	if err := r.syncThemeSegmentPlaybackSourceTx(ctx, tx, segID); err != nil {
-		return nil, err
+		return 0, err
 	}
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("commit create anime segment anime=%d segment=%d: %w", animeID, segID, err)
-	}
-
-	return loadSegmentByID(ctx, r, segID)
+	return segID, nil
 }
 
 // UpdateAnimeSegment aktualisiert ein Segment (partieller Patch).
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListThemeSegmentImportEpisodes liefert je numerischer Episode des Releases (Anime, Gruppe,
+// Version) die erste Variante, bevorzugt eine mit Jellyfin-Item.
+func (r *AdminContentRepository) ListThemeSegmentImportEpisodes(ctx context.Context, animeID int64, fansubGroupID int64, version string) ([]models.ThemeSegmentImportEpisode, error) {
+	if animeID <= 0 {
+		return nil, ErrNotFound
+	}
+
+	exists, err := r.animeExists(ctx, animeID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	normalizedVersion := strings.TrimSpace(version)
+	if normalizedVersion == "" {
+		normalizedVersion = "v1"
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (CAST(ep.episode_number AS INTEGER))
+			CAST(ep.episode_number AS INTEGER),
+			rv.id,
+			stream.jellyfin_item_id,
+			rv.duration_seconds
+		FROM release_version_groups rvg
+		JOIN release_versions rev ON rev.id = rvg.release_version_id
+			AND COALESCE(NULLIF(BTRIM(rev.version), ''), 'v1') = $3
+		JOIN fansub_releases fr ON fr.id = rev.release_id
+		JOIN episodes ep ON ep.id = fr.episode_id AND ep.anime_id = $1 AND ep.episode_number ~ '^[0-9]+$'
+		JOIN release_variants rv ON rv.release_version_id = rev.id
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(
+				NULLIF(BTRIM(rs.jellyfin_item_id), ''),
+				CASE WHEN ss.provider_type = 'jellyfin' THEN NULLIF(BTRIM(ss.external_id), '') END
+			) AS jellyfin_item_id
+			FROM release_streams rs
+			LEFT JOIN stream_sources ss ON ss.id = rs.stream_source_id
+			WHERE rs.variant_id = rv.id
+			ORDER BY rs.id ASC
+			LIMIT 1
+		) stream ON TRUE
+		WHERE rvg.fansub_group_id = $2
+		ORDER BY CAST(ep.episode_number AS INTEGER) ASC, stream.jellyfin_item_id IS NOT NULL DESC, rv.id ASC
+	`, animeID, fansubGroupID, normalizedVersion)
+	if err != nil {
+		return nil, fmt.Errorf("list theme segment import episodes anime=%d group=%d version=%q: %w", animeID, fansubGroupID, version, err)
+	}
+	defer rows.Close()
+
+	episodes := make([]models.ThemeSegmentImportEpisode, 0)
+	for rows.Next() {
+		var episode models.ThemeSegmentImportEpisode
+		if err := rows.Scan(&episode.EpisodeNumber, &episode.ReleaseVariantID, &episode.JellyfinItemID, &episode.DurationSeconds); err != nil {
+			return nil, fmt.Errorf("scan theme segment import episode anime=%d: %w", animeID, err)
+		}
+		episodes = append(episodes, episode)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate theme segment import episodes anime=%d: %w", animeID, err)
+	}
+
+	return episodes, nil
+}
+
+// ApplyThemeSegmentImport ersetzt input.ReplaceSegmentIDs durch input.Segments in einer
+// Transaktion. Vorher werden die Themes und Segmente des Anime gesperrt, damit parallel weder
+// Segmente angelegt noch verschoben werden. check erhält die gesperrten Segmente von Gruppe und
+// Version ohne die ersetzten; liefert check oder ein Schreibzugriff einen Fehler, wird nichts
+// gespeichert.
+func (r *AdminContentRepository) ApplyThemeSegmentImport(
+	ctx context.Context,
+	animeID int64,
+	input models.ThemeSegmentImportApplyInput,
+	check func(current []models.AdminThemeSegment) error,
+) ([]models.AdminThemeSegment, error) {
+	if animeID <= 0 {
+		return nil, ErrNotFound
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin theme segment import anime=%d: %w", animeID, err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Neue Segmente brauchen einen FK-Lock auf ihr Theme; FOR UPDATE auf den Themes blockiert sie.
+	if _, err := tx.Exec(ctx, `SELECT id FROM themes WHERE anime_id = $1 ORDER BY id FOR UPDATE`, animeID); err != nil {
+		return nil, fmt.Errorf("lock themes anime=%d: %w", animeID, err)
+	}
+	current, err := lockThemeSegmentImportSegmentsTx(ctx, tx, animeID, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(current); err != nil {
+		return nil, err
+	}
+
+	if len(input.ReplaceSegmentIDs) > 0 {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM theme_segments
+			WHERE id = ANY($1)
+				AND theme_id IN (SELECT id FROM themes WHERE anime_id = $2)
+		`, input.ReplaceSegmentIDs, animeID); err != nil {
+			return nil, fmt.Errorf("delete replaced theme segments anime=%d: %w", animeID, err)
+		}
+	}
+
+	segmentIDs := make([]int64, 0, len(input.Segments))
+	for _, segment := range input.Segments {
+		segmentID, err := r.insertAnimeSegmentTx(ctx, tx, animeID, segment)
+		if err != nil {
+			return nil, err
+		}
+		segmentIDs = append(segmentIDs, segmentID)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit theme segment import anime=%d: %w", animeID, err)
+	}
+
+	created := make([]models.AdminThemeSegment, 0, len(segmentIDs))
+	for _, segmentID := range segmentIDs {
+		segment, err := loadSegmentByID(ctx, r, segmentID)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, *segment)
+	}
+	return created, nil
+}
+
+// lockThemeSegmentImportSegmentsTx sperrt alle Segmente des Anime, auch die anderer Gruppen,
+// damit keines parallel in Gruppe und Version verschoben wird. Liefert die Segmente von Gruppe und
+// Version ohne input.ReplaceSegmentIDs.
+func lockThemeSegmentImportSegmentsTx(ctx context.Context, tx pgx.Tx, animeID int64, input models.ThemeSegmentImportApplyInput) ([]models.AdminThemeSegment, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT
+			ts.id,
+			ts.theme_id,
+			tt.name,
+			ts.fansub_group_id,
+			ts.version,
+			ts.start_episode,
+			ts.end_episode,
+			ts.start_time::text,
+			ts.end_time::text
+		FROM theme_segments ts
+		JOIN themes t ON t.id = ts.theme_id
+		JOIN theme_types tt ON tt.id = t.theme_type_id
+		WHERE t.anime_id = $1
+		ORDER BY ts.id
+		FOR UPDATE OF ts
+	`, animeID)
+	if err != nil {
+		return nil, fmt.Errorf("lock theme segments anime=%d: %w", animeID, err)
+	}
+	defer rows.Close()
+
+	replaced := make(map[int64]bool, len(input.ReplaceSegmentIDs))
+	for _, segmentID := range input.ReplaceSegmentIDs {
+		replaced[segmentID] = true
+	}
+	segments := make([]models.AdminThemeSegment, 0)
+	for rows.Next() {
+		var segment models.AdminThemeSegment
+		if err := rows.Scan(
+			&segment.ID,
+			&segment.ThemeID,
+			&segment.ThemeTypeName,
+			&segment.FansubGroupID,
+			&segment.Version,
+			&segment.StartEpisode,
+			&segment.EndEpisode,
+			&segment.StartTime,
+			&segment.EndTime,
+		); err != nil {
+			return nil, fmt.Errorf("scan locked theme segment anime=%d: %w", animeID, err)
+		}
+		if replaced[segment.ID] || segment.FansubGroupID == nil || *segment.FansubGroupID != input.FansubGroupID || segment.Version != input.Version {
+			continue
+		}
+		segment.AnimeID = animeID
+		segments = append(segments, segment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate locked theme segments anime=%d: %w", animeID, err)
+	}
+	return segments, nil
+}
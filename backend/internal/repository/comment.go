@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"team4s.v3/backend/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,100 +19,523 @@ func NewCommentRepository(db *pgxpool.Pool) *CommentRepository {
 	return &CommentRepository{db: db}
 }
 
-func (r *CommentRepository) ListByAnimeID(
+// CommentThreadError beschreibt eine ungültige Antwort (Elternkommentar fehlt, ist gelöscht
+// oder die maximale Tiefe ist erreicht). Message ist für die API-Antwort gedacht.
+type CommentThreadError struct {
+	Message string
+}
+
+func (e *CommentThreadError) Error() string {
+	return "comment thread: " + e.Message
+}
+
+func (e *CommentThreadError) Unwrap() error {
+	return ErrValidation
+}
+
+const commentColumns = `
+	c.id, c.target_type, c.target_id, c.anime_id, c.parent_id, c.root_id, c.depth, c.author_name, c.author_user_id,
+	c.content, c.created_at, c.edited_at, c.deleted_at IS NOT NULL, c.moderation_status,
+	` + commentMentionSlugsSQL + `
+`
+
+// commentThreadVisibleSQL blendet gelöschte oder moderierte Top-Level-Kommentare aus, unter
+// denen keine sichtbare Antwort mehr hängt.
+const commentThreadVisibleSQL = `(
+	(c.deleted_at IS NULL AND c.moderation_status = 'visible')
+	OR EXISTS (
+		SELECT 1 FROM comments r
+		WHERE r.root_id = c.id AND r.deleted_at IS NULL AND r.moderation_status = 'visible'
+	)
+)`
+
+// ListByTarget paginiert über die Threads eines Ziels (Top-Level-Kommentare, neueste zuerst)
+// und lädt zu jedem Thread alle Antworten in Gesprächsreihenfolge.
+func (r *CommentRepository) ListByTarget(
 	ctx context.Context,
-	animeID int64,
+	target models.CommentTarget,
 	filter models.CommentFilter,
 ) ([]models.CommentListItem, int64, error) {
-	exists, err := r.animeExists(ctx, animeID)
-	if err != nil {
+	if _, err := r.resolveTarget(ctx, target); err != nil {
 		return nil, 0, err
 	}
-	if !exists {
-		return nil, 0, ErrNotFound
-	}
 
 	var total int64
-	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM comments WHERE anime_id = $1`, animeID).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("count comments for anime %d: %w", animeID, err)
+	if err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM comments c
+		WHERE c.target_type = $1
+		  AND c.target_id = $2
+		  AND c.parent_id IS NULL
+		  AND `+commentThreadVisibleSQL, target.Type, target.ID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count comment threads for %s %d: %w", target.Type, target.ID, err)
 	}
 
 	offset := (filter.Page - 1) * filter.PerPage
-	rows, err := r.db.Query(ctx, `
-		SELECT id, anime_id, author_name, content, created_at
-		FROM comments
-		WHERE anime_id = $1
-		ORDER BY created_at DESC, id DESC
-		LIMIT $2 OFFSET $3
-	`, animeID, filter.PerPage, offset)
+	roots, err := r.queryComments(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments c
+		WHERE c.target_type = $1
+		  AND c.target_id = $2
+		  AND c.parent_id IS NULL
+		  AND `+commentThreadVisibleSQL+`
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $3 OFFSET $4
+	`, target.Type, target.ID, filter.PerPage, offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("query comments for anime %d: %w", animeID, err)
+		return nil, 0, fmt.Errorf("query comment threads for %s %d: %w", target.Type, target.ID, err)
+	}
+	if len(roots) == 0 {
+		return roots, total, nil
 	}
-	defer rows.Close()
 
-	items := make([]models.CommentListItem, 0, filter.PerPage)
-	for rows.Next() {
-		var item models.CommentListItem
-		if err := rows.Scan(
-			&item.ID,
-			&item.AnimeID,
-			&item.AuthorName,
-			&item.Content,
-			&item.CreatedAt,
-		); err != nil {
-			return nil, 0, fmt.Errorf("scan comment row: %w", err)
+	rootIDs := make([]int64, 0, len(roots))
+	for _, root := range roots {
+		rootIDs = append(rootIDs, root.ID)
+	}
+	replies, err := r.queryComments(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments c
+		WHERE c.root_id = ANY($1)
+		ORDER BY c.created_at ASC, c.id ASC
+	`, rootIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query comment replies for %s %d: %w", target.Type, target.ID, err)
+	}
+
+	return buildCommentThreads(roots, replies), total, nil
+}
+
+// CreateForTarget legt einen Kommentar oder eine Antwort an einem Ziel an. Fehlt das Ziel
+// (oder ist der zugehörige Anime deaktiviert), wird ErrNotFound zurückgegeben.
+func (r *CommentRepository) CreateForTarget(
+	ctx context.Context,
+	target models.CommentTarget,
+	input models.CommentCreateInput,
+) (*models.CommentListItem, error) {
+	animeID, err := r.resolveTarget(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var rootID *int64
+	depth := int16(0)
+	if input.ParentID != nil {
+		parent, err := r.GetByID(ctx, *input.ParentID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, &CommentThreadError{Message: "parent kommentar nicht gefunden"}
 		}
+		if err != nil {
+			return nil, err
+		}
+		if rootID, depth, err = resolveCommentReplyPosition(target, parent); err != nil {
+			return nil, err
+		}
+	}
 
-		items = append(items, item)
+	var authorUserID *int64
+	if input.AuthorUserID > 0 {
+		authorUserID = &input.AuthorUserID
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("iterate comment rows: %w", err)
+	moderationStatus := models.CommentModerationVisible
+	holdFlags := input.HoldFlags
+	if len(holdFlags) > 0 {
+		moderationStatus = models.CommentModerationPending
+	} else {
+		holdFlags = []string{}
 	}
 
-	return items, total, nil
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin comment insert tx for %s %d: %w", target.Type, target.ID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	item, err := scanComment(tx.QueryRow(ctx, `
+		INSERT INTO comments (
+			target_type, target_id, anime_id, author_name, author_user_id, content,
+			parent_id, root_id, depth, moderation_status, moderation_flags
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, target_type, target_id, anime_id, parent_id, root_id, depth, author_name,
+			author_user_id, content, created_at, edited_at, deleted_at IS NOT NULL, moderation_status,
+			'{}'::text[]
+	`, target.Type, target.ID, animeID, input.AuthorName, authorUserID, input.Content,
+		input.ParentID, rootID, depth, moderationStatus, holdFlags))
+	if err != nil {
+		return nil, fmt.Errorf("insert comment for %s %d: %w", target.Type, target.ID, err)
+	}
+
+	addedMentions, err := replaceCommentMentions(ctx, tx, item.ID, input.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit comment insert tx for %s %d: %w", target.Type, target.ID, err)
+	}
+	item.MentionSlugs = commentMentionSlugs(input.Mentions)
+	item.AddedMentions = addedMentions
+
+	return item, nil
 }
 
-func (r *CommentRepository) CreateByAnimeID(
+// GetByID lädt einen einzelnen Kommentar ungefiltert (auch gelöschte, Inhalt unmaskiert).
+func (r *CommentRepository) GetByID(ctx context.Context, commentID int64) (*models.CommentListItem, error) {
+	item, err := r.scanOne(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments c
+		WHERE c.id = $1
+	`, commentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query comment %d: %w", commentID, err)
+	}
+
+	return item, nil
+}
+
+// UpdateContent ersetzt den Inhalt eines Kommentars durch seinen Autor, legt den bisherigen
+// Inhalt als Revision ab und ersetzt die @mentions. Mit holdFlags wird ein sichtbarer Kommentar
+// in derselben Transaktion zur Prüfung zurückgehalten. ErrForbidden bei fremden Kommentaren,
+// ErrConflict bei gelöschten.
+func (r *CommentRepository) UpdateContent(
 	ctx context.Context,
-	animeID int64,
-	input models.CommentCreateInput,
+	commentID int64,
+	editorUserID int64,
+	content string,
+	mentions []models.CommentMentionTarget,
+	holdFlags []string,
 ) (*models.CommentListItem, error) {
-	exists, err := r.animeExists(ctx, animeID)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin comment update tx %d: %w", commentID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var authorUserID *int64
+	var currentContent string
+	var deleted bool
+	var addedMentions []models.CommentMentionTarget
+	if err := tx.QueryRow(ctx, `
+		SELECT author_user_id, content, deleted_at IS NOT NULL
+		FROM comments
+		WHERE id = $1
+		FOR UPDATE
+	`, commentID).Scan(&authorUserID, &currentContent, &deleted); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("lock comment %d: %w", commentID, err)
+	}
+	if deleted {
+		return nil, ErrConflict
+	}
+	if !isCommentAuthor(authorUserID, editorUserID) {
+		return nil, ErrForbidden
+	}
+
+	if currentContent != content {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO comment_revisions (comment_id, revision, content, edited_by_user_id)
+			SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3
+			FROM comment_revisions
+			WHERE comment_id = $1
+		`, commentID, currentContent, editorUserID); err != nil {
+			return nil, fmt.Errorf("insert comment revision %d: %w", commentID, err)
+		}
+
+		if holdFlags == nil {
+			holdFlags = []string{}
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE comments
+			SET content = $2,
+				moderation_status = CASE
+					WHEN cardinality($3::text[]) > 0 AND moderation_status = 'visible' THEN 'pending'
+					ELSE moderation_status
+				END,
+				moderation_flags = CASE
+					WHEN cardinality($3::text[]) > 0 AND moderation_status = 'visible'
+						THEN ARRAY(SELECT DISTINCT unnest(moderation_flags || $3::text[]))
+					ELSE moderation_flags
+				END,
+				edited_at = NOW(),
+				updated_at = NOW()
+			WHERE id = $1
+		`, commentID, content, holdFlags); err != nil {
+			return nil, fmt.Errorf("update comment %d: %w", commentID, err)
+		}
+
+		if addedMentions, err = replaceCommentMentions(ctx, tx, commentID, mentions); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit comment update tx %d: %w", commentID, err)
+	}
+
+	item, err := r.GetByID(ctx, commentID)
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
+	item.AddedMentions = addedMentions
+
+	return item, nil
+}
+
+// SoftDelete markiert einen Kommentar als gelöscht; Antworten bleiben erhalten und der
+// Kommentar erscheint im Thread als Platzhalter. Ohne allowAnyAuthor darf nur der Autor löschen.
+func (r *CommentRepository) SoftDelete(
+	ctx context.Context,
+	commentID int64,
+	actorUserID int64,
+	allowAnyAuthor bool,
+) error {
+	item, err := r.GetByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if item.IsDeleted {
+		return ErrNotFound
+	}
+	if !allowAnyAuthor && !isCommentAuthor(item.AuthorUserID, actorUserID) {
+		return ErrForbidden
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE comments
+		SET deleted_at = NOW(),
+			deleted_by_user_id = $2,
+			updated_at = NOW()
+		WHERE id = $1
+		  AND deleted_at IS NULL
+	`, commentID, actorUserID)
+	if err != nil {
+		return fmt.Errorf("soft delete comment %d: %w", commentID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListRevisions liefert die früheren Stände eines Kommentars (älteste zuerst).
+// Für gelöschte oder moderierte Kommentare wird kein Verlauf herausgegeben.
+func (r *CommentRepository) ListRevisions(ctx context.Context, commentID int64) ([]models.CommentRevision, error) {
+	item, err := r.GetByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if isCommentRemoved(item) {
 		return nil, ErrNotFound
 	}
 
+	rows, err := r.db.Query(ctx, `
+		SELECT revision, content, created_at
+		FROM comment_revisions
+		WHERE comment_id = $1
+		ORDER BY revision ASC
+	`, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("query comment revisions %d: %w", commentID, err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.CommentRevision, 0)
+	for rows.Next() {
+		var revision models.CommentRevision
+		if err := rows.Scan(&revision.Revision, &revision.Content, &revision.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan comment revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate comment revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (r *CommentRepository) queryComments(ctx context.Context, sql string, args ...any) ([]models.CommentListItem, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.CommentListItem, 0)
+	for rows.Next() {
+		item, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan comment row: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate comment rows: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *CommentRepository) scanOne(ctx context.Context, sql string, args ...any) (*models.CommentListItem, error) {
+	return scanComment(r.db.QueryRow(ctx, sql, args...))
+}
+
+func scanComment(row pgx.Row) (*models.CommentListItem, error) {
 	var item models.CommentListItem
-	if err := r.db.QueryRow(ctx, `
-		INSERT INTO comments (anime_id, author_name, content)
-		VALUES ($1, $2, $3)
-		RETURNING id, anime_id, author_name, content, created_at
-	`, animeID, input.AuthorName, input.Content).Scan(
+	if err := row.Scan(
 		&item.ID,
+		&item.TargetType,
+		&item.TargetID,
 		&item.AnimeID,
+		&item.ParentID,
+		&item.RootID,
+		&item.Depth,
 		&item.AuthorName,
+		&item.AuthorUserID,
 		&item.Content,
 		&item.CreatedAt,
+		&item.EditedAt,
+		&item.IsDeleted,
+		&item.ModerationStatus,
+		&item.MentionSlugs,
 	); err != nil {
-		return nil, fmt.Errorf("insert comment for anime %d: %w", animeID, err)
+		return nil, err
 	}
+	item.Replies = []models.CommentListItem{}
 
 	return &item, nil
 }
 
-func (r *CommentRepository) animeExists(ctx context.Context, animeID int64) (bool, error) {
-	var exists bool
-	if err := r.db.QueryRow(
-		ctx,
-		`SELECT EXISTS(SELECT 1 FROM anime WHERE id = $1 AND status <> 'disabled')`,
-		animeID,
-	).Scan(&exists); err != nil {
-		return false, fmt.Errorf("check anime existence %d: %w", animeID, err)
+// commentTargetLookupSQL prüft je Zieltyp, ob das Ziel existiert, und liefert die Anime-ID
+// als Kontext. Ziele unter deaktivierten Anime gelten als nicht vorhanden.
+var commentTargetLookupSQL = map[string]string{
+	models.CommentTargetAnime: `
+		SELECT a.id
+		FROM anime a
+		WHERE a.id = $1
+		  AND a.status <> 'disabled'`,
+	models.CommentTargetEpisode: `
+		SELECT e.anime_id
+		FROM episodes e
+		JOIN anime a ON a.id = e.anime_id
+		WHERE e.id = $1
+		  AND a.status <> 'disabled'`,
+	models.CommentTargetReleaseVersion: `
+		SELECT e.anime_id
+		FROM release_versions rev
+		JOIN fansub_releases fr ON fr.id = rev.release_id
+		JOIN episodes e ON e.id = fr.episode_id
+		JOIN anime a ON a.id = e.anime_id
+		WHERE rev.id = $1
+		  AND a.status <> 'disabled'`,
+	models.CommentTargetFansubGroup: `
+		SELECT NULL::bigint
+		FROM fansub_groups fg
+		WHERE fg.id = $1`,
+}
+
+// resolveTarget prüft das Kommentarziel und liefert die zugehörige Anime-ID
+// (nil bei Fansubgruppen). ErrNotFound, wenn das Ziel fehlt.
+func (r *CommentRepository) resolveTarget(ctx context.Context, target models.CommentTarget) (*int64, error) {
+	query, ok := commentTargetLookupSQL[target.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown comment target type %q", target.Type)
+	}
+
+	var animeID *int64
+	if err := r.db.QueryRow(ctx, query, target.ID).Scan(&animeID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("check comment target %s %d: %w", target.Type, target.ID, err)
+	}
+
+	return animeID, nil
+}
+
+// resolveCommentReplyPosition prüft den Elternkommentar und liefert root_id und Tiefe der Antwort.
+func resolveCommentReplyPosition(target models.CommentTarget, parent *models.CommentListItem) (*int64, int16, error) {
+	if parent.TargetType != target.Type || parent.TargetID != target.ID {
+		return nil, 0, &CommentThreadError{Message: "parent kommentar nicht gefunden"}
+	}
+	if parent.IsDeleted {
+		return nil, 0, &CommentThreadError{Message: "auf gelöschte kommentare kann nicht geantwortet werden"}
+	}
+	if parent.ModerationStatus != models.CommentModerationVisible {
+		return nil, 0, &CommentThreadError{Message: "auf diesen kommentar kann nicht geantwortet werden"}
+	}
+	if parent.Depth >= models.MaxCommentDepth {
+		return nil, 0, &CommentThreadError{Message: "maximale antworttiefe erreicht"}
+	}
+
+	rootID := parent.ID
+	if parent.RootID != nil {
+		rootID = *parent.RootID
+	}
+
+	return &rootID, parent.Depth + 1, nil
+}
+
+func isCommentAuthor(authorUserID *int64, userID int64) bool {
+	return authorUserID != nil && userID > 0 && *authorUserID == userID
+}
+
+// isCommentRemoved meldet gelöschte sowie zurückgehaltene oder ausgeblendete Kommentare.
+func isCommentRemoved(item *models.CommentListItem) bool {
+	return item.IsDeleted || item.ModerationStatus != models.CommentModerationVisible
+}
+
+// buildCommentThreads hängt die Antworten (chronologisch sortiert) an ihre Eltern, entfernt
+// gelöschte oder moderierte Kommentare ohne sichtbare Antworten und maskiert die übrigen als
+// Platzhalter.
+func buildCommentThreads(roots []models.CommentListItem, replies []models.CommentListItem) []models.CommentListItem {
+	children := make(map[int64][]models.CommentListItem, len(replies))
+	for _, reply := range replies {
+		if reply.ParentID == nil {
+			continue
+		}
+		children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+	}
+
+	var attach func(item models.CommentListItem) (models.CommentListItem, bool)
+	attach = func(item models.CommentListItem) (models.CommentListItem, bool) {
+		item.Replies = make([]models.CommentListItem, 0, len(children[item.ID]))
+		item.ReplyCount = 0
+		for _, child := range children[item.ID] {
+			built, visible := attach(child)
+			if !visible {
+				continue
+			}
+			item.Replies = append(item.Replies, built)
+			item.ReplyCount += built.ReplyCount
+			if !isCommentRemoved(&built) {
+				item.ReplyCount++
+			}
+		}
+		if isCommentRemoved(&item) {
+			if len(item.Replies) == 0 {
+				return item, false
+			}
+			item.AuthorName = ""
+			item.Content = ""
+			item.EditedAt = nil
+		}
+		return item, true
+	}
+
+	threads := make([]models.CommentListItem, 0, len(roots))
+	for _, root := range roots {
+		if built, visible := attach(root); visible {
+			threads = append(threads, built)
+		}
 	}
 
-	return exists, nil
+	return threads
 }
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// commentMentionSlugsSQL lädt die aufgelösten Slugs eines Kommentars für die Verlinkung.
+const commentMentionSlugsSQL = `COALESCE((
+	SELECT array_agg(cm.member_slug ORDER BY cm.id)
+	FROM comment_mentions cm
+	WHERE cm.comment_id = c.id
+), '{}'::text[])`
+
+// ResolveMentions löst @slugs gegen die Member-Profile auf (gleiche Slug-Regel wie
+// /members/:slug; bei Kollisionen gewinnt die kleinste Member-ID). Unbekannte Slugs
+// werden ausgelassen.
+func (r *CommentRepository) ResolveMentions(ctx context.Context, slugs []string) ([]models.CommentMentionTarget, error) {
+	if len(slugs) == 0 {
+		return []models.CommentMentionTarget{}, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (candidates.db_slug)
+			candidates.id,
+			candidates.db_slug,
+			candidates.app_user_id
+		FROM (
+			SELECT
+				m.id,
+				COALESCE(claim_user.app_user_id, legacy_user.id) AS app_user_id,
+				LOWER(TRIM(BOTH '-' FROM REGEXP_REPLACE(TRIM(m.nickname), '[^a-z0-9]+', '-', 'gi'))) AS db_slug
+			FROM members m
+			LEFT JOIN LATERAL (
+				SELECT mc.app_user_id
+				FROM member_claims mc
+				WHERE mc.member_id = m.id
+				  AND mc.claim_status = 'verified'
+				ORDER BY mc.verified_at DESC NULLS LAST, mc.id DESC
+				LIMIT 1
+			) claim_user ON true
+			LEFT JOIN app_users legacy_user ON legacy_user.legacy_user_id = m.user_id
+		) candidates
+		WHERE candidates.db_slug = ANY($1)
+		ORDER BY candidates.db_slug, candidates.id ASC
+	`, slugs)
+	if err != nil {
+		return nil, fmt.Errorf("resolve comment mentions: %w", err)
+	}
+	defer rows.Close()
+
+	bySlug := make(map[string]models.CommentMentionTarget, len(slugs))
+	for rows.Next() {
+		var mention models.CommentMentionTarget
+		if err := rows.Scan(&mention.MemberID, &mention.MemberSlug, &mention.AppUserID); err != nil {
+			return nil, fmt.Errorf("scan comment mention: %w", err)
+		}
+		bySlug[mention.MemberSlug] = mention
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate comment mentions: %w", err)
+	}
+
+	// Reihenfolge wie im Kommentar beibehalten.
+	mentions := make([]models.CommentMentionTarget, 0, len(bySlug))
+	for _, slug := range slugs {
+		if mention, ok := bySlug[slug]; ok {
+			mentions = append(mentions, mention)
+		}
+	}
+
+	return mentions, nil
+}
+
+// ListMentionsForUser listet sichtbare Kommentare, in denen der Account erwähnt wurde
+// (neueste zuerst). Eigene Kommentare (Autor = legacyUserID) werden ausgelassen.
+func (r *CommentRepository) ListMentionsForUser(
+	ctx context.Context,
+	appUserID int64,
+	legacyUserID int64,
+	filter models.CommentFilter,
+) ([]models.CommentMentionItem, int64, error) {
+	const mentionScope = `
+		FROM comment_mentions m
+		JOIN comments c ON c.id = m.comment_id
+		WHERE m.mentioned_app_user_id = $1
+		  AND c.deleted_at IS NULL
+		  AND c.moderation_status = 'visible'
+		  AND (c.author_user_id IS NULL OR c.author_user_id <> $2)`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+mentionScope, appUserID, legacyUserID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count comment mentions for app user %d: %w", appUserID, err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			m.id, c.id, c.target_type, c.target_id, c.anime_id, c.author_name, c.content,
+			m.member_slug, m.created_at, `+commentMentionSlugsSQL+`
+		`+mentionScope+`
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $3 OFFSET $4
+	`, appUserID, legacyUserID, filter.PerPage, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query comment mentions for app user %d: %w", appUserID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.CommentMentionItem, 0)
+	for rows.Next() {
+		var item models.CommentMentionItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.CommentID,
+			&item.TargetType,
+			&item.TargetID,
+			&item.AnimeID,
+			&item.AuthorName,
+			&item.Content,
+			&item.MemberSlug,
+			&item.CreatedAt,
+			&item.MentionSlugs,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan comment mention row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate comment mention rows: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// replaceCommentMentions ersetzt die gespeicherten Erwähnungen eines Kommentars und liefert
+// die neu hinzugekommenen. Bereits vorhandene Einträge behalten ihr created_at, damit
+// Bearbeitungen keine erneuten Erwähnungen erzeugen.
+func replaceCommentMentions(
+	ctx context.Context,
+	tx pgx.Tx,
+	commentID int64,
+	mentions []models.CommentMentionTarget,
+) ([]models.CommentMentionTarget, error) {
+	memberIDs := make([]int64, 0, len(mentions))
+	for _, mention := range mentions {
+		memberIDs = append(memberIDs, mention.MemberID)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM comment_mentions
+		WHERE comment_id = $1
+		  AND NOT (member_id = ANY($2))
+	`, commentID, memberIDs); err != nil {
+		return nil, fmt.Errorf("delete stale comment mentions %d: %w", commentID, err)
+	}
+
+	added := make([]models.CommentMentionTarget, 0, len(mentions))
+	for _, mention := range mentions {
+		var inserted bool
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO comment_mentions (comment_id, member_id, member_slug, mentioned_app_user_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (comment_id, member_id) DO UPDATE
+			SET member_slug = EXCLUDED.member_slug,
+				mentioned_app_user_id = EXCLUDED.mentioned_app_user_id
+			RETURNING (xmax = 0)
+		`, commentID, mention.MemberID, mention.MemberSlug, mention.AppUserID).Scan(&inserted); err != nil {
+			return nil, fmt.Errorf("insert comment mention %d (member_id=%d): %w", commentID, mention.MemberID, err)
+		}
+		if inserted {
+			added = append(added, mention)
+		}
+	}
+
+	return added, nil
+}
+
+func commentMentionSlugs(mentions []models.CommentMentionTarget) []string {
+	slugs := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		slugs = append(slugs, mention.MemberSlug)
+	}
+	return slugs
+}
@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CommentModerationError beschreibt eine unzulässige Meldung oder Moderationsaktion.
+// Message ist für die API-Antwort gedacht.
+type CommentModerationError struct {
+	Message string
+}
+
+func (e *CommentModerationError) Error() string {
+	return "comment moderation: " + e.Message
+}
+
+func (e *CommentModerationError) Unwrap() error {
+	return ErrValidation
+}
+
+// IsUserBanned meldet, ob für den Legacy-Nutzer eine aktive Kommentarsperre besteht.
+func (r *CommentRepository) IsUserBanned(ctx context.Context, userID int64) (bool, error) {
+	var banned bool
+	if err := r.db.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM comment_user_bans
+			WHERE user_id = $1
+			  AND (expires_at IS NULL OR expires_at > NOW())
+		)
+	`, userID).Scan(&banned); err != nil {
+		return false, fmt.Errorf("check comment ban for user %d: %w", userID, err)
+	}
+
+	return banned, nil
+}
+
+// LoadAuthorSignals ermittelt die Vergleichswerte der Spam-Heuristiken: identische Kommentare
+// des Autors innerhalb von repeatWindow (0 = nicht zählen) und das Anlagedatum des Kontos.
+func (r *CommentRepository) LoadAuthorSignals(
+	ctx context.Context,
+	userID int64,
+	content string,
+	repeatWindow time.Duration,
+) (models.CommentAuthorSignals, error) {
+	var signals models.CommentAuthorSignals
+	if userID <= 0 {
+		return signals, nil
+	}
+
+	if repeatWindow > 0 {
+		if err := r.db.QueryRow(ctx, `
+			SELECT COUNT(*)
+			FROM comments
+			WHERE author_user_id = $1
+			  AND deleted_at IS NULL
+			  AND created_at >= NOW() - make_interval(secs => $2)
+			  AND lower(btrim(content)) = lower(btrim($3))
+		`, userID, repeatWindow.Seconds(), content).Scan(&signals.RecentDuplicates); err != nil {
+			return signals, fmt.Errorf("count repeated comments for user %d: %w", userID, err)
+		}
+	}
+
+	var createdAt time.Time
+	err := r.db.QueryRow(ctx, `SELECT created_at FROM users WHERE id = $1`, userID).Scan(&createdAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return signals, fmt.Errorf("load account age for user %d: %w", userID, err)
+	default:
+		signals.AccountCreatedAt = &createdAt
+	}
+
+	return signals, nil
+}
+
+// CreateReport legt eine Nutzermeldung an. Jeder Nutzer kann einen Kommentar nur einmal melden
+// (ErrConflict). Erreichen die offenen Meldungen hideThreshold (> 0), wird der Kommentar bis
+// zur Prüfung zurückgehalten.
+func (r *CommentRepository) CreateReport(
+	ctx context.Context,
+	commentID int64,
+	input models.CommentReportInput,
+	hideThreshold int,
+) (*models.CommentReportResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin comment report tx %d: %w", commentID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var authorUserID *int64
+	var deleted bool
+	var moderationStatus string
+	if err := tx.QueryRow(ctx, `
+		SELECT author_user_id, deleted_at IS NOT NULL, moderation_status
+		FROM comments
+		WHERE id = $1
+		FOR UPDATE
+	`, commentID).Scan(&authorUserID, &deleted, &moderationStatus); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("lock comment %d for report: %w", commentID, err)
+	}
+	if deleted || moderationStatus == models.CommentModerationHidden {
+		return nil, ErrNotFound
+	}
+	if isCommentAuthor(authorUserID, input.ReporterUserID) {
+		return nil, &CommentModerationError{Message: "eigene kommentare können nicht gemeldet werden"}
+	}
+
+	result := &models.CommentReportResult{}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO comment_reports (comment_id, reporter_user_id, reason, note)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (comment_id, reporter_user_id) DO NOTHING
+		RETURNING id
+	`, commentID, input.ReporterUserID, input.Reason, input.Note).Scan(&result.ReportID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("insert comment report %d: %w", commentID, err)
+	}
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM comment_reports WHERE comment_id = $1 AND status = 'open'
+	`, commentID).Scan(&result.OpenReports); err != nil {
+		return nil, fmt.Errorf("count open reports for comment %d: %w", commentID, err)
+	}
+
+	if shouldHoldReportedComment(moderationStatus, result.OpenReports, hideThreshold) {
+		if _, err := tx.Exec(ctx, `
+			UPDATE comments
+			SET moderation_status = 'pending',
+				moderation_flags = ARRAY(SELECT DISTINCT unnest(moderation_flags || ARRAY['reported']::text[])),
+				updated_at = NOW()
+			WHERE id = $1
+		`, commentID); err != nil {
+			return nil, fmt.Errorf("hold reported comment %d: %w", commentID, err)
+		}
+		result.HeldBack = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit comment report tx %d: %w", commentID, err)
+	}
+
+	return result, nil
+}
+
+// ListModerationQueue liefert nicht gelöschte Kommentare, die moderiert werden müssen
+// (älteste zuerst). Ohne Status-Filter: zurückgehaltene und gemeldete sichtbare Kommentare.
+func (r *CommentRepository) ListModerationQueue(
+	ctx context.Context,
+	filter models.CommentModerationFilter,
+) ([]models.CommentModerationQueueItem, int64, error) {
+	condition, err := commentModerationQueueCondition(filter.Status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM comments c
+		WHERE c.deleted_at IS NULL
+		  AND `+condition).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count comment moderation queue: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			c.id, c.target_type, c.target_id, c.anime_id, c.parent_id,
+			c.author_name, c.author_user_id, c.content, c.created_at,
+			c.moderation_status, c.moderation_flags, c.moderated_at,
+			EXISTS(
+				SELECT 1 FROM comment_user_bans b
+				WHERE b.user_id = c.author_user_id
+				  AND (b.expires_at IS NULL OR b.expires_at > NOW())
+			)
+		FROM comments c
+		WHERE c.deleted_at IS NULL
+		  AND `+condition+`
+		ORDER BY c.created_at ASC, c.id ASC
+		LIMIT $1 OFFSET $2
+	`, filter.PerPage, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query comment moderation queue: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.CommentModerationQueueItem, 0)
+	index := make(map[int64]int)
+	for rows.Next() {
+		var item models.CommentModerationQueueItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.TargetType,
+			&item.TargetID,
+			&item.AnimeID,
+			&item.ParentID,
+			&item.AuthorName,
+			&item.AuthorUserID,
+			&item.Content,
+			&item.CreatedAt,
+			&item.ModerationStatus,
+			&item.ModerationFlags,
+			&item.ModeratedAt,
+			&item.AuthorBanned,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan comment moderation queue row: %w", err)
+		}
+		item.Reports = []models.CommentReportSummary{}
+		index[item.ID] = len(items)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate comment moderation queue: %w", err)
+	}
+	rows.Close()
+	if len(items) == 0 {
+		return items, total, nil
+	}
+
+	commentIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		commentIDs = append(commentIDs, item.ID)
+	}
+	reportRows, err := r.db.Query(ctx, `
+		SELECT comment_id, reason, COUNT(*)
+		FROM comment_reports
+		WHERE comment_id = ANY($1)
+		  AND status = 'open'
+		GROUP BY comment_id, reason
+		ORDER BY comment_id, COUNT(*) DESC, reason
+	`, commentIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query comment report summaries: %w", err)
+	}
+	defer reportRows.Close()
+
+	for reportRows.Next() {
+		var commentID int64
+		var summary models.CommentReportSummary
+		if err := reportRows.Scan(&commentID, &summary.Reason, &summary.Count); err != nil {
+			return nil, 0, fmt.Errorf("scan comment report summary: %w", err)
+		}
+		position, ok := index[commentID]
+		if !ok {
+			continue
+		}
+		items[position].Reports = append(items[position].Reports, summary)
+		items[position].OpenReports += summary.Count
+	}
+	if err := reportRows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate comment report summaries: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// Moderate wendet eine Moderationsentscheidung an: approve macht den Kommentar sichtbar und
+// verwirft offene Meldungen, hide blendet ihn aus und schließt die Meldungen, ban blendet ihn
+// zusätzlich aus und sperrt den Autor für Kommentare.
+func (r *CommentRepository) Moderate(
+	ctx context.Context,
+	commentID int64,
+	input models.CommentModerationInput,
+) (*models.CommentModerationResult, error) {
+	status, reportStatus, err := resolveCommentModerationAction(input.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin comment moderation tx %d: %w", commentID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var authorUserID *int64
+	var deleted bool
+	if err := tx.QueryRow(ctx, `
+		SELECT author_user_id, deleted_at IS NOT NULL
+		FROM comments
+		WHERE id = $1
+		FOR UPDATE
+	`, commentID).Scan(&authorUserID, &deleted); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("lock comment %d for moderation: %w", commentID, err)
+	}
+	if deleted {
+		return nil, ErrNotFound
+	}
+	if input.Action == models.CommentModerationActionBan && authorUserID == nil {
+		return nil, &CommentModerationError{Message: "kommentar ist keinem benutzer zugeordnet"}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE comments
+		SET moderation_status = $2,
+			moderated_at = NOW(),
+			moderated_by_app_user_id = $3,
+			updated_at = NOW()
+		WHERE id = $1
+	`, commentID, status, input.ModeratorAppUserID); err != nil {
+		return nil, fmt.Errorf("update comment moderation %d: %w", commentID, err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE comment_reports
+		SET status = $2,
+			resolved_at = NOW(),
+			resolved_by_app_user_id = $3
+		WHERE comment_id = $1
+		  AND status = 'open'
+	`, commentID, reportStatus, input.ModeratorAppUserID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve comment reports %d: %w", commentID, err)
+	}
+
+	result := &models.CommentModerationResult{
+		CommentID:        commentID,
+		ModerationStatus: status,
+		ResolvedReports:  int(tag.RowsAffected()),
+	}
+
+	if input.Action == models.CommentModerationActionBan {
+		var expiresAt *time.Time
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO comment_user_bans (user_id, reason, banned_by_app_user_id, expires_at)
+			VALUES ($1, $2, $3, CASE WHEN $4::int > 0 THEN NOW() + make_interval(days => $4::int) END)
+			ON CONFLICT (user_id) DO UPDATE SET
+				reason = EXCLUDED.reason,
+				banned_by_app_user_id = EXCLUDED.banned_by_app_user_id,
+				created_at = NOW(),
+				expires_at = EXCLUDED.expires_at
+			RETURNING expires_at
+		`, *authorUserID, input.Reason, input.ModeratorAppUserID, input.BanDays).Scan(&expiresAt); err != nil {
+			return nil, fmt.Errorf("ban comment author %d: %w", *authorUserID, err)
+		}
+		result.BannedUserID = authorUserID
+		result.BanExpiresAt = expiresAt
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit comment moderation tx %d: %w", commentID, err)
+	}
+
+	return result, nil
+}
+
+// shouldHoldReportedComment entscheidet, ob ein sichtbarer Kommentar wegen Meldungen
+// zurückgehalten wird. Ein Schwellwert <= 0 deaktiviert das automatische Zurückhalten.
+func shouldHoldReportedComment(moderationStatus string, openReports int, hideThreshold int) bool {
+	return hideThreshold > 0 &&
+		moderationStatus == models.CommentModerationVisible &&
+		openReports >= hideThreshold
+}
+
+// commentModerationQueueCondition liefert die WHERE-Bedingung zum Queue-Filter.
+func commentModerationQueueCondition(status string) (string, error) {
+	const hasOpenReports = `EXISTS (SELECT 1 FROM comment_reports cr WHERE cr.comment_id = c.id AND cr.status = 'open')`
+
+	switch status {
+	case "":
+		return `(c.moderation_status = 'pending' OR (c.moderation_status = 'visible' AND ` + hasOpenReports + `))`, nil
+	case models.CommentModerationPending, models.CommentModerationHidden:
+		return `c.moderation_status = '` + status + `'`, nil
+	case "reported":
+		return `(c.moderation_status = 'visible' AND ` + hasOpenReports + `)`, nil
+	default:
+		return "", &CommentModerationError{Message: "ungültiger status filter"}
+	}
+}
+
+// resolveCommentModerationAction bildet eine Aktion auf den neuen Kommentarstatus und den
+// Abschlussstatus der offenen Meldungen ab.
+func resolveCommentModerationAction(action string) (string, string, error) {
+	switch action {
+	case models.CommentModerationActionApprove:
+		return models.CommentModerationVisible, "dismissed", nil
+	case models.CommentModerationActionHide, models.CommentModerationActionBan:
+		return models.CommentModerationHidden, "resolved", nil
+	default:
+		return "", "", &CommentModerationError{Message: "ungültige moderationsaktion"}
+	}
+}
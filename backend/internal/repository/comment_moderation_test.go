@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestResolveCommentModerationAction(t *testing.T) {
+	tests := []struct {
+		action       string
+		wantStatus   string
+		wantReports  string
+		wantErrValid bool
+	}{
+		{action: models.CommentModerationActionApprove, wantStatus: models.CommentModerationVisible, wantReports: "dismissed"},
+		{action: models.CommentModerationActionHide, wantStatus: models.CommentModerationHidden, wantReports: "resolved"},
+		{action: models.CommentModerationActionBan, wantStatus: models.CommentModerationHidden, wantReports: "resolved"},
+		{action: "delete", wantErrValid: true},
+	}
+
+	for _, tc := range tests {
+		status, reportStatus, err := resolveCommentModerationAction(tc.action)
+		if tc.wantErrValid {
+			var moderationErr *CommentModerationError
+			if !errors.As(err, &moderationErr) || !errors.Is(err, ErrValidation) {
+				t.Fatalf("%s: expected moderation validation error, got %v", tc.action, err)
+			}
+			continue
+		}
+		if err != nil || status != tc.wantStatus || reportStatus != tc.wantReports {
+			t.Fatalf("%s: expected %s/%s, got %s/%s (%v)", tc.action, tc.wantStatus, tc.wantReports, status, reportStatus, err)
+		}
+	}
+}
+
+func TestShouldHoldReportedComment(t *testing.T) {
+	tests := []struct {
+		status    string
+		open      int
+		threshold int
+		want      bool
+	}{
+		{status: models.CommentModerationVisible, open: 3, threshold: 3, want: true},
+		{status: models.CommentModerationVisible, open: 2, threshold: 3},
+		{status: models.CommentModerationPending, open: 5, threshold: 3},
+		{status: models.CommentModerationVisible, open: 10, threshold: 0},
+	}
+
+	for _, tc := range tests {
+		if got := shouldHoldReportedComment(tc.status, tc.open, tc.threshold); got != tc.want {
+			t.Fatalf("status %s open %d threshold %d: expected %v, got %v", tc.status, tc.open, tc.threshold, tc.want, got)
+		}
+	}
+}
+
+func TestCommentModerationQueueCondition(t *testing.T) {
+	for _, status := range []string{"", "pending", "hidden", "reported"} {
+		condition, err := commentModerationQueueCondition(status)
+		if err != nil || strings.TrimSpace(condition) == "" {
+			t.Fatalf("status %q: expected condition, got %q (%v)", status, condition, err)
+		}
+	}
+	if _, err := commentModerationQueueCondition("visible'; --"); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for unknown status, got %v", err)
+	}
+}
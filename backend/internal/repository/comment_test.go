@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestBuildCommentThreads(t *testing.T) {
+	base := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	comment := func(id int64, parentID int64, depth int16, deleted bool) models.CommentListItem {
+		item := models.CommentListItem{
+			ID:         id,
+			TargetType: models.CommentTargetAnime,
+			TargetID:   1,
+			Depth:      depth,
+			AuthorName: "Autor",
+			Content:    "Text",
+			CreatedAt:  base.Add(time.Duration(id) * time.Minute),
+			IsDeleted:  deleted,
+
+			ModerationStatus: models.CommentModerationVisible,
+		}
+		if parentID > 0 {
+			item.ParentID = int64Ptr(parentID)
+		}
+		return item
+	}
+
+	roots := []models.CommentListItem{
+		comment(1, 0, 0, true),
+		comment(2, 0, 0, false),
+		comment(3, 0, 0, true),
+	}
+	hiddenReply := comment(21, 2, 1, false)
+	hiddenReply.ModerationStatus = models.CommentModerationHidden
+
+	replies := []models.CommentListItem{
+		comment(10, 1, 1, false),
+		comment(11, 10, 2, true),
+		comment(12, 11, 3, false),
+		comment(13, 1, 1, true),
+		comment(20, 2, 1, true),
+		hiddenReply,
+		comment(30, 3, 1, true),
+	}
+
+	threads := buildCommentThreads(roots, replies)
+	if len(threads) != 2 {
+		t.Fatalf("expected deleted thread without visible replies to be dropped, got %d threads", len(threads))
+	}
+
+	first := threads[0]
+	if first.ID != 1 || !first.IsDeleted || first.Content != "" || first.AuthorName != "" {
+		t.Fatalf("expected masked placeholder for deleted root, got %+v", first)
+	}
+	if len(first.Replies) != 1 || first.Replies[0].ID != 10 {
+		t.Fatalf("expected deleted leaf reply 13 to be dropped, got %+v", first.Replies)
+	}
+	if first.ReplyCount != 2 {
+		t.Fatalf("expected 2 visible replies below root, got %d", first.ReplyCount)
+	}
+
+	placeholder := first.Replies[0].Replies[0]
+	if placeholder.ID != 11 || !placeholder.IsDeleted || placeholder.Content != "" {
+		t.Fatalf("expected deleted reply with children to stay as placeholder, got %+v", placeholder)
+	}
+	if len(placeholder.Replies) != 1 || placeholder.Replies[0].Content != "Text" {
+		t.Fatalf("expected visible grandchild under placeholder, got %+v", placeholder.Replies)
+	}
+
+	second := threads[1]
+	if second.ID != 2 || second.ReplyCount != 0 || len(second.Replies) != 0 {
+		t.Fatalf("expected thread 2 without deleted or hidden replies, got %+v", second)
+	}
+}
+
+func TestResolveCommentReplyPosition(t *testing.T) {
+	rootID := int64(5)
+
+	tests := []struct {
+		name       string
+		parent     models.CommentListItem
+		wantRootID int64
+		wantDepth  int16
+		wantErr    bool
+	}{
+		{
+			name:       "reply to top level comment",
+			parent:     models.CommentListItem{ID: 5, TargetType: models.CommentTargetAnime, TargetID: 1, ModerationStatus: models.CommentModerationVisible},
+			wantRootID: 5,
+			wantDepth:  1,
+		},
+		{
+			name:       "nested reply keeps root",
+			parent:     models.CommentListItem{ID: 8, TargetType: models.CommentTargetAnime, TargetID: 1, RootID: &rootID, Depth: 2, ModerationStatus: models.CommentModerationVisible},
+			wantRootID: 5,
+			wantDepth:  3,
+		},
+		{
+			name:    "max depth reached",
+			parent:  models.CommentListItem{ID: 9, TargetType: models.CommentTargetAnime, TargetID: 1, RootID: &rootID, Depth: models.MaxCommentDepth},
+			wantErr: true,
+		},
+		{
+			name:    "parent from other anime",
+			parent:  models.CommentListItem{ID: 5, TargetType: models.CommentTargetAnime, TargetID: 2},
+			wantErr: true,
+		},
+		{
+			name:    "parent on other target type",
+			parent:  models.CommentListItem{ID: 5, TargetType: models.CommentTargetEpisode, TargetID: 1, ModerationStatus: models.CommentModerationVisible},
+			wantErr: true,
+		},
+		{
+			name:    "deleted parent",
+			parent:  models.CommentListItem{ID: 5, TargetType: models.CommentTargetAnime, TargetID: 1, IsDeleted: true},
+			wantErr: true,
+		},
+		{
+			name:    "parent held for review",
+			parent:  models.CommentListItem{ID: 5, TargetType: models.CommentTargetAnime, TargetID: 1, ModerationStatus: models.CommentModerationPending},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			gotRootID, gotDepth, err := resolveCommentReplyPosition(models.CommentTarget{Type: models.CommentTargetAnime, ID: 1}, &tc.parent)
+			if tc.wantErr {
+				var threadErr *CommentThreadError
+				if !errors.As(err, &threadErr) || !errors.Is(err, ErrValidation) {
+					t.Fatalf("expected comment thread validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotRootID == nil || *gotRootID != tc.wantRootID || gotDepth != tc.wantDepth {
+				t.Fatalf("expected root %d depth %d, got %v depth %d", tc.wantRootID, tc.wantDepth, gotRootID, gotDepth)
+			}
+		})
+	}
+}
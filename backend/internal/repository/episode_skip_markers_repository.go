@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EpisodeSkipMarkerRepository löst die Theme-Segmente auf, die für eine Episodenversion gelten.
+type EpisodeSkipMarkerRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEpisodeSkipMarkerRepository(db *pgxpool.Pool) *EpisodeSkipMarkerRepository {
+	return &EpisodeSkipMarkerRepository{db: db}
+}
+
+// GetEpisodeSkipMarkerSource liefert die Release-Variante zu versionID (Varianten- oder
+// Versions-ID) samt aller Segmente, die nach Anime, Fansub-Gruppe, Version und Episodenbereich
+// zutreffen - dieselben Regeln wie segment_count in der Episodenversions-Liste.
+func (r *EpisodeSkipMarkerRepository) GetEpisodeSkipMarkerSource(ctx context.Context, versionID int64) (*models.EpisodeSkipMarkerSource, error) {
+	var (
+		source        models.EpisodeSkipMarkerSource
+		animeID       int64
+		episodeNumber int32
+		version       string
+		groupIDs      []int64
+	)
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			rv.id,
+			rev.id,
+			primary_episode.anime_id,
+			CAST(primary_episode.episode_number AS INTEGER),
+			COALESCE(NULLIF(BTRIM(rev.version), ''), 'v1'),
+			rv.duration_seconds,
+			stream.jellyfin_item_id,
+			ARRAY(
+				SELECT rvg.fansub_group_id
+				FROM release_version_groups rvg
+				WHERE rvg.release_version_id = rev.id
+				ORDER BY rvg.fansub_group_id
+			)
+		FROM release_variants rv
+		JOIN release_versions rev ON rev.id = rv.release_version_id
+		JOIN fansub_releases fr ON fr.id = rev.release_id
+		JOIN episodes primary_episode ON primary_episode.id = fr.episode_id AND primary_episode.episode_number ~ '^[0-9]+$'
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(
+				NULLIF(BTRIM(rs.jellyfin_item_id), ''),
+				CASE WHEN ss.provider_type = 'jellyfin' THEN NULLIF(BTRIM(ss.external_id), '') END
+			) AS jellyfin_item_id
+			FROM release_streams rs
+			LEFT JOIN stream_sources ss ON ss.id = rs.stream_source_id
+			WHERE rs.variant_id = rv.id
+			ORDER BY rs.id ASC
+			LIMIT 1
+		) stream ON TRUE
+		WHERE rv.id = $1 OR rev.id = $1
+		ORDER BY rv.id ASC
+		LIMIT 1
+	`, versionID).Scan(
+		&source.ReleaseVariantID,
+		&source.ReleaseVersionID,
+		&animeID,
+		&episodeNumber,
+		&version,
+		&source.DurationSeconds,
+		&source.JellyfinItemID,
+		&groupIDs,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get skip marker source version=%d: %w", versionID, err)
+	}
+	if len(groupIDs) == 0 {
+		// Versionen ohne Gruppe passen zu Segmenten ohne fansub_group_id.
+		groupIDs = []int64{0}
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			ts.id,
+			tt.name,
+			t.title,
+			COALESCE(tsps.start_offset_seconds, EXTRACT(EPOCH FROM ts.start_time)::INTEGER),
+			COALESCE(tsps.end_offset_seconds, EXTRACT(EPOCH FROM ts.end_time)::INTEGER)
+		FROM theme_segments ts
+		JOIN themes t ON t.id = ts.theme_id
+		JOIN theme_types tt ON tt.id = t.theme_type_id
+		LEFT JOIN theme_segment_playback_sources tsps ON tsps.theme_segment_id = ts.id
+		WHERE t.anime_id = $1
+		  AND COALESCE(ts.fansub_group_id, 0) = ANY($2)
+		  AND COALESCE(NULLIF(BTRIM(ts.version), ''), 'v1') = $3
+		  AND (ts.start_episode IS NULL OR ts.start_episode <= $4)
+		  AND (ts.end_episode IS NULL OR ts.end_episode >= $4)
+		ORDER BY 4 ASC NULLS LAST, ts.id ASC
+	`, animeID, groupIDs, version, episodeNumber)
+	if err != nil {
+		return nil, fmt.Errorf("list skip marker segments version=%d: %w", versionID, err)
+	}
+	defer rows.Close()
+
+	source.Segments = make([]models.EpisodeSkipMarkerSegment, 0)
+	for rows.Next() {
+		var segment models.EpisodeSkipMarkerSegment
+		if err := rows.Scan(
+			&segment.SegmentID,
+			&segment.ThemeTypeName,
+			&segment.ThemeTitle,
+			&segment.StartSeconds,
+			&segment.EndSeconds,
+		); err != nil {
+			return nil, fmt.Errorf("scan skip marker segment version=%d: %w", versionID, err)
+		}
+		source.Segments = append(source.Segments, segment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate skip marker segments version=%d: %w", versionID, err)
+	}
+
+	return &source, nil
+}
@@ -42,11 +42,17 @@ func (r *EpisodeVersionRepository) ListGroupedByAnimeID(
 	if err != nil {
 		return nil, err
 	}
+	commentCounts, err := r.countEpisodeCommentsByNumber(ctx, animeID)
+	if err != nil {
+		return nil, err
+	}
 
 	if !includeVersions {
+		grouped := buildGroupedEpisodeCounts(episodeTitlesByNumber, versionCounts)
+		applyGroupedEpisodeCommentCounts(grouped, commentCounts)
 		return &models.GroupedEpisodesData{
 			AnimeID:  animeID,
-			Episodes: buildGroupedEpisodeCounts(episodeTitlesByNumber, versionCounts),
+			Episodes: grouped,
 		}, nil
 	}
 
@@ -57,6 +63,7 @@ func (r *EpisodeVersionRepository) ListGroupedByAnimeID(
 	if len(grouped) == 0 {
 		grouped = buildGroupedEpisodeCounts(episodeTitlesByNumber, versionCounts)
 	}
+	applyGroupedEpisodeCommentCounts(grouped, commentCounts)
 	return &models.GroupedEpisodesData{AnimeID: animeID, Episodes: grouped}, nil
 }
 
@@ -359,6 +366,74 @@ func (r *EpisodeVersionRepository) DeleteByAnimeEpisodeNumberAndProvider(
 	return 0, phase20ReleaseImportDeferred("delete episode releases by provider", animeID)
 }
 
+// DeleteByAnimeMediaItemAndProvider löscht nur die Release-Varianten des Anime, deren Stream auf
+// das Mediaserver-Item mediaItemID zeigt. Weitere Versionen mit derselben Episodennummer (andere
+// Staffel, anderes Release) bleiben erhalten.
+func (r *EpisodeVersionRepository) DeleteByAnimeMediaItemAndProvider(
+	ctx context.Context,
+	animeID int64,
+	mediaItemID string,
+	provider string,
+) (int64, error) {
+	mediaItemID = strings.TrimSpace(mediaItemID)
+	if mediaItemID == "" {
+		return 0, fmt.Errorf("%w: media item id fehlt", ErrValidation)
+	}
+
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("begin delete media item releases tx anime=%d item=%s: %w", animeID, mediaItemID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	type variantTarget struct {
+		variantID        int64
+		releaseVersionID int64
+		releaseID        int64
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT rv.id, rev.id, rev.release_id
+		FROM release_streams rs
+		JOIN stream_sources ss ON ss.id = rs.stream_source_id
+		JOIN release_variants rv ON rv.id = rs.variant_id
+		JOIN release_versions rev ON rev.id = rv.release_version_id
+		JOIN fansub_releases fr ON fr.id = rev.release_id
+		JOIN episodes e ON e.id = fr.episode_id
+		WHERE e.anime_id = $1
+		  AND ss.provider_type = $2
+		  AND (ss.external_id = $3 OR rs.jellyfin_item_id = $3)
+		ORDER BY rv.id ASC
+	`, animeID, provider, mediaItemID)
+	if err != nil {
+		return 0, fmt.Errorf("query media item releases anime=%d item=%s: %w", animeID, mediaItemID, err)
+	}
+	targets := make([]variantTarget, 0, 1)
+	for rows.Next() {
+		var target variantTarget
+		if err := rows.Scan(&target.variantID, &target.releaseVersionID, &target.releaseID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan media item release anime=%d item=%s: %w", animeID, mediaItemID, err)
+		}
+		targets = append(targets, target)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("iterate media item releases anime=%d item=%s: %w", animeID, mediaItemID, err)
+	}
+	rows.Close()
+
+	for _, target := range targets {
+		if err := deleteReleaseVariantTx(ctx, tx, target.variantID, target.releaseVersionID, target.releaseID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit delete media item releases anime=%d item=%s: %w", animeID, mediaItemID, err)
+	}
+	return int64(len(targets)), nil
+}
+
 func (r *EpisodeVersionRepository) CountByAnimeAndProvider(ctx context.Context, animeID int64, provider string) (int64, error) {
 	var count int64
 	err := r.db.QueryRow(ctx, `
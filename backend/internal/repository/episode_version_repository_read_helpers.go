@@ -94,6 +94,50 @@ func (r *EpisodeVersionRepository) countReleaseVariantsByEpisodeNumber(
 	return result, nil
 }
 
+// countEpisodeCommentsByNumber zählt die sichtbaren Kommentare (inkl. Antworten) an den
+// Episoden eines Anime, gruppiert nach Episodennummer.
+func (r *EpisodeVersionRepository) countEpisodeCommentsByNumber(
+	ctx context.Context,
+	animeID int64,
+) (map[int32]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT CAST(e.episode_number AS INTEGER) AS episode_number, COUNT(c.id) AS count
+		FROM episodes e
+		JOIN comments c ON c.target_type = 'episode' AND c.target_id = e.id
+		WHERE e.anime_id = $1
+		  AND e.episode_number ~ '^[0-9]+$'
+		  AND c.deleted_at IS NULL
+		  AND c.moderation_status = 'visible'
+		GROUP BY CAST(e.episode_number AS INTEGER)
+	`, animeID)
+	if err != nil {
+		return nil, fmt.Errorf("query episode comment counts for anime %d: %w", animeID, err)
+	}
+	defer rows.Close()
+
+	result := make(map[int32]int64, 32)
+	for rows.Next() {
+		var episodeNumber int32
+		var count int64
+		if err := rows.Scan(&episodeNumber, &count); err != nil {
+			return nil, fmt.Errorf("scan episode comment count row for anime %d: %w", animeID, err)
+		}
+		result[episodeNumber] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate episode comment counts for anime %d: %w", animeID, err)
+	}
+
+	return result, nil
+}
+
+// applyGroupedEpisodeCommentCounts überträgt die Kommentarzahlen auf die gruppierten Episoden.
+func applyGroupedEpisodeCommentCounts(grouped []models.GroupedEpisode, commentCounts map[int32]int64) {
+	for index := range grouped {
+		grouped[index].CommentCount = commentCounts[grouped[index].EpisodeNumber]
+	}
+}
+
 func (r *EpisodeVersionRepository) listReleaseVariantsByAnimeID(
 	ctx context.Context,
 	animeID int64,
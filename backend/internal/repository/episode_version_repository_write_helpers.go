@@ -267,6 +267,19 @@ func (r *EpisodeVersionRepository) Delete(ctx context.Context, versionID int64)
 		return fmt.Errorf("resolve delete release version target %d: %w", versionID, err)
 	}
 
+	if err := deleteReleaseVariantTx(ctx, tx, variantID, releaseVersionID, releaseID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit delete release version %d: %w", versionID, err)
+	}
+	return nil
+}
+
+// deleteReleaseVariantTx entfernt eine Release-Variante samt Streams. Leere Versionen und
+// Releases sowie verwaiste Stream-Quellen werden mit aufgeräumt.
+func deleteReleaseVariantTx(ctx context.Context, tx pgx.Tx, variantID int64, releaseVersionID int64, releaseID int64) error {
 	streamSourceIDs := make([]int64, 0, 2)
 	rows, err := tx.Query(ctx, `
 		SELECT DISTINCT stream_source_id
@@ -340,10 +353,6 @@ func (r *EpisodeVersionRepository) Delete(ctx context.Context, versionID int64)
 			return fmt.Errorf("delete orphaned stream source %d after release delete: %w", streamSourceID, err)
 		}
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commit delete release version %d: %w", versionID, err)
-	}
 	return nil
 }
 
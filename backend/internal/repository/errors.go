@@ -7,3 +7,4 @@ var ErrValidation = errors.New("validation")
 var ErrMemberProfileRequired = errors.New("member profile required")
 var ErrInvalidAnimeFansubContext = errors.New("invalid anime fansub context")
 var ErrInvalidReleaseVersionContributorContext = errors.New("invalid release version contributor context")
+var ErrForbidden = errors.New("forbidden")
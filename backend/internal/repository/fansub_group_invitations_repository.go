@@ -154,7 +154,13 @@ func (r *FansubGroupInvitationRepository) Create(
 		expiresAt = input.ExpiresAt.UTC()
 	}
 
-	row := r.db.QueryRow(ctx, `
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create fansub group invitation: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, `
 		INSERT INTO fansub_group_invitations (
 			fansub_group_id,
 			email,
@@ -195,9 +201,30 @@ func (r *FansubGroupInvitationRepository) Create(
 		return nil, err
 	}
 
+	inviteLink := fmt.Sprintf("/invitations/accept?token=%s", rawToken)
+	if input.InviteMail != nil {
+		referenceType := "fansub_group_invitation"
+		mail := *input.InviteMail
+		mail.Recipient = invitation.Email
+		mail.RedactAfterSend = true
+		mail.ReferenceType = &referenceType
+		mail.ReferenceID = &invitation.ID
+		mail.TemplateData = mergeMailTemplateData(mail.TemplateData, map[string]any{
+			"invite_link": inviteLink,
+			"expires_at":  invitation.ExpiresAt,
+		})
+		if _, err := enqueueMailOutbox(ctx, tx, mail); err != nil {
+			return nil, fmt.Errorf("create fansub group invitation: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("create fansub group invitation: commit: %w", err)
+	}
+
 	return &models.FansubGroupInvitationCreateResult{
 		Invitation: invitation,
-		InviteLink: fmt.Sprintf("/invitations/accept?token=%s", rawToken),
+		InviteLink: inviteLink,
 	}, nil
 }
 
@@ -278,9 +278,37 @@ func (r *FansubRepository) GetPublicProfileBySlug(ctx context.Context, slug stri
 	}
 	resp.Media = media
 
+	commentCount, err := r.countPublicFansubComments(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp.CommentCount = commentCount
+
+	followerCount, err := countFansubGroupFollowers(ctx, r.db, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp.FollowerCount = followerCount
+
 	return resp, nil
 }
 
+func (r *FansubRepository) countPublicFansubComments(ctx context.Context, groupID int64) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM comments c
+		WHERE c.target_type = 'fansub_group'
+		  AND c.target_id = $1
+		  AND c.deleted_at IS NULL
+		  AND c.moderation_status = 'visible'
+	`, groupID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count public fansub comments %d: %w", groupID, err)
+	}
+
+	return count, nil
+}
+
 func (r *FansubRepository) getPublicFansubStory(ctx context.Context, groupID int64) (*models.PublicFansubStory, error) {
 	var story models.PublicFansubStory
 	err := r.db.QueryRow(ctx, `
@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FollowRepository speichert Follows auf Fansub-Gruppen und Anime und liefert den daraus
+// abgeleiteten Aktivitäts-Feed.
+type FollowRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewFollowRepository(db *pgxpool.Pool) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+// FollowFansubGroup folgt einer Gruppe (idempotent). Unbekannte Gruppen liefern ErrNotFound.
+func (r *FollowRepository) FollowFansubGroup(ctx context.Context, appUserID int64, ref models.FansubGroupRef) (*models.FollowState, error) {
+	groupID, err := r.resolveFansubGroup(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.db.Exec(ctx, `
+		INSERT INTO fansub_group_follows (app_user_id, fansub_group_id)
+		VALUES ($1, $2)
+		ON CONFLICT (app_user_id, fansub_group_id) DO NOTHING
+	`, appUserID, groupID); err != nil {
+		return nil, fmt.Errorf("follow fansub group %d for app user %d: %w", groupID, appUserID, err)
+	}
+	return r.fansubGroupFollowState(ctx, groupID, true)
+}
+
+// UnfollowFansubGroup entfernt den Follow (idempotent). Unbekannte Gruppen liefern ErrNotFound.
+func (r *FollowRepository) UnfollowFansubGroup(ctx context.Context, appUserID int64, ref models.FansubGroupRef) (*models.FollowState, error) {
+	groupID, err := r.resolveFansubGroup(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.db.Exec(ctx, `
+		DELETE FROM fansub_group_follows
+		WHERE app_user_id = $1
+		  AND fansub_group_id = $2
+	`, appUserID, groupID); err != nil {
+		return nil, fmt.Errorf("unfollow fansub group %d for app user %d: %w", groupID, appUserID, err)
+	}
+	return r.fansubGroupFollowState(ctx, groupID, false)
+}
+
+// FollowAnime folgt einem Anime (idempotent). Fehlende oder deaktivierte Anime liefern ErrNotFound.
+func (r *FollowRepository) FollowAnime(ctx context.Context, appUserID int64, animeID int64) (*models.FollowState, error) {
+	if err := r.ensureAnime(ctx, animeID); err != nil {
+		return nil, err
+	}
+	if _, err := r.db.Exec(ctx, `
+		INSERT INTO anime_follows (app_user_id, anime_id)
+		VALUES ($1, $2)
+		ON CONFLICT (app_user_id, anime_id) DO NOTHING
+	`, appUserID, animeID); err != nil {
+		return nil, fmt.Errorf("follow anime %d for app user %d: %w", animeID, appUserID, err)
+	}
+	return r.animeFollowState(ctx, animeID, true)
+}
+
+// UnfollowAnime entfernt den Follow (idempotent). Fehlende oder deaktivierte Anime liefern ErrNotFound.
+func (r *FollowRepository) UnfollowAnime(ctx context.Context, appUserID int64, animeID int64) (*models.FollowState, error) {
+	if err := r.ensureAnime(ctx, animeID); err != nil {
+		return nil, err
+	}
+	if _, err := r.db.Exec(ctx, `
+		DELETE FROM anime_follows
+		WHERE app_user_id = $1
+		  AND anime_id = $2
+	`, appUserID, animeID); err != nil {
+		return nil, fmt.Errorf("unfollow anime %d for app user %d: %w", animeID, appUserID, err)
+	}
+	return r.animeFollowState(ctx, animeID, false)
+}
+
+// ListFollows liefert alle Follows eines Nutzers (neueste zuerst).
+func (r *FollowRepository) ListFollows(ctx context.Context, appUserID int64) (*models.FollowList, error) {
+	list := &models.FollowList{
+		FansubGroups: make([]models.FollowedFansubGroup, 0),
+		Anime:        make([]models.FollowedAnime, 0),
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT fg.id, fg.slug, fg.name, f.created_at
+		FROM fansub_group_follows f
+		JOIN fansub_groups fg ON fg.id = f.fansub_group_id
+		WHERE f.app_user_id = $1
+		ORDER BY f.created_at DESC, fg.id DESC
+	`, appUserID)
+	if err != nil {
+		return nil, fmt.Errorf("query followed fansub groups for app user %d: %w", appUserID, err)
+	}
+	for rows.Next() {
+		var item models.FollowedFansubGroup
+		if err := rows.Scan(&item.ID, &item.Slug, &item.Name, &item.FollowedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan followed fansub group: %w", err)
+		}
+		list.FansubGroups = append(list.FansubGroups, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate followed fansub groups: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT a.id, a.title, f.created_at
+		FROM anime_follows f
+		JOIN anime a ON a.id = f.anime_id
+		WHERE f.app_user_id = $1
+		  AND a.status <> 'disabled'
+		ORDER BY f.created_at DESC, a.id DESC
+	`, appUserID)
+	if err != nil {
+		return nil, fmt.Errorf("query followed anime for app user %d: %w", appUserID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item models.FollowedAnime
+		if err := rows.Scan(&item.ID, &item.Title, &item.FollowedAt); err != nil {
+			return nil, fmt.Errorf("scan followed anime: %w", err)
+		}
+		list.Anime = append(list.Anime, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate followed anime: %w", err)
+	}
+
+	return list, nil
+}
+
+// ListFeed liefert bis zu limit Feed-Einträge aus gefolgten Gruppen und Anime, neueste zuerst,
+// beginnend nach cursor. Aufgenommen werden nur öffentliche Inhalte: Release-Versionen nicht
+// deaktivierter Anime, bestätigte Historieneinträge sowie veröffentlichte, öffentliche
+// Member-Stories und Projekt-Notizen.
+func (r *FollowRepository) ListFeed(
+	ctx context.Context,
+	appUserID int64,
+	cursor *models.FeedCursor,
+	limit int,
+) ([]models.FeedItem, error) {
+	args := []any{appUserID, nil, nil, nil, limit}
+	if cursor != nil {
+		args[1], args[2], args[3] = cursor.OccurredAt, cursor.Kind, cursor.ID
+	}
+
+	rows, err := r.db.Query(ctx, `
+		WITH followed_groups AS (
+			SELECT fansub_group_id FROM fansub_group_follows WHERE app_user_id = $1
+		),
+		followed_anime AS (
+			SELECT anime_id FROM anime_follows WHERE app_user_id = $1
+		),
+		feed AS (
+			SELECT
+				'release_version' AS kind,
+				rv.id,
+				rv.created_at AS occurred_at,
+				a.title || ' – Episode ' || COALESCE(e.episode_number, '') || ' (' || rv.version || ')' AS title,
+				NULL::text AS excerpt,
+				a.id AS anime_id,
+				a.title AS anime_title,
+				e.id AS episode_id,
+				g.id AS fansub_group_id,
+				g.slug AS fansub_group_slug,
+				g.name AS fansub_group_name
+			FROM release_versions rv
+			JOIN fansub_releases fr ON fr.id = rv.release_id
+			JOIN episodes e ON e.id = fr.episode_id
+			JOIN anime a ON a.id = e.anime_id
+			LEFT JOIN LATERAL (
+				SELECT fg.id, fg.slug, fg.name
+				FROM release_version_groups rvg
+				JOIN fansub_groups fg ON fg.id = rvg.fansub_group_id
+				WHERE rvg.release_version_id = rv.id
+				ORDER BY (rvg.fansub_group_id IN (SELECT fansub_group_id FROM followed_groups)) DESC, fg.id ASC
+				LIMIT 1
+			) g ON TRUE
+			WHERE a.status <> 'disabled'
+			  AND (
+				a.id IN (SELECT anime_id FROM followed_anime)
+				OR EXISTS (
+					SELECT 1
+					FROM release_version_groups rvg
+					WHERE rvg.release_version_id = rv.id
+					  AND rvg.fansub_group_id IN (SELECT fansub_group_id FROM followed_groups)
+				)
+			  )
+
+			UNION ALL
+
+			SELECT
+				'group_history', h.id, h.created_at,
+				COALESCE(NULLIF(h.title, ''), h.event_type),
+				LEFT(h.note, 280),
+				NULL, NULL, NULL,
+				fg.id, fg.slug, fg.name
+			FROM fansub_group_history h
+			JOIN fansub_groups fg ON fg.id = h.fansub_group_id
+			WHERE h.fansub_group_id IN (SELECT fansub_group_id FROM followed_groups)
+			  AND h.status = 'confirmed'
+
+			UNION ALL
+
+			SELECT
+				'member_story', s.id, s.created_at,
+				COALESCE(NULLIF(s.title, ''), m.nickname),
+				LEFT(s.body_text, 280),
+				NULL, NULL, NULL,
+				fg.id, fg.slug, fg.name
+			FROM member_group_stories s
+			JOIN members m ON m.id = s.member_id
+			JOIN fansub_groups fg ON fg.id = s.fansub_group_id
+			WHERE s.fansub_group_id IN (SELECT fansub_group_id FROM followed_groups)
+			  AND s.visibility = 'public'
+			  AND s.status = 'published'
+			  AND s.deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT
+				'project_note', n.id, n.created_at,
+				COALESCE(NULLIF(n.title, ''), a.title),
+				LEFT(n.body_text, 280),
+				a.id, a.title, NULL,
+				fg.id, fg.slug, fg.name
+			FROM anime_fansub_project_notes n
+			JOIN anime a ON a.id = n.anime_id
+			JOIN fansub_groups fg ON fg.id = n.fansub_group_id
+			WHERE (
+				n.fansub_group_id IN (SELECT fansub_group_id FROM followed_groups)
+				OR n.anime_id IN (SELECT anime_id FROM followed_anime)
+			  )
+			  AND a.status <> 'disabled'
+			  AND n.visibility = 'public'
+			  AND n.status = 'published'
+			  AND n.deleted_at IS NULL
+		)
+		SELECT kind, id, occurred_at, title, NULLIF(excerpt, ''), anime_id, anime_title, episode_id,
+			fansub_group_id, fansub_group_slug, fansub_group_name
+		FROM feed
+		WHERE $2::timestamptz IS NULL
+		   OR (occurred_at, kind, id) < ($2::timestamptz, $3::text, $4::bigint)
+		ORDER BY occurred_at DESC, kind DESC, id DESC
+		LIMIT $5
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query feed for app user %d: %w", appUserID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.FeedItem, 0, limit)
+	for rows.Next() {
+		var item models.FeedItem
+		if err := rows.Scan(
+			&item.Kind,
+			&item.ID,
+			&item.OccurredAt,
+			&item.Title,
+			&item.Excerpt,
+			&item.AnimeID,
+			&item.AnimeTitle,
+			&item.EpisodeID,
+			&item.FansubGroupID,
+			&item.FansubGroupSlug,
+			&item.FansubGroupName,
+		); err != nil {
+			return nil, fmt.Errorf("scan feed item: %w", err)
+		}
+		item.LinkURL = feedItemLink(item)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feed for app user %d: %w", appUserID, err)
+	}
+
+	return items, nil
+}
+
+func (r *FollowRepository) resolveFansubGroup(ctx context.Context, ref models.FansubGroupRef) (int64, error) {
+	var groupID int64
+	var err error
+	if ref.ID > 0 {
+		err = r.db.QueryRow(ctx, `SELECT id FROM fansub_groups WHERE id = $1`, ref.ID).Scan(&groupID)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT id FROM fansub_groups WHERE slug = $1`, strings.TrimSpace(ref.Slug)).Scan(&groupID)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("resolve fansub group (id=%d, slug=%q): %w", ref.ID, ref.Slug, err)
+	}
+	return groupID, nil
+}
+
+func (r *FollowRepository) ensureAnime(ctx context.Context, animeID int64) error {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM anime WHERE id = $1 AND status <> 'disabled')
+	`, animeID).Scan(&exists); err != nil {
+		return fmt.Errorf("check anime %d: %w", animeID, err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *FollowRepository) fansubGroupFollowState(ctx context.Context, groupID int64, following bool) (*models.FollowState, error) {
+	count, err := countFansubGroupFollowers(ctx, r.db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.FollowState{TargetType: "fansub_group", TargetID: groupID, Following: following, FollowerCount: count}, nil
+}
+
+func (r *FollowRepository) animeFollowState(ctx context.Context, animeID int64, following bool) (*models.FollowState, error) {
+	var count int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM anime_follows WHERE anime_id = $1`, animeID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("count anime followers %d: %w", animeID, err)
+	}
+	return &models.FollowState{TargetType: "anime", TargetID: animeID, Following: following, FollowerCount: count}, nil
+}
+
+// countFansubGroupFollowers zählt die Follower einer Gruppe (auch für das öffentliche Profil).
+func countFansubGroupFollowers(ctx context.Context, q querier, groupID int64) (int64, error) {
+	var count int64
+	if err := q.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM fansub_group_follows
+		WHERE fansub_group_id = $1
+	`, groupID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count fansub group followers %d: %w", groupID, err)
+	}
+	return count, nil
+}
+
+// feedItemLink liefert den Frontend-Pfad eines Feed-Eintrags.
+func feedItemLink(item models.FeedItem) string {
+	switch {
+	case item.Kind == models.FeedKindReleaseVersion && item.EpisodeID != nil:
+		return fmt.Sprintf("/episodes/%d", *item.EpisodeID)
+	case item.Kind == models.FeedKindProjectNote && item.AnimeID != nil:
+		return fmt.Sprintf("/anime/%d", *item.AnimeID)
+	case item.FansubGroupSlug != nil:
+		return "/fansubs/" + *item.FansubGroupSlug
+	}
+	return ""
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxJellyfinPendingChangeErrorLength begrenzt die gespeicherte Fehlermeldung einer Übernahme.
+const maxJellyfinPendingChangeErrorLength = 1000
+
+// JellyfinPendingChangeRepository verwaltet die per Jellyfin-Webhook gemeldeten Änderungen und
+// die Auto-Übernahme-Einstellung pro Anime.
+type JellyfinPendingChangeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewJellyfinPendingChangeRepository(db *pgxpool.Pool) *JellyfinPendingChangeRepository {
+	return &JellyfinPendingChangeRepository{db: db}
+}
+
+// Upsert legt eine offene Änderung an oder aktualisiert die bestehende offene Änderung desselben
+// Items (letztes Ereignis gewinnt, event_count zählt mit). created meldet eine Neuanlage.
+func (r *JellyfinPendingChangeRepository) Upsert(
+	ctx context.Context,
+	input models.JellyfinPendingChangeInput,
+) (*models.JellyfinPendingChange, bool, error) {
+	payload, err := json.Marshal(input.Payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal jellyfin change payload: %w", err)
+	}
+
+	var created bool
+	item, err := scanJellyfinPendingChange(r.db.QueryRow(ctx, `
+		INSERT INTO jellyfin_pending_changes AS c (
+			anime_id, event_type, item_type, jellyfin_item_id, jellyfin_series_id,
+			item_name, season_number, episode_number, payload
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::jsonb)
+		ON CONFLICT (anime_id, jellyfin_item_id) WHERE status = 'pending' DO UPDATE SET
+			event_type = EXCLUDED.event_type,
+			item_type = EXCLUDED.item_type,
+			jellyfin_series_id = EXCLUDED.jellyfin_series_id,
+			item_name = COALESCE(EXCLUDED.item_name, c.item_name),
+			season_number = COALESCE(EXCLUDED.season_number, c.season_number),
+			episode_number = COALESCE(EXCLUDED.episode_number, c.episode_number),
+			payload = EXCLUDED.payload,
+			event_count = c.event_count + 1,
+			updated_at = NOW()
+		RETURNING `+jellyfinPendingChangeColumns+`, (xmax = 0)
+	`,
+		input.AnimeID,
+		input.EventType,
+		input.ItemType,
+		input.JellyfinItemID,
+		input.JellyfinSeriesID,
+		input.ItemName,
+		input.SeasonNumber,
+		input.EpisodeNumber,
+		string(payload),
+	), &created)
+	if err != nil {
+		return nil, false, fmt.Errorf("upsert jellyfin change anime=%d item=%s: %w", input.AnimeID, input.JellyfinItemID, err)
+	}
+	return &item, created, nil
+}
+
+// List liefert Änderungen, neueste zuerst, samt Gesamtzahl für die Pagination.
+func (r *JellyfinPendingChangeRepository) List(
+	ctx context.Context,
+	filter models.JellyfinPendingChangeFilter,
+) ([]models.JellyfinPendingChange, int64, error) {
+	const scope = `
+		FROM jellyfin_pending_changes c
+		WHERE ($1 = '' OR c.status = $1)
+		  AND ($2::bigint = 0 OR c.anime_id = $2)`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+scope, filter.Status, filter.AnimeID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count jellyfin changes: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT `+jellyfinPendingChangeColumns+`
+		`+scope+`
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $3 OFFSET $4
+	`, filter.Status, filter.AnimeID, filter.PerPage, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query jellyfin changes: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.JellyfinPendingChange, 0)
+	for rows.Next() {
+		item, err := scanJellyfinPendingChange(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate jellyfin changes: %w", err)
+	}
+	return items, total, nil
+}
+
+// GetByID liefert eine Änderung oder ErrNotFound.
+func (r *JellyfinPendingChangeRepository) GetByID(ctx context.Context, id int64) (*models.JellyfinPendingChange, error) {
+	item, err := scanJellyfinPendingChange(r.db.QueryRow(ctx, `
+		SELECT `+jellyfinPendingChangeColumns+`
+		FROM jellyfin_pending_changes c
+		WHERE c.id = $1
+	`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Resolve schließt eine offene Änderung ab. Bereits entschiedene Änderungen liefern
+// ErrConflict, unbekannte ErrNotFound.
+func (r *JellyfinPendingChangeRepository) Resolve(
+	ctx context.Context,
+	id int64,
+	resolution models.JellyfinPendingChangeResolution,
+) (*models.JellyfinPendingChange, error) {
+	var result *string
+	if resolution.Result != nil {
+		encoded, err := json.Marshal(resolution.Result)
+		if err != nil {
+			return nil, fmt.Errorf("marshal jellyfin change result: %w", err)
+		}
+		value := string(encoded)
+		result = &value
+	}
+	errorMessage := resolution.ErrorMessage
+	if errorMessage != nil && len(*errorMessage) > maxJellyfinPendingChangeErrorLength {
+		truncated := (*errorMessage)[:maxJellyfinPendingChangeErrorLength]
+		errorMessage = &truncated
+	}
+
+	item, err := scanJellyfinPendingChange(r.db.QueryRow(ctx, `
+		UPDATE jellyfin_pending_changes c
+		SET status = $2,
+		    result = $3::jsonb,
+		    error_message = $4,
+		    resolved_by_app_user_id = $5,
+		    resolved_at = NOW(),
+		    updated_at = NOW()
+		WHERE c.id = $1 AND c.status = 'pending'
+		RETURNING `+jellyfinPendingChangeColumns,
+		id,
+		resolution.Status,
+		result,
+		errorMessage,
+		resolution.ResolvedByAppUserID,
+	))
+	if errors.Is(err, ErrNotFound) {
+		if _, getErr := r.GetByID(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetSyncSettings liefert die Auto-Übernahme-Einstellung einer Anime; ohne Eintrag ist sie aus.
+func (r *JellyfinPendingChangeRepository) GetSyncSettings(ctx context.Context, animeID int64) (*models.AnimeJellyfinSyncSettings, error) {
+	settings := models.AnimeJellyfinSyncSettings{AnimeID: animeID}
+	err := r.db.QueryRow(ctx, `
+		SELECT auto_apply, updated_at
+		FROM anime_jellyfin_sync_settings
+		WHERE anime_id = $1
+	`, animeID).Scan(&settings.AutoApply, &settings.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load jellyfin sync settings anime=%d: %w", animeID, err)
+	}
+	return &settings, nil
+}
+
+// SetAutoApply speichert die Auto-Übernahme-Einstellung einer Anime. Unbekannte Anime liefern
+// ErrNotFound.
+func (r *JellyfinPendingChangeRepository) SetAutoApply(
+	ctx context.Context,
+	animeID int64,
+	autoApply bool,
+	actorAppUserID *int64,
+) (*models.AnimeJellyfinSyncSettings, error) {
+	settings := models.AnimeJellyfinSyncSettings{AnimeID: animeID}
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO anime_jellyfin_sync_settings (anime_id, auto_apply, updated_by_app_user_id)
+		SELECT a.id, $2, $3 FROM anime a WHERE a.id = $1
+		ON CONFLICT (anime_id) DO UPDATE SET
+			auto_apply = EXCLUDED.auto_apply,
+			updated_by_app_user_id = EXCLUDED.updated_by_app_user_id,
+			updated_at = NOW()
+		RETURNING auto_apply, updated_at
+	`, animeID, autoApply, actorAppUserID).Scan(&settings.AutoApply, &settings.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("save jellyfin sync settings anime=%d: %w", animeID, err)
+	}
+	return &settings, nil
+}
+
+const jellyfinPendingChangeColumns = `
+	c.id, c.anime_id, c.event_type, c.item_type, c.jellyfin_item_id, c.jellyfin_series_id,
+	c.item_name, c.season_number, c.episode_number, c.status, c.event_count, c.result,
+	c.error_message, c.resolved_by_app_user_id, c.resolved_at, c.created_at, c.updated_at`
+
+// scanJellyfinPendingChange liest eine Zeile; extra nimmt zusätzliche Spalten hinter den
+// Standardspalten auf.
+func scanJellyfinPendingChange(row pgx.Row, extra ...any) (models.JellyfinPendingChange, error) {
+	var item models.JellyfinPendingChange
+	var result []byte
+	dest := []any{
+		&item.ID,
+		&item.AnimeID,
+		&item.EventType,
+		&item.ItemType,
+		&item.JellyfinItemID,
+		&item.JellyfinSeriesID,
+		&item.ItemName,
+		&item.SeasonNumber,
+		&item.EpisodeNumber,
+		&item.Status,
+		&item.EventCount,
+		&result,
+		&item.ErrorMessage,
+		&item.ResolvedByAppUserID,
+		&item.ResolvedAt,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.JellyfinPendingChange{}, ErrNotFound
+		}
+		return models.JellyfinPendingChange{}, fmt.Errorf("scan jellyfin change: %w", err)
+	}
+	if len(result) > 0 {
+		item.Result = result
+	}
+	return item, nil
+}
@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxJellyfinReconciliationErrorLength begrenzt die gespeicherte Fehlermeldung eines Laufs.
+const maxJellyfinReconciliationErrorLength = 1000
+
+// JellyfinReconciliationRepository verwaltet Läufe und Befunde des Bibliotheksabgleichs und
+// liefert die dafür nötigen Katalogdaten (verknüpfte Anime, Release-Varianten mit Streams).
+type JellyfinReconciliationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewJellyfinReconciliationRepository(db *pgxpool.Pool) *JellyfinReconciliationRepository {
+	return &JellyfinReconciliationRepository{db: db}
+}
+
+// StartRun legt einen laufenden Lauf an. Läuft bereits ein Lauf, liefert StartRun ErrConflict;
+// Läufe, die seit staleAfterMinutes nicht beendet wurden, gelten als abgebrochen und werden
+// vorher als failed markiert.
+func (r *JellyfinReconciliationRepository) StartRun(
+	ctx context.Context,
+	provider string,
+	libraryIDs []string,
+	staleAfterMinutes int,
+) (*models.JellyfinReconciliationRun, error) {
+	if _, err := r.db.Exec(ctx, `
+		UPDATE jellyfin_reconciliation_runs
+		SET status = 'failed',
+		    error_message = 'lauf abgebrochen',
+		    finished_at = NOW()
+		WHERE status = 'running'
+		  AND started_at < NOW() - make_interval(mins => $1)
+	`, staleAfterMinutes); err != nil {
+		return nil, fmt.Errorf("expire stale jellyfin reconciliation runs: %w", err)
+	}
+
+	if libraryIDs == nil {
+		libraryIDs = []string{}
+	}
+	run, err := scanJellyfinReconciliationRun(r.db.QueryRow(ctx, `
+		INSERT INTO jellyfin_reconciliation_runs (provider, library_ids)
+		VALUES ($1, $2)
+		RETURNING `+jellyfinReconciliationRunColumns,
+		provider,
+		libraryIDs,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrConflict
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FinishRun beendet einen Lauf. Bei status=completed werden offene und verworfene Befunde, die
+// dieser Lauf nicht mehr gesehen hat, als erledigt markiert.
+func (r *JellyfinReconciliationRepository) FinishRun(
+	ctx context.Context,
+	runID int64,
+	result models.JellyfinReconciliationRunResult,
+) (*models.JellyfinReconciliationRun, error) {
+	errorMessage := result.ErrorMessage
+	if errorMessage != nil && len(*errorMessage) > maxJellyfinReconciliationErrorLength {
+		truncated := (*errorMessage)[:maxJellyfinReconciliationErrorLength]
+		errorMessage = &truncated
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin finish jellyfin reconciliation run: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var autoResolved int64
+	if result.Status == models.JellyfinReconciliationRunStatusCompleted {
+		tag, err := tx.Exec(ctx, `
+			UPDATE jellyfin_reconciliation_findings
+			SET status = 'resolved',
+			    resolved_by_app_user_id = NULL,
+			    resolved_at = NOW()
+			WHERE status IN ('open', 'dismissed')
+			  AND last_run_id IS DISTINCT FROM $1
+		`, runID)
+		if err != nil {
+			return nil, fmt.Errorf("auto-resolve jellyfin findings run=%d: %w", runID, err)
+		}
+		autoResolved = tag.RowsAffected()
+	}
+
+	run, err := scanJellyfinReconciliationRun(tx.QueryRow(ctx, `
+		UPDATE jellyfin_reconciliation_runs
+		SET status = $2,
+		    anime_checked = $3,
+		    findings_detected = $4,
+		    findings_auto_resolved = $5,
+		    error_message = $6,
+		    finished_at = NOW()
+		WHERE id = $1
+		RETURNING `+jellyfinReconciliationRunColumns,
+		runID,
+		result.Status,
+		result.AnimeChecked,
+		result.FindingsDetected,
+		autoResolved,
+		errorMessage,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit finish jellyfin reconciliation run=%d: %w", runID, err)
+	}
+	return &run, nil
+}
+
+// ListRuns liefert die letzten Läufe, neueste zuerst.
+func (r *JellyfinReconciliationRepository) ListRuns(ctx context.Context, limit int) ([]models.JellyfinReconciliationRun, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+jellyfinReconciliationRunColumns+`
+		FROM jellyfin_reconciliation_runs r
+		ORDER BY r.started_at DESC, r.id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query jellyfin reconciliation runs: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.JellyfinReconciliationRun, 0)
+	for rows.Next() {
+		item, err := scanJellyfinReconciliationRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate jellyfin reconciliation runs: %w", err)
+	}
+	return items, nil
+}
+
+// UpsertFinding legt einen Befund an oder aktualisiert den offenen bzw. verworfenen Befund mit
+// demselben Fingerprint. Verworfene Befunde bleiben verworfen.
+func (r *JellyfinReconciliationRepository) UpsertFinding(
+	ctx context.Context,
+	runID int64,
+	input models.JellyfinReconciliationFindingInput,
+) error {
+	details, err := json.Marshal(input.Details)
+	if err != nil {
+		return fmt.Errorf("marshal jellyfin finding details: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, `
+		INSERT INTO jellyfin_reconciliation_findings AS f (
+			fingerprint, finding_type, anime_id, jellyfin_series_id, jellyfin_item_id,
+			release_variant_id, episode_number, details, first_run_id, last_run_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb, $9, $9)
+		ON CONFLICT (fingerprint) WHERE status IN ('open', 'dismissed') DO UPDATE SET
+			jellyfin_series_id = EXCLUDED.jellyfin_series_id,
+			jellyfin_item_id = EXCLUDED.jellyfin_item_id,
+			episode_number = EXCLUDED.episode_number,
+			details = EXCLUDED.details,
+			last_run_id = EXCLUDED.last_run_id,
+			seen_count = f.seen_count + 1,
+			last_seen_at = NOW()
+	`,
+		input.Fingerprint,
+		input.FindingType,
+		input.AnimeID,
+		input.JellyfinSeriesID,
+		input.JellyfinItemID,
+		input.ReleaseVariantID,
+		input.EpisodeNumber,
+		string(details),
+		runID,
+	); err != nil {
+		return fmt.Errorf("upsert jellyfin finding %s: %w", input.Fingerprint, err)
+	}
+	return nil
+}
+
+// ListFindings liefert Befunde, zuletzt gesehene zuerst, samt Gesamtzahl für die Pagination.
+func (r *JellyfinReconciliationRepository) ListFindings(
+	ctx context.Context,
+	filter models.JellyfinReconciliationFindingFilter,
+) ([]models.JellyfinReconciliationFinding, int64, error) {
+	const scope = `
+		FROM jellyfin_reconciliation_findings f
+		WHERE ($1 = '' OR f.status = $1)
+		  AND ($2 = '' OR f.finding_type = $2)
+		  AND ($3::bigint = 0 OR f.anime_id = $3)`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+scope, filter.Status, filter.FindingType, filter.AnimeID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count jellyfin findings: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT `+jellyfinReconciliationFindingColumns+`
+		`+scope+`
+		ORDER BY f.last_seen_at DESC, f.id DESC
+		LIMIT $4 OFFSET $5
+	`, filter.Status, filter.FindingType, filter.AnimeID, filter.PerPage, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query jellyfin findings: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.JellyfinReconciliationFinding, 0)
+	for rows.Next() {
+		item, err := scanJellyfinReconciliationFinding(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate jellyfin findings: %w", err)
+	}
+	return items, total, nil
+}
+
+// ResolveFindings setzt offene Befunde auf status (resolved oder dismissed) und liefert die
+// geänderten Befunde. Unbekannte oder bereits entschiedene IDs werden übersprungen.
+func (r *JellyfinReconciliationRepository) ResolveFindings(
+	ctx context.Context,
+	ids []int64,
+	status string,
+	actorAppUserID *int64,
+) ([]models.JellyfinReconciliationFinding, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE jellyfin_reconciliation_findings f
+		SET status = $2,
+		    resolved_by_app_user_id = $3,
+		    resolved_at = NOW()
+		WHERE f.id = ANY($1::bigint[]) AND f.status = 'open'
+		RETURNING `+jellyfinReconciliationFindingColumns,
+		ids,
+		status,
+		actorAppUserID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("resolve jellyfin findings: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.JellyfinReconciliationFinding, 0, len(ids))
+	for rows.Next() {
+		item, err := scanJellyfinReconciliationFinding(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate resolved jellyfin findings: %w", err)
+	}
+	return items, nil
+}
+
+// ListLinkedAnime liefert alle Anime mit Verknüpfung "jellyfin:<series-id>" über anime.source,
+// anime_source_links oder (V2 ohne source-Spalte) media_external.
+func (r *JellyfinReconciliationRepository) ListLinkedAnime(ctx context.Context) ([]models.JellyfinLinkedAnime, error) {
+	schema, err := loadAnimeV2SchemaInfo(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT anime.id, btrim(substr(anime.source, 10))
+		FROM anime
+		WHERE lower(anime.source) LIKE 'jellyfin:%'
+		UNION
+		SELECT asl.anime_id, btrim(substr(asl.source, 10))
+		FROM anime_source_links asl
+		WHERE lower(asl.source) LIKE 'jellyfin:%'
+		ORDER BY 1, 2
+	`
+	if !schema.HasSource {
+		query = `
+			SELECT DISTINCT am.anime_id, btrim(me.external_id)
+			FROM anime_media am
+			JOIN media_external me ON me.media_id = am.media_id
+			WHERE me.provider = 'jellyfin'
+			ORDER BY 1, 2
+		`
+	}
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query jellyfin linked anime: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.JellyfinLinkedAnime, 0)
+	for rows.Next() {
+		var item models.JellyfinLinkedAnime
+		if err := rows.Scan(&item.AnimeID, &item.SeriesID); err != nil {
+			return nil, fmt.Errorf("scan jellyfin linked anime: %w", err)
+		}
+		if item.SeriesID == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate jellyfin linked anime: %w", err)
+	}
+	return items, nil
+}
+
+// ListProviderVariants liefert die Release-Varianten einer Anime, deren Stream auf provider
+// (jellyfin oder emby) zeigt.
+func (r *JellyfinReconciliationRepository) ListProviderVariants(
+	ctx context.Context,
+	animeID int64,
+	provider string,
+) ([]models.JellyfinReconciliationVariant, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (rv.id, item_id)
+			rv.id,
+			e.anime_id,
+			e.episode_number,
+			COALESCE(ss.external_id, rs.jellyfin_item_id, '') AS item_id,
+			rv.duration_seconds
+		FROM release_variants rv
+		JOIN release_versions rev ON rev.id = rv.release_version_id
+		JOIN fansub_releases fr ON fr.id = rev.release_id
+		JOIN episodes e ON e.id = fr.episode_id
+		JOIN release_streams rs ON rs.variant_id = rv.id
+		JOIN stream_sources ss ON ss.id = rs.stream_source_id
+		WHERE e.anime_id = $1
+		  AND ss.provider_type = $2
+		ORDER BY rv.id, item_id
+	`, animeID, provider)
+	if err != nil {
+		return nil, fmt.Errorf("query provider variants anime=%d: %w", animeID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.JellyfinReconciliationVariant, 0)
+	for rows.Next() {
+		var item models.JellyfinReconciliationVariant
+		if err := rows.Scan(&item.VariantID, &item.AnimeID, &item.EpisodeNumber, &item.ItemID, &item.DurationSeconds); err != nil {
+			return nil, fmt.Errorf("scan provider variant anime=%d: %w", animeID, err)
+		}
+		if item.ItemID == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate provider variants anime=%d: %w", animeID, err)
+	}
+	return items, nil
+}
+
+const jellyfinReconciliationRunColumns = `
+	id, status, provider, library_ids, anime_checked, findings_detected,
+	findings_auto_resolved, error_message, started_at, finished_at`
+
+func scanJellyfinReconciliationRun(row pgx.Row) (models.JellyfinReconciliationRun, error) {
+	var item models.JellyfinReconciliationRun
+	if err := row.Scan(
+		&item.ID,
+		&item.Status,
+		&item.Provider,
+		&item.LibraryIDs,
+		&item.AnimeChecked,
+		&item.FindingsDetected,
+		&item.FindingsAutoResolved,
+		&item.ErrorMessage,
+		&item.StartedAt,
+		&item.FinishedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.JellyfinReconciliationRun{}, ErrNotFound
+		}
+		return models.JellyfinReconciliationRun{}, fmt.Errorf("scan jellyfin reconciliation run: %w", err)
+	}
+	return item, nil
+}
+
+const jellyfinReconciliationFindingColumns = `
+	f.id, f.finding_type, f.anime_id, f.jellyfin_series_id, f.jellyfin_item_id,
+	f.release_variant_id, f.episode_number, f.details, f.status, f.first_run_id, f.last_run_id,
+	f.seen_count, f.resolved_by_app_user_id, f.resolved_at, f.first_seen_at, f.last_seen_at`
+
+func scanJellyfinReconciliationFinding(row pgx.Row) (models.JellyfinReconciliationFinding, error) {
+	var item models.JellyfinReconciliationFinding
+	var details []byte
+	if err := row.Scan(
+		&item.ID,
+		&item.FindingType,
+		&item.AnimeID,
+		&item.JellyfinSeriesID,
+		&item.JellyfinItemID,
+		&item.ReleaseVariantID,
+		&item.EpisodeNumber,
+		&details,
+		&item.Status,
+		&item.FirstRunID,
+		&item.LastRunID,
+		&item.SeenCount,
+		&item.ResolvedByAppUserID,
+		&item.ResolvedAt,
+		&item.FirstSeenAt,
+		&item.LastSeenAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.JellyfinReconciliationFinding{}, ErrNotFound
+		}
+		return models.JellyfinReconciliationFinding{}, fmt.Errorf("scan jellyfin finding: %w", err)
+	}
+	if len(details) > 0 {
+		item.Details = details
+	}
+	return item, nil
+}
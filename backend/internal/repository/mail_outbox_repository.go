@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxMailOutboxErrorLength begrenzt die gespeicherte Fehlermeldung des letzten Versuchs.
+const maxMailOutboxErrorLength = 1000
+
+// MailOutboxRepository verwaltet den transaktionalen Mail-Outbox. Fachliche Repositories
+// reihen Mails über enqueueMailOutbox in ihrer eigenen Transaktion ein; der Worker beansprucht
+// fällige Einträge mit FOR UPDATE SKIP LOCKED, damit mehrere Instanzen parallel laufen können.
+type MailOutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMailOutboxRepository(db *pgxpool.Pool) *MailOutboxRepository {
+	return &MailOutboxRepository{db: db}
+}
+
+// Enqueue reiht eine Mail außerhalb einer fachlichen Transaktion ein.
+func (r *MailOutboxRepository) Enqueue(ctx context.Context, input models.MailOutboxInput) (int64, error) {
+	return enqueueMailOutbox(ctx, r.db, input)
+}
+
+// ClaimDue beansprucht bis zu limit fällige Mails, setzt sie auf sending und erhöht attempts.
+// Einträge, deren Lease (locked_until) abgelaufen ist, gelten wieder als fällig.
+func (r *MailOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.MailOutboxMessage, error) {
+	if limit <= 0 {
+		return []models.MailOutboxMessage{}, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		UPDATE mail_outbox o
+		SET status = 'sending',
+		    attempts = o.attempts + 1,
+		    locked_until = NOW() + $2 * INTERVAL '1 second',
+		    updated_at = NOW()
+		FROM (
+			SELECT id
+			FROM mail_outbox
+			WHERE (status = 'pending' AND next_attempt_at <= NOW())
+			   OR (status = 'sending' AND locked_until < NOW())
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE o.id = due.id
+		RETURNING o.id, o.template_name, o.locale, o.recipient, o.template_data, o.attempts, o.max_attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim due mail outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.MailOutboxMessage, 0, limit)
+	for rows.Next() {
+		var message models.MailOutboxMessage
+		var data []byte
+		if err := rows.Scan(
+			&message.ID,
+			&message.TemplateName,
+			&message.Locale,
+			&message.Recipient,
+			&data,
+			&message.Attempts,
+			&message.MaxAttempts,
+		); err != nil {
+			return nil, fmt.Errorf("scan mail outbox entry: %w", err)
+		}
+		message.TemplateData = map[string]any{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &message.TemplateData); err != nil {
+				return nil, fmt.Errorf("decode mail outbox data %d: %w", message.ID, err)
+			}
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate claimed mail outbox entries: %w", err)
+	}
+
+	return messages, nil
+}
+
+// MarkSent schließt einen Eintrag ab. Bei redact_after_send werden die Template-Daten geleert.
+func (r *MailOutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	if _, err := r.db.Exec(ctx, `
+		UPDATE mail_outbox
+		SET status = 'sent',
+		    sent_at = NOW(),
+		    locked_until = NULL,
+		    last_error = NULL,
+		    template_data = CASE WHEN redact_after_send THEN '{}'::jsonb ELSE template_data END,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id); err != nil {
+		return fmt.Errorf("mark mail outbox entry %d sent: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed speichert den Fehler des letzten Versuchs. nextAttemptAt = nil verschiebt den
+// Eintrag in den Status dead; bei redact_after_send werden die Template-Daten dann ebenfalls
+// geleert, damit z.B. ein Einladungs-Token nicht unbegrenzt in toten Einträgen liegen bleibt.
+func (r *MailOutboxRepository) MarkFailed(ctx context.Context, id int64, sendErr string, nextAttemptAt *time.Time) error {
+	status := models.MailOutboxStatusPending
+	if nextAttemptAt == nil {
+		status = models.MailOutboxStatusDead
+	}
+
+	if _, err := r.db.Exec(ctx, `
+		UPDATE mail_outbox
+		SET status = $2,
+		    last_error = $3,
+		    next_attempt_at = COALESCE($4, next_attempt_at),
+		    locked_until = NULL,
+		    template_data = CASE WHEN $2 = 'dead' AND redact_after_send THEN '{}'::jsonb ELSE template_data END,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, status, truncateMailOutboxError(sendErr), nextAttemptAt); err != nil {
+		return fmt.Errorf("mark mail outbox entry %d failed: %w", id, err)
+	}
+	return nil
+}
+
+// List liefert die Admin-Sicht des Outbox, neueste zuerst.
+func (r *MailOutboxRepository) List(ctx context.Context, filter models.MailOutboxFilter) ([]models.MailOutboxItem, int64, error) {
+	const scope = `
+		FROM mail_outbox o
+		WHERE ($1 = '' OR o.status = $1)`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+scope, filter.Status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count mail outbox entries: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT `+mailOutboxItemColumns+`
+		`+scope+`
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT $2 OFFSET $3
+	`, filter.Status, filter.PerPage, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query mail outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.MailOutboxItem, 0)
+	for rows.Next() {
+		item, err := scanMailOutboxItem(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate mail outbox entries: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// Requeue reiht eine tote oder bereits gesendete Mail erneut ein. Mails mit geleerten
+// Template-Daten (redact_after_send) können nach Versand oder Abbruch nicht erneut gesendet
+// werden und liefern ErrConflict, ebenso Einträge, die noch in Bearbeitung sind.
+func (r *MailOutboxRepository) Requeue(ctx context.Context, id int64) (*models.MailOutboxItem, error) {
+	row := r.db.QueryRow(ctx, `
+		UPDATE mail_outbox o
+		SET status = 'pending',
+		    attempts = 0,
+		    next_attempt_at = NOW(),
+		    locked_until = NULL,
+		    last_error = NULL,
+		    sent_at = NULL,
+		    updated_at = NOW()
+		WHERE o.id = $1
+		  AND o.status IN ('dead', 'sent')
+		  AND NOT o.redact_after_send
+		RETURNING `+mailOutboxItemColumns, id)
+
+	item, err := scanMailOutboxItem(row)
+	if err == nil {
+		return &item, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM mail_outbox WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check mail outbox entry %d: %w", id, err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return nil, ErrConflict
+}
+
+const mailOutboxItemColumns = `
+	o.id, o.template_name, o.locale, o.recipient, o.status, o.attempts, o.max_attempts,
+	o.next_attempt_at, o.last_error, o.sent_at, (o.redact_after_send AND o.status IN ('sent', 'dead')),
+	o.reference_type, o.reference_id, o.created_at, o.updated_at`
+
+func scanMailOutboxItem(row pgx.Row) (models.MailOutboxItem, error) {
+	var item models.MailOutboxItem
+	if err := row.Scan(
+		&item.ID,
+		&item.TemplateName,
+		&item.Locale,
+		&item.Recipient,
+		&item.Status,
+		&item.Attempts,
+		&item.MaxAttempts,
+		&item.NextAttemptAt,
+		&item.LastError,
+		&item.SentAt,
+		&item.Redacted,
+		&item.ReferenceType,
+		&item.ReferenceID,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.MailOutboxItem{}, ErrNotFound
+		}
+		return models.MailOutboxItem{}, fmt.Errorf("scan mail outbox item: %w", err)
+	}
+	return item, nil
+}
+
+// enqueueMailOutbox schreibt eine Mail in den Outbox. q ist typischerweise die Transaktion
+// der fachlichen Änderung, damit Mail und Änderung gemeinsam committet werden.
+func enqueueMailOutbox(ctx context.Context, q querier, input models.MailOutboxInput) (int64, error) {
+	templateName := strings.TrimSpace(input.TemplateName)
+	recipient := strings.TrimSpace(input.Recipient)
+	if templateName == "" || recipient == "" {
+		return 0, fmt.Errorf("enqueue mail: template and recipient are required")
+	}
+
+	locale := strings.ToLower(strings.TrimSpace(input.Locale))
+	if !models.IsMailLocale(locale) {
+		locale = models.DefaultMailLocale
+	}
+
+	data := input.TemplateData
+	if data == nil {
+		data = map[string]any{}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue mail: encode template data: %w", err)
+	}
+
+	var id int64
+	if err := q.QueryRow(ctx, `
+		INSERT INTO mail_outbox (
+			template_name, locale, recipient, template_data, redact_after_send, reference_type, reference_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, templateName, locale, recipient, encoded, input.RedactAfterSend, input.ReferenceType, input.ReferenceID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("enqueue mail %q: %w", templateName, err)
+	}
+
+	return id, nil
+}
+
+// mergeMailTemplateData ergänzt die Template-Daten des Aufrufers um Werte des Repositories.
+func mergeMailTemplateData(base map[string]any, extra map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(extra))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged
+}
+
+func truncateMailOutboxError(message string) string {
+	runes := []rune(strings.TrimSpace(message))
+	if len(runes) <= maxMailOutboxErrorLength {
+		return string(runes)
+	}
+	return string(runes[:maxMailOutboxErrorLength])
+}
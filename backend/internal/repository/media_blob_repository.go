@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// ensureMediaBlob legt den Blob-Eintrag für inhaltsadressierte Uploads an oder liefert den
+// vorhandenen. Ohne ContentSHA256 (Altpfade, Quelloriginale, Videos) bleibt blob_id NULL.
+func (r *MediaRepository) ensureMediaBlob(ctx context.Context, q querier, input models.MediaAssetCreateInput) (*int64, error) {
+	sha := strings.ToLower(strings.TrimSpace(input.ContentSHA256))
+	if sha == "" {
+		return nil, nil
+	}
+
+	var blobID int64
+	// DO UPDATE statt DO NOTHING, damit RETURNING auch bei vorhandenem Blob eine Zeile liefert.
+	// Innerhalb einer Transaktion (CreateMediaAssetWithBlob, CreateMediaAssetWithStatusTx) bleibt
+	// die Zeile damit bis zum Commit gegen ReleaseMediaBlobFile gesperrt.
+	if err := q.QueryRow(ctx, `
+		INSERT INTO media_blobs (sha256, storage_path, mime_type, size_bytes, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (sha256) DO UPDATE SET sha256 = EXCLUDED.sha256
+		RETURNING id
+	`, sha, strings.TrimSpace(input.StoragePath), input.MimeType, input.SizeBytes).Scan(&blobID); err != nil {
+		return nil, fmt.Errorf("ensure media blob %s: %w", sha, err)
+	}
+	return &blobID, nil
+}
+
+// ReleaseMediaBlobFile prüft, ob storagePath nach dem Löschen eines Assets noch referenziert wird.
+// Liegt dort ein geteilter Blob, wird dessen Zeile erst entfernt, wenn kein media_assets-Eintrag
+// mehr darauf zeigt. Die Blob-Zeile bleibt dabei per FOR UPDATE gesperrt, bis removeFile die
+// Datei gelöscht hat; parallele Uploads desselben Inhalts warten so auf den Commit und schreiben
+// die Datei danach neu. true bedeutet: die Datei wurde entfernt.
+func (r *MediaRepository) ReleaseMediaBlobFile(
+	ctx context.Context,
+	storagePath string,
+	removeFile func(ctx context.Context) error,
+) (bool, error) {
+	trimmed := strings.TrimSpace(storagePath)
+	if trimmed == "" {
+		return false, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("release media blob %q: begin tx: %w", trimmed, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Altbestand ohne Blob-Zeile sperrt nichts; dort gibt es keine Deduplizierung.
+	if _, err := tx.Exec(ctx, `SELECT id FROM media_blobs WHERE storage_path = $1 FOR UPDATE`, trimmed); err != nil {
+		return false, fmt.Errorf("lock media blob %q: %w", trimmed, err)
+	}
+
+	var referenced bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM media_assets ma
+			WHERE ma.file_path = $1
+			   OR ma.blob_id IN (SELECT b.id FROM media_blobs b WHERE b.storage_path = $1)
+		)
+	`, trimmed).Scan(&referenced); err != nil {
+		return false, fmt.Errorf("check media blob references %q: %w", trimmed, err)
+	}
+	if referenced {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM media_blobs WHERE storage_path = $1`, trimmed); err != nil {
+		if isForeignKeyViolation(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("delete media blob %q: %w", trimmed, err)
+	}
+	if removeFile != nil {
+		if err := removeFile(ctx); err != nil {
+			return false, fmt.Errorf("remove media blob file %q: %w", trimmed, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("release media blob %q: commit: %w", trimmed, err)
+	}
+	return true, nil
+}
+
+// ListMediaAssetFiles liefert alle Media-Assets mit lesbarem Dateipfad für den Dedupe-Report.
+func (r *MediaRepository) ListMediaAssetFiles(ctx context.Context) ([]models.MediaAssetFile, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, file_path, mime_type, blob_id
+		FROM media_assets
+		WHERE BTRIM(file_path) <> ''
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list media asset files: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.MediaAssetFile, 0)
+	for rows.Next() {
+		var item models.MediaAssetFile
+		if err := rows.Scan(&item.MediaAssetID, &item.StoragePath, &item.MimeType, &item.BlobID); err != nil {
+			return nil, fmt.Errorf("scan media asset file: %w", err)
+		}
+		item.StoragePath = r.resolveMediaAssetDiskPath(item.StoragePath)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate media asset files: %w", err)
+	}
+	return items, nil
+}
+
+// RegisterMediaBlob erfasst eine bereits vorhandene Datei als Blob und verknüpft die angegebenen
+// Assets ohne blob_id damit. Ist der Inhalt schon unter einem anderen Pfad als Blob erfasst, wird
+// nichts verknüpft. Liefert die Anzahl neu verknüpfter Assets.
+func (r *MediaRepository) RegisterMediaBlob(ctx context.Context, blob models.MediaAssetCreateInput, mediaAssetIDs []int64) (int, error) {
+	if strings.TrimSpace(blob.ContentSHA256) == "" || strings.TrimSpace(blob.StoragePath) == "" {
+		return 0, fmt.Errorf("register media blob: sha256 und storage path sind erforderlich")
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("register media blob: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	blobID, err := r.ensureMediaBlob(ctx, tx, blob)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrConflict
+		}
+		return 0, err
+	}
+
+	linked := 0
+	if len(mediaAssetIDs) > 0 {
+		tag, err := tx.Exec(ctx, `
+			UPDATE media_assets
+			SET blob_id = $1
+			WHERE id = ANY($2)
+			  AND blob_id IS NULL
+			  AND EXISTS (SELECT 1 FROM media_blobs b WHERE b.id = $1 AND b.storage_path = $3)
+		`, *blobID, mediaAssetIDs, strings.TrimSpace(blob.StoragePath))
+		if err != nil {
+			return 0, fmt.Errorf("register media blob: link assets: %w", err)
+		}
+		linked = int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("register media blob: commit: %w", err)
+	}
+	return linked, nil
+}
+
+// ListStoredMediaPaths liefert alle unterschiedlichen Dateipfade aus media_assets.file_path,
+// media_files.path und media_blobs.storage_path für die Bucket-Migration.
+func (r *MediaRepository) ListStoredMediaPaths(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT file_path FROM media_assets WHERE BTRIM(file_path) <> ''
+		UNION
+		SELECT path FROM media_files WHERE BTRIM(path) <> ''
+		UNION
+		SELECT storage_path FROM media_blobs WHERE BTRIM(storage_path) <> ''
+		ORDER BY 1
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list stored media paths: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]string, 0)
+	for rows.Next() {
+		var item string
+		if err := rows.Scan(&item); err != nil {
+			return nil, fmt.Errorf("scan stored media path: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate stored media paths: %w", err)
+	}
+	return items, nil
+}
+
+// RewriteStoredMediaPath ersetzt oldPath in allen drei Pfadspalten transaktional durch newPath.
+// Liegt unter newPath bereits ein anderer Blob, wird ErrConflict geliefert. Liefert die Anzahl
+// geänderter Zeilen.
+func (r *MediaRepository) RewriteStoredMediaPath(ctx context.Context, oldPath string, newPath string) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("rewrite stored media path: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var rewritten int64
+	for _, statement := range []string{
+		`UPDATE media_assets SET file_path = $2 WHERE file_path = $1`,
+		`UPDATE media_files SET path = $2 WHERE path = $1`,
+		`UPDATE media_blobs SET storage_path = $2 WHERE storage_path = $1`,
+	} {
+		tag, err := tx.Exec(ctx, statement, oldPath, newPath)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return 0, ErrConflict
+			}
+			return 0, fmt.Errorf("rewrite stored media path %q: %w", oldPath, err)
+		}
+		rewritten += tag.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("rewrite stored media path: commit: %w", err)
+	}
+	return rewritten, nil
+}
+
+// resolveMediaAssetDiskPath ergänzt resolveReadableStoragePath um relative "/media/..."-Pfade
+// (Theme-, Segment- und Trickplay-Assets), die unterhalb des Storage-Verzeichnisses liegen.
+func (r *MediaRepository) resolveMediaAssetDiskPath(raw string) string {
+	resolved := r.resolveReadableStoragePath(raw)
+	if _, err := os.Stat(resolved); err == nil || strings.TrimSpace(r.storageDir) == "" {
+		return resolved
+	}
+	relative := strings.TrimPrefix(strings.TrimSpace(raw), "/media/")
+	if relative == strings.TrimSpace(raw) || relative == "" || strings.Contains(relative, "..") {
+		return resolved
+	}
+	return filepath.Join(r.storageDir, filepath.FromSlash(relative))
+}
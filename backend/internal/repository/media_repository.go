@@ -120,6 +120,18 @@ func NewMediaRepository(db *pgxpool.Pool, publicBaseURL string, storageDir ...st
 func (r *MediaRepository) CreateMediaAsset(
 	ctx context.Context,
 	input models.MediaAssetCreateInput,
+) (*models.MediaAsset, error) {
+	return r.CreateMediaAssetWithBlob(ctx, input, nil)
+}
+
+// CreateMediaAssetWithBlob legt das Asset in einer Transaktion an. Die media_blobs-Zeile wird
+// zuerst angelegt bzw. gesperrt; erst danach prüft ensureStored (optional), ob die Blob-Datei
+// noch vorhanden ist. So kann ein paralleles ReleaseMediaBlobFile die Datei nicht zwischen
+// Prüfung und Anlage des Assets entfernen.
+func (r *MediaRepository) CreateMediaAssetWithBlob(
+	ctx context.Context,
+	input models.MediaAssetCreateInput,
+	ensureStored func(ctx context.Context) error,
 ) (*models.MediaAsset, error) {
 	filename := strings.TrimSpace(input.Filename)
 	storagePath := strings.TrimSpace(input.StoragePath)
@@ -135,8 +147,14 @@ func (r *MediaRepository) CreateMediaAsset(
 		return nil, err
 	}
 
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create media asset: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var mediaTypeID int64
-	if err := r.db.QueryRow(ctx, `
+	if err := tx.QueryRow(ctx, `
 		SELECT id
 		FROM media_types
 		WHERE name = $1
@@ -146,19 +164,29 @@ func (r *MediaRepository) CreateMediaAsset(
 	}
 
 	publicURL := r.buildPublicURL(filename)
+	blobID, err := r.ensureMediaBlob(ctx, tx, input)
+	if err != nil {
+		return nil, fmt.Errorf("create media asset: %w", err)
+	}
+	if ensureStored != nil {
+		if err := ensureStored(ctx); err != nil {
+			return nil, fmt.Errorf("create media asset: ensure blob file: %w", err)
+		}
+	}
 	var item models.MediaAsset
 	if input.VisibilityCode != nil && input.ReviewStatusCode != nil {
 		// Sub-SELECT-INSERT: visibility_id und review_status_id per Lookup-Tabellen aufgelöst (Lock K)
-		if err := r.db.QueryRow(ctx, `
+		if err := tx.QueryRow(ctx, `
 			INSERT INTO media_assets (media_type_id, file_path, mime_type, format,
-				visibility_id, review_status_id, created_at)
+				visibility_id, review_status_id, blob_id, created_at)
 			VALUES ($1, $2, $3, $4,
 				(SELECT id FROM visibilities WHERE name = $5 LIMIT 1),
 				(SELECT id FROM review_statuses WHERE code = $6 LIMIT 1),
+				$7,
 				NOW())
 			RETURNING id, file_path, mime_type, created_at
 		`, mediaTypeID, storagePath, input.MimeType, mediaFormatForKind(input.Kind),
-			*input.VisibilityCode, *input.ReviewStatusCode).Scan(
+			*input.VisibilityCode, *input.ReviewStatusCode, blobID).Scan(
 			&item.ID,
 			&item.StoragePath,
 			&item.MimeType,
@@ -170,11 +198,11 @@ func (r *MediaRepository) CreateMediaAsset(
 			return nil, fmt.Errorf("create media asset: %w", err)
 		}
 	} else {
-		if err := r.db.QueryRow(ctx, `
-			INSERT INTO media_assets (media_type_id, file_path, mime_type, format, created_at)
-			VALUES ($1, $2, $3, $4, NOW())
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO media_assets (media_type_id, file_path, mime_type, format, blob_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())
 			RETURNING id, file_path, mime_type, created_at
-		`, mediaTypeID, storagePath, input.MimeType, mediaFormatForKind(input.Kind)).Scan(
+		`, mediaTypeID, storagePath, input.MimeType, mediaFormatForKind(input.Kind), blobID).Scan(
 			&item.ID,
 			&item.StoragePath,
 			&item.MimeType,
@@ -187,6 +215,10 @@ func (r *MediaRepository) CreateMediaAsset(
 		}
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("create media asset: commit: %w", err)
+	}
+
 	item.Filename = filename
 	item.PublicURL = publicURL
 	item.SizeBytes = input.SizeBytes
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotificationDigestRepository speichert die Digest-Einstellungen und sammelt die Inhalte
+// eines Digest-Zeitfensters (neue Release-Versionen und Antworten in eigenen Threads).
+type NotificationDigestRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationDigestRepository(db *pgxpool.Pool) *NotificationDigestRepository {
+	return &NotificationDigestRepository{db: db}
+}
+
+// GetSettings liefert die Digest-Einstellung eines Nutzers; ohne Eintrag gilt off.
+func (r *NotificationDigestRepository) GetSettings(ctx context.Context, appUserID int64) (*models.DigestSettings, error) {
+	settings, err := scanDigestSettings(r.db.QueryRow(ctx, `
+		SELECT frequency, locale, last_sent_at, next_due_at
+		FROM notification_digest_settings
+		WHERE app_user_id = $1
+	`, appUserID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &models.DigestSettings{Frequency: models.DigestFrequencyOff, Locale: models.DefaultMailLocale}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get digest settings for app user %d: %w", appUserID, err)
+	}
+	return settings, nil
+}
+
+// UpdateSettings legt die Einstellung an oder ändert sie. Der Abmelde-Token wird beim ersten
+// Anlegen erzeugt und bleibt danach stabil, damit Links in älteren Mails gültig bleiben.
+func (r *NotificationDigestRepository) UpdateSettings(
+	ctx context.Context,
+	appUserID int64,
+	update models.DigestSettingsUpdate,
+) (*models.DigestSettings, error) {
+	token, err := generateDigestUnsubscribeToken()
+	if err != nil {
+		return nil, fmt.Errorf("update digest settings for app user %d: generate token: %w", appUserID, err)
+	}
+
+	settings, err := scanDigestSettings(r.db.QueryRow(ctx, `
+		INSERT INTO notification_digest_settings (app_user_id, frequency, locale, unsubscribe_token, next_due_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (app_user_id) DO UPDATE
+		SET frequency = EXCLUDED.frequency,
+			locale = EXCLUDED.locale,
+			next_due_at = EXCLUDED.next_due_at,
+			updated_at = NOW()
+		RETURNING frequency, locale, last_sent_at, next_due_at
+	`, appUserID, update.Frequency, update.Locale, token, update.NextDueAt))
+	if err != nil {
+		return nil, fmt.Errorf("update digest settings for app user %d: %w", appUserID, err)
+	}
+	return settings, nil
+}
+
+// Unsubscribe stellt den Digest zum Abmelde-Token auf off. Unbekannte Tokens liefern ErrNotFound.
+func (r *NotificationDigestRepository) Unsubscribe(ctx context.Context, token string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE notification_digest_settings
+		SET frequency = 'off',
+			next_due_at = NULL,
+			updated_at = NOW()
+		WHERE unsubscribe_token = $1
+	`, strings.TrimSpace(token))
+	if err != nil {
+		return fmt.Errorf("unsubscribe digest: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListDue liefert bis zu limit fällige Digest-Empfänger mit aktivem Account und E-Mail-Adresse.
+func (r *NotificationDigestRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.DigestSubscription, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.app_user_id, u.email, s.frequency, s.locale, s.unsubscribe_token, s.last_sent_at, s.next_due_at
+		FROM notification_digest_settings s
+		JOIN app_users u ON u.id = s.app_user_id
+		WHERE s.frequency <> 'off'
+		  AND s.next_due_at <= $1
+		  AND u.status <> 'disabled'
+		  AND u.email <> ''
+		ORDER BY s.next_due_at ASC, s.app_user_id ASC
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query due digests: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := make([]models.DigestSubscription, 0)
+	for rows.Next() {
+		var sub models.DigestSubscription
+		if err := rows.Scan(
+			&sub.AppUserID,
+			&sub.Email,
+			&sub.Frequency,
+			&sub.Locale,
+			&sub.UnsubscribeToken,
+			&sub.LastSentAt,
+			&sub.NextDueAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan due digest: %w", err)
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate due digests: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// LoadContent sammelt die Inhalte im Zeitfenster (since, until]: neue Release-Versionen für
+// Anime auf der Watchlist (ohne dropped) oder von gefolgten Gruppen sowie sichtbare Antworten
+// anderer Nutzer in Threads, die der Nutzer begonnen hat. Je Abschnitt höchstens limit Einträge.
+func (r *NotificationDigestRepository) LoadContent(
+	ctx context.Context,
+	appUserID int64,
+	since time.Time,
+	until time.Time,
+	limit int,
+) (models.DigestContent, error) {
+	content := models.DigestContent{
+		ReleaseVersions: []models.DigestReleaseVersion{},
+		Comments:        []models.CommentListItem{},
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT rv.id, a.id, a.title, e.id, COALESCE(e.episode_number, ''), rv.version,
+			COALESCE(string_agg(DISTINCT fg.name, ', '), ''), rv.created_at
+		FROM release_versions rv
+		JOIN fansub_releases fr ON fr.id = rv.release_id
+		JOIN episodes e ON e.id = fr.episode_id
+		JOIN anime a ON a.id = e.anime_id
+		LEFT JOIN release_version_groups rvg ON rvg.release_version_id = rv.id
+		LEFT JOIN fansub_groups fg ON fg.id = rvg.fansub_group_id
+		WHERE rv.created_at > $2
+		  AND rv.created_at <= $3
+		  AND a.status <> 'disabled'
+		  AND (
+			EXISTS (
+				SELECT 1
+				FROM watchlist_entries w
+				JOIN app_users u ON u.legacy_user_id = w.user_id
+				WHERE u.id = $1
+				  AND w.anime_id = a.id
+				  AND w.list_status <> 'dropped'
+			)
+			OR EXISTS (
+				SELECT 1
+				FROM release_version_groups g
+				JOIN fansub_group_follows f ON f.fansub_group_id = g.fansub_group_id
+				WHERE g.release_version_id = rv.id
+				  AND f.app_user_id = $1
+			)
+		  )
+		GROUP BY rv.id, a.id, e.id
+		ORDER BY rv.created_at ASC, rv.id ASC
+		LIMIT $4
+	`, appUserID, since, until, limit)
+	if err != nil {
+		return content, fmt.Errorf("query digest release versions for app user %d: %w", appUserID, err)
+	}
+	for rows.Next() {
+		var item models.DigestReleaseVersion
+		if err := rows.Scan(
+			&item.ReleaseVersionID,
+			&item.AnimeID,
+			&item.AnimeTitle,
+			&item.EpisodeID,
+			&item.EpisodeNumber,
+			&item.Version,
+			&item.GroupNames,
+			&item.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return content, fmt.Errorf("scan digest release version: %w", err)
+		}
+		content.ReleaseVersions = append(content.ReleaseVersions, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return content, fmt.Errorf("iterate digest release versions: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments c
+		JOIN comments root ON root.id = c.root_id
+		JOIN app_users u ON u.id = $1
+		WHERE root.author_user_id = u.legacy_user_id
+		  AND c.created_at > $2
+		  AND c.created_at <= $3
+		  AND c.deleted_at IS NULL
+		  AND c.moderation_status = 'visible'
+		  AND c.author_user_id IS DISTINCT FROM u.legacy_user_id
+		ORDER BY c.created_at ASC, c.id ASC
+		LIMIT $4
+	`, appUserID, since, until, limit)
+	if err != nil {
+		return content, fmt.Errorf("query digest comments for app user %d: %w", appUserID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item, err := scanComment(rows)
+		if err != nil {
+			return content, fmt.Errorf("scan digest comment: %w", err)
+		}
+		content.Comments = append(content.Comments, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return content, fmt.Errorf("iterate digest comments: %w", err)
+	}
+
+	return content, nil
+}
+
+// Complete schließt das Zeitfenster eines Digest-Laufs ab: last_sent_at und next_due_at werden
+// fortgeschrieben und die Mail (falls vorhanden) in derselben Transaktion eingereiht.
+// Hat ein paralleler Lauf oder eine Einstellungsänderung next_due_at bereits verändert,
+// passiert nichts und es wird false geliefert.
+func (r *NotificationDigestRepository) Complete(
+	ctx context.Context,
+	sub models.DigestSubscription,
+	windowEnd time.Time,
+	nextDueAt time.Time,
+	mail *models.MailOutboxInput,
+) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("complete digest for app user %d: begin tx: %w", sub.AppUserID, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE notification_digest_settings
+		SET last_sent_at = $3,
+			next_due_at = $4
+		WHERE app_user_id = $1
+		  AND next_due_at = $2
+		  AND frequency <> 'off'
+	`, sub.AppUserID, sub.NextDueAt, windowEnd, nextDueAt)
+	if err != nil {
+		return false, fmt.Errorf("complete digest for app user %d: %w", sub.AppUserID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if mail != nil {
+		if _, err := enqueueMailOutbox(ctx, tx, *mail); err != nil {
+			return false, fmt.Errorf("complete digest for app user %d: %w", sub.AppUserID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("complete digest for app user %d: commit: %w", sub.AppUserID, err)
+	}
+	return true, nil
+}
+
+func scanDigestSettings(row pgx.Row) (*models.DigestSettings, error) {
+	var settings models.DigestSettings
+	if err := row.Scan(&settings.Frequency, &settings.Locale, &settings.LastSentAt, &settings.NextDueAt); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// generateDigestUnsubscribeToken erzeugt den Abmelde-Token. Er wird im Klartext gespeichert,
+// weil er in jeder Digest-Mail erneut verlinkt wird und nur das Abbestellen erlaubt.
+func generateDigestUnsubscribeToken() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
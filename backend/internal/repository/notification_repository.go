@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotificationRepository speichert Benachrichtigungen und Zustellpräferenzen und löst die
+// Empfänger der veröffentlichten Ereignisse auf.
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// LoadRecipients liefert für die aktiven App-User den Kanal des Ereignistyps (ohne Präferenz
+// gilt models.DefaultNotificationChannel) und die E-Mail-Adresse. Deaktivierte oder
+// unbekannte Accounts werden ausgelassen.
+func (r *NotificationRepository) LoadRecipients(
+	ctx context.Context,
+	appUserIDs []int64,
+	eventType string,
+) ([]models.NotificationRecipient, error) {
+	if len(appUserIDs) == 0 {
+		return []models.NotificationRecipient{}, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, COALESCE(p.channel, $3), u.email
+		FROM app_users u
+		LEFT JOIN notification_preferences p
+			ON p.app_user_id = u.id
+		   AND p.event_type = $2
+		WHERE u.id = ANY($1)
+		  AND u.status <> 'disabled'
+		ORDER BY u.id ASC
+	`, appUserIDs, eventType, models.DefaultNotificationChannel)
+	if err != nil {
+		return nil, fmt.Errorf("query notification recipients for %s: %w", eventType, err)
+	}
+	defer rows.Close()
+
+	recipients := make([]models.NotificationRecipient, 0, len(appUserIDs))
+	for rows.Next() {
+		var recipient models.NotificationRecipient
+		if err := rows.Scan(&recipient.AppUserID, &recipient.Channel, &recipient.Email); err != nil {
+			return nil, fmt.Errorf("scan notification recipient: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification recipients: %w", err)
+	}
+
+	return recipients, nil
+}
+
+// CreateForUsers legt dieselbe Benachrichtigung für mehrere Empfänger an und reiht die
+// E-Mails des email-Kanals in derselben Transaktion in den Mail-Outbox ein.
+func (r *NotificationRepository) CreateForUsers(
+	ctx context.Context,
+	event models.NotificationEvent,
+	appUserIDs []int64,
+	mails []models.MailOutboxInput,
+) error {
+	if len(appUserIDs) == 0 && len(mails) == 0 {
+		return nil
+	}
+
+	payload := event.Payload
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal notification payload: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("insert notifications %s: begin tx: %w", event.EventType, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if len(appUserIDs) > 0 {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO notifications (app_user_id, event_type, title, body, link_url, payload, actor_app_user_id)
+			SELECT recipient, $2, $3, $4, $5, $6::jsonb, $7
+			FROM UNNEST($1::bigint[]) AS recipient
+		`, appUserIDs, event.EventType, event.Title, event.Body, event.LinkURL, string(encoded), event.ActorAppUserID); err != nil {
+			return fmt.Errorf("insert notifications %s: %w", event.EventType, err)
+		}
+	}
+	for _, mail := range mails {
+		if _, err := enqueueMailOutbox(ctx, tx, mail); err != nil {
+			return fmt.Errorf("insert notifications %s: %w", event.EventType, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("insert notifications %s: commit: %w", event.EventType, err)
+	}
+	return nil
+}
+
+// ListForUser paginiert die Benachrichtigungen eines Nutzers (neueste zuerst).
+func (r *NotificationRepository) ListForUser(
+	ctx context.Context,
+	appUserID int64,
+	filter models.NotificationFilter,
+) ([]models.Notification, int64, error) {
+	const scope = `
+		FROM notifications n
+		WHERE n.app_user_id = $1
+		  AND (NOT $2::bool OR n.read_at IS NULL)`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+scope, appUserID, filter.UnreadOnly).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count notifications for app user %d: %w", appUserID, err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT n.id, n.event_type, n.title, n.body, n.link_url, n.payload, n.read_at, n.created_at
+		`+scope+`
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $3 OFFSET $4
+	`, appUserID, filter.UnreadOnly, filter.PerPage, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query notifications for app user %d: %w", appUserID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.Notification, 0)
+	for rows.Next() {
+		var item models.Notification
+		var payload []byte
+		if err := rows.Scan(
+			&item.ID,
+			&item.EventType,
+			&item.Title,
+			&item.Body,
+			&item.LinkURL,
+			&payload,
+			&item.ReadAt,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan notification: %w", err)
+		}
+		item.Payload = map[string]any{}
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &item.Payload); err != nil {
+				return nil, 0, fmt.Errorf("decode notification payload %d: %w", item.ID, err)
+			}
+		}
+		item.IsRead = item.ReadAt != nil
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate notifications: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// CountUnread zählt die ungelesenen Benachrichtigungen eines Nutzers.
+func (r *NotificationRepository) CountUnread(ctx context.Context, appUserID int64) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE app_user_id = $1
+		  AND read_at IS NULL
+	`, appUserID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count unread notifications for app user %d: %w", appUserID, err)
+	}
+	return count, nil
+}
+
+// MarkRead markiert Benachrichtigungen als gelesen. Ohne IDs werden alle ungelesenen
+// Benachrichtigungen des Nutzers markiert. Fremde IDs werden ignoriert.
+func (r *NotificationRepository) MarkRead(ctx context.Context, appUserID int64, ids []int64) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE app_user_id = $1
+		  AND read_at IS NULL
+		  AND (cardinality($2::bigint[]) = 0 OR id = ANY($2))
+	`, appUserID, ids)
+	if err != nil {
+		return 0, fmt.Errorf("mark notifications read for app user %d: %w", appUserID, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListPreferences liefert den Kanal für jeden bekannten Ereignistyp; fehlende Einträge
+// werden mit dem Standardkanal aufgefüllt.
+func (r *NotificationRepository) ListPreferences(ctx context.Context, appUserID int64) ([]models.NotificationPreference, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT event_type, channel
+		FROM notification_preferences
+		WHERE app_user_id = $1
+	`, appUserID)
+	if err != nil {
+		return nil, fmt.Errorf("query notification preferences for app user %d: %w", appUserID, err)
+	}
+	defer rows.Close()
+
+	stored := make(map[string]string)
+	for rows.Next() {
+		var eventType, channel string
+		if err := rows.Scan(&eventType, &channel); err != nil {
+			return nil, fmt.Errorf("scan notification preference: %w", err)
+		}
+		stored[eventType] = channel
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification preferences: %w", err)
+	}
+
+	return mergeNotificationPreferences(stored), nil
+}
+
+// UpdatePreferences speichert die übergebenen Kanäle (Upsert je Ereignistyp) und liefert
+// anschließend die vollständige Präferenzliste.
+func (r *NotificationRepository) UpdatePreferences(
+	ctx context.Context,
+	appUserID int64,
+	preferences []models.NotificationPreference,
+) ([]models.NotificationPreference, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin notification preferences tx %d: %w", appUserID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, preference := range preferences {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO notification_preferences (app_user_id, event_type, channel)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (app_user_id, event_type) DO UPDATE
+			SET channel = EXCLUDED.channel,
+				updated_at = NOW()
+		`, appUserID, preference.EventType, preference.Channel); err != nil {
+			return nil, fmt.Errorf("upsert notification preference %s for app user %d: %w", preference.EventType, appUserID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit notification preferences tx %d: %w", appUserID, err)
+	}
+
+	return r.ListPreferences(ctx, appUserID)
+}
+
+// ContributionProposerAppUserID liefert den Account, der einen Beitragsvorschlag angelegt hat.
+func (r *NotificationRepository) ContributionProposerAppUserID(ctx context.Context, contributionID int64) (*int64, error) {
+	return r.optionalAppUserID(ctx, `
+		SELECT created_by
+		FROM anime_contributions
+		WHERE id = $1
+	`, contributionID)
+}
+
+// ClaimAppUserID liefert den Account hinter einem Member-Claim.
+func (r *NotificationRepository) ClaimAppUserID(ctx context.Context, claimID int64) (*int64, error) {
+	return r.optionalAppUserID(ctx, `
+		SELECT app_user_id
+		FROM member_claims
+		WHERE id = $1
+	`, claimID)
+}
+
+// AppUserIDByEmail sucht einen vorhandenen Account zu einer E-Mail-Adresse (Groß-/Kleinschreibung egal).
+func (r *NotificationRepository) AppUserIDByEmail(ctx context.Context, email string) (*int64, error) {
+	return r.optionalAppUserID(ctx, `
+		SELECT id
+		FROM app_users
+		WHERE LOWER(email) = $1
+		ORDER BY id ASC
+		LIMIT 1
+	`, strings.ToLower(strings.TrimSpace(email)))
+}
+
+// WatchlistAppUserIDs liefert die Accounts, die einen Anime auf der Watchlist haben und ihn
+// nicht abgebrochen haben. Die Watchlist hängt an Legacy-User-IDs.
+func (r *NotificationRepository) WatchlistAppUserIDs(ctx context.Context, animeID int64) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT u.id
+		FROM watchlist_entries w
+		JOIN app_users u ON u.legacy_user_id = w.user_id
+		WHERE w.anime_id = $1
+		  AND w.list_status <> 'dropped'
+		ORDER BY u.id ASC
+	`, animeID)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist recipients for anime %d: %w", animeID, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan watchlist recipient: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlist recipients: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (r *NotificationRepository) optionalAppUserID(ctx context.Context, query string, arg any) (*int64, error) {
+	var appUserID *int64
+	if err := r.db.QueryRow(ctx, query, arg).Scan(&appUserID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("resolve notification recipient: %w", err)
+	}
+	return appUserID, nil
+}
+
+func mergeNotificationPreferences(stored map[string]string) []models.NotificationPreference {
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationEventTypes))
+	for _, eventType := range models.NotificationEventTypes {
+		channel, ok := stored[eventType]
+		if !ok || !models.IsNotificationChannel(channel) {
+			channel = models.DefaultNotificationChannel
+		}
+		preferences = append(preferences, models.NotificationPreference{EventType: eventType, Channel: channel})
+	}
+	return preferences
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PlaybackSecurityRepository speichert Sicherheitsereignisse der Wiedergabe und verwaltet die
+// daraus abgeleiteten, zeitlich begrenzten Grant-Sperren.
+type PlaybackSecurityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPlaybackSecurityRepository(db *pgxpool.Pool) *PlaybackSecurityRepository {
+	return &PlaybackSecurityRepository{db: db}
+}
+
+// RecordEvent speichert ein Sicherheitsereignis.
+func (r *PlaybackSecurityRepository) RecordEvent(
+	ctx context.Context,
+	input models.PlaybackSecurityEventInput,
+) (*models.PlaybackSecurityEvent, error) {
+	if !models.IsPlaybackSecurityEventType(input.EventType) || !models.IsPlaybackSecuritySeverity(input.Severity) {
+		return nil, fmt.Errorf("invalid playback security event %q/%q", input.EventType, input.Severity)
+	}
+
+	details := input.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("marshal playback security event details: %w", err)
+	}
+
+	event, err := scanPlaybackSecurityEvent(r.db.QueryRow(ctx, `
+		INSERT INTO playback_security_events (event_type, severity, user_id, client_ip, episode_id, action, details)
+		VALUES ($1, $2, NULLIF($3::bigint, 0), NULLIF($4, ''), NULLIF($5::bigint, 0), NULLIF($6, ''), $7)
+		RETURNING `+playbackSecurityEventColumns,
+		input.EventType,
+		input.Severity,
+		input.UserID,
+		truncatePlaybackSecurityField(input.ClientIP, 64),
+		input.EpisodeID,
+		truncatePlaybackSecurityField(input.Action, 40),
+		detailsJSON,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ApplyGrantBlockRule sperrt die Grants von userID, wenn seit Beginn des Regelfensters
+// mindestens rule.Threshold Ereignisse der Schwere warning oder critical gespeichert wurden.
+// Ereignisse vor der letzten aufgehobenen Sperre zählen nicht erneut. Liefert die neue Sperre
+// oder nil, wenn keine Sperre nötig war oder bereits eine aktiv ist.
+func (r *PlaybackSecurityRepository) ApplyGrantBlockRule(
+	ctx context.Context,
+	userID int64,
+	rule models.PlaybackGrantBlockRule,
+) (*models.PlaybackGrantBlock, error) {
+	if userID <= 0 || !rule.Enabled() {
+		return nil, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin playback grant block: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialisiert die Regelprüfung pro Nutzer über alle Instanzen.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('playback_grant_blocks'), hashtext($1))`, strconv.FormatInt(userID, 10)); err != nil {
+		return nil, fmt.Errorf("lock playback grant block: %w", err)
+	}
+
+	var active bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM playback_grant_blocks
+			WHERE user_id = $1 AND lifted_at IS NULL AND blocked_until > NOW()
+		)
+	`, userID).Scan(&active); err != nil {
+		return nil, fmt.Errorf("check active playback grant block: %w", err)
+	}
+	if active {
+		return nil, nil
+	}
+
+	var count int32
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM playback_security_events
+		WHERE user_id = $1
+		  AND severity IN ('warning', 'critical')
+		  AND event_type <> $3
+		  AND created_at > GREATEST(
+		      NOW() - make_interval(secs => $2),
+		      COALESCE((SELECT MAX(COALESCE(lifted_at, blocked_until)) FROM playback_grant_blocks WHERE user_id = $1), '-infinity'::timestamptz)
+		  )
+	`, userID, rule.Window.Seconds(), models.PlaybackSecurityEventRateLimit).Scan(&count); err != nil {
+		return nil, fmt.Errorf("count playback security events: %w", err)
+	}
+	if int(count) < rule.Threshold {
+		return nil, nil
+	}
+
+	block, err := scanPlaybackGrantBlock(tx.QueryRow(ctx, `
+		INSERT INTO playback_grant_blocks (user_id, reason, event_count, blocked_until)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING `+playbackGrantBlockColumns,
+		userID,
+		fmt.Sprintf("%d sicherheitsereignisse innerhalb von %s", count, rule.Window),
+		count,
+		rule.Duration.Seconds(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit playback grant block: %w", err)
+	}
+	return &block, nil
+}
+
+// ActiveGrantBlock liefert die am längsten laufende aktive Sperre von userID oder ErrNotFound.
+func (r *PlaybackSecurityRepository) ActiveGrantBlock(ctx context.Context, userID int64) (*models.PlaybackGrantBlock, error) {
+	block, err := scanPlaybackGrantBlock(r.db.QueryRow(ctx, `
+		SELECT `+playbackGrantBlockColumns+`
+		FROM playback_grant_blocks
+		WHERE user_id = $1 AND lifted_at IS NULL AND blocked_until > NOW()
+		ORDER BY blocked_until DESC
+		LIMIT 1
+	`, userID))
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// ListEvents liefert die Ereignisse passend zu filter, neueste zuerst, samt Gesamtzahl.
+func (r *PlaybackSecurityRepository) ListEvents(
+	ctx context.Context,
+	filter models.PlaybackSecurityEventFilter,
+) ([]models.PlaybackSecurityEvent, int64, error) {
+	const scope = `
+		FROM playback_security_events
+		WHERE ($1::bigint = 0 OR user_id = $1)
+		  AND ($2 = '' OR event_type = $2)
+		  AND ($3 = '' OR severity = $3)
+		  AND ($4::timestamptz IS NULL OR created_at >= $4)
+		  AND ($5::timestamptz IS NULL OR created_at < $5)`
+	args := []any{filter.UserID, filter.EventType, filter.Severity, filter.From, filter.To}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+scope, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count playback security events: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT `+playbackSecurityEventColumns+`
+		`+scope+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $6 OFFSET $7
+	`, append(args, filter.PerPage, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query playback security events: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.PlaybackSecurityEvent, 0)
+	for rows.Next() {
+		item, err := scanPlaybackSecurityEvent(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate playback security events: %w", err)
+	}
+	return items, total, nil
+}
+
+// ListGrantBlocks liefert die neuesten Sperren, optional nur die von userID bzw. nur aktive.
+func (r *PlaybackSecurityRepository) ListGrantBlocks(
+	ctx context.Context,
+	userID int64,
+	activeOnly bool,
+	limit int,
+) ([]models.PlaybackGrantBlock, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+playbackGrantBlockColumns+`
+		FROM playback_grant_blocks
+		WHERE ($1::bigint = 0 OR user_id = $1)
+		  AND (NOT $2 OR (lifted_at IS NULL AND blocked_until > NOW()))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`, userID, activeOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query playback grant blocks: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.PlaybackGrantBlock, 0)
+	for rows.Next() {
+		item, err := scanPlaybackGrantBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate playback grant blocks: %w", err)
+	}
+	return items, nil
+}
+
+// LiftGrantBlock hebt eine aktive Sperre vorzeitig auf; ErrNotFound, wenn sie nicht (mehr)
+// aktiv ist.
+func (r *PlaybackSecurityRepository) LiftGrantBlock(
+	ctx context.Context,
+	blockID int64,
+	actorAppUserID *int64,
+) (*models.PlaybackGrantBlock, error) {
+	block, err := scanPlaybackGrantBlock(r.db.QueryRow(ctx, `
+		UPDATE playback_grant_blocks
+		SET lifted_at = NOW(),
+		    lifted_by_app_user_id = $2
+		WHERE id = $1 AND lifted_at IS NULL AND blocked_until > NOW()
+		RETURNING `+playbackGrantBlockColumns,
+		blockID,
+		actorAppUserID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+const playbackSecurityEventColumns = `
+	id, event_type, severity, user_id, client_ip, episode_id, action, details, created_at`
+
+func scanPlaybackSecurityEvent(row pgx.Row) (models.PlaybackSecurityEvent, error) {
+	var item models.PlaybackSecurityEvent
+	if err := row.Scan(
+		&item.ID,
+		&item.EventType,
+		&item.Severity,
+		&item.UserID,
+		&item.ClientIP,
+		&item.EpisodeID,
+		&item.Action,
+		&item.Details,
+		&item.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PlaybackSecurityEvent{}, ErrNotFound
+		}
+		return models.PlaybackSecurityEvent{}, fmt.Errorf("scan playback security event: %w", err)
+	}
+	return item, nil
+}
+
+const playbackGrantBlockColumns = `
+	id, user_id, reason, event_count, blocked_until, lifted_by_app_user_id, lifted_at, created_at`
+
+func scanPlaybackGrantBlock(row pgx.Row) (models.PlaybackGrantBlock, error) {
+	var item models.PlaybackGrantBlock
+	if err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.Reason,
+		&item.EventCount,
+		&item.BlockedUntil,
+		&item.LiftedByAppUserID,
+		&item.LiftedAt,
+		&item.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PlaybackGrantBlock{}, ErrNotFound
+		}
+		return models.PlaybackGrantBlock{}, fmt.Errorf("scan playback grant block: %w", err)
+	}
+	return item, nil
+}
+
+func truncatePlaybackSecurityField(value string, maxLength int) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) <= maxLength {
+		return string(runes)
+	}
+	return string(runes[:maxLength])
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Alle Schlüssel teilen den Hash-Tag {playback_sessions}, damit die Lua-Skripte auch in einem
+// Redis-Cluster auf einem einzigen Slot arbeiten.
+const (
+	playbackSessionAllKey        = "{playback_sessions}:all"
+	playbackSessionKeyPrefix     = "{playback_sessions}:session:"
+	playbackSessionUserKeyPrefix = "{playback_sessions}:user:"
+)
+
+var (
+	// ErrPlaybackSessionUserLimit: der Nutzer hat bereits die maximale Zahl laufender Sessions.
+	ErrPlaybackSessionUserLimit = errors.New("playback session user limit reached")
+	// ErrPlaybackSessionGlobalLimit: die maximale Zahl laufender Sessions aller Nutzer ist erreicht.
+	ErrPlaybackSessionGlobalLimit = errors.New("playback session global limit reached")
+)
+
+// openPlaybackSessionScript räumt abgelaufene Einträge ab, verlängert eine laufende Session
+// desselben Nutzers für dieselbe Episode oder legt unter Prüfung beider Limits eine neue an.
+// Rückgabe: {1, id} bei Wiederverwendung, {0, id} bei Neuanlage, {-1, ""} Nutzerlimit,
+// {-2, ""} globales Limit.
+var openPlaybackSessionScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local timeout = tonumber(ARGV[2])
+local expires = now + timeout
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+
+local existing = redis.call('GET', KEYS[3])
+if existing and redis.call('ZSCORE', KEYS[2], existing) then
+  local existingKey = ARGV[6] .. existing
+  if redis.call('EXISTS', existingKey) == 1 then
+    redis.call('HSET', existingKey, 'last_seen_ms', now, 'client_ip', ARGV[9], 'user_agent', ARGV[10])
+    redis.call('PEXPIRE', existingKey, timeout)
+    redis.call('ZADD', KEYS[1], expires, existing)
+    redis.call('ZADD', KEYS[2], expires, existing)
+    redis.call('PEXPIRE', KEYS[2], timeout)
+    redis.call('PEXPIRE', KEYS[3], timeout)
+    return {1, existing}
+  end
+end
+
+local perUser = tonumber(ARGV[3])
+local global = tonumber(ARGV[4])
+if perUser > 0 and redis.call('ZCARD', KEYS[2]) >= perUser then
+  return {-1, ''}
+end
+if global > 0 and redis.call('ZCARD', KEYS[1]) >= global then
+  return {-2, ''}
+end
+
+local sessionKey = ARGV[6] .. ARGV[5]
+redis.call('HSET', sessionKey,
+  'user_id', ARGV[7], 'episode_id', ARGV[8], 'client_ip', ARGV[9], 'user_agent', ARGV[10],
+  'created_ms', now, 'last_seen_ms', now)
+redis.call('PEXPIRE', sessionKey, timeout)
+redis.call('ZADD', KEYS[1], expires, ARGV[5])
+redis.call('ZADD', KEYS[2], expires, ARGV[5])
+redis.call('PEXPIRE', KEYS[2], timeout)
+redis.call('SET', KEYS[3], ARGV[5], 'PX', timeout)
+return {0, ARGV[5]}
+`)
+
+// touchPlaybackSessionScript verlängert eine laufende Session. Rückgabe 1 bei Erfolg, 0 wenn
+// die Session nicht (mehr) existiert.
+var touchPlaybackSessionScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local timeout = tonumber(ARGV[2])
+local sessionKey = ARGV[3] .. ARGV[4]
+local fields = redis.call('HMGET', sessionKey, 'user_id', 'episode_id')
+if not fields[1] then
+  return 0
+end
+
+local expires = now + timeout
+redis.call('HSET', sessionKey, 'last_seen_ms', now)
+redis.call('PEXPIRE', sessionKey, timeout)
+redis.call('ZADD', KEYS[1], expires, ARGV[4])
+local userKey = ARGV[5] .. fields[1]
+redis.call('ZADD', userKey, expires, ARGV[4])
+redis.call('PEXPIRE', userKey, timeout)
+local pointerKey = userKey .. ':episode:' .. fields[2]
+if redis.call('GET', pointerKey) == ARGV[4] then
+  redis.call('PEXPIRE', pointerKey, timeout)
+end
+return 1
+`)
+
+// terminatePlaybackSessionScript entfernt eine Session samt Index-Einträgen. Rückgabe 1 bei
+// Erfolg, 0 wenn die Session nicht existiert.
+var terminatePlaybackSessionScript = redis.NewScript(`
+local sessionKey = ARGV[1] .. ARGV[2]
+local fields = redis.call('HMGET', sessionKey, 'user_id', 'episode_id')
+if not fields[1] then
+  return 0
+end
+
+redis.call('DEL', sessionKey)
+redis.call('ZREM', KEYS[1], ARGV[2])
+local userKey = ARGV[3] .. fields[1]
+redis.call('ZREM', userKey, ARGV[2])
+local pointerKey = userKey .. ':episode:' .. fields[2]
+if redis.call('GET', pointerKey) == ARGV[2] then
+  redis.call('DEL', pointerKey)
+end
+return 1
+`)
+
+// PlaybackSessionConfig steuert Zeitüberschreitung und Limits der Playback-Sessions.
+// Limits <= 0 sind deaktiviert.
+type PlaybackSessionConfig struct {
+	Timeout    time.Duration // ohne Heartbeat läuft eine Session nach dieser Zeit ab (Default 90s)
+	MaxPerUser int           // maximale gleichzeitige Sessions pro Nutzer
+	MaxGlobal  int           // maximale gleichzeitige Sessions über alle Instanzen
+}
+
+// PlaybackSessionRepository verwaltet Playback-Sessions in Redis. Alle Zustandsänderungen
+// laufen über Lua-Skripte, damit die Limits auch bei mehreren Backend-Instanzen atomar gelten.
+type PlaybackSessionRepository struct {
+	redis redis.UniversalClient
+	cfg   PlaybackSessionConfig
+	now   func() time.Time
+}
+
+// NewPlaybackSessionRepository erstellt ein Repository auf redisClient.
+func NewPlaybackSessionRepository(redisClient redis.UniversalClient, cfg PlaybackSessionConfig) *PlaybackSessionRepository {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 90 * time.Second
+	}
+	return &PlaybackSessionRepository{redis: redisClient, cfg: cfg, now: time.Now}
+}
+
+// Timeout liefert die Zeit, nach der eine Session ohne Heartbeat abläuft.
+func (r *PlaybackSessionRepository) Timeout() time.Duration {
+	return r.cfg.Timeout
+}
+
+// Open legt eine Session an oder verlängert die laufende Session desselben Nutzers für dieselbe
+// Episode. Liefert ErrPlaybackSessionUserLimit bzw. ErrPlaybackSessionGlobalLimit, wenn keine
+// weitere Session erlaubt ist.
+func (r *PlaybackSessionRepository) Open(ctx context.Context, input models.PlaybackSessionInput) (*models.PlaybackSession, error) {
+	if input.UserID <= 0 || input.EpisodeID <= 0 {
+		return nil, fmt.Errorf("invalid playback session input")
+	}
+
+	sessionID, err := randomToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate playback session id: %w", err)
+	}
+
+	now := r.now().UTC()
+	result, err := openPlaybackSessionScript.Run(
+		ctx,
+		r.redis,
+		[]string{
+			playbackSessionAllKey,
+			playbackSessionUserKey(input.UserID),
+			playbackSessionPointerKey(input.UserID, input.EpisodeID),
+		},
+		now.UnixMilli(),
+		r.cfg.Timeout.Milliseconds(),
+		r.cfg.MaxPerUser,
+		r.cfg.MaxGlobal,
+		sessionID,
+		playbackSessionKeyPrefix,
+		input.UserID,
+		input.EpisodeID,
+		strings.TrimSpace(input.ClientIP),
+		strings.TrimSpace(input.UserAgent),
+	).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("open playback session: %w", err)
+	}
+	if len(result) != 2 {
+		return nil, fmt.Errorf("open playback session: unexpected script result %v", result)
+	}
+
+	code, _ := result[0].(int64)
+	switch code {
+	case -1:
+		return nil, ErrPlaybackSessionUserLimit
+	case -2:
+		return nil, ErrPlaybackSessionGlobalLimit
+	}
+	openedID, _ := result[1].(string)
+	return r.Get(ctx, openedID)
+}
+
+// Get liefert eine laufende Session oder ErrNotFound.
+func (r *PlaybackSessionRepository) Get(ctx context.Context, sessionID string) (*models.PlaybackSession, error) {
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return nil, ErrNotFound
+	}
+
+	fields, err := r.redis.HGetAll(ctx, playbackSessionKeyPrefix+sessionID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get playback session: %w", err)
+	}
+	session, ok := r.decodeSession(sessionID, fields)
+	if !ok || !session.ExpiresAt.After(r.now()) {
+		return nil, ErrNotFound
+	}
+	return session, nil
+}
+
+// Touch verlängert eine laufende Session (Heartbeat oder Stream-Abruf) und liefert sie zurück.
+func (r *PlaybackSessionRepository) Touch(ctx context.Context, sessionID string) (*models.PlaybackSession, error) {
+	if _, err := r.Get(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	touched, err := touchPlaybackSessionScript.Run(
+		ctx,
+		r.redis,
+		[]string{playbackSessionAllKey},
+		r.now().UTC().UnixMilli(),
+		r.cfg.Timeout.Milliseconds(),
+		playbackSessionKeyPrefix,
+		strings.TrimSpace(sessionID),
+		playbackSessionUserKeyPrefix,
+	).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("touch playback session: %w", err)
+	}
+	if touched == 0 {
+		return nil, ErrNotFound
+	}
+	return r.Get(ctx, sessionID)
+}
+
+// Terminate beendet eine Session und liefert ihren letzten Zustand; ErrNotFound, wenn sie
+// nicht (mehr) läuft.
+func (r *PlaybackSessionRepository) Terminate(ctx context.Context, sessionID string) (*models.PlaybackSession, error) {
+	session, err := r.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := terminatePlaybackSessionScript.Run(
+		ctx,
+		r.redis,
+		[]string{playbackSessionAllKey},
+		playbackSessionKeyPrefix,
+		session.ID,
+		playbackSessionUserKeyPrefix,
+	).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("terminate playback session: %w", err)
+	}
+	if removed == 0 {
+		return nil, ErrNotFound
+	}
+	return session, nil
+}
+
+// List liefert alle laufenden Sessions, bei userID > 0 nur die dieses Nutzers, älteste zuerst.
+func (r *PlaybackSessionRepository) List(ctx context.Context, userID int64) ([]models.PlaybackSession, error) {
+	indexKey := playbackSessionAllKey
+	if userID > 0 {
+		indexKey = playbackSessionUserKey(userID)
+	}
+
+	ids, err := r.redis.ZRangeByScore(ctx, indexKey, &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(r.now().UTC().UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list playback sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return []models.PlaybackSession{}, nil
+	}
+
+	pipe := r.redis.Pipeline()
+	commands := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		commands[i] = pipe.HGetAll(ctx, playbackSessionKeyPrefix+id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("load playback sessions: %w", err)
+	}
+
+	now := r.now()
+	sessions := make([]models.PlaybackSession, 0, len(ids))
+	for i, id := range ids {
+		session, ok := r.decodeSession(id, commands[i].Val())
+		if !ok || !session.ExpiresAt.After(now) {
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (r *PlaybackSessionRepository) decodeSession(sessionID string, fields map[string]string) (*models.PlaybackSession, bool) {
+	if len(fields) == 0 {
+		return nil, false
+	}
+	userID, err := strconv.ParseInt(fields["user_id"], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	episodeID, err := strconv.ParseInt(fields["episode_id"], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	createdMS, _ := strconv.ParseInt(fields["created_ms"], 10, 64)
+	lastSeenMS, _ := strconv.ParseInt(fields["last_seen_ms"], 10, 64)
+	lastSeenAt := time.UnixMilli(lastSeenMS).UTC()
+
+	return &models.PlaybackSession{
+		ID:         sessionID,
+		UserID:     userID,
+		EpisodeID:  episodeID,
+		ClientIP:   fields["client_ip"],
+		UserAgent:  fields["user_agent"],
+		CreatedAt:  time.UnixMilli(createdMS).UTC(),
+		LastSeenAt: lastSeenAt,
+		ExpiresAt:  lastSeenAt.Add(r.cfg.Timeout),
+	}, true
+}
+
+func playbackSessionUserKey(userID int64) string {
+	return playbackSessionUserKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+func playbackSessionPointerKey(userID int64, episodeID int64) string {
+	return fmt.Sprintf("%s:episode:%d", playbackSessionUserKey(userID), episodeID)
+}
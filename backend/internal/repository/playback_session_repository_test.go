@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestPlaybackSessionRepository(t *testing.T, cfg PlaybackSessionConfig) (*PlaybackSessionRepository, *time.Time) {
+	t.Helper()
+
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+		mini.Close()
+	})
+
+	now := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	repo := NewPlaybackSessionRepository(client, cfg)
+	repo.now = func() time.Time { return now }
+	return repo, &now
+}
+
+func TestPlaybackSessionOpenReusesSessionAndEnforcesLimits(t *testing.T) {
+	// Zwei Repository-Instanzen auf demselben Redis simulieren zwei Backend-Replikas.
+	repo, _ := newTestPlaybackSessionRepository(t, PlaybackSessionConfig{Timeout: time.Minute, MaxPerUser: 1, MaxGlobal: 2})
+	other := NewPlaybackSessionRepository(repo.redis, repo.cfg)
+	other.now = repo.now
+	ctx := context.Background()
+
+	first, err := repo.Open(ctx, models.PlaybackSessionInput{UserID: 1, EpisodeID: 10, ClientIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("open first session: %v", err)
+	}
+	again, err := other.Open(ctx, models.PlaybackSessionInput{UserID: 1, EpisodeID: 10, ClientIP: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("reopen session: %v", err)
+	}
+	if again.ID != first.ID || again.ClientIP != "10.0.0.2" {
+		t.Fatalf("expected session reuse, got %+v", again)
+	}
+
+	if _, err := other.Open(ctx, models.PlaybackSessionInput{UserID: 1, EpisodeID: 11}); !errors.Is(err, ErrPlaybackSessionUserLimit) {
+		t.Fatalf("expected user limit, got %v", err)
+	}
+	if _, err := other.Open(ctx, models.PlaybackSessionInput{UserID: 2, EpisodeID: 11}); err != nil {
+		t.Fatalf("open second user session: %v", err)
+	}
+	if _, err := repo.Open(ctx, models.PlaybackSessionInput{UserID: 3, EpisodeID: 11}); !errors.Is(err, ErrPlaybackSessionGlobalLimit) {
+		t.Fatalf("expected global limit, got %v", err)
+	}
+
+	sessions, err := repo.List(ctx, 0)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 live sessions, got %d", len(sessions))
+	}
+}
+
+func TestPlaybackSessionExpiresWithoutHeartbeat(t *testing.T) {
+	repo, now := newTestPlaybackSessionRepository(t, PlaybackSessionConfig{Timeout: time.Minute, MaxPerUser: 1})
+	ctx := context.Background()
+
+	session, err := repo.Open(ctx, models.PlaybackSessionInput{UserID: 1, EpisodeID: 10})
+	if err != nil {
+		t.Fatalf("open session: %v", err)
+	}
+
+	*now = now.Add(45 * time.Second)
+	touched, err := repo.Touch(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("touch session: %v", err)
+	}
+	if !touched.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected heartbeat to extend expiry, got %s", touched.ExpiresAt)
+	}
+
+	*now = now.Add(61 * time.Second)
+	if _, err := repo.Touch(ctx, session.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected expired session, got %v", err)
+	}
+	if _, err := repo.Open(ctx, models.PlaybackSessionInput{UserID: 1, EpisodeID: 11}); err != nil {
+		t.Fatalf("expected expired session to free the user slot: %v", err)
+	}
+}
+
+func TestPlaybackSessionTerminateFreesSlot(t *testing.T) {
+	repo, _ := newTestPlaybackSessionRepository(t, PlaybackSessionConfig{Timeout: time.Minute, MaxGlobal: 1})
+	ctx := context.Background()
+
+	session, err := repo.Open(ctx, models.PlaybackSessionInput{UserID: 1, EpisodeID: 10})
+	if err != nil {
+		t.Fatalf("open session: %v", err)
+	}
+	if _, err := repo.Terminate(ctx, session.ID); err != nil {
+		t.Fatalf("terminate session: %v", err)
+	}
+	if _, err := repo.Touch(ctx, session.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected terminated session to be gone, got %v", err)
+	}
+	if _, err := repo.Terminate(ctx, session.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected second terminate to report not found, got %v", err)
+	}
+
+	reopened, err := repo.Open(ctx, models.PlaybackSessionInput{UserID: 1, EpisodeID: 10})
+	if err != nil {
+		t.Fatalf("open after terminate: %v", err)
+	}
+	if reopened.ID == session.ID {
+		t.Fatalf("expected a fresh session after terminate")
+	}
+}
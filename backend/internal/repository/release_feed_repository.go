@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// releaseFeedFromSQL verbindet Release-Versionen mit Episode und Anime. Der LATERAL-Join
+// verlangt mindestens eine Variante, wie die öffentliche Episodenliste (ListGroupedByAnimeID),
+// und liefert Qualitäten sowie den jüngsten Änderungszeitpunkt der Varianten.
+const releaseFeedFromSQL = `
+		FROM release_versions rev
+		JOIN fansub_releases fr ON fr.id = rev.release_id
+		JOIN episodes e ON e.id = fr.episode_id AND e.episode_number ~ '^[0-9]+$'
+		JOIN anime a ON a.id = e.anime_id
+		JOIN LATERAL (
+			SELECT
+				MAX(GREATEST(rv.created_at, rv.updated_at)) AS updated_at,
+				COALESCE(
+					ARRAY_AGG(DISTINCT COALESCE(NULLIF(BTRIM(rv.video_quality), ''), NULLIF(BTRIM(rv.resolution), '')))
+						FILTER (WHERE COALESCE(NULLIF(BTRIM(rv.video_quality), ''), NULLIF(BTRIM(rv.resolution), '')) IS NOT NULL),
+					ARRAY[]::text[]
+				) AS qualities
+			FROM release_variants rv
+			WHERE rv.release_version_id = rev.id
+		) variants ON variants.updated_at IS NOT NULL`
+
+// ReleaseFeedRepository liefert die Release-Versionen der öffentlichen Atom-Feeds.
+type ReleaseFeedRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReleaseFeedRepository(db *pgxpool.Pool) *ReleaseFeedRepository {
+	return &ReleaseFeedRepository{db: db}
+}
+
+// GetState liefert Titel und Änderungsstand eines Feeds mit einer einzigen Aggregat-Abfrage.
+// Unbekannte oder deaktivierte Anime sowie unbekannte Gruppen liefern ErrNotFound.
+func (r *ReleaseFeedRepository) GetState(ctx context.Context, scope models.ReleaseFeedScope) (*models.ReleaseFeedState, error) {
+	state := &models.ReleaseFeedState{}
+
+	switch scope.Kind {
+	case models.ReleaseFeedScopeAnime:
+		err := r.db.QueryRow(ctx, `
+			SELECT title FROM anime WHERE id = $1 AND status <> 'disabled'
+		`, scope.AnimeID).Scan(&state.Title)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get release feed anime %d: %w", scope.AnimeID, err)
+		}
+	case models.ReleaseFeedScopeFansub:
+		err := r.db.QueryRow(ctx, `
+			SELECT name FROM fansub_groups WHERE slug = $1
+		`, scope.FansubSlug).Scan(&state.Title)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get release feed fansub group %q: %w", scope.FansubSlug, err)
+		}
+	}
+
+	whereSQL, args := buildReleaseFeedWhere(scope)
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(MAX(rev.id), 0),
+			MAX(GREATEST(rev.created_at, rev.updated_at, variants.updated_at))` +
+		releaseFeedFromSQL + whereSQL
+
+	if err := r.db.QueryRow(ctx, query, args...).Scan(
+		&state.EntryCount,
+		&state.MaxVersionID,
+		&state.LastModified,
+	); err != nil {
+		return nil, fmt.Errorf("get release feed state (%s): %w", scope.Kind, err)
+	}
+
+	return state, nil
+}
+
+// ListEntries liefert die neuesten sichtbaren Release-Versionen eines Feeds.
+func (r *ReleaseFeedRepository) ListEntries(ctx context.Context, scope models.ReleaseFeedScope, limit int) ([]models.ReleaseFeedEntry, error) {
+	whereSQL, args := buildReleaseFeedWhere(scope)
+	query := fmt.Sprintf(`
+		SELECT
+			rev.id,
+			rev.version,
+			NULLIF(BTRIM(COALESCE(rev.title, e.title)), ''),
+			a.id,
+			a.title,
+			e.id,
+			e.episode_number,
+			COALESCE(rev.release_date, fr.release_date),
+			rev.created_at,
+			GREATEST(rev.created_at, rev.updated_at, variants.updated_at),
+			COALESCE((
+				SELECT ARRAY_AGG(fg.name ORDER BY fg.name)
+				FROM release_version_groups rvg
+				JOIN fansub_groups fg ON fg.id = rvg.fansub_group_id
+				WHERE rvg.release_version_id = rev.id
+			), ARRAY[]::text[]),
+			variants.qualities`+releaseFeedFromSQL+whereSQL+`
+		ORDER BY rev.created_at DESC, rev.id DESC
+		LIMIT $%d
+	`, len(args)+1)
+
+	rows, err := r.db.Query(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("query release feed entries (%s): %w", scope.Kind, err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.ReleaseFeedEntry, 0, limit)
+	for rows.Next() {
+		var entry models.ReleaseFeedEntry
+		if err := rows.Scan(
+			&entry.ReleaseVersionID,
+			&entry.Version,
+			&entry.Title,
+			&entry.AnimeID,
+			&entry.AnimeTitle,
+			&entry.EpisodeID,
+			&entry.EpisodeNumber,
+			&entry.ReleaseDate,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.FansubGroups,
+			&entry.Qualities,
+		); err != nil {
+			return nil, fmt.Errorf("scan release feed entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate release feed entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// buildReleaseFeedWhere bildet die Sichtbarkeitsregeln der öffentlichen Release-Listen ab:
+// deaktivierte Anime sind ausgeblendet, und Gruppen-Feeds enthalten wie GetGroupReleases nur
+// Versionen der Gruppe zu Anime, denen sie über anime_fansub_groups zugeordnet ist.
+func buildReleaseFeedWhere(scope models.ReleaseFeedScope) (string, []any) {
+	conditions := []string{"a.status <> 'disabled'"}
+	args := make([]any, 0, 1)
+
+	switch scope.Kind {
+	case models.ReleaseFeedScopeAnime:
+		args = append(args, scope.AnimeID)
+		conditions = append(conditions, "e.anime_id = $1")
+	case models.ReleaseFeedScopeFansub:
+		args = append(args, scope.FansubSlug)
+		conditions = append(conditions, `EXISTS (
+			SELECT 1
+			FROM release_version_groups rvg
+			JOIN fansub_groups fg ON fg.id = rvg.fansub_group_id
+			JOIN anime_fansub_groups afg ON afg.fansub_group_id = rvg.fansub_group_id AND afg.anime_id = e.anime_id
+			WHERE rvg.release_version_id = rev.id
+			  AND fg.slug = $1
+		)`)
+	}
+
+	return "\n\t\tWHERE " + strings.Join(conditions, "\n\t\t  AND "), args
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestBuildReleaseFeedWhere_Scopes(t *testing.T) {
+	whereSQL, args := buildReleaseFeedWhere(models.ReleaseFeedScope{Kind: models.ReleaseFeedScopeAll})
+	if !strings.Contains(whereSQL, "a.status <> 'disabled'") || len(args) != 0 {
+		t.Fatalf("expected disabled filter without args, got %q %#v", whereSQL, args)
+	}
+
+	whereSQL, args = buildReleaseFeedWhere(models.ReleaseFeedScope{Kind: models.ReleaseFeedScopeAnime, AnimeID: 12})
+	if !strings.Contains(whereSQL, "e.anime_id = $1") || !reflect.DeepEqual(args, []any{int64(12)}) {
+		t.Fatalf("expected anime filter, got %q %#v", whereSQL, args)
+	}
+
+	whereSQL, args = buildReleaseFeedWhere(models.ReleaseFeedScope{Kind: models.ReleaseFeedScopeFansub, FansubSlug: "team4s"})
+	if !strings.Contains(whereSQL, "JOIN anime_fansub_groups afg") || !strings.Contains(whereSQL, "fg.slug = $1") {
+		t.Fatalf("expected group filter restricted to linked anime, got %q", whereSQL)
+	}
+	if !reflect.DeepEqual(args, []any{"team4s"}) {
+		t.Fatalf("expected slug arg, got %#v", args)
+	}
+}
+
+func TestReleaseFeedFromSQL_RequiresVariant(t *testing.T) {
+	if !strings.Contains(releaseFeedFromSQL, "variants ON variants.updated_at IS NOT NULL") {
+		t.Fatal("expected feed to skip release versions without variants")
+	}
+	if !strings.Contains(releaseFeedFromSQL, "e.episode_number ~ '^[0-9]+$'") {
+		t.Fatal("expected feed to skip non-numeric episodes like the public lists")
+	}
+}
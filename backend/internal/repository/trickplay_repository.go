@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TrickplayRepository verwaltet die Trickplay-Sprites von Release-Varianten und die zugehörigen
+// media_assets.
+type TrickplayRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTrickplayRepository(db *pgxpool.Pool) *TrickplayRepository {
+	return &TrickplayRepository{db: db}
+}
+
+// trickplaySourceProviders sind die Provider, deren Quelle ffmpeg direkt lesen kann.
+const trickplaySourceProviders = `('local', 'jellyfin', 'direct')`
+
+// ClaimTrickplayCandidates markiert bis zu limit Varianten als in Arbeit und liefert sie samt
+// Quelle. Berücksichtigt werden Varianten ohne Trickplay, fehlgeschlagene nach retryAfter (bis
+// maxAttempts Versuche) und hängengebliebene nach staleAfter.
+func (r *TrickplayRepository) ClaimTrickplayCandidates(
+	ctx context.Context,
+	limit int,
+	intervalSeconds int32,
+	retryAfter time.Duration,
+	staleAfter time.Duration,
+	maxAttempts int,
+) ([]models.TrickplayCandidate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin claim trickplay candidates: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+		WITH candidates AS (
+			SELECT rv.id
+			FROM release_variants rv
+			LEFT JOIN release_variant_trickplay t ON t.release_variant_id = rv.id
+			WHERE EXISTS (
+				SELECT 1
+				FROM release_streams rs
+				JOIN stream_sources ss ON ss.id = rs.stream_source_id
+				WHERE rs.variant_id = rv.id
+				  AND ss.provider_type IN `+trickplaySourceProviders+`
+			)
+			  AND (
+				t.release_variant_id IS NULL
+				OR (t.status = 'failed' AND t.attempts < $3 AND t.updated_at < NOW() - make_interval(secs => $4))
+				OR (t.status = 'processing' AND t.updated_at < NOW() - make_interval(secs => $5))
+			  )
+			ORDER BY rv.id ASC
+			LIMIT $1
+			FOR UPDATE OF rv SKIP LOCKED
+		)
+		INSERT INTO release_variant_trickplay (release_variant_id, status, interval_seconds, attempts, updated_at)
+		SELECT id, 'processing', $2, 1, NOW()
+		FROM candidates
+		ON CONFLICT (release_variant_id) DO UPDATE
+		SET status = 'processing',
+		    interval_seconds = EXCLUDED.interval_seconds,
+		    attempts = release_variant_trickplay.attempts + 1,
+		    error_message = NULL,
+		    updated_at = NOW()
+		RETURNING release_variant_id
+	`, limit, intervalSeconds, maxAttempts, retryAfter.Seconds(), staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim trickplay candidates: %w", err)
+	}
+	variantIDs := make([]int64, 0, limit)
+	for rows.Next() {
+		var variantID int64
+		if err := rows.Scan(&variantID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan claimed trickplay candidate: %w", err)
+		}
+		variantIDs = append(variantIDs, variantID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate claimed trickplay candidates: %w", err)
+	}
+	if len(variantIDs) == 0 {
+		return nil, tx.Commit(ctx)
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT DISTINCT ON (rv.id)
+			rv.id,
+			ss.provider_type,
+			COALESCE(ss.external_id, rs.jellyfin_item_id, ''),
+			ss.url,
+			rv.duration_seconds
+		FROM release_variants rv
+		JOIN release_streams rs ON rs.variant_id = rv.id
+		JOIN stream_sources ss ON ss.id = rs.stream_source_id
+		WHERE rv.id = ANY($1)
+		  AND ss.provider_type IN `+trickplaySourceProviders+`
+		ORDER BY rv.id ASC, rs.id ASC
+	`, variantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load trickplay candidate sources: %w", err)
+	}
+	items := make([]models.TrickplayCandidate, 0, len(variantIDs))
+	for rows.Next() {
+		var item models.TrickplayCandidate
+		if err := rows.Scan(&item.ReleaseVariantID, &item.MediaProvider, &item.MediaItemID, &item.StreamURL, &item.DurationSeconds); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan trickplay candidate source: %w", err)
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trickplay candidate sources: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit claim trickplay candidates: %w", err)
+	}
+	return items, nil
+}
+
+// SaveTrickplayResult legt Sprites und WebVTT-Spur als media_assets der Variante an und markiert
+// das Trickplay als fertig. Assets einer früheren Erzeugung verlieren ihren Eigentümer und werden
+// vom Media-Cleanup entfernt.
+func (r *TrickplayRepository) SaveTrickplayResult(ctx context.Context, variantID int64, result models.TrickplayResult) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin save trickplay variant=%d: %w", variantID, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE media_assets
+		SET owner_release_variant_id = NULL,
+		    modified_at = NOW()
+		WHERE owner_release_variant_id = $1
+		  AND media_type_id IN (SELECT id FROM media_types WHERE name IN ($2, $3))
+	`, variantID, models.TrickplayMediaTypeSprite, models.TrickplayMediaTypeVTT); err != nil {
+		return fmt.Errorf("release previous trickplay assets variant=%d: %w", variantID, err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO media_assets (media_type_id, file_path, mime_type, format, status, owner_release_variant_id, created_at)
+		SELECT (SELECT id FROM media_types WHERE name = $1), sprite_path, 'image/jpeg', 'jpg', 'ready', $2, NOW()
+		FROM unnest($3::text[]) AS sprite_path
+	`, models.TrickplayMediaTypeSprite, variantID, result.SpriteFilePaths); err != nil {
+		return fmt.Errorf("insert trickplay sprites variant=%d: %w", variantID, err)
+	}
+
+	var vttAssetID int64
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO media_assets (media_type_id, file_path, mime_type, format, status, owner_release_variant_id, created_at)
+		VALUES ((SELECT id FROM media_types WHERE name = $1), $2, 'text/vtt', 'vtt', 'ready', $3, NOW())
+		RETURNING id
+	`, models.TrickplayMediaTypeVTT, result.VTTFilePath, variantID).Scan(&vttAssetID); err != nil {
+		return fmt.Errorf("insert trickplay vtt variant=%d: %w", variantID, err)
+	}
+
+	commandTag, err := tx.Exec(ctx, `
+		UPDATE release_variant_trickplay
+		SET status = 'ready',
+		    interval_seconds = $2,
+		    tile_width = $3,
+		    tile_height = $4,
+		    tile_columns = $5,
+		    tile_rows = $6,
+		    thumbnail_count = $7,
+		    sprite_count = $8,
+		    vtt_media_asset_id = $9,
+		    error_message = NULL,
+		    generated_at = NOW(),
+		    updated_at = NOW()
+		WHERE release_variant_id = $1
+	`,
+		variantID,
+		result.IntervalSeconds,
+		result.TileWidth,
+		result.TileHeight,
+		result.TileColumns,
+		result.TileRows,
+		result.ThumbnailCount,
+		len(result.SpriteFilePaths),
+		vttAssetID,
+	)
+	if err != nil {
+		return fmt.Errorf("update trickplay variant=%d: %w", variantID, err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit save trickplay variant=%d: %w", variantID, err)
+	}
+	return nil
+}
+
+// MarkTrickplayFailed hält den Fehler der letzten Erzeugung fest.
+func (r *TrickplayRepository) MarkTrickplayFailed(ctx context.Context, variantID int64, message string) error {
+	if _, err := r.db.Exec(ctx, `
+		UPDATE release_variant_trickplay
+		SET status = 'failed',
+		    error_message = $2,
+		    updated_at = NOW()
+		WHERE release_variant_id = $1
+	`, variantID, message); err != nil {
+		return fmt.Errorf("mark trickplay failed variant=%d: %w", variantID, err)
+	}
+	return nil
+}
+
+// GetReadyTrickplay liefert das fertige Trickplay der Release-Version releaseVersionID samt Pfad
+// der WebVTT-Spur oder ErrNotFound. Maßgeblich ist die Variante, die GET /releases/:id/stream
+// abspielt (erster Stream der Version); Vorschauen anderer Varianten passen nicht zur Zeitachse.
+func (r *TrickplayRepository) GetReadyTrickplay(ctx context.Context, releaseVersionID int64) (*models.ReleaseVariantTrickplay, error) {
+	var item models.ReleaseVariantTrickplay
+	err := r.db.QueryRow(ctx, `
+		WITH streamed_variant AS (
+			SELECT rs.variant_id
+			FROM release_variants rv
+			JOIN release_streams rs ON rs.variant_id = rv.id
+			JOIN stream_sources ss ON ss.id = rs.stream_source_id
+			WHERE rv.release_version_id = $1
+			ORDER BY rs.id ASC
+			LIMIT 1
+		)
+		SELECT
+			t.release_variant_id,
+			t.status,
+			t.interval_seconds,
+			t.tile_width,
+			t.tile_height,
+			t.tile_columns,
+			t.tile_rows,
+			t.thumbnail_count,
+			t.sprite_count,
+			ma.file_path,
+			t.attempts,
+			t.error_message,
+			t.generated_at,
+			t.updated_at
+		FROM streamed_variant sv
+		JOIN release_variant_trickplay t ON t.release_variant_id = sv.variant_id
+		JOIN media_assets ma ON ma.id = t.vtt_media_asset_id
+		WHERE t.status = 'ready'
+	`, releaseVersionID).Scan(
+		&item.ReleaseVariantID,
+		&item.Status,
+		&item.IntervalSeconds,
+		&item.TileWidth,
+		&item.TileHeight,
+		&item.TileColumns,
+		&item.TileRows,
+		&item.ThumbnailCount,
+		&item.SpriteCount,
+		&item.VTTFilePath,
+		&item.Attempts,
+		&item.ErrorMessage,
+		&item.GeneratedAt,
+		&item.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get trickplay release_version=%d: %w", releaseVersionID, err)
+	}
+	return &item, nil
+}
+
+// ListOrphanedTrickplayAssets liefert bis zu limit Trickplay-Assets ohne Eigentümer-Variante.
+func (r *TrickplayRepository) ListOrphanedTrickplayAssets(ctx context.Context, limit int) ([]models.TrickplayOrphanAsset, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT ma.id, ma.file_path
+		FROM media_assets ma
+		JOIN media_types mt ON mt.id = ma.media_type_id
+		WHERE mt.name IN ($1, $2)
+		  AND ma.owner_release_variant_id IS NULL
+		ORDER BY ma.id ASC
+		LIMIT $3
+	`, models.TrickplayMediaTypeSprite, models.TrickplayMediaTypeVTT, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query orphaned trickplay assets: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.TrickplayOrphanAsset, 0)
+	for rows.Next() {
+		var item models.TrickplayOrphanAsset
+		if err := rows.Scan(&item.MediaAssetID, &item.FilePath); err != nil {
+			return nil, fmt.Errorf("scan orphaned trickplay asset: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate orphaned trickplay assets: %w", err)
+	}
+	return items, nil
+}
+
+// DeleteOrphanedTrickplayAssets löscht die Zeilen der angegebenen Assets, sofern sie weiterhin
+// keiner Variante gehören.
+func (r *TrickplayRepository) DeleteOrphanedTrickplayAssets(ctx context.Context, mediaAssetIDs []int64) error {
+	if len(mediaAssetIDs) == 0 {
+		return nil
+	}
+	if _, err := r.db.Exec(ctx, `
+		DELETE FROM media_assets
+		WHERE id = ANY($1)
+		  AND owner_release_variant_id IS NULL
+	`, mediaAssetIDs); err != nil {
+		return fmt.Errorf("delete orphaned trickplay assets: %w", err)
+	}
+	return nil
+}
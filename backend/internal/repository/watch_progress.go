@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WatchProgressRepository verwaltet den Wiedergabefortschritt (episode_watch_progress)
+// und leitet daraus die "Weiterschauen"-Liste für Watchlist-Anime ab.
+type WatchProgressRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWatchProgressRepository erstellt ein neues WatchProgressRepository.
+func NewWatchProgressRepository(db *pgxpool.Pool) *WatchProgressRepository {
+	return &WatchProgressRepository{db: db}
+}
+
+const watchProgressColumns = `
+	anime_id, episode_id, release_version_id, position_seconds, duration_seconds,
+	completed, completed_at, last_watched_at
+`
+
+// UpsertProgress speichert einen Fortschritts-Heartbeat. Ein einmal abgeschlossener
+// Eintrag bleibt abgeschlossen, auch wenn der User die Episode erneut startet.
+// Gibt ErrNotFound zurück, wenn die Episode (oder ihr Anime) nicht existiert, und
+// ErrValidation, wenn die Release-Version nicht zur Episode gehört.
+func (r *WatchProgressRepository) UpsertProgress(
+	ctx context.Context,
+	input models.EpisodeWatchProgressInput,
+) (*models.EpisodeWatchProgress, error) {
+	if err := validateWatchProgressIdentity(input.AppUserID); err != nil {
+		return nil, err
+	}
+
+	animeID, err := r.resolveEpisodeAnimeID(ctx, input.EpisodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ReleaseVersionID != nil {
+		belongs, err := r.releaseVersionBelongsToEpisode(ctx, *input.ReleaseVersionID, input.EpisodeID)
+		if err != nil {
+			return nil, err
+		}
+		if !belongs {
+			return nil, fmt.Errorf(
+				"release version %d does not belong to episode %d: %w",
+				*input.ReleaseVersionID, input.EpisodeID, ErrValidation,
+			)
+		}
+	}
+
+	item, err := scanWatchProgress(r.db.QueryRow(ctx, `
+		INSERT INTO episode_watch_progress (
+			app_user_id, anime_id, episode_id, release_version_id,
+			position_seconds, duration_seconds, completed, completed_at,
+			last_watched_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7::boolean,
+			CASE WHEN $7::boolean THEN NOW() ELSE NULL END,
+			NOW(), NOW(), NOW()
+		)
+		ON CONFLICT ON CONSTRAINT uq_episode_watch_progress_user_episode_version
+		DO UPDATE SET
+			position_seconds = EXCLUDED.position_seconds,
+			duration_seconds = COALESCE(EXCLUDED.duration_seconds, episode_watch_progress.duration_seconds),
+			completed = episode_watch_progress.completed OR EXCLUDED.completed,
+			completed_at = COALESCE(episode_watch_progress.completed_at, EXCLUDED.completed_at),
+			last_watched_at = NOW(),
+			updated_at = NOW()
+		RETURNING `+watchProgressColumns,
+		input.AppUserID,
+		animeID,
+		input.EpisodeID,
+		input.ReleaseVersionID,
+		input.PositionSeconds,
+		input.DurationSeconds,
+		input.Completed,
+	))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"upsert watch progress app_user_id %d episode %d: %w",
+			input.AppUserID, input.EpisodeID, err,
+		)
+	}
+
+	return item, nil
+}
+
+// ListByEpisode gibt alle gespeicherten Stände eines Users für eine Episode zurück
+// (einer je Release-Version), der zuletzt gesehene zuerst.
+func (r *WatchProgressRepository) ListByEpisode(
+	ctx context.Context,
+	appUserID int64,
+	episodeID int64,
+) ([]models.EpisodeWatchProgress, error) {
+	if err := validateWatchProgressIdentity(appUserID); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.resolveEpisodeAnimeID(ctx, episodeID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+watchProgressColumns+`
+		FROM episode_watch_progress
+		WHERE app_user_id = $1
+		  AND episode_id = $2
+		ORDER BY last_watched_at DESC, id DESC
+	`, appUserID, episodeID)
+	if err != nil {
+		return nil, fmt.Errorf("query watch progress app_user_id %d episode %d: %w", appUserID, episodeID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.EpisodeWatchProgress, 0, 2)
+	for rows.Next() {
+		item, err := scanWatchProgress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan watch progress row: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watch progress rows: %w", err)
+	}
+
+	return items, nil
+}
+
+// ListContinueWatching liefert pro Anime auf der Watchlist (ohne abgebrochene Einträge)
+// die nächste ungesehene Episode.
+// Anime mit jüngster Aktivität stehen vorne, danach folgt die Watchlist-Reihenfolge.
+// Anime, bei denen nach der zuletzt abgeschlossenen Episode nichts mehr folgt, fallen heraus.
+func (r *WatchProgressRepository) ListContinueWatching(
+	ctx context.Context,
+	filter models.ContinueWatchingFilter,
+) ([]models.ContinueWatchingItem, error) {
+	if err := validateWatchProgressIdentity(filter.AppUserID); err != nil {
+		return nil, err
+	}
+	if err := validateWatchlistIdentity(filter.WatchlistUserID); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.listWatchlistAnime(ctx, filter.WatchlistUserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return []models.ContinueWatchingItem{}, nil
+	}
+
+	animeIDs := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		animeIDs = append(animeIDs, entry.AnimeID)
+	}
+
+	episodesByAnime, episodeTitles, err := r.listEpisodesByAnime(ctx, animeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := r.loadProgressSnapshot(ctx, filter.AppUserID, animeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.ContinueWatchingItem, 0, len(entries))
+	for _, entry := range entries {
+		episodes := episodesByAnime[entry.AnimeID]
+		sortEpisodeIdentities(episodes)
+
+		next, ok := nextUnwatchedEpisode(episodes, progress.completed)
+		if !ok {
+			continue
+		}
+
+		item := models.ContinueWatchingItem{
+			AnimeID:       entry.AnimeID,
+			AnimeTitle:    entry.Title,
+			CoverImage:    entry.CoverImage,
+			EpisodeID:     next.ID,
+			EpisodeNumber: next.EpisodeNumber,
+			EpisodeTitle:  episodeTitles[next.ID],
+			TotalEpisodes: len(episodes),
+			AddedAt:       entry.AddedAt,
+		}
+		for _, episode := range episodes {
+			if progress.completed[episode.ID] {
+				item.CompletedEpisodes++
+			}
+		}
+		if latest, ok := progress.latestByEpisode[next.ID]; ok {
+			item.ReleaseVersionID = latest.ReleaseVersionID
+			item.PositionSeconds = latest.PositionSeconds
+			item.DurationSeconds = latest.DurationSeconds
+		}
+		if lastWatchedAt, ok := progress.lastWatchedByAnime[entry.AnimeID]; ok {
+			watchedAt := lastWatchedAt
+			item.LastWatchedAt = &watchedAt
+		}
+
+		items = append(items, item)
+	}
+
+	sortContinueWatchingItems(items)
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+
+	return items, nil
+}
+
+type continueWatchingEntry struct {
+	AnimeID    int64
+	Title      string
+	CoverImage *string
+	AddedAt    time.Time
+}
+
+type watchProgressSnapshot struct {
+	completed          map[int64]bool
+	latestByEpisode    map[int64]models.EpisodeWatchProgress
+	lastWatchedByAnime map[int64]time.Time
+}
+
+func (r *WatchProgressRepository) listWatchlistAnime(ctx context.Context, userID int64) ([]continueWatchingEntry, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT w.anime_id, a.title, a.cover_image, w.created_at
+		FROM watchlist_entries w
+		INNER JOIN anime a ON a.id = w.anime_id
+		WHERE w.user_id = $1
+		  AND w.list_status <> 'dropped'
+		  AND a.status <> 'disabled'
+		ORDER BY w.created_at DESC, w.id DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query continue-watching watchlist for user_id %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	entries := make([]continueWatchingEntry, 0, 16)
+	for rows.Next() {
+		var entry continueWatchingEntry
+		if err := rows.Scan(&entry.AnimeID, &entry.Title, &entry.CoverImage, &entry.AddedAt); err != nil {
+			return nil, fmt.Errorf("scan continue-watching watchlist row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate continue-watching watchlist rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *WatchProgressRepository) listEpisodesByAnime(
+	ctx context.Context,
+	animeIDs []int64,
+) (map[int64][]episodeIdentity, map[int64]*string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, anime_id, episode_number, title
+		FROM episodes
+		WHERE anime_id = ANY($1)
+		  AND status <> 'disabled'
+	`, animeIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query continue-watching episodes: %w", err)
+	}
+	defer rows.Close()
+
+	episodes := make(map[int64][]episodeIdentity, len(animeIDs))
+	titles := make(map[int64]*string)
+	for rows.Next() {
+		var (
+			item    episodeIdentity
+			animeID int64
+			title   *string
+		)
+		if err := rows.Scan(&item.ID, &animeID, &item.EpisodeNumber, &title); err != nil {
+			return nil, nil, fmt.Errorf("scan continue-watching episode row: %w", err)
+		}
+		episodes[animeID] = append(episodes[animeID], item)
+		titles[item.ID] = title
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate continue-watching episode rows: %w", err)
+	}
+
+	return episodes, titles, nil
+}
+
+func (r *WatchProgressRepository) loadProgressSnapshot(
+	ctx context.Context,
+	appUserID int64,
+	animeIDs []int64,
+) (*watchProgressSnapshot, error) {
+	snapshot := &watchProgressSnapshot{
+		completed:          make(map[int64]bool),
+		latestByEpisode:    make(map[int64]models.EpisodeWatchProgress),
+		lastWatchedByAnime: make(map[int64]time.Time),
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+watchProgressColumns+`
+		FROM episode_watch_progress
+		WHERE app_user_id = $1
+		  AND anime_id = ANY($2)
+		ORDER BY last_watched_at DESC, id DESC
+	`, appUserID, animeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query continue-watching progress app_user_id %d: %w", appUserID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanWatchProgress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan continue-watching progress row: %w", err)
+		}
+		if item.Completed {
+			snapshot.completed[item.EpisodeID] = true
+		}
+		if _, seen := snapshot.latestByEpisode[item.EpisodeID]; !seen {
+			snapshot.latestByEpisode[item.EpisodeID] = *item
+		}
+		if _, seen := snapshot.lastWatchedByAnime[item.AnimeID]; !seen {
+			snapshot.lastWatchedByAnime[item.AnimeID] = item.LastWatchedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate continue-watching progress rows: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+func (r *WatchProgressRepository) resolveEpisodeAnimeID(ctx context.Context, episodeID int64) (int64, error) {
+	var animeID int64
+	if err := r.db.QueryRow(ctx, `
+		SELECT e.anime_id
+		FROM episodes e
+		INNER JOIN anime a ON a.id = e.anime_id
+		WHERE e.id = $1
+		  AND a.status <> 'disabled'
+	`, episodeID).Scan(&animeID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("resolve anime for episode %d: %w", episodeID, err)
+	}
+
+	return animeID, nil
+}
+
+func (r *WatchProgressRepository) releaseVersionBelongsToEpisode(
+	ctx context.Context,
+	releaseVersionID int64,
+	episodeID int64,
+) (bool, error) {
+	var belongs bool
+	if err := r.db.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM release_versions rv
+			INNER JOIN fansub_releases fr ON fr.id = rv.release_id
+			WHERE rv.id = $1
+			  AND (
+				fr.episode_id = $2
+				OR EXISTS(
+					SELECT 1
+					FROM release_variants rvar
+					INNER JOIN release_variant_episodes rve ON rve.release_variant_id = rvar.id
+					WHERE rvar.release_version_id = rv.id
+					  AND rve.episode_id = $2
+				)
+			  )
+		)
+	`, releaseVersionID, episodeID).Scan(&belongs); err != nil {
+		return false, fmt.Errorf("check release version %d for episode %d: %w", releaseVersionID, episodeID, err)
+	}
+
+	return belongs, nil
+}
+
+func scanWatchProgress(row pgx.Row) (*models.EpisodeWatchProgress, error) {
+	var item models.EpisodeWatchProgress
+	if err := row.Scan(
+		&item.AnimeID,
+		&item.EpisodeID,
+		&item.ReleaseVersionID,
+		&item.PositionSeconds,
+		&item.DurationSeconds,
+		&item.Completed,
+		&item.CompletedAt,
+		&item.LastWatchedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// nextUnwatchedEpisode wählt die erste nicht abgeschlossene Episode nach der am weitesten
+// fortgeschrittenen abgeschlossenen Episode. Übersprungene frühere Episoden holen den User
+// nicht zurück an den Anfang. Ohne abgeschlossene Episode ist es die erste Episode.
+func nextUnwatchedEpisode(sorted []episodeIdentity, completed map[int64]bool) (episodeIdentity, bool) {
+	start := 0
+	for index := len(sorted) - 1; index >= 0; index-- {
+		if completed[sorted[index].ID] {
+			start = index + 1
+			break
+		}
+	}
+
+	for _, item := range sorted[start:] {
+		if !completed[item.ID] {
+			return item, true
+		}
+	}
+
+	return episodeIdentity{}, false
+}
+
+func sortContinueWatchingItems(items []models.ContinueWatchingItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		left := items[i]
+		right := items[j]
+		switch {
+		case left.LastWatchedAt != nil && right.LastWatchedAt == nil:
+			return true
+		case left.LastWatchedAt == nil && right.LastWatchedAt != nil:
+			return false
+		case left.LastWatchedAt != nil && right.LastWatchedAt != nil && !left.LastWatchedAt.Equal(*right.LastWatchedAt):
+			return left.LastWatchedAt.After(*right.LastWatchedAt)
+		}
+		return left.AddedAt.After(right.AddedAt)
+	})
+}
+
+func validateWatchProgressIdentity(appUserID int64) error {
+	if appUserID <= 0 {
+		return fmt.Errorf("invalid watch progress app user id: %d", appUserID)
+	}
+
+	return nil
+}
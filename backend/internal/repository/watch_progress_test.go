@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestNextUnwatchedEpisode(t *testing.T) {
+	sorted := []episodeIdentity{
+		{ID: 11, EpisodeNumber: "1"},
+		{ID: 12, EpisodeNumber: "2"},
+		{ID: 13, EpisodeNumber: "3"},
+		{ID: 14, EpisodeNumber: "4"},
+	}
+
+	tests := []struct {
+		name      string
+		completed map[int64]bool
+		wantID    int64
+		wantFound bool
+	}{
+		{
+			name:      "nothing watched starts at first episode",
+			completed: map[int64]bool{},
+			wantID:    11,
+			wantFound: true,
+		},
+		{
+			name:      "continues after last completed episode",
+			completed: map[int64]bool{11: true, 12: true},
+			wantID:    13,
+			wantFound: true,
+		},
+		{
+			name:      "skipped earlier episodes do not reset progress",
+			completed: map[int64]bool{13: true},
+			wantID:    14,
+			wantFound: true,
+		},
+		{
+			name:      "last episode completed means caught up",
+			completed: map[int64]bool{11: true, 14: true},
+			wantFound: false,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := nextUnwatchedEpisode(sorted, tc.completed)
+			if found != tc.wantFound {
+				t.Fatalf("expected found=%v, got %v", tc.wantFound, found)
+			}
+			if found && got.ID != tc.wantID {
+				t.Fatalf("expected episode %d, got %d", tc.wantID, got.ID)
+			}
+		})
+	}
+}
+
+func TestNextUnwatchedEpisodeWithoutEpisodes(t *testing.T) {
+	if _, found := nextUnwatchedEpisode(nil, map[int64]bool{}); found {
+		t.Fatalf("expected no episode for empty anime")
+	}
+}
+
+func TestSortContinueWatchingItems(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	older := base.Add(-time.Hour)
+
+	items := []models.ContinueWatchingItem{
+		{AnimeID: 1, AddedAt: base},
+		{AnimeID: 2, AddedAt: older, LastWatchedAt: &older},
+		{AnimeID: 3, AddedAt: older},
+		{AnimeID: 4, AddedAt: older, LastWatchedAt: &base},
+	}
+
+	sortContinueWatchingItems(items)
+
+	want := []int64{4, 2, 1, 3}
+	for index, animeID := range want {
+		if items[index].AnimeID != animeID {
+			t.Fatalf("position %d: expected anime %d, got %d", index, animeID, items[index].AnimeID)
+		}
+	}
+}
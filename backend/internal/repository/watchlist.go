@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"team4s.v3/backend/internal/models"
 
@@ -19,6 +21,45 @@ func NewWatchlistRepository(db *pgxpool.Pool) *WatchlistRepository {
 	return &WatchlistRepository{db: db}
 }
 
+// WatchlistPatchError beschreibt eine fachlich ungültige Kombination von Listenfeldern
+// (z.B. Enddatum vor Startdatum). Message ist für die API-Antwort gedacht.
+type WatchlistPatchError struct {
+	Message string
+}
+
+func (e *WatchlistPatchError) Error() string {
+	return "watchlist patch: " + e.Message
+}
+
+func (e *WatchlistPatchError) Unwrap() error {
+	return ErrValidation
+}
+
+const watchlistItemColumns = `
+	w.anime_id, a.title, a.type, a.status, a.year, a.cover_image, a.max_episodes, w.created_at,
+	w.list_status, w.score, w.episodes_watched,
+	to_char(w.started_on, 'YYYY-MM-DD'), to_char(w.finished_on, 'YYYY-MM-DD'),
+	w.note, w.updated_at
+`
+
+// watchlistSortColumns ist die Whitelist der Sortierschlüssel für ListByUser.
+var watchlistSortColumns = map[string]string{
+	"added_at":         "w.created_at",
+	"updated_at":       "w.updated_at",
+	"title":            "lower(a.title)",
+	"status":           "w.list_status",
+	"score":            "w.score",
+	"episodes_watched": "w.episodes_watched",
+	"started_on":       "w.started_on",
+	"finished_on":      "w.finished_on",
+}
+
+// IsValidWatchlistSort prüft, ob sortBy ein erlaubter Sortierschlüssel ist.
+func IsValidWatchlistSort(sortBy string) bool {
+	_, ok := watchlistSortColumns[sortBy]
+	return ok
+}
+
 func (r *WatchlistRepository) ListByUser(
 	ctx context.Context,
 	filter models.WatchlistFilter,
@@ -27,24 +68,28 @@ func (r *WatchlistRepository) ListByUser(
 		return nil, 0, err
 	}
 
+	whereClause, args := buildWatchlistWhereClause(filter)
+
 	var total int64
 	if err := r.db.QueryRow(ctx, `
 		SELECT COUNT(*)
-		FROM watchlist_entries
-		WHERE user_id = $1
-	`, filter.UserID).Scan(&total); err != nil {
+		FROM watchlist_entries w
+		INNER JOIN anime a ON a.id = w.anime_id
+		WHERE `+whereClause, args...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count watchlist entries for user_id %d: %w", filter.UserID, err)
 	}
 
 	offset := (filter.Page - 1) * filter.PerPage
-	rows, err := r.db.Query(ctx, `
-		SELECT w.anime_id, a.title, a.type, a.status, a.year, a.cover_image, a.max_episodes, w.created_at
+	limitIndex := len(args) + 1
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s
 		FROM watchlist_entries w
 		INNER JOIN anime a ON a.id = w.anime_id
-		WHERE w.user_id = $1
-		ORDER BY w.created_at DESC, w.id DESC
-		LIMIT $2 OFFSET $3
-	`, filter.UserID, filter.PerPage, offset)
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, watchlistItemColumns, whereClause, buildWatchlistOrderBy(filter.SortBy, filter.SortOrder), limitIndex, limitIndex+1),
+		append(args, filter.PerPage, offset)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("query watchlist entries for user_id %d: %w", filter.UserID, err)
 	}
@@ -52,20 +97,11 @@ func (r *WatchlistRepository) ListByUser(
 
 	items := make([]models.WatchlistItem, 0, filter.PerPage)
 	for rows.Next() {
-		var item models.WatchlistItem
-		if err := rows.Scan(
-			&item.AnimeID,
-			&item.Title,
-			&item.Type,
-			&item.Status,
-			&item.Year,
-			&item.CoverImage,
-			&item.MaxEpisodes,
-			&item.AddedAt,
-		); err != nil {
+		item, err := scanWatchlistItem(rows)
+		if err != nil {
 			return nil, 0, fmt.Errorf("scan watchlist row: %w", err)
 		}
-		items = append(items, item)
+		items = append(items, *item)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -79,6 +115,7 @@ func (r *WatchlistRepository) CreateByUser(
 	ctx context.Context,
 	userID int64,
 	animeID int64,
+	input models.WatchlistEntryInput,
 ) (*models.WatchlistItem, error) {
 	if err := validateWatchlistIdentity(userID); err != nil {
 		return nil, err
@@ -92,23 +129,101 @@ func (r *WatchlistRepository) CreateByUser(
 		return nil, ErrNotFound
 	}
 
-	item, err := r.GetByUserAndAnimeID(ctx, userID, animeID)
-	if err == nil {
-		return item, nil
+	// Ein bestehender Eintrag wird nicht stillschweigend zurückgegeben: die übergebenen Felder
+	// gelten wie bei einem PATCH, damit ein wiederholtes POST keine Eingaben verwirft.
+	if _, err := r.GetByUserAndAnimeID(ctx, userID, animeID); err == nil {
+		return r.UpdateByUser(ctx, userID, animeID, watchlistPatchFromInput(input))
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	maxEpisodes, err := r.animeMaxEpisodes(ctx, animeID)
+	if err != nil {
+		return nil, err
 	}
-	if !errors.Is(err, ErrNotFound) {
+
+	entry, err := applyWatchlistPatch(
+		models.WatchlistItem{ListStatus: models.WatchlistStatusPlanned, MaxEpisodes: maxEpisodes},
+		watchlistPatchFromInput(input),
+		time.Now(),
+	)
+	if err != nil {
 		return nil, err
 	}
 
-	if _, err := r.db.Exec(ctx, `
-		INSERT INTO watchlist_entries (user_id, anime_id)
-		VALUES ($1, $2)
-		ON CONFLICT (user_id, anime_id)
-		DO UPDATE SET
-			updated_at = NOW()
-	`, userID, animeID); err != nil {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO watchlist_entries (
+			user_id, anime_id, list_status, score, episodes_watched, started_on, finished_on, note
+		)
+		VALUES ($1, $2, $3, $4, $5, $6::date, $7::date, $8)
+		ON CONFLICT (user_id, anime_id) DO NOTHING
+	`,
+		userID,
+		animeID,
+		entry.ListStatus,
+		entry.Score,
+		entry.EpisodesWatched,
+		entry.StartedOn,
+		entry.FinishedOn,
+		entry.Note,
+	)
+	if err != nil {
 		return nil, fmt.Errorf("insert watchlist entry user_id %d anime %d: %w", userID, animeID, err)
 	}
+	if tag.RowsAffected() == 0 {
+		// Paralleles POST hat den Eintrag zuerst angelegt.
+		return r.UpdateByUser(ctx, userID, animeID, watchlistPatchFromInput(input))
+	}
+
+	return r.GetByUserAndAnimeID(ctx, userID, animeID)
+}
+
+// UpdateByUser schreibt ein partielles Update eines Listeneintrags inklusive der
+// Lebenszyklus-Regeln aus applyWatchlistPatch. Gibt ErrNotFound zurück, wenn kein
+// Eintrag existiert, und *WatchlistPatchError bei widersprüchlichen Feldern.
+func (r *WatchlistRepository) UpdateByUser(
+	ctx context.Context,
+	userID int64,
+	animeID int64,
+	patch models.WatchlistEntryPatchInput,
+) (*models.WatchlistItem, error) {
+	current, err := r.GetByUserAndAnimeID(ctx, userID, animeID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := applyWatchlistPatch(*current, patch, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE watchlist_entries
+		SET list_status = $3,
+			score = $4,
+			episodes_watched = $5,
+			started_on = $6::date,
+			finished_on = $7::date,
+			note = $8,
+			updated_at = NOW()
+		WHERE user_id = $1
+		  AND anime_id = $2
+	`,
+		userID,
+		animeID,
+		next.ListStatus,
+		next.Score,
+		next.EpisodesWatched,
+		next.StartedOn,
+		next.FinishedOn,
+		next.Note,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update watchlist entry user_id %d anime %d: %w", userID, animeID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
 
 	return r.GetByUserAndAnimeID(ctx, userID, animeID)
 }
@@ -142,15 +257,45 @@ func (r *WatchlistRepository) GetByUserAndAnimeID(
 		return nil, err
 	}
 
-	var item models.WatchlistItem
-	if err := r.db.QueryRow(ctx, `
-		SELECT w.anime_id, a.title, a.type, a.status, a.year, a.cover_image, a.max_episodes, w.created_at
+	item, err := scanWatchlistItem(r.db.QueryRow(ctx, `
+		SELECT `+watchlistItemColumns+`
 		FROM watchlist_entries w
 		INNER JOIN anime a ON a.id = w.anime_id
 		WHERE w.user_id = $1
 		  AND w.anime_id = $2
 		LIMIT 1
-	`, userID, animeID).Scan(
+	`, userID, animeID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query watchlist entry user_id %d anime %d: %w", userID, animeID, err)
+	}
+
+	return item, nil
+}
+
+func (r *WatchlistRepository) animeExists(ctx context.Context, animeID int64) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM anime WHERE id = $1)`, animeID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check anime existence %d: %w", animeID, err)
+	}
+
+	return exists, nil
+}
+
+func (r *WatchlistRepository) animeMaxEpisodes(ctx context.Context, animeID int64) (*int16, error) {
+	var maxEpisodes *int16
+	if err := r.db.QueryRow(ctx, `SELECT max_episodes FROM anime WHERE id = $1`, animeID).Scan(&maxEpisodes); err != nil {
+		return nil, fmt.Errorf("query max episodes for anime %d: %w", animeID, err)
+	}
+
+	return maxEpisodes, nil
+}
+
+func scanWatchlistItem(row pgx.Row) (*models.WatchlistItem, error) {
+	var item models.WatchlistItem
+	if err := row.Scan(
 		&item.AnimeID,
 		&item.Title,
 		&item.Type,
@@ -159,23 +304,173 @@ func (r *WatchlistRepository) GetByUserAndAnimeID(
 		&item.CoverImage,
 		&item.MaxEpisodes,
 		&item.AddedAt,
+		&item.ListStatus,
+		&item.Score,
+		&item.EpisodesWatched,
+		&item.StartedOn,
+		&item.FinishedOn,
+		&item.Note,
+		&item.UpdatedAt,
 	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrNotFound
-		}
-		return nil, fmt.Errorf("query watchlist entry user_id %d anime %d: %w", userID, animeID, err)
+		return nil, err
 	}
 
 	return &item, nil
 }
 
-func (r *WatchlistRepository) animeExists(ctx context.Context, animeID int64) (bool, error) {
-	var exists bool
-	if err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM anime WHERE id = $1)`, animeID).Scan(&exists); err != nil {
-		return false, fmt.Errorf("check anime existence %d: %w", animeID, err)
+// buildWatchlistWhereClause übersetzt die optionalen Listenfilter in eine WHERE-Bedingung.
+// $1 ist immer die user_id.
+func buildWatchlistWhereClause(filter models.WatchlistFilter) (string, []any) {
+	conditions := []string{"w.user_id = $1"}
+	args := []any{filter.UserID}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
 	}
 
-	return exists, nil
+	if len(filter.Statuses) > 0 {
+		add("w.list_status = ANY($%d)", filter.Statuses)
+	}
+	if filter.ScoreMin != nil {
+		add("w.score >= $%d", *filter.ScoreMin)
+	}
+	if filter.ScoreMax != nil {
+		add("w.score <= $%d", *filter.ScoreMax)
+	}
+	if filter.EpisodesWatchedMin != nil {
+		add("w.episodes_watched >= $%d", *filter.EpisodesWatchedMin)
+	}
+	if filter.StartedFrom != nil {
+		add("w.started_on >= $%d::date", *filter.StartedFrom)
+	}
+	if filter.StartedTo != nil {
+		add("w.started_on <= $%d::date", *filter.StartedTo)
+	}
+	if filter.FinishedFrom != nil {
+		add("w.finished_on >= $%d::date", *filter.FinishedFrom)
+	}
+	if filter.FinishedTo != nil {
+		add("w.finished_on <= $%d::date", *filter.FinishedTo)
+	}
+	if filter.HasNote != nil {
+		if *filter.HasNote {
+			conditions = append(conditions, "COALESCE(btrim(w.note), '') <> ''")
+		} else {
+			conditions = append(conditions, "COALESCE(btrim(w.note), '') = ''")
+		}
+	}
+
+	return strings.Join(conditions, "\n\t\t  AND "), args
+}
+
+// buildWatchlistOrderBy liefert die ORDER BY-Klausel. Unbekannte Schlüssel fallen auf
+// added_at zurück; NULL-Werte (ohne Wertung/Datum) stehen immer am Ende.
+func buildWatchlistOrderBy(sortBy string, sortOrder string) string {
+	column, ok := watchlistSortColumns[sortBy]
+	if !ok {
+		column = watchlistSortColumns["added_at"]
+	}
+
+	direction := "DESC"
+	if strings.EqualFold(sortOrder, "asc") {
+		direction = "ASC"
+	}
+
+	return fmt.Sprintf("%s %s NULLS LAST, w.created_at DESC, w.id DESC", column, direction)
+}
+
+func watchlistPatchFromInput(input models.WatchlistEntryInput) models.WatchlistEntryPatchInput {
+	var patch models.WatchlistEntryPatchInput
+	if input.ListStatus != nil {
+		patch.ListStatus = models.OptionalString{Set: true, Value: input.ListStatus}
+	}
+	if input.Score != nil {
+		patch.Score = models.OptionalInt16{Set: true, Value: input.Score}
+	}
+	if input.EpisodesWatched != nil {
+		patch.EpisodesWatched = models.OptionalInt32{Set: true, Value: input.EpisodesWatched}
+	}
+	if input.StartedOn != nil {
+		patch.StartedOn = models.OptionalString{Set: true, Value: input.StartedOn}
+	}
+	if input.FinishedOn != nil {
+		patch.FinishedOn = models.OptionalString{Set: true, Value: input.FinishedOn}
+	}
+	if input.Note != nil {
+		patch.Note = models.OptionalString{Set: true, Value: input.Note}
+	}
+	return patch
+}
+
+// applyWatchlistPatch wendet ein Patch auf einen Listeneintrag an und ergänzt die
+// Lebenszyklus-Felder: Wechsel auf "watching" setzt ein fehlendes Startdatum, Wechsel auf
+// "completed" setzt ein fehlendes Enddatum und – bei bekannter Episodenzahl – den
+// Episodenzähler auf das Maximum. Explizit mitgesendete Felder haben immer Vorrang.
+func applyWatchlistPatch(
+	current models.WatchlistItem,
+	patch models.WatchlistEntryPatchInput,
+	now time.Time,
+) (models.WatchlistItem, error) {
+	next := current
+	today := now.Format("2006-01-02")
+
+	if patch.ListStatus.Set {
+		if patch.ListStatus.Value == nil || !models.IsValidWatchlistStatus(*patch.ListStatus.Value) {
+			return current, &WatchlistPatchError{Message: "ungültiger listenstatus"}
+		}
+		next.ListStatus = *patch.ListStatus.Value
+	}
+	if patch.Score.Set {
+		next.Score = patch.Score.Value
+	}
+	if patch.EpisodesWatched.Set {
+		if patch.EpisodesWatched.Value == nil {
+			next.EpisodesWatched = 0
+		} else {
+			next.EpisodesWatched = *patch.EpisodesWatched.Value
+		}
+	}
+	if patch.StartedOn.Set {
+		next.StartedOn = patch.StartedOn.Value
+	}
+	if patch.FinishedOn.Set {
+		next.FinishedOn = patch.FinishedOn.Value
+	}
+	if patch.Note.Set {
+		next.Note = patch.Note.Value
+	}
+
+	if next.ListStatus != current.ListStatus {
+		switch next.ListStatus {
+		case models.WatchlistStatusWatching:
+			if next.StartedOn == nil && !patch.StartedOn.Set {
+				next.StartedOn = &today
+			}
+		case models.WatchlistStatusCompleted:
+			if next.FinishedOn == nil && !patch.FinishedOn.Set {
+				next.FinishedOn = &today
+			}
+			if next.MaxEpisodes != nil && *next.MaxEpisodes > 0 && !patch.EpisodesWatched.Set {
+				next.EpisodesWatched = int32(*next.MaxEpisodes)
+			}
+		}
+	}
+
+	if next.Score != nil && (*next.Score < 1 || *next.Score > 10) {
+		return current, &WatchlistPatchError{Message: "score muss zwischen 1 und 10 liegen"}
+	}
+	if next.EpisodesWatched < 0 {
+		return current, &WatchlistPatchError{Message: "episodes_watched darf nicht negativ sein"}
+	}
+	if next.MaxEpisodes != nil && *next.MaxEpisodes > 0 && next.EpisodesWatched > int32(*next.MaxEpisodes) {
+		return current, &WatchlistPatchError{Message: "episodes_watched überschreitet die episodenanzahl"}
+	}
+	if next.StartedOn != nil && next.FinishedOn != nil && *next.FinishedOn < *next.StartedOn {
+		return current, &WatchlistPatchError{Message: "finished_on darf nicht vor started_on liegen"}
+	}
+
+	return next, nil
 }
 
 func validateWatchlistIdentity(userID int64) error {
@@ -0,0 +1,577 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Präfixe der externen Listen-IDs in anime_source_links (analog zu "anisearch:<id>").
+const (
+	watchlistImportMALSourcePrefix     = "mal:"
+	watchlistImportAniListSourcePrefix = "anilist:"
+)
+
+// watchlistImportTitleKeySQL normalisiert Titel in SQL genauso wie normalizeWatchlistImportTitle in Go.
+const watchlistImportTitleKeySQL = `lower(regexp_replace(btrim(%s), '\s+', ' ', 'g'))`
+
+// PreviewImport ordnet importierte Listeneinträge lokalen Anime zu. Reihenfolge:
+// MAL-/AniList-ID über anime_source_links, danach exakter (normalisierter) Titel gegen
+// anime.title/title_de/title_en und anime_titles. Es wird nichts geschrieben.
+func (r *WatchlistRepository) PreviewImport(
+	ctx context.Context,
+	userID int64,
+	entries []models.WatchlistImportEntry,
+) ([]models.WatchlistImportPreviewItem, error) {
+	if err := validateWatchlistIdentity(userID); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return []models.WatchlistImportPreviewItem{}, nil
+	}
+
+	sourceMatches, err := r.loadImportSourceMatches(ctx, collectWatchlistImportSourceKeys(entries))
+	if err != nil {
+		return nil, err
+	}
+	titleMatches, err := r.loadImportTitleMatches(ctx, collectWatchlistImportTitleKeys(entries))
+	if err != nil {
+		return nil, err
+	}
+
+	items := resolveWatchlistImportMatches(entries, sourceMatches, titleMatches)
+
+	animeIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		if item.AnimeID != nil {
+			animeIDs = append(animeIDs, *item.AnimeID)
+		}
+		for _, candidate := range item.Candidates {
+			animeIDs = append(animeIDs, candidate.AnimeID)
+		}
+	}
+	if len(animeIDs) == 0 {
+		return items, nil
+	}
+
+	titles, err := r.loadImportAnimeTitles(ctx, animeIDs)
+	if err != nil {
+		return nil, err
+	}
+	onList, err := r.loadWatchlistAnimeIDs(ctx, r.db, userID, animeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range items {
+		item := &items[index]
+		if item.AnimeID != nil {
+			if title, ok := titles[*item.AnimeID]; ok {
+				item.AnimeTitle = &title
+			}
+			_, item.OnWatchlist = onList[*item.AnimeID]
+		}
+		for candidateIndex := range item.Candidates {
+			item.Candidates[candidateIndex].Title = titles[item.Candidates[candidateIndex].AnimeID]
+		}
+	}
+
+	return items, nil
+}
+
+// ApplyImport schreibt bestätigte Importeinträge in einer Transaktion. Vorhandene Einträge
+// werden nur mit overwrite=true ersetzt. Fachlich ungültige Einträge (z.B. Enddatum vor
+// Startdatum) landen in Failed, ohne den restlichen Import abzubrechen.
+func (r *WatchlistRepository) ApplyImport(
+	ctx context.Context,
+	userID int64,
+	entries []models.WatchlistImportApplyEntry,
+	overwrite bool,
+) (*models.WatchlistImportResult, error) {
+	if err := validateWatchlistIdentity(userID); err != nil {
+		return nil, err
+	}
+
+	result := &models.WatchlistImportResult{Failed: []models.WatchlistImportFailure{}}
+	if len(entries) == 0 {
+		return result, nil
+	}
+
+	animeIDs := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		animeIDs = append(animeIDs, entry.AnimeID)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin watchlist import tx user_id %d: %w", userID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	maxEpisodesByAnime := make(map[int64]*int16, len(animeIDs))
+	rows, err := tx.Query(ctx, `SELECT id, max_episodes FROM anime WHERE id = ANY($1)`, animeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query anime for watchlist import user_id %d: %w", userID, err)
+	}
+	for rows.Next() {
+		var animeID int64
+		var maxEpisodes *int16
+		if err := rows.Scan(&animeID, &maxEpisodes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan anime for watchlist import: %w", err)
+		}
+		maxEpisodesByAnime[animeID] = maxEpisodes
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate anime for watchlist import: %w", err)
+	}
+
+	existing, err := r.loadWatchlistAnimeIDs(ctx, tx, userID, animeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	processed := make(map[int64]struct{}, len(entries))
+	for _, entry := range entries {
+		if _, duplicate := processed[entry.AnimeID]; duplicate {
+			result.Skipped++
+			continue
+		}
+		processed[entry.AnimeID] = struct{}{}
+
+		maxEpisodes, ok := maxEpisodesByAnime[entry.AnimeID]
+		if !ok {
+			result.Failed = append(result.Failed, models.WatchlistImportFailure{AnimeID: entry.AnimeID, Message: "anime nicht gefunden"})
+			continue
+		}
+		_, onList := existing[entry.AnimeID]
+		if onList && !overwrite {
+			result.Skipped++
+			continue
+		}
+
+		item, err := buildWatchlistImportItem(entry, maxEpisodes, now)
+		if err != nil {
+			var patchErr *WatchlistPatchError
+			if errors.As(err, &patchErr) {
+				result.Failed = append(result.Failed, models.WatchlistImportFailure{AnimeID: entry.AnimeID, Message: patchErr.Message})
+				continue
+			}
+			return nil, err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO watchlist_entries (
+				user_id, anime_id, list_status, score, episodes_watched, started_on, finished_on, note
+			)
+			VALUES ($1, $2, $3, $4, $5, $6::date, $7::date, $8)
+			ON CONFLICT (user_id, anime_id)
+			DO UPDATE SET
+				list_status = EXCLUDED.list_status,
+				score = EXCLUDED.score,
+				episodes_watched = EXCLUDED.episodes_watched,
+				started_on = EXCLUDED.started_on,
+				finished_on = EXCLUDED.finished_on,
+				note = EXCLUDED.note,
+				updated_at = NOW()
+		`,
+			userID,
+			entry.AnimeID,
+			item.ListStatus,
+			item.Score,
+			item.EpisodesWatched,
+			item.StartedOn,
+			item.FinishedOn,
+			item.Note,
+		); err != nil {
+			return nil, fmt.Errorf("import watchlist entry user_id %d anime %d: %w", userID, entry.AnimeID, err)
+		}
+
+		if onList {
+			result.Updated++
+		} else {
+			result.Created++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit watchlist import tx user_id %d: %w", userID, err)
+	}
+
+	return result, nil
+}
+
+// ListForExport liefert die komplette Watchlist eines Users (älteste zuerst) inklusive der
+// MAL-/AniList-IDs aus anime_source_links.
+func (r *WatchlistRepository) ListForExport(ctx context.Context, userID int64) ([]models.WatchlistExportItem, error) {
+	if err := validateWatchlistIdentity(userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+watchlistItemColumns+`,
+			ARRAY(
+				SELECT asl.source
+				FROM anime_source_links asl
+				WHERE asl.anime_id = w.anime_id
+				  AND (asl.source LIKE 'mal:%' OR asl.source LIKE 'anilist:%')
+				ORDER BY asl.source
+			)
+		FROM watchlist_entries w
+		INNER JOIN anime a ON a.id = w.anime_id
+		WHERE w.user_id = $1
+		ORDER BY w.created_at ASC, w.id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist export user_id %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.WatchlistExportItem, 0)
+	for rows.Next() {
+		var item models.WatchlistExportItem
+		var sources []string
+		if err := rows.Scan(
+			&item.AnimeID,
+			&item.Title,
+			&item.Type,
+			&item.Status,
+			&item.Year,
+			&item.CoverImage,
+			&item.MaxEpisodes,
+			&item.AddedAt,
+			&item.ListStatus,
+			&item.Score,
+			&item.EpisodesWatched,
+			&item.StartedOn,
+			&item.FinishedOn,
+			&item.Note,
+			&item.UpdatedAt,
+			&sources,
+		); err != nil {
+			return nil, fmt.Errorf("scan watchlist export row: %w", err)
+		}
+		item.MALID, item.AniListID = parseWatchlistExternalIDs(sources)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlist export rows: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *WatchlistRepository) loadImportSourceMatches(ctx context.Context, sources []string) (map[string]int64, error) {
+	matches := make(map[string]int64, len(sources))
+	if len(sources) == 0 {
+		return matches, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT asl.source, asl.anime_id
+		FROM anime_source_links asl
+		WHERE asl.source = ANY($1::text[])
+		UNION
+		SELECT a.source, a.id
+		FROM anime a
+		WHERE a.source = ANY($1::text[])
+	`, sources)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist import source matches: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var source string
+		var animeID int64
+		if err := rows.Scan(&source, &animeID); err != nil {
+			return nil, fmt.Errorf("scan watchlist import source match: %w", err)
+		}
+		matches[source] = animeID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlist import source matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+func (r *WatchlistRepository) loadImportTitleMatches(ctx context.Context, keys []string) (map[string][]int64, error) {
+	matches := make(map[string][]int64, len(keys))
+	if len(keys) == 0 {
+		return matches, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT title_matches.title_key, title_matches.anime_id
+		FROM (
+			SELECT `+fmt.Sprintf(watchlistImportTitleKeySQL, "a.title")+` AS title_key, a.id AS anime_id
+			FROM anime a
+			UNION ALL
+			SELECT `+fmt.Sprintf(watchlistImportTitleKeySQL, "a.title_de")+`, a.id
+			FROM anime a
+			WHERE a.title_de IS NOT NULL
+			UNION ALL
+			SELECT `+fmt.Sprintf(watchlistImportTitleKeySQL, "a.title_en")+`, a.id
+			FROM anime a
+			WHERE a.title_en IS NOT NULL
+			UNION ALL
+			SELECT `+fmt.Sprintf(watchlistImportTitleKeySQL, "at.title")+`, at.anime_id
+			FROM anime_titles at
+		) title_matches
+		WHERE title_matches.title_key = ANY($1::text[])
+		ORDER BY title_matches.title_key, title_matches.anime_id
+	`, keys)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist import title matches: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var animeID int64
+		if err := rows.Scan(&key, &animeID); err != nil {
+			return nil, fmt.Errorf("scan watchlist import title match: %w", err)
+		}
+		matches[key] = append(matches[key], animeID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlist import title matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+func (r *WatchlistRepository) loadImportAnimeTitles(ctx context.Context, animeIDs []int64) (map[int64]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, `+primaryNormalizedTitleSQL("a.id", "a.title")+`
+		FROM anime a
+		WHERE a.id = ANY($1)
+	`, animeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist import anime titles: %w", err)
+	}
+	defer rows.Close()
+
+	titles := make(map[int64]string, len(animeIDs))
+	for rows.Next() {
+		var animeID int64
+		var title string
+		if err := rows.Scan(&animeID, &title); err != nil {
+			return nil, fmt.Errorf("scan watchlist import anime title: %w", err)
+		}
+		titles[animeID] = title
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlist import anime titles: %w", err)
+	}
+
+	return titles, nil
+}
+
+type watchlistImportQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (r *WatchlistRepository) loadWatchlistAnimeIDs(
+	ctx context.Context,
+	q watchlistImportQuerier,
+	userID int64,
+	animeIDs []int64,
+) (map[int64]struct{}, error) {
+	rows, err := q.Query(ctx, `
+		SELECT anime_id
+		FROM watchlist_entries
+		WHERE user_id = $1
+		  AND anime_id = ANY($2)
+	`, userID, animeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist anime ids user_id %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]struct{}, len(animeIDs))
+	for rows.Next() {
+		var animeID int64
+		if err := rows.Scan(&animeID); err != nil {
+			return nil, fmt.Errorf("scan watchlist anime id: %w", err)
+		}
+		ids[animeID] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlist anime ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// resolveWatchlistImportMatches entscheidet die Zuordnung je Eintrag. Eine Source-Verknüpfung
+// gewinnt immer; sonst zählt zuerst der Haupttitel und nur wenn dieser nichts findet die
+// Alternativtitel. Mehrere Treffer ergeben "ambiguous" mit Kandidatenliste.
+func resolveWatchlistImportMatches(
+	entries []models.WatchlistImportEntry,
+	sourceMatches map[string]int64,
+	titleMatches map[string][]int64,
+) []models.WatchlistImportPreviewItem {
+	items := make([]models.WatchlistImportPreviewItem, 0, len(entries))
+	for index, entry := range entries {
+		item := models.WatchlistImportPreviewItem{
+			Index: index,
+			Entry: entry,
+			Match: models.WatchlistImportMatchUnmatched,
+		}
+
+		if animeID, ok := lookupWatchlistImportSource(entry, sourceMatches); ok {
+			item.Match = models.WatchlistImportMatchSource
+			item.AnimeID = &animeID
+			items = append(items, item)
+			continue
+		}
+
+		candidates := titleMatches[normalizeWatchlistImportTitle(entry.Title)]
+		if len(candidates) == 0 {
+			candidates = collectWatchlistImportAltCandidates(entry.AltTitles, titleMatches)
+		}
+
+		switch len(candidates) {
+		case 0:
+		case 1:
+			animeID := candidates[0]
+			item.Match = models.WatchlistImportMatchTitle
+			item.AnimeID = &animeID
+		default:
+			item.Match = models.WatchlistImportMatchAmbiguous
+			item.Candidates = make([]models.WatchlistImportCandidate, 0, len(candidates))
+			for _, animeID := range candidates {
+				item.Candidates = append(item.Candidates, models.WatchlistImportCandidate{AnimeID: animeID})
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func lookupWatchlistImportSource(entry models.WatchlistImportEntry, sourceMatches map[string]int64) (int64, bool) {
+	if entry.MALID != nil {
+		if animeID, ok := sourceMatches[watchlistImportMALSourcePrefix+strconv.FormatInt(*entry.MALID, 10)]; ok {
+			return animeID, true
+		}
+	}
+	if entry.AniListID != nil {
+		if animeID, ok := sourceMatches[watchlistImportAniListSourcePrefix+strconv.FormatInt(*entry.AniListID, 10)]; ok {
+			return animeID, true
+		}
+	}
+	return 0, false
+}
+
+func collectWatchlistImportAltCandidates(altTitles []string, titleMatches map[string][]int64) []int64 {
+	seen := make(map[int64]struct{})
+	candidates := make([]int64, 0)
+	for _, title := range altTitles {
+		for _, animeID := range titleMatches[normalizeWatchlistImportTitle(title)] {
+			if _, ok := seen[animeID]; ok {
+				continue
+			}
+			seen[animeID] = struct{}{}
+			candidates = append(candidates, animeID)
+		}
+	}
+	return candidates
+}
+
+func collectWatchlistImportSourceKeys(entries []models.WatchlistImportEntry) []string {
+	keys := make([]string, 0, len(entries)*2)
+	for _, entry := range entries {
+		if entry.MALID != nil {
+			keys = append(keys, watchlistImportMALSourcePrefix+strconv.FormatInt(*entry.MALID, 10))
+		}
+		if entry.AniListID != nil {
+			keys = append(keys, watchlistImportAniListSourcePrefix+strconv.FormatInt(*entry.AniListID, 10))
+		}
+	}
+	return keys
+}
+
+func collectWatchlistImportTitleKeys(entries []models.WatchlistImportEntry) []string {
+	seen := make(map[string]struct{})
+	keys := make([]string, 0, len(entries))
+	add := func(title string) {
+		key := normalizeWatchlistImportTitle(title)
+		if key == "" {
+			return
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	for _, entry := range entries {
+		add(entry.Title)
+		for _, alt := range entry.AltTitles {
+			add(alt)
+		}
+	}
+	return keys
+}
+
+// normalizeWatchlistImportTitle: Kleinschreibung, getrimmt, Whitespace zu einem Leerzeichen.
+func normalizeWatchlistImportTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// buildWatchlistImportItem übernimmt alle Felder explizit, damit der Lebenszyklus keine
+// Daten ergänzt, die der User in der Quelle nie gesetzt hat. Episodenzähler über der lokalen
+// Episodenzahl (abweichende Zählung bei MAL/AniList) werden auf das Maximum begrenzt.
+func buildWatchlistImportItem(
+	entry models.WatchlistImportApplyEntry,
+	maxEpisodes *int16,
+	now time.Time,
+) (models.WatchlistItem, error) {
+	status := entry.ListStatus
+	episodesWatched := entry.EpisodesWatched
+	if maxEpisodes != nil && *maxEpisodes > 0 && episodesWatched > int32(*maxEpisodes) {
+		episodesWatched = int32(*maxEpisodes)
+	}
+
+	return applyWatchlistPatch(
+		models.WatchlistItem{ListStatus: models.WatchlistStatusPlanned, MaxEpisodes: maxEpisodes},
+		models.WatchlistEntryPatchInput{
+			ListStatus:      models.OptionalString{Set: true, Value: &status},
+			Score:           models.OptionalInt16{Set: true, Value: entry.Score},
+			EpisodesWatched: models.OptionalInt32{Set: true, Value: &episodesWatched},
+			StartedOn:       models.OptionalString{Set: true, Value: entry.StartedOn},
+			FinishedOn:      models.OptionalString{Set: true, Value: entry.FinishedOn},
+			Note:            models.OptionalString{Set: true, Value: entry.Note},
+		},
+		now,
+	)
+}
+
+func parseWatchlistExternalIDs(sources []string) (*int64, *int64) {
+	var malID, aniListID *int64
+	for _, source := range sources {
+		switch {
+		case malID == nil && strings.HasPrefix(source, watchlistImportMALSourcePrefix):
+			if id, err := strconv.ParseInt(strings.TrimPrefix(source, watchlistImportMALSourcePrefix), 10, 64); err == nil && id > 0 {
+				malID = &id
+			}
+		case aniListID == nil && strings.HasPrefix(source, watchlistImportAniListSourcePrefix):
+			if id, err := strconv.ParseInt(strings.TrimPrefix(source, watchlistImportAniListSourcePrefix), 10, 64); err == nil && id > 0 {
+				aniListID = &id
+			}
+		}
+	}
+	return malID, aniListID
+}
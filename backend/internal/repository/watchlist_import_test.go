@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestResolveWatchlistImportMatches(t *testing.T) {
+	malID := int64(1)
+	aniListID := int64(99)
+
+	entries := []models.WatchlistImportEntry{
+		{MALID: &malID, Title: "Cowboy Bebop"},
+		{AniListID: &aniListID, Title: "Unbekannt"},
+		{Title: "  Shingeki   no Kyojin "},
+		{Title: "Hunter x Hunter"},
+		{Title: "Kein Treffer", AltTitles: []string{"Attack on Titan"}},
+		{Title: "Nirgends"},
+	}
+	sourceMatches := map[string]int64{
+		"mal:1":      10,
+		"anilist:99": 20,
+	}
+	titleMatches := map[string][]int64{
+		"cowboy bebop":       {11},
+		"shingeki no kyojin": {30},
+		"hunter x hunter":    {40, 41},
+		"attack on titan":    {30},
+	}
+
+	items := resolveWatchlistImportMatches(entries, sourceMatches, titleMatches)
+
+	tests := []struct {
+		match      string
+		animeID    int64
+		candidates int
+	}{
+		{match: models.WatchlistImportMatchSource, animeID: 10},
+		{match: models.WatchlistImportMatchSource, animeID: 20},
+		{match: models.WatchlistImportMatchTitle, animeID: 30},
+		{match: models.WatchlistImportMatchAmbiguous, candidates: 2},
+		{match: models.WatchlistImportMatchTitle, animeID: 30},
+		{match: models.WatchlistImportMatchUnmatched},
+	}
+
+	if len(items) != len(tests) {
+		t.Fatalf("expected %d items, got %d", len(tests), len(items))
+	}
+	for index, want := range tests {
+		got := items[index]
+		if got.Index != index || got.Match != want.match {
+			t.Fatalf("item %d: expected match %q, got %q", index, want.match, got.Match)
+		}
+		if want.animeID != 0 && (got.AnimeID == nil || *got.AnimeID != want.animeID) {
+			t.Fatalf("item %d: expected anime %d, got %v", index, want.animeID, got.AnimeID)
+		}
+		if want.animeID == 0 && got.AnimeID != nil {
+			t.Fatalf("item %d: expected no anime, got %d", index, *got.AnimeID)
+		}
+		if len(got.Candidates) != want.candidates {
+			t.Fatalf("item %d: expected %d candidates, got %d", index, want.candidates, len(got.Candidates))
+		}
+	}
+}
+
+func TestCollectWatchlistImportTitleKeys(t *testing.T) {
+	keys := collectWatchlistImportTitleKeys([]models.WatchlistImportEntry{
+		{Title: "One Piece", AltTitles: []string{"ONE  PIECE", "ワンピース"}},
+		{Title: ""},
+	})
+	if len(keys) != 2 || keys[0] != "one piece" || keys[1] != "ワンピース" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+}
+
+func TestBuildWatchlistImportItem(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	maxEpisodes := int16(12)
+
+	item, err := buildWatchlistImportItem(models.WatchlistImportApplyEntry{
+		AnimeID:         1,
+		ListStatus:      models.WatchlistStatusCompleted,
+		EpisodesWatched: 13,
+	}, &maxEpisodes, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.EpisodesWatched != 12 {
+		t.Fatalf("expected episodes to be clamped to 12, got %d", item.EpisodesWatched)
+	}
+	if item.FinishedOn != nil {
+		t.Fatalf("expected import not to invent a finish date, got %v", *item.FinishedOn)
+	}
+
+	started := "2026-02-01"
+	finished := "2026-01-01"
+	if _, err := buildWatchlistImportItem(models.WatchlistImportApplyEntry{
+		AnimeID:    1,
+		ListStatus: models.WatchlistStatusCompleted,
+		StartedOn:  &started,
+		FinishedOn: &finished,
+	}, nil, now); err == nil {
+		t.Fatalf("expected error for finish before start")
+	}
+}
+
+func TestParseWatchlistExternalIDs(t *testing.T) {
+	malID, aniListID := parseWatchlistExternalIDs([]string{"anilist:21", "mal:abc", "mal:20"})
+	if malID == nil || *malID != 20 {
+		t.Fatalf("expected mal id 20, got %v", malID)
+	}
+	if aniListID == nil || *aniListID != 21 {
+		t.Fatalf("expected anilist id 21, got %v", aniListID)
+	}
+
+	malID, aniListID = parseWatchlistExternalIDs(nil)
+	if malID != nil || aniListID != nil {
+		t.Fatalf("expected no ids for empty sources")
+	}
+}
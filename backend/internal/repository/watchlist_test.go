@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestApplyWatchlistPatchLifecycle(t *testing.T) {
+	now := time.Date(2026, 4, 2, 18, 0, 0, 0, time.UTC)
+	maxEpisodes := int16(12)
+	watching := models.WatchlistStatusWatching
+	completed := models.WatchlistStatusCompleted
+	explicitStart := "2026-03-01"
+
+	t.Run("watching sets missing start date", func(t *testing.T) {
+		next, err := applyWatchlistPatch(
+			models.WatchlistItem{ListStatus: models.WatchlistStatusPlanned},
+			models.WatchlistEntryPatchInput{ListStatus: models.OptionalString{Set: true, Value: &watching}},
+			now,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if next.StartedOn == nil || *next.StartedOn != "2026-04-02" {
+			t.Fatalf("expected started_on 2026-04-02, got %v", next.StartedOn)
+		}
+	})
+
+	t.Run("explicit start date wins", func(t *testing.T) {
+		next, err := applyWatchlistPatch(
+			models.WatchlistItem{ListStatus: models.WatchlistStatusPlanned},
+			models.WatchlistEntryPatchInput{
+				ListStatus: models.OptionalString{Set: true, Value: &watching},
+				StartedOn:  models.OptionalString{Set: true, Value: &explicitStart},
+			},
+			now,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if next.StartedOn == nil || *next.StartedOn != explicitStart {
+			t.Fatalf("expected started_on %s, got %v", explicitStart, next.StartedOn)
+		}
+	})
+
+	t.Run("completed fills finish date and episode count", func(t *testing.T) {
+		next, err := applyWatchlistPatch(
+			models.WatchlistItem{ListStatus: models.WatchlistStatusWatching, StartedOn: &explicitStart, MaxEpisodes: &maxEpisodes, EpisodesWatched: 7},
+			models.WatchlistEntryPatchInput{ListStatus: models.OptionalString{Set: true, Value: &completed}},
+			now,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if next.FinishedOn == nil || *next.FinishedOn != "2026-04-02" {
+			t.Fatalf("expected finished_on 2026-04-02, got %v", next.FinishedOn)
+		}
+		if next.EpisodesWatched != 12 {
+			t.Fatalf("expected 12 episodes watched, got %d", next.EpisodesWatched)
+		}
+	})
+
+	t.Run("unchanged status does not touch dates", func(t *testing.T) {
+		note := "rewatch later"
+		next, err := applyWatchlistPatch(
+			models.WatchlistItem{ListStatus: models.WatchlistStatusWatching},
+			models.WatchlistEntryPatchInput{Note: models.OptionalString{Set: true, Value: &note}},
+			now,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if next.StartedOn != nil {
+			t.Fatalf("expected no started_on, got %v", *next.StartedOn)
+		}
+	})
+}
+
+func TestApplyWatchlistPatchRejectsInconsistentFields(t *testing.T) {
+	now := time.Date(2026, 4, 2, 18, 0, 0, 0, time.UTC)
+	maxEpisodes := int16(12)
+	tooMany := int32(13)
+	earlier := "2026-01-01"
+
+	_, err := applyWatchlistPatch(
+		models.WatchlistItem{ListStatus: models.WatchlistStatusWatching, MaxEpisodes: &maxEpisodes},
+		models.WatchlistEntryPatchInput{EpisodesWatched: models.OptionalInt32{Set: true, Value: &tooMany}},
+		now,
+	)
+	var patchErr *WatchlistPatchError
+	if !errors.As(err, &patchErr) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected WatchlistPatchError wrapping ErrValidation, got %v", err)
+	}
+
+	started := "2026-02-01"
+	_, err = applyWatchlistPatch(
+		models.WatchlistItem{ListStatus: models.WatchlistStatusWatching, StartedOn: &started},
+		models.WatchlistEntryPatchInput{FinishedOn: models.OptionalString{Set: true, Value: &earlier}},
+		now,
+	)
+	if !errors.As(err, &patchErr) || patchErr.Message != "finished_on darf nicht vor started_on liegen" {
+		t.Fatalf("expected date order error, got %v", err)
+	}
+}
+
+func TestBuildWatchlistWhereClause(t *testing.T) {
+	scoreMin := int16(7)
+	hasNote := true
+	where, args := buildWatchlistWhereClause(models.WatchlistFilter{
+		UserID:   9,
+		Statuses: []string{"watching", "on_hold"},
+		ScoreMin: &scoreMin,
+		HasNote:  &hasNote,
+	})
+
+	for _, fragment := range []string{
+		"w.user_id = $1",
+		"w.list_status = ANY($2)",
+		"w.score >= $3",
+		"COALESCE(btrim(w.note), '') <> ''",
+	} {
+		if !strings.Contains(where, fragment) {
+			t.Fatalf("expected where clause to contain %q, got %q", fragment, where)
+		}
+	}
+	if len(args) != 3 {
+		t.Fatalf("expected 3 args, got %d", len(args))
+	}
+}
+
+func TestBuildWatchlistOrderBy(t *testing.T) {
+	if got := buildWatchlistOrderBy("score", "asc"); !strings.HasPrefix(got, "w.score ASC NULLS LAST") {
+		t.Fatalf("unexpected score order: %q", got)
+	}
+	if got := buildWatchlistOrderBy("unknown; DROP TABLE anime", "desc"); !strings.HasPrefix(got, "w.created_at DESC NULLS LAST") {
+		t.Fatalf("expected fallback to added_at, got %q", got)
+	}
+}
@@ -0,0 +1,478 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxWebhookErrorLength begrenzt die gespeicherte Fehlermeldung des letzten Versuchs.
+const maxWebhookErrorLength = 1000
+
+// WebhookRepository verwaltet die Webhooks der Fansub-Gruppen und deren Zustell-Log.
+// Zustellungen werden wie der Mail-Outbox mit FOR UPDATE SKIP LOCKED beansprucht.
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// ListForGroup liefert alle Webhooks einer Gruppe (ohne Secret). Unbekannte Gruppen liefern
+// ErrNotFound.
+func (r *WebhookRepository) ListForGroup(ctx context.Context, fansubGroupID int64) ([]models.FansubGroupWebhook, error) {
+	if err := r.ensureFansubGroup(ctx, fansubGroupID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM fansub_group_webhooks w
+		WHERE w.fansub_group_id = $1
+		ORDER BY w.created_at ASC, w.id ASC
+	`, fansubGroupID)
+	if err != nil {
+		return nil, fmt.Errorf("query webhooks for fansub group %d: %w", fansubGroupID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.FansubGroupWebhook, 0)
+	for rows.Next() {
+		item, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhooks for fansub group %d: %w", fansubGroupID, err)
+	}
+	return items, nil
+}
+
+// Create legt einen Webhook mit frisch erzeugtem Secret an. Das Secret ist nur im Rückgabewert
+// enthalten.
+func (r *WebhookRepository) Create(
+	ctx context.Context,
+	fansubGroupID int64,
+	input models.FansubGroupWebhookInput,
+	createdByAppUserID int64,
+) (*models.FansubGroupWebhook, error) {
+	if err := r.ensureFansubGroup(ctx, fansubGroupID); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("generate webhook secret: %w", err)
+	}
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	item, err := scanWebhook(r.db.QueryRow(ctx, `
+		INSERT INTO fansub_group_webhooks (fansub_group_id, url, format, events, secret, is_active, created_by_app_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0))
+		RETURNING `+webhookColumns,
+		fansubGroupID, input.URL, input.Format, input.Events, secret, isActive, createdByAppUserID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create webhook for fansub group %d: %w", fansubGroupID, err)
+	}
+	item.Secret = secret
+	return &item, nil
+}
+
+// Update ändert einen Webhook der Gruppe. Mit RotateSecret wird ein neues Secret erzeugt und
+// einmalig zurückgegeben.
+func (r *WebhookRepository) Update(
+	ctx context.Context,
+	fansubGroupID int64,
+	webhookID int64,
+	input models.FansubGroupWebhookInput,
+) (*models.FansubGroupWebhook, error) {
+	var secret *string
+	if input.RotateSecret {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return nil, fmt.Errorf("generate webhook secret: %w", err)
+		}
+		secret = &generated
+	}
+	var events []string
+	if len(input.Events) > 0 {
+		events = input.Events
+	}
+
+	item, err := scanWebhook(r.db.QueryRow(ctx, `
+		UPDATE fansub_group_webhooks w
+		SET url = COALESCE(NULLIF($3, ''), w.url),
+		    format = COALESCE(NULLIF($4, ''), w.format),
+		    events = COALESCE($5, w.events),
+		    is_active = COALESCE($6, w.is_active),
+		    secret = COALESCE($7, w.secret),
+		    updated_at = NOW()
+		WHERE w.id = $2 AND w.fansub_group_id = $1
+		RETURNING `+webhookColumns,
+		fansubGroupID, webhookID, input.URL, input.Format, events, input.IsActive, secret,
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("update webhook %d: %w", webhookID, err)
+	}
+	if secret != nil {
+		item.Secret = *secret
+	}
+	return &item, nil
+}
+
+// Delete entfernt einen Webhook samt Zustell-Log.
+func (r *WebhookRepository) Delete(ctx context.Context, fansubGroupID int64, webhookID int64) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM fansub_group_webhooks
+		WHERE id = $2 AND fansub_group_id = $1
+	`, fansubGroupID, webhookID)
+	if err != nil {
+		return fmt.Errorf("delete webhook %d: %w", webhookID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListDeliveries liefert das Zustell-Log eines Webhooks der Gruppe, neueste zuerst.
+func (r *WebhookRepository) ListDeliveries(
+	ctx context.Context,
+	fansubGroupID int64,
+	webhookID int64,
+	filter models.WebhookDeliveryFilter,
+) ([]models.WebhookDelivery, int64, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM fansub_group_webhooks WHERE id = $2 AND fansub_group_id = $1)
+	`, fansubGroupID, webhookID).Scan(&exists); err != nil {
+		return nil, 0, fmt.Errorf("check webhook %d: %w", webhookID, err)
+	}
+	if !exists {
+		return nil, 0, ErrNotFound
+	}
+
+	const scope = `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		  AND ($2 = '' OR d.status = $2)`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+scope, webhookID, filter.Status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count deliveries of webhook %d: %w", webhookID, err)
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	rows, err := r.db.Query(ctx, `
+		SELECT d.id, d.webhook_id, d.event_type, d.status, d.attempts, d.max_attempts, d.next_attempt_at,
+		       d.response_status, d.last_error, d.delivered_at, d.created_at, d.updated_at
+		`+scope+`
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3 OFFSET $4
+	`, webhookID, filter.Status, filter.PerPage, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query deliveries of webhook %d: %w", webhookID, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		var item models.WebhookDelivery
+		if err := rows.Scan(
+			&item.ID,
+			&item.WebhookID,
+			&item.EventType,
+			&item.Status,
+			&item.Attempts,
+			&item.MaxAttempts,
+			&item.NextAttemptAt,
+			&item.ResponseStatus,
+			&item.LastError,
+			&item.DeliveredAt,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate deliveries of webhook %d: %w", webhookID, err)
+	}
+	return items, total, nil
+}
+
+// EnqueueEvent legt für jeden aktiven Webhook der beteiligten Gruppen, der den Ereignistyp
+// abonniert hat, eine Zustellung an und liefert deren Anzahl.
+func (r *WebhookRepository) EnqueueEvent(ctx context.Context, event models.WebhookEvent) (int, error) {
+	if len(event.FansubGroupIDs) == 0 {
+		return 0, nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("encode webhook event %s: %w", event.Type, err)
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT w.id, $2, $3::jsonb
+		FROM fansub_group_webhooks w
+		WHERE w.fansub_group_id = ANY($1)
+		  AND w.is_active
+		  AND $2 = ANY(w.events)
+	`, event.FansubGroupIDs, event.Type, payload)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue webhook event %s: %w", event.Type, err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// LoadReleaseContext lädt Anime, Episode und Gruppen einer Release-Version.
+func (r *WebhookRepository) LoadReleaseContext(ctx context.Context, releaseVersionID int64) (*models.WebhookReleaseContext, error) {
+	return r.loadReleaseContext(ctx, releaseVersionID, 0)
+}
+
+// LoadReleaseContextByVariant lädt den Release-Kontext über eine Release-Variante; die
+// Qualität ist dann die der Variante.
+func (r *WebhookRepository) LoadReleaseContextByVariant(ctx context.Context, variantID int64) (*models.WebhookReleaseContext, error) {
+	var releaseVersionID int64
+	err := r.db.QueryRow(ctx, `SELECT release_version_id FROM release_variants WHERE id = $1`, variantID).Scan(&releaseVersionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve release version of variant %d: %w", variantID, err)
+	}
+	return r.loadReleaseContext(ctx, releaseVersionID, variantID)
+}
+
+// loadReleaseContext fasst die Qualitäten aller Varianten zusammen, bei variantID > 0 nur
+// die der angegebenen Variante.
+func (r *WebhookRepository) loadReleaseContext(ctx context.Context, releaseVersionID int64, variantID int64) (*models.WebhookReleaseContext, error) {
+	var item models.WebhookReleaseContext
+	err := r.db.QueryRow(ctx, `
+		SELECT rev.id, rev.version, a.id, a.title, e.id, COALESCE(e.episode_number, ''),
+		       COALESCE((
+		           SELECT string_agg(DISTINCT COALESCE(v.video_quality, v.resolution), ', ')
+		           FROM release_variants v
+		           WHERE v.release_version_id = rev.id
+		             AND ($2::bigint = 0 OR v.id = $2)
+		       ), ''),
+		       COALESCE(array_agg(fg.id ORDER BY fg.name) FILTER (WHERE fg.id IS NOT NULL), '{}'),
+		       COALESCE(array_agg(fg.name ORDER BY fg.name) FILTER (WHERE fg.id IS NOT NULL), '{}')
+		FROM release_versions rev
+		JOIN fansub_releases fr ON fr.id = rev.release_id
+		JOIN episodes e ON e.id = fr.episode_id
+		JOIN anime a ON a.id = e.anime_id
+		LEFT JOIN release_version_groups rvg ON rvg.release_version_id = rev.id
+		LEFT JOIN fansub_groups fg ON fg.id = rvg.fansub_group_id
+		WHERE rev.id = $1
+		GROUP BY rev.id, a.id, e.id
+	`, releaseVersionID, variantID).Scan(
+		&item.ReleaseVersionID,
+		&item.Version,
+		&item.AnimeID,
+		&item.AnimeTitle,
+		&item.EpisodeID,
+		&item.EpisodeNumber,
+		&item.VideoQuality,
+		&item.FansubGroupIDs,
+		&item.FansubGroupNames,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load webhook release context %d: %w", releaseVersionID, err)
+	}
+	return &item, nil
+}
+
+// LoadProjectNoteContext lädt eine Projektnotiz samt Anime- und Gruppennamen.
+func (r *WebhookRepository) LoadProjectNoteContext(ctx context.Context, noteID int64) (*models.WebhookProjectNoteContext, error) {
+	var item models.WebhookProjectNoteContext
+	err := r.db.QueryRow(ctx, `
+		SELECT n.id, a.id, a.title, fg.id, fg.name, n.title, COALESCE(n.body_text, '')
+		FROM anime_fansub_project_notes n
+		JOIN anime a ON a.id = n.anime_id
+		JOIN fansub_groups fg ON fg.id = n.fansub_group_id
+		WHERE n.id = $1 AND n.deleted_at IS NULL
+	`, noteID).Scan(
+		&item.NoteID,
+		&item.AnimeID,
+		&item.AnimeTitle,
+		&item.FansubGroupID,
+		&item.FansubGroupName,
+		&item.Title,
+		&item.BodyText,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load webhook project note context %d: %w", noteID, err)
+	}
+	return &item, nil
+}
+
+// ClaimDue beansprucht bis zu limit fällige Zustellungen aktiver Webhooks, setzt sie auf
+// sending und erhöht attempts. Abgelaufene Leases gelten wieder als fällig.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDeliveryMessage, error) {
+	if limit <= 0 {
+		return []models.WebhookDeliveryMessage{}, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		UPDATE webhook_deliveries d
+		SET status = 'sending',
+		    attempts = d.attempts + 1,
+		    locked_until = NOW() + $2 * INTERVAL '1 second',
+		    updated_at = NOW()
+		FROM (
+			SELECT dd.id
+			FROM webhook_deliveries dd
+			JOIN fansub_group_webhooks ww ON ww.id = dd.webhook_id AND ww.is_active
+			WHERE (dd.status = 'pending' AND dd.next_attempt_at <= NOW())
+			   OR (dd.status = 'sending' AND dd.locked_until < NOW())
+			ORDER BY dd.next_attempt_at, dd.id
+			LIMIT $1
+			FOR UPDATE OF dd SKIP LOCKED
+		) due, fansub_group_webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING d.id, d.webhook_id, w.url, w.format, w.secret, d.payload, d.attempts, d.max_attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.WebhookDeliveryMessage, 0, limit)
+	for rows.Next() {
+		var message models.WebhookDeliveryMessage
+		var payload []byte
+		if err := rows.Scan(
+			&message.ID,
+			&message.WebhookID,
+			&message.URL,
+			&message.Format,
+			&message.Secret,
+			&payload,
+			&message.Attempts,
+			&message.MaxAttempts,
+		); err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		if err := json.Unmarshal(payload, &message.Event); err != nil {
+			return nil, fmt.Errorf("decode webhook delivery payload %d: %w", message.ID, err)
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate claimed webhook deliveries: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkDelivered schließt eine Zustellung mit dem HTTP-Status der Antwort ab.
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, responseStatus int) error {
+	if _, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered',
+		    response_status = $2,
+		    delivered_at = NOW(),
+		    locked_until = NULL,
+		    last_error = NULL,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, responseStatus); err != nil {
+		return fmt.Errorf("mark webhook delivery %d delivered: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed speichert Fehler und HTTP-Status (falls vorhanden) des letzten Versuchs.
+// nextAttemptAt = nil verschiebt die Zustellung in den Status dead.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id int64, responseStatus *int, sendErr string, nextAttemptAt *time.Time) error {
+	status := models.WebhookDeliveryStatusPending
+	if nextAttemptAt == nil {
+		status = models.WebhookDeliveryStatusDead
+	}
+	if len(sendErr) > maxWebhookErrorLength {
+		sendErr = sendErr[:maxWebhookErrorLength]
+	}
+
+	if _, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    response_status = $3,
+		    last_error = $4,
+		    next_attempt_at = COALESCE($5, next_attempt_at),
+		    locked_until = NULL,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, status, responseStatus, sendErr, nextAttemptAt); err != nil {
+		return fmt.Errorf("mark webhook delivery %d failed: %w", id, err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ensureFansubGroup(ctx context.Context, fansubGroupID int64) error {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM fansub_groups WHERE id = $1)`, fansubGroupID).Scan(&exists); err != nil {
+		return fmt.Errorf("check fansub group %d: %w", fansubGroupID, err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const webhookColumns = `
+	w.id, w.fansub_group_id, w.url, w.format, w.events, w.is_active, w.created_at, w.updated_at`
+
+func scanWebhook(row pgx.Row) (models.FansubGroupWebhook, error) {
+	var item models.FansubGroupWebhook
+	if err := row.Scan(
+		&item.ID,
+		&item.FansubGroupID,
+		&item.URL,
+		&item.Format,
+		&item.Events,
+		&item.IsActive,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.FansubGroupWebhook{}, ErrNotFound
+		}
+		return models.FansubGroupWebhook{}, fmt.Errorf("scan webhook: %w", err)
+	}
+	return item, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buffer), nil
+}
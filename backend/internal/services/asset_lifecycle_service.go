@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
 )
@@ -25,6 +26,7 @@ type AssetLifecycleStore interface {
 type AssetLifecycleService struct {
 	store      AssetLifecycleStore
 	storageDir string
+	blobs      blobstore.BlobStore
 }
 
 // NewAssetLifecycleService erstellt einen neuen AssetLifecycleService mit dem angegebenen
@@ -42,6 +44,13 @@ func NewAssetLifecycleService(store AssetLifecycleStore, storageDir string) *Ass
 	}
 }
 
+// WithBlobStore setzt die Medienablage. Liegt sie nicht im lokalen Dateisystem, existieren Ordner
+// nur als Schlüsselpräfixe und werden nicht angelegt.
+func (s *AssetLifecycleService) WithBlobStore(blobs blobstore.BlobStore) *AssetLifecycleService {
+	s.blobs = blobs
+	return s
+}
+
 // Phase6AnimeAssetLifecyclePolicy gibt die Asset-Lebenszyklus-Policy für Anime-Entities zurück.
 // Sie definiert erlaubte Asset-Typen und die erforderlichen Unterordner im kanonischen Layout.
 func Phase6AnimeAssetLifecyclePolicy() models.AssetLifecyclePolicy {
@@ -98,7 +107,12 @@ func (s *AssetLifecycleService) EnsureCanonicalLayout(
 		return nil, err
 	}
 
-	statuses, err := s.ensureFolders(rootPath, policy.RequiredFolders)
+	var statuses []models.ProvisioningFolderStatus
+	if s.usesLocalFolders() {
+		statuses, err = s.ensureFolders(rootPath, policy.RequiredFolders)
+	} else {
+		statuses = implicitFolderStatuses(policy.RequiredFolders)
+	}
 	if err != nil {
 		auditErr := s.recordAudit(ctx, actorUserID, subject, assetType, "provision", "failed", map[string]any{
 			"error": err.Error(),
@@ -174,6 +188,28 @@ func isPathWithinBase(base string, target string) (bool, error) {
 	return true, nil
 }
 
+// usesLocalFolders meldet, ob Ordner im Dateisystem angelegt werden müssen.
+func (s *AssetLifecycleService) usesLocalFolders() bool {
+	if s.blobs == nil {
+		return true
+	}
+	_, ok := blobstore.LocalRoot(s.blobs)
+	return ok
+}
+
+// implicitFolderStatuses beschreibt das Layout in einem Bucket: Ordner entstehen mit dem ersten
+// Objekt unter ihrem Präfix.
+func implicitFolderStatuses(folders []string) []models.ProvisioningFolderStatus {
+	statuses := make([]models.ProvisioningFolderStatus, 0, len(folders))
+	for _, folder := range folders {
+		statuses = append(statuses, models.ProvisioningFolderStatus{Folder: folder, State: "implicit"})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Folder < statuses[j].Folder
+	})
+	return statuses
+}
+
 func (s *AssetLifecycleService) ensureFolders(rootPath string, folders []string) ([]models.ProvisioningFolderStatus, error) {
 	rootInfo, err := os.Stat(rootPath)
 	if err == nil && !rootInfo.IsDir() {
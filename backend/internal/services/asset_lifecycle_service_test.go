@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
 )
@@ -160,3 +161,20 @@ func TestAssetLifecycleService_ResolveCanonicalRootRejectsEscapes(t *testing.T)
 		t.Fatal("expected path to escape the base directory")
 	}
 }
+
+func TestAssetLifecycleService_BucketLayoutCreatesNoFolders(t *testing.T) {
+	root := t.TempDir()
+	bucket := blobstore.NewFallbackStore(blobstore.NewLocalStore(t.TempDir()), blobstore.NewLocalStore(root))
+	service := NewAssetLifecycleService(&mockAssetLifecycleStore{}, root).WithBlobStore(bucket)
+
+	result, err := service.EnsureCanonicalLayout(context.Background(), 44, "anime", 123, "cover")
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if len(result.Statuses) != 5 || result.Statuses[0].State != "implicit" {
+		t.Fatalf("expected implicit folder statuses, got %+v", result.Statuses)
+	}
+	if _, err := os.Stat(filepath.Join(root, "anime", "123")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no local folders for bucket storage, got %v", err)
+	}
+}
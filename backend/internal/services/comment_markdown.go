@@ -0,0 +1,177 @@
+package services
+
+import (
+	"strings"
+)
+
+// MaxCommentMentions begrenzt die pro Kommentar aufgelösten @mentions.
+const MaxCommentMentions = 10
+
+// CommentMentionPath ist der Profilpfad, auf den aufgelöste @mentions verlinken.
+const CommentMentionPath = "/members/"
+
+// ExtractCommentMentions liefert die @slug-Erwähnungen eines Kommentars (kleingeschrieben,
+// dedupliziert, in Reihenfolge des Auftretens, höchstens MaxCommentMentions). Erwähnungen in
+// Code-Spans und Code-Blöcken sowie E-Mail-Adressen werden ignoriert.
+func ExtractCommentMentions(content string) []string {
+	slugs := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, segment := range splitCommentCode(content) {
+		if segment.code {
+			continue
+		}
+		forEachCommentMention(segment.text, func(_ int, _ int, slug string) {
+			if len(slugs) >= MaxCommentMentions {
+				return
+			}
+			if _, ok := seen[slug]; ok {
+				return
+			}
+			seen[slug] = struct{}{}
+			slugs = append(slugs, slug)
+		})
+	}
+
+	return slugs
+}
+
+// LinkCommentMentions ersetzt @slug-Erwähnungen, die in resolved enthalten sind, durch
+// Markdown-Links auf das Member-Profil. Nicht aufgelöste Erwähnungen bleiben Text.
+func LinkCommentMentions(content string, resolved []string) string {
+	if len(resolved) == 0 {
+		return content
+	}
+	known := make(map[string]struct{}, len(resolved))
+	for _, slug := range resolved {
+		known[slug] = struct{}{}
+	}
+
+	var out strings.Builder
+	out.Grow(len(content))
+	for _, segment := range splitCommentCode(content) {
+		if segment.code {
+			out.WriteString(segment.text)
+			continue
+		}
+		last := 0
+		forEachCommentMention(segment.text, func(start int, end int, slug string) {
+			if _, ok := known[slug]; !ok {
+				return
+			}
+			out.WriteString(segment.text[last:start])
+			out.WriteString("[")
+			out.WriteString(segment.text[start:end])
+			out.WriteString("](")
+			out.WriteString(CommentMentionPath)
+			out.WriteString(slug)
+			out.WriteString(")")
+			last = end
+		})
+		out.WriteString(segment.text[last:])
+	}
+
+	return out.String()
+}
+
+type commentTextSegment struct {
+	text string
+	code bool
+}
+
+// splitCommentCode trennt Code-Spans und Code-Blöcke (Backtick-Folgen gleicher Länge) vom
+// übrigen Text. Eine Backtick-Folge ohne Gegenstück bleibt normaler Text.
+func splitCommentCode(content string) []commentTextSegment {
+	segments := make([]commentTextSegment, 0, 1)
+	textStart := 0
+	i := 0
+	for i < len(content) {
+		if content[i] != '`' {
+			i++
+			continue
+		}
+		runLength := backtickRunLength(content, i)
+		closeAt := findBacktickRun(content, i+runLength, runLength)
+		if closeAt < 0 {
+			i += runLength
+			continue
+		}
+		if i > textStart {
+			segments = append(segments, commentTextSegment{text: content[textStart:i]})
+		}
+		end := closeAt + runLength
+		segments = append(segments, commentTextSegment{text: content[i:end], code: true})
+		i = end
+		textStart = end
+	}
+	if textStart < len(content) {
+		segments = append(segments, commentTextSegment{text: content[textStart:]})
+	}
+
+	return segments
+}
+
+func backtickRunLength(content string, start int) int {
+	end := start
+	for end < len(content) && content[end] == '`' {
+		end++
+	}
+	return end - start
+}
+
+// findBacktickRun sucht ab from eine Backtick-Folge mit genau length Zeichen.
+func findBacktickRun(content string, from int, length int) int {
+	for i := from; i < len(content); {
+		if content[i] != '`' {
+			i++
+			continue
+		}
+		runLength := backtickRunLength(content, i)
+		if runLength == length {
+			return i
+		}
+		i += runLength
+	}
+	return -1
+}
+
+// forEachCommentMention ruft fn für jede Erwähnung mit Byte-Bereich [start, end) des
+// Original-Texts (inklusive @) und dem kleingeschriebenen Slug auf. Ein @ direkt nach einem
+// Buchstaben, einer Ziffer oder einem Pfad-/Link-Zeichen zählt nicht als Erwähnung.
+func forEachCommentMention(text string, fn func(start int, end int, slug string)) {
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' {
+			continue
+		}
+		if i > 0 && !isCommentMentionBoundary(text[i-1]) {
+			continue
+		}
+		end := i + 1
+		for end < len(text) && isCommentSlugByte(text[end]) {
+			end++
+		}
+		// Bindestriche am Ende gehören nicht zum Slug (Slugs werden an den Rändern getrimmt).
+		for end > i+1 && text[end-1] == '-' {
+			end--
+		}
+		if end == i+1 || text[i+1] == '-' {
+			continue
+		}
+		fn(i, end, strings.ToLower(text[i+1:end]))
+		i = end - 1
+	}
+}
+
+func isCommentSlugByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '-'
+}
+
+func isCommentMentionBoundary(b byte) bool {
+	if isCommentSlugByte(b) || b >= 0x80 {
+		return false
+	}
+	switch b {
+	case '_', '.', '/', '@', '[', '\\':
+		return false
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractCommentMentions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "single mention", content: "Danke @Nico!", want: []string{"nico"}},
+		{name: "slug with dashes and dedupe", content: "@mira-chan und @nico, nochmal @MIRA-CHAN", want: []string{"mira-chan", "nico"}},
+		{name: "trailing dash trimmed", content: "@nico- genau", want: []string{"nico"}},
+		{name: "email ignored", content: "schreib an team@example.org", want: []string{}},
+		{name: "code span ignored", content: "`@nico` aber @mira", want: []string{"mira"}},
+		{name: "fenced code ignored", content: "```\n@nico\n```\n@mira", want: []string{"mira"}},
+		{name: "unclosed backtick is text", content: "` @nico", want: []string{"nico"}},
+		{name: "existing link ignored", content: "[@nico](https://example.org)", want: []string{}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := ExtractCommentMentions(tc.content)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestExtractCommentMentionsLimit(t *testing.T) {
+	var content strings.Builder
+	for i := 0; i < MaxCommentMentions+5; i++ {
+		content.WriteString(" @user-")
+		content.WriteByte(byte('a' + i))
+	}
+
+	if got := ExtractCommentMentions(content.String()); len(got) != MaxCommentMentions {
+		t.Fatalf("expected %d mentions, got %d", MaxCommentMentions, len(got))
+	}
+}
+
+func TestLinkCommentMentions(t *testing.T) {
+	got := LinkCommentMentions("Hi @Nico und @unbekannt, `@nico` bleibt", []string{"nico"})
+	want := "Hi [@Nico](/members/nico) und @unbekannt, `@nico` bleibt"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMarkdownService_RenderCommentMarkdown_RestrictsSubset(t *testing.T) {
+	svc := NewMarkdownService()
+
+	html, err := svc.RenderCommentMarkdown("# Titel\n\n**fett** ![bild](https://example.org/a.png) [@nico](/members/nico) <script>alert(1)</script>")
+	if err != nil {
+		t.Fatalf("RenderCommentMarkdown returned error: %v", err)
+	}
+	if strings.Contains(html, "<h1") || strings.Contains(html, "<img") || strings.Contains(strings.ToLower(html), "<script") {
+		t.Fatalf("expected headings, images and scripts to be removed, got %q", html)
+	}
+	if !strings.Contains(html, "<strong>fett</strong>") {
+		t.Fatalf("expected strong tag in rendered html, got %q", html)
+	}
+	if !strings.Contains(html, `<a href="/members/nico" rel="nofollow">@nico</a>`) {
+		t.Fatalf("expected mention link in rendered html, got %q", html)
+	}
+}
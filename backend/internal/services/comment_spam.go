@@ -0,0 +1,112 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"team4s.v3/backend/internal/models"
+)
+
+var commentLinkPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s]+`)
+
+// CommentSpamConfig enthält die konfigurierbaren Schwellen der Kommentar-Heuristiken.
+// Ein Wert <= 0 deaktiviert die jeweilige Prüfung.
+type CommentSpamConfig struct {
+	MaxLinks        int
+	RepeatWindow    time.Duration
+	RepeatThreshold int
+	NewAccountAge   time.Duration
+	BlockedWords    []string
+}
+
+// CommentSpamHeuristics entscheidet, ob ein neuer Kommentar zur Prüfung zurückgehalten wird.
+type CommentSpamHeuristics struct {
+	cfg          CommentSpamConfig
+	blockedWords []string
+}
+
+// NewCommentSpamHeuristics normalisiert die Wortliste (klein, nur Buchstaben/Ziffern, dedupliziert).
+func NewCommentSpamHeuristics(cfg CommentSpamConfig) *CommentSpamHeuristics {
+	seen := make(map[string]struct{}, len(cfg.BlockedWords))
+	words := make([]string, 0, len(cfg.BlockedWords))
+	for _, raw := range cfg.BlockedWords {
+		word := normalizeCommentWords(raw)
+		if word == "" {
+			continue
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+		words = append(words, word)
+	}
+
+	return &CommentSpamHeuristics{cfg: cfg, blockedWords: words}
+}
+
+// RepeatWindow liefert das Zeitfenster für die Wiederholungsprüfung (0 = deaktiviert).
+func (h *CommentSpamHeuristics) RepeatWindow() time.Duration {
+	if h == nil || h.cfg.RepeatThreshold <= 0 || h.cfg.RepeatWindow <= 0 {
+		return 0
+	}
+	return h.cfg.RepeatWindow
+}
+
+// Evaluate gibt die ausgelösten Flags zurück. Eine leere Liste bedeutet: sofort sichtbar.
+// Die Wiederholungs- und Kontoalter-Prüfung greifen nur, wenn signals gesetzt sind.
+func (h *CommentSpamHeuristics) Evaluate(content string, signals *models.CommentAuthorSignals, now time.Time) []string {
+	if h == nil {
+		return nil
+	}
+
+	flags := make([]string, 0, 4)
+	if h.cfg.MaxLinks > 0 && CountCommentLinks(content) > h.cfg.MaxLinks {
+		flags = append(flags, models.CommentFlagTooManyLinks)
+	}
+	if signals != nil {
+		if h.RepeatWindow() > 0 && signals.RecentDuplicates >= h.cfg.RepeatThreshold {
+			flags = append(flags, models.CommentFlagRepeatedContent)
+		}
+		if h.cfg.NewAccountAge > 0 && signals.AccountCreatedAt != nil &&
+			now.Sub(*signals.AccountCreatedAt) < h.cfg.NewAccountAge {
+			flags = append(flags, models.CommentFlagNewAccount)
+		}
+	}
+	if h.containsBlockedWord(content) {
+		flags = append(flags, models.CommentFlagBlockedWord)
+	}
+
+	return flags
+}
+
+// CountCommentLinks zählt http(s)- und www-Links im Kommentartext.
+func CountCommentLinks(content string) int {
+	return len(commentLinkPattern.FindAllStringIndex(content, -1))
+}
+
+// containsBlockedWord sucht gesperrte Wörter an Wortgrenzen; Einträge aus mehreren Wörtern
+// werden als Phrase gesucht.
+func (h *CommentSpamHeuristics) containsBlockedWord(content string) bool {
+	if len(h.blockedWords) == 0 {
+		return false
+	}
+
+	normalized := " " + normalizeCommentWords(content) + " "
+	for _, word := range h.blockedWords {
+		if strings.Contains(normalized, " "+word+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeCommentWords reduziert Text auf kleingeschriebene, mit einem Leerzeichen
+// getrennte Wörter aus Buchstaben und Ziffern.
+func normalizeCommentWords(raw string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
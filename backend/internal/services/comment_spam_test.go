@@ -0,0 +1,72 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestCommentSpamHeuristicsEvaluate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-2 * time.Hour)
+	old := now.Add(-30 * 24 * time.Hour)
+
+	heuristics := NewCommentSpamHeuristics(CommentSpamConfig{
+		MaxLinks:        2,
+		RepeatWindow:    time.Hour,
+		RepeatThreshold: 2,
+		NewAccountAge:   24 * time.Hour,
+		BlockedWords:    []string{"  Casino ", "casino", "free money", ""},
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		signals *models.CommentAuthorSignals
+		want    []string
+	}{
+		{name: "clean", content: "Tolle Folge!", signals: &models.CommentAuthorSignals{AccountCreatedAt: &old}},
+		{name: "two links allowed", content: "https://a.example und www.b.example", signals: nil},
+		{
+			name:    "too many links",
+			content: "http://a.example http://b.example www.c.example",
+			want:    []string{models.CommentFlagTooManyLinks},
+		},
+		{
+			name:    "repeated and new account",
+			content: "Hallo",
+			signals: &models.CommentAuthorSignals{RecentDuplicates: 2, AccountCreatedAt: &fresh},
+			want:    []string{models.CommentFlagRepeatedContent, models.CommentFlagNewAccount},
+		},
+		{name: "below repeat threshold", content: "Hallo", signals: &models.CommentAuthorSignals{RecentDuplicates: 1}},
+		{name: "blocked word", content: "Bestes CASINO!", want: []string{models.CommentFlagBlockedWord}},
+		{name: "blocked phrase", content: "hier gibt es Free-Money", want: []string{models.CommentFlagBlockedWord}},
+		{name: "word boundary", content: "casinos sind kein treffer"},
+	}
+
+	for _, tc := range tests {
+		got := heuristics.Evaluate(tc.content, tc.signals, now)
+		if !slices.Equal(got, tc.want) && !(len(got) == 0 && len(tc.want) == 0) {
+			t.Fatalf("%s: expected flags %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestCommentSpamHeuristicsDisabled(t *testing.T) {
+	heuristics := NewCommentSpamHeuristics(CommentSpamConfig{})
+	if window := heuristics.RepeatWindow(); window != 0 {
+		t.Fatalf("expected repeat check to be disabled, got %v", window)
+	}
+
+	flags := heuristics.Evaluate("http://a http://b http://c", &models.CommentAuthorSignals{RecentDuplicates: 10}, time.Now())
+	if len(flags) != 0 {
+		t.Fatalf("expected no flags with zero config, got %v", flags)
+	}
+
+	var nilHeuristics *CommentSpamHeuristics
+	if flags := nilHeuristics.Evaluate("casino", nil, time.Now()); flags != nil {
+		t.Fatalf("expected nil heuristics to pass, got %v", flags)
+	}
+}
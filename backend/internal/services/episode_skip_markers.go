@@ -0,0 +1,163 @@
+package services
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// skipChapterEpisodeLabel benennt die Kapitel zwischen den Skip-Markern.
+const skipChapterEpisodeLabel = "Episode"
+
+// jellyfinTicksPerSecond: Jellyfin misst Positionen in 100-ns-Ticks.
+const jellyfinTicksPerSecond = 10_000_000
+
+// ThemeSegmentKind leitet die Marker-Art aus dem Theme-Typ ab ("OP Kara" -> intro, "ED Kara" und
+// "Outro" -> outro, "Recap" -> recap). Insert-Songs gehören zur Episode und liefern "".
+func ThemeSegmentKind(themeTypeName string) string {
+	name := strings.ToLower(strings.TrimSpace(themeTypeName))
+	switch {
+	case strings.HasPrefix(name, "op"):
+		return models.SkipMarkerKindIntro
+	case strings.HasPrefix(name, "ed"), strings.HasPrefix(name, "outro"):
+		return models.SkipMarkerKindOutro
+	case strings.HasPrefix(name, "recap"):
+		return models.SkipMarkerKindRecap
+	default:
+		return ""
+	}
+}
+
+func skipMarkerLabel(kind string, themeTitle *string) string {
+	label := map[string]string{
+		models.SkipMarkerKindIntro: "Opening",
+		models.SkipMarkerKindOutro: "Ending",
+		models.SkipMarkerKindRecap: "Recap",
+	}[kind]
+	if themeTitle != nil && strings.TrimSpace(*themeTitle) != "" {
+		label += ": " + strings.TrimSpace(*themeTitle)
+	}
+	return label
+}
+
+// ResolveEpisodeSkipMarkers wandelt die zutreffenden Segmente in zeitlich sortierte Skip-Marker.
+// Segmente ohne Startzeit oder Marker-Art entfallen; ein fehlendes Ende wird mit der Laufzeit
+// aufgefüllt und Marker werden auf die Laufzeit gekürzt. Überlappt ein Marker seinen Vorgänger
+// (etwa bei Gemeinschaftsreleases mit doppelt gepflegten Segmenten), gewinnt der frühere.
+func ResolveEpisodeSkipMarkers(source models.EpisodeSkipMarkerSource) []models.EpisodeSkipMarker {
+	candidates := make([]models.EpisodeSkipMarker, 0, len(source.Segments))
+	for _, segment := range source.Segments {
+		kind := ThemeSegmentKind(segment.ThemeTypeName)
+		if kind == "" || segment.StartSeconds == nil {
+			continue
+		}
+		start := *segment.StartSeconds
+		var end int32
+		switch {
+		case segment.EndSeconds != nil:
+			end = *segment.EndSeconds
+		case source.DurationSeconds != nil:
+			end = *source.DurationSeconds
+		default:
+			continue
+		}
+		if source.DurationSeconds != nil && *source.DurationSeconds > 0 && end > *source.DurationSeconds {
+			end = *source.DurationSeconds
+		}
+		if start < 0 || end <= start {
+			continue
+		}
+		candidates = append(candidates, models.EpisodeSkipMarker{
+			SegmentID:    segment.SegmentID,
+			Kind:         kind,
+			Label:        skipMarkerLabel(kind, segment.ThemeTitle),
+			ThemeType:    segment.ThemeTypeName,
+			ThemeTitle:   segment.ThemeTitle,
+			StartSeconds: start,
+			EndSeconds:   end,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].StartSeconds != candidates[j].StartSeconds {
+			return candidates[i].StartSeconds < candidates[j].StartSeconds
+		}
+		return candidates[i].SegmentID < candidates[j].SegmentID
+	})
+
+	markers := make([]models.EpisodeSkipMarker, 0, len(candidates))
+	for _, marker := range candidates {
+		if len(markers) > 0 && marker.StartSeconds < markers[len(markers)-1].EndSeconds {
+			continue
+		}
+		markers = append(markers, marker)
+	}
+	return markers
+}
+
+// BuildSkipChapterVTT erzeugt eine WebVTT-Kapitelspur. Die Lücken zwischen den Markern werden
+// als "Episode" ausgewiesen, bei bekannter Laufzeit bis zum Ende der Datei.
+func BuildSkipChapterVTT(markers []models.EpisodeSkipMarker, durationSeconds *int32) string {
+	var builder strings.Builder
+	builder.WriteString("WEBVTT\n")
+
+	cue := 0
+	writeChapter := func(start int32, end int32, label string) {
+		cue++
+		fmt.Fprintf(
+			&builder,
+			"\n%d\n%s --> %s\n%s\n",
+			cue,
+			formatVTTTimestamp(time.Duration(start)*time.Second),
+			formatVTTTimestamp(time.Duration(end)*time.Second),
+			label,
+		)
+	}
+
+	var cursor int32
+	for _, marker := range markers {
+		if marker.StartSeconds > cursor {
+			writeChapter(cursor, marker.StartSeconds, skipChapterEpisodeLabel)
+		}
+		writeChapter(marker.StartSeconds, marker.EndSeconds, marker.Label)
+		cursor = marker.EndSeconds
+	}
+	if durationSeconds != nil && *durationSeconds > cursor {
+		writeChapter(cursor, *durationSeconds, skipChapterEpisodeLabel)
+	}
+	return builder.String()
+}
+
+// BuildJellyfinMediaSegments bildet die Marker auf das MediaSegments-Format von Jellyfin 10.10 ab.
+// Die Segment-IDs sind stabile, aus Variante und Segment abgeleitete UUIDs.
+func BuildJellyfinMediaSegments(markers []models.EpisodeSkipMarker, releaseVariantID int64, itemID *string) models.JellyfinMediaSegmentsResult {
+	types := map[string]string{
+		models.SkipMarkerKindIntro: "Intro",
+		models.SkipMarkerKindOutro: "Outro",
+		models.SkipMarkerKindRecap: "Recap",
+	}
+
+	items := make([]models.JellyfinMediaSegment, 0, len(markers))
+	for _, marker := range markers {
+		items = append(items, models.JellyfinMediaSegment{
+			ID:         skipMarkerSegmentUUID(releaseVariantID, marker.SegmentID),
+			ItemID:     itemID,
+			Type:       types[marker.Kind],
+			StartTicks: int64(marker.StartSeconds) * jellyfinTicksPerSecond,
+			EndTicks:   int64(marker.EndSeconds) * jellyfinTicksPerSecond,
+		})
+	}
+	return models.JellyfinMediaSegmentsResult{Items: items, TotalRecordCount: len(items), StartIndex: 0}
+}
+
+// skipMarkerSegmentUUID bildet eine namensbasierte UUID (Version 5) für Variante und Segment.
+func skipMarkerSegmentUUID(releaseVariantID int64, segmentID int64) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("team4s:release-variant:%d:theme-segment:%d", releaseVariantID, segmentID)))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func skipMarkerTestInt32(value int32) *int32 {
+	return &value
+}
+
+func skipMarkerTestSource() models.EpisodeSkipMarkerSource {
+	title := "Blue Bird"
+	return models.EpisodeSkipMarkerSource{
+		ReleaseVariantID: 11,
+		ReleaseVersionID: 5,
+		DurationSeconds:  skipMarkerTestInt32(1440),
+		Segments: []models.EpisodeSkipMarkerSegment{
+			{SegmentID: 3, ThemeTypeName: "ED Kara", StartSeconds: skipMarkerTestInt32(1300)},
+			{SegmentID: 1, ThemeTypeName: "OP Kara", ThemeTitle: &title, StartSeconds: skipMarkerTestInt32(90), EndSeconds: skipMarkerTestInt32(180)},
+			{SegmentID: 2, ThemeTypeName: "Insert Kara", StartSeconds: skipMarkerTestInt32(600), EndSeconds: skipMarkerTestInt32(690)},
+			{SegmentID: 4, ThemeTypeName: "Recap", StartSeconds: skipMarkerTestInt32(0), EndSeconds: skipMarkerTestInt32(60)},
+			{SegmentID: 5, ThemeTypeName: "OP Kara", StartSeconds: skipMarkerTestInt32(100), EndSeconds: skipMarkerTestInt32(190)},
+			{SegmentID: 6, ThemeTypeName: "Outro", EndSeconds: skipMarkerTestInt32(1400)},
+		},
+	}
+}
+
+func TestResolveEpisodeSkipMarkers(t *testing.T) {
+	markers := ResolveEpisodeSkipMarkers(skipMarkerTestSource())
+
+	if len(markers) != 3 {
+		t.Fatalf("expected 3 markers, got %+v", markers)
+	}
+	want := []struct {
+		segmentID int64
+		kind      string
+		label     string
+		start     int32
+		end       int32
+	}{
+		{4, models.SkipMarkerKindRecap, "Recap", 0, 60},
+		{1, models.SkipMarkerKindIntro, "Opening: Blue Bird", 90, 180},
+		{3, models.SkipMarkerKindOutro, "Ending", 1300, 1440},
+	}
+	for i, expected := range want {
+		got := markers[i]
+		if got.SegmentID != expected.segmentID || got.Kind != expected.kind || got.Label != expected.label ||
+			got.StartSeconds != expected.start || got.EndSeconds != expected.end {
+			t.Fatalf("marker %d: expected %+v, got %+v", i, expected, got)
+		}
+	}
+}
+
+func TestResolveEpisodeSkipMarkersWithoutDurationDropsOpenEnds(t *testing.T) {
+	source := skipMarkerTestSource()
+	source.DurationSeconds = nil
+
+	markers := ResolveEpisodeSkipMarkers(source)
+	if len(markers) != 2 || markers[1].SegmentID != 1 {
+		t.Fatalf("expected recap and opening only, got %+v", markers)
+	}
+}
+
+func TestBuildSkipChapterVTT(t *testing.T) {
+	markers := ResolveEpisodeSkipMarkers(skipMarkerTestSource())
+
+	vtt := BuildSkipChapterVTT(markers, skipMarkerTestInt32(1440))
+	want := strings.Join([]string{
+		"WEBVTT",
+		"",
+		"1",
+		"00:00:00.000 --> 00:01:00.000",
+		"Recap",
+		"",
+		"2",
+		"00:01:00.000 --> 00:01:30.000",
+		"Episode",
+		"",
+		"3",
+		"00:01:30.000 --> 00:03:00.000",
+		"Opening: Blue Bird",
+		"",
+		"4",
+		"00:03:00.000 --> 00:21:40.000",
+		"Episode",
+		"",
+		"5",
+		"00:21:40.000 --> 00:24:00.000",
+		"Ending",
+		"",
+	}, "\n")
+	if vtt != want {
+		t.Fatalf("unexpected chapters:\n%s", vtt)
+	}
+
+	if empty := BuildSkipChapterVTT(nil, nil); empty != "WEBVTT\n" {
+		t.Fatalf("expected empty track, got %q", empty)
+	}
+}
+
+func TestBuildJellyfinMediaSegments(t *testing.T) {
+	itemID := "a1b2c3"
+	markers := ResolveEpisodeSkipMarkers(skipMarkerTestSource())
+
+	result := BuildJellyfinMediaSegments(markers, 11, &itemID)
+	if result.TotalRecordCount != 3 || result.StartIndex != 0 || len(result.Items) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	intro := result.Items[1]
+	if intro.Type != "Intro" || intro.StartTicks != 900_000_000 || intro.EndTicks != 1_800_000_000 || intro.ItemID == nil || *intro.ItemID != itemID {
+		t.Fatalf("unexpected intro segment: %+v", intro)
+	}
+	if result.Items[0].Type != "Recap" || result.Items[2].Type != "Outro" {
+		t.Fatalf("unexpected segment types: %+v", result.Items)
+	}
+	if len(intro.ID) != 36 || intro.ID[14] != '5' {
+		t.Fatalf("expected version 5 uuid, got %q", intro.ID)
+	}
+	if again := BuildJellyfinMediaSegments(markers, 11, &itemID); again.Items[1].ID != intro.ID {
+		t.Fatalf("expected stable segment ids")
+	}
+	if other := BuildJellyfinMediaSegments(markers, 12, &itemID); other.Items[1].ID == intro.ID {
+		t.Fatalf("expected ids to differ per variant")
+	}
+}
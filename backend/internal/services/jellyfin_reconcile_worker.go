@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+)
+
+// JellyfinReconciler führt einen Abgleich zwischen Katalog und Jellyfin-Bibliotheken aus
+// (implementiert von handlers.AdminContentHandler).
+type JellyfinReconciler interface {
+	ReconcileJellyfinLibraries(ctx context.Context) (*models.JellyfinReconciliationRun, error)
+}
+
+// JellyfinReconcileWorkerConfig steuert den Zeitplan des Abgleichs. Werte <= 0 nutzen die Defaults.
+type JellyfinReconcileWorkerConfig struct {
+	Interval     time.Duration // Abstand zwischen zwei Läufen (Default 6h)
+	InitialDelay time.Duration // Wartezeit vor dem ersten Lauf nach dem Start (Default 5min)
+}
+
+func (c JellyfinReconcileWorkerConfig) withDefaults() JellyfinReconcileWorkerConfig {
+	if c.Interval <= 0 {
+		c.Interval = 6 * time.Hour
+	}
+	if c.InitialDelay <= 0 {
+		c.InitialDelay = 5 * time.Minute
+	}
+	return c
+}
+
+// JellyfinReconcileWorker startet den Bibliotheksabgleich periodisch. Läuft bereits ein Abgleich
+// (etwa auf einer anderen Instanz), wird der Termin übersprungen.
+type JellyfinReconcileWorker struct {
+	reconciler JellyfinReconciler
+	cfg        JellyfinReconcileWorkerConfig
+}
+
+// NewJellyfinReconcileWorker erstellt einen Worker für reconciler.
+func NewJellyfinReconcileWorker(reconciler JellyfinReconciler, cfg JellyfinReconcileWorkerConfig) *JellyfinReconcileWorker {
+	return &JellyfinReconcileWorker{reconciler: reconciler, cfg: cfg.withDefaults()}
+}
+
+// Run führt den Abgleich nach InitialDelay und danach alle Interval aus, bis ctx endet.
+func (w *JellyfinReconcileWorker) Run(ctx context.Context) {
+	timer := time.NewTimer(w.cfg.InitialDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		w.RunOnce(ctx)
+		timer.Reset(w.cfg.Interval)
+	}
+}
+
+// RunOnce führt einen Abgleich aus und protokolliert das Ergebnis. Gibt den beendeten Lauf
+// zurück oder nil, wenn kein Lauf stattfand.
+func (w *JellyfinReconcileWorker) RunOnce(ctx context.Context) *models.JellyfinReconciliationRun {
+	run, err := w.reconciler.ReconcileJellyfinLibraries(ctx)
+	if errors.Is(err, repository.ErrConflict) {
+		log.Printf("jellyfin reconcile: skipped, another run is still active")
+		return nil
+	}
+	if err != nil {
+		log.Printf("jellyfin reconcile: %v", err)
+		return nil
+	}
+	log.Printf(
+		"jellyfin reconcile: run %d %s (anime=%d, findings=%d, auto_resolved=%d)",
+		run.ID, run.Status, run.AnimeChecked, run.FindingsDetected, run.FindingsAutoResolved,
+	)
+	return run
+}
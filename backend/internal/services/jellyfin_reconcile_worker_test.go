@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+)
+
+type jellyfinReconcilerStub struct {
+	calls int
+	err   error
+}
+
+func (s *jellyfinReconcilerStub) ReconcileJellyfinLibraries(context.Context) (*models.JellyfinReconciliationRun, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.JellyfinReconciliationRun{ID: int64(s.calls), Status: models.JellyfinReconciliationRunStatusCompleted}, nil
+}
+
+func TestJellyfinReconcileWorkerRunOnce(t *testing.T) {
+	reconciler := &jellyfinReconcilerStub{}
+	worker := NewJellyfinReconcileWorker(reconciler, JellyfinReconcileWorkerConfig{})
+	if worker.cfg.Interval <= 0 || worker.cfg.InitialDelay <= 0 {
+		t.Fatalf("expected defaults, got %+v", worker.cfg)
+	}
+
+	if run := worker.RunOnce(context.Background()); run == nil || run.ID != 1 {
+		t.Fatalf("expected finished run, got %+v", run)
+	}
+
+	reconciler.err = repository.ErrConflict
+	if run := worker.RunOnce(context.Background()); run != nil {
+		t.Fatalf("expected skipped run on conflict, got %+v", run)
+	}
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// LocalMediaProviderName ist der MediaProvider-Wert von Episodenversionen, deren MediaItemID
+// ein relativer Pfad unterhalb des konfigurierten LOCAL_MEDIA_ROOT ist.
+const LocalMediaProviderName = "local"
+
+const localMediaProbeTimeout = 30 * time.Second
+
+var (
+	// ErrLocalMediaDisabled signalisiert, dass kein LOCAL_MEDIA_ROOT konfiguriert ist.
+	ErrLocalMediaDisabled = errors.New("local media root is not configured")
+	// ErrLocalMediaInvalidPath signalisiert einen Pfad, der absolut ist, ".." enthält oder
+	// nach Auflösung von Symlinks außerhalb des Medienverzeichnisses liegt.
+	ErrLocalMediaInvalidPath = errors.New("local media path is invalid")
+	// ErrLocalMediaNotFound signalisiert eine fehlende Datei bzw. einen fehlenden Ordner.
+	ErrLocalMediaNotFound = errors.New("local media file not found")
+
+	localMediaVideoContentTypes = map[string]string{
+		".mkv":  "video/x-matroska",
+		".mp4":  "video/mp4",
+		".m4v":  "video/x-m4v",
+		".webm": "video/webm",
+		".avi":  "video/x-msvideo",
+		".mov":  "video/quicktime",
+		".ts":   "video/mp2t",
+		".m2ts": "video/mp2t",
+		".ogm":  "video/ogg",
+	}
+
+	localMediaSeasonEpisodePattern = regexp.MustCompile(`(?i)s\d{1,2}e(\d{1,4})`)
+	localMediaDashEpisodePattern   = regexp.MustCompile(`\s-\s(?:e|ep)?(\d{1,4})(?:v\d)?(?:[\s._\-\[(]|$)`)
+	localMediaEpisodePattern       = regexp.MustCompile(`(?i)(?:^|[\s._\-\]])(?:e|ep|episode|folge)?\s?(\d{1,4})(?:v\d)?(?:[\s._\-\[(]|$)`)
+	localMediaQualityPattern       = regexp.MustCompile(`(?i)(?:^|[^0-9])(2160|1440|1080|720|576|480|360)p`)
+)
+
+// LocalMediaFile beschreibt eine Videodatei unterhalb des Medienverzeichnisses.
+type LocalMediaFile struct {
+	RelativePath          string // mit "/" getrennter Pfad relativ zum Medienverzeichnis (= MediaItemID)
+	AbsolutePath          string // aufgelöster Dateisystempfad
+	Size                  int64
+	ModTime               time.Time
+	ContentType           string
+	VideoQuality          *string // aus dem Dateinamen erkannte Auflösung, z. B. "1080p"
+	DetectedEpisodeNumber *int32  // aus dem Dateinamen erkannte Episodennummer
+}
+
+// LocalMediaStream beschreibt einen einzelnen Stream aus der ffprobe-Ausgabe.
+type LocalMediaStream struct {
+	Index     int     `json:"index"`
+	CodecType string  `json:"codec_type"`
+	CodecName string  `json:"codec_name"`
+	Language  *string `json:"language,omitempty"`
+	Title     *string `json:"title,omitempty"`
+	Width     *int    `json:"width,omitempty"`
+	Height    *int    `json:"height,omitempty"`
+	Channels  *int    `json:"channels,omitempty"`
+	Default   bool    `json:"default"`
+}
+
+// LocalMediaProbe enthält Laufzeit und Stream-Metadaten einer lokalen Datei.
+type LocalMediaProbe struct {
+	DurationSeconds *int32             `json:"duration_seconds,omitempty"`
+	FormatName      string             `json:"format_name"`
+	Streams         []LocalMediaStream `json:"streams"`
+}
+
+// LocalMediaLibrary kapselt den Zugriff auf Mediendateien unterhalb eines festen Wurzelverzeichnisses.
+// Alle Pfade werden vor dem Zugriff gegen das Wurzelverzeichnis geprüft, auch nach Auflösung von Symlinks.
+type LocalMediaLibrary struct {
+	root        string
+	ffprobePath string
+	runProbe    func(ctx context.Context, ffprobePath string, filePath string) ([]byte, error)
+}
+
+// NewLocalMediaLibrary erstellt eine LocalMediaLibrary. Ein leeres root deaktiviert den Provider;
+// ffprobe wird wie beim Video-Upload neben der FFmpeg-Binärdatei erwartet.
+func NewLocalMediaLibrary(root string, ffmpegPath string) *LocalMediaLibrary {
+	return &LocalMediaLibrary{
+		root:        strings.TrimSpace(root),
+		ffprobePath: strings.Replace(strings.TrimSpace(ffmpegPath), "ffmpeg", "ffprobe", 1),
+		runProbe:    runFFprobe,
+	}
+}
+
+// Enabled meldet, ob ein Medienverzeichnis konfiguriert ist.
+func (l *LocalMediaLibrary) Enabled() bool {
+	return l != nil && l.root != ""
+}
+
+// Resolve liefert den aufgelösten Dateisystempfad zu einem relativen Medienpfad.
+func (l *LocalMediaLibrary) Resolve(relativePath string) (string, error) {
+	root, err := l.resolvedRoot()
+	if err != nil {
+		return "", err
+	}
+
+	normalized := strings.ReplaceAll(strings.TrimSpace(relativePath), "\\", "/")
+	if normalized == "" || strings.ContainsRune(normalized, 0) || strings.HasPrefix(normalized, "/") || filepath.IsAbs(normalized) || filepath.VolumeName(normalized) != "" {
+		return "", ErrLocalMediaInvalidPath
+	}
+	for _, segment := range strings.Split(normalized, "/") {
+		if segment == ".." {
+			return "", ErrLocalMediaInvalidPath
+		}
+	}
+
+	return l.confine(root, filepath.Join(root, filepath.FromSlash(path.Clean(normalized))))
+}
+
+// Stat löst einen relativen Medienpfad auf und liefert die Metadaten einer regulären Videodatei.
+func (l *LocalMediaLibrary) Stat(relativePath string) (*LocalMediaFile, error) {
+	absolutePath, err := l.Resolve(relativePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(absolutePath)
+	if err != nil {
+		return nil, ErrLocalMediaNotFound
+	}
+	if !info.Mode().IsRegular() {
+		return nil, ErrLocalMediaNotFound
+	}
+
+	root, err := l.resolvedRoot()
+	if err != nil {
+		return nil, err
+	}
+	file := buildLocalMediaFile(root, absolutePath, info)
+	file.ContentType = DetectLocalMediaContentType(absolutePath)
+	return &file, nil
+}
+
+// Scan durchsucht einen Ordner rekursiv nach Videodateien. folder darf relativ zum Medienverzeichnis
+// oder ein absoluter Pfad innerhalb des Medienverzeichnisses sein; ein leerer Ordner steht für die Wurzel.
+// Symlinks auf Ordner werden nicht verfolgt.
+func (l *LocalMediaLibrary) Scan(folder string) ([]LocalMediaFile, error) {
+	root, err := l.resolvedRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := root
+	trimmed := strings.TrimSpace(folder)
+	switch {
+	case trimmed == "":
+	case filepath.IsAbs(trimmed):
+		dir, err = l.confine(root, filepath.Clean(trimmed))
+	default:
+		dir, err = l.Resolve(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info, statErr := os.Stat(dir); statErr != nil || !info.IsDir() {
+		return nil, ErrLocalMediaNotFound
+	}
+
+	files := make([]LocalMediaFile, 0)
+	walkErr := filepath.WalkDir(dir, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if current != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !IsLocalMediaVideoFile(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		file := buildLocalMediaFile(root, current, info)
+		file.ContentType = localMediaVideoContentTypes[strings.ToLower(filepath.Ext(current))]
+		files = append(files, file)
+		return nil
+	})
+	if walkErr != nil {
+		return nil, fmt.Errorf("scan local media folder %q: %w", folder, walkErr)
+	}
+	return files, nil
+}
+
+// Probe liest Laufzeit und Streams einer Datei per ffprobe aus.
+func (l *LocalMediaLibrary) Probe(ctx context.Context, relativePath string) (*LocalMediaProbe, error) {
+	absolutePath, err := l.Resolve(relativePath)
+	if err != nil {
+		return nil, err
+	}
+	if l.ffprobePath == "" {
+		return nil, fmt.Errorf("ffprobe path is not configured")
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, localMediaProbeTimeout)
+	defer cancel()
+	output, err := l.runProbe(probeCtx, l.ffprobePath, absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe %q: %w", relativePath, err)
+	}
+	return parseFFprobeOutput(output)
+}
+
+// IsLocalMediaVideoFile meldet, ob ein Dateiname eine unterstützte Video-Endung trägt.
+func IsLocalMediaVideoFile(name string) bool {
+	_, ok := localMediaVideoContentTypes[strings.ToLower(filepath.Ext(name))]
+	return ok
+}
+
+// DetectLocalMediaContentType ermittelt den MIME-Typ anhand der Endung und fällt für unbekannte
+// Endungen auf eine Inhaltserkennung zurück.
+func DetectLocalMediaContentType(filePath string) string {
+	if contentType, ok := localMediaVideoContentTypes[strings.ToLower(filepath.Ext(filePath))]; ok {
+		return contentType
+	}
+	detected, err := mimetype.DetectFile(filePath)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return detected.String()
+}
+
+func (l *LocalMediaLibrary) resolvedRoot() (string, error) {
+	if !l.Enabled() {
+		return "", ErrLocalMediaDisabled
+	}
+	absoluteRoot, err := filepath.Abs(l.root)
+	if err != nil {
+		return "", fmt.Errorf("resolve local media root: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(absoluteRoot)
+	if err != nil {
+		return "", fmt.Errorf("resolve local media root: %w", err)
+	}
+	return root, nil
+}
+
+// confine löst Symlinks in candidate auf und stellt sicher, dass das Ziel innerhalb von root liegt.
+func (l *LocalMediaLibrary) confine(root string, candidate string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(candidate)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrLocalMediaNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolve local media path: %w", err)
+	}
+	relative, err := filepath.Rel(root, resolved)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) || filepath.IsAbs(relative) {
+		return "", ErrLocalMediaInvalidPath
+	}
+	return resolved, nil
+}
+
+func buildLocalMediaFile(root string, absolutePath string, info fs.FileInfo) LocalMediaFile {
+	relative, _ := filepath.Rel(root, absolutePath)
+	file := LocalMediaFile{
+		RelativePath: filepath.ToSlash(relative),
+		AbsolutePath: absolutePath,
+		Size:         info.Size(),
+		ModTime:      info.ModTime().UTC(),
+	}
+	name := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
+	if match := localMediaQualityPattern.FindStringSubmatch(name); len(match) == 2 {
+		quality := match[1] + "p"
+		file.VideoQuality = &quality
+	}
+	if episodeNumber := detectLocalMediaEpisodeNumber(name); episodeNumber > 0 {
+		file.DetectedEpisodeNumber = &episodeNumber
+	}
+	return file
+}
+
+// detectLocalMediaEpisodeNumber erkennt Episodennummern in Dateinamen wie "S01E05",
+// "[Gruppe] Titel - 05 [1080p]" oder "Titel Ep05". Auflösungsangaben werden ignoriert.
+func detectLocalMediaEpisodeNumber(name string) int32 {
+	if match := localMediaSeasonEpisodePattern.FindStringSubmatch(name); len(match) == 2 {
+		if value, err := strconv.ParseInt(match[1], 10, 32); err == nil && value > 0 {
+			return int32(value)
+		}
+	}
+	withoutQuality := localMediaQualityPattern.ReplaceAllString(name, " ")
+	if match := localMediaDashEpisodePattern.FindStringSubmatch(withoutQuality); len(match) == 2 {
+		if value, err := strconv.ParseInt(match[1], 10, 32); err == nil && value > 0 {
+			return int32(value)
+		}
+	}
+	for _, match := range localMediaEpisodePattern.FindAllStringSubmatch(withoutQuality, -1) {
+		if value, err := strconv.ParseInt(match[1], 10, 32); err == nil && value > 0 {
+			return int32(value)
+		}
+	}
+	return 0
+}
+
+type ffprobeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+	Streams []struct {
+		Index       int               `json:"index"`
+		CodecType   string            `json:"codec_type"`
+		CodecName   string            `json:"codec_name"`
+		Width       int               `json:"width"`
+		Height      int               `json:"height"`
+		Channels    int               `json:"channels"`
+		Tags        map[string]string `json:"tags"`
+		Disposition map[string]int    `json:"disposition"`
+	} `json:"streams"`
+}
+
+func parseFFprobeOutput(output []byte) (*LocalMediaProbe, error) {
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("decode ffprobe output: %w", err)
+	}
+
+	probe := &LocalMediaProbe{
+		FormatName: parsed.Format.FormatName,
+		Streams:    make([]LocalMediaStream, 0, len(parsed.Streams)),
+	}
+	if duration, err := strconv.ParseFloat(strings.TrimSpace(parsed.Format.Duration), 64); err == nil && duration > 0 && duration < math.MaxInt32 {
+		seconds := int32(math.Round(duration))
+		probe.DurationSeconds = &seconds
+	}
+	for _, stream := range parsed.Streams {
+		item := LocalMediaStream{
+			Index:     stream.Index,
+			CodecType: stream.CodecType,
+			CodecName: stream.CodecName,
+			Default:   stream.Disposition["default"] == 1,
+		}
+		if language := strings.TrimSpace(stream.Tags["language"]); language != "" {
+			item.Language = &language
+		}
+		if title := strings.TrimSpace(stream.Tags["title"]); title != "" {
+			item.Title = &title
+		}
+		if stream.Width > 0 && stream.Height > 0 {
+			width, height := stream.Width, stream.Height
+			item.Width = &width
+			item.Height = &height
+		}
+		if stream.Channels > 0 {
+			channels := stream.Channels
+			item.Channels = &channels
+		}
+		probe.Streams = append(probe.Streams, item)
+	}
+	return probe, nil
+}
+
+func runFFprobe(ctx context.Context, ffprobePath string, filePath string) ([]byte, error) {
+	cmd := exec.CommandContext(
+		ctx,
+		ffprobePath,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		filePath,
+	)
+	return cmd.Output()
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestLocalMediaLibrary(t *testing.T) (*LocalMediaLibrary, string) {
+	t.Helper()
+	root := t.TempDir()
+	mustWriteLocalMediaFile(t, filepath.Join(root, "Frieren", "[Team4s] Frieren - 05 [1080p].mkv"), "episode-five")
+	mustWriteLocalMediaFile(t, filepath.Join(root, "Frieren", "Extras", "Frieren.S01E12.720p.mp4"), "episode-twelve")
+	mustWriteLocalMediaFile(t, filepath.Join(root, "Frieren", "notes.txt"), "not a video")
+	mustWriteLocalMediaFile(t, filepath.Join(root, "Frieren", ".trash", "old - 01.mkv"), "hidden")
+	return NewLocalMediaLibrary(root, "/usr/bin/ffmpeg"), root
+}
+
+func mustWriteLocalMediaFile(t *testing.T, filePath string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+}
+
+func TestLocalMediaLibraryResolveRejectsTraversal(t *testing.T) {
+	library, root := newTestLocalMediaLibrary(t)
+	outside := t.TempDir()
+	mustWriteLocalMediaFile(t, filepath.Join(outside, "secret.mkv"), "secret")
+	if err := os.Symlink(filepath.Join(outside, "secret.mkv"), filepath.Join(root, "Frieren", "escape.mkv")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	for _, raw := range []string{"", "../secret.mkv", "Frieren/../../secret.mkv", "Frieren\\..\\..\\secret.mkv", "/etc/passwd", filepath.Join(outside, "secret.mkv"), "Frieren/escape.mkv"} {
+		if _, err := library.Resolve(raw); !errors.Is(err, ErrLocalMediaInvalidPath) {
+			t.Fatalf("expected ErrLocalMediaInvalidPath for %q, got %v", raw, err)
+		}
+	}
+	if _, err := library.Resolve("Frieren/missing.mkv"); !errors.Is(err, ErrLocalMediaNotFound) {
+		t.Fatalf("expected ErrLocalMediaNotFound, got %v", err)
+	}
+	if _, err := NewLocalMediaLibrary("", "").Resolve("Frieren/x.mkv"); !errors.Is(err, ErrLocalMediaDisabled) {
+		t.Fatalf("expected ErrLocalMediaDisabled, got %v", err)
+	}
+}
+
+func TestLocalMediaLibraryStatDetectsContentType(t *testing.T) {
+	library, _ := newTestLocalMediaLibrary(t)
+
+	file, err := library.Stat("Frieren/./[Team4s] Frieren - 05 [1080p].mkv")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if file.RelativePath != "Frieren/[Team4s] Frieren - 05 [1080p].mkv" || file.ContentType != "video/x-matroska" || file.Size != int64(len("episode-five")) {
+		t.Fatalf("unexpected file %+v", file)
+	}
+	if _, err := library.Stat("Frieren"); !errors.Is(err, ErrLocalMediaNotFound) {
+		t.Fatalf("expected directories to be rejected, got %v", err)
+	}
+}
+
+func TestLocalMediaLibraryScanFindsVideoFiles(t *testing.T) {
+	library, root := newTestLocalMediaLibrary(t)
+
+	for _, folder := range []string{"Frieren", filepath.Join(root, "Frieren")} {
+		files, err := library.Scan(folder)
+		if err != nil {
+			t.Fatalf("scan %q: %v", folder, err)
+		}
+		if len(files) != 2 {
+			t.Fatalf("expected two video files in %q, got %+v", folder, files)
+		}
+		byPath := map[string]LocalMediaFile{}
+		for _, file := range files {
+			byPath[file.RelativePath] = file
+		}
+		five := byPath["Frieren/[Team4s] Frieren - 05 [1080p].mkv"]
+		twelve := byPath["Frieren/Extras/Frieren.S01E12.720p.mp4"]
+		if five.DetectedEpisodeNumber == nil || *five.DetectedEpisodeNumber != 5 || five.VideoQuality == nil || *five.VideoQuality != "1080p" {
+			t.Fatalf("unexpected metadata for episode 5: %+v", five)
+		}
+		if twelve.DetectedEpisodeNumber == nil || *twelve.DetectedEpisodeNumber != 12 || twelve.ContentType != "video/mp4" {
+			t.Fatalf("unexpected metadata for episode 12: %+v", twelve)
+		}
+	}
+
+	if _, err := library.Scan(t.TempDir()); !errors.Is(err, ErrLocalMediaInvalidPath) {
+		t.Fatalf("expected absolute folders outside the root to be rejected, got %v", err)
+	}
+}
+
+func TestLocalMediaLibraryProbeParsesFFprobeOutput(t *testing.T) {
+	library, root := newTestLocalMediaLibrary(t)
+	var probedPath string
+	library.runProbe = func(_ context.Context, ffprobePath string, filePath string) ([]byte, error) {
+		if ffprobePath != "/usr/bin/ffprobe" {
+			t.Fatalf("unexpected ffprobe path %q", ffprobePath)
+		}
+		probedPath = filePath
+		return []byte(`{
+			"streams": [
+				{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "disposition": {"default": 1}},
+				{"index": 1, "codec_type": "audio", "codec_name": "aac", "channels": 2, "tags": {"language": "jpn"}},
+				{"index": 2, "codec_type": "subtitle", "codec_name": "ass", "tags": {"language": "ger", "title": "Deutsch"}}
+			],
+			"format": {"format_name": "matroska,webm", "duration": "1420.480000"}
+		}`), nil
+	}
+
+	probe, err := library.Probe(context.Background(), "Frieren/[Team4s] Frieren - 05 [1080p].mkv")
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	resolvedRoot, _ := filepath.EvalSymlinks(root)
+	if probedPath != filepath.Join(resolvedRoot, "Frieren", "[Team4s] Frieren - 05 [1080p].mkv") {
+		t.Fatalf("unexpected probed path %q", probedPath)
+	}
+	if probe.DurationSeconds == nil || *probe.DurationSeconds != 1420 || len(probe.Streams) != 3 {
+		t.Fatalf("unexpected probe %+v", probe)
+	}
+	if video := probe.Streams[0]; video.Height == nil || *video.Height != 1080 || !video.Default {
+		t.Fatalf("unexpected video stream %+v", video)
+	}
+	if subtitle := probe.Streams[2]; subtitle.Language == nil || *subtitle.Language != "ger" || subtitle.Title == nil || *subtitle.Title != "Deutsch" {
+		t.Fatalf("unexpected subtitle stream %+v", subtitle)
+	}
+}
+
+func TestDetectLocalMediaEpisodeNumber(t *testing.T) {
+	cases := map[string]int32{
+		"[Group] Mob Psycho 100 - 07 [1080p]": 7,
+		"Frieren.S01E12.1080p.WEB":            12,
+		"Frieren Ep03v2":                      3,
+		"Frieren 1080p":                       0,
+	}
+	for name, expected := range cases {
+		if got := detectLocalMediaEpisodeNumber(name); got != expected {
+			t.Fatalf("detectLocalMediaEpisodeNumber(%q) = %d, expected %d", name, got, expected)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// MailOutboxStore ist die Datenbankschnittstelle des MailOutboxWorker
+// (implementiert von repository.MailOutboxRepository).
+type MailOutboxStore interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.MailOutboxMessage, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, sendErr string, nextAttemptAt *time.Time) error
+}
+
+// MailOutboxConfig steuert Polling und Backoff des Workers. Werte <= 0 nutzen die Defaults.
+type MailOutboxConfig struct {
+	PollInterval time.Duration // Abstand zwischen zwei Durchläufen (Default 15s)
+	BatchSize    int           // Mails pro Durchlauf (Default 20)
+	BaseBackoff  time.Duration // Wartezeit nach dem ersten Fehlversuch (Default 1m)
+	MaxBackoff   time.Duration // Obergrenze der Wartezeit (Default 6h)
+	SendTimeout  time.Duration // Timeout je SMTP-Versand (Default 30s)
+}
+
+func (c MailOutboxConfig) withDefaults() MailOutboxConfig {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 15 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 20
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = time.Minute
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 6 * time.Hour
+	}
+	if c.MaxBackoff < c.BaseBackoff {
+		c.MaxBackoff = c.BaseBackoff
+	}
+	if c.SendTimeout <= 0 {
+		c.SendTimeout = 30 * time.Second
+	}
+	return c
+}
+
+// MailOutboxWorker versendet eingereihte Mails. Fehlversuche werden mit exponentiellem
+// Backoff wiederholt; nach max_attempts oder bei nicht renderbaren Templates landet die Mail
+// im Status dead. Alle Fehler werden geloggt und stoppen den Server nicht.
+type MailOutboxWorker struct {
+	store     MailOutboxStore
+	mailer    Mailer
+	templates *MailTemplates
+	cfg       MailOutboxConfig
+	now       func() time.Time
+}
+
+// NewMailOutboxWorker erstellt einen Worker für den Outbox.
+func NewMailOutboxWorker(store MailOutboxStore, mailer Mailer, templates *MailTemplates, cfg MailOutboxConfig) *MailOutboxWorker {
+	return &MailOutboxWorker{
+		store:     store,
+		mailer:    mailer,
+		templates: templates,
+		cfg:       cfg.withDefaults(),
+		now:       time.Now,
+	}
+}
+
+// PollInterval liefert den Abstand zwischen zwei Durchläufen.
+func (w *MailOutboxWorker) PollInterval() time.Duration {
+	return w.cfg.PollInterval
+}
+
+// Run arbeitet den Outbox bis zum Ende von ctx periodisch ab.
+func (w *MailOutboxWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce beansprucht einen Batch fälliger Mails und versendet ihn. Gibt die Anzahl der
+// erfolgreich versendeten Mails zurück.
+func (w *MailOutboxWorker) RunOnce(ctx context.Context) int {
+	// Die Lease muss den Versand aller Mails des Batches abdecken, sonst könnte eine
+	// zweite Instanz sie erneut beanspruchen.
+	lease := w.cfg.SendTimeout*time.Duration(w.cfg.BatchSize) + time.Minute
+	messages, err := w.store.ClaimDue(ctx, w.cfg.BatchSize, lease)
+	if err != nil {
+		log.Printf("mail outbox: claim due entries: %v", err)
+		return 0
+	}
+
+	sent := 0
+	for _, message := range messages {
+		if w.deliver(ctx, message) {
+			sent++
+		}
+	}
+	return sent
+}
+
+func (w *MailOutboxWorker) deliver(ctx context.Context, message models.MailOutboxMessage) bool {
+	rendered, err := w.templates.Render(message.TemplateName, message.Locale, message.TemplateData)
+	if err != nil {
+		// Ein Template-Fehler wird durch Wiederholen nicht besser.
+		w.markFailed(ctx, message, err, nil)
+		return false
+	}
+	rendered.To = message.Recipient
+
+	sendCtx, cancel := context.WithTimeout(ctx, w.cfg.SendTimeout)
+	err = w.mailer.Send(sendCtx, rendered)
+	cancel()
+	if err != nil {
+		var next *time.Time
+		if message.Attempts < message.MaxAttempts {
+			retryAt := w.now().Add(w.backoff(message.Attempts))
+			next = &retryAt
+		}
+		w.markFailed(ctx, message, err, next)
+		return false
+	}
+
+	if err := w.store.MarkSent(ctx, message.ID); err != nil {
+		log.Printf("mail outbox: mark sent (id=%d): %v", message.ID, err)
+	}
+	return true
+}
+
+func (w *MailOutboxWorker) markFailed(ctx context.Context, message models.MailOutboxMessage, sendErr error, next *time.Time) {
+	if next == nil {
+		log.Printf("mail outbox: giving up (id=%d, template=%s, attempts=%d): %v", message.ID, message.TemplateName, message.Attempts, sendErr)
+	} else {
+		log.Printf("mail outbox: send failed, retry at %s (id=%d, attempts=%d): %v", next.UTC().Format(time.RFC3339), message.ID, message.Attempts, sendErr)
+	}
+	if err := w.store.MarkFailed(ctx, message.ID, sendErr.Error(), next); err != nil {
+		log.Printf("mail outbox: mark failed (id=%d): %v", message.ID, err)
+	}
+}
+
+// backoff liefert BaseBackoff * 2^(attempts-1), begrenzt auf MaxBackoff.
+func (w *MailOutboxWorker) backoff(attempts int) time.Duration {
+	delay := w.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.cfg.MaxBackoff {
+			return w.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
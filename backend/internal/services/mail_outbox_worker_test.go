@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/services/smtptest"
+)
+
+// memoryMailOutbox bildet den Outbox-Zustand für Worker-Tests im Speicher nach.
+type memoryMailOutbox struct {
+	entries map[int64]*memoryMailOutboxEntry
+}
+
+type memoryMailOutboxEntry struct {
+	message models.MailOutboxMessage
+	status  string
+	next    time.Time
+	lastErr string
+}
+
+func (s *memoryMailOutbox) add(message models.MailOutboxMessage) {
+	if s.entries == nil {
+		s.entries = map[int64]*memoryMailOutboxEntry{}
+	}
+	s.entries[message.ID] = &memoryMailOutboxEntry{message: message, status: models.MailOutboxStatusPending}
+}
+
+func (s *memoryMailOutbox) ClaimDue(_ context.Context, limit int, _ time.Duration) ([]models.MailOutboxMessage, error) {
+	claimed := make([]models.MailOutboxMessage, 0)
+	for id := int64(1); id <= int64(len(s.entries)) && len(claimed) < limit; id++ {
+		entry := s.entries[id]
+		if entry == nil || entry.status != models.MailOutboxStatusPending {
+			continue
+		}
+		entry.status = models.MailOutboxStatusSending
+		entry.message.Attempts++
+		claimed = append(claimed, entry.message)
+	}
+	return claimed, nil
+}
+
+func (s *memoryMailOutbox) MarkSent(_ context.Context, id int64) error {
+	s.entries[id].status = models.MailOutboxStatusSent
+	return nil
+}
+
+func (s *memoryMailOutbox) MarkFailed(_ context.Context, id int64, sendErr string, next *time.Time) error {
+	entry := s.entries[id]
+	entry.lastErr = sendErr
+	if next == nil {
+		entry.status = models.MailOutboxStatusDead
+		return nil
+	}
+	entry.status = models.MailOutboxStatusPending
+	entry.next = *next
+	return nil
+}
+
+func newTestMailOutboxWorker(t *testing.T, store MailOutboxStore, server *smtptest.Server) *MailOutboxWorker {
+	t.Helper()
+	templates, err := NewMailTemplates("https://team4s.example")
+	if err != nil {
+		t.Fatalf("unexpected template error: %v", err)
+	}
+	mailer := NewSMTPMailer(MailerConfig{Host: server.Host, Port: server.Port, FromEmail: "noreply@team4s.local"})
+	worker := NewMailOutboxWorker(store, mailer, templates, MailOutboxConfig{
+		BaseBackoff: time.Minute,
+		MaxBackoff:  10 * time.Minute,
+		SendTimeout: 3 * time.Second,
+	})
+	worker.now = func() time.Time { return time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return worker
+}
+
+func TestMailOutboxWorkerSendsRenderedTemplateThroughSMTP(t *testing.T) {
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("could not start smtp stand-in: %v", err)
+	}
+	defer server.Close()
+
+	store := &memoryMailOutbox{}
+	store.add(models.MailOutboxMessage{
+		ID:           1,
+		TemplateName: MailTemplateFansubGroupInvitation,
+		Locale:       models.MailLocaleEN,
+		Recipient:    "invitee@example.org",
+		TemplateData: map[string]any{"invite_link": "/invitations/accept?token=abc", "expires_at": "2026-06-08T12:00:00Z"},
+		MaxAttempts:  3,
+	})
+
+	if sent := newTestMailOutboxWorker(t, store, server).RunOnce(context.Background()); sent != 1 {
+		t.Fatalf("expected one sent mail, got %d", sent)
+	}
+	if store.entries[1].status != models.MailOutboxStatusSent {
+		t.Fatalf("expected entry to be sent, got %q (%s)", store.entries[1].status, store.entries[1].lastErr)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 || len(messages[0].To) != 1 || messages[0].To[0] != "invitee@example.org" {
+		t.Fatalf("expected one mail to the invitee, got %+v", messages)
+	}
+	for _, want := range []string{
+		"Invitation to a fansub group",
+		"https://team4s.example/invitations/accept?token=abc",
+		"June 8, 2026",
+	} {
+		if !strings.Contains(messages[0].Data, want) {
+			t.Fatalf("expected %q in mail, got:\n%s", want, messages[0].Data)
+		}
+	}
+}
+
+func TestMailOutboxWorkerBacksOffAndDeadLetters(t *testing.T) {
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("could not start smtp stand-in: %v", err)
+	}
+	defer server.Close()
+	server.RejectNext(2)
+
+	store := &memoryMailOutbox{}
+	store.add(models.MailOutboxMessage{
+		ID:           1,
+		TemplateName: MailTemplateNotification,
+		Recipient:    "user@example.org",
+		TemplateData: map[string]any{"title": "Hallo"},
+		MaxAttempts:  2,
+	})
+	worker := newTestMailOutboxWorker(t, store, server)
+	now := worker.now()
+
+	worker.RunOnce(context.Background())
+	entry := store.entries[1]
+	if entry.status != models.MailOutboxStatusPending || !entry.next.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected retry after base backoff, got status %q next %s", entry.status, entry.next)
+	}
+
+	worker.RunOnce(context.Background())
+	if entry.status != models.MailOutboxStatusDead {
+		t.Fatalf("expected dead letter after max attempts, got %q", entry.status)
+	}
+	if !strings.Contains(entry.lastErr, "abgelehnt") {
+		t.Fatalf("expected server rejection as last error, got %q", entry.lastErr)
+	}
+	if len(server.Messages()) != 0 {
+		t.Fatalf("expected no accepted mails, got %d", len(server.Messages()))
+	}
+}
+
+func TestMailOutboxWorkerDeadLettersUnrenderableTemplates(t *testing.T) {
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("could not start smtp stand-in: %v", err)
+	}
+	defer server.Close()
+
+	store := &memoryMailOutbox{}
+	store.add(models.MailOutboxMessage{ID: 1, TemplateName: MailTemplateNotification, Recipient: "user@example.org", MaxAttempts: 5})
+
+	newTestMailOutboxWorker(t, store, server).RunOnce(context.Background())
+
+	if store.entries[1].status != models.MailOutboxStatusDead {
+		t.Fatalf("expected missing template data to dead-letter immediately, got %q", store.entries[1].status)
+	}
+}
+
+func TestMailOutboxWorkerBackoffIsCapped(t *testing.T) {
+	worker := NewMailOutboxWorker(nil, nil, nil, MailOutboxConfig{BaseBackoff: time.Minute, MaxBackoff: 5 * time.Minute})
+
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		3:  4 * time.Minute,
+		4:  5 * time.Minute,
+		30: 5 * time.Minute,
+	} {
+		if got := worker.backoff(attempts); got != want {
+			t.Fatalf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// Namen der Mail-Templates. Handler und Services reihen Mails nur noch über diese Namen ein;
+// der Inhalt wird erst beim Versand aus den Template-Daten gerendert.
+const (
+	MailTemplateFansubGroupInvitation = "fansub_group_invitation"
+	MailTemplateNotification          = "notification"
+	MailTemplateDigest                = "digest"
+)
+
+// mailTemplateSource beschreibt eine Sprachvariante eines Templates. Subject und Text werden
+// mit text/template, HTML mit html/template (kontextabhängiges Escaping) gerendert.
+type mailTemplateSource struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// mailTemplateDefinition bündelt die Sprachvarianten und die Pflichtfelder eines Templates.
+type mailTemplateDefinition struct {
+	Required []string
+	Locales  map[string]mailTemplateSource
+}
+
+var builtinMailTemplates = map[string]mailTemplateDefinition{
+	MailTemplateFansubGroupInvitation: {
+		Required: []string{"invite_link"},
+		Locales: map[string]mailTemplateSource{
+			models.MailLocaleDE: {
+				Subject: `Einladung zur Fansub-Gruppe`,
+				Text: `Du wurdest zu einer Fansub-Gruppe eingeladen.
+
+Link zum Annehmen: {{url .invite_link}}
+{{with .expires_at}}
+Dieser Link ist gültig bis {{date .}}.{{end}}`,
+				HTML: `<p>Du wurdest zu einer Fansub-Gruppe eingeladen.</p>` +
+					`<p><a href="{{url .invite_link}}">Einladung annehmen</a></p>` +
+					`{{with .expires_at}}<p>Dieser Link ist gültig bis {{date .}}.</p>{{end}}`,
+			},
+			models.MailLocaleEN: {
+				Subject: `Invitation to a fansub group`,
+				Text: `You have been invited to join a fansub group.
+
+Accept the invitation: {{url .invite_link}}
+{{with .expires_at}}
+This link is valid until {{date .}}.{{end}}`,
+				HTML: `<p>You have been invited to join a fansub group.</p>` +
+					`<p><a href="{{url .invite_link}}">Accept invitation</a></p>` +
+					`{{with .expires_at}}<p>This link is valid until {{date .}}.</p>{{end}}`,
+			},
+		},
+	},
+	MailTemplateNotification: {
+		Required: []string{"title"},
+		Locales: map[string]mailTemplateSource{
+			models.MailLocaleDE: {
+				Subject: `{{.title}}`,
+				Text: `{{.title}}{{with .body}}
+
+{{.}}{{end}}{{with .link_url}}
+
+{{url .}}{{end}}`,
+				HTML: `<p>{{.title}}</p>{{with .body}}<p>{{.}}</p>{{end}}` +
+					`{{with .link_url}}<p><a href="{{url .}}">Öffnen</a></p>{{end}}`,
+			},
+			models.MailLocaleEN: {
+				Subject: `{{.title}}`,
+				Text: `{{.title}}{{with .body}}
+
+{{.}}{{end}}{{with .link_url}}
+
+{{url .}}{{end}}`,
+				HTML: `<p>{{.title}}</p>{{with .body}}<p>{{.}}</p>{{end}}` +
+					`{{with .link_url}}<p><a href="{{url .}}">Open</a></p>{{end}}`,
+			},
+		},
+	},
+	MailTemplateDigest: {
+		Required: []string{"frequency", "unsubscribe_link"},
+		Locales: map[string]mailTemplateSource{
+			models.MailLocaleDE: {
+				Subject: `{{if eq .frequency "weekly"}}Deine Wochenübersicht{{else}}Deine Tagesübersicht{{end}} bei Team4s`,
+				Text: `{{with .releases}}Neue Release-Versionen:
+{{range .}}
+- {{.anime_title}}, Episode {{.episode_number}} ({{.version}}){{with .group_names}} von {{.}}{{end}}
+  {{url .link}}{{end}}
+{{end}}{{with .comments}}
+Neue Antworten in deinen Threads:
+{{range .}}
+- {{.author_name}}: {{.excerpt}}
+  {{url .link}}{{end}}
+{{end}}
+Zusammenfassung abbestellen: {{url .unsubscribe_link}}`,
+				HTML: `{{with .releases}}<h2>Neue Release-Versionen</h2><ul>{{range .}}` +
+					`<li><a href="{{url .link}}">{{.anime_title}}, Episode {{.episode_number}} ({{.version}})</a>` +
+					`{{with .group_names}} von {{.}}{{end}}</li>{{end}}</ul>{{end}}` +
+					`{{with .comments}}<h2>Neue Antworten in deinen Threads</h2><ul>{{range .}}` +
+					`<li><a href="{{url .link}}">{{.author_name}}</a>: {{.excerpt}}</li>{{end}}</ul>{{end}}` +
+					`<p><a href="{{url .unsubscribe_link}}">Zusammenfassung abbestellen</a></p>`,
+			},
+			models.MailLocaleEN: {
+				Subject: `Your {{if eq .frequency "weekly"}}weekly{{else}}daily{{end}} Team4s summary`,
+				Text: `{{with .releases}}New release versions:
+{{range .}}
+- {{.anime_title}}, episode {{.episode_number}} ({{.version}}){{with .group_names}} by {{.}}{{end}}
+  {{url .link}}{{end}}
+{{end}}{{with .comments}}
+New replies in your threads:
+{{range .}}
+- {{.author_name}}: {{.excerpt}}
+  {{url .link}}{{end}}
+{{end}}
+Unsubscribe from this summary: {{url .unsubscribe_link}}`,
+				HTML: `{{with .releases}}<h2>New release versions</h2><ul>{{range .}}` +
+					`<li><a href="{{url .link}}">{{.anime_title}}, episode {{.episode_number}} ({{.version}})</a>` +
+					`{{with .group_names}} by {{.}}{{end}}</li>{{end}}</ul>{{end}}` +
+					`{{with .comments}}<h2>New replies in your threads</h2><ul>{{range .}}` +
+					`<li><a href="{{url .link}}">{{.author_name}}</a>: {{.excerpt}}</li>{{end}}</ul>{{end}}` +
+					`<p><a href="{{url .unsubscribe_link}}">Unsubscribe from this summary</a></p>`,
+			},
+		},
+	},
+}
+
+// mailDateLayouts legt das Datumsformat je Sprache fest.
+var mailDateLayouts = map[string]string{
+	models.MailLocaleDE: "02.01.2006",
+	models.MailLocaleEN: "January 2, 2006",
+}
+
+type compiledMailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// MailTemplates rendert die benannten, lokalisierten Mail-Templates. Relative Links werden
+// über die Template-Funktion url mit AppPublicURL zu absoluten Links ergänzt.
+type MailTemplates struct {
+	appPublicURL string
+	required     map[string][]string
+	compiled     map[string]map[string]compiledMailTemplate
+}
+
+// NewMailTemplates kompiliert alle eingebauten Templates. Ein Fehler bedeutet einen
+// Programmierfehler in einem Template und sollte den Start abbrechen.
+func NewMailTemplates(appPublicURL string) (*MailTemplates, error) {
+	t := &MailTemplates{
+		appPublicURL: strings.TrimRight(strings.TrimSpace(appPublicURL), "/"),
+		required:     make(map[string][]string, len(builtinMailTemplates)),
+		compiled:     make(map[string]map[string]compiledMailTemplate, len(builtinMailTemplates)),
+	}
+
+	for name, definition := range builtinMailTemplates {
+		t.required[name] = definition.Required
+		t.compiled[name] = make(map[string]compiledMailTemplate, len(definition.Locales))
+		for locale, source := range definition.Locales {
+			compiled, err := t.compile(name+"."+locale, locale, source)
+			if err != nil {
+				return nil, err
+			}
+			t.compiled[name][locale] = compiled
+		}
+		if _, ok := t.compiled[name][models.DefaultMailLocale]; !ok {
+			return nil, fmt.Errorf("mail template %q: missing default locale %q", name, models.DefaultMailLocale)
+		}
+	}
+
+	return t, nil
+}
+
+// Has meldet, ob ein Template mit diesem Namen existiert.
+func (t *MailTemplates) Has(name string) bool {
+	_, ok := t.compiled[name]
+	return ok
+}
+
+// Render erzeugt die Mail für Template, Sprache und Daten. Unbekannte Sprachen fallen auf
+// models.DefaultMailLocale zurück; fehlende Pflichtfelder liefern einen Fehler.
+func (t *MailTemplates) Render(name string, locale string, data map[string]any) (MailMessage, error) {
+	variants, ok := t.compiled[name]
+	if !ok {
+		return MailMessage{}, fmt.Errorf("mail template %q: unknown template", name)
+	}
+	compiled, ok := variants[locale]
+	if !ok {
+		compiled = variants[models.DefaultMailLocale]
+	}
+	for _, key := range t.required[name] {
+		if value, ok := data[key]; !ok || value == nil || value == "" {
+			return MailMessage{}, fmt.Errorf("mail template %q: missing field %q", name, key)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := compiled.subject.Execute(&subject, data); err != nil {
+		return MailMessage{}, fmt.Errorf("mail template %q: render subject: %w", name, err)
+	}
+	if err := compiled.text.Execute(&text, data); err != nil {
+		return MailMessage{}, fmt.Errorf("mail template %q: render text: %w", name, err)
+	}
+	if err := compiled.html.Execute(&html, data); err != nil {
+		return MailMessage{}, fmt.Errorf("mail template %q: render html: %w", name, err)
+	}
+
+	return MailMessage{
+		Subject:  strings.TrimSpace(subject.String()),
+		BodyText: strings.TrimSpace(text.String()),
+		BodyHTML: strings.TrimSpace(html.String()),
+	}, nil
+}
+
+func (t *MailTemplates) compile(name string, locale string, source mailTemplateSource) (compiledMailTemplate, error) {
+	funcs := map[string]any{
+		"url":  t.absoluteURL,
+		"date": mailDateFormatter(locale),
+	}
+
+	subject, err := texttemplate.New(name + ".subject").Funcs(funcs).Parse(source.Subject)
+	if err != nil {
+		return compiledMailTemplate{}, fmt.Errorf("mail template %q: parse subject: %w", name, err)
+	}
+	text, err := texttemplate.New(name + ".text").Funcs(funcs).Parse(source.Text)
+	if err != nil {
+		return compiledMailTemplate{}, fmt.Errorf("mail template %q: parse text: %w", name, err)
+	}
+	html, err := htmltemplate.New(name + ".html").Funcs(funcs).Parse(source.HTML)
+	if err != nil {
+		return compiledMailTemplate{}, fmt.Errorf("mail template %q: parse html: %w", name, err)
+	}
+
+	return compiledMailTemplate{subject: subject, text: text, html: html}, nil
+}
+
+// absoluteURL ergänzt relative Pfade um AppPublicURL; absolute URLs bleiben unverändert.
+func (t *MailTemplates) absoluteURL(value any) string {
+	link := strings.TrimSpace(fmt.Sprint(value))
+	if link == "" || strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		return link
+	}
+	if !strings.HasPrefix(link, "/") {
+		link = "/" + link
+	}
+	return t.appPublicURL + link
+}
+
+// mailDateFormatter formatiert time.Time oder RFC-3339-Strings (so landen Zeitpunkte nach dem
+// JSON-Umweg durch den Outbox in den Template-Daten) im Datumsformat der Sprache.
+func mailDateFormatter(locale string) func(any) string {
+	layout, ok := mailDateLayouts[locale]
+	if !ok {
+		layout = mailDateLayouts[models.DefaultMailLocale]
+	}
+	return func(value any) string {
+		switch typed := value.(type) {
+		case time.Time:
+			return typed.UTC().Format(layout)
+		case string:
+			parsed, err := time.Parse(time.RFC3339, typed)
+			if err != nil {
+				return typed
+			}
+			return parsed.UTC().Format(layout)
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
+
+func TestMailTemplatesRenderLocalizedVariants(t *testing.T) {
+	templates, err := NewMailTemplates("https://team4s.example/")
+	if err != nil {
+		t.Fatalf("unexpected template error: %v", err)
+	}
+	data := map[string]any{"invite_link": "/invitations/accept?token=abc", "expires_at": "2026-06-08T12:00:00Z"}
+
+	de, err := templates.Render(MailTemplateFansubGroupInvitation, models.MailLocaleDE, data)
+	if err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+	if de.Subject != "Einladung zur Fansub-Gruppe" || !strings.Contains(de.BodyText, "gültig bis 08.06.2026") {
+		t.Fatalf("unexpected german mail: %+v", de)
+	}
+
+	fallback, err := templates.Render(MailTemplateFansubGroupInvitation, "fr", data)
+	if err != nil || fallback.Subject != de.Subject {
+		t.Fatalf("expected unknown locale to fall back to german, got %+v (%v)", fallback, err)
+	}
+
+	en, err := templates.Render(MailTemplateFansubGroupInvitation, models.MailLocaleEN, data)
+	if err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+	if !strings.Contains(en.BodyHTML, `href="https://team4s.example/invitations/accept?token=abc"`) {
+		t.Fatalf("expected absolute link in html body, got %q", en.BodyHTML)
+	}
+}
+
+func TestMailTemplatesEscapeHTMLAndRequireFields(t *testing.T) {
+	templates, err := NewMailTemplates("https://team4s.example")
+	if err != nil {
+		t.Fatalf("unexpected template error: %v", err)
+	}
+
+	rendered, err := templates.Render(MailTemplateNotification, models.MailLocaleDE, map[string]any{
+		"title": "Neu <script>",
+		"body":  "a & b",
+	})
+	if err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+	if strings.Contains(rendered.BodyHTML, "<script>") || !strings.Contains(rendered.BodyHTML, "a &amp; b") {
+		t.Fatalf("expected escaped html body, got %q", rendered.BodyHTML)
+	}
+	if strings.Contains(rendered.BodyText, "<no value>") {
+		t.Fatalf("expected optional fields to be omitted, got %q", rendered.BodyText)
+	}
+
+	if _, err := templates.Render(MailTemplateNotification, models.MailLocaleDE, map[string]any{}); err == nil {
+		t.Fatal("expected error for missing title")
+	}
+	if _, err := templates.Render("unknown", models.MailLocaleDE, nil); err == nil {
+		t.Fatal("expected error for unknown template")
+	}
+}
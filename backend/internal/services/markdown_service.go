@@ -12,8 +12,9 @@ import (
 
 // MarkdownService rendert Markdown zu sanitisiertem HTML.
 type MarkdownService struct {
-	md        goldmark.Markdown
-	sanitizer *bluemonday.Policy
+	md               goldmark.Markdown
+	sanitizer        *bluemonday.Policy
+	commentSanitizer *bluemonday.Policy
 }
 
 // NewMarkdownService erstellt einen MarkdownService mit sicheren Standardwerten.
@@ -32,11 +33,26 @@ func NewMarkdownService() *MarkdownService {
 		),
 	)
 	return &MarkdownService{
-		md:        md,
-		sanitizer: bluemonday.UGCPolicy(),
+		md:               md,
+		sanitizer:        bluemonday.UGCPolicy(),
+		commentSanitizer: newCommentMarkdownPolicy(),
 	}
 }
 
+// newCommentMarkdownPolicy erlaubt für Kommentare nur eine kleine Teilmenge: Absätze,
+// Hervorhebungen, Code, Zitate, Listen und Links (nofollow, neuer Tab). Überschriften,
+// Bilder und Tabellen werden auf ihren Text reduziert.
+func newCommentMarkdownPolicy() *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("p", "br", "strong", "em", "del", "code", "pre", "blockquote", "ul", "ol", "li", "hr")
+	policy.AllowAttrs("href").OnElements("a")
+	policy.AllowStandardURLs()
+	policy.AllowRelativeURLs(true)
+	policy.RequireNoFollowOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	return policy
+}
+
 // RenderMarkdown konvertiert Markdown-Text zu sanitisiertem HTML.
 // Leerer Input gibt leeren String zurück ohne Fehler.
 // Der Sanitizer entfernt gefährliche Tags wie <script>, <iframe> und Event-Handler.
@@ -51,3 +67,17 @@ func (s *MarkdownService) RenderMarkdown(input string) (string, error) {
 	safe := s.sanitizer.SanitizeBytes(buf.Bytes())
 	return string(safe), nil
 }
+
+// RenderCommentMarkdown rendert einen Kommentar mit der eingeschränkten Kommentar-Policy.
+// Leerer Input gibt leeren String zurück ohne Fehler.
+func (s *MarkdownService) RenderCommentMarkdown(input string) (string, error) {
+	if input == "" {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := s.md.Convert([]byte(input), &buf); err != nil {
+		return "", err
+	}
+	safe := s.commentSanitizer.SanitizeBytes(buf.Bytes())
+	return string(safe), nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"team4s.v3/backend/internal/blobstore"
+	"team4s.v3/backend/internal/models"
+	"team4s.v3/backend/internal/repository"
+)
+
+// MediaBucketMigrationStore ist der Ausschnitt des MediaRepository, den die Bucket-Migration benötigt.
+type MediaBucketMigrationStore interface {
+	ListStoredMediaPaths(ctx context.Context) ([]string, error)
+	RewriteStoredMediaPath(ctx context.Context, oldPath string, newPath string) (int64, error)
+}
+
+// MediaBucketMigrationOptions steuert einen Lauf von "migrate media-to-bucket".
+type MediaBucketMigrationOptions struct {
+	// DryRun zählt nur, was kopiert und umgeschrieben würde.
+	DryRun bool
+	// DeleteLocal entfernt lokale Dateien, sobald ihr Inhalt im Bucket liegt.
+	DeleteLocal bool
+}
+
+// MediaBucketMigrationService kopiert die in der Datenbank referenzierten Mediendateien aus dem
+// lokalen Medienverzeichnis in einen Bucket. Pfade unterhalb des Medienstamms bleiben unverändert,
+// da sich ihr Schlüssel aus dem Pfad ergibt. Altpfade außerhalb davon werden wie beim Lesen über
+// ihren Dateinamen aufgelöst und auf storageDir/{dateiname} umgeschrieben. Läufe sind wiederholbar.
+type MediaBucketMigrationService struct {
+	store      MediaBucketMigrationStore
+	storageDir string
+	bucket     blobstore.BlobStore
+}
+
+// NewMediaBucketMigrationService erstellt einen neuen MediaBucketMigrationService.
+func NewMediaBucketMigrationService(store MediaBucketMigrationStore, storageDir string, bucket blobstore.BlobStore) *MediaBucketMigrationService {
+	dir := strings.TrimSpace(storageDir)
+	if dir == "" {
+		dir = "./storage/media"
+	}
+	return &MediaBucketMigrationService{store: store, storageDir: dir, bucket: bucket}
+}
+
+// mediaBucketMigrationItem beschreibt, wohin ein gespeicherter Pfad migriert wird.
+type mediaBucketMigrationItem struct {
+	rawPath  string
+	key      string
+	diskPath string
+	newPath  string
+}
+
+// Migrate führt einen Migrationslauf aus. Fehler der Ablagen brechen den Lauf ab; fehlende Dateien
+// und Konflikte werden im Bericht gesammelt.
+func (s *MediaBucketMigrationService) Migrate(ctx context.Context, opts MediaBucketMigrationOptions) (*models.MediaBucketMigrationReport, error) {
+	paths, err := s.store.ListStoredMediaPaths(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("media bucket migration: list paths: %w", err)
+	}
+
+	report := &models.MediaBucketMigrationReport{
+		MissingPaths:  make([]string, 0),
+		ConflictPaths: make([]string, 0),
+	}
+	for _, raw := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.ScannedPaths++
+
+		item, ok := s.planItem(raw)
+		if !ok {
+			report.ConflictPaths = append(report.ConflictPaths, raw)
+			continue
+		}
+
+		outcome, size, err := s.copyItem(ctx, item, opts)
+		if err != nil {
+			return nil, fmt.Errorf("media bucket migration: %q: %w", raw, err)
+		}
+		switch outcome {
+		case mediaBucketMissing:
+			report.MissingPaths = append(report.MissingPaths, raw)
+			continue
+		case mediaBucketConflict:
+			report.ConflictPaths = append(report.ConflictPaths, raw)
+			continue
+		case mediaBucketCopied:
+			report.CopiedObjects++
+			report.CopiedBytes += size
+		case mediaBucketExisting:
+			report.AlreadyInBucket++
+		}
+
+		if item.newPath != item.rawPath {
+			if !opts.DryRun {
+				rows, err := s.store.RewriteStoredMediaPath(ctx, item.rawPath, item.newPath)
+				if errors.Is(err, repository.ErrConflict) {
+					report.ConflictPaths = append(report.ConflictPaths, raw)
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("media bucket migration: %w", err)
+				}
+				report.RewrittenRows += rows
+			}
+			report.RewrittenPaths++
+		}
+
+		if opts.DeleteLocal && !opts.DryRun {
+			if err := os.Remove(item.diskPath); err == nil {
+				report.DeletedLocal++
+			} else if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("media bucket migration: delete local %q: %w", item.diskPath, err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// planItem bestimmt Schlüssel, lokale Quelle und künftigen DB-Pfad. Pfade unterhalb des
+// Medienstamms (auch "/media/…") behalten ihren Pfad; alle anderen fallen wie
+// resolveReadableStoragePath im Repository auf storageDir/{dateiname} zurück.
+func (s *MediaBucketMigrationService) planItem(raw string) (mediaBucketMigrationItem, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if key, ok := blobstore.KeyFromPath(s.storageDir, trimmed); ok {
+		return mediaBucketMigrationItem{
+			rawPath:  raw,
+			key:      key,
+			diskPath: blobstore.PathForKey(s.storageDir, key),
+			newPath:  raw,
+		}, true
+	}
+
+	key, ok := blobstore.CleanKey(path.Base(strings.ReplaceAll(trimmed, "\\", "/")))
+	if !ok {
+		return mediaBucketMigrationItem{}, false
+	}
+	newPath := blobstore.PathForKey(s.storageDir, key)
+	diskPath := newPath
+	if info, err := os.Stat(trimmed); err == nil && info.Mode().IsRegular() {
+		diskPath = trimmed
+	}
+	return mediaBucketMigrationItem{rawPath: raw, key: key, diskPath: diskPath, newPath: newPath}, true
+}
+
+type mediaBucketOutcome int
+
+const (
+	mediaBucketCopied mediaBucketOutcome = iota
+	mediaBucketExisting
+	mediaBucketMissing
+	mediaBucketConflict
+)
+
+// copyItem lädt die lokale Datei in den Bucket, sofern dort nicht schon ein Objekt mit gleicher
+// SHA-256-Prüfsumme liegt; erst dann darf -delete-local die Datei entfernen. Ein abweichendes
+// Objekt unter dem Schlüssel eines umzuschreibenden Altpfads ist ein Konflikt.
+// size ist die Größe der (zu) kopierten Datei.
+func (s *MediaBucketMigrationService) copyItem(ctx context.Context, item mediaBucketMigrationItem, opts MediaBucketMigrationOptions) (mediaBucketOutcome, int64, error) {
+	remote, err := s.bucket.Stat(ctx, item.key)
+	if err != nil && !errors.Is(err, blobstore.ErrNotExist) {
+		return 0, 0, err
+	}
+	inBucket := err == nil
+
+	local, err := os.Stat(item.diskPath)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, 0, err
+	}
+	hasLocal := err == nil && local.Mode().IsRegular()
+
+	sameContent := false
+	if hasLocal && inBucket && remote.Size == local.Size() {
+		if sameContent, err = s.sameContent(ctx, item.key, item.diskPath); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	switch {
+	case !hasLocal && inBucket:
+		return mediaBucketExisting, 0, nil
+	case !hasLocal:
+		return mediaBucketMissing, 0, nil
+	case sameContent:
+		return mediaBucketExisting, 0, nil
+	case inBucket && item.newPath != item.rawPath:
+		return mediaBucketConflict, 0, nil
+	}
+	if opts.DryRun {
+		return mediaBucketCopied, local.Size(), nil
+	}
+
+	file, err := os.Open(item.diskPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+	if err := s.bucket.Put(ctx, item.key, file, local.Size(), mime.TypeByExtension(path.Ext(item.key))); err != nil {
+		return 0, 0, err
+	}
+	return mediaBucketCopied, local.Size(), nil
+}
+
+// sameContent vergleicht die SHA-256-Prüfsummen des Objekts key und der lokalen Datei diskPath.
+func (s *MediaBucketMigrationService) sameContent(ctx context.Context, key string, diskPath string) (bool, error) {
+	object, err := s.bucket.Open(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	defer object.Close()
+	remoteSum, err := sha256Sum(object)
+	if err != nil {
+		return false, fmt.Errorf("hash bucket object %s: %w", key, err)
+	}
+
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	localSum, err := sha256Sum(file)
+	if err != nil {
+		return false, fmt.Errorf("hash local file %s: %w", diskPath, err)
+	}
+	return bytes.Equal(remoteSum, localSum), nil
+}
+
+func sha256Sum(r io.Reader) ([]byte, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"team4s.v3/backend/internal/blobstore"
+)
+
+type mediaBucketMigrationStoreStub struct {
+	paths    []string
+	rewrites map[string]string
+}
+
+func (s *mediaBucketMigrationStoreStub) ListStoredMediaPaths(_ context.Context) ([]string, error) {
+	return s.paths, nil
+}
+
+func (s *mediaBucketMigrationStoreStub) RewriteStoredMediaPath(_ context.Context, oldPath string, newPath string) (int64, error) {
+	s.rewrites[oldPath] = newPath
+	for i, path := range s.paths {
+		if path == oldPath {
+			s.paths[i] = newPath
+		}
+	}
+	return 1, nil
+}
+
+func writeMediaBucketTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestMediaBucketMigrationCopiesFilesAndRewritesLegacyPaths(t *testing.T) {
+	root := t.TempDir()
+	legacyDir := t.TempDir()
+	bucket := blobstore.NewLocalStore(t.TempDir())
+	ctx := context.Background()
+
+	blobPath := filepath.Join(root, "blobs", "ab", "ab12.png")
+	writeMediaBucketTestFile(t, blobPath, "blob")
+	writeMediaBucketTestFile(t, filepath.Join(root, "trickplay", "variant_7", "1", "thumbnails.vtt"), "WEBVTT")
+	legacyPath := filepath.Join(legacyDir, "logo_1_abc.png")
+	writeMediaBucketTestFile(t, legacyPath, "legacy")
+
+	store := &mediaBucketMigrationStoreStub{
+		paths: []string{
+			blobPath,
+			"/media/trickplay/variant_7/1/thumbnails.vtt",
+			legacyPath,
+			filepath.Join(root, "missing.png"),
+		},
+		rewrites: make(map[string]string),
+	}
+	service := NewMediaBucketMigrationService(store, root, bucket)
+
+	dryRun, err := service.Migrate(ctx, MediaBucketMigrationOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if dryRun.CopiedObjects != 3 || dryRun.RewrittenPaths != 1 || len(store.rewrites) != 0 {
+		t.Fatalf("unexpected dry run report %+v rewrites=%v", dryRun, store.rewrites)
+	}
+	if _, err := bucket.Stat(ctx, "blobs/ab/ab12.png"); !errors.Is(err, blobstore.ErrNotExist) {
+		t.Fatalf("dry run must not copy, got %v", err)
+	}
+
+	report, err := service.Migrate(ctx, MediaBucketMigrationOptions{DeleteLocal: true})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if report.CopiedObjects != 3 || report.CopiedBytes != 16 || report.DeletedLocal != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.MissingPaths) != 1 || report.MissingPaths[0] != filepath.Join(root, "missing.png") {
+		t.Fatalf("expected missing file to be reported, got %v", report.MissingPaths)
+	}
+	for _, key := range []string{"blobs/ab/ab12.png", "trickplay/variant_7/1/thumbnails.vtt", "logo_1_abc.png"} {
+		if _, err := bucket.Stat(ctx, key); err != nil {
+			t.Fatalf("expected %s in bucket: %v", key, err)
+		}
+	}
+	if got := store.rewrites[legacyPath]; got != filepath.Join(root, "logo_1_abc.png") {
+		t.Fatalf("expected legacy path to be rewritten below the storage dir, got %q", got)
+	}
+	if _, err := os.Stat(blobPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected local copy to be deleted, got %v", err)
+	}
+
+	again, err := service.Migrate(ctx, MediaBucketMigrationOptions{})
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if again.CopiedObjects != 0 || again.AlreadyInBucket != 3 || again.RewrittenPaths != 0 {
+		t.Fatalf("expected second run to be a no-op, got %+v", again)
+	}
+}
+
+func TestMediaBucketMigrationComparesChecksumsBeforeDeletingLocalFiles(t *testing.T) {
+	root := t.TempDir()
+	legacyDir := t.TempDir()
+	bucketRoot := t.TempDir()
+	bucket := blobstore.NewLocalStore(bucketRoot)
+	ctx := context.Background()
+
+	blobPath := filepath.Join(root, "blobs", "ab", "ab12.png")
+	writeMediaBucketTestFile(t, blobPath, "local")
+	writeMediaBucketTestFile(t, filepath.Join(bucketRoot, "blobs", "ab", "ab12.png"), "stale")
+	legacyPath := filepath.Join(legacyDir, "logo_1_abc.png")
+	writeMediaBucketTestFile(t, legacyPath, "legacy")
+	writeMediaBucketTestFile(t, filepath.Join(bucketRoot, "logo_1_abc.png"), "others")
+
+	store := &mediaBucketMigrationStoreStub{
+		paths:    []string{blobPath, legacyPath},
+		rewrites: make(map[string]string),
+	}
+	report, err := NewMediaBucketMigrationService(store, root, bucket).Migrate(ctx, MediaBucketMigrationOptions{DeleteLocal: true})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if report.CopiedObjects != 1 || report.AlreadyInBucket != 0 || report.DeletedLocal != 1 {
+		t.Fatalf("expected same-size object with other content to be re-uploaded, got %+v", report)
+	}
+	object, err := bucket.Open(ctx, "blobs/ab/ab12.png")
+	if err != nil {
+		t.Fatalf("open bucket object: %v", err)
+	}
+	defer object.Close()
+	if content, _ := io.ReadAll(object); string(content) != "local" {
+		t.Fatalf("expected bucket object to hold the local content, got %q", content)
+	}
+
+	if len(report.ConflictPaths) != 1 || report.ConflictPaths[0] != legacyPath {
+		t.Fatalf("expected legacy path with other content to conflict, got %v", report.ConflictPaths)
+	}
+	if _, err := os.Stat(legacyPath); err != nil {
+		t.Fatalf("expected conflicting local file to be kept: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// MediaDedupeStore ist der Ausschnitt des MediaRepository, den der Dedupe-Report benötigt.
+type MediaDedupeStore interface {
+	ListMediaAssetFiles(ctx context.Context) ([]models.MediaAssetFile, error)
+	RegisterMediaBlob(ctx context.Context, blob models.MediaAssetCreateInput, mediaAssetIDs []int64) (int, error)
+}
+
+// MediaDedupeService hasht die Dateien bestehender Media-Assets und meldet identische Inhalte.
+// Genutzt für den CLI-Subbefehl "media-dedupe-report" in cmd/migrate.
+type MediaDedupeService struct {
+	store MediaDedupeStore
+}
+
+// NewMediaDedupeService erstellt einen neuen MediaDedupeService.
+func NewMediaDedupeService(store MediaDedupeStore) *MediaDedupeService {
+	return &MediaDedupeService{store: store}
+}
+
+type mediaDedupeContent struct {
+	sizeBytes int64
+	paths     []string
+	assetIDs  map[string][]int64
+	mimeType  string
+	hasBlob   bool
+}
+
+// Report liest jede referenzierte Datei einmal und gruppiert nach SHA-256. Dateien werden weder
+// verschoben noch gelöscht. Mit register werden Inhalte ohne Blob-Eintrag unter ihrem kanonischen
+// Pfad (kleinste Asset-ID) als Blob erfasst und die Assets an genau diesem Pfad verknüpft.
+func (s *MediaDedupeService) Report(ctx context.Context, register bool) (*models.MediaDedupeReport, error) {
+	files, err := s.store.ListMediaAssetFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("media dedupe: list files: %w", err)
+	}
+
+	report := &models.MediaDedupeReport{
+		MissingAssetIDs: make([]int64, 0),
+		DuplicateGroups: make([]models.MediaDedupeGroup, 0),
+	}
+	hashByPath := make(map[string]string)
+	contents := make(map[string]*mediaDedupeContent)
+	order := make([]string, 0)
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.ScannedAssets++
+		path := strings.TrimSpace(file.StoragePath)
+
+		sha, hashed := hashByPath[path]
+		if !hashed {
+			var size int64
+			sha, size, err = hashMediaFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				report.MissingAssetIDs = append(report.MissingAssetIDs, file.MediaAssetID)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("media dedupe: hash asset %d: %w", file.MediaAssetID, err)
+			}
+			hashByPath[path] = sha
+			report.HashedFiles++
+
+			content, ok := contents[sha]
+			if !ok {
+				content = &mediaDedupeContent{sizeBytes: size, assetIDs: make(map[string][]int64), mimeType: file.MimeType}
+				contents[sha] = content
+				order = append(order, sha)
+			}
+			content.paths = append(content.paths, path)
+		}
+
+		content := contents[sha]
+		content.assetIDs[path] = append(content.assetIDs[path], file.MediaAssetID)
+		if file.BlobID != nil {
+			content.hasBlob = true
+		}
+	}
+
+	report.UniqueContents = len(contents)
+	for _, sha := range order {
+		content := contents[sha]
+		if len(content.paths) > 1 {
+			group := models.MediaDedupeGroup{
+				SHA256:           sha,
+				SizeBytes:        content.sizeBytes,
+				Paths:            content.paths,
+				MediaAssetIDs:    make([]int64, 0),
+				ReclaimableBytes: int64(len(content.paths)-1) * content.sizeBytes,
+			}
+			for _, path := range content.paths {
+				group.MediaAssetIDs = append(group.MediaAssetIDs, content.assetIDs[path]...)
+			}
+			sort.Slice(group.MediaAssetIDs, func(i, j int) bool { return group.MediaAssetIDs[i] < group.MediaAssetIDs[j] })
+			report.DuplicateGroups = append(report.DuplicateGroups, group)
+			report.ReclaimableBytes += group.ReclaimableBytes
+		}
+
+		if !register || content.hasBlob {
+			continue
+		}
+		// Der erste Pfad gehört zur kleinsten Asset-ID, da die Dateien nach ID sortiert geliefert werden.
+		canonical := content.paths[0]
+		linked, err := s.store.RegisterMediaBlob(ctx, models.MediaAssetCreateInput{
+			StoragePath:   canonical,
+			MimeType:      content.mimeType,
+			SizeBytes:     content.sizeBytes,
+			ContentSHA256: sha,
+		}, content.assetIDs[canonical])
+		if err != nil {
+			return nil, fmt.Errorf("media dedupe: register blob %s: %w", sha, err)
+		}
+		report.RegisteredBlobs++
+		report.LinkedMediaAssets += linked
+	}
+
+	return report, nil
+}
+
+// hashMediaFile liefert SHA-256 (hex) und Größe einer Datei, ohne sie vollständig in den Speicher zu laden.
+func hashMediaFile(path string) (string, int64, error) {
+	if path == "" {
+		return "", 0, fs.ErrNotExist
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err != nil {
+		return "", 0, err
+	} else if !info.Mode().IsRegular() {
+		return "", 0, fs.ErrNotExist
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
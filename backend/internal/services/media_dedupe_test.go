@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
+
+type mediaDedupeStoreStub struct {
+	files      []models.MediaAssetFile
+	registered []models.MediaAssetCreateInput
+	linkedIDs  [][]int64
+}
+
+func (s *mediaDedupeStoreStub) ListMediaAssetFiles(_ context.Context) ([]models.MediaAssetFile, error) {
+	return s.files, nil
+}
+
+func (s *mediaDedupeStoreStub) RegisterMediaBlob(_ context.Context, blob models.MediaAssetCreateInput, mediaAssetIDs []int64) (int, error) {
+	s.registered = append(s.registered, blob)
+	s.linkedIDs = append(s.linkedIDs, mediaAssetIDs)
+	return len(mediaAssetIDs), nil
+}
+
+func mediaDedupeTestPNG(t *testing.T, shade uint8) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{R: shade, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSaveUploadStoresIdenticalContentOnce(t *testing.T) {
+	dir := t.TempDir()
+	service := NewMediaService(dir, "http://localhost:8092")
+	data := mediaDedupeTestPNG(t, 200)
+
+	first, err := service.SaveUpload(context.Background(), models.MediaKindImage, "a.png", data)
+	if err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	second, err := service.SaveUpload(context.Background(), models.MediaKindImage, "b.png", data)
+	if err != nil {
+		t.Fatalf("second upload: %v", err)
+	}
+
+	if first.Deduplicated || !second.Deduplicated {
+		t.Fatalf("expected only the second upload to be deduplicated, got %v/%v", first.Deduplicated, second.Deduplicated)
+	}
+	if first.CreateInput.StoragePath != second.CreateInput.StoragePath || first.CreateInput.ContentSHA256 != second.CreateInput.ContentSHA256 {
+		t.Fatalf("expected shared blob, got %q and %q", first.CreateInput.StoragePath, second.CreateInput.StoragePath)
+	}
+	sha := first.CreateInput.ContentSHA256
+	if want := filepath.Join(dir, "blobs", sha[:2], sha+".png"); first.CreateInput.StoragePath != want {
+		t.Fatalf("expected content-addressed path %q, got %q", want, first.CreateInput.StoragePath)
+	}
+	if !strings.HasSuffix(first.CreateInput.PublicURL, "/api/v1/media/files/"+sha+".png") {
+		t.Fatalf("unexpected public url %q", first.CreateInput.PublicURL)
+	}
+
+	other, err := service.SaveUpload(context.Background(), models.MediaKindImage, "a.png", mediaDedupeTestPNG(t, 10))
+	if err != nil {
+		t.Fatalf("third upload: %v", err)
+	}
+	if other.Deduplicated || other.CreateInput.StoragePath == first.CreateInput.StoragePath {
+		t.Fatalf("expected different content to get its own blob, got %+v", other.CreateInput)
+	}
+}
+
+func TestSaveUploadEnsureStoredRewritesReleasedBlob(t *testing.T) {
+	service := NewMediaService(t.TempDir(), "http://localhost:8092")
+	data := mediaDedupeTestPNG(t, 120)
+
+	first, err := service.SaveUpload(context.Background(), models.MediaKindImage, "a.png", data)
+	if err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	second, err := service.SaveUpload(context.Background(), models.MediaKindImage, "b.png", data)
+	if err != nil || !second.Deduplicated {
+		t.Fatalf("expected deduplicated second upload, got %+v (%v)", second, err)
+	}
+
+	// Das erste Asset wird gelöscht und gibt den Blob frei, bevor das zweite angelegt ist.
+	if err := service.RemoveStoredFile(context.Background(), first.CreateInput.StoragePath); err != nil {
+		t.Fatalf("remove blob: %v", err)
+	}
+	if err := second.EnsureStored(context.Background()); err != nil {
+		t.Fatalf("ensure stored: %v", err)
+	}
+	stored, err := os.ReadFile(second.CreateInput.StoragePath)
+	if err != nil || !bytes.Equal(stored, data) {
+		t.Fatalf("expected blob to be written again, got %d bytes (%v)", len(stored), err)
+	}
+}
+
+func TestMediaDedupeReportGroupsIdenticalFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+	logoA := write("logo_a.png", "same-bytes")
+	logoB := write("logo_b.png", "same-bytes")
+	unique := write("banner.png", "other")
+	blobID := int64(9)
+
+	store := &mediaDedupeStoreStub{files: []models.MediaAssetFile{
+		{MediaAssetID: 1, StoragePath: logoA, MimeType: "image/png"},
+		{MediaAssetID: 2, StoragePath: logoB, MimeType: "image/png"},
+		{MediaAssetID: 3, StoragePath: logoA, MimeType: "image/png"},
+		{MediaAssetID: 4, StoragePath: unique, MimeType: "image/png", BlobID: &blobID},
+		{MediaAssetID: 5, StoragePath: filepath.Join(dir, "missing.png"), MimeType: "image/png"},
+	}}
+
+	report, err := NewMediaDedupeService(store).Report(context.Background(), false)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.ScannedAssets != 5 || report.HashedFiles != 3 || report.UniqueContents != 2 {
+		t.Fatalf("unexpected counters %+v", report)
+	}
+	if len(report.MissingAssetIDs) != 1 || report.MissingAssetIDs[0] != 5 {
+		t.Fatalf("expected asset 5 to be missing, got %v", report.MissingAssetIDs)
+	}
+	if len(report.DuplicateGroups) != 1 || report.ReclaimableBytes != int64(len("same-bytes")) {
+		t.Fatalf("unexpected duplicate groups %+v", report.DuplicateGroups)
+	}
+	group := report.DuplicateGroups[0]
+	if len(group.Paths) != 2 || group.Paths[0] != logoA || len(group.MediaAssetIDs) != 3 {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	if len(store.registered) != 0 {
+		t.Fatalf("expected no registration without -register")
+	}
+
+	report, err = NewMediaDedupeService(store).Report(context.Background(), true)
+	if err != nil {
+		t.Fatalf("report with register: %v", err)
+	}
+	if report.RegisteredBlobs != 1 || len(store.registered) != 1 || store.registered[0].StoragePath != logoA {
+		t.Fatalf("expected canonical path of the duplicate to be registered, got %+v", store.registered)
+	}
+	if ids := store.linkedIDs[0]; len(ids) != 2 || ids[0] != 1 || ids[1] != 3 || report.LinkedMediaAssets != 2 {
+		t.Fatalf("expected assets at the canonical path to be linked, got %v", ids)
+	}
+}
@@ -2,14 +2,16 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
-	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
@@ -17,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/models"
 
 	"github.com/gabriel-vasile/mimetype"
@@ -27,6 +30,20 @@ import (
 type MediaSaveResult struct {
 	CreateInput  models.MediaAssetCreateInput
 	GIFLargeHint bool
+	// Deduplicated ist true, wenn der Inhalt bereits als Blob vorlag und nicht neu geschrieben wurde.
+	Deduplicated bool
+
+	ensureStored func(ctx context.Context) error
+}
+
+// EnsureStored schreibt den Blob erneut, falls er seit SaveUpload entfernt wurde. Aufrufer
+// sperren vorher die media_blobs-Zeile in der Asset-Transaktion; erst dann ist die Prüfung
+// verlässlich gegenüber einem parallelen ReleaseMediaBlobFile.
+func (r *MediaSaveResult) EnsureStored(ctx context.Context) error {
+	if r == nil || r.ensureStored == nil {
+		return nil
+	}
+	return r.ensureStored(ctx)
 }
 
 // MediaVariantSaveResult beschreibt eine gespeicherte Datei-Variante zu einem
@@ -51,10 +68,15 @@ func (e *MediaValidationError) Error() string {
 	return e.Message
 }
 
-// MediaService verwaltet das Speichern und Validieren von Medien-Uploads auf dem Dateisystem.
+// mediaBlobDir ist das Unterverzeichnis für inhaltsadressierte Uploads.
+const mediaBlobDir = "blobs"
+
+// MediaService verwaltet das Speichern und Validieren von Medien-Uploads. Dateien landen im
+// BlobStore; StoragePath bleibt unabhängig von der Ablage storageDir/{key}.
 type MediaService struct {
 	storageDir    string
 	publicBaseURL string
+	store         blobstore.BlobStore
 }
 
 type ReleaseThemeVideoStorageContext struct {
@@ -78,12 +100,80 @@ func NewMediaService(storageDir, publicBaseURL string) *MediaService {
 	return &MediaService{
 		storageDir:    dir,
 		publicBaseURL: baseURL,
+		store:         blobstore.NewLocalStore(dir),
 	}
 }
 
+// WithBlobStore setzt die Ablage für Uploads. Ohne Aufruf wird direkt in storageDir geschrieben.
+func (s *MediaService) WithBlobStore(store blobstore.BlobStore) *MediaService {
+	if store != nil {
+		s.store = store
+	}
+	return s
+}
+
+// BlobStore liefert die Ablage, in die der Service schreibt.
+func (s *MediaService) BlobStore() blobstore.BlobStore {
+	return s.store
+}
+
+// StorageKey bildet einen gespeicherten Dateipfad auf den Schlüssel im BlobStore ab.
+func (s *MediaService) StorageKey(storagePath string) (string, bool) {
+	return blobstore.KeyFromPath(s.storageDir, storagePath)
+}
+
+// StoreFile schreibt eine abgeleitete Datei (z.B. ein Thumbnail) unter storagePath in die Ablage.
+func (s *MediaService) StoreFile(ctx context.Context, storagePath string, data []byte, contentType string) error {
+	key, ok := s.StorageKey(storagePath)
+	if !ok {
+		return fmt.Errorf("media path %q is outside the media storage", storagePath)
+	}
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return fmt.Errorf("write media file: %w", err)
+	}
+	return nil
+}
+
+// StoredFileExists meldet, ob unter storagePath eine Datei in der Ablage liegt.
+func (s *MediaService) StoredFileExists(ctx context.Context, storagePath string) bool {
+	key, ok := s.StorageKey(storagePath)
+	if !ok {
+		return false
+	}
+	_, err := s.store.Stat(ctx, key)
+	return err == nil
+}
+
+// RemoveStoredFile löscht die Datei unter storagePath. Fehlende Dateien sind kein Fehler; Pfade
+// außerhalb des Medienstamms (Altbestand) werden direkt im Dateisystem entfernt.
+func (s *MediaService) RemoveStoredFile(ctx context.Context, storagePath string) error {
+	trimmed := strings.TrimSpace(storagePath)
+	if trimmed == "" {
+		return nil
+	}
+	key, ok := s.StorageKey(trimmed)
+	if !ok {
+		if err := os.Remove(trimmed); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return s.store.Delete(ctx, key)
+}
+
+// putFile legt data unter key ab und liefert den zugehörigen StoragePath.
+func (s *MediaService) putFile(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return "", err
+	}
+	return blobstore.PathForKey(s.storageDir, key), nil
+}
+
 // SaveUpload validiert und speichert einen Medien-Upload für die angegebene MediaKind.
+// Die Datei wird inhaltsadressiert unter blobs/{sha[0:2]}/{sha256}.{ext} abgelegt; identische
+// Uploads teilen sich dieselbe Datei (Referenzen über media_assets.blob_id).
 // Gibt ein MediaSaveResult mit Dateiinformationen zurück oder einen MediaValidationError bei ungültigen Daten.
-func (s *MediaService) SaveUpload(kind models.MediaKind, originalName string, data []byte) (*MediaSaveResult, error) {
+func (s *MediaService) SaveUpload(ctx context.Context, kind models.MediaKind, originalName string, data []byte) (*MediaSaveResult, error) {
 	if len(data) == 0 {
 		return nil, &MediaValidationError{Message: "datei ist leer"}
 	}
@@ -111,28 +201,35 @@ func (s *MediaService) SaveUpload(kind models.MediaKind, originalName string, da
 
 	width, height := decodeImageDimensions(data)
 	ext := extensionFromMime(detectedMime)
-	filename := buildFilename(kind, ext)
-	absolutePath := filepath.Join(s.storageDir, filename)
+	sum := sha256.Sum256(data)
+	contentHash := hex.EncodeToString(sum[:])
+	filename := contentHash + "." + ext
+	key := mediaBlobDir + "/" + contentHash[:2] + "/" + filename
 
-	if err := os.MkdirAll(filepath.Dir(absolutePath), 0o755); err != nil {
-		return nil, fmt.Errorf("create media directory: %w", err)
-	}
-	if err := os.WriteFile(absolutePath, data, fs.FileMode(0o644)); err != nil {
-		return nil, fmt.Errorf("write media file: %w", err)
+	existed, err := s.putContentAddressed(ctx, key, data, detectedMime)
+	if err != nil {
+		return nil, err
 	}
+	absolutePath := blobstore.PathForKey(s.storageDir, key)
 
 	publicURL := fmt.Sprintf("%s/api/v1/media/files/%s", s.publicBaseURL, url.PathEscape(filename))
 
 	result := &MediaSaveResult{
 		CreateInput: models.MediaAssetCreateInput{
-			Kind:        kind,
-			Filename:    filename,
-			StoragePath: absolutePath,
-			PublicURL:   publicURL,
-			MimeType:    detectedMime,
-			SizeBytes:   int64(len(data)),
-			Width:       width,
-			Height:      height,
+			Kind:          kind,
+			Filename:      filename,
+			StoragePath:   absolutePath,
+			PublicURL:     publicURL,
+			MimeType:      detectedMime,
+			SizeBytes:     int64(len(data)),
+			Width:         width,
+			Height:        height,
+			ContentSHA256: contentHash,
+		},
+		Deduplicated: existed,
+		ensureStored: func(ctx context.Context) error {
+			_, err := s.putContentAddressed(ctx, key, data, detectedMime)
+			return err
 		},
 	}
 	if kind == models.MediaKindBanner && detectedMime == "image/gif" && len(data) > 4*1024*1024 {
@@ -146,7 +243,7 @@ func (s *MediaService) SaveUpload(kind models.MediaKind, originalName string, da
 // SaveUploadSourceOriginal validiert und speichert die unbearbeitete Quelle zu
 // einem Fansub-Branding-Upload. Die Datei wird nicht öffentlich verlinkt, aber
 // als editierbare Quelle über geschützte Admin-/Leader-Antworten durchgereicht.
-func (s *MediaService) SaveUploadSourceOriginal(kind models.MediaKind, originalName string, data []byte) (*MediaVariantSaveResult, error) {
+func (s *MediaService) SaveUploadSourceOriginal(ctx context.Context, kind models.MediaKind, originalName string, data []byte) (*MediaVariantSaveResult, error) {
 	if len(data) == 0 {
 		return nil, &MediaValidationError{Message: "datei ist leer"}
 	}
@@ -170,12 +267,8 @@ func (s *MediaService) SaveUploadSourceOriginal(kind models.MediaKind, originalN
 	width, height := decodeImageDimensions(data)
 	ext := extensionFromMime(detectedMime)
 	filename := buildFilename(models.MediaKind(string(kind)+"_source"), ext)
-	absolutePath := filepath.Join(s.storageDir, filename)
-
-	if err := os.MkdirAll(filepath.Dir(absolutePath), 0o755); err != nil {
-		return nil, fmt.Errorf("create media directory: %w", err)
-	}
-	if err := os.WriteFile(absolutePath, data, fs.FileMode(0o644)); err != nil {
+	absolutePath, err := s.putFile(ctx, filename, data, detectedMime)
+	if err != nil {
 		return nil, fmt.Errorf("write source media file: %w", err)
 	}
 
@@ -192,7 +285,7 @@ func (s *MediaService) SaveUploadSourceOriginal(kind models.MediaKind, originalN
 }
 
 // SaveReleaseThemeVideoUpload validiert und speichert ein release-spezifisches Theme-Video fuer OP/ED-Assets.
-func (s *MediaService) SaveReleaseThemeVideoUpload(ctx ReleaseThemeVideoStorageContext, originalName string, data []byte) (*MediaSaveResult, error) {
+func (s *MediaService) SaveReleaseThemeVideoUpload(ctx context.Context, storage ReleaseThemeVideoStorageContext, originalName string, data []byte) (*MediaSaveResult, error) {
 	if len(data) == 0 {
 		return nil, &MediaValidationError{Message: "datei ist leer"}
 	}
@@ -214,11 +307,9 @@ func (s *MediaService) SaveReleaseThemeVideoUpload(ctx ReleaseThemeVideoStorageC
 	}
 
 	filename := buildFilename(models.MediaKindThemeVideo, ext)
-	absolutePath := filepath.Join(s.storageDir, s.releaseThemeVideoRelativeDir(ctx), filename)
-	if err := os.MkdirAll(filepath.Dir(absolutePath), 0o755); err != nil {
-		return nil, fmt.Errorf("create media directory: %w", err)
-	}
-	if err := os.WriteFile(absolutePath, data, fs.FileMode(0o644)); err != nil {
+	key := filepath.ToSlash(filepath.Join(s.releaseThemeVideoRelativeDir(storage), filename))
+	absolutePath, err := s.putFile(ctx, key, data, detectedMime)
+	if err != nil {
 		return nil, fmt.Errorf("write media file: %w", err)
 	}
 
@@ -265,7 +356,7 @@ type SegmentAssetContext struct {
 // Fallback ohne stabile Quelle:
 // segments/local/anime_{animeId}/group_{groupId}/{version}/{segmentTypeLower}/{sanitizedFilename}
 // Erlaubte Formate: mp4, webm, mkv, mp3, aac, flac, ogg, opus, m4a. Groessenlimit: 150 MB.
-func (s *MediaService) SaveSegmentAsset(ctx SegmentAssetContext, originalName string, data []byte) (*MediaSaveResult, error) {
+func (s *MediaService) SaveSegmentAsset(ctx context.Context, segment SegmentAssetContext, originalName string, data []byte) (*MediaSaveResult, error) {
 	if len(data) == 0 {
 		return nil, &MediaValidationError{Message: "datei ist leer"}
 	}
@@ -291,34 +382,30 @@ func (s *MediaService) SaveSegmentAsset(ctx SegmentAssetContext, originalName st
 	}
 
 	sanitized := sanitizeSegmentFilename(originalName, ext)
-	segTypeDir := strings.ToLower(strings.TrimSpace(ctx.SegmentTypeName))
+	segTypeDir := strings.ToLower(strings.TrimSpace(segment.SegmentTypeName))
 	if segTypeDir == "" {
 		segTypeDir = "unknown"
 	}
 
 	pathParts := []string{"segments"}
-	stableProvider := sanitizeSegmentPathComponent(ctx.StableProvider)
-	stableExternalID := sanitizeSegmentPathComponent(ctx.StableExternalID)
+	stableProvider := sanitizeSegmentPathComponent(segment.StableProvider)
+	stableExternalID := sanitizeSegmentPathComponent(segment.StableExternalID)
 	if stableProvider != "" && stableExternalID != "" {
 		pathParts = append(pathParts, "library", stableProvider, stableExternalID)
 	} else {
-		pathParts = append(pathParts, "local", fmt.Sprintf("anime_%d", ctx.AnimeID))
+		pathParts = append(pathParts, "local", fmt.Sprintf("anime_%d", segment.AnimeID))
 	}
 	pathParts = append(pathParts,
-		fmt.Sprintf("group_%d", ctx.GroupID),
-		sanitizeSegmentPathComponent(ctx.Version),
+		fmt.Sprintf("group_%d", segment.GroupID),
+		sanitizeSegmentPathComponent(segment.Version),
 		sanitizeSegmentPathComponent(segTypeDir),
 		sanitized,
 	)
 	relPath := filepath.Join(pathParts...)
 	// Use forward slashes for storage path keys (cross-platform consistent)
 	relPathFwd := filepath.ToSlash(relPath)
-	absolutePath := filepath.Join(s.storageDir, relPath)
-
-	if err := os.MkdirAll(filepath.Dir(absolutePath), 0o755); err != nil {
-		return nil, fmt.Errorf("create segment asset directory: %w", err)
-	}
-	if err := os.WriteFile(absolutePath, data, fs.FileMode(0o644)); err != nil {
+	absolutePath, err := s.putFile(ctx, relPathFwd, data, detectedMime)
+	if err != nil {
 		return nil, fmt.Errorf("write segment asset file: %w", err)
 	}
 
@@ -502,6 +589,24 @@ func extensionFromMime(mimeType string) string {
 	}
 }
 
+// putContentAddressed legt data unter key ab, sofern dort noch kein Objekt gleicher Größe liegt.
+// Die Ablagen schreiben atomar (lokal über temporäre Datei und Rename), sodass ein abgebrochener
+// Upload keinen halben Blob hinterlässt. existed meldet einen bereits vorhandenen Blob. Ohne
+// gesperrte media_blobs-Zeile kann der Blob danach noch entfernt werden (siehe EnsureStored).
+func (s *MediaService) putContentAddressed(ctx context.Context, key string, data []byte, contentType string) (existed bool, err error) {
+	info, err := s.store.Stat(ctx, key)
+	if err == nil && info.Size == int64(len(data)) {
+		return true, nil
+	}
+	if err != nil && !errors.Is(err, blobstore.ErrNotExist) {
+		return false, fmt.Errorf("stat media blob: %w", err)
+	}
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return false, fmt.Errorf("write media file: %w", err)
+	}
+	return false, nil
+}
+
 // buildFilename erzeugt einen eindeutigen Dateinamen aus MediaKind, Zeitstempel und
 // zufälligen Bytes. Falls die Zufallsgenerierung fehlschlägt, wird ein Fallback mit
 // Nano-Zeitstempel verwendet.
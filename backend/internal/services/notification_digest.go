@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// NotificationDigestStore ist die Datenbankschnittstelle des NotificationDigestService
+// (implementiert von repository.NotificationDigestRepository).
+type NotificationDigestStore interface {
+	ListDue(ctx context.Context, now time.Time, limit int) ([]models.DigestSubscription, error)
+	LoadContent(ctx context.Context, appUserID int64, since time.Time, until time.Time, limit int) (models.DigestContent, error)
+	Complete(ctx context.Context, sub models.DigestSubscription, windowEnd time.Time, nextDueAt time.Time, mail *models.MailOutboxInput) (bool, error)
+}
+
+// NotificationDigestConfig steuert Zeitplan und Umfang der Zusammenfassungs-Mails.
+// Sendezeitpunkte werden in UTC berechnet.
+type NotificationDigestConfig struct {
+	PollInterval time.Duration // Abstand zwischen zwei Durchläufen (Default 5m)
+	SendHour     int           // Stunde des Versands 0-23 (ungültig: 7)
+	WeeklyDay    time.Weekday  // Wochentag des Wochen-Digests (ungültig: Montag)
+	BatchSize    int           // Empfänger pro Datenbankabfrage (Default 100)
+	MaxItems     int           // Einträge je Abschnitt einer Mail (Default 25)
+}
+
+func (c NotificationDigestConfig) withDefaults() NotificationDigestConfig {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 5 * time.Minute
+	}
+	if c.SendHour < 0 || c.SendHour > 23 {
+		c.SendHour = 7
+	}
+	if c.WeeklyDay < time.Sunday || c.WeeklyDay > time.Saturday {
+		c.WeeklyDay = time.Monday
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.MaxItems <= 0 {
+		c.MaxItems = 25
+	}
+	return c
+}
+
+// NotificationDigestService stellt fällige Zusammenfassungs-Mails zusammen und reiht sie in
+// den Mail-Outbox ein; der Versand selbst läuft über den MailOutboxWorker und dessen Mailer.
+// Zeitfenster ohne neue Inhalte werden abgeschlossen, ohne eine Mail zu erzeugen.
+type NotificationDigestService struct {
+	store NotificationDigestStore
+	cfg   NotificationDigestConfig
+	now   func() time.Time
+}
+
+// NewNotificationDigestService erstellt einen neuen NotificationDigestService.
+func NewNotificationDigestService(store NotificationDigestStore, cfg NotificationDigestConfig) *NotificationDigestService {
+	return &NotificationDigestService{store: store, cfg: cfg.withDefaults(), now: time.Now}
+}
+
+// NextDue liefert den nächsten Sendezeitpunkt nach after für die Häufigkeit (nil bei off).
+func (s *NotificationDigestService) NextDue(frequency string, after time.Time) *time.Time {
+	after = after.UTC()
+	next := time.Date(after.Year(), after.Month(), after.Day(), s.cfg.SendHour, 0, 0, 0, time.UTC)
+	switch frequency {
+	case models.DigestFrequencyDaily:
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+	case models.DigestFrequencyWeekly:
+		next = next.AddDate(0, 0, (int(s.cfg.WeeklyDay)-int(next.Weekday())+7)%7)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+	default:
+		return nil
+	}
+	return &next
+}
+
+// Run verarbeitet fällige Digests bis zum Ende von ctx periodisch.
+func (s *NotificationDigestService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		s.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce verarbeitet alle fälligen Digests und gibt die Anzahl eingereihter Mails zurück.
+// Nach einem Fehler wird der Durchlauf beendet; betroffene Empfänger bleiben fällig.
+func (s *NotificationDigestService) RunOnce(ctx context.Context) int {
+	now := s.now().UTC()
+	queued := 0
+	for {
+		subs, err := s.store.ListDue(ctx, now, s.cfg.BatchSize)
+		if err != nil {
+			log.Printf("notification digest: list due: %v", err)
+			return queued
+		}
+
+		failed := false
+		for _, sub := range subs {
+			sent, err := s.process(ctx, sub, now)
+			if err != nil {
+				log.Printf("notification digest: process (app_user_id=%d): %v", sub.AppUserID, err)
+				failed = true
+				continue
+			}
+			if sent {
+				queued++
+			}
+		}
+		if failed || len(subs) < s.cfg.BatchSize {
+			return queued
+		}
+	}
+}
+
+func (s *NotificationDigestService) process(ctx context.Context, sub models.DigestSubscription, now time.Time) (bool, error) {
+	next := s.NextDue(sub.Frequency, now)
+	if next == nil {
+		return false, fmt.Errorf("unknown digest frequency %q", sub.Frequency)
+	}
+
+	// Das Zeitfenster reicht höchstens eine Periode zurück, damit ein lange pausierter
+	// Digest beim Reaktivieren keine alten Inhalte nachliefert.
+	since := now.AddDate(0, 0, -1)
+	if sub.Frequency == models.DigestFrequencyWeekly {
+		since = now.AddDate(0, 0, -7)
+	}
+	if sub.LastSentAt != nil && sub.LastSentAt.After(since) {
+		since = *sub.LastSentAt
+	}
+
+	content, err := s.store.LoadContent(ctx, sub.AppUserID, since, now, s.cfg.MaxItems)
+	if err != nil {
+		return false, err
+	}
+
+	var mail *models.MailOutboxInput
+	if !content.IsEmpty() {
+		built := digestMail(sub, content)
+		mail = &built
+	}
+	completed, err := s.store.Complete(ctx, sub, now, *next, mail)
+	if err != nil {
+		return false, err
+	}
+	return completed && mail != nil, nil
+}
+
+// digestMail baut den Outbox-Eintrag eines Digests. Die Template-Daten enthalten nur fertige
+// Texte und relative Links; absolute URLs ergänzt das Template beim Versand.
+func digestMail(sub models.DigestSubscription, content models.DigestContent) models.MailOutboxInput {
+	releases := make([]map[string]any, 0, len(content.ReleaseVersions))
+	for _, item := range content.ReleaseVersions {
+		releases = append(releases, map[string]any{
+			"anime_title":    item.AnimeTitle,
+			"episode_number": item.EpisodeNumber,
+			"version":        item.Version,
+			"group_names":    item.GroupNames,
+			"link":           fmt.Sprintf("/episodes/%d", item.EpisodeID),
+		})
+	}
+	comments := make([]map[string]any, 0, len(content.Comments))
+	for i := range content.Comments {
+		comment := &content.Comments[i]
+		comments = append(comments, map[string]any{
+			"author_name": comment.AuthorName,
+			"excerpt":     truncateNotificationText(comment.Content, 200),
+			"link":        CommentTargetLink(comment),
+		})
+	}
+
+	appUserID := sub.AppUserID
+	referenceType := "app_user"
+	return models.MailOutboxInput{
+		TemplateName: MailTemplateDigest,
+		Locale:       sub.Locale,
+		Recipient:    sub.Email,
+		TemplateData: map[string]any{
+			"frequency":        sub.Frequency,
+			"releases":         releases,
+			"comments":         comments,
+			"unsubscribe_link": "/digest/unsubscribe?token=" + url.QueryEscape(sub.UnsubscribeToken),
+		},
+		ReferenceType: &referenceType,
+		ReferenceID:   &appUserID,
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+type memoryDigestStore struct {
+	due       []models.DigestSubscription
+	content   map[int64]models.DigestContent
+	windows   map[int64][2]time.Time
+	completed map[int64]time.Time
+	mails     []models.MailOutboxInput
+}
+
+func (s *memoryDigestStore) ListDue(_ context.Context, now time.Time, limit int) ([]models.DigestSubscription, error) {
+	due := make([]models.DigestSubscription, 0)
+	for _, sub := range s.due {
+		if _, done := s.completed[sub.AppUserID]; done || sub.NextDueAt.After(now) {
+			continue
+		}
+		if len(due) < limit {
+			due = append(due, sub)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryDigestStore) LoadContent(_ context.Context, appUserID int64, since time.Time, until time.Time, _ int) (models.DigestContent, error) {
+	s.windows[appUserID] = [2]time.Time{since, until}
+	return s.content[appUserID], nil
+}
+
+func (s *memoryDigestStore) Complete(_ context.Context, sub models.DigestSubscription, _ time.Time, nextDueAt time.Time, mail *models.MailOutboxInput) (bool, error) {
+	s.completed[sub.AppUserID] = nextDueAt
+	if mail != nil {
+		s.mails = append(s.mails, *mail)
+	}
+	return true, nil
+}
+
+func TestNotificationDigestNextDue(t *testing.T) {
+	service := NewNotificationDigestService(nil, NotificationDigestConfig{SendHour: 7, WeeklyDay: time.Monday})
+	// Mittwoch, 3. Juni 2026
+	before := time.Date(2026, 6, 3, 6, 30, 0, 0, time.UTC)
+	after := time.Date(2026, 6, 3, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		frequency string
+		from      time.Time
+		want      time.Time
+	}{
+		{models.DigestFrequencyDaily, before, time.Date(2026, 6, 3, 7, 0, 0, 0, time.UTC)},
+		{models.DigestFrequencyDaily, after, time.Date(2026, 6, 4, 7, 0, 0, 0, time.UTC)},
+		{models.DigestFrequencyWeekly, before, time.Date(2026, 6, 8, 7, 0, 0, 0, time.UTC)},
+		{models.DigestFrequencyWeekly, time.Date(2026, 6, 8, 7, 0, 0, 0, time.UTC), time.Date(2026, 6, 15, 7, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		got := service.NextDue(tc.frequency, tc.from)
+		if got == nil || !got.Equal(tc.want) {
+			t.Fatalf("NextDue(%s, %s) = %v, want %s", tc.frequency, tc.from, got, tc.want)
+		}
+	}
+	if service.NextDue(models.DigestFrequencyOff, before) != nil {
+		t.Fatal("expected no due time for disabled digest")
+	}
+}
+
+func TestNotificationDigestRunOnceQueuesOnlyNonEmptyDigests(t *testing.T) {
+	now := time.Date(2026, 6, 3, 7, 5, 0, 0, time.UTC)
+	lastSent := now.Add(-30 * time.Hour)
+	animeID := int64(9)
+	store := &memoryDigestStore{
+		due: []models.DigestSubscription{
+			{AppUserID: 1, Email: "a@example.org", Frequency: models.DigestFrequencyDaily, Locale: models.MailLocaleDE, UnsubscribeToken: "tok/1", LastSentAt: &lastSent, NextDueAt: now.Add(-5 * time.Minute)},
+			{AppUserID: 2, Email: "b@example.org", Frequency: models.DigestFrequencyWeekly, UnsubscribeToken: "tok2", NextDueAt: now.Add(-time.Hour)},
+			{AppUserID: 3, Email: "c@example.org", Frequency: models.DigestFrequencyDaily, UnsubscribeToken: "tok3", NextDueAt: now.Add(time.Hour)},
+		},
+		content: map[int64]models.DigestContent{
+			1: {
+				ReleaseVersions: []models.DigestReleaseVersion{{AnimeTitle: "Naruto", EpisodeID: 44, EpisodeNumber: "12", Version: "v2", GroupNames: "GroupA"}},
+				Comments:        []models.CommentListItem{{ID: 5, TargetType: models.CommentTargetAnime, AnimeID: &animeID, AuthorName: "Sakura", Content: "Danke!"}},
+			},
+		},
+		windows:   map[int64][2]time.Time{},
+		completed: map[int64]time.Time{},
+	}
+	service := NewNotificationDigestService(store, NotificationDigestConfig{SendHour: 7, WeeklyDay: time.Monday, BatchSize: 1})
+	service.now = func() time.Time { return now }
+
+	if queued := service.RunOnce(context.Background()); queued != 1 {
+		t.Fatalf("expected one queued digest, got %d", queued)
+	}
+	if len(store.completed) != 2 {
+		t.Fatalf("expected both due digests to complete their window, got %v", store.completed)
+	}
+	if want := time.Date(2026, 6, 4, 7, 0, 0, 0, time.UTC); !store.completed[1].Equal(want) {
+		t.Fatalf("expected next daily digest at %s, got %s", want, store.completed[1])
+	}
+	if window := store.windows[1]; !window[0].Equal(now.AddDate(0, 0, -1)) {
+		t.Fatalf("expected window capped to one day, got %v", window)
+	}
+	if window := store.windows[2]; !window[0].Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("expected first weekly window to span seven days, got %v", window)
+	}
+
+	templates, err := NewMailTemplates("https://team4s.example")
+	if err != nil {
+		t.Fatalf("unexpected template error: %v", err)
+	}
+	mail := store.mails[0]
+	rendered, err := templates.Render(mail.TemplateName, mail.Locale, mail.TemplateData)
+	if err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+	if rendered.Subject != "Deine Tagesübersicht bei Team4s" {
+		t.Fatalf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{
+		"Naruto, Episode 12 (v2) von GroupA",
+		"https://team4s.example/episodes/44",
+		"Sakura: Danke!",
+		"https://team4s.example/anime/9#comment-5",
+		"https://team4s.example/digest/unsubscribe?token=tok%2F1",
+	} {
+		if !strings.Contains(rendered.BodyText, want) {
+			t.Fatalf("expected %q in digest text, got:\n%s", want, rendered.BodyText)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// NotificationStore kapselt die Datenbankzugriffe des NotificationService
+// (implementiert von repository.NotificationRepository).
+type NotificationStore interface {
+	LoadRecipients(ctx context.Context, appUserIDs []int64, eventType string) ([]models.NotificationRecipient, error)
+	CreateForUsers(ctx context.Context, event models.NotificationEvent, appUserIDs []int64, mails []models.MailOutboxInput) error
+	ContributionProposerAppUserID(ctx context.Context, contributionID int64) (*int64, error)
+	ClaimAppUserID(ctx context.Context, claimID int64) (*int64, error)
+	AppUserIDByEmail(ctx context.Context, email string) (*int64, error)
+	WatchlistAppUserIDs(ctx context.Context, animeID int64) ([]int64, error)
+}
+
+// NotificationService nimmt Ereignisse aus anderen Teilen des Backends entgegen und stellt
+// sie je nach Präferenz des Empfängers in-app, zusätzlich per E-Mail oder gar nicht zu.
+// Alle Methoden sind auf einem nil-Service No-ops.
+type NotificationService struct {
+	store NotificationStore
+}
+
+// NewNotificationService erstellt einen NotificationService.
+func NewNotificationService(store NotificationStore) *NotificationService {
+	return &NotificationService{store: store}
+}
+
+// Publish stellt ein Ereignis allen Empfängern zu. Doppelte Empfänger und der Auslöser selbst
+// werden übersprungen. E-Mails werden zusammen mit den Benachrichtigungen in den Mail-Outbox
+// geschrieben und vom MailOutboxWorker versendet.
+func (s *NotificationService) Publish(ctx context.Context, event models.NotificationEvent) error {
+	if s == nil || s.store == nil {
+		return nil
+	}
+	if !models.IsNotificationEventType(event.EventType) {
+		return fmt.Errorf("notification: unknown event type %q", event.EventType)
+	}
+
+	recipientIDs := uniqueNotificationRecipients(event.RecipientAppUserIDs, event.ActorAppUserID)
+	if len(recipientIDs) == 0 {
+		return nil
+	}
+
+	recipients, err := s.store.LoadRecipients(ctx, recipientIDs, event.EventType)
+	if err != nil {
+		return err
+	}
+
+	inApp := make([]int64, 0, len(recipients))
+	mails := make([]models.MailOutboxInput, 0)
+	for _, recipient := range recipients {
+		switch recipient.Channel {
+		case models.NotificationChannelOff:
+			continue
+		case models.NotificationChannelEmail:
+			if mail, ok := notificationMail(event, recipient); ok {
+				mails = append(mails, mail)
+			}
+		}
+		inApp = append(inApp, recipient.AppUserID)
+	}
+
+	return s.store.CreateForUsers(ctx, event, inApp, mails)
+}
+
+// NotifyContributionReviewed benachrichtigt den Ersteller eines Beitragsvorschlags über die
+// Bestätigung oder Ablehnung.
+func (s *NotificationService) NotifyContributionReviewed(
+	ctx context.Context,
+	contributionID int64,
+	actorAppUserID int64,
+	confirmed bool,
+	reviewNote *string,
+) error {
+	if s == nil || s.store == nil {
+		return nil
+	}
+
+	recipient, err := s.store.ContributionProposerAppUserID(ctx, contributionID)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	event := models.NotificationEvent{
+		EventType:           models.NotificationEventContributionConfirmed,
+		RecipientAppUserIDs: []int64{*recipient},
+		ActorAppUserID:      optionalNotificationActor(actorAppUserID),
+		Title:               "Dein Beitragsvorschlag wurde bestätigt",
+		LinkURL:             notificationLink("/me/contributions"),
+		Payload:             map[string]any{"contribution_id": contributionID},
+	}
+	if !confirmed {
+		event.EventType = models.NotificationEventContributionRejected
+		event.Title = "Dein Beitragsvorschlag wurde abgelehnt"
+		if reviewNote != nil && strings.TrimSpace(*reviewNote) != "" {
+			note := strings.TrimSpace(*reviewNote)
+			event.Body = &note
+		}
+	}
+
+	return s.Publish(ctx, event)
+}
+
+// NotifyMemberClaimVerified benachrichtigt den Antragsteller eines bestätigten Member-Claims.
+func (s *NotificationService) NotifyMemberClaimVerified(ctx context.Context, claimID int64, actorAppUserID int64) error {
+	if s == nil || s.store == nil {
+		return nil
+	}
+
+	recipient, err := s.store.ClaimAppUserID(ctx, claimID)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	return s.Publish(ctx, models.NotificationEvent{
+		EventType:           models.NotificationEventMemberClaimVerified,
+		RecipientAppUserIDs: []int64{*recipient},
+		ActorAppUserID:      optionalNotificationActor(actorAppUserID),
+		Title:               "Dein Member-Profil wurde bestätigt",
+		LinkURL:             notificationLink("/me/profile"),
+		Payload:             map[string]any{"claim_id": claimID},
+	})
+}
+
+// NotifyInvitationReceived benachrichtigt einen bereits registrierten Account über eine
+// Einladung in eine Fansubgruppe. Für unbekannte Adressen bleibt es bei der Einladungsmail.
+func (s *NotificationService) NotifyInvitationReceived(
+	ctx context.Context,
+	email string,
+	fansubGroupID int64,
+	inviteLink string,
+	actorAppUserID int64,
+) error {
+	if s == nil || s.store == nil {
+		return nil
+	}
+
+	recipient, err := s.store.AppUserIDByEmail(ctx, email)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	return s.Publish(ctx, models.NotificationEvent{
+		EventType:           models.NotificationEventInvitationReceived,
+		RecipientAppUserIDs: []int64{*recipient},
+		ActorAppUserID:      optionalNotificationActor(actorAppUserID),
+		Title:               "Du wurdest in eine Fansub-Gruppe eingeladen",
+		LinkURL:             notificationLink(inviteLink),
+		Payload:             map[string]any{"fansub_group_id": fansubGroupID},
+	})
+}
+
+// NotifyCommentMentions benachrichtigt die per @slug erwähnten Accounts eines Kommentars.
+func (s *NotificationService) NotifyCommentMentions(
+	ctx context.Context,
+	comment *models.CommentListItem,
+	mentions []models.CommentMentionTarget,
+	actorAppUserID int64,
+) error {
+	if s == nil || s.store == nil || comment == nil {
+		return nil
+	}
+
+	recipients := make([]int64, 0, len(mentions))
+	for _, mention := range mentions {
+		if mention.AppUserID != nil {
+			recipients = append(recipients, *mention.AppUserID)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	body := comment.AuthorName + ": " + truncateNotificationText(comment.Content, 200)
+	return s.Publish(ctx, models.NotificationEvent{
+		EventType:           models.NotificationEventCommentMention,
+		RecipientAppUserIDs: recipients,
+		ActorAppUserID:      optionalNotificationActor(actorAppUserID),
+		Title:               comment.AuthorName + " hat dich in einem Kommentar erwähnt",
+		Body:                &body,
+		LinkURL:             notificationLink(CommentTargetLink(comment)),
+		Payload: map[string]any{
+			"comment_id":  comment.ID,
+			"target_type": comment.TargetType,
+			"target_id":   comment.TargetID,
+		},
+	})
+}
+
+// NotifyNewEpisode benachrichtigt alle Nutzer mit dem Anime auf der Watchlist über eine neue Episode.
+func (s *NotificationService) NotifyNewEpisode(
+	ctx context.Context,
+	animeID int64,
+	episodeID int64,
+	episodeNumber string,
+	actorAppUserID int64,
+) error {
+	if s == nil || s.store == nil {
+		return nil
+	}
+
+	recipients, err := s.store.WatchlistAppUserIDs(ctx, animeID)
+	if err != nil || len(recipients) == 0 {
+		return err
+	}
+
+	return s.Publish(ctx, models.NotificationEvent{
+		EventType:           models.NotificationEventWatchlistNewEpisode,
+		RecipientAppUserIDs: recipients,
+		ActorAppUserID:      optionalNotificationActor(actorAppUserID),
+		Title:               "Neue Episode " + strings.TrimSpace(episodeNumber) + " auf deiner Watchlist",
+		LinkURL:             notificationLink(fmt.Sprintf("/episodes/%d", episodeID)),
+		Payload: map[string]any{
+			"anime_id":       animeID,
+			"episode_id":     episodeID,
+			"episode_number": episodeNumber,
+		},
+	})
+}
+
+// CommentTargetLink liefert den Frontend-Pfad zum Kommentar an seinem Ziel.
+func CommentTargetLink(comment *models.CommentListItem) string {
+	var base string
+	switch comment.TargetType {
+	case models.CommentTargetEpisode:
+		base = fmt.Sprintf("/episodes/%d", comment.TargetID)
+	case models.CommentTargetFansubGroup:
+		base = fmt.Sprintf("/fansubs/%d", comment.TargetID)
+	default:
+		if comment.AnimeID == nil {
+			return ""
+		}
+		base = fmt.Sprintf("/anime/%d", *comment.AnimeID)
+	}
+	return fmt.Sprintf("%s#comment-%d", base, comment.ID)
+}
+
+// notificationMail baut den Outbox-Eintrag für einen Empfänger mit E-Mail-Kanal.
+func notificationMail(event models.NotificationEvent, recipient models.NotificationRecipient) (models.MailOutboxInput, bool) {
+	email := strings.TrimSpace(recipient.Email)
+	if email == "" {
+		return models.MailOutboxInput{}, false
+	}
+
+	data := map[string]any{
+		"event_type": event.EventType,
+		"title":      event.Title,
+	}
+	if event.Body != nil {
+		data["body"] = *event.Body
+	}
+	if event.LinkURL != nil {
+		data["link_url"] = *event.LinkURL
+	}
+	referenceType := "app_user"
+	appUserID := recipient.AppUserID
+
+	return models.MailOutboxInput{
+		TemplateName:  MailTemplateNotification,
+		Recipient:     email,
+		TemplateData:  data,
+		ReferenceType: &referenceType,
+		ReferenceID:   &appUserID,
+	}, true
+}
+
+// uniqueNotificationRecipients entfernt doppelte, ungültige und den Auslöser selbst.
+func uniqueNotificationRecipients(ids []int64, actorAppUserID *int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 || (actorAppUserID != nil && *actorAppUserID == id) {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+func optionalNotificationActor(appUserID int64) *int64 {
+	if appUserID <= 0 {
+		return nil
+	}
+	return &appUserID
+}
+
+func notificationLink(path string) *string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	return &path
+}
+
+func truncateNotificationText(value string, maxRunes int) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) <= maxRunes {
+		return string(runes)
+	}
+	return string(runes[:maxRunes]) + "…"
+}
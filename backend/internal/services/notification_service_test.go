@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
+
+type fakeNotificationStore struct {
+	channels  map[int64]string
+	created   map[string][]int64
+	mails     []models.MailOutboxInput
+	watchlist []int64
+	claimUser *int64
+	loadErr   error
+}
+
+func (s *fakeNotificationStore) LoadRecipients(_ context.Context, ids []int64, _ string) ([]models.NotificationRecipient, error) {
+	if s.loadErr != nil {
+		return nil, s.loadErr
+	}
+	recipients := make([]models.NotificationRecipient, 0, len(ids))
+	for _, id := range ids {
+		channel, ok := s.channels[id]
+		if !ok {
+			channel = models.DefaultNotificationChannel
+		}
+		recipients = append(recipients, models.NotificationRecipient{AppUserID: id, Channel: channel, Email: "user@example.org"})
+	}
+	return recipients, nil
+}
+
+func (s *fakeNotificationStore) CreateForUsers(_ context.Context, event models.NotificationEvent, ids []int64, mails []models.MailOutboxInput) error {
+	if s.created == nil {
+		s.created = map[string][]int64{}
+	}
+	s.created[event.EventType] = append(s.created[event.EventType], ids...)
+	s.mails = append(s.mails, mails...)
+	return nil
+}
+
+func (s *fakeNotificationStore) ContributionProposerAppUserID(context.Context, int64) (*int64, error) {
+	return nil, nil
+}
+
+func (s *fakeNotificationStore) ClaimAppUserID(context.Context, int64) (*int64, error) {
+	return s.claimUser, nil
+}
+
+func (s *fakeNotificationStore) AppUserIDByEmail(context.Context, string) (*int64, error) {
+	return nil, nil
+}
+
+func (s *fakeNotificationStore) WatchlistAppUserIDs(context.Context, int64) ([]int64, error) {
+	return s.watchlist, nil
+}
+
+func TestNotificationServicePublishRespectsChannels(t *testing.T) {
+	store := &fakeNotificationStore{channels: map[int64]string{
+		2: models.NotificationChannelEmail,
+		3: models.NotificationChannelOff,
+	}}
+	svc := NewNotificationService(store)
+
+	err := svc.NotifyNewEpisode(context.Background(), 7, 42, "12", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.created) != 0 {
+		t.Fatalf("expected no notifications without watchlist recipients, got %v", store.created)
+	}
+
+	store.watchlist = []int64{1, 2, 3, 5, 2}
+	if err := svc.NotifyNewEpisode(context.Background(), 7, 42, "12", 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := store.created[models.NotificationEventWatchlistNewEpisode]
+	if !reflect.DeepEqual(got, []int64{1, 2}) {
+		t.Fatalf("expected in-app notifications for users 1 and 2 (not off, not actor), got %v", got)
+	}
+	if len(store.mails) != 1 {
+		t.Fatalf("expected one outbox mail for the email channel, got %d", len(store.mails))
+	}
+	if store.mails[0].TemplateName != MailTemplateNotification || *store.mails[0].ReferenceID != 2 {
+		t.Fatalf("expected notification mail for user 2, got %+v", store.mails[0])
+	}
+
+	templates, err := NewMailTemplates("https://team4s.example/")
+	if err != nil {
+		t.Fatalf("unexpected template error: %v", err)
+	}
+	rendered, err := templates.Render(store.mails[0].TemplateName, store.mails[0].Locale, store.mails[0].TemplateData)
+	if err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+	if !strings.Contains(rendered.BodyText, "https://team4s.example/episodes/42") {
+		t.Fatalf("expected absolute episode link in mail, got %q", rendered.BodyText)
+	}
+}
+
+func TestNotificationServicePublishRejectsUnknownEventType(t *testing.T) {
+	svc := NewNotificationService(&fakeNotificationStore{})
+
+	err := svc.Publish(context.Background(), models.NotificationEvent{EventType: "unknown", RecipientAppUserIDs: []int64{1}})
+	if err == nil {
+		t.Fatal("expected error for unknown event type")
+	}
+}
+
+func TestNotificationServiceNilIsNoop(t *testing.T) {
+	var svc *NotificationService
+	if err := svc.NotifyMemberClaimVerified(context.Background(), 1, 2); err != nil {
+		t.Fatalf("expected nil service to be a no-op, got %v", err)
+	}
+}
+
+func TestNotificationServicePropagatesStoreErrors(t *testing.T) {
+	appUserID := int64(9)
+	store := &fakeNotificationStore{claimUser: &appUserID, loadErr: errors.New("db down")}
+	svc := NewNotificationService(store)
+
+	if err := svc.NotifyMemberClaimVerified(context.Background(), 1, 2); err == nil {
+		t.Fatal("expected store error to be returned")
+	}
+}
+
+func TestCommentTargetLink(t *testing.T) {
+	animeID := int64(3)
+	tests := []struct {
+		comment models.CommentListItem
+		want    string
+	}{
+		{models.CommentListItem{ID: 1, TargetType: models.CommentTargetAnime, TargetID: 3, AnimeID: &animeID}, "/anime/3#comment-1"},
+		{models.CommentListItem{ID: 2, TargetType: models.CommentTargetEpisode, TargetID: 8, AnimeID: &animeID}, "/episodes/8#comment-2"},
+		{models.CommentListItem{ID: 3, TargetType: models.CommentTargetReleaseVersion, TargetID: 11, AnimeID: &animeID}, "/anime/3#comment-3"},
+		{models.CommentListItem{ID: 4, TargetType: models.CommentTargetFansubGroup, TargetID: 5}, "/fansubs/5#comment-4"},
+	}
+
+	for _, tc := range tests {
+		if got := CommentTargetLink(&tc.comment); got != tc.want {
+			t.Fatalf("expected %q, got %q", tc.want, got)
+		}
+	}
+}
@@ -2,11 +2,15 @@ package services
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"team4s.v3/backend/internal/blobstore"
+	"team4s.v3/backend/internal/models"
 	"team4s.v3/backend/internal/repository"
 )
 
@@ -32,15 +36,28 @@ type RVMCleanupStore interface {
 	HardDeleteRVMAndAsset(ctx context.Context, relationID, mediaAssetID int64) error
 }
 
+// TrickplayCleanupBatchSize limits how many orphaned trickplay assets one run removes.
+const TrickplayCleanupBatchSize = 500
+
+// TrickplayCleanupStore is the database interface for removing trickplay sprites whose
+// release variant was deleted (implemented by repository.TrickplayRepository).
+type TrickplayCleanupStore interface {
+	ListOrphanedTrickplayAssets(ctx context.Context, limit int) ([]models.TrickplayOrphanAsset, error)
+	DeleteOrphanedTrickplayAssets(ctx context.Context, mediaAssetIDs []int64) error
+}
+
 // RVMCleanupService performs periodic cleanup of release-version media assets:
 //  1. Stale processing: mark stuck-processing assets as failed and remove staging files
 //  2. Missing files: detect absent physical files and escalate the asset when no ready variant remains
 //  3. Soft-delete: physically delete files and hard-delete DB rows for exclusively-owned deleted media
+//  4. Trickplay: remove sprites and WebVTT tracks whose release variant was deleted (optional)
 //
 // All passes are best-effort — errors are logged but do not abort the run or stop the server (D-07).
 type RVMCleanupService struct {
-	store      RVMCleanupStore
-	storageDir string
+	store          RVMCleanupStore
+	trickplayStore TrickplayCleanupStore
+	storageDir     string
+	blobs          blobstore.BlobStore
 }
 
 // NewRVMCleanupService creates a new RVMCleanupService.
@@ -49,7 +66,22 @@ func NewRVMCleanupService(store RVMCleanupStore, storageDir string) *RVMCleanupS
 	if dir == "" {
 		dir = "./storage/media"
 	}
-	return &RVMCleanupService{store: store, storageDir: dir}
+	return &RVMCleanupService{store: store, storageDir: dir, blobs: blobstore.NewLocalStore(dir)}
+}
+
+// WithBlobStore sets the media storage the files live in. File paths below the storage dir are
+// mapped to blob keys; paths outside of it are treated as plain local files.
+func (s *RVMCleanupService) WithBlobStore(blobs blobstore.BlobStore) *RVMCleanupService {
+	if blobs != nil {
+		s.blobs = blobs
+	}
+	return s
+}
+
+// WithTrickplayCleanup enables the pass that removes orphaned trickplay assets.
+func (s *RVMCleanupService) WithTrickplayCleanup(store TrickplayCleanupStore) *RVMCleanupService {
+	s.trickplayStore = store
+	return s
 }
 
 // RunOnce executes one full cleanup cycle (all three passes).
@@ -58,6 +90,7 @@ func (s *RVMCleanupService) RunOnce(ctx context.Context) {
 	s.passStaleProcessing(ctx)
 	s.passMissingFiles(ctx)
 	s.passSoftDelete(ctx)
+	s.passOrphanedTrickplay(ctx)
 }
 
 // passStaleProcessing marks assets stuck in 'processing' as 'failed' and
@@ -73,7 +106,7 @@ func (s *RVMCleanupService) passStaleProcessing(ctx context.Context) {
 			log.Printf("rvm cleanup: mark asset %d failed: %v", c.MediaAssetID, err)
 			continue
 		}
-		removeFileQuietly(c.FilePath)
+		s.removeStoredFile(ctx, c.FilePath)
 	}
 }
 
@@ -89,7 +122,13 @@ func (s *RVMCleanupService) passMissingFiles(ctx context.Context) {
 		if c.FilePath == "" {
 			continue
 		}
-		if _, statErr := os.Stat(c.FilePath); statErr == nil {
+		exists, statErr := s.storedFileExists(ctx, c.FilePath)
+		if statErr != nil {
+			// storage unreachable — never mark files missing on a transient error
+			log.Printf("rvm cleanup: stat file %d: %v", c.MediaFileID, statErr)
+			continue
+		}
+		if exists {
 			// file exists — nothing to do
 			continue
 		}
@@ -134,8 +173,8 @@ func (s *RVMCleanupService) passSoftDelete(ctx context.Context) {
 		}
 
 		// Remove physical files first so the DB rows are only deleted on success.
-		removeFileQuietly(c.OriginalFilePath)
-		removeFileQuietly(c.ThumbFilePath)
+		s.removeStoredFile(ctx, c.OriginalFilePath)
+		s.removeStoredFile(ctx, c.ThumbFilePath)
 
 		if err := s.store.HardDeleteRVMAndAsset(ctx, c.RelationID, c.MediaAssetID); err != nil {
 			log.Printf("rvm cleanup: hard delete relation %d asset %d: %v",
@@ -144,6 +183,88 @@ func (s *RVMCleanupService) passSoftDelete(ctx context.Context) {
 	}
 }
 
+// passOrphanedTrickplay removes sprite and WebVTT files of deleted release variants (or of
+// superseded generations) and then deletes their media_assets rows. Emptied generation and
+// variant folders are removed as well.
+func (s *RVMCleanupService) passOrphanedTrickplay(ctx context.Context) {
+	if s.trickplayStore == nil {
+		return
+	}
+	assets, err := s.trickplayStore.ListOrphanedTrickplayAssets(ctx, TrickplayCleanupBatchSize)
+	if err != nil {
+		log.Printf("rvm cleanup: select orphaned trickplay assets: %v", err)
+		return
+	}
+	if len(assets) == 0 {
+		return
+	}
+
+	localRoot, isLocal := blobstore.LocalRoot(s.blobs)
+	ids := make([]int64, 0, len(assets))
+	dirs := make(map[string]struct{})
+	for _, asset := range assets {
+		key, ok := blobstore.KeyFromPath(s.storageDir, asset.FilePath)
+		if !ok {
+			log.Printf("rvm cleanup: skipping trickplay asset %d outside storage dir: %q", asset.MediaAssetID, asset.FilePath)
+			continue
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("rvm cleanup: remove trickplay file %s: %v", key, err)
+			continue
+		}
+		if isLocal {
+			dirs[filepath.Dir(blobstore.PathForKey(localRoot, key))] = struct{}{}
+		}
+		ids = append(ids, asset.MediaAssetID)
+	}
+	if err := s.trickplayStore.DeleteOrphanedTrickplayAssets(ctx, ids); err != nil {
+		log.Printf("rvm cleanup: delete orphaned trickplay assets: %v", err)
+		return
+	}
+	for dir := range dirs {
+		// os.Remove only succeeds for empty folders; a newer generation keeps its parent alive.
+		if os.Remove(dir) == nil {
+			_ = os.Remove(filepath.Dir(dir))
+		}
+	}
+}
+
+// removeStoredFile deletes a file from the blob store (or from disk for legacy paths outside the
+// storage dir), logging but not propagating errors.
+func (s *RVMCleanupService) removeStoredFile(ctx context.Context, path string) {
+	key, ok := blobstore.KeyFromPath(s.storageDir, path)
+	if !ok {
+		removeFileQuietly(path)
+		return
+	}
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		log.Printf("rvm cleanup: remove file %s: %v", key, err)
+	}
+}
+
+// storedFileExists reports whether the file behind path is present. Only a definite "not found"
+// yields false without an error.
+func (s *RVMCleanupService) storedFileExists(ctx context.Context, path string) (bool, error) {
+	key, ok := blobstore.KeyFromPath(s.storageDir, path)
+	if !ok {
+		_, err := os.Stat(path)
+		if err == nil {
+			return true, nil
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, err := s.blobs.Stat(ctx, key); err != nil {
+		if errors.Is(err, blobstore.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // removeFileQuietly deletes a single file, logging but not propagating errors.
 // Empty or whitespace-only paths are silently ignored.
 func removeFileQuietly(path string) {
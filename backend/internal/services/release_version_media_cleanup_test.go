@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
+	"team4s.v3/backend/internal/blobstore"
 	"team4s.v3/backend/internal/repository"
 )
 
@@ -401,3 +402,44 @@ func TestRVMPreviewAssignmentIsExclusive(t *testing.T) {
 	assert.Less(t, clearIdx, patchIdx,
 		"ClearPreviewCandidateForVersion must be called before PatchReleaseVersionMedia in the tx path")
 }
+
+// TestRVMCleanupService_BlobStore verifies that files which only exist in the
+// configured blob store count as present and are deleted there.
+func TestRVMCleanupService_BlobStore(t *testing.T) {
+	tmpDir := t.TempDir()
+	bucket := blobstore.NewLocalStore(t.TempDir())
+	ctx := context.Background()
+	for _, key := range []string{"release-version/1/original.jpg", "release-version/1/thumb.jpg"} {
+		if err := bucket.Put(ctx, key, strings.NewReader("dummy"), 5, "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := newMockRVMCleanupStore()
+	store.missingCandidates = []repository.MissingFileCleanupCandidate{
+		{MediaFileID: 7, MediaAssetID: 42, FilePath: filepath.Join(tmpDir, "release-version", "1", "original.jpg")},
+	}
+	store.softDeleteCandidates = []repository.SoftDeleteCleanupCandidate{
+		{
+			RelationID:       55,
+			MediaAssetID:     42,
+			OriginalFilePath: filepath.Join(tmpDir, "release-version", "1", "original.jpg"),
+			ThumbFilePath:    filepath.Join(tmpDir, "release-version", "1", "thumb.jpg"),
+			DeletedAt:        time.Now().Add(-48 * time.Hour),
+		},
+	}
+	store.referencedAssets[42] = false
+
+	svc := NewRVMCleanupService(store, tmpDir).
+		WithBlobStore(blobstore.NewFallbackStore(bucket, blobstore.NewLocalStore(tmpDir)))
+	svc.RunOnce(ctx)
+
+	assert.NotContains(t, store.markedFileMissing, int64(7),
+		"file present in the blob store must not be marked missing")
+	for _, key := range []string{"release-version/1/original.jpg", "release-version/1/thumb.jpg"} {
+		_, err := bucket.Stat(ctx, key)
+		assert.True(t, errors.Is(err, blobstore.ErrNotExist),
+			"blob must be removed after soft-delete cleanup: %s", key)
+	}
+	assert.Len(t, store.hardDeletedPairs, 1)
+}
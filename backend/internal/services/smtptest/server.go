@@ -0,0 +1,158 @@
+// Package smtptest stellt einen lokalen SMTP-Server für Tests bereit (analog zu
+// net/http/httptest). Er nimmt beliebig viele Sitzungen an, speichert empfangene Mails
+// und kann Zustellungen gezielt mit einem permanenten Fehler ablehnen.
+package smtptest
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message ist eine vom Server angenommene Mail.
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+// Server ist ein minimaler SMTP-Server auf 127.0.0.1 mit zufälligem Port.
+type Server struct {
+	Host string
+	Port int
+
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []Message
+	rejects  int
+	wg       sync.WaitGroup
+}
+
+// NewServer startet einen Server. Close muss vom Aufrufer aufgerufen werden.
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	host, portStr, _ := net.SplitHostPort(ln.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	s := &Server{Host: host, Port: port, ln: ln}
+	s.wg.Add(1)
+	go s.acceptLoop()
+	return s, nil
+}
+
+// RejectNext lässt die nächsten n Zustellungen mit 554 am Ende von DATA scheitern.
+func (s *Server) RejectNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejects = n
+}
+
+// Messages liefert eine Kopie aller bisher angenommenen Mails.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Close beendet den Server und wartet auf offene Sitzungen.
+func (s *Server) Close() {
+	_ = s.ln.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+		}()
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	write := func(line string) {
+		_, _ = w.WriteString(line + "\r\n")
+		_ = w.Flush()
+	}
+
+	write("220 smtptest ESMTP")
+	var current Message
+	var body strings.Builder
+	inData := false
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+		if inData {
+			if trimmed != "." {
+				body.WriteString(strings.TrimPrefix(trimmed, ".") + "\n")
+				continue
+			}
+			inData = false
+			current.Data = body.String()
+			if s.accept(current) {
+				write("250 OK queued")
+			} else {
+				write("554 Transaction failed")
+			}
+			current = Message{}
+			body.Reset()
+			continue
+		}
+
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			write("250-smtptest")
+			write("250 OK")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			current.From = addressArgument(trimmed)
+			write("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			current.To = append(current.To, addressArgument(trimmed))
+			write("250 OK")
+		case upper == "DATA":
+			write("354 End data with <CR><LF>.<CR><LF>")
+			inData = true
+		case upper == "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("250 OK")
+		}
+	}
+}
+
+func (s *Server) accept(message Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rejects > 0 {
+		s.rejects--
+		return false
+	}
+	s.messages = append(s.messages, message)
+	return true
+}
+
+func addressArgument(line string) string {
+	_, value, _ := strings.Cut(line, ":")
+	return strings.Trim(strings.TrimSpace(value), "<>")
+}
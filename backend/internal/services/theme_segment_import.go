@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/models"
+)
+
+// DefaultThemeSegmentImportTolerance ist die erlaubte Abweichung in Sekunden, bis zu der Episoden
+// in denselben Vorschlag fallen.
+const DefaultThemeSegmentImportTolerance int32 = 3
+
+// ThemeSegmentObservationsFromMediaSegments wandelt Jellyfin-Segmente einer Episode in
+// Beobachtungen um. Gibt es mehrere Segmente derselben Art, zählt das längste.
+func ThemeSegmentObservationsFromMediaSegments(episodeNumber int, segments []mediaserver.MediaSegment) []models.ThemeSegmentObservation {
+	kinds := map[string]string{
+		mediaserver.MediaSegmentTypeIntro: models.SkipMarkerKindIntro,
+		mediaserver.MediaSegmentTypeOutro: models.SkipMarkerKindOutro,
+	}
+
+	byKind := make(map[string]models.ThemeSegmentObservation, len(kinds))
+	for _, segment := range segments {
+		kind, ok := kinds[segment.Type]
+		if !ok {
+			continue
+		}
+		start := ticksToRoundedSeconds(segment.StartTicks)
+		end := ticksToRoundedSeconds(segment.EndTicks)
+		if start < 0 || end <= start {
+			continue
+		}
+		if current, exists := byKind[kind]; exists && current.EndSeconds-current.StartSeconds >= end-start {
+			continue
+		}
+		byKind[kind] = models.ThemeSegmentObservation{EpisodeNumber: episodeNumber, Kind: kind, StartSeconds: start, EndSeconds: end}
+	}
+
+	observations := make([]models.ThemeSegmentObservation, 0, len(byKind))
+	for _, kind := range []string{models.SkipMarkerKindIntro, models.SkipMarkerKindOutro} {
+		if observation, ok := byKind[kind]; ok {
+			observations = append(observations, observation)
+		}
+	}
+	return observations
+}
+
+// BuildThemeSegmentProposals fasst aufeinanderfolgende Episoden, deren Start und Ende höchstens
+// tolerance Sekunden von der ersten Episode der Gruppe abweichen, zu Vorschlägen zusammen. Eine
+// Episode ohne Beobachtung dieser Art beendet die Gruppe. Die Zeiten eines Vorschlags sind die
+// Mediane der Gruppe. Bestehende Segmente derselben Art mit überlappendem Episodenbereich werden
+// als Konflikt ausgewiesen.
+func BuildThemeSegmentProposals(
+	episodeNumbers []int,
+	observations []models.ThemeSegmentObservation,
+	existing []models.AdminThemeSegment,
+	themes []models.AdminAnimeTheme,
+	tolerance int32,
+) []models.ThemeSegmentProposal {
+	sortedEpisodes := append([]int(nil), episodeNumbers...)
+	sort.Ints(sortedEpisodes)
+
+	proposals := make([]models.ThemeSegmentProposal, 0)
+	for _, kind := range []string{models.SkipMarkerKindIntro, models.SkipMarkerKindOutro} {
+		byEpisode := make(map[int]models.ThemeSegmentObservation)
+		for _, observation := range observations {
+			if observation.Kind == kind {
+				byEpisode[observation.EpisodeNumber] = observation
+			}
+		}
+
+		var cluster []models.ThemeSegmentObservation
+		flush := func() {
+			if len(cluster) > 0 {
+				proposals = append(proposals, buildThemeSegmentProposal(kind, cluster, existing, themes, tolerance))
+			}
+			cluster = nil
+		}
+		for _, episodeNumber := range sortedEpisodes {
+			observation, ok := byEpisode[episodeNumber]
+			if !ok {
+				flush()
+				continue
+			}
+			if len(cluster) > 0 &&
+				(absInt32(observation.StartSeconds-cluster[0].StartSeconds) > tolerance ||
+					absInt32(observation.EndSeconds-cluster[0].EndSeconds) > tolerance) {
+				flush()
+			}
+			cluster = append(cluster, observation)
+		}
+		flush()
+	}
+
+	return proposals
+}
+
+func buildThemeSegmentProposal(
+	kind string,
+	cluster []models.ThemeSegmentObservation,
+	existing []models.AdminThemeSegment,
+	themes []models.AdminAnimeTheme,
+	tolerance int32,
+) models.ThemeSegmentProposal {
+	starts := make([]int32, 0, len(cluster))
+	ends := make([]int32, 0, len(cluster))
+	episodes := make([]int, 0, len(cluster))
+	for _, observation := range cluster {
+		starts = append(starts, observation.StartSeconds)
+		ends = append(ends, observation.EndSeconds)
+		episodes = append(episodes, observation.EpisodeNumber)
+	}
+	start := medianInt32(starts)
+	end := medianInt32(ends)
+
+	var deviation int32
+	for _, observation := range cluster {
+		deviation = max(deviation, absInt32(observation.StartSeconds-start), absInt32(observation.EndSeconds-end))
+	}
+
+	proposal := models.ThemeSegmentProposal{
+		Kind:                kind,
+		StartEpisode:        episodes[0],
+		EndEpisode:          episodes[len(episodes)-1],
+		Episodes:            episodes,
+		StartTime:           FormatSegmentClock(start),
+		EndTime:             FormatSegmentClock(end),
+		MaxDeviationSeconds: deviation,
+		Status:              models.ThemeSegmentProposalStatusNew,
+		Conflicts:           FindThemeSegmentConflicts(kind, episodes[0], episodes[len(episodes)-1], start, end, existing, tolerance),
+	}
+
+	for _, conflict := range proposal.Conflicts {
+		if conflict.Reason == models.ThemeSegmentConflictOverlap {
+			proposal.Status = models.ThemeSegmentProposalStatusConflict
+			break
+		}
+		proposal.Status = models.ThemeSegmentProposalStatusDuplicate
+	}
+
+	// Vorgeschlagen wird das Theme eines kollidierenden Segments, sonst das erste passende Theme.
+	if len(proposal.Conflicts) > 0 {
+		themeID := proposal.Conflicts[0].ThemeID
+		proposal.SuggestedThemeID = &themeID
+	} else {
+		for _, theme := range themes {
+			if ThemeSegmentKind(theme.ThemeTypeName) == kind {
+				themeID := theme.ID
+				proposal.SuggestedThemeID = &themeID
+				break
+			}
+		}
+	}
+
+	return proposal
+}
+
+// FindThemeSegmentConflicts liefert die bestehenden Segmente derselben Art, deren Episodenbereich
+// sich mit [startEpisode, endEpisode] überschneidet. Deckt ein Segment den Bereich vollständig ab
+// und weichen die Zeiten höchstens tolerance Sekunden ab, ist es ein Duplikat, sonst ein Überlapp.
+func FindThemeSegmentConflicts(
+	kind string,
+	startEpisode int,
+	endEpisode int,
+	startSeconds int32,
+	endSeconds int32,
+	existing []models.AdminThemeSegment,
+	tolerance int32,
+) []models.ThemeSegmentProposalConflict {
+	conflicts := make([]models.ThemeSegmentProposalConflict, 0)
+	for _, segment := range existing {
+		if ThemeSegmentKind(segment.ThemeTypeName) != kind {
+			continue
+		}
+		segmentStart, segmentEnd := math.MinInt, math.MaxInt
+		if segment.StartEpisode != nil {
+			segmentStart = *segment.StartEpisode
+		}
+		if segment.EndEpisode != nil {
+			segmentEnd = *segment.EndEpisode
+		}
+		if segmentStart > endEpisode || segmentEnd < startEpisode {
+			continue
+		}
+
+		reason := models.ThemeSegmentConflictOverlap
+		existingStart, startOK := ParseSegmentClock(segment.StartTime)
+		existingEnd, endOK := ParseSegmentClock(segment.EndTime)
+		if segmentStart <= startEpisode && segmentEnd >= endEpisode &&
+			startOK && endOK &&
+			absInt32(existingStart-startSeconds) <= tolerance &&
+			absInt32(existingEnd-endSeconds) <= tolerance {
+			reason = models.ThemeSegmentConflictDuplicate
+		}
+
+		conflicts = append(conflicts, models.ThemeSegmentProposalConflict{
+			SegmentID:     segment.ID,
+			ThemeID:       segment.ThemeID,
+			ThemeTypeName: segment.ThemeTypeName,
+			StartEpisode:  segment.StartEpisode,
+			EndEpisode:    segment.EndEpisode,
+			StartTime:     segment.StartTime,
+			EndTime:       segment.EndTime,
+			Reason:        reason,
+		})
+	}
+	return conflicts
+}
+
+// FormatSegmentClock formatiert Sekunden als HH:MM:SS wie theme_segments.start_time.
+func FormatSegmentClock(seconds int32) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// ParseSegmentClock liest HH:MM:SS, MM:SS oder SS; Bruchteile von Sekunden werden abgeschnitten.
+func ParseSegmentClock(raw *string) (int32, bool) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return 0, false
+	}
+	parts := strings.Split(strings.TrimSpace(*raw), ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+	var total int32
+	for _, part := range parts {
+		whole, _, _ := strings.Cut(strings.TrimSpace(part), ".")
+		value, err := strconv.Atoi(whole)
+		if err != nil || value < 0 {
+			return 0, false
+		}
+		total = total*60 + int32(value)
+	}
+	return total, true
+}
+
+func ticksToRoundedSeconds(ticks int64) int32 {
+	return int32((ticks + jellyfinTicksPerSecond/2) / jellyfinTicksPerSecond)
+}
+
+func medianInt32(values []int32) int32 {
+	sorted := append([]int32(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)-1)/2]
+}
+
+func absInt32(value int32) int32 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
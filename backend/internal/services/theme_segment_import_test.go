@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/models"
+)
+
+func themeSegmentImportTestObservation(episode int, kind string, start int32, end int32) models.ThemeSegmentObservation {
+	return models.ThemeSegmentObservation{EpisodeNumber: episode, Kind: kind, StartSeconds: start, EndSeconds: end}
+}
+
+func TestThemeSegmentObservationsFromMediaSegments(t *testing.T) {
+	observations := ThemeSegmentObservationsFromMediaSegments(4, []mediaserver.MediaSegment{
+		{Type: mediaserver.MediaSegmentTypeOutro, StartTicks: 13_000_000_000, EndTicks: 13_900_000_000},
+		{Type: mediaserver.MediaSegmentTypeIntro, StartTicks: 10_000_000, EndTicks: 50_000_000},
+		{Type: mediaserver.MediaSegmentTypeIntro, StartTicks: 899_000_000, EndTicks: 1_800_400_000},
+		{Type: "Commercial", StartTicks: 0, EndTicks: 100_000_000},
+	})
+
+	if len(observations) != 2 {
+		t.Fatalf("expected intro and outro, got %+v", observations)
+	}
+	if observations[0] != themeSegmentImportTestObservation(4, models.SkipMarkerKindIntro, 90, 180) {
+		t.Fatalf("expected longest intro rounded to seconds, got %+v", observations[0])
+	}
+	if observations[1] != themeSegmentImportTestObservation(4, models.SkipMarkerKindOutro, 1300, 1390) {
+		t.Fatalf("unexpected outro %+v", observations[1])
+	}
+}
+
+func TestBuildThemeSegmentProposalsClustersConsistentEpisodes(t *testing.T) {
+	observations := []models.ThemeSegmentObservation{
+		themeSegmentImportTestObservation(1, models.SkipMarkerKindIntro, 90, 180),
+		themeSegmentImportTestObservation(2, models.SkipMarkerKindIntro, 92, 181),
+		themeSegmentImportTestObservation(3, models.SkipMarkerKindIntro, 89, 179),
+		// Neues Opening ab Episode 4.
+		themeSegmentImportTestObservation(4, models.SkipMarkerKindIntro, 30, 120),
+		themeSegmentImportTestObservation(5, models.SkipMarkerKindIntro, 31, 121),
+		// Episode 6 ohne Segment trennt den Bereich.
+		themeSegmentImportTestObservation(7, models.SkipMarkerKindIntro, 30, 120),
+		themeSegmentImportTestObservation(1, models.SkipMarkerKindOutro, 1300, 1390),
+	}
+	themes := []models.AdminAnimeTheme{
+		{ID: 8, ThemeTypeName: "ED Kara"},
+		{ID: 7, ThemeTypeName: "OP Kara"},
+	}
+
+	proposals := BuildThemeSegmentProposals([]int{7, 6, 5, 4, 3, 2, 1}, observations, nil, themes, 3)
+	if len(proposals) != 4 {
+		t.Fatalf("expected 4 proposals, got %+v", proposals)
+	}
+
+	first := proposals[0]
+	if first.Kind != models.SkipMarkerKindIntro || first.StartEpisode != 1 || first.EndEpisode != 3 ||
+		first.StartTime != "00:01:30" || first.EndTime != "00:03:00" || first.MaxDeviationSeconds != 2 {
+		t.Fatalf("unexpected first proposal %+v", first)
+	}
+	if first.Status != models.ThemeSegmentProposalStatusNew || first.SuggestedThemeID == nil || *first.SuggestedThemeID != 7 {
+		t.Fatalf("expected new proposal for opening theme, got %+v", first)
+	}
+	if proposals[1].StartEpisode != 4 || proposals[1].EndEpisode != 5 || proposals[2].StartEpisode != 7 {
+		t.Fatalf("expected split at new opening and missing episode, got %+v", proposals[1:3])
+	}
+	if outro := proposals[3]; outro.Kind != models.SkipMarkerKindOutro || outro.SuggestedThemeID == nil || *outro.SuggestedThemeID != 8 {
+		t.Fatalf("unexpected outro proposal %+v", outro)
+	}
+}
+
+func TestBuildThemeSegmentProposalsFlagsConflicts(t *testing.T) {
+	one, two, five := 1, 2, 5
+	start, end := "00:01:31", "00:03:00"
+	otherStart, otherEnd := "00:00:30", "00:02:00"
+	existing := []models.AdminThemeSegment{
+		{ID: 50, ThemeID: 7, ThemeTypeName: "OP Kara", StartEpisode: &one, EndEpisode: &five, StartTime: &start, EndTime: &end},
+		{ID: 51, ThemeID: 9, ThemeTypeName: "ED Kara", StartEpisode: &one, EndEpisode: &two, StartTime: &otherStart, EndTime: &otherEnd},
+	}
+	observations := []models.ThemeSegmentObservation{
+		themeSegmentImportTestObservation(1, models.SkipMarkerKindIntro, 90, 180),
+		themeSegmentImportTestObservation(2, models.SkipMarkerKindIntro, 90, 180),
+		themeSegmentImportTestObservation(2, models.SkipMarkerKindOutro, 1300, 1390),
+		themeSegmentImportTestObservation(3, models.SkipMarkerKindOutro, 1300, 1390),
+		themeSegmentImportTestObservation(6, models.SkipMarkerKindIntro, 90, 180),
+	}
+
+	proposals := BuildThemeSegmentProposals([]int{1, 2, 3, 4, 5, 6}, observations, existing, nil, 3)
+	if len(proposals) != 3 {
+		t.Fatalf("expected 3 proposals, got %+v", proposals)
+	}
+	if duplicate := proposals[0]; duplicate.Status != models.ThemeSegmentProposalStatusDuplicate ||
+		len(duplicate.Conflicts) != 1 || duplicate.Conflicts[0].SegmentID != 50 || *duplicate.SuggestedThemeID != 7 {
+		t.Fatalf("expected duplicate of segment 50, got %+v", duplicate)
+	}
+	if fresh := proposals[1]; fresh.StartEpisode != 6 || fresh.Status != models.ThemeSegmentProposalStatusNew || fresh.SuggestedThemeID != nil {
+		t.Fatalf("expected unmatched new proposal for episode 6, got %+v", fresh)
+	}
+	if conflict := proposals[2]; conflict.Status != models.ThemeSegmentProposalStatusConflict ||
+		conflict.Conflicts[0].Reason != models.ThemeSegmentConflictOverlap || conflict.Conflicts[0].SegmentID != 51 {
+		t.Fatalf("expected overlap with segment 51, got %+v", conflict)
+	}
+}
+
+func TestFindThemeSegmentConflictsTreatsOpenRangesAsUnbounded(t *testing.T) {
+	existing := []models.AdminThemeSegment{{ID: 3, ThemeTypeName: "Outro"}}
+
+	if conflicts := FindThemeSegmentConflicts(models.SkipMarkerKindOutro, 10, 12, 0, 60, existing, 0); len(conflicts) != 1 {
+		t.Fatalf("expected open segment to conflict, got %+v", conflicts)
+	}
+	if conflicts := FindThemeSegmentConflicts(models.SkipMarkerKindIntro, 10, 12, 0, 60, existing, 0); len(conflicts) != 0 {
+		t.Fatalf("expected other kinds to be ignored, got %+v", conflicts)
+	}
+}
+
+func TestSegmentClockRoundTrip(t *testing.T) {
+	if got := FormatSegmentClock(3725); got != "01:02:05" {
+		t.Fatalf("unexpected clock %q", got)
+	}
+	for raw, want := range map[string]int32{"01:02:05": 3725, "02:05": 125, "00:01:30.500": 90} {
+		value := raw
+		if got, ok := ParseSegmentClock(&value); !ok || got != want {
+			t.Fatalf("parse %q: got %d %v", raw, got, ok)
+		}
+	}
+	invalid := "ab:cd"
+	if _, ok := ParseSegmentClock(&invalid); ok {
+		t.Fatalf("expected invalid clock to fail")
+	}
+}
@@ -0,0 +1,529 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // registriert den JPEG-Decoder für image.DecodeConfig
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/blobstore"
+	"team4s.v3/backend/internal/mediaserver"
+	"team4s.v3/backend/internal/models"
+)
+
+const (
+	trickplayStorageSegment = "trickplay"
+	trickplaySpritePattern  = "sprite_%04d.jpg"
+	trickplayVTTFileName    = "thumbnails.vtt"
+	trickplayMaxErrorLength = 1000
+)
+
+// ErrTrickplaySourceUnavailable signalisiert eine Variante, deren Quelle ffmpeg nicht lesen kann.
+var ErrTrickplaySourceUnavailable = errors.New("trickplay source unavailable")
+
+// TrickplayStore ist die Datenbankschnittstelle des TrickplayWorker
+// (implementiert von repository.TrickplayRepository).
+type TrickplayStore interface {
+	ClaimTrickplayCandidates(
+		ctx context.Context,
+		limit int,
+		intervalSeconds int32,
+		retryAfter time.Duration,
+		staleAfter time.Duration,
+		maxAttempts int,
+	) ([]models.TrickplayCandidate, error)
+	SaveTrickplayResult(ctx context.Context, variantID int64, result models.TrickplayResult) error
+	MarkTrickplayFailed(ctx context.Context, variantID int64, message string) error
+}
+
+// TrickplayConfig steuert Zeitplan und Sprite-Layout. Werte <= 0 nutzen die Defaults.
+type TrickplayConfig struct {
+	FFmpegPath      string
+	StorageDir      string        // Medienverzeichnis; Sprites landen unter trickplay/variant_<id>/
+	PublicBaseURL   string        // Basis-URL der Sprite-Verweise in der WebVTT-Spur
+	PollInterval    time.Duration // Abstand zwischen zwei Durchläufen (Default 15min)
+	InitialDelay    time.Duration // Wartezeit vor dem ersten Lauf nach dem Start (Default 2min)
+	BatchSize       int           // Varianten pro Durchlauf (Default 2)
+	IntervalSeconds int           // Abstand zwischen zwei Vorschaubildern (Default 10s)
+	TileWidth       int           // Breite eines Vorschaubildes in Pixeln (Default 320)
+	TileColumns     int           // Kacheln pro Zeile eines Sprites (Default 10)
+	TileRows        int           // Kachelzeilen pro Sprite (Default 10)
+	GenerateTimeout time.Duration // Obergrenze für einen ffmpeg-Lauf (Default 30min)
+	RetryAfter      time.Duration // Wartezeit vor einem erneuten Versuch nach Fehlern (Default 6h)
+	MaxAttempts     int           // Versuche je Variante (Default 3)
+}
+
+func (c TrickplayConfig) withDefaults() TrickplayConfig {
+	c.StorageDir = strings.TrimSpace(c.StorageDir)
+	if c.StorageDir == "" {
+		c.StorageDir = "./storage/media"
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 15 * time.Minute
+	}
+	if c.InitialDelay <= 0 {
+		c.InitialDelay = 2 * time.Minute
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 2
+	}
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = 10
+	}
+	if c.TileWidth <= 0 {
+		c.TileWidth = 320
+	}
+	if c.TileColumns <= 0 {
+		c.TileColumns = 10
+	}
+	if c.TileRows <= 0 {
+		c.TileRows = 10
+	}
+	if c.GenerateTimeout <= 0 {
+		c.GenerateTimeout = 30 * time.Minute
+	}
+	if c.RetryAfter <= 0 {
+		c.RetryAfter = 6 * time.Hour
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	return c
+}
+
+// TrickplaySources löst die Quelle einer Variante in einen für ffmpeg lesbaren Pfad bzw. eine
+// URL auf: lokale Dateien über die LocalMediaLibrary, sonst die gespeicherte Stream-URL oder die
+// Direct-Stream-URL des Media-Servers.
+type TrickplaySources struct {
+	local   *LocalMediaLibrary
+	servers map[string]mediaserver.MediaServerProvider
+}
+
+// NewTrickplaySources erstellt einen Resolver; nicht konfigurierte Media-Server werden ignoriert.
+func NewTrickplaySources(local *LocalMediaLibrary, servers ...mediaserver.MediaServerProvider) *TrickplaySources {
+	sources := &TrickplaySources{local: local, servers: map[string]mediaserver.MediaServerProvider{}}
+	for _, server := range servers {
+		if server == nil || !server.Configured() {
+			continue
+		}
+		if _, exists := sources.servers[server.Kind()]; !exists {
+			sources.servers[server.Kind()] = server
+		}
+	}
+	return sources
+}
+
+// trickplaySecretQueryParameters tragen Zugangsdaten der Media-Server in Upstream-URLs.
+var trickplaySecretQueryParameters = []string{"api_key", "ApiKey", "X-Emby-Token", "X-MediaBrowser-Token"}
+
+// TrickplaySource ist die ffmpeg-Eingabe einer Variante. Zugangsdaten der Media-Server stehen
+// nicht in Input, sondern in Headers; secrets werden aus ffmpeg-Fehlern entfernt.
+type TrickplaySource struct {
+	Input   string
+	Headers string
+	secrets []string
+}
+
+// Remote meldet, ob ffmpeg die Quelle über HTTP(S) liest.
+func (s TrickplaySource) Remote() bool {
+	lower := strings.ToLower(s.Input)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// Redact ersetzt Zugangsdaten der Quelle in message, bevor sie geloggt oder gespeichert wird.
+func (s TrickplaySource) Redact(message string) string {
+	for _, secret := range s.secrets {
+		message = strings.ReplaceAll(message, secret, "[redacted]")
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			message = strings.ReplaceAll(message, escaped, "[redacted]")
+		}
+	}
+	return message
+}
+
+// newRemoteTrickplaySource übernimmt target; bei Media-Server-Quellen (withToken) wandert der
+// API-Schlüssel aus der Query in den X-Emby-Token-Header (Jellyfin und Emby).
+func newRemoteTrickplaySource(target *url.URL, withToken bool) TrickplaySource {
+	var source TrickplaySource
+	query := target.Query()
+	for _, name := range trickplaySecretQueryParameters {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		source.secrets = append(source.secrets, value)
+		if withToken {
+			if source.Headers == "" {
+				source.Headers = "X-Emby-Token: " + value + "\r\n"
+			}
+			query.Del(name)
+		}
+	}
+	if withToken {
+		target.RawQuery = query.Encode()
+	}
+	source.Input = target.String()
+	return source
+}
+
+// Resolve liefert die ffmpeg-Eingabe für candidate oder ErrTrickplaySourceUnavailable.
+func (s *TrickplaySources) Resolve(candidate models.TrickplayCandidate) (TrickplaySource, error) {
+	if strings.EqualFold(strings.TrimSpace(candidate.MediaProvider), LocalMediaProviderName) {
+		if !s.local.Enabled() {
+			return TrickplaySource{}, fmt.Errorf("%w: local media root is not configured", ErrTrickplaySourceUnavailable)
+		}
+		file, err := s.local.Stat(candidate.MediaItemID)
+		if err != nil {
+			return TrickplaySource{}, fmt.Errorf("%w: %v", ErrTrickplaySourceUnavailable, err)
+		}
+		return TrickplaySource{Input: file.AbsolutePath}, nil
+	}
+
+	if candidate.StreamURL != nil {
+		if trimmed := strings.TrimSpace(*candidate.StreamURL); trimmed != "" {
+			target, err := url.Parse(trimmed)
+			if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+				return TrickplaySource{}, fmt.Errorf("%w: stream url is not http(s)", ErrTrickplaySourceUnavailable)
+			}
+			_, fromServer := s.servers[mediaserver.NormalizeKind(candidate.MediaProvider)]
+			return newRemoteTrickplaySource(target, fromServer), nil
+		}
+	}
+
+	server, ok := s.servers[mediaserver.NormalizeKind(candidate.MediaProvider)]
+	if !ok {
+		return TrickplaySource{}, fmt.Errorf("%w: provider %q is not configured", ErrTrickplaySourceUnavailable, candidate.MediaProvider)
+	}
+	streamURL, err := server.StreamURL(candidate.MediaItemID)
+	if err != nil {
+		return TrickplaySource{}, fmt.Errorf("%w: %v", ErrTrickplaySourceUnavailable, err)
+	}
+	target, err := url.Parse(streamURL)
+	if err != nil {
+		return TrickplaySource{}, fmt.Errorf("%w: invalid stream url", ErrTrickplaySourceUnavailable)
+	}
+	server.Authorize(target)
+	return newRemoteTrickplaySource(target, true), nil
+}
+
+// TrickplayWorker erzeugt für Release-Varianten gekachelte JPEG-Sprites und eine WebVTT-Spur für
+// Vorschaubilder auf der Zeitleiste. Jede Erzeugung landet in einem eigenen Ordner, damit die
+// Dateien einer früheren Erzeugung erst vom Media-Cleanup entfernt werden.
+type TrickplayWorker struct {
+	store     TrickplayStore
+	sources   *TrickplaySources
+	cfg       TrickplayConfig
+	blobs     blobstore.BlobStore
+	now       func() time.Time
+	runFFmpeg func(ctx context.Context, args []string) error
+}
+
+// NewTrickplayWorker erstellt einen Worker für store.
+func NewTrickplayWorker(store TrickplayStore, sources *TrickplaySources, cfg TrickplayConfig) *TrickplayWorker {
+	w := &TrickplayWorker{
+		store:   store,
+		sources: sources,
+		cfg:     cfg.withDefaults(),
+		now:     time.Now,
+	}
+	w.blobs = blobstore.NewLocalStore(w.cfg.StorageDir)
+	w.runFFmpeg = w.execFFmpeg
+	return w
+}
+
+// WithBlobStore setzt die Ablage für Sprites und WebVTT (Standard: StorageDir im Dateisystem).
+func (w *TrickplayWorker) WithBlobStore(store blobstore.BlobStore) *TrickplayWorker {
+	if store != nil {
+		w.blobs = store
+	}
+	return w
+}
+
+// Run erzeugt Sprites nach InitialDelay und danach alle PollInterval, bis ctx endet.
+func (w *TrickplayWorker) Run(ctx context.Context) {
+	timer := time.NewTimer(w.cfg.InitialDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		w.RunOnce(ctx)
+		timer.Reset(w.cfg.PollInterval)
+	}
+}
+
+// RunOnce beansprucht einen Batch Varianten und erzeugt deren Sprites. Gibt die Anzahl der
+// erfolgreich erzeugten Trickplays zurück.
+func (w *TrickplayWorker) RunOnce(ctx context.Context) int {
+	// Hängt eine Erzeugung länger als alle ffmpeg-Läufe des Batches, darf eine andere Instanz sie
+	// erneut beanspruchen.
+	staleAfter := w.cfg.GenerateTimeout*time.Duration(w.cfg.BatchSize) + 5*time.Minute
+	candidates, err := w.store.ClaimTrickplayCandidates(
+		ctx,
+		w.cfg.BatchSize,
+		int32(w.cfg.IntervalSeconds),
+		w.cfg.RetryAfter,
+		staleAfter,
+		w.cfg.MaxAttempts,
+	)
+	if err != nil {
+		log.Printf("trickplay: claim candidates: %v", err)
+		return 0
+	}
+
+	generated := 0
+	for _, candidate := range candidates {
+		if err := w.generate(ctx, candidate); err != nil {
+			log.Printf("trickplay: variant %d failed: %v", candidate.ReleaseVariantID, err)
+			if markErr := w.store.MarkTrickplayFailed(ctx, candidate.ReleaseVariantID, truncateTrickplayError(err.Error())); markErr != nil {
+				log.Printf("trickplay: mark variant %d failed: %v", candidate.ReleaseVariantID, markErr)
+			}
+			continue
+		}
+		generated++
+	}
+	return generated
+}
+
+func (w *TrickplayWorker) generate(ctx context.Context, candidate models.TrickplayCandidate) error {
+	source, err := w.sources.Resolve(candidate)
+	if err != nil {
+		return err
+	}
+
+	// Pfad relativ zum Medienverzeichnis, mit "/" getrennt wie in media_assets.file_path.
+	relativeDir := path.Join(
+		trickplayStorageSegment,
+		fmt.Sprintf("variant_%d", candidate.ReleaseVariantID),
+		strconv.FormatInt(w.now().UnixNano(), 10),
+	)
+	// ffmpeg schreibt in ein lokales Arbeitsverzeichnis; danach landen Sprites und WebVTT im
+	// BlobStore.
+	outputDir, err := os.MkdirTemp("", "trickplay-*")
+	if err != nil {
+		return fmt.Errorf("create trickplay dir: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	runCtx, cancel := context.WithTimeout(ctx, w.cfg.GenerateTimeout)
+	defer cancel()
+	if err := w.runFFmpeg(runCtx, w.ffmpegArgs(source, filepath.Join(outputDir, trickplaySpritePattern))); err != nil {
+		// ffmpeg gibt die Eingabe-URL in Fehlern wieder; Zugangsdaten dürfen weder ins Log noch
+		// nach release_variant_trickplay.error_message.
+		return errors.New(source.Redact(err.Error()))
+	}
+
+	spriteNames, err := filepath.Glob(filepath.Join(outputDir, "sprite_*.jpg"))
+	if err != nil {
+		return fmt.Errorf("list trickplay sprites: %w", err)
+	}
+	if len(spriteNames) == 0 {
+		return fmt.Errorf("ffmpeg produced no sprites")
+	}
+	sort.Strings(spriteNames)
+
+	tileHeight, err := trickplayTileHeight(spriteNames[0], w.cfg.TileRows)
+	if err != nil {
+		return err
+	}
+
+	perSprite := w.cfg.TileColumns * w.cfg.TileRows
+	thumbnailCount := len(spriteNames) * perSprite
+	if candidate.DurationSeconds != nil && *candidate.DurationSeconds > 0 {
+		byDuration := (int(*candidate.DurationSeconds) + w.cfg.IntervalSeconds - 1) / w.cfg.IntervalSeconds
+		if byDuration > (len(spriteNames)-1)*perSprite && byDuration < thumbnailCount {
+			thumbnailCount = byDuration
+		}
+	}
+
+	spritePaths := make([]string, 0, len(spriteNames))
+	spriteURLs := make([]string, 0, len(spriteNames))
+	for _, name := range spriteNames {
+		filePath := "/media/" + path.Join(relativeDir, filepath.Base(name))
+		spritePaths = append(spritePaths, filePath)
+		spriteURLs = append(spriteURLs, strings.TrimRight(strings.TrimSpace(w.cfg.PublicBaseURL), "/")+filePath)
+	}
+
+	vtt := BuildTrickplayVTT(spriteURLs, w.cfg.IntervalSeconds, w.cfg.TileWidth, tileHeight, w.cfg.TileColumns, w.cfg.TileRows, thumbnailCount)
+	if err := os.WriteFile(filepath.Join(outputDir, trickplayVTTFileName), []byte(vtt), 0o644); err != nil {
+		return fmt.Errorf("write trickplay vtt: %w", err)
+	}
+
+	keys := make([]string, 0, len(spriteNames)+1)
+	keep := false
+	defer func() {
+		if keep {
+			return
+		}
+		for _, key := range keys {
+			_ = w.blobs.Delete(ctx, key)
+		}
+	}()
+	for _, name := range append(spriteNames, filepath.Join(outputDir, trickplayVTTFileName)) {
+		key := path.Join(relativeDir, filepath.Base(name))
+		keys = append(keys, key)
+		if err := w.putTrickplayFile(ctx, key, name); err != nil {
+			return fmt.Errorf("store trickplay file: %w", err)
+		}
+	}
+
+	if err := w.store.SaveTrickplayResult(ctx, candidate.ReleaseVariantID, models.TrickplayResult{
+		IntervalSeconds: int32(w.cfg.IntervalSeconds),
+		TileWidth:       int32(w.cfg.TileWidth),
+		TileHeight:      int32(tileHeight),
+		TileColumns:     int32(w.cfg.TileColumns),
+		TileRows:        int32(w.cfg.TileRows),
+		ThumbnailCount:  int32(thumbnailCount),
+		SpriteFilePaths: spritePaths,
+		VTTFilePath:     "/media/" + path.Join(relativeDir, trickplayVTTFileName),
+	}); err != nil {
+		return fmt.Errorf("save trickplay: %w", err)
+	}
+	keep = true
+	log.Printf("trickplay: variant %d ready (sprites=%d, thumbnails=%d)", candidate.ReleaseVariantID, len(spriteNames), thumbnailCount)
+	return nil
+}
+
+// putTrickplayFile legt die lokal erzeugte Datei localPath unter key im BlobStore ab.
+func (w *TrickplayWorker) putTrickplayFile(ctx context.Context, key string, localPath string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	contentType := "image/jpeg"
+	if path.Ext(key) == ".vtt" {
+		contentType = "text/vtt"
+	}
+	return w.blobs.Put(ctx, key, file, info.Size(), contentType)
+}
+
+func (w *TrickplayWorker) ffmpegArgs(source TrickplaySource, outputPattern string) []string {
+	filter := fmt.Sprintf(
+		"fps=1/%d,scale=%d:-2,tile=%dx%d",
+		w.cfg.IntervalSeconds,
+		w.cfg.TileWidth,
+		w.cfg.TileColumns,
+		w.cfg.TileRows,
+	)
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-nostdin",
+	}
+	// Die Eingabe darf nur das erwartete Protokoll öffnen, damit präparierte Container oder
+	// Playlists keine lokalen Dateien bzw. internen Dienste nachladen.
+	if source.Remote() {
+		args = append(args, "-protocol_whitelist", "http,https,tcp,tls")
+		if source.Headers != "" {
+			args = append(args, "-headers", source.Headers)
+		}
+	} else {
+		args = append(args, "-protocol_whitelist", "file")
+	}
+	return append(args,
+		"-i", source.Input,
+		"-an", "-sn",
+		"-vf", filter,
+		"-q:v", "5",
+		"-f", "image2",
+		outputPattern,
+	)
+}
+
+func (w *TrickplayWorker) execFFmpeg(ctx context.Context, args []string) error {
+	if strings.TrimSpace(w.cfg.FFmpegPath) == "" {
+		return fmt.Errorf("ffmpeg is not configured")
+	}
+	output, err := exec.CommandContext(ctx, w.cfg.FFmpegPath, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// BuildTrickplayVTT erzeugt eine WebVTT-Spur, deren Cues per Media-Fragment (#xywh) auf die
+// Kacheln der Sprites verweisen. Die Kacheln sind zeilenweise von links oben angeordnet.
+func BuildTrickplayVTT(spriteURLs []string, intervalSeconds, tileWidth, tileHeight, columns, rows, thumbnailCount int) string {
+	var builder strings.Builder
+	builder.WriteString("WEBVTT\n")
+
+	perSprite := columns * rows
+	for index := 0; index < thumbnailCount && perSprite > 0; index++ {
+		sprite := index / perSprite
+		if sprite >= len(spriteURLs) {
+			break
+		}
+		tile := index % perSprite
+		start := time.Duration(index*intervalSeconds) * time.Second
+		end := start + time.Duration(intervalSeconds)*time.Second
+		fmt.Fprintf(
+			&builder,
+			"\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			formatVTTTimestamp(start),
+			formatVTTTimestamp(end),
+			spriteURLs[sprite],
+			(tile%columns)*tileWidth,
+			(tile/columns)*tileHeight,
+			tileWidth,
+			tileHeight,
+		)
+	}
+	return builder.String()
+}
+
+func formatVTTTimestamp(value time.Duration) string {
+	milliseconds := value.Milliseconds()
+	return fmt.Sprintf(
+		"%02d:%02d:%02d.%03d",
+		milliseconds/3_600_000,
+		milliseconds/60_000%60,
+		milliseconds/1000%60,
+		milliseconds%1000,
+	)
+}
+
+// trickplayTileHeight liest die Sprite-Höhe; der tile-Filter füllt jedes Sprite auf das volle
+// Raster auf, daher ist die Kachelhöhe Höhe/rows.
+func trickplayTileHeight(spritePath string, rows int) (int, error) {
+	file, err := os.Open(spritePath)
+	if err != nil {
+		return 0, fmt.Errorf("open trickplay sprite: %w", err)
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, fmt.Errorf("decode trickplay sprite: %w", err)
+	}
+	if config.Height < rows {
+		return 0, fmt.Errorf("trickplay sprite too small (%dx%d)", config.Width, config.Height)
+	}
+	return config.Height / rows, nil
+}
+
+func truncateTrickplayError(message string) string {
+	runes := []rune(strings.TrimSpace(message))
+	if len(runes) <= trickplayMaxErrorLength {
+		return string(runes)
+	}
+	return string(runes[:trickplayMaxErrorLength])
+}
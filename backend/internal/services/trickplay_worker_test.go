@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/mediaserver/mediaservertest"
+	"team4s.v3/backend/internal/models"
+)
+
+type fakeTrickplayStore struct {
+	candidates []models.TrickplayCandidate
+	saved      map[int64]models.TrickplayResult
+	failed     map[int64]string
+}
+
+func newFakeTrickplayStore(candidates ...models.TrickplayCandidate) *fakeTrickplayStore {
+	return &fakeTrickplayStore{
+		candidates: candidates,
+		saved:      map[int64]models.TrickplayResult{},
+		failed:     map[int64]string{},
+	}
+}
+
+func (s *fakeTrickplayStore) ClaimTrickplayCandidates(_ context.Context, limit int, _ int32, _ time.Duration, _ time.Duration, _ int) ([]models.TrickplayCandidate, error) {
+	if len(s.candidates) > limit {
+		return s.candidates[:limit], nil
+	}
+	return s.candidates, nil
+}
+
+func (s *fakeTrickplayStore) SaveTrickplayResult(_ context.Context, variantID int64, result models.TrickplayResult) error {
+	s.saved[variantID] = result
+	return nil
+}
+
+func (s *fakeTrickplayStore) MarkTrickplayFailed(_ context.Context, variantID int64, message string) error {
+	s.failed[variantID] = message
+	return nil
+}
+
+type fakeTrickplayCleanupStore struct {
+	assets  []models.TrickplayOrphanAsset
+	deleted []int64
+}
+
+func (s *fakeTrickplayCleanupStore) ListOrphanedTrickplayAssets(_ context.Context, _ int) ([]models.TrickplayOrphanAsset, error) {
+	return s.assets, nil
+}
+
+func (s *fakeTrickplayCleanupStore) DeleteOrphanedTrickplayAssets(_ context.Context, ids []int64) error {
+	s.deleted = append(s.deleted, ids...)
+	return nil
+}
+
+func writeTestSprite(t *testing.T, filePath string, width int, height int) {
+	t.Helper()
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatalf("create sprite: %v", err)
+	}
+	defer file.Close()
+	if err := jpeg.Encode(file, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("encode sprite: %v", err)
+	}
+}
+
+func TestBuildTrickplayVTT(t *testing.T) {
+	vtt := BuildTrickplayVTT([]string{"https://cdn/a.jpg", "https://cdn/b.jpg"}, 10, 160, 90, 2, 2, 5)
+
+	want := strings.Join([]string{
+		"WEBVTT",
+		"",
+		"00:00:00.000 --> 00:00:10.000",
+		"https://cdn/a.jpg#xywh=0,0,160,90",
+		"",
+		"00:00:10.000 --> 00:00:20.000",
+		"https://cdn/a.jpg#xywh=160,0,160,90",
+		"",
+		"00:00:20.000 --> 00:00:30.000",
+		"https://cdn/a.jpg#xywh=0,90,160,90",
+		"",
+		"00:00:30.000 --> 00:00:40.000",
+		"https://cdn/a.jpg#xywh=160,90,160,90",
+		"",
+		"00:00:40.000 --> 00:00:50.000",
+		"https://cdn/b.jpg#xywh=0,0,160,90",
+		"",
+	}, "\n")
+	if vtt != want {
+		t.Fatalf("unexpected vtt:\n%s", vtt)
+	}
+	if got := formatVTTTimestamp(3*time.Hour + 2*time.Minute + 5*time.Second); got != "03:02:05.000" {
+		t.Fatalf("unexpected timestamp %q", got)
+	}
+}
+
+func TestTrickplayWorkerGeneratesSpritesAndVTT(t *testing.T) {
+	storageDir := t.TempDir()
+	direct := "https://cdn.example/video.mkv"
+	duration := int32(125)
+	store := newFakeTrickplayStore(
+		models.TrickplayCandidate{ReleaseVariantID: 7, MediaProvider: "direct", StreamURL: &direct, DurationSeconds: &duration},
+		models.TrickplayCandidate{ReleaseVariantID: 8, MediaProvider: "youtube", MediaItemID: "abc"},
+	)
+	worker := NewTrickplayWorker(store, NewTrickplaySources(nil), TrickplayConfig{
+		StorageDir:    storageDir,
+		PublicBaseURL: "http://localhost:8092/",
+		TileWidth:     160,
+		TileColumns:   2,
+		TileRows:      2,
+	})
+	worker.now = func() time.Time { return time.Unix(0, 42) }
+
+	var gotArgs []string
+	worker.runFFmpeg = func(_ context.Context, args []string) error {
+		gotArgs = args
+		pattern := args[len(args)-1]
+		// 125s bei 10s Abstand ergeben 13 Bilder, also 4 Sprites à 4 Kacheln.
+		for index := 1; index <= 4; index++ {
+			writeTestSprite(t, fmt.Sprintf(pattern, index), 320, 180)
+		}
+		return nil
+	}
+
+	if generated := worker.RunOnce(context.Background()); generated != 1 {
+		t.Fatalf("expected one generated trickplay, got %d", generated)
+	}
+	if !strings.Contains(strings.Join(gotArgs, " "), "-i "+direct+" ") || !strings.Contains(strings.Join(gotArgs, " "), "fps=1/10,scale=160:-2,tile=2x2") {
+		t.Fatalf("unexpected ffmpeg args: %v", gotArgs)
+	}
+
+	result, ok := store.saved[7]
+	if !ok {
+		t.Fatalf("expected variant 7 to be saved")
+	}
+	if result.TileHeight != 90 || result.ThumbnailCount != 13 || len(result.SpriteFilePaths) != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.SpriteFilePaths[0] != "/media/trickplay/variant_7/42/sprite_0001.jpg" || result.VTTFilePath != "/media/trickplay/variant_7/42/thumbnails.vtt" {
+		t.Fatalf("unexpected paths: %+v", result)
+	}
+
+	vtt, err := os.ReadFile(filepath.Join(storageDir, "trickplay", "variant_7", "42", "thumbnails.vtt"))
+	if err != nil {
+		t.Fatalf("read vtt: %v", err)
+	}
+	if !strings.Contains(string(vtt), "00:02:00.000 --> 00:02:10.000\nhttp://localhost:8092/media/trickplay/variant_7/42/sprite_0004.jpg#xywh=0,0,160,90") {
+		t.Fatalf("unexpected vtt:\n%s", vtt)
+	}
+
+	if message := store.failed[8]; !strings.Contains(message, ErrTrickplaySourceUnavailable.Error()) {
+		t.Fatalf("expected unresolvable variant to fail, got %q", message)
+	}
+}
+
+func TestTrickplayWorkerRemovesOutputOnFailure(t *testing.T) {
+	storageDir := t.TempDir()
+	direct := "https://cdn.example/video.mkv"
+	store := newFakeTrickplayStore(models.TrickplayCandidate{ReleaseVariantID: 7, MediaProvider: "direct", StreamURL: &direct})
+	worker := NewTrickplayWorker(store, NewTrickplaySources(nil), TrickplayConfig{StorageDir: storageDir})
+	worker.runFFmpeg = func(_ context.Context, args []string) error {
+		writeTestSprite(t, fmt.Sprintf(args[len(args)-1], 1), 320, 180)
+		return errors.New("ffmpeg: exit status 1: invalid data")
+	}
+
+	if generated := worker.RunOnce(context.Background()); generated != 0 {
+		t.Fatalf("expected no generated trickplay, got %d", generated)
+	}
+	if !strings.Contains(store.failed[7], "invalid data") {
+		t.Fatalf("expected failure to be recorded, got %q", store.failed[7])
+	}
+	entries, err := os.ReadDir(filepath.Join(storageDir, "trickplay", "variant_7"))
+	if (err != nil && !os.IsNotExist(err)) || len(entries) != 0 {
+		t.Fatalf("expected no stored generation, got %v (%v)", entries, err)
+	}
+}
+
+func TestTrickplaySourcesResolveLocalFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "episode.mkv"), []byte("video"), 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+	sources := NewTrickplaySources(NewLocalMediaLibrary(root, "/usr/bin/ffmpeg"))
+
+	resolved, err := sources.Resolve(models.TrickplayCandidate{MediaProvider: "local", MediaItemID: "episode.mkv"})
+	if err != nil || filepath.Base(resolved.Input) != "episode.mkv" || resolved.Remote() {
+		t.Fatalf("expected local file, got %q (%v)", resolved, err)
+	}
+	if _, err := sources.Resolve(models.TrickplayCandidate{MediaProvider: "local", MediaItemID: "../secret.mkv"}); !errors.Is(err, ErrTrickplaySourceUnavailable) {
+		t.Fatalf("expected traversal to be rejected, got %v", err)
+	}
+	if _, err := sources.Resolve(models.TrickplayCandidate{MediaProvider: "jellyfin", MediaItemID: "item"}); !errors.Is(err, ErrTrickplaySourceUnavailable) {
+		t.Fatalf("expected unconfigured jellyfin to be unavailable, got %v", err)
+	}
+}
+
+func TestTrickplayWorkerKeepsMediaServerKeyOutOfURLAndErrors(t *testing.T) {
+	store := newFakeTrickplayStore(models.TrickplayCandidate{ReleaseVariantID: 7, MediaProvider: "jellyfin", MediaItemID: "item"})
+	worker := NewTrickplayWorker(store, NewTrickplaySources(nil, mediaservertest.NewProvider()), TrickplayConfig{StorageDir: t.TempDir()})
+
+	var gotArgs []string
+	worker.runFFmpeg = func(_ context.Context, args []string) error {
+		gotArgs = args
+		return errors.New("ffmpeg: exit status 1: http://media.test/Videos/item/stream?api_key=test-key&static=true: Server returned 404")
+	}
+
+	if generated := worker.RunOnce(context.Background()); generated != 0 {
+		t.Fatalf("expected no generated trickplay, got %d", generated)
+	}
+	joined := strings.Join(gotArgs, " ")
+	if !strings.Contains(joined, "-headers X-Emby-Token: test-key\r\n") || !strings.Contains(joined, "-protocol_whitelist http,https,tcp,tls") {
+		t.Fatalf("expected token header and protocol whitelist, got %v", gotArgs)
+	}
+	if !strings.Contains(joined, "-i http://media.test/Videos/item/stream?static=true ") {
+		t.Fatalf("expected input url without api key, got %v", gotArgs)
+	}
+	if message := store.failed[7]; strings.Contains(message, "test-key") || !strings.Contains(message, "api_key=[redacted]") {
+		t.Fatalf("expected redacted failure message, got %q", message)
+	}
+}
+
+func TestRVMCleanupRemovesOrphanedTrickplayAssets(t *testing.T) {
+	storageDir := t.TempDir()
+	generationDir := filepath.Join(storageDir, "trickplay", "variant_7", "42")
+	if err := os.MkdirAll(generationDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range []string{"sprite_0001.jpg", "thumbnails.vtt"} {
+		if err := os.WriteFile(filepath.Join(generationDir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	outside := filepath.Join(t.TempDir(), "keep.txt")
+	if err := os.WriteFile(outside, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write outside: %v", err)
+	}
+
+	trickplayStore := &fakeTrickplayCleanupStore{assets: []models.TrickplayOrphanAsset{
+		{MediaAssetID: 1, FilePath: "/media/trickplay/variant_7/42/sprite_0001.jpg"},
+		{MediaAssetID: 2, FilePath: "/media/trickplay/variant_7/42/thumbnails.vtt"},
+		{MediaAssetID: 3, FilePath: "/media/../../" + outside},
+	}}
+	NewRVMCleanupService(newMockRVMCleanupStore(), storageDir).
+		WithTrickplayCleanup(trickplayStore).
+		RunOnce(context.Background())
+
+	if len(trickplayStore.deleted) != 2 || trickplayStore.deleted[0] != 1 || trickplayStore.deleted[1] != 2 {
+		t.Fatalf("expected assets 1 and 2 to be deleted, got %v", trickplayStore.deleted)
+	}
+	if _, err := os.Stat(filepath.Join(storageDir, "trickplay", "variant_7")); !os.IsNotExist(err) {
+		t.Fatalf("expected emptied variant folder to be removed, got %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Fatalf("expected file outside storage dir to be kept: %v", err)
+	}
+}
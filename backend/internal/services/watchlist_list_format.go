@@ -0,0 +1,578 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// watchlistImportMaxNoteRunes entspricht der Notizlänge, die die Watchlist-API akzeptiert.
+const watchlistImportMaxNoteRunes = 2000
+
+// DetectWatchlistListFormat erkennt anhand des ersten Zeichens, ob eine Datei ein
+// MAL-XML-Export oder eine AniList-JSON-Liste ist. Gibt "" zurück, wenn beides nicht passt.
+func DetectWatchlistListFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return ""
+	}
+	switch trimmed[0] {
+	case '<':
+		return models.WatchlistListFormatMAL
+	case '{', '[':
+		return models.WatchlistListFormatAniList
+	default:
+		return ""
+	}
+}
+
+// ParseWatchlistListFile liest eine Liste im angegebenen Format. skipped zählt Einträge,
+// die weder eine externe ID noch einen Titel haben und deshalb nicht zuordenbar sind.
+func ParseWatchlistListFile(format string, data []byte) (entries []models.WatchlistImportEntry, skipped int, err error) {
+	switch format {
+	case models.WatchlistListFormatMAL:
+		return ParseMALListXML(data)
+	case models.WatchlistListFormatAniList:
+		return ParseAniListListJSON(data)
+	default:
+		return nil, 0, fmt.Errorf("unsupported watchlist list format %q", format)
+	}
+}
+
+// --- MyAnimeList XML ---
+
+type malListDocument struct {
+	XMLName xml.Name       `xml:"myanimelist"`
+	MyInfo  *malListMyInfo `xml:"myinfo,omitempty"`
+	Anime   []malListAnime `xml:"anime"`
+}
+
+type malListMyInfo struct {
+	UserExportType   int `xml:"user_export_type"`
+	TotalAnime       int `xml:"user_total_anime"`
+	TotalWatching    int `xml:"user_total_watching"`
+	TotalCompleted   int `xml:"user_total_completed"`
+	TotalOnHold      int `xml:"user_total_onhold"`
+	TotalDropped     int `xml:"user_total_dropped"`
+	TotalPlanToWatch int `xml:"user_total_plantowatch"`
+}
+
+// malListAnime nutzt beim Lesen reine Strings, weil MAL-Exporte je nach Alter Zahlen,
+// Texte und leere Elemente mischen.
+type malListAnime struct {
+	SeriesAnimeDBID   string `xml:"series_animedb_id"`
+	SeriesTitle       string `xml:"series_title"`
+	SeriesType        string `xml:"series_type"`
+	SeriesEpisodes    string `xml:"series_episodes"`
+	MyWatchedEpisodes string `xml:"my_watched_episodes"`
+	MyStartDate       string `xml:"my_start_date"`
+	MyFinishDate      string `xml:"my_finish_date"`
+	MyScore           string `xml:"my_score"`
+	MyStatus          string `xml:"my_status"`
+	MyComments        string `xml:"my_comments"`
+}
+
+type malListExportDocument struct {
+	XMLName xml.Name             `xml:"myanimelist"`
+	MyInfo  malListMyInfo        `xml:"myinfo"`
+	Anime   []malListExportAnime `xml:"anime"`
+}
+
+type malListExportAnime struct {
+	SeriesAnimeDBID   int64        `xml:"series_animedb_id"`
+	SeriesTitle       malListCDATA `xml:"series_title"`
+	SeriesType        string       `xml:"series_type"`
+	SeriesEpisodes    int          `xml:"series_episodes"`
+	MyID              int          `xml:"my_id"`
+	MyWatchedEpisodes int32        `xml:"my_watched_episodes"`
+	MyStartDate       string       `xml:"my_start_date"`
+	MyFinishDate      string       `xml:"my_finish_date"`
+	MyScore           int16        `xml:"my_score"`
+	MyStatus          string       `xml:"my_status"`
+	MyComments        malListCDATA `xml:"my_comments"`
+	UpdateOnImport    int          `xml:"update_on_import"`
+}
+
+type malListCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+// ParseMALListXML liest den XML-Export von MyAnimeList (Profil → Export → Anime List).
+func ParseMALListXML(data []byte) ([]models.WatchlistImportEntry, int, error) {
+	var doc malListDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("parse mal list xml: %w", err)
+	}
+
+	entries := make([]models.WatchlistImportEntry, 0, len(doc.Anime))
+	skipped := 0
+	for _, raw := range doc.Anime {
+		entry := models.WatchlistImportEntry{
+			Title:           strings.TrimSpace(raw.SeriesTitle),
+			ListStatus:      mapMALListStatus(raw.MyStatus),
+			Score:           normalizeImportScore(parseImportFloat(raw.MyScore)),
+			EpisodesWatched: parseImportEpisodes(raw.MyWatchedEpisodes),
+			StartedOn:       normalizeImportDateString(raw.MyStartDate),
+			FinishedOn:      normalizeImportDateString(raw.MyFinishDate),
+			Note:            normalizeImportNote(raw.MyComments),
+		}
+		if id, err := strconv.ParseInt(strings.TrimSpace(raw.SeriesAnimeDBID), 10, 64); err == nil && id > 0 {
+			entry.MALID = &id
+		}
+		if entry.MALID == nil && entry.Title == "" {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, skipped, nil
+}
+
+// EncodeMALListXML erzeugt einen MAL-kompatiblen XML-Export. Anime ohne MAL-Verknüpfung
+// erhalten series_animedb_id 0; Tools, die nach Titel zuordnen, können sie trotzdem lesen.
+func EncodeMALListXML(items []models.WatchlistExportItem) ([]byte, error) {
+	doc := malListExportDocument{
+		MyInfo: malListMyInfo{UserExportType: 1, TotalAnime: len(items)},
+		Anime:  make([]malListExportAnime, 0, len(items)),
+	}
+
+	for _, item := range items {
+		switch item.ListStatus {
+		case models.WatchlistStatusWatching:
+			doc.MyInfo.TotalWatching++
+		case models.WatchlistStatusCompleted:
+			doc.MyInfo.TotalCompleted++
+		case models.WatchlistStatusOnHold:
+			doc.MyInfo.TotalOnHold++
+		case models.WatchlistStatusDropped:
+			doc.MyInfo.TotalDropped++
+		default:
+			doc.MyInfo.TotalPlanToWatch++
+		}
+
+		anime := malListExportAnime{
+			SeriesTitle:       malListCDATA{Text: item.Title},
+			SeriesType:        item.Type,
+			MyWatchedEpisodes: item.EpisodesWatched,
+			MyStartDate:       malListDate(item.StartedOn),
+			MyFinishDate:      malListDate(item.FinishedOn),
+			MyStatus:          malListStatusLabel(item.ListStatus),
+			UpdateOnImport:    1,
+		}
+		if item.MALID != nil {
+			anime.SeriesAnimeDBID = *item.MALID
+		}
+		if item.MaxEpisodes != nil {
+			anime.SeriesEpisodes = int(*item.MaxEpisodes)
+		}
+		if item.Score != nil {
+			anime.MyScore = *item.Score
+		}
+		if item.Note != nil {
+			anime.MyComments = malListCDATA{Text: *item.Note}
+		}
+		doc.Anime = append(doc.Anime, anime)
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("encode mal list xml: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+func mapMALListStatus(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "watching", "1":
+		return models.WatchlistStatusWatching
+	case "completed", "2":
+		return models.WatchlistStatusCompleted
+	case "on-hold", "on hold", "onhold", "3":
+		return models.WatchlistStatusOnHold
+	case "dropped", "4":
+		return models.WatchlistStatusDropped
+	default:
+		return models.WatchlistStatusPlanned
+	}
+}
+
+func malListStatusLabel(status string) string {
+	switch status {
+	case models.WatchlistStatusWatching:
+		return "Watching"
+	case models.WatchlistStatusCompleted:
+		return "Completed"
+	case models.WatchlistStatusOnHold:
+		return "On-Hold"
+	case models.WatchlistStatusDropped:
+		return "Dropped"
+	default:
+		return "Plan to Watch"
+	}
+}
+
+// malListDate liefert das MAL-Datumsformat; fehlende Daten werden als 0000-00-00 exportiert.
+func malListDate(value *string) string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return "0000-00-00"
+	}
+	return *value
+}
+
+// --- AniList JSON ---
+
+type aniListCollectionEnvelope struct {
+	Data *struct {
+		MediaListCollection *aniListCollection `json:"MediaListCollection"`
+	} `json:"data,omitempty"`
+	MediaListCollection *aniListCollection `json:"MediaListCollection,omitempty"`
+	Lists               []aniListList      `json:"lists,omitempty"`
+}
+
+type aniListCollection struct {
+	Lists []aniListList `json:"lists"`
+}
+
+type aniListList struct {
+	Name    string         `json:"name"`
+	Status  string         `json:"status,omitempty"`
+	Entries []aniListEntry `json:"entries"`
+}
+
+type aniListEntry struct {
+	MediaID     int64        `json:"mediaId"`
+	Status      string       `json:"status"`
+	Score       float64      `json:"score"`
+	Progress    int32        `json:"progress"`
+	Notes       *string      `json:"notes"`
+	StartedAt   aniListDate  `json:"startedAt"`
+	CompletedAt aniListDate  `json:"completedAt"`
+	Media       aniListMedia `json:"media"`
+}
+
+type aniListDate struct {
+	Year  *int `json:"year"`
+	Month *int `json:"month"`
+	Day   *int `json:"day"`
+}
+
+type aniListMedia struct {
+	ID       int64        `json:"id"`
+	IDMal    *int64       `json:"idMal"`
+	Title    aniListTitle `json:"title"`
+	Synonyms []string     `json:"synonyms,omitempty"`
+	Episodes *int16       `json:"episodes,omitempty"`
+}
+
+type aniListTitle struct {
+	UserPreferred string `json:"userPreferred,omitempty"`
+	Romaji        string `json:"romaji,omitempty"`
+	English       string `json:"english,omitempty"`
+	Native        string `json:"native,omitempty"`
+}
+
+// ParseAniListListJSON liest eine AniList-Liste im Format der GraphQL-Abfrage
+// MediaListCollection. Akzeptiert wird die vollständige Antwort ({"data": ...}), das
+// MediaListCollection-Objekt oder direkt {"lists": [...]}.
+func ParseAniListListJSON(data []byte) ([]models.WatchlistImportEntry, int, error) {
+	var envelope aniListCollectionEnvelope
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &envelope); err != nil {
+		return nil, 0, fmt.Errorf("parse anilist list json: %w", err)
+	}
+
+	lists := envelope.Lists
+	if envelope.MediaListCollection != nil {
+		lists = envelope.MediaListCollection.Lists
+	}
+	if envelope.Data != nil && envelope.Data.MediaListCollection != nil {
+		lists = envelope.Data.MediaListCollection.Lists
+	}
+
+	entries := make([]models.WatchlistImportEntry, 0)
+	seen := make(map[int64]struct{})
+	skipped := 0
+	for _, list := range lists {
+		for _, raw := range list.Entries {
+			mediaID := raw.MediaID
+			if mediaID <= 0 {
+				mediaID = raw.Media.ID
+			}
+			// Benutzerdefinierte AniList-Listen enthalten dieselben Einträge ein zweites Mal.
+			if mediaID > 0 {
+				if _, ok := seen[mediaID]; ok {
+					continue
+				}
+				seen[mediaID] = struct{}{}
+			}
+
+			status := raw.Status
+			if status == "" {
+				status = list.Status
+			}
+			title, altTitles := aniListTitles(raw.Media)
+			entry := models.WatchlistImportEntry{
+				Title:           title,
+				AltTitles:       altTitles,
+				ListStatus:      mapAniListStatus(status),
+				Score:           normalizeImportScore(raw.Score),
+				EpisodesWatched: raw.Progress,
+				StartedOn:       raw.StartedAt.format(),
+				FinishedOn:      raw.CompletedAt.format(),
+			}
+			if entry.EpisodesWatched < 0 {
+				entry.EpisodesWatched = 0
+			}
+			if raw.Notes != nil {
+				entry.Note = normalizeImportNote(*raw.Notes)
+			}
+			if mediaID > 0 {
+				id := mediaID
+				entry.AniListID = &id
+			}
+			if raw.Media.IDMal != nil && *raw.Media.IDMal > 0 {
+				id := *raw.Media.IDMal
+				entry.MALID = &id
+			}
+			if entry.AniListID == nil && entry.MALID == nil && entry.Title == "" {
+				skipped++
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, skipped, nil
+}
+
+// EncodeAniListListJSON erzeugt eine AniList-Liste im MediaListCollection-Format,
+// gruppiert nach Status wie in der AniList-Oberfläche. Wertungen bleiben auf der 10er-Skala.
+func EncodeAniListListJSON(items []models.WatchlistExportItem) ([]byte, error) {
+	order := []string{
+		models.WatchlistStatusWatching,
+		models.WatchlistStatusPlanned,
+		models.WatchlistStatusOnHold,
+		models.WatchlistStatusCompleted,
+		models.WatchlistStatusDropped,
+	}
+	byStatus := make(map[string][]aniListEntry, len(order))
+
+	for _, item := range items {
+		entry := aniListEntry{
+			Status:      aniListStatusLabel(item.ListStatus),
+			Progress:    item.EpisodesWatched,
+			Notes:       item.Note,
+			StartedAt:   parseAniListDate(item.StartedOn),
+			CompletedAt: parseAniListDate(item.FinishedOn),
+			Media: aniListMedia{
+				IDMal:    item.MALID,
+				Title:    aniListTitle{UserPreferred: item.Title, Romaji: item.Title},
+				Episodes: item.MaxEpisodes,
+			},
+		}
+		if item.AniListID != nil {
+			entry.MediaID = *item.AniListID
+			entry.Media.ID = *item.AniListID
+		}
+		if item.Score != nil {
+			entry.Score = float64(*item.Score)
+		}
+		status := item.ListStatus
+		if !models.IsValidWatchlistStatus(status) {
+			status = models.WatchlistStatusPlanned
+		}
+		byStatus[status] = append(byStatus[status], entry)
+	}
+
+	collection := aniListCollection{Lists: make([]aniListList, 0, len(order))}
+	for _, status := range order {
+		entries := byStatus[status]
+		if len(entries) == 0 {
+			continue
+		}
+		collection.Lists = append(collection.Lists, aniListList{
+			Name:    aniListListName(status),
+			Status:  aniListStatusLabel(status),
+			Entries: entries,
+		})
+	}
+
+	body, err := json.MarshalIndent(map[string]any{"MediaListCollection": collection}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode anilist list json: %w", err)
+	}
+
+	return body, nil
+}
+
+func mapAniListStatus(raw string) string {
+	switch strings.ToUpper(strings.TrimSpace(raw)) {
+	case "CURRENT":
+		return models.WatchlistStatusWatching
+	case "COMPLETED", "REPEATING":
+		return models.WatchlistStatusCompleted
+	case "PAUSED":
+		return models.WatchlistStatusOnHold
+	case "DROPPED":
+		return models.WatchlistStatusDropped
+	default:
+		return models.WatchlistStatusPlanned
+	}
+}
+
+func aniListStatusLabel(status string) string {
+	switch status {
+	case models.WatchlistStatusWatching:
+		return "CURRENT"
+	case models.WatchlistStatusCompleted:
+		return "COMPLETED"
+	case models.WatchlistStatusOnHold:
+		return "PAUSED"
+	case models.WatchlistStatusDropped:
+		return "DROPPED"
+	default:
+		return "PLANNING"
+	}
+}
+
+func aniListListName(status string) string {
+	switch status {
+	case models.WatchlistStatusWatching:
+		return "Watching"
+	case models.WatchlistStatusCompleted:
+		return "Completed"
+	case models.WatchlistStatusOnHold:
+		return "Paused"
+	case models.WatchlistStatusDropped:
+		return "Dropped"
+	default:
+		return "Planning"
+	}
+}
+
+// aniListTitles wählt den Anzeigetitel und sammelt alle weiteren Titel und Synonyme
+// ohne Duplikate für die Titelzuordnung.
+func aniListTitles(media aniListMedia) (string, []string) {
+	candidates := []string{
+		media.Title.UserPreferred,
+		media.Title.Romaji,
+		media.Title.English,
+		media.Title.Native,
+	}
+	candidates = append(candidates, media.Synonyms...)
+
+	title := ""
+	alt := make([]string, 0, len(candidates))
+	seen := make(map[string]struct{}, len(candidates))
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		key := strings.ToLower(candidate)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if title == "" {
+			title = candidate
+			continue
+		}
+		alt = append(alt, candidate)
+	}
+	if len(alt) == 0 {
+		return title, nil
+	}
+	return title, alt
+}
+
+func (d aniListDate) format() *string {
+	if d.Year == nil || d.Month == nil || d.Day == nil {
+		return nil
+	}
+	return normalizeImportDateString(fmt.Sprintf("%04d-%02d-%02d", *d.Year, *d.Month, *d.Day))
+}
+
+func parseAniListDate(value *string) aniListDate {
+	if value == nil {
+		return aniListDate{}
+	}
+	parsed, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return aniListDate{}
+	}
+	year, month, day := parsed.Year(), int(parsed.Month()), parsed.Day()
+	return aniListDate{Year: &year, Month: &month, Day: &day}
+}
+
+// --- gemeinsame Normalisierung ---
+
+// normalizeImportScore bildet externe Wertungen auf die lokale Skala 1–10 ab. Werte über 10
+// stammen aus der 100er-Skala von AniList; 0 bedeutet in beiden Formaten "keine Wertung".
+func normalizeImportScore(score float64) *int16 {
+	if score <= 0 || math.IsNaN(score) {
+		return nil
+	}
+	if score > 10 {
+		score = score / 10
+	}
+	rounded := int16(math.Round(score))
+	if rounded < 1 {
+		rounded = 1
+	}
+	if rounded > 10 {
+		rounded = 10
+	}
+	return &rounded
+}
+
+// normalizeImportDateString akzeptiert nur vollständige, gültige Daten. MAL exportiert
+// unbekannte Daten als 0000-00-00 und unvollständige als z.B. 2020-05-00.
+func normalizeImportDateString(raw string) *string {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil
+	}
+	formatted := parsed.Format("2006-01-02")
+	return &formatted
+}
+
+func normalizeImportNote(raw string) *string {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(value) > watchlistImportMaxNoteRunes {
+		value = string([]rune(value)[:watchlistImportMaxNoteRunes])
+	}
+	return &value
+}
+
+func parseImportFloat(raw string) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+func parseImportEpisodes(raw string) int32 {
+	value, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 32)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return int32(value)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"team4s.v3/backend/internal/models"
+)
+
+const sampleMALListXML = `<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<myinfo>
+		<user_export_type>1</user_export_type>
+	</myinfo>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title><![CDATA[Cowboy Bebop]]></series_title>
+		<series_episodes>26</series_episodes>
+		<my_watched_episodes>26</my_watched_episodes>
+		<my_start_date>2021-01-03</my_start_date>
+		<my_finish_date>0000-00-00</my_finish_date>
+		<my_score>9</my_score>
+		<my_status>Completed</my_status>
+		<my_comments><![CDATA[  Klassiker  ]]></my_comments>
+	</anime>
+	<anime>
+		<series_animedb_id>0</series_animedb_id>
+		<series_title><![CDATA[Lokaler Titel]]></series_title>
+		<my_watched_episodes>x</my_watched_episodes>
+		<my_start_date>2020-05-00</my_start_date>
+		<my_score>0</my_score>
+		<my_status>6</my_status>
+	</anime>
+	<anime>
+		<series_animedb_id></series_animedb_id>
+		<series_title></series_title>
+		<my_status>Watching</my_status>
+	</anime>
+</myanimelist>`
+
+func TestParseMALListXML(t *testing.T) {
+	entries, skipped, err := ParseMALListXML([]byte(sampleMALListXML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped != 1 {
+		t.Fatalf("expected 1 skipped entry, got %d", skipped)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	first := entries[0]
+	if first.MALID == nil || *first.MALID != 1 {
+		t.Fatalf("expected mal id 1, got %v", first.MALID)
+	}
+	if first.Title != "Cowboy Bebop" || first.ListStatus != models.WatchlistStatusCompleted {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+	if first.Score == nil || *first.Score != 9 || first.EpisodesWatched != 26 {
+		t.Fatalf("unexpected score/progress: %+v", first)
+	}
+	if first.StartedOn == nil || *first.StartedOn != "2021-01-03" || first.FinishedOn != nil {
+		t.Fatalf("unexpected dates: %v %v", first.StartedOn, first.FinishedOn)
+	}
+	if first.Note == nil || *first.Note != "Klassiker" {
+		t.Fatalf("expected trimmed note, got %v", first.Note)
+	}
+
+	second := entries[1]
+	if second.MALID != nil || second.Score != nil || second.StartedOn != nil || second.EpisodesWatched != 0 {
+		t.Fatalf("expected empty optional fields, got %+v", second)
+	}
+	if second.ListStatus != models.WatchlistStatusPlanned {
+		t.Fatalf("expected numeric status 6 to map to planned, got %q", second.ListStatus)
+	}
+}
+
+func TestParseMALListXMLInvalid(t *testing.T) {
+	if _, _, err := ParseMALListXML([]byte("<myanimelist><anime>")); err == nil {
+		t.Fatalf("expected error for truncated xml")
+	}
+}
+
+func TestParseAniListListJSON(t *testing.T) {
+	raw := `{"data":{"MediaListCollection":{"lists":[
+		{"name":"Watching","status":"CURRENT","entries":[
+			{"mediaId":21,"status":"CURRENT","score":85,"progress":12,"notes":"weiter",
+			 "startedAt":{"year":2024,"month":2,"day":29},"completedAt":{"year":null,"month":null,"day":null},
+			 "media":{"id":21,"idMal":21,"title":{"romaji":"One Piece","english":"One Piece","native":"ワンピース"},"synonyms":["OP"]}}
+		]},
+		{"name":"Favoriten","entries":[
+			{"mediaId":21,"status":"CURRENT","score":85,"progress":12,"media":{"id":21}}
+		]},
+		{"name":"Paused","status":"PAUSED","entries":[
+			{"mediaId":0,"score":0,"progress":0,"media":{"id":0,"title":{}}}
+		]}
+	]}}}`
+
+	entries, skipped, err := ParseAniListListJSON([]byte(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped != 1 {
+		t.Fatalf("expected 1 skipped entry, got %d", skipped)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected custom list duplicate to be ignored, got %d entries", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.AniListID == nil || *entry.AniListID != 21 || entry.MALID == nil || *entry.MALID != 21 {
+		t.Fatalf("unexpected ids: %+v", entry)
+	}
+	if entry.Title != "One Piece" || len(entry.AltTitles) != 2 {
+		t.Fatalf("expected deduplicated titles, got %q %v", entry.Title, entry.AltTitles)
+	}
+	if entry.Score == nil || *entry.Score != 9 {
+		t.Fatalf("expected 100-point score 85 to map to 9, got %v", entry.Score)
+	}
+	if entry.StartedOn == nil || *entry.StartedOn != "2024-02-29" || entry.FinishedOn != nil {
+		t.Fatalf("unexpected dates: %v %v", entry.StartedOn, entry.FinishedOn)
+	}
+	if entry.ListStatus != models.WatchlistStatusWatching {
+		t.Fatalf("expected watching, got %q", entry.ListStatus)
+	}
+}
+
+func TestNormalizeImportScore(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want int16
+		nil  bool
+	}{
+		{in: 0, nil: true},
+		{in: 7, want: 7},
+		{in: 7.5, want: 8},
+		{in: 100, want: 10},
+		{in: 3, want: 3},
+		{in: 0.2, want: 1},
+	}
+	for _, tc := range tests {
+		got := normalizeImportScore(tc.in)
+		if tc.nil {
+			if got != nil {
+				t.Fatalf("score %v: expected nil, got %d", tc.in, *got)
+			}
+			continue
+		}
+		if got == nil || *got != tc.want {
+			t.Fatalf("score %v: expected %d, got %v", tc.in, tc.want, got)
+		}
+	}
+}
+
+func TestDetectWatchlistListFormat(t *testing.T) {
+	if got := DetectWatchlistListFormat([]byte("\xef\xbb\xbf  <?xml?>")); got != models.WatchlistListFormatMAL {
+		t.Fatalf("expected mal, got %q", got)
+	}
+	if got := DetectWatchlistListFormat([]byte(`{"lists":[]}`)); got != models.WatchlistListFormatAniList {
+		t.Fatalf("expected anilist, got %q", got)
+	}
+	if got := DetectWatchlistListFormat([]byte("csv,data")); got != "" {
+		t.Fatalf("expected no format, got %q", got)
+	}
+}
+
+func TestWatchlistListFormatRoundTrip(t *testing.T) {
+	score := int16(8)
+	malID := int64(5114)
+	aniListID := int64(5114)
+	started := "2023-04-01"
+	note := "Notiz mit <xml> & Sonderzeichen"
+	maxEpisodes := int16(64)
+	items := []models.WatchlistExportItem{
+		{
+			WatchlistItem: models.WatchlistItem{
+				AnimeID:         3,
+				Title:           "Fullmetal Alchemist: Brotherhood",
+				Type:            "TV",
+				MaxEpisodes:     &maxEpisodes,
+				ListStatus:      models.WatchlistStatusOnHold,
+				Score:           &score,
+				EpisodesWatched: 20,
+				StartedOn:       &started,
+				Note:            &note,
+			},
+			MALID:     &malID,
+			AniListID: &aniListID,
+		},
+	}
+
+	malXML, err := EncodeMALListXML(items)
+	if err != nil {
+		t.Fatalf("encode mal: %v", err)
+	}
+	if !strings.Contains(string(malXML), "<my_finish_date>0000-00-00</my_finish_date>") {
+		t.Fatalf("expected empty finish date in mal export:\n%s", malXML)
+	}
+	malEntries, _, err := ParseMALListXML(malXML)
+	if err != nil {
+		t.Fatalf("parse mal: %v", err)
+	}
+
+	aniListJSON, err := EncodeAniListListJSON(items)
+	if err != nil {
+		t.Fatalf("encode anilist: %v", err)
+	}
+	aniListEntries, _, err := ParseAniListListJSON(aniListJSON)
+	if err != nil {
+		t.Fatalf("parse anilist: %v", err)
+	}
+
+	for name, entries := range map[string][]models.WatchlistImportEntry{"mal": malEntries, "anilist": aniListEntries} {
+		if len(entries) != 1 {
+			t.Fatalf("%s: expected 1 entry, got %d", name, len(entries))
+		}
+		entry := entries[0]
+		if entry.MALID == nil || *entry.MALID != malID {
+			t.Fatalf("%s: expected mal id to survive, got %v", name, entry.MALID)
+		}
+		if entry.ListStatus != models.WatchlistStatusOnHold || entry.EpisodesWatched != 20 {
+			t.Fatalf("%s: unexpected status/progress: %+v", name, entry)
+		}
+		if entry.Score == nil || *entry.Score != score {
+			t.Fatalf("%s: expected score %d, got %v", name, score, entry.Score)
+		}
+		if entry.StartedOn == nil || *entry.StartedOn != started || entry.FinishedOn != nil {
+			t.Fatalf("%s: unexpected dates: %v %v", name, entry.StartedOn, entry.FinishedOn)
+		}
+		if entry.Note == nil || *entry.Note != note {
+			t.Fatalf("%s: expected note to survive, got %v", name, entry.Note)
+		}
+	}
+}
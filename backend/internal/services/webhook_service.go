@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// webhookDescriptionMaxRunes begrenzt den Textauszug einer Projektnotiz im Ereignis.
+const webhookDescriptionMaxRunes = 500
+
+// WebhookStore ist die Datenbankschnittstelle des WebhookService
+// (implementiert von repository.WebhookRepository).
+type WebhookStore interface {
+	EnqueueEvent(ctx context.Context, event models.WebhookEvent) (int, error)
+	LoadReleaseContext(ctx context.Context, releaseVersionID int64) (*models.WebhookReleaseContext, error)
+	LoadReleaseContextByVariant(ctx context.Context, variantID int64) (*models.WebhookReleaseContext, error)
+	LoadProjectNoteContext(ctx context.Context, noteID int64) (*models.WebhookProjectNoteContext, error)
+}
+
+// WebhookService erzeugt aus fachlichen Schreibvorgängen Webhook-Ereignisse und reiht sie
+// für alle passenden Webhooks ein. Die Zustellung übernimmt der WebhookWorker. Fehler werden
+// nur geloggt, damit der auslösende Request nicht scheitert. Ein nil-Service ist ein No-op.
+type WebhookService struct {
+	store WebhookStore
+	now   func() time.Time
+}
+
+// NewWebhookService erstellt einen neuen WebhookService.
+func NewWebhookService(store WebhookStore) *WebhookService {
+	return &WebhookService{store: store, now: time.Now}
+}
+
+// NotifyReleaseVersionCreated meldet eine neu angelegte Episodenversion. variantID ist die ID
+// der angelegten Release-Variante.
+func (s *WebhookService) NotifyReleaseVersionCreated(ctx context.Context, variantID int64) {
+	if s == nil || variantID <= 0 {
+		return
+	}
+	release, err := s.store.LoadReleaseContextByVariant(ctx, variantID)
+	if err != nil {
+		log.Printf("webhooks: load release context (variant_id=%d): %v", variantID, err)
+		return
+	}
+
+	event := s.releaseEvent(models.WebhookEventReleaseVersionCreated, release)
+	event.Title = fmt.Sprintf("%s – Episode %s", release.AnimeTitle, release.EpisodeNumber)
+	event.Description = fmt.Sprintf("Neue Version %s von %s", release.Version, strings.Join(release.FansubGroupNames, ", "))
+	if release.VideoQuality != "" {
+		event.Description += " (" + release.VideoQuality + ")"
+	}
+	event.Data["release_variant_id"] = variantID
+	s.enqueue(ctx, event)
+}
+
+// NotifyReleaseMediaAdded meldet neu hochgeladene Medien einer Release-Version.
+func (s *WebhookService) NotifyReleaseMediaAdded(ctx context.Context, releaseVersionID int64, category string, count int) {
+	if s == nil || releaseVersionID <= 0 || count <= 0 {
+		return
+	}
+	release, err := s.store.LoadReleaseContext(ctx, releaseVersionID)
+	if err != nil {
+		log.Printf("webhooks: load release context (release_version_id=%d): %v", releaseVersionID, err)
+		return
+	}
+
+	event := s.releaseEvent(models.WebhookEventReleaseMediaAdded, release)
+	event.Title = fmt.Sprintf("%s – Episode %s: neue Medien", release.AnimeTitle, release.EpisodeNumber)
+	event.Description = fmt.Sprintf("%d neue Datei(en) in %s für Version %s", count, category, release.Version)
+	event.Data["category"] = category
+	event.Data["count"] = count
+	s.enqueue(ctx, event)
+}
+
+// NotifyProjectNotePublished meldet eine Projektnotiz, die gerade öffentlich veröffentlicht
+// wurde. Ob ein Statuswechsel vorliegt, entscheidet der Aufrufer.
+func (s *WebhookService) NotifyProjectNotePublished(ctx context.Context, noteID int64) {
+	if s == nil || noteID <= 0 {
+		return
+	}
+	note, err := s.store.LoadProjectNoteContext(ctx, noteID)
+	if err != nil {
+		log.Printf("webhooks: load project note context (note_id=%d): %v", noteID, err)
+		return
+	}
+
+	s.enqueue(ctx, models.WebhookEvent{
+		Type:           models.WebhookEventProjectNotePublished,
+		FansubGroupIDs: []int64{note.FansubGroupID},
+		OccurredAt:     s.now().UTC(),
+		Title:          fmt.Sprintf("%s: %s", note.AnimeTitle, note.Title),
+		Description:    truncateNotificationText(note.BodyText, webhookDescriptionMaxRunes),
+		LinkURL:        fmt.Sprintf("/anime/%d", note.AnimeID),
+		Data: map[string]any{
+			"note_id":           note.NoteID,
+			"anime_id":          note.AnimeID,
+			"anime_title":       note.AnimeTitle,
+			"fansub_group_id":   note.FansubGroupID,
+			"fansub_group_name": note.FansubGroupName,
+			"title":             note.Title,
+		},
+	})
+}
+
+func (s *WebhookService) releaseEvent(eventType string, release *models.WebhookReleaseContext) models.WebhookEvent {
+	return models.WebhookEvent{
+		Type:           eventType,
+		FansubGroupIDs: release.FansubGroupIDs,
+		OccurredAt:     s.now().UTC(),
+		LinkURL:        fmt.Sprintf("/episodes/%d", release.EpisodeID),
+		Data: map[string]any{
+			"release_version_id": release.ReleaseVersionID,
+			"version":            release.Version,
+			"anime_id":           release.AnimeID,
+			"anime_title":        release.AnimeTitle,
+			"episode_id":         release.EpisodeID,
+			"episode_number":     release.EpisodeNumber,
+			"video_quality":      release.VideoQuality,
+			"fansub_group_ids":   release.FansubGroupIDs,
+			"fansub_group_names": release.FansubGroupNames,
+		},
+	}
+}
+
+func (s *WebhookService) enqueue(ctx context.Context, event models.WebhookEvent) {
+	if _, err := s.store.EnqueueEvent(ctx, event); err != nil {
+		log.Printf("webhooks: enqueue %s: %v", event.Type, err)
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// errWebhookTargetBlocked kennzeichnet Zustellungen an nicht-öffentliche Adressen. Sie werden
+// nicht wiederholt.
+var errWebhookTargetBlocked = errors.New("webhook-ziel ist keine öffentliche adresse")
+
+// nonPublicWebhookPrefixes ergänzt die Prüfungen von netip.Addr um Bereiche, die ebenfalls nicht
+// im öffentlichen Internet liegen oder interne Adressen erreichbar machen (z.B. NAT64).
+var nonPublicWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// IsPublicWebhookAddress meldet, ob Webhooks an addr zugestellt werden dürfen. Loopback,
+// private (RFC 1918/4193), link-lokale (inkl. Cloud-Metadaten), unspezifizierte und
+// Multicast-Adressen sind ausgeschlossen.
+func IsPublicWebhookAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range nonPublicWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookDialTarget prüft die bereits aufgelöste Zieladresse direkt vor dem Verbindungsaufbau.
+// So greift die Prüfung auch, wenn ein DNS-Name zwischen Anlage und Zustellung umgebogen wird.
+func checkWebhookDialTarget(_ string, address string, _ syscall.RawConn) error {
+	target, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errWebhookTargetBlocked, address)
+	}
+	if !IsPublicWebhookAddress(target.Addr()) {
+		return fmt.Errorf("%w: %s", errWebhookTargetBlocked, target.Addr().Unmap())
+	}
+	return nil
+}
+
+// newWebhookHTTPClient baut den Client der Zustellungen: ohne Proxy, ohne Weiterleitungen und
+// mit Prüfung jeder aufgelösten Zieladresse. allowPrivate schaltet die Prüfung ab (nur Tests).
+func newWebhookHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = checkWebhookDialTarget
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
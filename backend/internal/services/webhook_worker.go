@@ -0,0 +1,285 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// Header, mit denen Empfänger eine Zustellung zuordnen und prüfen können. Die Signatur ist
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	WebhookHeaderEvent     = "X-Team4s-Event"
+	WebhookHeaderDelivery  = "X-Team4s-Delivery"
+	WebhookHeaderTimestamp = "X-Team4s-Timestamp"
+	WebhookHeaderSignature = "X-Team4s-Signature"
+)
+
+// Discord begrenzt Titel und Beschreibung eines Embeds.
+const (
+	discordEmbedTitleMaxRunes       = 256
+	discordEmbedDescriptionMaxRunes = 4096
+)
+
+// discordEmbedColors ordnet jedem Ereignistyp eine Embed-Farbe zu.
+var discordEmbedColors = map[string]int{
+	models.WebhookEventReleaseVersionCreated: 0x2ecc71,
+	models.WebhookEventReleaseMediaAdded:     0x3498db,
+	models.WebhookEventProjectNotePublished:  0xf1c40f,
+}
+
+// discordEmbedFooters beschriftet das Embed mit dem Ereignistyp.
+var discordEmbedFooters = map[string]string{
+	models.WebhookEventReleaseVersionCreated: "Neue Release-Version",
+	models.WebhookEventReleaseMediaAdded:     "Neue Release-Medien",
+	models.WebhookEventProjectNotePublished:  "Neue Projektnotiz",
+}
+
+// WebhookDeliveryStore ist die Datenbankschnittstelle des WebhookWorker
+// (implementiert von repository.WebhookRepository).
+type WebhookDeliveryStore interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDeliveryMessage, error)
+	MarkDelivered(ctx context.Context, id int64, responseStatus int) error
+	MarkFailed(ctx context.Context, id int64, responseStatus *int, sendErr string, nextAttemptAt *time.Time) error
+}
+
+// WebhookWorkerConfig steuert Polling und Backoff des Workers. Werte <= 0 nutzen die Defaults.
+type WebhookWorkerConfig struct {
+	PollInterval   time.Duration // Abstand zwischen zwei Durchläufen (Default 10s)
+	BatchSize      int           // Zustellungen pro Durchlauf (Default 20)
+	BaseBackoff    time.Duration // Wartezeit nach dem ersten Fehlversuch (Default 30s)
+	MaxBackoff     time.Duration // Obergrenze der Wartezeit (Default 1h)
+	RequestTimeout time.Duration // Timeout je HTTP-Zustellung (Default 10s)
+}
+
+func (c WebhookWorkerConfig) withDefaults() WebhookWorkerConfig {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 10 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 20
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 30 * time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Hour
+	}
+	if c.MaxBackoff < c.BaseBackoff {
+		c.MaxBackoff = c.BaseBackoff
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = 10 * time.Second
+	}
+	return c
+}
+
+// WebhookWorker stellt eingereihte Webhook-Ereignisse per HTTP POST zu. 2xx gilt als Erfolg;
+// andere 4xx-Antworten (außer 408 und 429) sind endgültig, alles Übrige wird mit
+// exponentiellem Backoff bis max_attempts wiederholt. Weiterleitungen werden nicht verfolgt,
+// Ziele mit nicht-öffentlicher Adresse abgelehnt (siehe IsPublicWebhookAddress).
+type WebhookWorker struct {
+	store         WebhookDeliveryStore
+	client        *http.Client
+	publicBaseURL string
+	cfg           WebhookWorkerConfig
+	now           func() time.Time
+}
+
+// NewWebhookWorker erstellt einen Worker. publicBaseURL macht die Links der Ereignisse absolut.
+func NewWebhookWorker(store WebhookDeliveryStore, publicBaseURL string, cfg WebhookWorkerConfig) *WebhookWorker {
+	cfg = cfg.withDefaults()
+	return &WebhookWorker{
+		store:         store,
+		client:        newWebhookHTTPClient(cfg.RequestTimeout, false),
+		publicBaseURL: strings.TrimRight(strings.TrimSpace(publicBaseURL), "/"),
+		cfg:           cfg,
+		now:           time.Now,
+	}
+}
+
+// Run arbeitet die fälligen Zustellungen bis zum Ende von ctx periodisch ab.
+func (w *WebhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce beansprucht einen Batch fälliger Zustellungen und versendet ihn. Gibt die Anzahl
+// der erfolgreichen Zustellungen zurück.
+func (w *WebhookWorker) RunOnce(ctx context.Context) int {
+	lease := w.cfg.RequestTimeout*time.Duration(w.cfg.BatchSize) + time.Minute
+	messages, err := w.store.ClaimDue(ctx, w.cfg.BatchSize, lease)
+	if err != nil {
+		log.Printf("webhooks: claim due deliveries: %v", err)
+		return 0
+	}
+
+	delivered := 0
+	for _, message := range messages {
+		if w.deliver(ctx, message) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+func (w *WebhookWorker) deliver(ctx context.Context, message models.WebhookDeliveryMessage) bool {
+	body, err := w.renderBody(message)
+	if err != nil {
+		w.markFailed(ctx, message, nil, err, nil)
+		return false
+	}
+
+	timestamp := w.now().Unix()
+	reqCtx, cancel := context.WithTimeout(ctx, w.cfg.RequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, message.URL, bytes.NewReader(body))
+	if err != nil {
+		w.markFailed(ctx, message, nil, err, nil)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Team4s-Webhooks/1.0")
+	req.Header.Set(WebhookHeaderEvent, message.Event.Type)
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatInt(message.ID, 10))
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(message.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if errors.Is(err, errWebhookTargetBlocked) {
+		w.markFailed(ctx, message, nil, err, nil)
+		return false
+	}
+	if err != nil {
+		w.markFailed(ctx, message, nil, err, w.retryAt(message))
+		return false
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	status := resp.StatusCode
+	if status >= 200 && status < 300 {
+		if err := w.store.MarkDelivered(ctx, message.ID, status); err != nil {
+			log.Printf("webhooks: mark delivered (id=%d): %v", message.ID, err)
+		}
+		return true
+	}
+
+	sendErr := fmt.Errorf("empfänger antwortete mit http %d", status)
+	var next *time.Time
+	if isRetryableWebhookStatus(status) {
+		next = w.retryAt(message)
+	}
+	w.markFailed(ctx, message, &status, sendErr, next)
+	return false
+}
+
+// renderBody baut den Request-Body im Format des Webhooks.
+func (w *WebhookWorker) renderBody(message models.WebhookDeliveryMessage) ([]byte, error) {
+	event := message.Event
+	link := ""
+	if event.LinkURL != "" {
+		link = w.publicBaseURL + event.LinkURL
+	}
+
+	switch message.Format {
+	case models.WebhookFormatJSON:
+		return json.Marshal(map[string]any{
+			"event":       event.Type,
+			"delivery_id": message.ID,
+			"occurred_at": event.OccurredAt.UTC().Format(time.RFC3339),
+			"title":       event.Title,
+			"description": event.Description,
+			"url":         link,
+			"data":        event.Data,
+		})
+	case models.WebhookFormatDiscord:
+		embed := map[string]any{
+			"title":     truncateNotificationText(event.Title, discordEmbedTitleMaxRunes-1),
+			"timestamp": event.OccurredAt.UTC().Format(time.RFC3339),
+			"color":     discordEmbedColors[event.Type],
+			"footer":    map[string]string{"text": discordEmbedFooters[event.Type]},
+		}
+		if event.Description != "" {
+			embed["description"] = truncateNotificationText(event.Description, discordEmbedDescriptionMaxRunes-1)
+		}
+		if link != "" {
+			embed["url"] = link
+		}
+		return json.Marshal(map[string]any{
+			"username": "Team4s",
+			"embeds":   []map[string]any{embed},
+		})
+	}
+	return nil, errors.New("unbekanntes webhook-format " + message.Format)
+}
+
+func (w *WebhookWorker) retryAt(message models.WebhookDeliveryMessage) *time.Time {
+	if message.Attempts >= message.MaxAttempts {
+		return nil
+	}
+	next := w.now().Add(w.backoff(message.Attempts))
+	return &next
+}
+
+func (w *WebhookWorker) markFailed(ctx context.Context, message models.WebhookDeliveryMessage, status *int, sendErr error, next *time.Time) {
+	if next == nil {
+		log.Printf("webhooks: giving up (id=%d, webhook_id=%d, attempts=%d): %v", message.ID, message.WebhookID, message.Attempts, sendErr)
+	} else {
+		log.Printf("webhooks: delivery failed, retry at %s (id=%d, attempts=%d): %v", next.UTC().Format(time.RFC3339), message.ID, message.Attempts, sendErr)
+	}
+	if err := w.store.MarkFailed(ctx, message.ID, status, sendErr.Error(), next); err != nil {
+		log.Printf("webhooks: mark failed (id=%d): %v", message.ID, err)
+	}
+}
+
+// backoff liefert BaseBackoff * 2^(attempts-1), begrenzt auf MaxBackoff.
+func (w *WebhookWorker) backoff(attempts int) time.Duration {
+	delay := w.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.cfg.MaxBackoff {
+			return w.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// isRetryableWebhookStatus meldet, ob eine Nicht-2xx-Antwort wiederholt werden soll.
+func isRetryableWebhookStatus(status int) bool {
+	if status == http.StatusRequestTimeout || status == http.StatusTooManyRequests {
+		return true
+	}
+	return status < 400 || status >= 500
+}
+
+// SignWebhookPayload berechnet den Signatur-Header für body. Empfänger bilden denselben Wert
+// aus X-Team4s-Timestamp und dem Roh-Body und vergleichen in konstanter Zeit.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"team4s.v3/backend/internal/models"
+)
+
+// memoryWebhookDeliveries bildet das Zustell-Log für Worker-Tests im Speicher nach.
+type memoryWebhookDeliveries struct {
+	entries map[int64]*memoryWebhookDelivery
+}
+
+type memoryWebhookDelivery struct {
+	message        models.WebhookDeliveryMessage
+	status         string
+	responseStatus *int
+	next           time.Time
+	lastErr        string
+}
+
+func (s *memoryWebhookDeliveries) add(message models.WebhookDeliveryMessage) {
+	if s.entries == nil {
+		s.entries = map[int64]*memoryWebhookDelivery{}
+	}
+	s.entries[message.ID] = &memoryWebhookDelivery{message: message, status: models.WebhookDeliveryStatusPending}
+}
+
+func (s *memoryWebhookDeliveries) ClaimDue(_ context.Context, limit int, _ time.Duration) ([]models.WebhookDeliveryMessage, error) {
+	claimed := make([]models.WebhookDeliveryMessage, 0)
+	for id := int64(1); id <= int64(len(s.entries)) && len(claimed) < limit; id++ {
+		entry := s.entries[id]
+		if entry == nil || entry.status != models.WebhookDeliveryStatusPending {
+			continue
+		}
+		entry.status = models.WebhookDeliveryStatusSending
+		entry.message.Attempts++
+		claimed = append(claimed, entry.message)
+	}
+	return claimed, nil
+}
+
+func (s *memoryWebhookDeliveries) MarkDelivered(_ context.Context, id int64, responseStatus int) error {
+	s.entries[id].status = models.WebhookDeliveryStatusDelivered
+	s.entries[id].responseStatus = &responseStatus
+	return nil
+}
+
+func (s *memoryWebhookDeliveries) MarkFailed(_ context.Context, id int64, responseStatus *int, sendErr string, next *time.Time) error {
+	entry := s.entries[id]
+	entry.responseStatus = responseStatus
+	entry.lastErr = sendErr
+	if next == nil {
+		entry.status = models.WebhookDeliveryStatusDead
+		return nil
+	}
+	entry.status = models.WebhookDeliveryStatusPending
+	entry.next = *next
+	return nil
+}
+
+// webhookReceiver nimmt Zustellungen an und antwortet mit den vorgegebenen Statuscodes.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newTestWebhookWorker(store WebhookDeliveryStore) *WebhookWorker {
+	worker := NewWebhookWorker(store, "https://team4s.example/", WebhookWorkerConfig{
+		BaseBackoff:    time.Minute,
+		MaxBackoff:     10 * time.Minute,
+		RequestTimeout: 3 * time.Second,
+	})
+	worker.now = func() time.Time { return time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) }
+	// httptest-Empfänger lauschen auf Loopback.
+	worker.client = newWebhookHTTPClient(3*time.Second, true)
+	return worker
+}
+
+func testWebhookDelivery(url string, format string) models.WebhookDeliveryMessage {
+	return models.WebhookDeliveryMessage{
+		ID:        1,
+		WebhookID: 4,
+		URL:       url,
+		Format:    format,
+		Secret:    "whsec_test",
+		Event: models.WebhookEvent{
+			Type:        models.WebhookEventReleaseVersionCreated,
+			OccurredAt:  time.Date(2026, 6, 1, 11, 59, 0, 0, time.UTC),
+			Title:       "Frieren – Episode 3",
+			Description: "Neue Version v2 von Team4s (1080p)",
+			LinkURL:     "/episodes/42",
+			Data:        map[string]any{"release_version_id": 7},
+		},
+		MaxAttempts: 3,
+	}
+}
+
+func TestWebhookWorkerSignsJSONDelivery(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	store := &memoryWebhookDeliveries{}
+	store.add(testWebhookDelivery(server.URL, models.WebhookFormatJSON))
+	worker := newTestWebhookWorker(store)
+
+	if delivered := worker.RunOnce(context.Background()); delivered != 1 {
+		t.Fatalf("expected one delivery, got %d (%s)", delivered, store.entries[1].lastErr)
+	}
+	if store.entries[1].status != models.WebhookDeliveryStatusDelivered || *store.entries[1].responseStatus != http.StatusNoContent {
+		t.Fatalf("expected delivered entry with 204, got %+v", store.entries[1])
+	}
+
+	req, body := receiver.requests[0], receiver.bodies[0]
+	timestamp, err := strconv.ParseInt(req.Header.Get(WebhookHeaderTimestamp), 10, 64)
+	if err != nil || timestamp != worker.now().Unix() {
+		t.Fatalf("unexpected timestamp header %q", req.Header.Get(WebhookHeaderTimestamp))
+	}
+	if got, want := req.Header.Get(WebhookHeaderSignature), SignWebhookPayload("whsec_test", timestamp, body); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	if req.Header.Get(WebhookHeaderEvent) != models.WebhookEventReleaseVersionCreated || req.Header.Get(WebhookHeaderDelivery) != "1" {
+		t.Fatalf("unexpected event headers: %v", req.Header)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("invalid json body: %v", err)
+	}
+	if payload["event"] != models.WebhookEventReleaseVersionCreated || payload["url"] != "https://team4s.example/episodes/42" {
+		t.Fatalf("unexpected json payload: %s", body)
+	}
+}
+
+func TestWebhookWorkerRendersDiscordEmbed(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	store := &memoryWebhookDeliveries{}
+	store.add(testWebhookDelivery(server.URL, models.WebhookFormatDiscord))
+	newTestWebhookWorker(store).RunOnce(context.Background())
+
+	var payload struct {
+		Embeds []struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			URL         string `json:"url"`
+			Timestamp   string `json:"timestamp"`
+			Footer      struct {
+				Text string `json:"text"`
+			} `json:"footer"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal(receiver.bodies[0], &payload); err != nil || len(payload.Embeds) != 1 {
+		t.Fatalf("expected one discord embed, got %s (%v)", receiver.bodies[0], err)
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != "Frieren – Episode 3" || embed.URL != "https://team4s.example/episodes/42" ||
+		embed.Timestamp != "2026-06-01T11:59:00Z" || embed.Footer.Text != "Neue Release-Version" {
+		t.Fatalf("unexpected embed: %+v", embed)
+	}
+}
+
+func TestWebhookWorkerRetriesServerErrorsAndDeadLetters(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusServiceUnavailable}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	store := &memoryWebhookDeliveries{}
+	store.add(testWebhookDelivery(server.URL, models.WebhookFormatJSON))
+	worker := newTestWebhookWorker(store)
+	now := worker.now()
+	entry := store.entries[1]
+
+	worker.RunOnce(context.Background())
+	if entry.status != models.WebhookDeliveryStatusPending || !entry.next.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected retry after base backoff, got status %q next %s", entry.status, entry.next)
+	}
+
+	worker.RunOnce(context.Background())
+	if entry.status != models.WebhookDeliveryStatusPending || !entry.next.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("expected 429 to be retried with doubled backoff, got status %q next %s", entry.status, entry.next)
+	}
+
+	worker.RunOnce(context.Background())
+	if entry.status != models.WebhookDeliveryStatusDead || *entry.responseStatus != http.StatusServiceUnavailable {
+		t.Fatalf("expected dead letter after max attempts, got %+v", entry)
+	}
+	if !strings.Contains(entry.lastErr, "http 503") {
+		t.Fatalf("expected response status in last error, got %q", entry.lastErr)
+	}
+}
+
+func TestWebhookWorkerDeadLettersClientErrors(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusNotFound}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	store := &memoryWebhookDeliveries{}
+	store.add(testWebhookDelivery(server.URL, models.WebhookFormatJSON))
+	newTestWebhookWorker(store).RunOnce(context.Background())
+
+	if store.entries[1].status != models.WebhookDeliveryStatusDead {
+		t.Fatalf("expected 404 to dead-letter immediately, got %q", store.entries[1].status)
+	}
+}
+
+func TestWebhookWorkerRejectsNonPublicTargets(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	store := &memoryWebhookDeliveries{}
+	store.add(testWebhookDelivery(server.URL, models.WebhookFormatJSON))
+	worker := NewWebhookWorker(store, "https://team4s.example/", WebhookWorkerConfig{RequestTimeout: 3 * time.Second})
+
+	if delivered := worker.RunOnce(context.Background()); delivered != 0 {
+		t.Fatalf("expected loopback target to be rejected, got %d deliveries", delivered)
+	}
+	if len(receiver.requests) != 0 {
+		t.Fatalf("expected no request to reach the loopback receiver, got %d", len(receiver.requests))
+	}
+	entry := store.entries[1]
+	if entry.status != models.WebhookDeliveryStatusDead || !strings.Contains(entry.lastErr, "keine öffentliche adresse") {
+		t.Fatalf("expected blocked target to dead-letter without retry, got %+v", entry)
+	}
+}
+
+func TestIsPublicWebhookAddress(t *testing.T) {
+	cases := map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::248": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.178.1":        false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"64:ff9b::a00:1":       false,
+		"::":                   false,
+	}
+	for raw, want := range cases {
+		if got := IsPublicWebhookAddress(netip.MustParseAddr(raw)); got != want {
+			t.Errorf("IsPublicWebhookAddress(%s) = %v, want %v", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+-- Migration 0117 DOWN: Wiedergabefortschritt entfernen.
+
+DROP TABLE IF EXISTS episode_watch_progress;
@@ -0,0 +1,29 @@
+-- Migration 0117: Wiedergabefortschritt pro App-User, Episode und Release-Version.
+-- release_version_id ist NULL, wenn der Client keine konkrete Version meldet
+-- (z.B. Legacy-Stream ueber /episodes/:id/play). anime_id ist denormalisiert,
+-- damit /me/continue-watching ohne Episoden-Join pro Anime gruppieren kann.
+
+CREATE TABLE IF NOT EXISTS episode_watch_progress (
+    id BIGSERIAL PRIMARY KEY,
+    app_user_id BIGINT NOT NULL REFERENCES app_users(id) ON DELETE CASCADE,
+    anime_id BIGINT NOT NULL REFERENCES anime(id) ON DELETE CASCADE,
+    episode_id BIGINT NOT NULL REFERENCES episodes(id) ON DELETE CASCADE,
+    release_version_id BIGINT NULL REFERENCES release_versions(id) ON DELETE CASCADE,
+    position_seconds INTEGER NOT NULL DEFAULT 0,
+    duration_seconds INTEGER NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    completed_at TIMESTAMPTZ NULL,
+    last_watched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_episode_watch_progress_user_episode_version
+        UNIQUE NULLS NOT DISTINCT (app_user_id, episode_id, release_version_id),
+    CONSTRAINT chk_episode_watch_progress_position CHECK (position_seconds >= 0),
+    CONSTRAINT chk_episode_watch_progress_duration CHECK (duration_seconds IS NULL OR duration_seconds > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_episode_watch_progress_user_last_watched
+    ON episode_watch_progress (app_user_id, last_watched_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_episode_watch_progress_user_anime
+    ON episode_watch_progress (app_user_id, anime_id);
@@ -0,0 +1,21 @@
+-- Migration 0118 DOWN: Listenfelder der Watchlist entfernen.
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_watchlist_user_id_list_status;
+
+ALTER TABLE watchlist_entries
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_list_status,
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_score,
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_episodes_watched,
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_dates;
+
+ALTER TABLE watchlist_entries
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS finished_on,
+    DROP COLUMN IF EXISTS started_on,
+    DROP COLUMN IF EXISTS episodes_watched,
+    DROP COLUMN IF EXISTS score,
+    DROP COLUMN IF EXISTS list_status;
+
+COMMIT;
@@ -0,0 +1,35 @@
+-- Migration 0118: Watchlist-Eintraege werden zu Listeneintraegen mit Status-Lebenszyklus
+-- (planned, watching, on_hold, dropped, completed), persoenlicher Wertung 1-10,
+-- Episodenzaehler, Start-/Enddatum und privater Notiz.
+-- Bestandszeilen erhalten ueber den Spalten-Default den Status 'planned'.
+
+BEGIN;
+
+ALTER TABLE watchlist_entries
+    ADD COLUMN IF NOT EXISTS list_status VARCHAR(20) NOT NULL DEFAULT 'planned',
+    ADD COLUMN IF NOT EXISTS score SMALLINT NULL,
+    ADD COLUMN IF NOT EXISTS episodes_watched INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS started_on DATE NULL,
+    ADD COLUMN IF NOT EXISTS finished_on DATE NULL,
+    ADD COLUMN IF NOT EXISTS note TEXT NULL;
+
+ALTER TABLE watchlist_entries
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_list_status,
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_score,
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_episodes_watched,
+    DROP CONSTRAINT IF EXISTS chk_watchlist_entries_dates;
+
+ALTER TABLE watchlist_entries
+    ADD CONSTRAINT chk_watchlist_entries_list_status
+        CHECK (list_status IN ('planned', 'watching', 'on_hold', 'dropped', 'completed')),
+    ADD CONSTRAINT chk_watchlist_entries_score
+        CHECK (score IS NULL OR score BETWEEN 1 AND 10),
+    ADD CONSTRAINT chk_watchlist_entries_episodes_watched
+        CHECK (episodes_watched >= 0),
+    ADD CONSTRAINT chk_watchlist_entries_dates
+        CHECK (started_on IS NULL OR finished_on IS NULL OR finished_on >= started_on);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_user_id_list_status
+    ON watchlist_entries (user_id, list_status, updated_at DESC);
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Migration 0119 DOWN: Thread-, Verlaufs- und Soft-Delete-Spalten der Kommentare entfernen.
+-- Antworten werden dabei geloescht, weil sie ohne parent_id nicht mehr zuordenbar sind.
+
+BEGIN;
+
+DROP TABLE IF EXISTS comment_revisions;
+
+DELETE FROM comments WHERE parent_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_comments_author_user_id;
+DROP INDEX IF EXISTS idx_comments_root_id;
+DROP INDEX IF EXISTS idx_comments_anime_top_level;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_thread_shape;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS deleted_by_user_id,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS edited_at,
+    DROP COLUMN IF EXISTS depth,
+    DROP COLUMN IF EXISTS root_id,
+    DROP COLUMN IF EXISTS parent_id,
+    DROP COLUMN IF EXISTS author_user_id;
+
+COMMIT;
@@ -0,0 +1,47 @@
+-- Migration 0119: Kommentare werden zu Threads mit Antworten (parent_id, begrenzte Tiefe),
+-- Autor-Bindung ueber die Legacy users.id, Bearbeitungsverlauf und Soft-Delete.
+-- Bestandskommentare bleiben Top-Level-Kommentare ohne Autor-Bindung (nicht editierbar).
+
+BEGIN;
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS author_user_id BIGINT NULL,
+    ADD COLUMN IF NOT EXISTS parent_id BIGINT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS root_id BIGINT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS depth SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS deleted_by_user_id BIGINT NULL;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_thread_shape;
+ALTER TABLE comments
+    ADD CONSTRAINT chk_comments_thread_shape CHECK (
+        (parent_id IS NULL AND root_id IS NULL AND depth = 0)
+        OR (parent_id IS NOT NULL AND root_id IS NOT NULL AND depth BETWEEN 1 AND 3)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_comments_anime_top_level
+    ON comments (anime_id, created_at DESC, id DESC)
+    WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_root_id
+    ON comments (root_id, created_at, id)
+    WHERE root_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_author_user_id
+    ON comments (author_user_id)
+    WHERE author_user_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    content TEXT NOT NULL,
+    edited_by_user_id BIGINT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_comment_revisions_comment_revision UNIQUE (comment_id, revision),
+    CONSTRAINT chk_comment_revisions_revision CHECK (revision >= 1)
+);
+
+COMMIT;
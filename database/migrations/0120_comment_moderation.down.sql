@@ -0,0 +1,22 @@
+-- Migration 0120 DOWN: Meldungen, Kommentarsperren und Moderationsspalten entfernen.
+
+BEGIN;
+
+DELETE FROM action_definitions WHERE code = 'comments.moderate';
+
+DROP TABLE IF EXISTS comment_user_bans;
+DROP TABLE IF EXISTS comment_reports;
+
+DROP INDEX IF EXISTS idx_comments_author_recent;
+DROP INDEX IF EXISTS idx_comments_moderation_queue;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_moderation_status;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS moderated_by_app_user_id,
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderation_flags,
+    DROP COLUMN IF EXISTS moderation_status;
+
+COMMIT;
@@ -0,0 +1,62 @@
+-- Migration 0120: Kommentar-Moderation — Meldungen durch Nutzer, Moderationsstatus
+-- (sichtbar/zur Pruefung/ausgeblendet) samt Heuristik-Flags und Kommentarsperren.
+-- Die Berechtigung comments.moderate ist eine standalone-Action ohne role_capabilities-Eintrag.
+
+BEGIN;
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(20) NOT NULL DEFAULT 'visible',
+    ADD COLUMN IF NOT EXISTS moderation_flags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS moderated_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_moderation_status;
+ALTER TABLE comments
+    ADD CONSTRAINT chk_comments_moderation_status CHECK (
+        moderation_status IN ('visible', 'pending', 'hidden')
+    );
+
+CREATE INDEX IF NOT EXISTS idx_comments_moderation_queue
+    ON comments (moderation_status, created_at DESC, id DESC)
+    WHERE moderation_status <> 'visible';
+
+CREATE INDEX IF NOT EXISTS idx_comments_author_recent
+    ON comments (author_user_id, created_at DESC)
+    WHERE author_user_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS comment_reports (
+    id BIGSERIAL PRIMARY KEY,
+    comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    reporter_user_id BIGINT NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note TEXT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ NULL,
+    resolved_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    CONSTRAINT uq_comment_reports_comment_reporter UNIQUE (comment_id, reporter_user_id),
+    CONSTRAINT chk_comment_reports_reason CHECK (reason IN ('spam', 'abuse', 'spoiler', 'off_topic', 'other')),
+    CONSTRAINT chk_comment_reports_status CHECK (status IN ('open', 'resolved', 'dismissed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_reports_open
+    ON comment_reports (comment_id)
+    WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS comment_user_bans (
+    user_id BIGINT PRIMARY KEY,
+    reason TEXT NULL,
+    banned_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NULL
+);
+
+INSERT INTO action_definitions (code, label_de, category, sort_order) VALUES
+    ('comments.moderate', 'Kommentare moderieren', 'kommentare', 10)
+ON CONFLICT (code) DO UPDATE SET
+    label_de   = EXCLUDED.label_de,
+    category   = EXCLUDED.category,
+    sort_order = EXCLUDED.sort_order;
+
+COMMIT;
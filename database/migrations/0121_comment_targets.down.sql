@@ -0,0 +1,23 @@
+-- Migration 0121 DOWN: Kommentarziele entfernen. Kommentare, die nicht an einem Anime
+-- haengen, werden geloescht, weil sie ohne target_type nicht mehr zuordenbar sind.
+
+BEGIN;
+
+DELETE FROM comments WHERE target_type <> 'anime';
+
+DROP INDEX IF EXISTS idx_comments_target_visible;
+DROP INDEX IF EXISTS idx_comments_target_top_level;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_target_anime;
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_target_type;
+
+ALTER TABLE comments
+    ALTER COLUMN anime_id SET NOT NULL;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS target_id,
+    DROP COLUMN IF EXISTS target_type;
+
+COMMIT;
@@ -0,0 +1,42 @@
+-- Migration 0121: Kommentare bekommen ein polymorphes Ziel (anime, episode, release_version,
+-- fansub_group). anime_id bleibt als Kontext erhalten und ist nur bei Fansubgruppen leer.
+-- target_id hat bewusst keinen Fremdschluessel; das Ziel wird beim Anlegen geprueft.
+
+BEGIN;
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS target_type VARCHAR(30) NOT NULL DEFAULT 'anime',
+    ADD COLUMN IF NOT EXISTS target_id BIGINT NULL;
+
+UPDATE comments
+SET target_type = 'anime',
+    target_id = anime_id
+WHERE target_id IS NULL;
+
+ALTER TABLE comments
+    ALTER COLUMN target_id SET NOT NULL,
+    ALTER COLUMN anime_id DROP NOT NULL;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_target_type;
+ALTER TABLE comments
+    ADD CONSTRAINT chk_comments_target_type CHECK (
+        target_type IN ('anime', 'episode', 'release_version', 'fansub_group')
+    );
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS chk_comments_target_anime;
+ALTER TABLE comments
+    ADD CONSTRAINT chk_comments_target_anime CHECK (
+        target_type = 'fansub_group' OR anime_id IS NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_comments_target_top_level
+    ON comments (target_type, target_id, created_at DESC, id DESC)
+    WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_target_visible
+    ON comments (target_type, target_id)
+    WHERE deleted_at IS NULL AND moderation_status = 'visible';
+
+COMMIT;
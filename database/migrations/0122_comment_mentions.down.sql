@@ -0,0 +1,8 @@
+-- Migration 0122 DOWN: @mentions in Kommentaren entfernen.
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_comment_mentions_app_user;
+DROP TABLE IF EXISTS comment_mentions;
+
+COMMIT;
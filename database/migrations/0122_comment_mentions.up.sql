@@ -0,0 +1,22 @@
+-- Migration 0122: @mentions in Kommentaren. Pro Kommentar und erwaehntem Member ein Eintrag;
+-- mentioned_app_user_id ist der Account hinter dem Member-Profil (verifizierter Claim oder
+-- Legacy-Verknuepfung) und speist die Liste unter /me/mentions. member_slug haelt den
+-- aufgeloesten Profil-Slug fuer die Verlinkung im gerenderten Kommentar fest.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    id BIGSERIAL PRIMARY KEY,
+    comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    member_id BIGINT NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    member_slug VARCHAR(120) NOT NULL,
+    mentioned_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_comment_mentions_comment_member UNIQUE (comment_id, member_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_app_user
+    ON comment_mentions (mentioned_app_user_id, created_at DESC, id DESC)
+    WHERE mentioned_app_user_id IS NOT NULL;
+
+COMMIT;
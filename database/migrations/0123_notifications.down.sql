@@ -0,0 +1,10 @@
+-- Migration 0123 DOWN: Benachrichtigungen und Praeferenzen entfernen.
+
+BEGIN;
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP INDEX IF EXISTS idx_notifications_user_unread;
+DROP INDEX IF EXISTS idx_notifications_user_created;
+DROP TABLE IF EXISTS notifications;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- Migration 0123: In-App-Benachrichtigungen und Zustellpraeferenzen je Ereignistyp.
+-- notifications gehoert einem App-User; read_at = NULL bedeutet ungelesen.
+-- notification_preferences speichert nur Abweichungen vom Standardkanal (in_app).
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    app_user_id BIGINT NOT NULL REFERENCES app_users(id) ON DELETE CASCADE,
+    event_type VARCHAR(60) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NULL,
+    link_url TEXT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    actor_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    read_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+    ON notifications (app_user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+    ON notifications (app_user_id)
+    WHERE read_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    app_user_id BIGINT NOT NULL REFERENCES app_users(id) ON DELETE CASCADE,
+    event_type VARCHAR(60) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_notification_preferences PRIMARY KEY (app_user_id, event_type),
+    CONSTRAINT chk_notification_preferences_channel CHECK (channel IN ('in_app', 'email', 'off'))
+);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration 0124 DOWN: Mail-Outbox entfernen.
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_mail_outbox_reference;
+DROP INDEX IF EXISTS idx_mail_outbox_status_created;
+DROP INDEX IF EXISTS idx_mail_outbox_due;
+DROP TABLE IF EXISTS mail_outbox;
+
+COMMIT;
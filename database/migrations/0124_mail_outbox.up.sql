@@ -0,0 +1,42 @@
+-- Migration 0124: Transaktionaler Mail-Outbox.
+-- Mails werden in derselben Transaktion wie die fachliche Aenderung eingereiht und von einem
+-- Hintergrund-Worker mit exponentiellem Backoff versendet. Nach max_attempts Fehlversuchen
+-- landet eine Mail im Status dead und kann ueber die Admin-Ansicht erneut eingereiht werden.
+-- redact_after_send leert template_data nach erfolgreichem Versand (z. B. Einladungs-Token).
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS mail_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    template_name VARCHAR(80) NOT NULL,
+    locale VARCHAR(10) NOT NULL DEFAULT 'de',
+    recipient VARCHAR(320) NOT NULL,
+    template_data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    redact_after_send BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 8,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ NULL,
+    last_error TEXT NULL,
+    sent_at TIMESTAMPTZ NULL,
+    reference_type VARCHAR(60) NULL,
+    reference_id BIGINT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_mail_outbox_status CHECK (status IN ('pending', 'sending', 'sent', 'dead')),
+    CONSTRAINT chk_mail_outbox_attempts CHECK (attempts >= 0 AND max_attempts >= 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_due
+    ON mail_outbox (next_attempt_at, id)
+    WHERE status IN ('pending', 'sending');
+
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_status_created
+    ON mail_outbox (status, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_reference
+    ON mail_outbox (reference_type, reference_id)
+    WHERE reference_type IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration 0125 DOWN: Digest-Einstellungen und Gruppen-Follows entfernen.
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_fansub_group_follows_group;
+DROP TABLE IF EXISTS fansub_group_follows;
+DROP INDEX IF EXISTS idx_notification_digest_settings_due;
+DROP TABLE IF EXISTS notification_digest_settings;
+
+COMMIT;
@@ -0,0 +1,37 @@
+-- Migration 0125: Zusammenfassungs-Mails (Digest) je Nutzer.
+-- notification_digest_settings speichert Haeufigkeit (off/daily/weekly), Sprache und den
+-- Abmelde-Token. next_due_at wird vom Scheduler nach jedem Lauf neu berechnet; last_sent_at
+-- markiert den Beginn des naechsten Zeitfensters.
+-- fansub_group_follows haelt die gefolgten Fansub-Gruppen, deren neue Release-Versionen im
+-- Digest erscheinen.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notification_digest_settings (
+    app_user_id BIGINT PRIMARY KEY REFERENCES app_users(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL DEFAULT 'off',
+    locale VARCHAR(10) NOT NULL DEFAULT 'de',
+    unsubscribe_token VARCHAR(64) NOT NULL,
+    last_sent_at TIMESTAMPTZ NULL,
+    next_due_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_notification_digest_settings_frequency CHECK (frequency IN ('off', 'daily', 'weekly')),
+    CONSTRAINT uq_notification_digest_settings_unsubscribe_token UNIQUE (unsubscribe_token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_digest_settings_due
+    ON notification_digest_settings (next_due_at)
+    WHERE frequency <> 'off';
+
+CREATE TABLE IF NOT EXISTS fansub_group_follows (
+    app_user_id BIGINT NOT NULL REFERENCES app_users(id) ON DELETE CASCADE,
+    fansub_group_id BIGINT NOT NULL REFERENCES fansub_groups(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_fansub_group_follows PRIMARY KEY (app_user_id, fansub_group_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fansub_group_follows_group
+    ON fansub_group_follows (fansub_group_id);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration 0126 DOWN: Anime-Follows und Feed-Indizes entfernen.
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_fansub_group_history_group_created;
+DROP INDEX IF EXISTS idx_release_versions_created;
+DROP INDEX IF EXISTS idx_anime_follows_anime;
+DROP TABLE IF EXISTS anime_follows;
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- Migration 0126: Anime folgen und Indizes fuer den Aktivitaets-Feed.
+-- fansub_group_follows existiert seit Migration 0125; anime_follows ergaenzt das Gegenstueck
+-- fuer Anime (unabhaengig von der Watchlist). Der Feed unter /me/feed sortiert neue
+-- Release-Versionen, Gruppenhistorie, Member-Stories und Projekt-Notizen nach created_at.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS anime_follows (
+    app_user_id BIGINT NOT NULL REFERENCES app_users(id) ON DELETE CASCADE,
+    anime_id BIGINT NOT NULL REFERENCES anime(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_anime_follows PRIMARY KEY (app_user_id, anime_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_anime_follows_anime
+    ON anime_follows (anime_id);
+
+CREATE INDEX IF NOT EXISTS idx_release_versions_created
+    ON release_versions (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_fansub_group_history_group_created
+    ON fansub_group_history (fansub_group_id, created_at DESC);
+
+COMMIT;
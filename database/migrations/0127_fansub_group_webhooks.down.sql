@@ -0,0 +1,14 @@
+-- Migration 0127 DOWN: Fansub-Webhooks und Zustell-Log entfernen.
+
+BEGIN;
+
+DELETE FROM role_capabilities WHERE action_code = 'fansub_group.webhooks.manage';
+DELETE FROM action_definitions WHERE code = 'fansub_group.webhooks.manage';
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_created;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_fansub_group_webhooks_group;
+DROP TABLE IF EXISTS fansub_group_webhooks;
+
+COMMIT;
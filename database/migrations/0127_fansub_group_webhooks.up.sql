@@ -0,0 +1,65 @@
+-- Migration 0127: Ausgehende Webhooks pro Fansub-Gruppe.
+-- Gruppenleitungen registrieren Ziel-URLs fuer ausgewaehlte Ereignisse (z. B. neue Release-Version).
+-- Zustellungen landen in webhook_deliveries und werden von einem Hintergrund-Worker mit
+-- HMAC-Signatur und exponentiellem Backoff versendet; die Tabelle dient zugleich als Zustell-Log.
+-- secret wird im Klartext gespeichert, weil es fuer jede Signatur benoetigt wird.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS fansub_group_webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    fansub_group_id BIGINT NOT NULL REFERENCES fansub_groups(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    format VARCHAR(20) NOT NULL DEFAULT 'json',
+    events TEXT[] NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_fansub_group_webhooks_format CHECK (format IN ('json', 'discord')),
+    CONSTRAINT chk_fansub_group_webhooks_events CHECK (cardinality(events) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fansub_group_webhooks_group
+    ON fansub_group_webhooks (fansub_group_id)
+    WHERE is_active;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL REFERENCES fansub_group_webhooks(id) ON DELETE CASCADE,
+    event_type VARCHAR(60) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 6,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ NULL,
+    response_status INTEGER NULL,
+    last_error TEXT NULL,
+    delivered_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_webhook_deliveries_status CHECK (status IN ('pending', 'sending', 'delivered', 'dead')),
+    CONSTRAINT chk_webhook_deliveries_attempts CHECK (attempts >= 0 AND max_attempts >= 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at, id)
+    WHERE status IN ('pending', 'sending');
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_created
+    ON webhook_deliveries (webhook_id, created_at DESC, id DESC);
+
+INSERT INTO action_definitions (code, label_de, category, sort_order) VALUES
+    ('fansub_group.webhooks.manage', 'Webhooks verwalten', 'gruppe', 48)
+ON CONFLICT (code) DO UPDATE SET
+    label_de = EXCLUDED.label_de,
+    category = EXCLUDED.category,
+    sort_order = EXCLUDED.sort_order;
+
+INSERT INTO role_capabilities (role_code, action_code) VALUES
+    ('fansub_lead', 'fansub_group.webhooks.manage')
+ON CONFLICT DO NOTHING;
+
+COMMIT;
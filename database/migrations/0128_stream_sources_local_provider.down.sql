@@ -0,0 +1,13 @@
+-- Migration 0128 DOWN: Media-Provider "local" wieder aus dem Provider-Check entfernen.
+-- NOT VALID, damit bereits angelegte lokale Stream-Quellen den Rollback nicht blockieren.
+
+BEGIN;
+
+ALTER TABLE stream_sources
+    DROP CONSTRAINT IF EXISTS chk_stream_sources_provider_type;
+
+ALTER TABLE stream_sources
+    ADD CONSTRAINT chk_stream_sources_provider_type
+        CHECK (provider_type IN ('jellyfin', 'youtube', 'vimeo', 'direct')) NOT VALID;
+
+COMMIT;
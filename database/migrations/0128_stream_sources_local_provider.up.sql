@@ -0,0 +1,14 @@
+-- Migration 0128: Media-Provider "local" fuer Stream-Quellen.
+-- Episodenversionen koennen auf Dateien unterhalb von LOCAL_MEDIA_ROOT verweisen; external_id
+-- enthaelt dann den relativen Pfad (VARCHAR(255) reicht dafuer aus).
+
+BEGIN;
+
+ALTER TABLE stream_sources
+    DROP CONSTRAINT IF EXISTS chk_stream_sources_provider_type;
+
+ALTER TABLE stream_sources
+    ADD CONSTRAINT chk_stream_sources_provider_type
+        CHECK (provider_type IN ('jellyfin', 'youtube', 'vimeo', 'direct', 'local'));
+
+COMMIT;
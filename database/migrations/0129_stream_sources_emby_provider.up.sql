@@ -0,0 +1,13 @@
+-- Migration 0129: Media-Provider "emby" fuer Stream-Quellen.
+-- Mit MEDIA_SERVER_CATALOG=emby legen Intake und Sync Stream-Quellen mit provider_type 'emby' an.
+
+BEGIN;
+
+ALTER TABLE stream_sources
+    DROP CONSTRAINT IF EXISTS chk_stream_sources_provider_type;
+
+ALTER TABLE stream_sources
+    ADD CONSTRAINT chk_stream_sources_provider_type
+        CHECK (provider_type IN ('jellyfin', 'emby', 'youtube', 'vimeo', 'direct', 'local'));
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0130 DOWN: Ausstehende Jellyfin-Aenderungen und Auto-Uebernahme entfernen.
+
+BEGIN;
+
+DROP TABLE IF EXISTS anime_jellyfin_sync_settings;
+DROP TABLE IF EXISTS jellyfin_pending_changes;
+
+COMMIT;
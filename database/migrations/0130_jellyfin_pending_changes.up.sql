@@ -0,0 +1,47 @@
+-- Migration 0130: Ausstehende Aenderungen aus dem Jellyfin-Webhook.
+-- Der Webhook-Empfaenger ordnet ItemAdded/ItemUpdated/ItemRemoved einer Anime zu und legt pro
+-- Anime und Jellyfin-Item hoechstens einen offenen Eintrag an; weitere Ereignisse zum selben Item
+-- aktualisieren ihn (event_count). Admins uebernehmen oder verwerfen Eintraege, pro Anime kann
+-- die automatische Uebernahme aktiviert werden.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS jellyfin_pending_changes (
+    id BIGSERIAL PRIMARY KEY,
+    anime_id BIGINT NOT NULL REFERENCES anime(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL,
+    item_type VARCHAR(40) NOT NULL,
+    jellyfin_item_id VARCHAR(120) NOT NULL,
+    jellyfin_series_id VARCHAR(120) NOT NULL,
+    item_name TEXT NULL,
+    season_number INTEGER NULL,
+    episode_number INTEGER NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    event_count INTEGER NOT NULL DEFAULT 1,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    result JSONB NULL,
+    error_message TEXT NULL,
+    resolved_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_jellyfin_pending_changes_event_type CHECK (event_type IN ('item_added', 'item_updated', 'item_removed')),
+    CONSTRAINT chk_jellyfin_pending_changes_status CHECK (status IN ('pending', 'applied', 'rejected', 'failed')),
+    CONSTRAINT chk_jellyfin_pending_changes_event_count CHECK (event_count >= 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_jellyfin_pending_changes_open_item
+    ON jellyfin_pending_changes (anime_id, jellyfin_item_id)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_jellyfin_pending_changes_status_created
+    ON jellyfin_pending_changes (status, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS anime_jellyfin_sync_settings (
+    anime_id BIGINT PRIMARY KEY REFERENCES anime(id) ON DELETE CASCADE,
+    auto_apply BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0131 DOWN: Jellyfin-Abgleich (Laeufe und Befunde) entfernen.
+
+BEGIN;
+
+DROP TABLE IF EXISTS jellyfin_reconciliation_findings;
+DROP TABLE IF EXISTS jellyfin_reconciliation_runs;
+
+COMMIT;
@@ -0,0 +1,70 @@
+-- Migration 0131: Geplanter Abgleich zwischen Katalog und Jellyfin-Bibliotheken.
+-- Jeder Lauf prueft die verknuepften Anime gegen die freigegebenen Bibliotheken und legt
+-- Abweichungen als Befunde ab. Ein Befund wird ueber seinen Fingerprint wiedererkannt: solange
+-- er offen oder verworfen ist, aktualisieren weitere Laeufe denselben Eintrag. Befunde, die ein
+-- abgeschlossener Lauf nicht mehr sieht, werden automatisch als erledigt markiert.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS jellyfin_reconciliation_runs (
+    id BIGSERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    provider VARCHAR(20) NOT NULL,
+    library_ids TEXT[] NOT NULL DEFAULT '{}',
+    anime_checked INTEGER NOT NULL DEFAULT 0,
+    findings_detected INTEGER NOT NULL DEFAULT 0,
+    findings_auto_resolved INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ NULL,
+    CONSTRAINT chk_jellyfin_reconciliation_runs_status CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_jellyfin_reconciliation_runs_running
+    ON jellyfin_reconciliation_runs (status)
+    WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_jellyfin_reconciliation_runs_started
+    ON jellyfin_reconciliation_runs (started_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS jellyfin_reconciliation_findings (
+    id BIGSERIAL PRIMARY KEY,
+    fingerprint VARCHAR(300) NOT NULL,
+    finding_type VARCHAR(40) NOT NULL,
+    anime_id BIGINT NOT NULL REFERENCES anime(id) ON DELETE CASCADE,
+    jellyfin_series_id VARCHAR(120) NULL,
+    jellyfin_item_id VARCHAR(120) NULL,
+    release_variant_id BIGINT NULL REFERENCES release_variants(id) ON DELETE CASCADE,
+    episode_number TEXT NULL,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    first_run_id BIGINT NULL REFERENCES jellyfin_reconciliation_runs(id) ON DELETE SET NULL,
+    last_run_id BIGINT NULL REFERENCES jellyfin_reconciliation_runs(id) ON DELETE SET NULL,
+    seen_count INTEGER NOT NULL DEFAULT 1,
+    resolved_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_jellyfin_reconciliation_findings_type CHECK (finding_type IN (
+        'series_missing',
+        'series_outside_libraries',
+        'episode_without_variant',
+        'variant_item_missing',
+        'runtime_mismatch',
+        'metadata_drift'
+    )),
+    CONSTRAINT chk_jellyfin_reconciliation_findings_status CHECK (status IN ('open', 'resolved', 'dismissed')),
+    CONSTRAINT chk_jellyfin_reconciliation_findings_seen_count CHECK (seen_count >= 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_jellyfin_reconciliation_findings_active_fingerprint
+    ON jellyfin_reconciliation_findings (fingerprint)
+    WHERE status IN ('open', 'dismissed');
+
+CREATE INDEX IF NOT EXISTS idx_jellyfin_reconciliation_findings_status_seen
+    ON jellyfin_reconciliation_findings (status, last_seen_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_jellyfin_reconciliation_findings_anime
+    ON jellyfin_reconciliation_findings (anime_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration 0132 DOWN: Sicherheitsereignisse der Wiedergabe und Grant-Sperren entfernen.
+
+BEGIN;
+
+DROP TABLE IF EXISTS playback_grant_blocks;
+DROP TABLE IF EXISTS playback_security_events;
+
+COMMIT;
@@ -0,0 +1,61 @@
+-- Migration 0132: Sicherheitsereignisse der Wiedergabe und automatische Grant-Sperren.
+-- Rate-Limit-Verstoesse, Grant-Replays, Mehrfach-IPs usw. wurden bisher nur als kurzlebige
+-- Redis-Zaehler festgehalten. Sie landen jetzt dauerhaft in playback_security_events, damit
+-- Admins sie nach Nutzer, Zeitraum und Typ auswerten koennen. Ueberschreitet ein Nutzer die
+-- Schwelle, sperrt eine Zeile in playback_grant_blocks seine Stream-Grants voruebergehend.
+-- user_id ist bewusst ohne Fremdschluessel: Ereignisse sollen auch nach dem Loeschen eines
+-- Kontos nachvollziehbar bleiben.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS playback_security_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(40) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    user_id BIGINT NULL,
+    client_ip VARCHAR(64) NULL,
+    episode_id BIGINT NULL,
+    action VARCHAR(40) NULL,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_playback_security_events_type CHECK (event_type IN (
+        'rapid_grant_requests',
+        'multiple_ips',
+        'rate_limit_violation',
+        'grant_replay',
+        'session_limit_reached'
+    )),
+    CONSTRAINT chk_playback_security_events_severity CHECK (severity IN ('info', 'warning', 'critical'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_playback_security_events_created
+    ON playback_security_events (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_playback_security_events_user_created
+    ON playback_security_events (user_id, created_at DESC)
+    WHERE user_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_playback_security_events_type_created
+    ON playback_security_events (event_type, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS playback_grant_blocks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    reason VARCHAR(200) NOT NULL,
+    event_count INTEGER NOT NULL DEFAULT 0,
+    blocked_until TIMESTAMPTZ NOT NULL,
+    lifted_by_app_user_id BIGINT NULL REFERENCES app_users(id) ON DELETE SET NULL,
+    lifted_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_playback_grant_blocks_event_count CHECK (event_count >= 0),
+    CONSTRAINT chk_playback_grant_blocks_until CHECK (blocked_until > created_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_playback_grant_blocks_user_until
+    ON playback_grant_blocks (user_id, blocked_until DESC)
+    WHERE lifted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_playback_grant_blocks_created
+    ON playback_grant_blocks (created_at DESC, id DESC);
+
+COMMIT;
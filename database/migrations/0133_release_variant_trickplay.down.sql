@@ -0,0 +1,18 @@
+-- Migration 0133 DOWN: Trickplay-Vorschaubilder fuer Release-Varianten entfernen.
+-- Bereits erzeugte Sprite-Dateien bleiben im Medienverzeichnis liegen.
+
+BEGIN;
+
+DROP TABLE IF EXISTS release_variant_trickplay;
+
+DELETE FROM media_assets
+WHERE media_type_id IN (SELECT id FROM media_types WHERE name IN ('trickplay_sprite', 'trickplay_vtt'));
+
+DROP INDEX IF EXISTS idx_media_assets_owner_release_variant;
+
+ALTER TABLE media_assets
+    DROP COLUMN IF EXISTS owner_release_variant_id;
+
+DELETE FROM media_types WHERE name IN ('trickplay_sprite', 'trickplay_vtt');
+
+COMMIT;
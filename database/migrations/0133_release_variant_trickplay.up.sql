@@ -0,0 +1,44 @@
+-- Migration 0133: Trickplay-Vorschaubilder fuer Release-Varianten.
+-- Ein Hintergrundjob erzeugt per ffmpeg gekachelte JPEG-Sprites und eine WebVTT-Spur fuer die
+-- Vorschau auf der Zeitleiste. Beide liegen als media_assets vor, deren Eigentuemer die Variante
+-- ist (owner_release_variant_id). Wird die Variante geloescht, verlieren die Assets ihren
+-- Eigentuemer und der Media-Cleanup entfernt Dateien und Zeilen.
+
+BEGIN;
+
+INSERT INTO media_types (name) VALUES
+    ('trickplay_sprite'),
+    ('trickplay_vtt')
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE media_assets
+    ADD COLUMN IF NOT EXISTS owner_release_variant_id BIGINT NULL REFERENCES release_variants(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_media_assets_owner_release_variant
+    ON media_assets (owner_release_variant_id)
+    WHERE owner_release_variant_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS release_variant_trickplay (
+    release_variant_id BIGINT PRIMARY KEY REFERENCES release_variants(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'processing',
+    interval_seconds INTEGER NOT NULL,
+    tile_width INTEGER NULL,
+    tile_height INTEGER NULL,
+    tile_columns INTEGER NULL,
+    tile_rows INTEGER NULL,
+    thumbnail_count INTEGER NULL,
+    sprite_count INTEGER NULL,
+    vtt_media_asset_id BIGINT NULL REFERENCES media_assets(id) ON DELETE SET NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT NULL,
+    generated_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_release_variant_trickplay_status CHECK (status IN ('processing', 'ready', 'failed')),
+    CONSTRAINT chk_release_variant_trickplay_interval CHECK (interval_seconds > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_release_variant_trickplay_status
+    ON release_variant_trickplay (status, updated_at);
+
+COMMIT;
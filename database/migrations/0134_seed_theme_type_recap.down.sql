@@ -0,0 +1,9 @@
+-- Migration 0134 DOWN: Theme-Typ "Recap" entfernen, sofern kein Theme ihn verwendet.
+
+BEGIN;
+
+DELETE FROM theme_types tt
+WHERE tt.name = 'Recap'
+  AND NOT EXISTS (SELECT 1 FROM themes t WHERE t.theme_type_id = tt.id);
+
+COMMIT;
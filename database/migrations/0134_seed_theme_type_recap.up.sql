@@ -0,0 +1,11 @@
+-- Migration 0134: Theme-Typ "Recap" fuer Rueckblicke am Episodenanfang.
+-- Segmente dieses Typs werden als Skip-Marker "recap" exportiert (neben OP -> intro und
+-- ED/Outro -> outro).
+
+BEGIN;
+
+INSERT INTO theme_types (name) VALUES
+    ('Recap')
+ON CONFLICT (name) DO NOTHING;
+
+COMMIT;
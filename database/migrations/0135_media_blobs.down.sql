@@ -0,0 +1,13 @@
+-- Migration 0135 DOWN: Inhaltsadressierte Medien-Blobs entfernen.
+-- Die Dateien bleiben unter ihrem Pfad liegen; media_assets.file_path zeigt weiterhin darauf.
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_media_assets_blob;
+
+ALTER TABLE media_assets
+    DROP COLUMN IF EXISTS blob_id;
+
+DROP TABLE IF EXISTS media_blobs;
+
+COMMIT;
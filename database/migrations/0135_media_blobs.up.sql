@@ -0,0 +1,28 @@
+-- Migration 0135: Inhaltsadressierte Medien-Blobs.
+-- Uploads ueber MediaService.SaveUpload werden per SHA-256 abgelegt; identische Dateien teilen
+-- sich einen Blob. Die Referenzen sind die media_assets-Zeilen mit blob_id, der Zaehler ergibt sich
+-- daraus. Ein Blob (Zeile und Datei) wird erst entfernt, wenn kein Asset mehr auf ihn zeigt; der
+-- Fremdschluessel verhindert das Loeschen referenzierter Blobs.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS media_blobs (
+    id BIGSERIAL PRIMARY KEY,
+    sha256 CHAR(64) NOT NULL,
+    storage_path TEXT NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_media_blobs_sha256 UNIQUE (sha256),
+    CONSTRAINT uq_media_blobs_storage_path UNIQUE (storage_path),
+    CONSTRAINT chk_media_blobs_size_bytes CHECK (size_bytes >= 0)
+);
+
+ALTER TABLE media_assets
+    ADD COLUMN IF NOT EXISTS blob_id BIGINT NULL REFERENCES media_blobs(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_media_assets_blob
+    ON media_assets (blob_id)
+    WHERE blob_id IS NOT NULL;
+
+COMMIT;
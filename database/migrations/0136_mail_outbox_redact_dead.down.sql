@@ -0,0 +1,4 @@
+-- Migration 0136 DOWN: keine Aktion, geleerte Template-Daten lassen sich nicht wiederherstellen.
+
+BEGIN;
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration 0136: Template-Daten toter Mails mit redact_after_send leeren.
+-- Bisher wurden nur erfolgreich versendete Eintraege geleert; abgebrochene Einladungsmails
+-- behielten den Roh-Token. Der Worker leert sie ab jetzt beim Uebergang nach dead.
+
+BEGIN;
+
+UPDATE mail_outbox
+SET template_data = '{}'::jsonb,
+    updated_at = NOW()
+WHERE status = 'dead'
+  AND redact_after_send
+  AND template_data <> '{}'::jsonb;
+
+COMMIT;
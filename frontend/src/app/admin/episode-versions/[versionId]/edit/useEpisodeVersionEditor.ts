@@ -180,7 +180,7 @@ export function useEpisodeVersionEditor() {
     setFormState((current) => ({
       ...current,
       title: current.title.trim() ? current.title : file.release_name || current.title,
-      mediaProvider: 'jellyfin',
+      mediaProvider: file.media_provider || 'jellyfin',
       mediaItemID: file.media_item_id,
       videoQuality: file.video_quality || current.videoQuality,
       streamURL: file.stream_url || current.streamURL,
@@ -58,6 +58,7 @@ export interface EpisodeVersionEditorContextResponse {
 export interface EpisodeVersionMediaFile {
   file_name: string
   path: string
+  media_provider?: string
   media_item_id: string
   stream_url?: string | null
   video_quality?: string | null